@@ -0,0 +1,564 @@
+/*
+MIT License
+
+Copyright (c) 2020-2024 Kazuhito Suda
+
+This file is part of NGSI Go
+
+https://github.com/lets-fiware/ngsi-go
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+
+*/
+
+package convenience
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lets-fiware/ngsi-go/internal/ngsicli"
+	"github.com/lets-fiware/ngsi-go/internal/ngsierr"
+	"github.com/lets-fiware/ngsi-go/internal/ngsilib"
+)
+
+// applyItem is a subscription or a registration read from a manifest or from a broker
+type applyItem struct {
+	kind string
+	key  string
+	file string
+	id   string
+	body map[string]interface{}
+}
+
+type applyStep struct {
+	op      string
+	want    *applyItem
+	have    *applyItem
+	changes []string
+}
+
+var applyKinds = []string{"registration", "subscription"}
+
+func apply(c *ngsicli.Context, ngsi *ngsilib.NGSI, client *ngsilib.Client) error {
+	const funcName = "apply"
+
+	manifests, err := applyReadManifests(ngsi, c.String("file"), client.IsNgsiLd(), c.String("host"))
+	if err != nil {
+		return ngsierr.New(funcName, 1, err.Error(), err)
+	}
+
+	// Only the kinds which have manifests are pruned, so that the manifests of subscriptions alone
+	// don't delete the registrations of the tenant.
+	managed := map[string]bool{}
+	for _, m := range manifests {
+		managed[m.kind] = true
+	}
+
+	var steps []*applyStep
+	var kept []string
+	for _, kind := range applyKinds {
+		items, err := applyList(client, kind)
+		if err != nil {
+			return ngsierr.New(funcName, 2, err.Error(), err)
+		}
+		prune := c.Bool("prune") && managed[kind]
+		if c.Bool("prune") && !managed[kind] {
+			kept = append(kept, kind)
+		}
+		steps = append(steps, applyPlan(kind, manifests, items, prune)...)
+	}
+
+	count := map[string]int{}
+	for _, s := range steps {
+		count[s.op]++
+	}
+
+	if c.Bool("diff") {
+		for _, s := range steps {
+			switch s.op {
+			case "create":
+				fmt.Fprintf(ngsi.StdWriter, "+ %s %q (%s)\n", s.want.kind, s.want.key, s.want.file)
+			case "update":
+				fmt.Fprintf(ngsi.StdWriter, "~ %s %q %s (%s)\n", s.want.kind, s.want.key, s.have.id, s.want.file)
+				for _, change := range s.changes {
+					fmt.Fprintf(ngsi.StdWriter, "    %s\n", change)
+				}
+			case "delete":
+				fmt.Fprintf(ngsi.StdWriter, "- %s %q %s\n", s.have.kind, s.have.key, s.have.id)
+			}
+		}
+		for _, kind := range kept {
+			fmt.Fprintf(ngsi.StdWriter, "= %ss not pruned: no %s in the manifests\n", kind, kind)
+		}
+		fmt.Fprintf(ngsi.StdWriter, "plan: %d to create, %d to update, %d to delete, %d unchanged\n", count["create"], count["update"], count["delete"], count["unchanged"])
+		return nil
+	}
+
+	for _, s := range steps {
+		var msg string
+		switch s.op {
+		case "create":
+			id, err := applyCreate(client, s.want)
+			if err != nil {
+				return ngsierr.New(funcName, 3, err.Error(), err)
+			}
+			msg = fmt.Sprintf("created %s %q %s", s.want.kind, s.want.key, id)
+		case "update":
+			if err := applyUpdate(client, s.want, s.have); err != nil {
+				return ngsierr.New(funcName, 4, err.Error(), err)
+			}
+			msg = fmt.Sprintf("updated %s %q %s", s.want.kind, s.want.key, s.have.id)
+		case "delete":
+			if err := applyDelete(client, s.have); err != nil {
+				return ngsierr.New(funcName, 5, err.Error(), err)
+			}
+			msg = fmt.Sprintf("deleted %s %q %s", s.have.kind, s.have.key, s.have.id)
+		default:
+			continue
+		}
+		msg = strings.TrimSpace(msg)
+		ngsi.Logging(ngsilib.LogInfo, msg)
+		fmt.Fprintln(ngsi.StdWriter, msg)
+	}
+	fmt.Fprintf(ngsi.StdWriter, "%d created, %d updated, %d deleted, %d unchanged\n", count["create"], count["update"], count["delete"], count["unchanged"])
+
+	return nil
+}
+
+// applyReadManifests reads the manifests in a JSON or YAML file, or in the files of a directory
+func applyReadManifests(ngsi *ngsilib.NGSI, path string, ld bool, host string) ([]*applyItem, error) {
+	const funcName = "applyReadManifests"
+
+	files := []string{path}
+	if !applyIsManifestFile(path) {
+		files = nil
+		for _, ext := range []string{"*.json", "*.yaml", "*.yml"} {
+			names, err := ngsi.FilePath.FilePathGlob(ngsi.FilePath.FilePathJoin(path, ext))
+			if err != nil {
+				return nil, ngsierr.New(funcName, 1, err.Error(), err)
+			}
+			files = append(files, names...)
+		}
+		sort.Strings(files)
+		if len(files) == 0 {
+			return nil, ngsierr.New(funcName, 2, "no manifest found in "+path, nil)
+		}
+	}
+
+	var items []*applyItem
+	keys := map[string]string{}
+
+	for _, file := range files {
+		b, err := ngsi.Ioutil.ReadFile(file)
+		if err != nil {
+			return nil, ngsierr.New(funcName, 3, err.Error(), err)
+		}
+		var docs []interface{}
+		if strings.HasSuffix(file, ".json") {
+			var v interface{}
+			if err := ngsilib.JSONUnmarshal(b, &v); err != nil {
+				return nil, ngsierr.New(funcName, 4, fmt.Sprintf("%s: %s", file, err.Error()), err)
+			}
+			docs = append(docs, v)
+		} else {
+			docs, err = ngsilib.YAMLDecode(b)
+			if err != nil {
+				return nil, ngsierr.New(funcName, 5, fmt.Sprintf("%s: %s", file, err.Error()), err)
+			}
+		}
+		for _, doc := range docs {
+			list, ok := doc.([]interface{})
+			if !ok {
+				list = []interface{}{doc}
+			}
+			for _, v := range list {
+				item, err := applyManifest(file, v, ld, host)
+				if err != nil {
+					return nil, ngsierr.New(funcName, 6, err.Error(), err)
+				}
+				k := item.kind + "\t" + applyMatchKey(item.key)
+				if f, ok := keys[k]; ok {
+					return nil, ngsierr.New(funcName, 7, fmt.Sprintf("%s: %s %q is also defined in %s", file, item.kind, item.key, f), nil)
+				}
+				keys[k] = file
+				items = append(items, item)
+			}
+		}
+	}
+
+	return items, nil
+}
+
+func applyIsManifestFile(path string) bool {
+	for _, ext := range []string{".json", ".yaml", ".yml"} {
+		if strings.HasSuffix(path, ext) {
+			return true
+		}
+	}
+	return false
+}
+
+// applyManifest makes an applyItem from a manifest. The shape of the manifest tells its kind and NGSI type.
+// A label is kept in the description as "[label] description" so that the description can be changed.
+func applyManifest(file string, v interface{}, ld bool, host string) (*applyItem, error) {
+	const funcName = "applyManifest"
+
+	body, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, ngsierr.New(funcName, 1, file+": manifest must be a JSON object", nil)
+	}
+
+	item := &applyItem{file: file, body: body}
+	isLd := false
+
+	t, _ := body["type"].(string)
+	switch {
+	case t == "Subscription":
+		item.kind, isLd = "subscription", true
+	case t == "ContextSourceRegistration":
+		item.kind, isLd = "registration", true
+	case body["dataProvided"] != nil || body["provider"] != nil:
+		item.kind = "registration"
+	case body["subject"] != nil || body["notification"] != nil:
+		item.kind = "subscription"
+	default:
+		return nil, ngsierr.New(funcName, 2, file+": neither subscription nor registration", nil)
+	}
+	if isLd != ld {
+		want, have := "NGSIv2", "NGSI-LD"
+		if isLd {
+			want, have = have, want
+		}
+		return nil, ngsierr.New(funcName, 3, fmt.Sprintf("%s: %s is for %s, but %s is %s", file, item.kind, want, host, have), nil)
+	}
+
+	description, _ := body["description"].(string)
+	if label, ok := body["label"].(string); ok && label != "" {
+		delete(body, "label")
+		description = strings.TrimSpace("[" + label + "] " + strings.TrimSpace(strings.TrimPrefix(description, "["+label+"]")))
+		body["description"] = description
+	}
+	if description == "" {
+		return nil, ngsierr.New(funcName, 4, fmt.Sprintf("%s: %s without description or label", file, item.kind), nil)
+	}
+	item.key = description
+
+	deleteKeys(body, "id", "createdAt", "modifiedAt")
+
+	return item, nil
+}
+
+// applyMatchKey returns the key which matches a manifest and a broker item. It is the label if there is one.
+func applyMatchKey(description string) string {
+	if strings.HasPrefix(description, "[") {
+		if i := strings.Index(description, "]"); i > 1 {
+			return description[:i+1]
+		}
+	}
+	return description
+}
+
+func applyPath(client *ngsilib.Client, kind string) string {
+	if kind == "subscription" {
+		return "/subscriptions"
+	}
+	if client.IsNgsiLd() {
+		return "/csourceRegistrations"
+	}
+	return "/registrations"
+}
+
+// applyList gets the subscriptions or registrations of a broker
+func applyList(client *ngsilib.Client, kind string) ([]*applyItem, error) {
+	const funcName = "applyList"
+
+	page := 0
+	limit := 100
+
+	var items []*applyItem
+
+	for {
+		v := url.Values{}
+		if client.IsNgsiLd() {
+			client.SetPath(applyPath(client, kind) + "/")
+			v.Set("count", "true")
+		} else {
+			client.SetPath(applyPath(client, kind))
+			v.Set("options", "count")
+		}
+		v.Set("limit", fmt.Sprintf("%d", limit))
+		v.Set("offset", fmt.Sprintf("%d", page*limit))
+		client.SetQuery(&v)
+
+		res, body, err := client.HTTPGet()
+		if err != nil {
+			return nil, ngsierr.New(funcName, 1, err.Error(), err)
+		}
+		if res.StatusCode != http.StatusOK {
+			return nil, ngsierr.New(funcName, 2, fmt.Sprintf("%s %s", res.Status, string(body)), nil)
+		}
+		count, err := client.ResultsCount(res)
+		if err != nil {
+			return nil, ngsierr.New(funcName, 3, "ResultsCount error", err)
+		}
+		if count == 0 {
+			break
+		}
+		var list []map[string]interface{}
+		if err := ngsilib.JSONUnmarshalDecode(body, &list, client.IsSafeString()); err != nil {
+			return nil, ngsierr.New(funcName, 4, err.Error(), err)
+		}
+		for _, e := range list {
+			id, _ := e["id"].(string)
+			description, _ := e["description"].(string)
+			items = append(items, &applyItem{kind: kind, key: description, id: id, body: e})
+		}
+
+		if (page+1)*limit < count {
+			page = page + 1
+		} else {
+			break
+		}
+	}
+
+	return items, nil
+}
+
+// applyPlan matches the manifests of a kind with the items of a broker. With prune, the items which
+// have a description but match no manifest are deleted. Those without a description are never
+// managed by apply, so they are kept.
+func applyPlan(kind string, manifests, items []*applyItem, prune bool) []*applyStep {
+	have := map[string]*applyItem{}
+	for _, e := range items {
+		if e.key != "" {
+			if _, ok := have[applyMatchKey(e.key)]; !ok {
+				have[applyMatchKey(e.key)] = e
+			}
+		}
+	}
+
+	var steps []*applyStep
+	matched := map[*applyItem]bool{}
+
+	for _, m := range manifests {
+		if m.kind != kind {
+			continue
+		}
+		e, ok := have[applyMatchKey(m.key)]
+		if !ok {
+			steps = append(steps, &applyStep{op: "create", want: m})
+			continue
+		}
+		matched[e] = true
+		var changes []string
+		applyCompare("", m.body, e.body, &changes)
+		if len(changes) == 0 {
+			steps = append(steps, &applyStep{op: "unchanged", want: m, have: e})
+		} else {
+			steps = append(steps, &applyStep{op: "update", want: m, have: e, changes: changes})
+		}
+	}
+
+	if prune {
+		for _, e := range items {
+			if !matched[e] && e.key != "" {
+				steps = append(steps, &applyStep{op: "delete", have: e})
+			}
+		}
+	}
+
+	return steps
+}
+
+// applyCompare appends the differences of the attributes in want from those in have. The attributes which
+// are only in have, such as the statistics of notifications, are ignored. Timestamps which are the same
+// time in different forms, such as "2027-01-01T00:00:00Z" and "2027-01-01T00:00:00.000Z", are equal.
+func applyCompare(path string, want, have interface{}, changes *[]string) {
+	if w, ok := want.(map[string]interface{}); ok {
+		if h, ok := have.(map[string]interface{}); ok {
+			keys := make([]string, 0, len(w))
+			for k := range w {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			for _, k := range keys {
+				if k == "@context" {
+					continue
+				}
+				p := k
+				if path != "" {
+					p = path + "." + k
+				}
+				applyCompare(p, w[k], h[k], changes)
+			}
+			return
+		}
+	}
+	if w, ok := want.([]interface{}); ok {
+		if h, ok := have.([]interface{}); ok && len(w) == len(h) {
+			for i := range w {
+				applyCompare(fmt.Sprintf("%s[%d]", path, i), w[i], h[i], changes)
+			}
+			return
+		}
+	}
+	if !reflect.DeepEqual(want, have) && !applySameTime(want, have) {
+		*changes = append(*changes, fmt.Sprintf("%s: %s -> %s", path, applyString(have), applyString(want)))
+	}
+}
+
+func applySameTime(want, have interface{}) bool {
+	w, ok := want.(string)
+	if !ok {
+		return false
+	}
+	h, ok := have.(string)
+	if !ok {
+		return false
+	}
+	wt, err := time.Parse(time.RFC3339Nano, w)
+	if err != nil {
+		return false
+	}
+	ht, err := time.Parse(time.RFC3339Nano, h)
+	if err != nil {
+		return false
+	}
+	return wt.Equal(ht)
+}
+
+func applyString(v interface{}) string {
+	if v == nil {
+		return "(none)"
+	}
+	b, err := ngsilib.JSONMarshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
+
+func applySetContentType(client *ngsilib.Client, body map[string]interface{}) {
+	client.SetQuery(&url.Values{})
+	if _, ok := body["@context"]; ok {
+		client.SetContentLdJSON()
+		client.RemoveHeader("Link")
+	} else {
+		client.SetContentJSON()
+	}
+}
+
+func applyCreate(client *ngsilib.Client, item *applyItem) (string, error) {
+	const funcName = "applyCreate"
+
+	b, err := ngsilib.JSONMarshal(item.body)
+	if err != nil {
+		return "", ngsierr.New(funcName, 1, err.Error(), err)
+	}
+
+	client.SetPath(applyPath(client, item.kind))
+	applySetContentType(client, item.body)
+
+	res, body, err := client.HTTPPost(b)
+	if ngsilib.IsDryRun(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", ngsierr.New(funcName, 2, err.Error(), err)
+	}
+	if res.StatusCode != http.StatusCreated {
+		return "", ngsierr.New(funcName, 3, fmt.Sprintf("%s %q %s %s", item.file, item.key, res.Status, string(body)), nil)
+	}
+
+	location := res.Header.Get("Location")
+
+	return location[strings.LastIndex(location, "/")+1:], nil
+}
+
+// applyUpdate updates a subscription or an NGSI-LD registration. An NGSIv2 registration is replaced because
+// NGSIv2 brokers can't update it. When the new registration can't be created, the old one is created again.
+func applyUpdate(client *ngsilib.Client, want, have *applyItem) error {
+	const funcName = "applyUpdate"
+
+	if want.kind == "registration" && client.IsNgsiV2() {
+		if err := applyDelete(client, have); err != nil {
+			return ngsierr.New(funcName, 1, err.Error(), err)
+		}
+		if _, err := applyCreate(client, want); err != nil {
+			old := &applyItem{kind: have.kind, key: have.key, file: want.file, body: map[string]interface{}{}}
+			for k, v := range have.body {
+				old.body[k] = v
+			}
+			deleteKeys(old.body, "id", "forwardingInformation")
+			id, restoreErr := applyCreate(client, old)
+			if restoreErr != nil {
+				return ngsierr.New(funcName, 2, fmt.Sprintf("%s, and %s couldn't be restored: %s", err.Error(), have.id, restoreErr.Error()), err)
+			}
+			return ngsierr.New(funcName, 3, fmt.Sprintf("%s, and %s was restored as %s", err.Error(), have.id, id), err)
+		}
+		return nil
+	}
+
+	b, err := ngsilib.JSONMarshal(want.body)
+	if err != nil {
+		return ngsierr.New(funcName, 4, err.Error(), err)
+	}
+
+	client.SetPath(applyPath(client, want.kind) + "/" + have.id)
+	applySetContentType(client, want.body)
+
+	res, body, err := client.HTTPPatch(b)
+	if ngsilib.IsDryRun(err) {
+		return nil
+	}
+	if err != nil {
+		return ngsierr.New(funcName, 5, err.Error(), err)
+	}
+	if res.StatusCode != http.StatusNoContent {
+		return ngsierr.New(funcName, 6, fmt.Sprintf("%s %q %s %s", want.file, want.key, res.Status, string(body)), nil)
+	}
+
+	return nil
+}
+
+func applyDelete(client *ngsilib.Client, item *applyItem) error {
+	const funcName = "applyDelete"
+
+	client.SetPath(applyPath(client, item.kind) + "/" + item.id)
+	client.SetQuery(&url.Values{})
+
+	res, body, err := client.HTTPDelete(nil)
+	if ngsilib.IsDryRun(err) {
+		return nil
+	}
+	if err != nil {
+		return ngsierr.New(funcName, 1, err.Error(), err)
+	}
+	if res.StatusCode != http.StatusNoContent {
+		return ngsierr.New(funcName, 2, fmt.Sprintf("%s %s %s", item.id, res.Status, string(body)), nil)
+	}
+
+	return nil
+}
@@ -0,0 +1,700 @@
+/*
+MIT License
+
+Copyright (c) 2020-2024 Kazuhito Suda
+
+This file is part of NGSI Go
+
+https://github.com/lets-fiware/ngsi-go
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+
+*/
+
+package convenience
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/lets-fiware/ngsi-go/internal/assert"
+	"github.com/lets-fiware/ngsi-go/internal/helper"
+	"github.com/lets-fiware/ngsi-go/internal/ngsierr"
+)
+
+func applyTestManifests(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func applyTestList(path, body, count string) helper.MockHTTPReqRes {
+	reqRes := helper.MockHTTPReqRes{}
+	reqRes.Res.StatusCode = http.StatusOK
+	reqRes.ResBody = []byte(body)
+	reqRes.Path = path
+	if count != "" {
+		reqRes.ResHeader = http.Header{"Fiware-Total-Count": []string{count}, "Ngsild-Results-Count": []string{count}}
+	}
+	return reqRes
+}
+
+func applyTestReqRes(path string, status int, data string) helper.MockHTTPReqRes {
+	reqRes := helper.MockHTTPReqRes{}
+	reqRes.Res.StatusCode = status
+	reqRes.Path = path
+	if data != "" {
+		reqRes.ReqData = []byte(data)
+	}
+	return reqRes
+}
+
+const applyTestSubscriptionsV2 = `[
+{"id":"5f01","description":"[price] Notify price changes","subject":{"entities":[{"idPattern":".*","type":"Product"}]},"notification":{"http":{"url":"http://old:1028/n"},"timesSent":3},"status":"active"},
+{"id":"5f02","description":"stock","subject":{"entities":[{"idPattern":".*"}]},"notification":{"http":{"url":"http://stock/n"}},"status":"active"},
+{"id":"5f03","description":"unmanaged","subject":{"entities":[{"idPattern":".*"}]},"notification":{"http":{"url":"http://x/n"}},"status":"active"}
+]`
+
+const applyTestManifestYAML = `label: price
+description: Notify me of all product price changes
+subject:
+  entities:
+  - idPattern: .*
+    type: Product
+notification:
+  http:
+    url: http://new:1028/n
+---
+description: stock
+subject: {entities: [{idPattern: ".*"}]}
+notification: {http: {url: "http://stock/n"}}
+`
+
+const applyTestManifestJSON = `[
+{"description":"new","subject":{"entities":[{"idPattern":".*"}]},"notification":{"http":{"url":"http://new/n"}}},
+{"description":"weather","dataProvided":{"entities":[{"type":"Weather"}]},"provider":{"http":{"url":"http://provider/v2"}}}
+]`
+
+func TestApplyV2Diff(t *testing.T) {
+	dir := applyTestManifests(t, map[string]string{"a.yaml": applyTestManifestYAML, "b.json": applyTestManifestJSON, "README.md": "ignored"})
+	c := setupTest([]string{"apply", "--host", "orion", "--file", dir, "--diff", "--prune"})
+
+	reqRes1 := applyTestList("/v2/registrations", `[{"id":"5f10","description":"weather","dataProvided":{"entities":[{"type":"Weather"}]},"provider":{"http":{"url":"http://provider/v2"}}}]`, "1")
+	reqRes2 := applyTestList("/v2/subscriptions", applyTestSubscriptionsV2, "3")
+
+	helper.SetClientHTTP(c, reqRes1, reqRes2)
+
+	err := apply(c, c.Ngsi, c.Client)
+
+	if assert.NoError(t, err) {
+		actual := helper.GetStdoutString(c)
+		expected := "" +
+			"~ subscription \"[price] Notify me of all product price changes\" 5f01 (" + dir + "/a.yaml)\n" +
+			"    description: \"[price] Notify price changes\" -> \"[price] Notify me of all product price changes\"\n" +
+			"    notification.http.url: \"http://old:1028/n\" -> \"http://new:1028/n\"\n" +
+			"+ subscription \"new\" (" + dir + "/b.json)\n" +
+			"- subscription \"unmanaged\" 5f03\n" +
+			"plan: 1 to create, 1 to update, 1 to delete, 2 unchanged\n"
+		assert.Equal(t, expected, actual)
+	}
+}
+
+func TestApplyV2(t *testing.T) {
+	dir := applyTestManifests(t, map[string]string{"a.yml": applyTestManifestYAML, "b.json": applyTestManifestJSON})
+	c := setupTest([]string{"apply", "--host", "orion", "--file", dir, "--prune"})
+
+	reqRes1 := applyTestList("/v2/registrations", `[{"id":"5f10","description":"weather","dataProvided":{"entities":[{"type":"Weather"}]},"provider":{"http":{"url":"http://old/v2"}}}]`, "1")
+	reqRes2 := applyTestList("/v2/subscriptions", applyTestSubscriptionsV2, "3")
+	reqRes3 := applyTestReqRes("/v2/registrations/5f10", http.StatusNoContent, "")
+	reqRes4 := applyTestReqRes("/v2/registrations", http.StatusCreated, `{"dataProvided":{"entities":[{"type":"Weather"}]},"description":"weather","provider":{"http":{"url":"http://provider/v2"}}}`)
+	reqRes4.ResHeader = http.Header{"Location": []string{"/v2/registrations/5f11"}}
+	reqRes5 := applyTestReqRes("/v2/subscriptions/5f01", http.StatusNoContent, `{"description":"[price] Notify me of all product price changes","notification":{"http":{"url":"http://new:1028/n"}},"subject":{"entities":[{"idPattern":".*","type":"Product"}]}}`)
+	reqRes6 := applyTestReqRes("/v2/subscriptions", http.StatusCreated, `{"description":"new","notification":{"http":{"url":"http://new/n"}},"subject":{"entities":[{"idPattern":".*"}]}}`)
+	reqRes6.ResHeader = http.Header{"Location": []string{"/v2/subscriptions/5f04"}}
+	reqRes7 := applyTestReqRes("/v2/subscriptions/5f03", http.StatusNoContent, "")
+
+	helper.SetClientHTTP(c, reqRes1, reqRes2, reqRes3, reqRes4, reqRes5, reqRes6, reqRes7)
+
+	err := apply(c, c.Ngsi, c.Client)
+
+	if assert.NoError(t, err) {
+		actual := helper.GetStdoutString(c)
+		expected := "" +
+			"updated registration \"weather\" 5f10\n" +
+			"updated subscription \"[price] Notify me of all product price changes\" 5f01\n" +
+			"created subscription \"new\" 5f04\n" +
+			"deleted subscription \"unmanaged\" 5f03\n" +
+			"1 created, 2 updated, 1 deleted, 1 unchanged\n"
+		assert.Equal(t, expected, actual)
+	}
+}
+
+func TestApplyV2File(t *testing.T) {
+	c := setupTest([]string{"apply", "--host", "orion", "--file", "stock.yaml"})
+	c.Ngsi.Ioutil = &helper.MockIoutilLib{ReadFileData: []byte("description: stock\nsubject: {entities: [{idPattern: \".*\"}]}\nnotification: {http: {url: \"http://stock/n\"}}\n")}
+
+	reqRes1 := applyTestList("/v2/registrations", `[]`, "0")
+	reqRes2 := applyTestList("/v2/subscriptions", applyTestSubscriptionsV2, "3")
+
+	helper.SetClientHTTP(c, reqRes1, reqRes2)
+
+	err := apply(c, c.Ngsi, c.Client)
+
+	if assert.NoError(t, err) {
+		actual := helper.GetStdoutString(c)
+		expected := "0 created, 0 updated, 0 deleted, 1 unchanged\n"
+		assert.Equal(t, expected, actual)
+	}
+}
+
+func TestApplyV2Page(t *testing.T) {
+	c := setupTest([]string{"apply", "--host", "orion", "--file", "stock.json", "--diff", "--prune"})
+	c.Ngsi.Ioutil = &helper.MockIoutilLib{ReadFileData: []byte(`{"description":"stock","subject":{},"notification":{}}`)}
+
+	reqRes1 := applyTestList("/v2/registrations", `[]`, "0")
+	reqRes2 := applyTestList("/v2/subscriptions", `[{"id":"5f01","description":"a","subject":{},"notification":{}}]`, "101")
+	reqRes2.RawQuery = helper.StrPtr("limit=100&offset=0&options=count")
+	reqRes3 := applyTestList("/v2/subscriptions", `[{"id":"5f02","description":"stock","subject":{},"notification":{}}]`, "101")
+	reqRes3.RawQuery = helper.StrPtr("limit=100&offset=100&options=count")
+
+	helper.SetClientHTTP(c, reqRes1, reqRes2, reqRes3)
+
+	err := apply(c, c.Ngsi, c.Client)
+
+	if assert.NoError(t, err) {
+		actual := helper.GetStdoutString(c)
+		expected := "" +
+			"- subscription \"a\" 5f01\n" +
+			"= registrations not pruned: no registration in the manifests\n" +
+			"plan: 0 to create, 0 to update, 1 to delete, 1 unchanged\n"
+		assert.Equal(t, expected, actual)
+	}
+}
+
+func TestApplyPruneManagedOnly(t *testing.T) {
+	c := setupTest([]string{"apply", "--host", "orion", "--file", "stock.json", "--diff", "--prune"})
+	c.Ngsi.Ioutil = &helper.MockIoutilLib{ReadFileData: []byte(`{"description":"stock","subject":{},"notification":{}}`)}
+
+	reqRes1 := applyTestList("/v2/registrations", `[{"id":"5f10","description":"weather"},{"id":"5f11"}]`, "2")
+	reqRes2 := applyTestList("/v2/subscriptions", `[{"id":"5f01","subject":{},"notification":{}},{"id":"5f02","description":"stock","subject":{},"notification":{}},{"id":"5f03","description":"old","subject":{},"notification":{}}]`, "3")
+
+	helper.SetClientHTTP(c, reqRes1, reqRes2)
+
+	err := apply(c, c.Ngsi, c.Client)
+
+	if assert.NoError(t, err) {
+		actual := helper.GetStdoutString(c)
+		expected := "" +
+			"- subscription \"old\" 5f03\n" +
+			"= registrations not pruned: no registration in the manifests\n" +
+			"plan: 0 to create, 0 to update, 1 to delete, 1 unchanged\n"
+		assert.Equal(t, expected, actual)
+	}
+}
+
+func TestApplyLd(t *testing.T) {
+	c := setupTest([]string{"apply", "--host", "orion-ld", "--file", "sub.yaml"})
+	c.Ngsi.Ioutil = &helper.MockIoutilLib{ReadFileData: []byte(`type: Subscription
+description: temperature
+entities:
+- type: Sensor
+notification:
+  endpoint:
+    uri: http://new/n
+"@context": https://fiware.github.io/data-models/context.jsonld
+---
+type: ContextSourceRegistration
+description: weather
+information:
+- entities:
+  - type: Weather
+endpoint: http://provider
+`)}
+
+	reqRes1 := applyTestList("/ngsi-ld/v1/csourceRegistrations/", `[]`, "0")
+	reqRes1.RawQuery = helper.StrPtr("count=true&limit=100&offset=0")
+	reqRes2 := applyTestList("/ngsi-ld/v1/subscriptions/", `[{"id":"urn:ngsi-ld:Subscription:1","type":"Subscription","description":"temperature","entities":[{"type":"Sensor"}],"notification":{"endpoint":{"uri":"http://old/n"},"status":"ok"},"isActive":true}]`, "1")
+	reqRes3 := applyTestReqRes("/ngsi-ld/v1/csourceRegistrations", http.StatusCreated, `{"description":"weather","endpoint":"http://provider","information":[{"entities":[{"type":"Weather"}]}],"type":"ContextSourceRegistration"}`)
+	reqRes3.ResHeader = http.Header{"Location": []string{"/ngsi-ld/v1/csourceRegistrations/urn:ngsi-ld:ContextSourceRegistration:1"}}
+	reqRes4 := applyTestReqRes("/ngsi-ld/v1/subscriptions/urn:ngsi-ld:Subscription:1", http.StatusNoContent, `{"@context":"https://fiware.github.io/data-models/context.jsonld","description":"temperature","entities":[{"type":"Sensor"}],"notification":{"endpoint":{"uri":"http://new/n"}},"type":"Subscription"}`)
+
+	helper.SetClientHTTP(c, reqRes1, reqRes2, reqRes3, reqRes4)
+
+	err := apply(c, c.Ngsi, c.Client)
+
+	if assert.NoError(t, err) {
+		actual := helper.GetStdoutString(c)
+		expected := "" +
+			"created registration \"weather\" urn:ngsi-ld:ContextSourceRegistration:1\n" +
+			"updated subscription \"temperature\" urn:ngsi-ld:Subscription:1\n" +
+			"1 created, 1 updated, 0 deleted, 0 unchanged\n"
+		assert.Equal(t, expected, actual)
+		assert.Equal(t, "application/ld+json", c.Client.Headers["Content-Type"])
+	}
+}
+
+func TestApplyDryRun(t *testing.T) {
+	c := setupTest([]string{"--dryRun", "apply", "--host", "orion", "--file", "new.json", "--prune"})
+	c.Ngsi.Ioutil = &helper.MockIoutilLib{ReadFileData: []byte(`{"description":"new","subject":{},"notification":{"attrs":["a"]}}`)}
+
+	reqRes1 := applyTestList("/v2/registrations", `[{"id":"5f10","description":"old"}]`, "1")
+	reqRes2 := applyTestList("/v2/subscriptions", `[{"id":"5f01","description":"new","subject":{},"notification":{"http":{"url":"http://x"}}},{"id":"5f02","description":"gone"}]`, "2")
+
+	helper.SetClientHTTP(c, reqRes1, reqRes2)
+
+	err := apply(c, c.Ngsi, c.Client)
+
+	if assert.NoError(t, err) {
+		actual := helper.GetStdoutString(c)
+		assert.Equal(t, true, strings.Contains(actual, "DELETE https://orion/v2/subscriptions/5f02"))
+		assert.Equal(t, true, strings.Contains(actual, "PATCH "))
+		assert.Equal(t, true, strings.Contains(actual, "updated subscription \"new\" 5f01\n"))
+		assert.Equal(t, true, strings.HasSuffix(actual, "deleted subscription \"gone\" 5f02\n0 created, 1 updated, 1 deleted, 0 unchanged\n"))
+	}
+}
+
+func TestApplyDryRunCreate(t *testing.T) {
+	c := setupTest([]string{"--dryRun", "apply", "--host", "orion", "--file", "new.json"})
+	c.Ngsi.Ioutil = &helper.MockIoutilLib{ReadFileData: []byte(`{"description":"new","subject":{},"notification":{}}`)}
+
+	reqRes1 := applyTestList("/v2/registrations", `[]`, "0")
+	reqRes2 := applyTestList("/v2/subscriptions", `[]`, "0")
+
+	helper.SetClientHTTP(c, reqRes1, reqRes2)
+
+	err := apply(c, c.Ngsi, c.Client)
+
+	if assert.NoError(t, err) {
+		actual := helper.GetStdoutString(c)
+		assert.Equal(t, true, strings.Contains(actual, "POST "))
+		assert.Equal(t, true, strings.Contains(actual, "created subscription \"new\"\n1 created, 0 updated, 0 deleted, 0 unchanged\n"))
+	}
+}
+
+func TestApplyErrorManifest(t *testing.T) {
+	c := setupTest([]string{"apply", "--host", "orion", "--file", "a.json"})
+	c.Ngsi.Ioutil = &helper.MockIoutilLib{ReadFileErr: errors.New("read error")}
+
+	err := apply(c, c.Ngsi, c.Client)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 1, ngsiErr.ErrNo)
+		assert.Equal(t, "read error", ngsiErr.Message)
+	}
+}
+
+func TestApplyErrorList(t *testing.T) {
+	c := setupTest([]string{"apply", "--host", "orion", "--file", "a.json"})
+	c.Ngsi.Ioutil = &helper.MockIoutilLib{ReadFileData: []byte(`[]`)}
+
+	reqRes := helper.MockHTTPReqRes{}
+	reqRes.Path = "/v2/registrations"
+	reqRes.Err = errors.New("http error")
+
+	helper.SetClientHTTP(c, reqRes)
+
+	err := apply(c, c.Ngsi, c.Client)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 2, ngsiErr.ErrNo)
+		assert.Equal(t, "http error", ngsiErr.Message)
+	}
+}
+
+func TestApplyErrorCreate(t *testing.T) {
+	c := setupTest([]string{"apply", "--host", "orion", "--file", "a.json"})
+	c.Ngsi.Ioutil = &helper.MockIoutilLib{ReadFileData: []byte(`{"description":"new","subject":{},"notification":{}}`)}
+
+	reqRes1 := applyTestList("/v2/registrations", `[]`, "0")
+	reqRes2 := applyTestList("/v2/subscriptions", `[]`, "0")
+	reqRes3 := applyTestReqRes("/v2/subscriptions", http.StatusBadRequest, "")
+	reqRes3.Res.Status = "400 Bad Request"
+	reqRes3.ResBody = []byte("error")
+
+	helper.SetClientHTTP(c, reqRes1, reqRes2, reqRes3)
+
+	err := apply(c, c.Ngsi, c.Client)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 3, ngsiErr.ErrNo)
+		assert.Equal(t, "a.json \"new\" 400 Bad Request error", ngsiErr.Message)
+	}
+}
+
+func TestApplyErrorUpdate(t *testing.T) {
+	c := setupTest([]string{"apply", "--host", "orion", "--file", "a.json"})
+	c.Ngsi.Ioutil = &helper.MockIoutilLib{ReadFileData: []byte(`{"description":"new","subject":{},"notification":{"attrs":["a"]}}`)}
+
+	reqRes1 := applyTestList("/v2/registrations", `[]`, "0")
+	reqRes2 := applyTestList("/v2/subscriptions", `[{"id":"5f01","description":"new","subject":{},"notification":{}}]`, "1")
+	reqRes3 := applyTestReqRes("/v2/subscriptions/5f01", http.StatusBadRequest, "")
+	reqRes3.Res.Status = "400 Bad Request"
+	reqRes3.ResBody = []byte("error")
+
+	helper.SetClientHTTP(c, reqRes1, reqRes2, reqRes3)
+
+	err := apply(c, c.Ngsi, c.Client)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 4, ngsiErr.ErrNo)
+		assert.Equal(t, "a.json \"new\" 400 Bad Request error", ngsiErr.Message)
+	}
+}
+
+func TestApplyErrorDelete(t *testing.T) {
+	c := setupTest([]string{"apply", "--host", "orion", "--file", "a.json", "--prune"})
+	c.Ngsi.Ioutil = &helper.MockIoutilLib{ReadFileData: []byte(`[{"description":"keep","dataProvided":{},"provider":{}}]`)}
+
+	reqRes1 := applyTestList("/v2/registrations", `[{"id":"5f11","description":"keep","dataProvided":{},"provider":{}},{"id":"5f10","description":"old"}]`, "2")
+	reqRes2 := applyTestList("/v2/subscriptions", `[]`, "0")
+	reqRes3 := applyTestReqRes("/v2/registrations/5f10", http.StatusNotFound, "")
+	reqRes3.Res.Status = "404 Not Found"
+	reqRes3.ResBody = []byte("error")
+
+	helper.SetClientHTTP(c, reqRes1, reqRes2, reqRes3)
+
+	err := apply(c, c.Ngsi, c.Client)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 5, ngsiErr.ErrNo)
+		assert.Equal(t, "5f10 404 Not Found error", ngsiErr.Message)
+	}
+}
+
+func TestApplyReadManifestsError(t *testing.T) {
+	dir := applyTestManifests(t, map[string]string{
+		"a.json":   `{"description":"a","subject":{},"notification":{}}`,
+		"b.yaml":   "description: a\nsubject: {}\nnotification: {}\n",
+		"bad.json": `{`,
+		"bad.yml":  "a: [",
+	})
+
+	cases := []struct {
+		path     string
+		errno    int
+		expected string
+	}{
+		{path: filepath.Join(dir, "bad.json"), errno: 4},
+		{path: filepath.Join(dir, "bad.yml"), errno: 5, expected: filepath.Join(dir, "bad.yml") + ": line 1: unexpected end of flow collection"},
+		{path: filepath.Join(dir, "b.yaml"), errno: 6, expected: filepath.Join(dir, "b.yaml") + ": subscription is for NGSIv2, but orion-ld is NGSI-LD"},
+		{path: filepath.Join(dir, "none"), errno: 2, expected: "no manifest found in " + filepath.Join(dir, "none")},
+	}
+
+	for _, c := range cases {
+		ngsi := setupTest([]string{"apply", "--host", "orion", "--file", c.path}).Ngsi
+
+		_, err := applyReadManifests(ngsi, c.path, c.errno == 6, "orion-ld")
+
+		if assert.Error(t, err) {
+			ngsiErr := err.(*ngsierr.NgsiError)
+			assert.Equal(t, c.errno, ngsiErr.ErrNo)
+			if c.expected != "" {
+				assert.Equal(t, c.expected, ngsiErr.Message)
+			}
+		}
+	}
+}
+
+func TestApplyReadManifestsErrorDuplicate(t *testing.T) {
+	dir := applyTestManifests(t, map[string]string{
+		"a.json": `{"label":"x","description":"a","subject":{},"notification":{}}`,
+		"b.yaml": "description: '[x] b'\nsubject: {}\nnotification: {}\n",
+	})
+	c := setupTest([]string{"apply", "--host", "orion", "--file", dir})
+
+	_, err := applyReadManifests(c.Ngsi, dir, false, "orion")
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 7, ngsiErr.ErrNo)
+		assert.Equal(t, filepath.Join(dir, "b.yaml")+": subscription \"[x] b\" is also defined in "+filepath.Join(dir, "a.json"), ngsiErr.Message)
+	}
+}
+
+func TestApplyReadManifestsErrorGlob(t *testing.T) {
+	c := setupTest([]string{"apply", "--host", "orion", "--file", "dir"})
+	c.Ngsi.FilePath = &helper.MockFilePathLib{GlobErr: errors.New("glob error")}
+
+	_, err := applyReadManifests(c.Ngsi, "dir", false, "orion")
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 1, ngsiErr.ErrNo)
+		assert.Equal(t, "glob error", ngsiErr.Message)
+	}
+}
+
+func TestApplyManifest(t *testing.T) {
+	cases := []struct {
+		v    interface{}
+		ld   bool
+		kind string
+		key  string
+	}{
+		{v: map[string]interface{}{"type": "Subscription", "description": "a", "id": "urn:1"}, ld: true, kind: "subscription", key: "a"},
+		{v: map[string]interface{}{"type": "ContextSourceRegistration", "label": "r"}, ld: true, kind: "registration", key: "[r]"},
+		{v: map[string]interface{}{"provider": map[string]interface{}{}, "label": "r", "description": "[r] b"}, kind: "registration", key: "[r] b"},
+		{v: map[string]interface{}{"subject": map[string]interface{}{}, "label": "s", "description": "c"}, kind: "subscription", key: "[s] c"},
+	}
+
+	for _, c := range cases {
+		item, err := applyManifest("a.yaml", c.v, c.ld, "orion")
+
+		if assert.NoError(t, err) {
+			assert.Equal(t, c.kind, item.kind)
+			assert.Equal(t, c.key, item.key)
+			assert.Equal(t, nil, item.body["label"])
+			assert.Equal(t, nil, item.body["id"])
+		}
+	}
+}
+
+func TestApplyManifestError(t *testing.T) {
+	cases := []struct {
+		v        interface{}
+		ld       bool
+		errno    int
+		expected string
+	}{
+		{v: "a", errno: 1, expected: "a.yaml: manifest must be a JSON object"},
+		{v: map[string]interface{}{"description": "a"}, errno: 2, expected: "a.yaml: neither subscription nor registration"},
+		{v: map[string]interface{}{"type": "Subscription", "description": "a"}, errno: 3, expected: "a.yaml: subscription is for NGSI-LD, but orion is NGSIv2"},
+		{v: map[string]interface{}{"notification": map[string]interface{}{}}, errno: 4, expected: "a.yaml: subscription without description or label"},
+	}
+
+	for _, c := range cases {
+		_, err := applyManifest("a.yaml", c.v, c.ld, "orion")
+
+		if assert.Error(t, err) {
+			ngsiErr := err.(*ngsierr.NgsiError)
+			assert.Equal(t, c.errno, ngsiErr.ErrNo)
+			assert.Equal(t, c.expected, ngsiErr.Message)
+		}
+	}
+}
+
+func TestApplyCompare(t *testing.T) {
+	want := map[string]interface{}{
+		"@context": "ctx",
+		"a":        []interface{}{float64(1), map[string]interface{}{"b": "c"}},
+		"d":        []interface{}{"e"},
+		"f":        map[string]interface{}{"g": true},
+		"h":        "i",
+		"j":        "2027-01-01T00:00:00Z",
+		"k":        "2027-01-01T00:00:00Z",
+		"l":        "2027",
+	}
+	have := map[string]interface{}{
+		"a": []interface{}{float64(2), map[string]interface{}{"b": "c", "x": "y"}},
+		"d": []interface{}{"e", "f"},
+		"f": "g",
+		"j": "2027-01-01T00:00:00.000Z",
+		"k": "2027-01-01T00:00:01.000Z",
+		"l": "2027",
+		"z": "ignored",
+	}
+
+	var changes []string
+	applyCompare("", want, have, &changes)
+
+	expected := []string{
+		`a[0]: 2 -> 1`,
+		`d: ["e","f"] -> ["e"]`,
+		`f: "g" -> {"g":true}`,
+		`h: (none) -> "i"`,
+		`k: "2027-01-01T00:00:01.000Z" -> "2027-01-01T00:00:00Z"`,
+	}
+	assert.Equal(t, expected, changes)
+}
+
+func TestApplySameTime(t *testing.T) {
+	assert.Equal(t, true, applySameTime("2027-01-01T09:00:00+09:00", "2027-01-01T00:00:00.000Z"))
+	assert.Equal(t, false, applySameTime("2027-01-01T00:00:00Z", float64(1)))
+	assert.Equal(t, false, applySameTime(float64(1), "2027-01-01T00:00:00Z"))
+	assert.Equal(t, false, applySameTime("2027-01-01T00:00:00Z", "2027"))
+}
+
+func TestApplyString(t *testing.T) {
+	assert.Equal(t, "(none)", applyString(nil))
+	assert.Equal(t, `"a"`, applyString("a"))
+	assert.Equal(t, "(0+0i)", applyString(complex(0, 0)))
+}
+
+func TestApplyListError(t *testing.T) {
+	cases := []struct {
+		reqRes   helper.MockHTTPReqRes
+		errno    int
+		expected string
+	}{
+		{reqRes: helper.MockHTTPReqRes{Path: "/v2/subscriptions", Err: errors.New("http error")}, errno: 1, expected: "http error"},
+		{reqRes: helper.MockHTTPReqRes{Path: "/v2/subscriptions", Res: http.Response{StatusCode: http.StatusBadRequest, Status: "400 Bad Request"}, ResBody: []byte("error")}, errno: 2, expected: "400 Bad Request error"},
+		{reqRes: applyTestList("/v2/subscriptions", `[]`, ""), errno: 3, expected: "ResultsCount error"},
+		{reqRes: applyTestList("/v2/subscriptions", `{}`, "1"), errno: 4},
+	}
+
+	for _, tc := range cases {
+		c := setupTest([]string{"apply", "--host", "orion", "--file", "a.json"})
+		helper.SetClientHTTP(c, tc.reqRes)
+
+		_, err := applyList(c.Client, "subscription")
+
+		if assert.Error(t, err) {
+			ngsiErr := err.(*ngsierr.NgsiError)
+			assert.Equal(t, tc.errno, ngsiErr.ErrNo)
+			if tc.expected != "" {
+				assert.Equal(t, tc.expected, ngsiErr.Message)
+			}
+		}
+	}
+}
+
+func TestApplyCreateError(t *testing.T) {
+	c := setupTest([]string{"apply", "--host", "orion", "--file", "a.json"})
+
+	reqRes := helper.MockHTTPReqRes{Path: "/v2/subscriptions", Err: errors.New("http error")}
+	helper.SetClientHTTP(c, reqRes)
+
+	item := &applyItem{kind: "subscription", body: map[string]interface{}{}}
+	_, err := applyCreate(c.Client, item)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 2, ngsiErr.ErrNo)
+		assert.Equal(t, "http error", ngsiErr.Message)
+	}
+
+	item.body["x"] = complex(0, 0)
+	_, err = applyCreate(c.Client, item)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 1, ngsiErr.ErrNo)
+	}
+}
+
+func TestApplyUpdateRegistrationLd(t *testing.T) {
+	c := setupTest([]string{"apply", "--host", "orion-ld", "--file", "a.json"})
+
+	have := &applyItem{kind: "registration", id: "urn:ngsi-ld:ContextSourceRegistration:1"}
+	want := &applyItem{kind: "registration", body: map[string]interface{}{"description": "weather", "endpoint": "http://new"}}
+
+	reqRes := applyTestReqRes("/ngsi-ld/v1/csourceRegistrations/urn:ngsi-ld:ContextSourceRegistration:1", http.StatusNoContent, `{"description":"weather","endpoint":"http://new"}`)
+	helper.SetClientHTTP(c, reqRes)
+
+	err := applyUpdate(c.Client, want, have)
+
+	assert.NoError(t, err)
+}
+
+func TestApplyUpdateRegistrationRestore(t *testing.T) {
+	c := setupTest([]string{"apply", "--host", "orion", "--file", "a.json"})
+
+	have := &applyItem{kind: "registration", key: "weather", id: "5f10", body: map[string]interface{}{
+		"id":                    "5f10",
+		"description":           "weather",
+		"provider":              map[string]interface{}{"http": map[string]interface{}{"url": "http://old"}},
+		"forwardingInformation": map[string]interface{}{"timesSent": float64(3)},
+	}}
+	want := &applyItem{kind: "registration", key: "weather", file: "a.json", body: map[string]interface{}{"description": "weather", "provider": "invalid"}}
+
+	reqRes1 := applyTestReqRes("/v2/registrations/5f10", http.StatusNoContent, "")
+	reqRes2 := applyTestReqRes("/v2/registrations", http.StatusBadRequest, "")
+	reqRes2.Res.Status = "400 Bad Request"
+	reqRes2.ResBody = []byte("error")
+	reqRes3 := applyTestReqRes("/v2/registrations", http.StatusCreated, `{"description":"weather","provider":{"http":{"url":"http://old"}}}`)
+	reqRes3.ResHeader = http.Header{"Location": []string{"/v2/registrations/5f11"}}
+	helper.SetClientHTTP(c, reqRes1, reqRes2, reqRes3)
+
+	err := applyUpdate(c.Client, want, have)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 3, ngsiErr.ErrNo)
+		assert.Equal(t, "a.json \"weather\" 400 Bad Request error, and 5f10 was restored as 5f11", ngsiErr.Message)
+	}
+}
+
+func TestApplyUpdateError(t *testing.T) {
+	c := setupTest([]string{"apply", "--host", "orion", "--file", "a.json"})
+
+	have := &applyItem{kind: "registration", id: "5f10"}
+	want := &applyItem{kind: "registration", body: map[string]interface{}{}}
+
+	reqRes1 := helper.MockHTTPReqRes{Path: "/v2/registrations/5f10", Err: errors.New("delete error")}
+	reqRes2 := applyTestReqRes("/v2/registrations/5f10", http.StatusNoContent, "")
+	reqRes3 := helper.MockHTTPReqRes{Path: "/v2/registrations", Err: errors.New("create error")}
+	reqRes4 := helper.MockHTTPReqRes{Path: "/v2/registrations", Err: errors.New("restore error")}
+	reqRes5 := helper.MockHTTPReqRes{Path: "/v2/subscriptions/5f01", Err: errors.New("patch error")}
+	helper.SetClientHTTP(c, reqRes1, reqRes2, reqRes3, reqRes4, reqRes5)
+
+	err := applyUpdate(c.Client, want, have)
+	if assert.Error(t, err) {
+		assert.Equal(t, 1, err.(*ngsierr.NgsiError).ErrNo)
+	}
+
+	err = applyUpdate(c.Client, want, have)
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 2, ngsiErr.ErrNo)
+		assert.Equal(t, "create error, and 5f10 couldn't be restored: restore error", ngsiErr.Message)
+	}
+
+	want = &applyItem{kind: "subscription", body: map[string]interface{}{"x": complex(0, 0)}}
+	have = &applyItem{kind: "subscription", id: "5f01"}
+	err = applyUpdate(c.Client, want, have)
+	if assert.Error(t, err) {
+		assert.Equal(t, 4, err.(*ngsierr.NgsiError).ErrNo)
+	}
+
+	want.body = map[string]interface{}{}
+	err = applyUpdate(c.Client, want, have)
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 5, ngsiErr.ErrNo)
+		assert.Equal(t, "patch error", ngsiErr.Message)
+	}
+}
+
+func TestApplyDeleteError(t *testing.T) {
+	c := setupTest([]string{"apply", "--host", "orion", "--file", "a.json"})
+
+	reqRes := helper.MockHTTPReqRes{Path: "/v2/subscriptions/5f01", Err: errors.New("http error")}
+	helper.SetClientHTTP(c, reqRes)
+
+	err := applyDelete(c.Client, &applyItem{kind: "subscription", id: "5f01"})
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 1, ngsiErr.ErrNo)
+		assert.Equal(t, "http error", ngsiErr.Message)
+	}
+}
@@ -47,16 +47,21 @@ var ConvenienceApp = &ngsicli.App{
 	Commands: []*ngsicli.Command{
 		&AdminCmd,
 		&ApisCmd,
+		&ApplyCmd,
 		&DebugCmd,
 		&CopyCmd,
 		&DocumentsCmd,
+		&ExportCmd,
 		&HealthCmd,
+		&ImportCmd,
 		&QueryProxyCmd,
 		&ReceiverCmd,
 		&RegProxyCmd,
 		&RemoveCmd,
+		&ShellCmd,
 		&TokenProxyCmd,
 		&VersionCmd,
+		&WatchCmd,
 	},
 }
 
@@ -190,6 +195,11 @@ var CopyCmd = ngsicli.Command{
 		context2Flag,
 		ngsiV1Flag,
 		skipForwardingFlag,
+		multiAttrFlag,
+		mapFlag,
+		checkpointFlag,
+		resumeFlag,
+		ngsicli.ParallelFlag,
 		ngsicli.RunFlag,
 	},
 	RequiredFlags: []string{"type"},
@@ -232,6 +242,26 @@ var DocumentsCmd = ngsicli.Command{
 	},
 }
 
+var ExportCmd = ngsicli.Command{
+	Name:       "export",
+	Usage:      "export entities, subscriptions and registrations to archive",
+	Category:   "CONVENIENCE",
+	ServerList: []string{"brokerv2", "brokerld"},
+	Flags: []ngsicli.Flag{
+		ngsicli.HostRFlag,
+		ngsicli.OAuthTokenFlag,
+		ngsicli.TenantFlag,
+		ngsicli.ScopeFlag,
+		linkFlag,
+		exportTypeFlag,
+		archiveFileRFlag,
+	},
+	RequiredFlags: []string{"file"},
+	Action: func(c *ngsicli.Context, ngsi *ngsilib.NGSI, client *ngsilib.Client) error {
+		return export(c, ngsi, client)
+	},
+}
+
 var HealthCmd = ngsicli.Command{
 	Name:       "health",
 	Usage:      "print health status",
@@ -246,6 +276,47 @@ var HealthCmd = ngsicli.Command{
 	},
 }
 
+var ApplyCmd = ngsicli.Command{
+	Name:       "apply",
+	Usage:      "reconcile subscriptions and registrations with manifests",
+	Category:   "CONVENIENCE",
+	ServerList: []string{"brokerv2", "brokerld"},
+	Flags: []ngsicli.Flag{
+		ngsicli.HostRFlag,
+		ngsicli.OAuthTokenFlag,
+		ngsicli.TenantFlag,
+		ngsicli.ScopeFlag,
+		linkFlag,
+		applyFileRFlag,
+		applyPruneFlag,
+		applyDiffFlag,
+		ngsicli.SafeStringFlag,
+	},
+	RequiredFlags: []string{"file"},
+	Action: func(c *ngsicli.Context, ngsi *ngsilib.NGSI, client *ngsilib.Client) error {
+		return apply(c, ngsi, client)
+	},
+}
+
+var ImportCmd = ngsicli.Command{
+	Name:       "import",
+	Usage:      "import entities, subscriptions and registrations from archive",
+	Category:   "CONVENIENCE",
+	ServerList: []string{"brokerv2", "brokerld"},
+	Flags: []ngsicli.Flag{
+		ngsicli.HostRFlag,
+		ngsicli.OAuthTokenFlag,
+		ngsicli.TenantFlag,
+		ngsicli.ScopeFlag,
+		archiveFileRFlag,
+		importReplaceURLFlag,
+	},
+	RequiredFlags: []string{"file"},
+	Action: func(c *ngsicli.Context, ngsi *ngsilib.NGSI, client *ngsilib.Client) error {
+		return importArchive(c, ngsi, client)
+	},
+}
+
 var QueryProxyCmd = ngsicli.Command{
 	Name:     "queryproxy",
 	Category: "CONVENIENCE",
@@ -298,6 +369,14 @@ var ReceiverCmd = ngsicli.Command{
 		receiverCertFlag,
 		ngsicli.VerboseFlag,
 		headerFlag,
+		receiverStoreFlag,
+		receiverStatusFlag,
+		receiverDelayFlag,
+		receiverForwardFlag,
+		receiverReplayFlag,
+		receiverExpectFlag,
+		receiverCountFlag,
+		receiverDeadlineFlag,
 	},
 	Action: func(c *ngsicli.Context, ngsi *ngsilib.NGSI, client *ngsilib.Client) error {
 		return receiver(c, ngsi, client)
@@ -377,6 +456,7 @@ var RemoveCmd = ngsicli.Command{
 		linkFlag,
 		ngsiV1Flag,
 		skipForwardingFlag,
+		ngsicli.ParallelFlag,
 		ngsicli.RunFlag,
 	},
 	RequiredFlags: []string{"type"},
@@ -433,6 +513,21 @@ var ScorpioCmd = ngsicli.Command{
 	},
 }
 
+var ShellCmd = ngsicli.Command{
+	Name:     "shell",
+	Usage:    "start interactive shell",
+	Category: "CONVENIENCE",
+	Flags: []ngsicli.Flag{
+		ngsicli.HostFlag,
+		ngsicli.TenantFlag,
+		ngsicli.ScopeFlag,
+		shellHistoryFileFlag,
+	},
+	Action: func(c *ngsicli.Context, ngsi *ngsilib.NGSI, client *ngsilib.Client) error {
+		return shell(c, ngsi, client)
+	},
+}
+
 var TokenProxyCmd = ngsicli.Command{
 	Name:     "tokenproxy",
 	Category: "CONVENIENCE",
@@ -484,3 +579,34 @@ var VersionCmd = ngsicli.Command{
 		return cbVersion(c, ngsi, client)
 	},
 }
+
+var WatchCmd = ngsicli.Command{
+	Name:       "watch",
+	Usage:      "print changes to entities as they happen",
+	Category:   "CONVENIENCE",
+	ServerList: []string{"brokerv2", "brokerld"},
+	Flags: []ngsicli.Flag{
+		ngsicli.HostRFlag,
+		ngsicli.OAuthTokenFlag,
+		ngsicli.TenantFlag,
+		ngsicli.ScopeFlag,
+		linkFlag,
+		typeRFlag,
+		watchIDFlag,
+		watchIDPatternFlag,
+		watchQueryFlag,
+		watchAttrsFlag,
+		watchPortFlag,
+		watchURLFlag,
+		watchCallbackHostFlag,
+		watchPollFlag,
+		watchIntervalFlag,
+		watchProbeFlag,
+		ngsicli.PrettyFlag,
+		ngsicli.VerboseFlag,
+	},
+	RequiredFlags: []string{"type"},
+	Action: func(c *ngsicli.Context, ngsi *ngsilib.NGSI, client *ngsilib.Client) error {
+		return watch(c, ngsi, client)
+	},
+}
@@ -66,9 +66,12 @@ func TestNGSICommand(t *testing.T) {
 		{args: []string{"admin", "scorpio", "stats", "--host", "scorpio"}, rc: 1},
 		{args: []string{"admin", "scorpio", "health", "--host", "scorpio"}, rc: 1},
 		{args: []string{"apis", "--host", "orion"}, rc: 1},
+		{args: []string{"apply", "--host", "orion", "--file", "/tmp/ngsi-go-test-notfound"}, rc: 1},
 		{args: []string{"cp", "--type", "abc", "--host", "orion", "--host2", "orion-ld", "--type", "device"}, rc: 1},
 		{args: []string{"debug", "--host", "orion"}, rc: 0},
+		{args: []string{"export", "--host", "orion", "--file", "/tmp/ngsi-go-test-export.zip"}, rc: 1},
 		{args: []string{"health", "--host", "orion"}, rc: 1},
+		{args: []string{"import", "--host", "orion", "--file", "/tmp/ngsi-go-test-notfound.zip"}, rc: 1},
 		{args: []string{"man"}, rc: 0},
 		{args: []string{"queryproxy", "server", "--host", "orion", "--https"}, rc: 1},
 		{args: []string{"queryproxy", "health", "--host", "queryproxy"}, rc: 1},
@@ -77,6 +80,7 @@ func TestNGSICommand(t *testing.T) {
 		{args: []string{"regproxy", "health", "--host", "regproxy"}, rc: 1},
 		{args: []string{"regproxy", "config", "--host", "regproxy"}, rc: 1},
 		{args: []string{"rm", "--host", "orion", "--type", "abc"}, rc: 1},
+		{args: []string{"shell", "--host", "unknown"}, rc: 1},
 		{args: []string{"tokenproxy", "server", "--https"}, rc: 1},
 		{args: []string{"tokenproxy", "health", "--host", "tokenproxy"}, rc: 1},
 		{args: []string{"version", "--host", "orion"}, rc: 1},
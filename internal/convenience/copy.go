@@ -73,7 +73,7 @@ func copy(c *ngsicli.Context, ngsi *ngsilib.NGSI, client *ngsilib.Client) error
 		destination.Headers["Fiware-ServicePath"] = "/"
 	}
 
-	var f func(c *ngsicli.Context, ngsi *ngsilib.NGSI, source, destination *ngsilib.Client, entityType string) error
+	var f func(c *ngsicli.Context, ngsi *ngsilib.NGSI, source, destination *ngsilib.Client, entityType string, mapping *copyMapping, status *copyStatus) error
 
 	if source.IsNgsiV2() && destination.IsNgsiV2() {
 		if c.Bool("ngsiV1") {
@@ -86,209 +86,310 @@ func copy(c *ngsicli.Context, ngsi *ngsilib.NGSI, client *ngsilib.Client) error
 	} else if source.IsNgsiLd() && destination.IsNgsiLd() {
 		f = copyLDLD
 	} else {
-		return ngsierr.New(funcName, 3, "cannot copy entities from NGSI-LD to NGSI v2", err)
+		f = copyLDV2
 	}
 
-	entities := strings.Split(c.String("type"), ",")
+	if !ngsilib.Contains([]string{"", "default", "suffix", "error"}, c.String("multiAttr")) {
+		return ngsierr.New(funcName, 3, "multiAttr error: "+c.String("multiAttr"), nil)
+	}
+
+	if _, err = ngsicli.ParallelWorkers(c); err != nil {
+		return ngsierr.New(funcName, 4, err.Error(), err)
+	}
+
+	mapping, err := newCopyMapping(c, ngsi)
+	if err != nil {
+		return ngsierr.New(funcName, 5, err.Error(), err)
+	}
+
+	status, err := newCopyStatus(c, ngsi)
+	if err != nil {
+		return ngsierr.New(funcName, 6, err.Error(), err)
+	}
+
+	entities, err := status.resumeTypes(strings.Split(c.String("type"), ","))
+	if err != nil {
+		return ngsierr.New(funcName, 7, err.Error(), err)
+	}
+
+	summary := c.IsSet("run") && !destination.DryRun
 
 	for _, e := range entities {
-		err = f(c, ngsi, source, destination, e)
+		err = f(c, ngsi, source, destination, e, mapping, status)
 		if err != nil {
-			return ngsierr.New(funcName, 4, err.Error(), err)
+			if summary {
+				status.printSummary(ngsi)
+			}
+			return ngsierr.New(funcName, 8, err.Error(), err)
 		}
 		ngsi.StdoutFlush()
 	}
 
+	if summary {
+		status.printSummary(ngsi)
+	}
+
 	return nil
 }
 
-func copyV2V2(c *ngsicli.Context, ngsi *ngsilib.NGSI, source, destination *ngsilib.Client, entityType string) error {
-	const funcName = "copyV2V2"
+const copyLimit = 100
 
-	page := 0
-	count := 0
-	limit := 100
-	total := 0
-	for {
-		source.SetPath("/entities")
+// copyPage is a page of entities fetched from a source
+type copyPage struct {
+	offset int
+	n      int
+	body   []byte
+	report string
+}
 
-		v := url.Values{}
-		v.Set("type", entityType)
-		if c.Bool("skipForwarding") {
-			v.Set("options", "count,skipForwarding")
-		} else {
-			v.Set("options", "count")
+// copyFetchFunc gets a page of entities at offset and returns it with the number of entities matched
+type copyFetchFunc func(offset int) ([]byte, int, error)
+
+// copyWriteFunc writes a page of entities to a destination. It is called concurrently with --parallel.
+type copyWriteFunc func(destination *ngsilib.Client, page *copyPage) error
+
+// copyPages fetches pages of entities in order and writes them through a pool of --parallel workers.
+// When mapping is not nil, it is applied to each page before the page is written.
+// With --dryRun, the requests are printed for all pages and the checkpoint is not updated.
+// Errors returned by fetch and write are returned as they are.
+func copyPages(c *ngsicli.Context, ngsi *ngsilib.NGSI, destination *ngsilib.Client, entityType, filter string, mapping *copyMapping, status *copyStatus, fetch copyFetchFunc, write copyWriteFunc) error {
+	const funcName = "copyPages"
+
+	offset, err := status.start(entityType, filter)
+	if err != nil {
+		return ngsierr.New(funcName, 1, err.Error(), err)
+	}
+
+	body, count, err := fetch(offset)
+	if err != nil {
+		return err
+	}
+
+	if !c.IsSet("run") && !destination.DryRun {
+		fmt.Fprintf(ngsi.StdWriter, "%d entities will be copied. run copy with --run option\n", count)
+		return nil
+	}
+
+	produce := func() (interface{}, error) {
+		if offset >= count {
+			return nil, nil
 		}
-		v.Set("limit", fmt.Sprintf("%d", limit))
-		v.Set("offset", fmt.Sprintf("%d", page*limit))
-		source.SetQuery(&v)
+		if body == nil {
+			b, _, err := fetch(offset)
+			if err != nil {
+				return nil, err
+			}
+			body = b
+		}
+		page := &copyPage{offset: offset, n: copyPageLen(count, offset, copyLimit), body: body}
+		body = nil
+		offset += copyLimit
+		return page, nil
+	}
 
-		res, body, err := source.HTTPGet()
-		if err != nil {
-			return ngsierr.New(funcName, 1, err.Error(), err)
+	work := func(job interface{}) (interface{}, error) {
+		page := job.(*copyPage)
+		if mapping != nil {
+			body, err := mapping.apply(page.body)
+			if err != nil {
+				return page, ngsierr.New(funcName, 2, err.Error(), err)
+			}
+			page.body = body
 		}
-		if res.StatusCode != http.StatusOK {
-			return ngsierr.New(funcName, 2, fmt.Sprintf("%s %s", res.Status, string(body)), nil)
+		err := write(destination.Clone(), page)
+		if ngsilib.IsDryRun(err) {
+			return page, nil
 		}
-		count, err = source.ResultsCount(res)
+		return page, err
+	}
+
+	total := 0
+
+	consume := func(result interface{}, err error) error {
+		page, _ := result.(*copyPage)
 		if err != nil {
-			return ngsierr.New(funcName, 3, err.Error(), err)
+			if page != nil {
+				status.fail(page.n)
+				if page.report != "" {
+					fmt.Fprintln(ngsi.Stderr, page.report)
+				}
+			}
+			return err
 		}
 
-		if !c.IsSet("run") {
-			fmt.Fprintf(ngsi.StdWriter, "%d entities will be copied. run copy with --run option\n", count)
+		total += page.n
+
+		if destination.DryRun {
 			return nil
 		}
 
-		if count == 0 {
-			break
+		err = status.commit(ngsi, entityType, filter, page.offset, copyLimit, page.n)
+		if err != nil {
+			return ngsierr.New(funcName, 3, err.Error(), err)
+		}
+		return nil
+	}
+
+	err = ngsilib.Pipeline(int(c.Int64("parallel")), produce, work, consume)
+	if err != nil {
+		return err
+	}
+
+	if destination.DryRun {
+		fmt.Fprintf(ngsi.StdWriter, "%d entities would be copied\n", total)
+		return nil
+	}
+
+	fmt.Fprintln(ngsi.StdWriter, total)
+
+	return nil
+}
+
+func copyV2V2(c *ngsicli.Context, ngsi *ngsilib.NGSI, source, destination *ngsilib.Client, entityType string, mapping *copyMapping, status *copyStatus) error {
+	const funcName = "copyV2V2"
+
+	v := url.Values{}
+	v.Set("type", entityType)
+	if c.Bool("skipForwarding") {
+		v.Set("options", "count,skipForwarding")
+	} else {
+		v.Set("options", "count")
+	}
+	filter := v.Encode()
+
+	fetch := func(offset int) ([]byte, int, error) {
+		source.SetPath("/entities")
+
+		v.Set("limit", fmt.Sprintf("%d", copyLimit))
+		v.Set("offset", fmt.Sprintf("%d", offset))
+		source.SetQuery(&v)
+
+		res, body, err := source.HTTPGet()
+		if err != nil {
+			return nil, 0, ngsierr.New(funcName, 1, err.Error(), err)
+		}
+		if res.StatusCode != http.StatusOK {
+			return nil, 0, ngsierr.New(funcName, 2, fmt.Sprintf("%s %s", res.Status, string(body)), nil)
+		}
+		count, err := source.ResultsCount(res)
+		if err != nil {
+			return nil, 0, ngsierr.New(funcName, 3, err.Error(), err)
 		}
+		return body, count, nil
+	}
 
+	write := func(destination *ngsilib.Client, page *copyPage) error {
 		var entities ngsilib.EntitiesRespose
-		err = ngsilib.JSONUnmarshal(body, &entities)
+		err := ngsilib.JSONUnmarshal(page.body, &entities)
 		if err != nil {
 			return ngsierr.New(funcName, 4, err.Error(), err)
 		}
+		page.n = len(entities)
 
-		res, body, err = destination.OpUpdate(&entities, "append", false, false)
+		res, body, err := destination.OpUpdate(&entities, "append", false, false)
 		if err != nil {
 			return ngsierr.New(funcName, 5, err.Error(), err)
 		}
 		if res.StatusCode != http.StatusNoContent {
 			return ngsierr.New(funcName, 6, fmt.Sprintf("%s %s", res.Status, string(body)), nil)
 		}
-
-		total += len(entities)
-
-		if (page+1)*limit < count {
-			page = page + 1
-		} else {
-			break
-		}
+		return nil
 	}
 
-	fmt.Fprintln(ngsi.StdWriter, total)
-
-	return nil
+	return copyPages(c, ngsi, destination, entityType, filter, mapping, status, fetch, write)
 }
 
-func copyLDLD(c *ngsicli.Context, ngsi *ngsilib.NGSI, source, destination *ngsilib.Client, entityType string) error {
+func copyLDLD(c *ngsicli.Context, ngsi *ngsilib.NGSI, source, destination *ngsilib.Client, entityType string, mapping *copyMapping, status *copyStatus) error {
 	const funcName = "copyLDLD"
 
-	page := 0
-	limit := 100
-	total := 0
-	for {
+	v := url.Values{}
+	v.Set("type", entityType)
+	v.Set("count", "true")
+	filter := v.Encode()
+
+	fetch := func(offset int) ([]byte, int, error) {
 		// get count
 		source.SetPath("/entities")
 
-		v := url.Values{}
-		v.Set("type", entityType)
-		v.Set("count", "true")
-		v.Set("limit", fmt.Sprintf("%d", limit))
+		v.Set("limit", fmt.Sprintf("%d", copyLimit))
+		v.Set("offset", fmt.Sprintf("%d", offset))
 		source.SetQuery(&v)
 
 		res, body, err := source.HTTPGet()
 		if err != nil {
-			return ngsierr.New(funcName, 1, err.Error(), err)
+			return nil, 0, ngsierr.New(funcName, 1, err.Error(), err)
 		}
 		if res.StatusCode != http.StatusOK {
-			return ngsierr.New(funcName, 2, fmt.Sprintf("%s %s", res.Status, string(body)), nil)
+			return nil, 0, ngsierr.New(funcName, 2, fmt.Sprintf("%s %s", res.Status, string(body)), nil)
 		}
 
 		count, err := source.ResultsCount(res)
 		if err != nil {
-			return ngsierr.New(funcName, 3, "results count error", nil)
-		}
-
-		if !c.IsSet("run") {
-			fmt.Fprintf(ngsi.StdWriter, "%d entities will be copied. run copy with --run option\n", count)
-			return nil
-		}
-
-		if count == 0 {
-			break
+			return nil, 0, ngsierr.New(funcName, 3, "results count error", nil)
 		}
+		return body, count, nil
+	}
 
+	write := func(destination *ngsilib.Client, page *copyPage) error {
 		destination.SetPath("/entityOperations/create")
 		destination.SetContentLdJSON()
 
+		body := page.body
 		if c.IsSet("context2") {
+			var err error
 			body, err = ngsi.InsertAtContext(body, c.String("context2"))
 			if err != nil {
 				return ngsierr.New(funcName, 4, err.Error(), err)
 			}
 		}
-		res, body, err = destination.HTTPPost(body)
+		res, body, err := destination.HTTPPost(body)
 		if err != nil {
 			return ngsierr.New(funcName, 5, err.Error(), err)
 		}
 		if res.StatusCode != http.StatusCreated {
 			return ngsierr.New(funcName, 6, fmt.Sprintf("%s %s", res.Status, string(body)), nil)
 		}
-
-		total += count
-
-		if (page+1)*limit < count {
-			page = page + 1
-		} else {
-			break
-		}
+		return nil
 	}
 
-	fmt.Fprintln(ngsi.StdWriter, total)
-
-	return nil
+	return copyPages(c, ngsi, destination, entityType, filter, mapping, status, fetch, write)
 }
 
-func copyV1V1(c *ngsicli.Context, ngsi *ngsilib.NGSI, source, destination *ngsilib.Client, entityType string) error {
+func copyV1V1(c *ngsicli.Context, ngsi *ngsilib.NGSI, source, destination *ngsilib.Client, entityType string, mapping *copyMapping, status *copyStatus) error {
 	const funcName = "copyV1V1"
 
-	page := 0
-	count := 0
-	limit := 100
-	total := 0
-	for {
+	payload := fmt.Sprintf("{\"entities\":[{\"type\":\"%s\",\"isPattern\":\"true\",\"id\":\".*\"}]}", entityType)
 
+	fetch := func(offset int) ([]byte, int, error) {
 		source.SetPath("/v1/queryContext")
-		payload := fmt.Sprintf("{\"entities\":[{\"type\":\"%s\",\"isPattern\":\"true\",\"id\":\".*\"}]}", entityType)
 
 		v := url.Values{}
 		v.Set("details", "on")
-		v.Set("limit", fmt.Sprintf("%d", limit))
-		v.Set("offset", fmt.Sprintf("%d", page*limit))
+		v.Set("limit", fmt.Sprintf("%d", copyLimit))
+		v.Set("offset", fmt.Sprintf("%d", offset))
 		source.SetQuery(&v)
 		source.SetContentJSON()
 
-		res, body, err := source.HTTPPost([]byte(payload))
+		res, body, err := source.HTTPQuery([]byte(payload))
 		if err != nil {
-			return ngsierr.New(funcName, 1, err.Error(), err)
+			return nil, 0, ngsierr.New(funcName, 1, err.Error(), err)
 		}
 		if res.StatusCode != http.StatusOK {
-			return ngsierr.New(funcName, 2, fmt.Sprintf("%s %s", res.Status, string(body)), nil)
+			return nil, 0, ngsierr.New(funcName, 2, fmt.Sprintf("%s %s", res.Status, string(body)), nil)
 		}
 
-		body, count, err = makeV1Entities(body, "APPEND")
+		body, count, err := makeV1Entities(body, "APPEND")
 		if err != nil {
-			return ngsierr.New(funcName, 3, err.Error(), err)
-		}
-
-		if !c.IsSet("run") {
-			fmt.Fprintf(ngsi.StdWriter, "%d entities will be copied. run copy with --run option\n", count)
-			return nil
-		}
-
-		if count == 0 {
-			break
+			return nil, 0, ngsierr.New(funcName, 3, err.Error(), err)
 		}
+		return body, count, nil
+	}
 
+	write := func(destination *ngsilib.Client, page *copyPage) error {
 		destination.SetPath("/v1/updateContext")
-		v = url.Values{}
-		v.Set("details", "on")
-		source.SetContentJSON()
 		destination.SetContentJSON()
 
-		res, body, err = destination.HTTPPost(body)
+		res, body, err := destination.HTTPPost(page.body)
 		if err != nil {
 			return ngsierr.New(funcName, 4, err.Error(), err)
 		}
@@ -307,18 +408,11 @@ func copyV1V1(c *ngsicli.Context, ngsi *ngsilib.NGSI, source, destination *ngsil
 				return ngsierr.New(funcName, 7, fmt.Sprintf("error %s %s", e.StatusCode.Code, e.StatusCode.ReasonPhrase), err)
 			}
 		}
-		total += len(resBody.ContextResponses)
-
-		if (page+1)*limit < count {
-			page = page + 1
-		} else {
-			break
-		}
+		page.n = len(resBody.ContextResponses)
+		return nil
 	}
 
-	fmt.Fprintln(ngsi.StdWriter, total)
-
-	return nil
+	return copyPages(c, ngsi, destination, entityType, payload, mapping, status, fetch, write)
 }
 
 func makeV1Entities(body []byte, actionType string) ([]byte, int, error) {
@@ -357,50 +451,43 @@ func makeV1Entities(body []byte, actionType string) ([]byte, int, error) {
 	return b, count, nil
 }
 
-func copyV2LD(c *ngsicli.Context, ngsi *ngsilib.NGSI, source, destination *ngsilib.Client, entityType string) error {
+func copyV2LD(c *ngsicli.Context, ngsi *ngsilib.NGSI, source, destination *ngsilib.Client, entityType string, mapping *copyMapping, status *copyStatus) error {
 	const funcName = "copyV2LD"
 
-	page := 0
-	limit := 100
-	total := 0
-	for {
+	v := url.Values{}
+	v.Set("type", entityType)
+	if c.Bool("skipForwarding") {
+		v.Set("options", "count,skipForwarding")
+	} else {
+		v.Set("options", "count")
+	}
+	filter := v.Encode()
+
+	fetch := func(offset int) ([]byte, int, error) {
 		// get count
 		source.SetPath("/entities")
 
-		v := url.Values{}
-		v.Set("type", entityType)
-		if c.Bool("skipForwarding") {
-			v.Set("options", "count,skipForwarding")
-		} else {
-			v.Set("options", "count")
-		}
-		v.Set("limit", fmt.Sprintf("%d", limit))
-		v.Set("offset", fmt.Sprintf("%d", page*limit))
+		v.Set("limit", fmt.Sprintf("%d", copyLimit))
+		v.Set("offset", fmt.Sprintf("%d", offset))
 		source.SetQuery(&v)
 
 		res, body, err := source.HTTPGet()
 		if err != nil {
-			return ngsierr.New(funcName, 1, err.Error(), err)
+			return nil, 0, ngsierr.New(funcName, 1, err.Error(), err)
 		}
 		if res.StatusCode != http.StatusOK {
-			return ngsierr.New(funcName, 2, fmt.Sprintf("%s %s", res.Status, string(body)), nil)
+			return nil, 0, ngsierr.New(funcName, 2, fmt.Sprintf("%s %s", res.Status, string(body)), nil)
 		}
 
 		count, err := source.ResultsCount(res)
 		if err != nil {
-			return ngsierr.New(funcName, 3, "results count error", nil)
-		}
-
-		if !c.IsSet("run") {
-			fmt.Fprintf(ngsi.StdWriter, "%d entities will be copied. run copy with --run option\n", count)
-			return nil
-		}
-
-		if count == 0 {
-			break
+			return nil, 0, ngsierr.New(funcName, 3, "results count error", nil)
 		}
+		return body, count, nil
+	}
 
-		body, err = normalized2LD(body)
+	write := func(destination *ngsilib.Client, page *copyPage) error {
+		body, err := normalized2LD(page.body)
 		if err != nil {
 			return ngsierr.New(funcName, 4, err.Error(), err)
 		}
@@ -419,21 +506,13 @@ func copyV2LD(c *ngsicli.Context, ngsi *ngsilib.NGSI, source, destination *ngsil
 			return ngsierr.New(funcName, 6, err.Error(), err)
 		}
 		if res.StatusCode != http.StatusCreated {
-			fmt.Fprintln(ngsi.Stderr, string(body))
+			page.report = string(body)
 			return ngsierr.New(funcName, 7, fmt.Sprintf("%s %s", res.Status, string(resBody)), nil)
 		}
-
-		total += count
-
-		if (page+1)*limit < count {
-			page = page + 1
-		} else {
-			break
-		}
+		return nil
 	}
 
-	fmt.Fprintln(ngsi.StdWriter, total)
-	return nil
+	return copyPages(c, ngsi, destination, entityType, filter, mapping, status, fetch, write)
 }
 
 // Porting of https://github.com/FIWARE/dataModels/blob/master/tools/normalized2LD.py
@@ -481,7 +560,7 @@ func normalized2LDEntity(v2 ngsilib.NgsiEntity) (ldEntity, error) {
 		if !ok {
 			return nil, ngsierr.New(funcName, 2, "type not string", nil)
 		}
-		ld["id"] = ldId(v, t)
+		ld["id"] = ngsilib.LdID(v, t)
 	}
 	for key, value := range v2 {
 		switch key {
@@ -667,29 +746,11 @@ func ldRelationship(attrName string, entityId string) string {
 		if strings.HasPrefix(attrName, "ref") {
 			entityType = attrName[3:]
 		}
-		return ngsildUri(entityType, entityId)
+		return ngsilib.NgsiLdURI(entityType, entityId)
 	}
 	return uri
 }
 
-func ngsildUri(typePart, idPart string) string {
-	entityType := uriParse.FindAllStringSubmatch(idPart, -1)
-	if strings.EqualFold(entityType[0][0], typePart) {
-		return fmt.Sprintf("urn:ngsi-ld:%s", idPart)
-	}
-	return fmt.Sprintf("urn:ngsi-ld:%s:%s", typePart, idPart)
-}
-
-func ldId(entityId, entityType string) string {
-	scheme := uriParse.FindAllStringSubmatch(entityId, -1)
-
-	if !ngsilib.Contains([]string{"urn", "http", "https"}, scheme[0][0]) {
-		return ngsildUri(entityType, entityId)
-	}
-
-	return entityId
-}
-
 type ngsiAtTypeValue struct {
 	Type  string      `json:"@type"`
 	Value interface{} `json:"@value"`
@@ -0,0 +1,174 @@
+/*
+MIT License
+
+Copyright (c) 2020-2024 Kazuhito Suda
+
+This file is part of NGSI Go
+
+https://github.com/lets-fiware/ngsi-go
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+
+*/
+
+package convenience
+
+import (
+	"fmt"
+
+	"github.com/lets-fiware/ngsi-go/internal/ngsicli"
+	"github.com/lets-fiware/ngsi-go/internal/ngsierr"
+	"github.com/lets-fiware/ngsi-go/internal/ngsilib"
+)
+
+// copyCheckpoint is the content of a checkpoint file written by cp.
+// Page is the last committed page, Offset is the offset from which copying continues
+// and Copied is the number of entities committed so far.
+type copyCheckpoint struct {
+	Type   string `json:"type"`
+	Filter string `json:"filter"`
+	Page   int    `json:"page"`
+	Offset int    `json:"offset"`
+	Copied int    `json:"copied"`
+}
+
+// copyStatus holds the progress of cp
+type copyStatus struct {
+	file       string
+	checkpoint *copyCheckpoint
+	copied     int
+	skipped    int
+	failed     int
+}
+
+func newCopyStatus(c *ngsicli.Context, ngsi *ngsilib.NGSI) (*copyStatus, error) {
+	const funcName = "newCopyStatus"
+
+	status := &copyStatus{}
+
+	if !c.IsSet("checkpoint") {
+		if c.Bool("resume") {
+			return nil, ngsierr.New(funcName, 1, "--resume requires --checkpoint", nil)
+		}
+		return status, nil
+	}
+
+	status.file = c.String("checkpoint")
+
+	if !c.Bool("resume") {
+		return status, nil
+	}
+
+	b, err := ngsi.Ioutil.ReadFile(status.file)
+	if err != nil {
+		return nil, ngsierr.New(funcName, 2, err.Error(), err)
+	}
+
+	var checkpoint copyCheckpoint
+	err = ngsilib.JSONUnmarshal(b, &checkpoint)
+	if err != nil {
+		return nil, ngsierr.New(funcName, 3, err.Error(), err)
+	}
+
+	status.checkpoint = &checkpoint
+	status.skipped = checkpoint.Copied
+
+	return status, nil
+}
+
+// resumeTypes returns the entity types that remain to be copied
+func (s *copyStatus) resumeTypes(types []string) ([]string, error) {
+	const funcName = "resumeTypes"
+
+	if s.checkpoint == nil {
+		return types, nil
+	}
+
+	for i, t := range types {
+		if t == s.checkpoint.Type {
+			return types[i:], nil
+		}
+	}
+
+	return nil, ngsierr.New(funcName, 1, fmt.Sprintf("type in checkpoint not found: %s", s.checkpoint.Type), nil)
+}
+
+// start returns the offset from which entities of entityType are copied
+func (s *copyStatus) start(entityType, filter string) (int, error) {
+	const funcName = "start"
+
+	if s.checkpoint == nil || s.checkpoint.Type != entityType {
+		return 0, nil
+	}
+
+	checkpoint := s.checkpoint
+	s.checkpoint = nil
+
+	if checkpoint.Filter != filter {
+		return 0, ngsierr.New(funcName, 1, fmt.Sprintf("filter in checkpoint mismatch: %s", checkpoint.Filter), nil)
+	}
+
+	return checkpoint.Offset, nil
+}
+
+// commit counts copied entities and writes a checkpoint file
+func (s *copyStatus) commit(ngsi *ngsilib.NGSI, entityType, filter string, offset, limit, n int) error {
+	const funcName = "commit"
+
+	s.copied += n
+
+	if s.file == "" {
+		return nil
+	}
+
+	checkpoint := copyCheckpoint{
+		Type:   entityType,
+		Filter: filter,
+		Page:   offset/limit + 1,
+		Offset: offset + limit,
+		Copied: s.skipped + s.copied,
+	}
+
+	b, err := ngsilib.JSONMarshal(&checkpoint)
+	if err != nil {
+		return ngsierr.New(funcName, 1, err.Error(), err)
+	}
+
+	err = ngsi.Ioutil.WriteFile(s.file, b, 0600)
+	if err != nil {
+		return ngsierr.New(funcName, 2, err.Error(), err)
+	}
+
+	return nil
+}
+
+func (s *copyStatus) fail(n int) {
+	s.failed += n
+}
+
+func (s *copyStatus) printSummary(ngsi *ngsilib.NGSI) {
+	fmt.Fprintf(ngsi.StdWriter, "copied: %d, skipped: %d, failed: %d\n", s.copied, s.skipped, s.failed)
+}
+
+func copyPageLen(count, offset, limit int) int {
+	if count-offset < limit {
+		return count - offset
+	}
+	return limit
+}
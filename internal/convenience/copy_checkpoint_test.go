@@ -0,0 +1,262 @@
+/*
+MIT License
+
+Copyright (c) 2020-2024 Kazuhito Suda
+
+This file is part of NGSI Go
+
+https://github.com/lets-fiware/ngsi-go
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+
+*/
+
+package convenience
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/lets-fiware/ngsi-go/internal/assert"
+	"github.com/lets-fiware/ngsi-go/internal/helper"
+	"github.com/lets-fiware/ngsi-go/internal/ngsierr"
+)
+
+func TestNewCopyStatus(t *testing.T) {
+	c := setupTest([]string{"cp", "--host", "orion", "--host2", "orion-ld", "--type", "Thing"})
+
+	actual, err := newCopyStatus(c, c.Ngsi)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, &copyStatus{}, actual)
+	}
+}
+
+func TestNewCopyStatusCheckpoint(t *testing.T) {
+	c := setupTest([]string{"cp", "--host", "orion", "--host2", "orion-ld", "--type", "Thing", "--checkpoint", "cp.json"})
+
+	actual, err := newCopyStatus(c, c.Ngsi)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, &copyStatus{file: "cp.json"}, actual)
+	}
+}
+
+func TestNewCopyStatusResume(t *testing.T) {
+	c := setupTest([]string{"cp", "--host", "orion", "--host2", "orion-ld", "--type", "Thing", "--checkpoint", "cp.json", "--resume"})
+	c.Ngsi.Ioutil = &helper.MockIoutilLib{ReadFileData: []byte(`{"type":"Thing","filter":"options=count&type=Thing","page":2,"offset":200,"copied":200}`)}
+
+	actual, err := newCopyStatus(c, c.Ngsi)
+
+	if assert.NoError(t, err) {
+		expected := &copyStatus{
+			file:       "cp.json",
+			checkpoint: &copyCheckpoint{Type: "Thing", Filter: "options=count&type=Thing", Page: 2, Offset: 200, Copied: 200},
+			skipped:    200,
+		}
+		assert.Equal(t, expected, actual)
+	}
+}
+
+func TestNewCopyStatusErrorResume(t *testing.T) {
+	c := setupTest([]string{"cp", "--host", "orion", "--host2", "orion-ld", "--type", "Thing", "--resume"})
+
+	_, err := newCopyStatus(c, c.Ngsi)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 1, ngsiErr.ErrNo)
+		assert.Equal(t, "--resume requires --checkpoint", ngsiErr.Message)
+	}
+}
+
+func TestNewCopyStatusErrorReadFile(t *testing.T) {
+	c := setupTest([]string{"cp", "--host", "orion", "--host2", "orion-ld", "--type", "Thing", "--checkpoint", "cp.json", "--resume"})
+	c.Ngsi.Ioutil = &helper.MockIoutilLib{ReadFileErr: errors.New("read file error")}
+
+	_, err := newCopyStatus(c, c.Ngsi)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 2, ngsiErr.ErrNo)
+		assert.Equal(t, "read file error", ngsiErr.Message)
+	}
+}
+
+func TestNewCopyStatusErrorJSONUnmarshal(t *testing.T) {
+	c := setupTest([]string{"cp", "--host", "orion", "--host2", "orion-ld", "--type", "Thing", "--checkpoint", "cp.json", "--resume"})
+	c.Ngsi.Ioutil = &helper.MockIoutilLib{ReadFileData: []byte(`{}`)}
+	helper.SetJSONDecodeErr(c.Ngsi, 0)
+
+	_, err := newCopyStatus(c, c.Ngsi)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 3, ngsiErr.ErrNo)
+		assert.Equal(t, "json error", ngsiErr.Message)
+	}
+}
+
+func TestResumeTypes(t *testing.T) {
+	cases := []struct {
+		checkpoint *copyCheckpoint
+		expected   []string
+	}{
+		{checkpoint: nil, expected: []string{"Device", "Event", "Thing"}},
+		{checkpoint: &copyCheckpoint{Type: "Device"}, expected: []string{"Device", "Event", "Thing"}},
+		{checkpoint: &copyCheckpoint{Type: "Event"}, expected: []string{"Event", "Thing"}},
+		{checkpoint: &copyCheckpoint{Type: "Thing"}, expected: []string{"Thing"}},
+	}
+
+	for _, c := range cases {
+		status := &copyStatus{checkpoint: c.checkpoint}
+
+		actual, err := status.resumeTypes([]string{"Device", "Event", "Thing"})
+
+		if assert.NoError(t, err) {
+			assert.Equal(t, c.expected, actual)
+		}
+	}
+}
+
+func TestResumeTypesError(t *testing.T) {
+	status := &copyStatus{checkpoint: &copyCheckpoint{Type: "Room"}}
+
+	_, err := status.resumeTypes([]string{"Device", "Event", "Thing"})
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 1, ngsiErr.ErrNo)
+		assert.Equal(t, "type in checkpoint not found: Room", ngsiErr.Message)
+	}
+}
+
+func TestCopyStatusStart(t *testing.T) {
+	status := &copyStatus{checkpoint: &copyCheckpoint{Type: "Thing", Filter: "type=Thing", Offset: 300}}
+
+	actual, err := status.start("Device", "type=Device")
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, 0, actual)
+	}
+
+	actual, err = status.start("Thing", "type=Thing")
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, 300, actual)
+		assert.Equal(t, (*copyCheckpoint)(nil), status.checkpoint)
+	}
+
+	actual, err = status.start("Thing", "type=Thing")
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, 0, actual)
+	}
+}
+
+func TestCopyStatusStartError(t *testing.T) {
+	status := &copyStatus{checkpoint: &copyCheckpoint{Type: "Thing", Filter: "type=Thing"}}
+
+	_, err := status.start("Thing", "options=count&type=Thing")
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 1, ngsiErr.ErrNo)
+		assert.Equal(t, "filter in checkpoint mismatch: type=Thing", ngsiErr.Message)
+	}
+}
+
+func TestCopyStatusCommit(t *testing.T) {
+	c := setupTest([]string{"cp", "--host", "orion", "--host2", "orion-ld", "--type", "Thing"})
+	file := filepath.Join(t.TempDir(), "cp.json")
+
+	status := &copyStatus{file: file, skipped: 200}
+
+	err := status.commit(c.Ngsi, "Thing", "type=Thing", 300, 100, 100)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, 100, status.copied)
+		b, _ := os.ReadFile(file)
+		expected := `{"type":"Thing","filter":"type=Thing","page":4,"offset":400,"copied":300}`
+		assert.Equal(t, expected, string(b))
+	}
+}
+
+func TestCopyStatusCommitNoFile(t *testing.T) {
+	c := setupTest([]string{"cp", "--host", "orion", "--host2", "orion-ld", "--type", "Thing"})
+	c.Ngsi.Ioutil = &helper.MockIoutilLib{WriteFileErr: errors.New("write file error")}
+
+	status := &copyStatus{}
+
+	err := status.commit(c.Ngsi, "Thing", "type=Thing", 0, 100, 10)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, 10, status.copied)
+	}
+}
+
+func TestCopyStatusCommitErrorJSONMarshal(t *testing.T) {
+	c := setupTest([]string{"cp", "--host", "orion", "--host2", "orion-ld", "--type", "Thing"})
+	helper.SetJSONEncodeErr(c.Ngsi, 0)
+
+	status := &copyStatus{file: "cp.json"}
+
+	err := status.commit(c.Ngsi, "Thing", "type=Thing", 0, 100, 10)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 1, ngsiErr.ErrNo)
+		assert.Equal(t, "json error", ngsiErr.Message)
+	}
+}
+
+func TestCopyStatusCommitErrorWriteFile(t *testing.T) {
+	c := setupTest([]string{"cp", "--host", "orion", "--host2", "orion-ld", "--type", "Thing"})
+	c.Ngsi.Ioutil = &helper.MockIoutilLib{WriteFileErr: errors.New("write file error")}
+
+	status := &copyStatus{file: "cp.json"}
+
+	err := status.commit(c.Ngsi, "Thing", "type=Thing", 0, 100, 10)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 2, ngsiErr.ErrNo)
+		assert.Equal(t, "write file error", ngsiErr.Message)
+	}
+}
+
+func TestCopyStatusPrintSummary(t *testing.T) {
+	c := setupTest([]string{"cp", "--host", "orion", "--host2", "orion-ld", "--type", "Thing"})
+
+	status := &copyStatus{copied: 150, skipped: 200}
+	status.fail(100)
+	status.printSummary(c.Ngsi)
+
+	actual := helper.GetStdoutString(c)
+	expected := "copied: 150, skipped: 200, failed: 100\n"
+	assert.Equal(t, expected, actual)
+}
+
+func TestCopyPageLen(t *testing.T) {
+	assert.Equal(t, 100, copyPageLen(250, 0, 100))
+	assert.Equal(t, 100, copyPageLen(250, 100, 100))
+	assert.Equal(t, 50, copyPageLen(250, 200, 100))
+}
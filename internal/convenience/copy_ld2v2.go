@@ -0,0 +1,326 @@
+/*
+MIT License
+
+Copyright (c) 2020-2024 Kazuhito Suda
+
+This file is part of NGSI Go
+
+https://github.com/lets-fiware/ngsi-go
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+
+*/
+
+package convenience
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/lets-fiware/ngsi-go/internal/ngsicli"
+	"github.com/lets-fiware/ngsi-go/internal/ngsierr"
+	"github.com/lets-fiware/ngsi-go/internal/ngsilib"
+)
+
+func copyLDV2(c *ngsicli.Context, ngsi *ngsilib.NGSI, source, destination *ngsilib.Client, entityType string, mapping *copyMapping, status *copyStatus) error {
+	const funcName = "copyLDV2"
+
+	v := url.Values{}
+	v.Set("type", entityType)
+	v.Set("count", "true")
+	filter := v.Encode()
+
+	fetch := func(offset int) ([]byte, int, error) {
+		source.SetPath("/entities")
+
+		v.Set("limit", fmt.Sprintf("%d", copyLimit))
+		v.Set("offset", fmt.Sprintf("%d", offset))
+		source.SetQuery(&v)
+
+		res, body, err := source.HTTPGet()
+		if err != nil {
+			return nil, 0, ngsierr.New(funcName, 1, err.Error(), err)
+		}
+		if res.StatusCode != http.StatusOK {
+			return nil, 0, ngsierr.New(funcName, 2, fmt.Sprintf("%s %s", res.Status, string(body)), nil)
+		}
+
+		count, err := source.ResultsCount(res)
+		if err != nil {
+			return nil, 0, ngsierr.New(funcName, 3, "results count error", nil)
+		}
+		return body, count, nil
+	}
+
+	write := func(destination *ngsilib.Client, page *copyPage) error {
+		entities, err := ld2Normalized(page.body, c.String("multiAttr"))
+		if err != nil {
+			return ngsierr.New(funcName, 4, err.Error(), err)
+		}
+
+		res, body, err := destination.OpUpdate(&entities, "append", false, false)
+		if err != nil {
+			return ngsierr.New(funcName, 5, err.Error(), err)
+		}
+		if res.StatusCode != http.StatusNoContent {
+			return ngsierr.New(funcName, 6, fmt.Sprintf("%s %s", res.Status, string(body)), nil)
+		}
+		return nil
+	}
+
+	return copyPages(c, ngsi, destination, entityType, filter, mapping, status, fetch, write)
+}
+
+const ldDefaultContextPrefix = "https://uri.etsi.org/ngsi-ld/default-context/"
+
+// ld2Normalized converts NGSI-LD entities to NGSIv2 normalized entities.
+// multiAttr is the handling of attributes with more than one instance:
+// "default" (or "") keeps the default instance, "suffix" keeps all instances
+// by adding the last part of datasetId to the attribute name, and "error" fails.
+func ld2Normalized(body []byte, multiAttr string) (ngsilib.EntitiesRespose, error) {
+	const funcName = "ld2Normalized"
+
+	var ldEntities []map[string]interface{}
+	err := ngsilib.JSONUnmarshal(body, &ldEntities)
+	if err != nil {
+		return nil, ngsierr.New(funcName, 1, err.Error(), err)
+	}
+
+	v2Entities := ngsilib.EntitiesRespose{}
+
+	for _, ld := range ldEntities {
+		v2, err := ld2NormalizedEntity(ld, multiAttr)
+		if err != nil {
+			return nil, ngsierr.New(funcName, 2, err.Error(), err)
+		}
+		v2Entities = append(v2Entities, v2)
+	}
+
+	return v2Entities, nil
+}
+
+func ld2NormalizedEntity(ld map[string]interface{}, multiAttr string) (ngsilib.NgsiEntity, error) {
+	const funcName = "ld2NormalizedEntity"
+
+	v2 := make(ngsilib.NgsiEntity)
+
+	id, ok := ld["id"].(string)
+	if !ok {
+		return nil, ngsierr.New(funcName, 1, "id not string", nil)
+	}
+	v2["id"] = id
+
+	t, ok := ld["type"].(string)
+	if !ok {
+		return nil, ngsierr.New(funcName, 2, "type not string", nil)
+	}
+	v2["type"] = compactLDName(t)
+
+	keys := make([]string, 0, len(ld))
+	for key := range ld {
+		switch key {
+		case "id", "type", "@context", "scope", "createdAt", "modifiedAt":
+			continue
+		}
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	names := map[string]string{}
+
+	for _, key := range keys {
+		name := compactLDName(key)
+
+		attrs := map[string]interface{}{}
+
+		switch value := ld[key].(type) {
+		case map[string]interface{}:
+			attr, err := v2Attribute(value)
+			if err != nil {
+				return nil, ngsierr.New(funcName, 3, name+": "+err.Error(), err)
+			}
+			attrs[name] = attr
+		case []interface{}:
+			var err error
+			attrs, err = v2MultiAttribute(name, value, multiAttr)
+			if err != nil {
+				return nil, ngsierr.New(funcName, 4, err.Error(), err)
+			}
+		default:
+			return nil, ngsierr.New(funcName, 5, name+": attribute error", nil)
+		}
+
+		for k, v := range attrs {
+			if prev, ok := names[k]; ok {
+				return nil, ngsierr.New(funcName, 6, fmt.Sprintf("%s and %s are both compacted to %s", prev, key, k), nil)
+			}
+			names[k] = key
+			v2[k] = v
+		}
+	}
+
+	return v2, nil
+}
+
+func v2MultiAttribute(name string, instances []interface{}, multiAttr string) (map[string]interface{}, error) {
+	const funcName = "v2MultiAttribute"
+
+	if len(instances) > 1 && multiAttr == "error" {
+		return nil, ngsierr.New(funcName, 1, name+": multi-attribute", nil)
+	}
+
+	attrs := map[string]interface{}{}
+	var first map[string]interface{}
+
+	for _, instance := range instances {
+		ldAttr, ok := instance.(map[string]interface{})
+		if !ok {
+			return nil, ngsierr.New(funcName, 2, name+": attribute error", nil)
+		}
+		attr, err := v2Attribute(ldAttr)
+		if err != nil {
+			return nil, ngsierr.New(funcName, 3, name+": "+err.Error(), err)
+		}
+
+		datasetID, ok := ldAttr["datasetId"].(string)
+		switch {
+		case !ok:
+			attrs[name] = attr
+		case multiAttr == "suffix":
+			k := name + "_" + compactLDName(datasetID)
+			if _, ok := attrs[k]; ok {
+				return nil, ngsierr.New(funcName, 4, fmt.Sprintf("%s: datasetId %s is compacted to %s twice", name, datasetID, k), nil)
+			}
+			attrs[k] = attr
+		case first == nil:
+			first = attr
+		}
+	}
+
+	if _, ok := attrs[name]; !ok && first != nil && multiAttr != "suffix" {
+		attrs[name] = first
+	}
+
+	return attrs, nil
+}
+
+// v2Attribute converts an NGSI-LD attribute to an NGSIv2 attribute.
+// Sub-properties become metadata and observedAt becomes TimeInstant metadata.
+func v2Attribute(ldAttr map[string]interface{}) (map[string]interface{}, error) {
+	const funcName = "v2Attribute"
+
+	attr := map[string]interface{}{}
+
+	t, _ := ldAttr["type"].(string)
+
+	switch t {
+	case "Relationship":
+		attr["type"] = "Relationship"
+		attr["value"] = ldAttr["object"]
+	case "GeoProperty":
+		attr["type"] = "geo:json"
+		attr["value"] = ldAttr["value"]
+	case "LanguageProperty":
+		attr["type"] = "StructuredValue"
+		attr["value"] = ldAttr["languageMap"]
+	case "Property", "":
+		value := ldAttr["value"]
+		if v, ok := value.(map[string]interface{}); ok && v["@type"] != nil && v["@value"] != nil {
+			attr["type"] = compactLDName(fmt.Sprint(v["@type"]))
+			attr["value"] = v["@value"]
+		} else {
+			attr["type"] = v2Type(value)
+			attr["value"] = value
+		}
+	default:
+		return nil, ngsierr.New(funcName, 1, "unknown attribute type: "+t, nil)
+	}
+
+	metadata := map[string]interface{}{}
+
+	keys := make([]string, 0, len(ldAttr))
+	for key := range ldAttr {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		value := ldAttr[key]
+		switch key {
+		case "type", "value", "object", "languageMap", "createdAt", "modifiedAt":
+			continue
+		case "observedAt":
+			metadata["TimeInstant"] = ngsiTypeValue{Type: "DateTime", Value: value}
+		case "unitCode", "datasetId":
+			metadata[key] = ngsiTypeValue{Type: "Text", Value: value}
+		default:
+			sub, ok := value.(map[string]interface{})
+			if !ok {
+				return nil, ngsierr.New(funcName, 2, key+": sub-attribute error", nil)
+			}
+			v, err := v2Attribute(sub)
+			if err != nil {
+				return nil, ngsierr.New(funcName, 3, key+": "+err.Error(), err)
+			}
+			name := compactLDName(key)
+			if _, ok := metadata[name]; ok {
+				return nil, ngsierr.New(funcName, 4, fmt.Sprintf("%s: metadata %s already exists", key, name), nil)
+			}
+			metadata[name] = ngsiTypeValue{Type: v["type"].(string), Value: v["value"]}
+		}
+	}
+
+	if len(metadata) > 0 {
+		attr["metadata"] = metadata
+	}
+
+	return attr, nil
+}
+
+func v2Type(value interface{}) string {
+	switch value.(type) {
+	case string:
+		return "Text"
+	case float64:
+		return "Number"
+	case bool:
+		return "Boolean"
+	case nil:
+		return "None"
+	}
+	return "StructuredValue"
+}
+
+// compactLDName compacts an expanded name that the @context of the source did not compact.
+// It removes the default context prefix, or takes the last part of other IRIs. As two IRIs can
+// end with the same part, the callers fail when a compacted name is already in use.
+func compactLDName(name string) string {
+	if strings.HasPrefix(name, ldDefaultContextPrefix) {
+		return name[len(ldDefaultContextPrefix):]
+	}
+	if strings.HasPrefix(name, "http://") || strings.HasPrefix(name, "https://") || strings.HasPrefix(name, "urn:") {
+		if i := strings.LastIndexAny(name, "/#:"); i >= 0 {
+			return name[i+1:]
+		}
+	}
+	return name
+}
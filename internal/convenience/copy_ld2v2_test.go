@@ -0,0 +1,516 @@
+/*
+MIT License
+
+Copyright (c) 2020-2024 Kazuhito Suda
+
+This file is part of NGSI Go
+
+https://github.com/lets-fiware/ngsi-go
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+
+*/
+
+package convenience
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/lets-fiware/ngsi-go/internal/assert"
+	"github.com/lets-fiware/ngsi-go/internal/helper"
+	"github.com/lets-fiware/ngsi-go/internal/ngsierr"
+	"github.com/lets-fiware/ngsi-go/internal/ngsilib"
+)
+
+var testCopyLDV2Conf = `{
+	"version": "1",
+	"servers": {
+		"orion-src": {
+			"serverHost": "https://orion-src",
+			"ngsiType": "ld"
+		},
+		"orion-dest": {
+			"serverHost": "https://orion-dest",
+			"ngsiType": "v2"
+		}
+	}
+}`
+
+func TestCopyLDV2(t *testing.T) {
+	c := setupTestWithConfig([]string{"cp", "--host", "orion-src", "--host2", "orion-dest", "--type", "Thing"}, testCopyLDV2Conf)
+
+	reqRes1 := helper.MockHTTPReqRes{}
+	reqRes1.Res.StatusCode = http.StatusOK
+	reqRes1.ResBody = []byte(`[{"id":"urn:ngsi-ld:Thing:001","type":"Thing"}]`)
+	reqRes1.ResHeader = http.Header{"Ngsild-Results-Count": []string{"1"}}
+	reqRes1.Path = "/ngsi-ld/v1/entities"
+	helper.SetClientHTTP(c, reqRes1)
+
+	err := copy(c, c.Ngsi, c.Client)
+
+	if assert.NoError(t, err) {
+		actual := helper.GetStdoutString(c)
+		expected := "1 entities will be copied. run copy with --run option\n"
+		assert.Equal(t, expected, actual)
+	}
+}
+
+func TestCopyLDV2Run(t *testing.T) {
+	c := setupTestWithConfig([]string{"cp", "--host", "orion-src", "--host2", "orion-dest", "--type", "Thing", "--run"}, testCopyLDV2Conf)
+
+	reqRes1 := helper.MockHTTPReqRes{}
+	reqRes1.Res.StatusCode = http.StatusOK
+	reqRes1.ResBody = []byte(`[{"id":"urn:ngsi-ld:Thing:001","type":"Thing","temperature":{"type":"Property","value":21.5,"observedAt":"2021-01-01T00:00:00Z"}}]`)
+	reqRes1.ResHeader = http.Header{"Ngsild-Results-Count": []string{"1"}}
+	reqRes1.Path = "/ngsi-ld/v1/entities"
+
+	reqRes2 := helper.MockHTTPReqRes{}
+	reqRes2.Res.StatusCode = http.StatusNoContent
+	reqRes2.Path = "/v2/op/update"
+	reqRes2.ReqData = []byte(`{"actionType":"append","entities":[{"id":"urn:ngsi-ld:Thing:001","temperature":{"metadata":{"TimeInstant":{"type":"DateTime","value":"2021-01-01T00:00:00Z"}},"type":"Number","value":21.5},"type":"Thing"}]}`)
+
+	mockSource := helper.NewMockHTTP()
+	mockSource.ReqRes = append(mockSource.ReqRes, reqRes1)
+	c.Client.HTTP = mockSource
+
+	mockDest := helper.NewMockHTTP()
+	mockDest.ReqRes = append(mockDest.ReqRes, reqRes2)
+	c.Client2.HTTP = mockDest
+
+	err := copy(c, c.Ngsi, c.Client)
+
+	if assert.NoError(t, err) {
+		actual := helper.GetStdoutString(c)
+		expected := "1\ncopied: 1, skipped: 0, failed: 0\n"
+		assert.Equal(t, expected, actual)
+	}
+}
+
+func TestCopyLDV2ErrorHTTP(t *testing.T) {
+	c := setupTestWithConfig([]string{"cp", "--host", "orion-src", "--host2", "orion-dest", "--type", "Thing", "--run"}, testCopyLDV2Conf)
+
+	reqRes1 := helper.MockHTTPReqRes{}
+	reqRes1.Err = errors.New("http error")
+	reqRes1.Path = "/ngsi-ld/v1/entities"
+	helper.SetClientHTTP(c, reqRes1)
+
+	err := copyLDV2(c, c.Ngsi, c.Client, c.Client2, "Thing", nil, &copyStatus{})
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 1, ngsiErr.ErrNo)
+		assert.Equal(t, "http error", ngsiErr.Message)
+	}
+}
+
+func TestCopyLDV2ErrorStatus(t *testing.T) {
+	c := setupTestWithConfig([]string{"cp", "--host", "orion-src", "--host2", "orion-dest", "--type", "Thing", "--run"}, testCopyLDV2Conf)
+
+	reqRes1 := helper.MockHTTPReqRes{}
+	reqRes1.Res.StatusCode = http.StatusBadRequest
+	reqRes1.ResBody = []byte("error")
+	reqRes1.Path = "/ngsi-ld/v1/entities"
+	helper.SetClientHTTP(c, reqRes1)
+
+	err := copyLDV2(c, c.Ngsi, c.Client, c.Client2, "Thing", nil, &copyStatus{})
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 2, ngsiErr.ErrNo)
+		assert.Equal(t, " error", ngsiErr.Message)
+	}
+}
+
+func TestCopyLDV2ErrorResultsCount(t *testing.T) {
+	c := setupTestWithConfig([]string{"cp", "--host", "orion-src", "--host2", "orion-dest", "--type", "Thing", "--run"}, testCopyLDV2Conf)
+
+	reqRes1 := helper.MockHTTPReqRes{}
+	reqRes1.Res.StatusCode = http.StatusOK
+	reqRes1.ResBody = []byte(`[]`)
+	reqRes1.Path = "/ngsi-ld/v1/entities"
+	helper.SetClientHTTP(c, reqRes1)
+
+	err := copyLDV2(c, c.Ngsi, c.Client, c.Client2, "Thing", nil, &copyStatus{})
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 3, ngsiErr.ErrNo)
+		assert.Equal(t, "results count error", ngsiErr.Message)
+	}
+}
+
+func TestCopyLDV2ErrorConvert(t *testing.T) {
+	c := setupTestWithConfig([]string{"cp", "--host", "orion-src", "--host2", "orion-dest", "--type", "Thing", "--run"}, testCopyLDV2Conf)
+
+	reqRes1 := helper.MockHTTPReqRes{}
+	reqRes1.Res.StatusCode = http.StatusOK
+	reqRes1.ResBody = []byte(`[{"id":"urn:ngsi-ld:Thing:001","type":"Thing","temperature":1}]`)
+	reqRes1.ResHeader = http.Header{"Ngsild-Results-Count": []string{"1"}}
+	reqRes1.Path = "/ngsi-ld/v1/entities"
+	helper.SetClientHTTP(c, reqRes1)
+
+	err := copyLDV2(c, c.Ngsi, c.Client, c.Client2, "Thing", nil, &copyStatus{})
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 4, ngsiErr.ErrNo)
+		assert.Equal(t, "temperature: attribute error", ngsiErr.Message)
+	}
+}
+
+func TestCopyLDV2ErrorOpUpdate(t *testing.T) {
+	c := setupTestWithConfig([]string{"cp", "--host", "orion-src", "--host2", "orion-dest", "--type", "Thing", "--run"}, testCopyLDV2Conf)
+
+	reqRes1 := helper.MockHTTPReqRes{}
+	reqRes1.Res.StatusCode = http.StatusOK
+	reqRes1.ResBody = []byte(`[{"id":"urn:ngsi-ld:Thing:001","type":"Thing"}]`)
+	reqRes1.ResHeader = http.Header{"Ngsild-Results-Count": []string{"1"}}
+	reqRes1.Path = "/ngsi-ld/v1/entities"
+
+	reqRes2 := helper.MockHTTPReqRes{}
+	reqRes2.Err = errors.New("http error")
+	reqRes2.Path = "/v2/op/update"
+
+	mockSource := helper.NewMockHTTP()
+	mockSource.ReqRes = append(mockSource.ReqRes, reqRes1)
+	c.Client.HTTP = mockSource
+
+	mockDest := helper.NewMockHTTP()
+	mockDest.ReqRes = append(mockDest.ReqRes, reqRes2)
+	c.Client2.HTTP = mockDest
+
+	err := copyLDV2(c, c.Ngsi, c.Client, c.Client2, "Thing", nil, &copyStatus{})
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 5, ngsiErr.ErrNo)
+		assert.Equal(t, "http error", ngsiErr.Message)
+	}
+}
+
+func TestCopyLDV2ErrorOpUpdateStatus(t *testing.T) {
+	c := setupTestWithConfig([]string{"cp", "--host", "orion-src", "--host2", "orion-dest", "--type", "Thing", "--run"}, testCopyLDV2Conf)
+
+	reqRes1 := helper.MockHTTPReqRes{}
+	reqRes1.Res.StatusCode = http.StatusOK
+	reqRes1.ResBody = []byte(`[{"id":"urn:ngsi-ld:Thing:001","type":"Thing"}]`)
+	reqRes1.ResHeader = http.Header{"Ngsild-Results-Count": []string{"1"}}
+	reqRes1.Path = "/ngsi-ld/v1/entities"
+
+	reqRes2 := helper.MockHTTPReqRes{}
+	reqRes2.Res.StatusCode = http.StatusBadRequest
+	reqRes2.ResBody = []byte("error")
+	reqRes2.Path = "/v2/op/update"
+
+	mockSource := helper.NewMockHTTP()
+	mockSource.ReqRes = append(mockSource.ReqRes, reqRes1)
+	c.Client.HTTP = mockSource
+
+	mockDest := helper.NewMockHTTP()
+	mockDest.ReqRes = append(mockDest.ReqRes, reqRes2)
+	c.Client2.HTTP = mockDest
+
+	err := copyLDV2(c, c.Ngsi, c.Client, c.Client2, "Thing", nil, &copyStatus{})
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 6, ngsiErr.ErrNo)
+		assert.Equal(t, " error", ngsiErr.Message)
+	}
+}
+
+func TestLD2Normalized(t *testing.T) {
+	body := []byte(`[{"@context":"https://context","id":"urn:ngsi-ld:Vehicle:A4567","type":"https://uri.etsi.org/ngsi-ld/default-context/Vehicle",` +
+		`"createdAt":"2021-01-01T00:00:00Z","brandName":{"type":"Property","value":"Mercedes"},` +
+		`"isParked":{"type":"Relationship","object":"urn:ngsi-ld:OffStreetParking:Downtown1","observedAt":"2021-01-01T12:00:00Z","providedBy":{"type":"Relationship","object":"urn:ngsi-ld:Person:Bob"}},` +
+		`"location":{"type":"GeoProperty","value":{"type":"Point","coordinates":[-8.5,41.2]}},` +
+		`"speed":{"type":"Property","value":80,"unitCode":"KMH"},` +
+		`"date":{"type":"Property","value":{"@type":"DateTime","@value":"2021-01-01T00:00:00Z"}},` +
+		`"name":{"type":"LanguageProperty","languageMap":{"en":"car"}},` +
+		`"http://example.org/vehicle/color":{"type":"Property","value":{"r":0}}}]`)
+
+	actual, err := ld2Normalized(body, "")
+
+	if assert.NoError(t, err) {
+		b, _ := ngsilib.JSONMarshal(actual)
+		expected := `[{"brandName":{"type":"Text","value":"Mercedes"},"color":{"type":"StructuredValue","value":{"r":0}},` +
+			`"date":{"type":"DateTime","value":"2021-01-01T00:00:00Z"},"id":"urn:ngsi-ld:Vehicle:A4567",` +
+			`"isParked":{"metadata":{"TimeInstant":{"type":"DateTime","value":"2021-01-01T12:00:00Z"},"providedBy":{"type":"Relationship","value":"urn:ngsi-ld:Person:Bob"}},"type":"Relationship","value":"urn:ngsi-ld:OffStreetParking:Downtown1"},` +
+			`"location":{"type":"geo:json","value":{"coordinates":[-8.5,41.2],"type":"Point"}},` +
+			`"name":{"type":"StructuredValue","value":{"en":"car"}},` +
+			`"speed":{"metadata":{"unitCode":{"type":"Text","value":"KMH"}},"type":"Number","value":80},"type":"Vehicle"}]`
+		assert.Equal(t, expected, string(b))
+	}
+}
+
+func TestLD2NormalizedErrorUnmarshal(t *testing.T) {
+	c := setupTestWithConfig([]string{"cp", "--host", "orion-src", "--host2", "orion-dest", "--type", "Thing"}, testCopyLDV2Conf)
+
+	helper.SetJSONDecodeErr(c.Ngsi, 0)
+
+	_, err := ld2Normalized([]byte(`[]`), "")
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 1, ngsiErr.ErrNo)
+		assert.Equal(t, "json error", ngsiErr.Message)
+	}
+}
+
+func TestLD2NormalizedErrorEntity(t *testing.T) {
+	_, err := ld2Normalized([]byte(`[{"id":1}]`), "")
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 2, ngsiErr.ErrNo)
+		assert.Equal(t, "id not string", ngsiErr.Message)
+	}
+}
+
+func TestLD2NormalizedEntityErrorType(t *testing.T) {
+	_, err := ld2NormalizedEntity(map[string]interface{}{"id": "urn:ngsi-ld:Thing:001", "type": []interface{}{"Thing"}}, "")
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 2, ngsiErr.ErrNo)
+		assert.Equal(t, "type not string", ngsiErr.Message)
+	}
+}
+
+func TestLD2NormalizedEntityErrorAttribute(t *testing.T) {
+	ld := map[string]interface{}{"id": "urn:ngsi-ld:Thing:001", "type": "Thing", "temperature": map[string]interface{}{"type": "ListProperty"}}
+
+	_, err := ld2NormalizedEntity(ld, "")
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 3, ngsiErr.ErrNo)
+		assert.Equal(t, "temperature: unknown attribute type: ListProperty", ngsiErr.Message)
+	}
+}
+
+func TestLD2NormalizedEntityErrorMultiAttribute(t *testing.T) {
+	ld := map[string]interface{}{"id": "urn:ngsi-ld:Thing:001", "type": "Thing", "temperature": []interface{}{
+		map[string]interface{}{"type": "Property", "value": 1.0},
+		map[string]interface{}{"type": "Property", "value": 2.0, "datasetId": "urn:ngsi-ld:Dataset:raw"},
+	}}
+
+	_, err := ld2NormalizedEntity(ld, "error")
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 4, ngsiErr.ErrNo)
+		assert.Equal(t, "temperature: multi-attribute", ngsiErr.Message)
+	}
+}
+
+func TestLD2NormalizedEntityErrorAttributeNotObject(t *testing.T) {
+	ld := map[string]interface{}{"id": "urn:ngsi-ld:Thing:001", "type": "Thing", "temperature": 1.0}
+
+	_, err := ld2NormalizedEntity(ld, "")
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 5, ngsiErr.ErrNo)
+		assert.Equal(t, "temperature: attribute error", ngsiErr.Message)
+	}
+}
+
+func TestLD2NormalizedEntityErrorCollision(t *testing.T) {
+	ld := map[string]interface{}{
+		"id":                             "urn:ngsi-ld:Thing:001",
+		"type":                           "Thing",
+		"https://example.com/a/location": map[string]interface{}{"type": "Property", "value": "room1"},
+		"https://example.com/b/location": map[string]interface{}{"type": "Property", "value": "room2"},
+	}
+
+	_, err := ld2NormalizedEntity(ld, "")
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 6, ngsiErr.ErrNo)
+		assert.Equal(t, "https://example.com/a/location and https://example.com/b/location are both compacted to location", ngsiErr.Message)
+	}
+}
+
+func TestLD2NormalizedEntityErrorCollisionCompacted(t *testing.T) {
+	ld := map[string]interface{}{
+		"id":                              "urn:ngsi-ld:Thing:001",
+		"type":                            "Thing",
+		"temperature":                     map[string]interface{}{"type": "Property", "value": 1.0},
+		"https://example.com/temperature": map[string]interface{}{"type": "Property", "value": 2.0},
+	}
+
+	_, err := ld2NormalizedEntity(ld, "")
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 6, ngsiErr.ErrNo)
+		assert.Equal(t, "https://example.com/temperature and temperature are both compacted to temperature", ngsiErr.Message)
+	}
+}
+
+func TestLD2NormalizedEntityErrorCollisionSuffix(t *testing.T) {
+	ld := map[string]interface{}{
+		"id":              "urn:ngsi-ld:Thing:001",
+		"type":            "Thing",
+		"temperature":     []interface{}{map[string]interface{}{"type": "Property", "value": 1.0, "datasetId": "urn:ngsi-ld:Dataset:raw"}},
+		"temperature_raw": map[string]interface{}{"type": "Property", "value": 2.0},
+	}
+
+	_, err := ld2NormalizedEntity(ld, "suffix")
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 6, ngsiErr.ErrNo)
+		assert.Equal(t, "temperature and temperature_raw are both compacted to temperature_raw", ngsiErr.Message)
+	}
+}
+
+func testCopyLDV2Instances() []interface{} {
+	return []interface{}{
+		map[string]interface{}{"type": "Property", "value": 1.0, "datasetId": "urn:ngsi-ld:Dataset:raw"},
+		map[string]interface{}{"type": "Property", "value": 2.0},
+		map[string]interface{}{"type": "Property", "value": 3.0, "datasetId": "urn:ngsi-ld:Dataset:avg"},
+	}
+}
+
+func TestV2MultiAttributeDefault(t *testing.T) {
+	actual, err := v2MultiAttribute("temperature", testCopyLDV2Instances(), "default")
+
+	if assert.NoError(t, err) {
+		expected := map[string]interface{}{"temperature": map[string]interface{}{"type": "Number", "value": 2.0}}
+		assert.Equal(t, expected, actual)
+	}
+}
+
+func TestV2MultiAttributeDefaultNoDefaultInstance(t *testing.T) {
+	instances := testCopyLDV2Instances()
+	instances = append(instances[:1], instances[2:]...)
+
+	actual, err := v2MultiAttribute("temperature", instances, "")
+
+	if assert.NoError(t, err) {
+		expected := map[string]interface{}{"temperature": map[string]interface{}{
+			"type": "Number", "value": 1.0,
+			"metadata": map[string]interface{}{"datasetId": ngsiTypeValue{Type: "Text", Value: "urn:ngsi-ld:Dataset:raw"}},
+		}}
+		assert.Equal(t, expected, actual)
+	}
+}
+
+func TestV2MultiAttributeSuffix(t *testing.T) {
+	actual, err := v2MultiAttribute("temperature", testCopyLDV2Instances(), "suffix")
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, 3, len(actual))
+		assert.Equal(t, 2.0, actual["temperature"].(map[string]interface{})["value"])
+		assert.Equal(t, 1.0, actual["temperature_raw"].(map[string]interface{})["value"])
+		assert.Equal(t, 3.0, actual["temperature_avg"].(map[string]interface{})["value"])
+	}
+}
+
+func TestV2MultiAttributeErrorSuffix(t *testing.T) {
+	instances := []interface{}{
+		map[string]interface{}{"type": "Property", "value": 1.0, "datasetId": "urn:ngsi-ld:Dataset:a:raw"},
+		map[string]interface{}{"type": "Property", "value": 2.0, "datasetId": "urn:ngsi-ld:Dataset:b:raw"},
+	}
+
+	_, err := v2MultiAttribute("temperature", instances, "suffix")
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 4, ngsiErr.ErrNo)
+		assert.Equal(t, "temperature: datasetId urn:ngsi-ld:Dataset:b:raw is compacted to temperature_raw twice", ngsiErr.Message)
+	}
+}
+
+func TestV2MultiAttributeErrorInstance(t *testing.T) {
+	_, err := v2MultiAttribute("temperature", []interface{}{"abc"}, "")
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 2, ngsiErr.ErrNo)
+		assert.Equal(t, "temperature: attribute error", ngsiErr.Message)
+	}
+}
+
+func TestV2MultiAttributeErrorAttribute(t *testing.T) {
+	_, err := v2MultiAttribute("temperature", []interface{}{map[string]interface{}{"type": "ListProperty"}}, "")
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 3, ngsiErr.ErrNo)
+		assert.Equal(t, "temperature: unknown attribute type: ListProperty", ngsiErr.Message)
+	}
+}
+
+func TestV2AttributeErrorSubAttribute(t *testing.T) {
+	_, err := v2Attribute(map[string]interface{}{"type": "Property", "value": 1.0, "accuracy": 0.1})
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 2, ngsiErr.ErrNo)
+		assert.Equal(t, "accuracy: sub-attribute error", ngsiErr.Message)
+	}
+}
+
+func TestV2AttributeErrorSubAttributeType(t *testing.T) {
+	_, err := v2Attribute(map[string]interface{}{"type": "Property", "value": 1.0, "accuracy": map[string]interface{}{"type": "ListProperty"}})
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 3, ngsiErr.ErrNo)
+		assert.Equal(t, "accuracy: unknown attribute type: ListProperty", ngsiErr.Message)
+	}
+}
+
+func TestV2Type(t *testing.T) {
+	assert.Equal(t, "Text", v2Type("abc"))
+	assert.Equal(t, "Number", v2Type(1.0))
+	assert.Equal(t, "Boolean", v2Type(true))
+	assert.Equal(t, "None", v2Type(nil))
+	assert.Equal(t, "StructuredValue", v2Type([]interface{}{1.0}))
+}
+
+func TestV2AttributeErrorMetadataCollision(t *testing.T) {
+	ldAttr := map[string]interface{}{
+		"type":                         "Property",
+		"value":                        1.0,
+		"https://example.com/a/source": map[string]interface{}{"type": "Property", "value": "a"},
+		"https://example.com/b/source": map[string]interface{}{"type": "Property", "value": "b"},
+	}
+
+	_, err := v2Attribute(ldAttr)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 4, ngsiErr.ErrNo)
+		assert.Equal(t, "https://example.com/b/source: metadata source already exists", ngsiErr.Message)
+	}
+}
+
+func TestCompactLDName(t *testing.T) {
+	assert.Equal(t, "temperature", compactLDName("https://uri.etsi.org/ngsi-ld/default-context/temperature"))
+	assert.Equal(t, "color", compactLDName("http://example.org/vehicle#color"))
+	assert.Equal(t, "raw", compactLDName("urn:ngsi-ld:Dataset:raw"))
+	assert.Equal(t, "temperature", compactLDName("temperature"))
+}
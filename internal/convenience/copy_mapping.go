@@ -0,0 +1,221 @@
+/*
+MIT License
+
+Copyright (c) 2020-2024 Kazuhito Suda
+
+This file is part of NGSI Go
+
+https://github.com/lets-fiware/ngsi-go
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+
+*/
+
+package convenience
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/lets-fiware/ngsi-go/internal/ngsicli"
+	"github.com/lets-fiware/ngsi-go/internal/ngsierr"
+	"github.com/lets-fiware/ngsi-go/internal/ngsilib"
+)
+
+// copyMappingRule rewrites the part of id or type that matches Regex with Replace.
+// Replace can refer to submatches of Regex as $1, $2 and so on.
+type copyMappingRule struct {
+	Regex   string `json:"regex"`
+	Replace string `json:"replace"`
+}
+
+// copyMappingFile is the content of a mapping file given by --map.
+// Rules are applied to each entity in the order of id, type, delete, rename and set.
+type copyMappingFile struct {
+	ID     []copyMappingRule      `json:"id"`
+	Type   []copyMappingRule      `json:"type"`
+	Delete []string               `json:"delete"`
+	Rename map[string]string      `json:"rename"`
+	Set    map[string]interface{} `json:"set"`
+}
+
+type copyMappingRegex struct {
+	re      *regexp.Regexp
+	replace string
+}
+
+// copyMapping transforms entities copied by cp. It is used by workers concurrently
+// and must not be changed after newCopyMapping.
+type copyMapping struct {
+	id     []copyMappingRegex
+	typ    []copyMappingRegex
+	delete [][]string
+	rename map[string]string
+	set    map[string]interface{}
+}
+
+func newCopyMapping(c *ngsicli.Context, ngsi *ngsilib.NGSI) (*copyMapping, error) {
+	const funcName = "newCopyMapping"
+
+	if !c.IsSet("map") {
+		return nil, nil
+	}
+
+	if c.Bool("ngsiV1") {
+		return nil, ngsierr.New(funcName, 1, "--map cannot be used with --ngsiV1", nil)
+	}
+
+	b, err := ngsi.ReadAll(c.String("map"))
+	if err != nil {
+		return nil, ngsierr.New(funcName, 2, err.Error(), err)
+	}
+
+	var file copyMappingFile
+	err = ngsilib.JSONUnmarshal(b, &file)
+	if err != nil {
+		return nil, ngsierr.New(funcName, 3, err.Error(), err)
+	}
+
+	m := &copyMapping{rename: file.Rename, set: file.Set}
+
+	m.id, err = compileCopyMappingRules(file.ID)
+	if err != nil {
+		return nil, ngsierr.New(funcName, 4, err.Error(), err)
+	}
+	m.typ, err = compileCopyMappingRules(file.Type)
+	if err != nil {
+		return nil, ngsierr.New(funcName, 5, err.Error(), err)
+	}
+
+	for _, path := range file.Delete {
+		keys := strings.Split(path, ".")
+		if isCopyMappingReserved(keys[0]) {
+			return nil, ngsierr.New(funcName, 6, "cannot delete "+path, nil)
+		}
+		m.delete = append(m.delete, keys)
+	}
+	for from, to := range file.Rename {
+		if isCopyMappingReserved(from) || isCopyMappingReserved(to) || to == "" {
+			return nil, ngsierr.New(funcName, 7, fmt.Sprintf("cannot rename %s to %s", from, to), nil)
+		}
+	}
+	for name := range file.Set {
+		if isCopyMappingReserved(name) {
+			return nil, ngsierr.New(funcName, 8, "cannot set "+name, nil)
+		}
+	}
+
+	return m, nil
+}
+
+func compileCopyMappingRules(rules []copyMappingRule) ([]copyMappingRegex, error) {
+	const funcName = "compileCopyMappingRules"
+
+	regexes := []copyMappingRegex{}
+	for _, rule := range rules {
+		re, err := regexp.Compile(rule.Regex)
+		if err != nil {
+			return nil, ngsierr.New(funcName, 1, err.Error(), err)
+		}
+		regexes = append(regexes, copyMappingRegex{re: re, replace: rule.Replace})
+	}
+
+	return regexes, nil
+}
+
+func isCopyMappingReserved(name string) bool {
+	return name == "id" || name == "type" || name == "@context"
+}
+
+// apply transforms a JSON array of entities
+func (m *copyMapping) apply(body []byte) ([]byte, error) {
+	const funcName = "apply"
+
+	var entities []map[string]interface{}
+	err := ngsilib.JSONUnmarshal(body, &entities)
+	if err != nil {
+		return nil, ngsierr.New(funcName, 1, err.Error(), err)
+	}
+
+	for _, entity := range entities {
+		m.applyEntity(entity)
+	}
+
+	b, err := ngsilib.JSONMarshal(entities)
+	if err != nil {
+		return nil, ngsierr.New(funcName, 2, err.Error(), err)
+	}
+
+	return b, nil
+}
+
+func (m *copyMapping) applyEntity(entity map[string]interface{}) {
+	if id, ok := entity["id"].(string); ok {
+		entity["id"] = rewriteCopyMapping(m.id, id)
+	}
+	if t, ok := entity["type"].(string); ok {
+		entity["type"] = rewriteCopyMapping(m.typ, t)
+	}
+
+	for _, keys := range m.delete {
+		if keys[0] == "*" {
+			for name := range entity {
+				if !isCopyMappingReserved(name) {
+					deleteCopyMappingPath(entity, append([]string{name}, keys[1:]...))
+				}
+			}
+		} else {
+			deleteCopyMappingPath(entity, keys)
+		}
+	}
+
+	renamed := map[string]interface{}{}
+	for from, to := range m.rename {
+		if value, ok := entity[from]; ok {
+			delete(entity, from)
+			renamed[to] = value
+		}
+	}
+	for name, value := range renamed {
+		entity[name] = value
+	}
+
+	for name, value := range m.set {
+		entity[name] = value
+	}
+}
+
+func rewriteCopyMapping(regexes []copyMappingRegex, s string) string {
+	for _, r := range regexes {
+		s = r.re.ReplaceAllString(s, r.replace)
+	}
+	return s
+}
+
+func deleteCopyMappingPath(v map[string]interface{}, keys []string) {
+	for len(keys) > 1 {
+		next, ok := v[keys[0]].(map[string]interface{})
+		if !ok {
+			return
+		}
+		v, keys = next, keys[1:]
+	}
+	delete(v, keys[0])
+}
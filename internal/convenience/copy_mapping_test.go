@@ -0,0 +1,271 @@
+/*
+MIT License
+
+Copyright (c) 2020-2024 Kazuhito Suda
+
+This file is part of NGSI Go
+
+https://github.com/lets-fiware/ngsi-go
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+
+*/
+
+package convenience
+
+import (
+	"testing"
+
+	"github.com/lets-fiware/ngsi-go/internal/assert"
+	"github.com/lets-fiware/ngsi-go/internal/helper"
+	"github.com/lets-fiware/ngsi-go/internal/ngsierr"
+)
+
+func TestNewCopyMapping(t *testing.T) {
+	mapping := `{"id":[{"regex":"^urn:ngsi-ld:Device:","replace":"urn:ngsi-ld:Sensor:"}],"type":[{"regex":"^Device$","replace":"Sensor"}],"delete":["humidity","*.metadata"],"rename":{"temperature":"temp"},"set":{"source":{"type":"Text","value":"migration"}}}`
+	c := setupTest([]string{"cp", "--host", "orion", "--host2", "orion-ld", "--type", "Device", "--map", mapping})
+
+	actual, err := newCopyMapping(c, c.Ngsi)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, 1, len(actual.id))
+		assert.Equal(t, 1, len(actual.typ))
+		assert.Equal(t, [][]string{{"humidity"}, {"*", "metadata"}}, actual.delete)
+		assert.Equal(t, map[string]string{"temperature": "temp"}, actual.rename)
+		assert.Equal(t, 1, len(actual.set))
+	}
+}
+
+func TestNewCopyMappingNotSet(t *testing.T) {
+	c := setupTest([]string{"cp", "--host", "orion", "--host2", "orion-ld", "--type", "Device"})
+
+	actual, err := newCopyMapping(c, c.Ngsi)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, (*copyMapping)(nil), actual)
+	}
+}
+
+func TestNewCopyMappingErrorNgsiV1(t *testing.T) {
+	c := setupTest([]string{"cp", "--host", "orion", "--host2", "orion-ld", "--type", "Device", "--ngsiV1", "--map", "{}"})
+
+	_, err := newCopyMapping(c, c.Ngsi)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 1, ngsiErr.ErrNo)
+		assert.Equal(t, "--map cannot be used with --ngsiV1", ngsiErr.Message)
+	}
+}
+
+func TestNewCopyMappingErrorReadAll(t *testing.T) {
+	c := setupTest([]string{"cp", "--host", "orion", "--host2", "orion-ld", "--type", "Device", "--map", "@mapping.json"})
+
+	c.Ngsi.ReadAll = helper.MockReadAllError
+
+	_, err := newCopyMapping(c, c.Ngsi)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 2, ngsiErr.ErrNo)
+		assert.Equal(t, "readall error", ngsiErr.Message)
+	}
+}
+
+func TestNewCopyMappingErrorJSON(t *testing.T) {
+	c := setupTest([]string{"cp", "--host", "orion", "--host2", "orion-ld", "--type", "Device", "--map", `{"delete":["humidity"]}`})
+
+	helper.SetJSONDecodeErr(c.Ngsi, 0)
+
+	_, err := newCopyMapping(c, c.Ngsi)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 3, ngsiErr.ErrNo)
+		assert.Equal(t, "json error", ngsiErr.Message)
+	}
+}
+
+func TestNewCopyMappingErrorID(t *testing.T) {
+	c := setupTest([]string{"cp", "--host", "orion", "--host2", "orion-ld", "--type", "Device", "--map", `{"id":[{"regex":"(","replace":""}]}`})
+
+	_, err := newCopyMapping(c, c.Ngsi)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 4, ngsiErr.ErrNo)
+		assert.Equal(t, "error parsing regexp: missing closing ): `(`", ngsiErr.Message)
+	}
+}
+
+func TestNewCopyMappingErrorType(t *testing.T) {
+	c := setupTest([]string{"cp", "--host", "orion", "--host2", "orion-ld", "--type", "Device", "--map", `{"type":[{"regex":"[","replace":""}]}`})
+
+	_, err := newCopyMapping(c, c.Ngsi)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 5, ngsiErr.ErrNo)
+		assert.Equal(t, "error parsing regexp: missing closing ]: `[`", ngsiErr.Message)
+	}
+}
+
+func TestNewCopyMappingErrorDelete(t *testing.T) {
+	c := setupTest([]string{"cp", "--host", "orion", "--host2", "orion-ld", "--type", "Device", "--map", `{"delete":["id"]}`})
+
+	_, err := newCopyMapping(c, c.Ngsi)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 6, ngsiErr.ErrNo)
+		assert.Equal(t, "cannot delete id", ngsiErr.Message)
+	}
+}
+
+func TestNewCopyMappingErrorRename(t *testing.T) {
+	c := setupTest([]string{"cp", "--host", "orion", "--host2", "orion-ld", "--type", "Device", "--map", `{"rename":{"name":"type"}}`})
+
+	_, err := newCopyMapping(c, c.Ngsi)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 7, ngsiErr.ErrNo)
+		assert.Equal(t, "cannot rename name to type", ngsiErr.Message)
+	}
+}
+
+func TestNewCopyMappingErrorSet(t *testing.T) {
+	c := setupTest([]string{"cp", "--host", "orion", "--host2", "orion-ld", "--type", "Device", "--map", `{"set":{"@context":"ctx"}}`})
+
+	_, err := newCopyMapping(c, c.Ngsi)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 8, ngsiErr.ErrNo)
+		assert.Equal(t, "cannot set @context", ngsiErr.Message)
+	}
+}
+
+func TestCompileCopyMappingRules(t *testing.T) {
+	actual, err := compileCopyMappingRules([]copyMappingRule{{Regex: "^a", Replace: "b"}})
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, 1, len(actual))
+		assert.Equal(t, "b", actual[0].replace)
+	}
+}
+
+func TestCompileCopyMappingRulesError(t *testing.T) {
+	_, err := compileCopyMappingRules([]copyMappingRule{{Regex: "(", Replace: ""}})
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 1, ngsiErr.ErrNo)
+		assert.Equal(t, "error parsing regexp: missing closing ): `(`", ngsiErr.Message)
+	}
+}
+
+func TestCopyMappingApply(t *testing.T) {
+	mapping := `{"id":[{"regex":"^urn:ngsi-ld:Device:(.*)$","replace":"urn:ngsi-ld:Sensor:$1"}],"type":[{"regex":"^Device$","replace":"Sensor"}],"delete":["humidity","*.metadata"],"rename":{"temperature":"temp"},"set":{"source":{"type":"Text","value":"migration"}}}`
+	c := setupTest([]string{"cp", "--host", "orion", "--host2", "orion-ld", "--type", "Device", "--map", mapping})
+
+	m, err := newCopyMapping(c, c.Ngsi)
+	assert.NoError(t, err)
+
+	body := []byte(`[{"id":"urn:ngsi-ld:Device:001","type":"Device","temperature":{"type":"Number","value":21.5,"metadata":{"unit":{"type":"Text","value":"CEL"}}},"humidity":{"type":"Number","value":40,"metadata":{}}}]`)
+
+	actual, err := m.apply(body)
+
+	if assert.NoError(t, err) {
+		expected := `[{"id":"urn:ngsi-ld:Sensor:001","source":{"type":"Text","value":"migration"},"temp":{"type":"Number","value":21.5},"type":"Sensor"}]`
+		assert.Equal(t, expected, string(actual))
+	}
+}
+
+func TestCopyMappingApplyErrorUnmarshal(t *testing.T) {
+	c := setupTest([]string{"cp", "--host", "orion", "--host2", "orion-ld", "--type", "Device"})
+
+	helper.SetJSONDecodeErr(c.Ngsi, 0)
+
+	m := &copyMapping{}
+
+	_, err := m.apply([]byte(`[{"id":"urn:ngsi-ld:Device:001"}]`))
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 1, ngsiErr.ErrNo)
+		assert.Equal(t, "json error", ngsiErr.Message)
+	}
+}
+
+func TestCopyMappingApplyErrorMarshal(t *testing.T) {
+	c := setupTest([]string{"cp", "--host", "orion", "--host2", "orion-ld", "--type", "Device"})
+
+	helper.SetJSONEncodeErr(c.Ngsi, 0)
+
+	m := &copyMapping{}
+
+	_, err := m.apply([]byte(`[{"id":"urn:ngsi-ld:Device:001"}]`))
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 2, ngsiErr.ErrNo)
+		assert.Equal(t, "json error", ngsiErr.Message)
+	}
+}
+
+func TestCopyMappingApplyEntityRename(t *testing.T) {
+	m := &copyMapping{rename: map[string]string{"a": "b", "b": "c"}}
+	entity := map[string]interface{}{"id": "e1", "type": "T", "a": 1.0, "b": 2.0}
+
+	m.applyEntity(entity)
+
+	assert.Equal(t, map[string]interface{}{"id": "e1", "type": "T", "b": 1.0, "c": 2.0}, entity)
+}
+
+func TestCopyMappingApplyEntityNotString(t *testing.T) {
+	mapping := `{"type":[{"regex":"^Device$","replace":"Sensor"}]}`
+	c := setupTest([]string{"cp", "--host", "orion", "--host2", "orion-ld", "--type", "Device", "--map", mapping})
+
+	m, err := newCopyMapping(c, c.Ngsi)
+	assert.NoError(t, err)
+
+	entity := map[string]interface{}{"id": "e1", "type": []interface{}{"Device", "Thing"}}
+
+	m.applyEntity(entity)
+
+	assert.Equal(t, map[string]interface{}{"id": "e1", "type": []interface{}{"Device", "Thing"}}, entity)
+}
+
+func TestDeleteCopyMappingPath(t *testing.T) {
+	entity := map[string]interface{}{
+		"a": map[string]interface{}{"metadata": map[string]interface{}{"unit": "CEL", "accuracy": 0.1}},
+		"b": 1.0,
+	}
+
+	deleteCopyMappingPath(entity, []string{"a", "metadata", "unit"})
+	deleteCopyMappingPath(entity, []string{"b", "metadata"})
+	deleteCopyMappingPath(entity, []string{"c"})
+
+	expected := map[string]interface{}{
+		"a": map[string]interface{}{"metadata": map[string]interface{}{"accuracy": 0.1}},
+		"b": 1.0,
+	}
+	assert.Equal(t, expected, entity)
+}
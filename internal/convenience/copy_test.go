@@ -32,7 +32,9 @@ package convenience
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
+	"strings"
 	"testing"
 
 	"github.com/lets-fiware/ngsi-go/internal/assert"
@@ -198,7 +200,7 @@ func TestCopyErrorSrcDestSame(t *testing.T) {
 	}
 }
 
-func TestCopyErrroLDV2(t *testing.T) {
+func TestCopyErrorMultiAttr(t *testing.T) {
 	conf := `{
 		"version": "1",
 		"servers": {
@@ -212,8 +214,66 @@ func TestCopyErrroLDV2(t *testing.T) {
 			}
 		}
 	}`
+	c := setupTestWithConfig([]string{"cp", "--host", "orion-src", "--host2", "orion-dest", "--type", "Thing", "--multiAttr", "first"}, conf)
+
+	err := copy(c, c.Ngsi, c.Client)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 3, ngsiErr.ErrNo)
+		assert.Equal(t, "multiAttr error: first", ngsiErr.Message)
+	}
+}
+
+func TestCopyErrorCopy(t *testing.T) {
+	conf := `{
+		"version": "1",
+		"servers": {
+			"orion-src": {
+				"serverHost": "https://orion-src",
+				"ngsiType": "v2"
+			},
+			"orion-dest": {
+				"serverHost": "https://orion-dest",
+				"ngsiType": "v2"
+			}
+		}
+	}`
 	c := setupTestWithConfig([]string{"cp", "--host", "orion-src", "--host2", "orion-dest", "--type", "Thing"}, conf)
 
+	reqRes1 := helper.MockHTTPReqRes{}
+	reqRes1.Res.StatusCode = http.StatusBadRequest
+	reqRes1.ResBody = []byte(`{"code":"400","reasonPhrase":"Bad Request"}`)
+	reqRes1.ResHeader = http.Header{"Fiware-Total-Count": []string{"1"}}
+	reqRes1.Path = "/v2/entities"
+	helper.SetClientHTTP(c, reqRes1)
+
+	err := copy(c, c.Ngsi, c.Client)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 8, ngsiErr.ErrNo)
+		assert.Equal(t, " {\"code\":\"400\",\"reasonPhrase\":\"Bad Request\"}", ngsiErr.Message)
+	}
+}
+
+func TestCopyRunSummary(t *testing.T) {
+	conf := `{
+		"version": "1",
+		"servers": {
+			"orion-src": {
+				"serverHost": "https://orion-src",
+				"ngsiType": "v2"
+			},
+			"orion-dest": {
+				"serverHost": "https://orion-dest",
+				"ngsiType": "v2"
+			}
+		}
+	}`
+	c := setupTestWithConfig([]string{"cp", "--host", "orion-src", "--host2", "orion-dest", "--type", "Thing", "--checkpoint", "cp.json", "--run"}, conf)
+	c.Ngsi.Ioutil = &helper.MockIoutilLib{WriteSkip: true}
+
 	reqRes1 := helper.MockHTTPReqRes{}
 	reqRes1.Res.StatusCode = http.StatusOK
 	reqRes1.ResBody = []byte(`[{"id":"device001"}]`)
@@ -221,16 +281,87 @@ func TestCopyErrroLDV2(t *testing.T) {
 	reqRes1.Path = "/v2/entities"
 	helper.SetClientHTTP(c, reqRes1)
 
+	reqRes2 := helper.MockHTTPReqRes{}
+	reqRes2.Res.StatusCode = http.StatusNoContent
+	mockDest := helper.NewMockHTTP()
+	mockDest.ReqRes = append(mockDest.ReqRes, reqRes2)
+	c.Client2.HTTP = mockDest
+
+	err := copy(c, c.Ngsi, c.Client)
+
+	if assert.NoError(t, err) {
+		actual := helper.GetStdoutString(c)
+		expected := "1\ncopied: 1, skipped: 0, failed: 0\n"
+		assert.Equal(t, expected, actual)
+	}
+}
+
+func TestCopyResume(t *testing.T) {
+	conf := `{
+		"version": "1",
+		"servers": {
+			"orion-src": {
+				"serverHost": "https://orion-src",
+				"ngsiType": "v2"
+			},
+			"orion-dest": {
+				"serverHost": "https://orion-dest",
+				"ngsiType": "v2"
+			}
+		}
+	}`
+	c := setupTestWithConfig([]string{"cp", "--host", "orion-src", "--host2", "orion-dest", "--type", "Device,Thing", "--checkpoint", "cp.json", "--resume", "--run"}, conf)
+	c.Ngsi.Ioutil = &helper.MockIoutilLib{WriteSkip: true, ReadFileData: []byte(`{"type":"Thing","filter":"options=count&type=Thing","page":1,"offset":100,"copied":180}`)}
+
+	reqRes1 := helper.MockHTTPReqRes{}
+	reqRes1.Res.StatusCode = http.StatusOK
+	reqRes1.ResBody = []byte(`[{"id":"device001"}]`)
+	reqRes1.ResHeader = http.Header{"Fiware-Total-Count": []string{"101"}}
+	reqRes1.Path = "/v2/entities"
+	reqRes1.RawQuery = helper.StrPtr("limit=100&offset=100&options=count&type=Thing")
+	helper.SetClientHTTP(c, reqRes1)
+
+	reqRes2 := helper.MockHTTPReqRes{}
+	reqRes2.Res.StatusCode = http.StatusNoContent
+	mockDest := helper.NewMockHTTP()
+	mockDest.ReqRes = append(mockDest.ReqRes, reqRes2)
+	c.Client2.HTTP = mockDest
+
+	err := copy(c, c.Ngsi, c.Client)
+
+	if assert.NoError(t, err) {
+		actual := helper.GetStdoutString(c)
+		expected := "1\ncopied: 1, skipped: 180, failed: 0\n"
+		assert.Equal(t, expected, actual)
+	}
+}
+
+func TestCopyErrorNewCopyStatus(t *testing.T) {
+	conf := `{
+		"version": "1",
+		"servers": {
+			"orion-src": {
+				"serverHost": "https://orion-src",
+				"ngsiType": "v2"
+			},
+			"orion-dest": {
+				"serverHost": "https://orion-dest",
+				"ngsiType": "v2"
+			}
+		}
+	}`
+	c := setupTestWithConfig([]string{"cp", "--host", "orion-src", "--host2", "orion-dest", "--type", "Thing", "--resume"}, conf)
+
 	err := copy(c, c.Ngsi, c.Client)
 
 	if assert.Error(t, err) {
 		ngsiErr := err.(*ngsierr.NgsiError)
-		assert.Equal(t, 3, ngsiErr.ErrNo)
-		assert.Equal(t, "cannot copy entities from NGSI-LD to NGSI v2", ngsiErr.Message)
+		assert.Equal(t, 6, ngsiErr.ErrNo)
+		assert.Equal(t, "--resume requires --checkpoint", ngsiErr.Message)
 	}
 }
 
-func TestCopyErrorCopy(t *testing.T) {
+func TestCopyErrorResumeTypes(t *testing.T) {
 	conf := `{
 		"version": "1",
 		"servers": {
@@ -244,21 +375,245 @@ func TestCopyErrorCopy(t *testing.T) {
 			}
 		}
 	}`
-	c := setupTestWithConfig([]string{"cp", "--host", "orion-src", "--host2", "orion-dest", "--type", "Thing"}, conf)
+	c := setupTestWithConfig([]string{"cp", "--host", "orion-src", "--host2", "orion-dest", "--type", "Thing", "--checkpoint", "cp.json", "--resume"}, conf)
+	c.Ngsi.Ioutil = &helper.MockIoutilLib{ReadFileData: []byte(`{"type":"Device","filter":"options=count&type=Device","page":1,"offset":100,"copied":100}`)}
+
+	err := copy(c, c.Ngsi, c.Client)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 7, ngsiErr.ErrNo)
+		assert.Equal(t, "type in checkpoint not found: Device", ngsiErr.Message)
+	}
+}
+
+func TestCopyErrorCopyRun(t *testing.T) {
+	conf := `{
+		"version": "1",
+		"servers": {
+			"orion-src": {
+				"serverHost": "https://orion-src",
+				"ngsiType": "v2"
+			},
+			"orion-dest": {
+				"serverHost": "https://orion-dest",
+				"ngsiType": "v2"
+			}
+		}
+	}`
+	c := setupTestWithConfig([]string{"cp", "--host", "orion-src", "--host2", "orion-dest", "--type", "Thing", "--run"}, conf)
 
 	reqRes1 := helper.MockHTTPReqRes{}
-	reqRes1.Res.StatusCode = http.StatusBadRequest
-	reqRes1.ResBody = []byte(`{"code":"400","reasonPhrase":"Bad Request"}`)
-	reqRes1.ResHeader = http.Header{"Fiware-Total-Count": []string{"1"}}
+	reqRes1.Res.StatusCode = http.StatusOK
+	reqRes1.ResBody = []byte(`[{"id":"device001"},{"id":"device002"}]`)
+	reqRes1.ResHeader = http.Header{"Fiware-Total-Count": []string{"2"}}
 	reqRes1.Path = "/v2/entities"
 	helper.SetClientHTTP(c, reqRes1)
 
+	reqRes2 := helper.MockHTTPReqRes{}
+	reqRes2.Res.StatusCode = http.StatusBadRequest
+	mockDest := helper.NewMockHTTP()
+	mockDest.ReqRes = append(mockDest.ReqRes, reqRes2)
+	c.Client2.HTTP = mockDest
+
+	err := copy(c, c.Ngsi, c.Client)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 8, ngsiErr.ErrNo)
+		actual := helper.GetStdoutString(c)
+		expected := "copied: 0, skipped: 0, failed: 2\n"
+		assert.Equal(t, expected, actual)
+	}
+}
+
+func TestCopyErrorParallel(t *testing.T) {
+	conf := `{
+		"version": "1",
+		"servers": {
+			"orion-src": {
+				"serverHost": "https://orion-src",
+				"ngsiType": "v2"
+			},
+			"orion-dest": {
+				"serverHost": "https://orion-dest",
+				"ngsiType": "v2"
+			}
+		}
+	}`
+	c := setupTestWithConfig([]string{"cp", "--host", "orion-src", "--host2", "orion-dest", "--type", "Thing", "--parallel", "0"}, conf)
+
 	err := copy(c, c.Ngsi, c.Client)
 
 	if assert.Error(t, err) {
 		ngsiErr := err.(*ngsierr.NgsiError)
 		assert.Equal(t, 4, ngsiErr.ErrNo)
-		assert.Equal(t, " {\"code\":\"400\",\"reasonPhrase\":\"Bad Request\"}", ngsiErr.Message)
+		assert.Equal(t, "parallel error: 0 (1-64)", ngsiErr.Message)
+	}
+}
+
+func TestCopyParallel(t *testing.T) {
+	conf := `{
+		"version": "1",
+		"servers": {
+			"orion-src": {
+				"serverHost": "https://orion-src",
+				"ngsiType": "v2"
+			},
+			"orion-dest": {
+				"serverHost": "https://orion-dest",
+				"ngsiType": "v2"
+			}
+		}
+	}`
+	c := setupTestWithConfig([]string{"cp", "--host", "orion-src", "--host2", "orion-dest", "--type", "Thing", "--parallel", "3", "--run"}, conf)
+
+	mockSource := helper.NewMockHTTP()
+	for i := 0; i < 3; i++ {
+		reqRes := helper.MockHTTPReqRes{}
+		reqRes.Res.StatusCode = http.StatusOK
+		reqRes.ResBody = []byte(`[{"id":"device001"},{"id":"device002"}]`)
+		reqRes.ResHeader = http.Header{"Fiware-Total-Count": []string{"250"}}
+		reqRes.Path = "/v2/entities"
+		reqRes.RawQuery = helper.StrPtr(fmt.Sprintf("limit=100&offset=%d&options=count&type=Thing", i*100))
+		mockSource.ReqRes = append(mockSource.ReqRes, reqRes)
+	}
+	c.Client.HTTP = mockSource
+
+	mockDest := helper.NewMockHTTP()
+	for i := 0; i < 3; i++ {
+		reqRes := helper.MockHTTPReqRes{}
+		reqRes.Res.StatusCode = http.StatusNoContent
+		reqRes.Path = "/v2/op/update"
+		mockDest.ReqRes = append(mockDest.ReqRes, reqRes)
+	}
+	c.Client2.HTTP = mockDest
+
+	err := copy(c, c.Ngsi, c.Client)
+
+	if assert.NoError(t, err) {
+		actual := helper.GetStdoutString(c)
+		expected := "6\ncopied: 6, skipped: 0, failed: 0\n"
+		assert.Equal(t, expected, actual)
+	}
+}
+
+func TestCopyDryRun(t *testing.T) {
+	conf := `{
+		"version": "1",
+		"servers": {
+			"orion-src": {
+				"serverHost": "https://orion-src",
+				"ngsiType": "v2"
+			},
+			"orion-dest": {
+				"serverHost": "https://orion-dest",
+				"ngsiType": "v2"
+			}
+		}
+	}`
+	c := setupTestWithConfig([]string{"--dryRun", "cp", "--host", "orion-src", "--host2", "orion-dest", "--type", "Thing"}, conf)
+
+	reqRes := helper.MockHTTPReqRes{}
+	reqRes.Res.StatusCode = http.StatusOK
+	reqRes.ResBody = []byte(`[{"id":"device001","type":"Thing"}]`)
+	reqRes.ResHeader = http.Header{"Fiware-Total-Count": []string{"1"}}
+	reqRes.Path = "/v2/entities"
+	helper.SetClientHTTP(c, reqRes)
+
+	c.Client2.HTTP = helper.NewMockHTTP()
+
+	err := copy(c, c.Ngsi, c.Client)
+
+	if assert.NoError(t, err) {
+		actual := helper.GetStdoutString(c)
+		expected := "POST https://orion-dest/v2/op/update\n" +
+			"Accept: */*\nContent-Type: application/json\nFiware-ServicePath: /\n\n" +
+			"{\"actionType\":\"append\",\"entities\":[{\"id\":\"device001\",\"type\":\"Thing\"}]}\n\n" +
+			"1 entities would be copied\n"
+		assert.Equal(t, expected, actual)
+	}
+}
+
+func TestCopyV1V1DryRun(t *testing.T) {
+	conf := `{
+		"version": "1",
+		"servers": {
+			"orion-src": {
+				"serverHost": "https://orion-src",
+				"ngsiType": "v2"
+			},
+			"orion-dest": {
+				"serverHost": "https://orion-dest",
+				"ngsiType": "v2"
+			}
+		}
+	}`
+	c := setupTestWithConfig([]string{"--dryRun", "cp", "--host", "orion-src", "--host2", "orion-dest", "--type", "Thing", "--ngsiV1"}, conf)
+
+	reqRes := helper.MockHTTPReqRes{}
+	reqRes.Res.StatusCode = http.StatusOK
+	reqRes.ResBody = []byte(`{"contextResponses":[{"contextElement":{"type":"Thing","isPattern":"false","id":"thing001","attributes":[{"name":"abc","type":"Text","value":"001"}]},"statusCode":{"code":"200","reasonPhrase":"OK"}}],"errorCode":{"code":"200","reasonPhrase":"OK","details":"Count: 1"}}`)
+	reqRes.Path = "/v1/queryContext"
+	helper.SetClientHTTP(c, reqRes)
+
+	c.Client2.HTTP = helper.NewMockHTTP()
+
+	err := copy(c, c.Ngsi, c.Client)
+
+	if assert.NoError(t, err) {
+		actual := helper.GetStdoutString(c)
+		assert.Equal(t, true, strings.HasPrefix(actual, "POST https://orion-dest/v1/updateContext\n"))
+		assert.Equal(t, true, strings.HasSuffix(actual, "1 entities would be copied\n"))
+	}
+}
+
+func TestCopyParallelError(t *testing.T) {
+	conf := `{
+		"version": "1",
+		"servers": {
+			"orion-src": {
+				"serverHost": "https://orion-src",
+				"ngsiType": "v2"
+			},
+			"orion-dest": {
+				"serverHost": "https://orion-dest",
+				"ngsiType": "v2"
+			}
+		}
+	}`
+	c := setupTestWithConfig([]string{"cp", "--host", "orion-src", "--host2", "orion-dest", "--type", "Thing", "--parallel", "2", "--run"}, conf)
+
+	mockSource := helper.NewMockHTTP()
+	for i := 0; i < 3; i++ {
+		reqRes := helper.MockHTTPReqRes{}
+		reqRes.Res.StatusCode = http.StatusOK
+		reqRes.ResBody = []byte(`[{"id":"device001"}]`)
+		reqRes.ResHeader = http.Header{"Fiware-Total-Count": []string{"300"}}
+		reqRes.Path = "/v2/entities"
+		mockSource.ReqRes = append(mockSource.ReqRes, reqRes)
+	}
+	c.Client.HTTP = mockSource
+
+	mockDest := helper.NewMockHTTP()
+	for i := 0; i < 3; i++ {
+		reqRes := helper.MockHTTPReqRes{}
+		reqRes.Res.StatusCode = http.StatusBadRequest
+		reqRes.Res.Status = "400 Bad Request"
+		reqRes.Path = "/v2/op/update"
+		mockDest.ReqRes = append(mockDest.ReqRes, reqRes)
+	}
+	c.Client2.HTTP = mockDest
+
+	err := copy(c, c.Ngsi, c.Client)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 8, ngsiErr.ErrNo)
+		assert.Equal(t, "400 Bad Request ", ngsiErr.Message)
+		actual := helper.GetStdoutString(c)
+		expected := "copied: 0, skipped: 0, failed: 1\n"
+		assert.Equal(t, expected, actual)
 	}
 }
 
@@ -306,7 +661,7 @@ func TestV2V2CopyPage(t *testing.T) {
 	mockDest.ReqRes = append(mockDest.ReqRes, reqRes4)
 	c.Client2.HTTP = mockDest
 
-	err := copyV2V2(c, c.Ngsi, c.Client, c.Client2, "Thing")
+	err := copyV2V2(c, c.Ngsi, c.Client, c.Client2, "Thing", nil, &copyStatus{})
 
 	if assert.NoError(t, err) {
 		actual := helper.GetStdoutString(c)
@@ -361,7 +716,7 @@ func TestV2V2CopyPageSkipForwarding(t *testing.T) {
 	mockDest.ReqRes = append(mockDest.ReqRes, reqRes4)
 	c.Client2.HTTP = mockDest
 
-	err := copyV2V2(c, c.Ngsi, c.Client, c.Client2, "Thing")
+	err := copyV2V2(c, c.Ngsi, c.Client, c.Client2, "Thing", nil, &copyStatus{})
 
 	if assert.NoError(t, err) {
 		actual := helper.GetStdoutString(c)
@@ -392,7 +747,7 @@ func TestCopyV2V2ErrorRunFlag(t *testing.T) {
 	reqRes.Path = "/v2/entities"
 	helper.SetClientHTTP(c, reqRes)
 
-	err := copyV2V2(c, c.Ngsi, c.Client, c.Client2, "Thing")
+	err := copyV2V2(c, c.Ngsi, c.Client, c.Client2, "Thing", nil, &copyStatus{})
 
 	if assert.NoError(t, err) {
 		actual := helper.GetStdoutString(c)
@@ -424,7 +779,7 @@ func TestCopyErrorHTTP(t *testing.T) {
 	reqRes.Err = errors.New("http error")
 	helper.SetClientHTTP(c, reqRes)
 
-	err := copyV2V2(c, c.Ngsi, c.Client, c.Client2, "Thing")
+	err := copyV2V2(c, c.Ngsi, c.Client, c.Client2, "Thing", nil, &copyStatus{})
 
 	if assert.Error(t, err) {
 		ngsiErr := err.(*ngsierr.NgsiError)
@@ -456,7 +811,7 @@ func TestCopyErrorHTTPStatus(t *testing.T) {
 	reqRes.Path = "/v2/entities"
 	helper.SetClientHTTP(c, reqRes)
 
-	err := copyV2V2(c, c.Ngsi, c.Client, c.Client2, "Thing")
+	err := copyV2V2(c, c.Ngsi, c.Client, c.Client2, "Thing", nil, &copyStatus{})
 
 	if assert.Error(t, err) {
 		ngsiErr := err.(*ngsierr.NgsiError)
@@ -487,7 +842,7 @@ func TestCopyErrorResultCount(t *testing.T) {
 	reqRes.Path = "/v2/entities"
 	helper.SetClientHTTP(c, reqRes)
 
-	err := copyV2V2(c, c.Ngsi, c.Client, c.Client2, "Thing")
+	err := copyV2V2(c, c.Ngsi, c.Client, c.Client2, "Thing", nil, &copyStatus{})
 
 	if assert.Error(t, err) {
 		ngsiErr := err.(*ngsierr.NgsiError)
@@ -518,7 +873,7 @@ func TestCopyResultCountZero(t *testing.T) {
 	reqRes.Path = "/v2/entities"
 	helper.SetClientHTTP(c, reqRes)
 
-	err := copyV2V2(c, c.Ngsi, c.Client, c.Client2, "Thing")
+	err := copyV2V2(c, c.Ngsi, c.Client, c.Client2, "Thing", nil, &copyStatus{})
 
 	if assert.NoError(t, err) {
 		actual := helper.GetStdoutString(c)
@@ -552,7 +907,7 @@ func TestCopyErrorJSONUnmarshal(t *testing.T) {
 	reqRes.Path = "/v2/entities"
 	helper.SetClientHTTP(c, reqRes)
 
-	err := copyV2V2(c, c.Ngsi, c.Client, c.Client2, "Thing")
+	err := copyV2V2(c, c.Ngsi, c.Client, c.Client2, "Thing", nil, &copyStatus{})
 
 	if assert.Error(t, err) {
 		ngsiErr := err.(*ngsierr.NgsiError)
@@ -597,7 +952,7 @@ func TestCopyErrorOpUpdate(t *testing.T) {
 	mockDest.ReqRes = append(mockDest.ReqRes, reqRes2)
 	c.Client2.HTTP = mockDest
 
-	err := copyV2V2(c, c.Ngsi, c.Client, c.Client2, "Thing")
+	err := copyV2V2(c, c.Ngsi, c.Client, c.Client2, "Thing", nil, &copyStatus{})
 
 	if assert.Error(t, err) {
 		ngsiErr := err.(*ngsierr.NgsiError)
@@ -640,7 +995,7 @@ func TestCopyErrorOpUpdateStatus(t *testing.T) {
 	mockDest.ReqRes = append(mockDest.ReqRes, reqRes2)
 	c.Client2.HTTP = mockDest
 
-	err := copyV2V2(c, c.Ngsi, c.Client, c.Client2, "Thing")
+	err := copyV2V2(c, c.Ngsi, c.Client, c.Client2, "Thing", nil, &copyStatus{})
 
 	if assert.Error(t, err) {
 		ngsiErr := err.(*ngsierr.NgsiError)
@@ -649,6 +1004,77 @@ func TestCopyErrorOpUpdateStatus(t *testing.T) {
 	}
 }
 
+func TestCopyV2V2ErrorStart(t *testing.T) {
+	conf := `{
+		"version": "1",
+		"servers": {
+			"orion-src": {
+				"serverHost": "https://orion-src",
+				"ngsiType": "v2"
+			},
+			"orion-dest": {
+				"serverHost": "https://orion-dest",
+				"ngsiType": "v2"
+			}
+		}
+	}`
+	c := setupTestWithConfig([]string{"cp", "--host", "orion-src", "--host2", "orion-dest", "--type", "Thing", "--run"}, conf)
+
+	status := &copyStatus{checkpoint: &copyCheckpoint{Type: "Thing", Filter: "type=Thing"}}
+
+	err := copyV2V2(c, c.Ngsi, c.Client, c.Client2, "Thing", nil, status)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 1, ngsiErr.ErrNo)
+		assert.Equal(t, "filter in checkpoint mismatch: type=Thing", ngsiErr.Message)
+	}
+}
+
+func TestCopyV2V2ErrorCommit(t *testing.T) {
+	conf := `{
+		"version": "1",
+		"servers": {
+			"orion-src": {
+				"serverHost": "https://orion-src",
+				"ngsiType": "v2"
+			},
+			"orion-dest": {
+				"serverHost": "https://orion-dest",
+				"ngsiType": "v2"
+			}
+		}
+	}`
+	c := setupTestWithConfig([]string{"cp", "--host", "orion-src", "--host2", "orion-dest", "--type", "Thing", "--run"}, conf)
+	c.Ngsi.Ioutil = &helper.MockIoutilLib{WriteFileErr: errors.New("write file error")}
+
+	reqRes1 := helper.MockHTTPReqRes{}
+	reqRes1.Res.StatusCode = http.StatusOK
+	reqRes1.ResBody = []byte(`[{"id":"device001"}]`)
+	reqRes1.ResHeader = http.Header{"Fiware-Total-Count": []string{"1"}}
+	reqRes1.Path = "/v2/entities"
+
+	reqRes2 := helper.MockHTTPReqRes{}
+	reqRes2.Res.StatusCode = http.StatusNoContent
+	reqRes2.Path = "/v2/op/update"
+
+	mockSource := helper.NewMockHTTP()
+	mockSource.ReqRes = append(mockSource.ReqRes, reqRes1)
+	c.Client.HTTP = mockSource
+
+	mockDest := helper.NewMockHTTP()
+	mockDest.ReqRes = append(mockDest.ReqRes, reqRes2)
+	c.Client2.HTTP = mockDest
+
+	err := copyV2V2(c, c.Ngsi, c.Client, c.Client2, "Thing", nil, &copyStatus{file: "cp.json"})
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 3, ngsiErr.ErrNo)
+		assert.Equal(t, "write file error", ngsiErr.Message)
+	}
+}
+
 func TestMakeV1Entities(t *testing.T) {
 	_ = helper.SetupTestInitCmd(nil)
 
@@ -755,7 +1181,7 @@ func TestCopyV1V1Page(t *testing.T) {
 
 	reqRes1 := helper.MockHTTPReqRes{}
 	reqRes1.Res.StatusCode = http.StatusOK
-	reqRes1.ResBody = []byte(`{"contextResponses":[{"contextElement":{"type":"Thing","isPattern":"false","id":"thing001","attributes":[{"name":"abc","type":"Text","value":"001"}]},"statusCode":{"code":"200","reasonPhrase":"OK"}},{"contextElement":{"type":"Thing","isPattern":"false","id":"thing002","attributes":[{"name":"abc","type":"Text","value":"002"}]},"statusCode":{"code":"200","reasonPhrase":"OK"}},{"contextElement":{"type":"Thing","isPattern":"false","id":"thing002","attributes":[{"name":"abc","type":"Text","value":"003"}]},"statusCode":{"code":"200","reasonPhrase":"OK"}}],"errorCode":{"code":"200","reasonPhrase":"OK","details":"Count: 300"}}`)
+	reqRes1.ResBody = []byte(`{"contextResponses":[{"contextElement":{"type":"Thing","isPattern":"false","id":"thing001","attributes":[{"name":"abc","type":"Text","value":"001"}]},"statusCode":{"code":"200","reasonPhrase":"OK"}},{"contextElement":{"type":"Thing","isPattern":"false","id":"thing002","attributes":[{"name":"abc","type":"Text","value":"002"}]},"statusCode":{"code":"200","reasonPhrase":"OK"}},{"contextElement":{"type":"Thing","isPattern":"false","id":"thing002","attributes":[{"name":"abc","type":"Text","value":"003"}]},"statusCode":{"code":"200","reasonPhrase":"OK"}}],"errorCode":{"code":"200","reasonPhrase":"OK","details":"Count: 200"}}`)
 	reqRes1.Path = "/v1/queryContext"
 
 	reqRes2 := helper.MockHTTPReqRes{}
@@ -765,7 +1191,7 @@ func TestCopyV1V1Page(t *testing.T) {
 
 	reqRes3 := helper.MockHTTPReqRes{}
 	reqRes3.Res.StatusCode = http.StatusOK
-	reqRes3.ResBody = []byte(`{"contextResponses":[{"contextElement":{"type":"Thing","isPattern":"false","id":"thing001","attributes":[{"name":"abc","type":"Text","value":"001"}]},"statusCode":{"code":"200","reasonPhrase":"OK"}},{"contextElement":{"type":"Thing","isPattern":"false","id":"thing002","attributes":[{"name":"abc","type":"Text","value":"002"}]},"statusCode":{"code":"200","reasonPhrase":"OK"}},{"contextElement":{"type":"Thing","isPattern":"false","id":"thing002","attributes":[{"name":"abc","type":"Text","value":"003"}]},"statusCode":{"code":"200","reasonPhrase":"OK"}}],"errorCode":{"code":"200","reasonPhrase":"OK","details":"Count: 3"}}`)
+	reqRes3.ResBody = []byte(`{"contextResponses":[{"contextElement":{"type":"Thing","isPattern":"false","id":"thing001","attributes":[{"name":"abc","type":"Text","value":"001"}]},"statusCode":{"code":"200","reasonPhrase":"OK"}},{"contextElement":{"type":"Thing","isPattern":"false","id":"thing002","attributes":[{"name":"abc","type":"Text","value":"002"}]},"statusCode":{"code":"200","reasonPhrase":"OK"}},{"contextElement":{"type":"Thing","isPattern":"false","id":"thing002","attributes":[{"name":"abc","type":"Text","value":"003"}]},"statusCode":{"code":"200","reasonPhrase":"OK"}}],"errorCode":{"code":"200","reasonPhrase":"OK","details":"Count: 200"}}`)
 	reqRes3.Path = "/v1/queryContext"
 
 	reqRes4 := helper.MockHTTPReqRes{}
@@ -783,7 +1209,7 @@ func TestCopyV1V1Page(t *testing.T) {
 	mockDest.ReqRes = append(mockDest.ReqRes, reqRes4)
 	c.Client2.HTTP = mockDest
 
-	err := copyV1V1(c, c.Ngsi, c.Client, c.Client2, "Thing")
+	err := copyV1V1(c, c.Ngsi, c.Client, c.Client2, "Thing", nil, &copyStatus{})
 
 	if assert.NoError(t, err) {
 		actual := helper.GetStdoutString(c)
@@ -828,7 +1254,7 @@ func TestCopyV1V1PageZero(t *testing.T) {
 	mockDest.ReqRes = append(mockDest.ReqRes, reqRes2)
 	c.Client2.HTTP = mockDest
 
-	err := copyV1V1(c, c.Ngsi, c.Client, c.Client2, "Thing")
+	err := copyV1V1(c, c.Ngsi, c.Client, c.Client2, "Thing", nil, &copyStatus{})
 
 	if assert.NoError(t, err) {
 		actual := helper.GetStdoutString(c)
@@ -861,7 +1287,7 @@ func TestCopyV1V1ErrorHTTP1(t *testing.T) {
 
 	helper.SetClientHTTP(c, reqRes1)
 
-	err := copyV1V1(c, c.Ngsi, c.Client, c.Client2, "Thing")
+	err := copyV1V1(c, c.Ngsi, c.Client, c.Client2, "Thing", nil, &copyStatus{})
 
 	if assert.Error(t, err) {
 		ngsiErr := err.(*ngsierr.NgsiError)
@@ -893,7 +1319,7 @@ func TestCopyV1V1ErrorHTTPStatus1(t *testing.T) {
 
 	helper.SetClientHTTP(c, reqRes1)
 
-	err := copyV1V1(c, c.Ngsi, c.Client, c.Client2, "Thing")
+	err := copyV1V1(c, c.Ngsi, c.Client, c.Client2, "Thing", nil, &copyStatus{})
 
 	if assert.Error(t, err) {
 		ngsiErr := err.(*ngsierr.NgsiError)
@@ -925,7 +1351,7 @@ func TestCopyV1V1ErrorMkaeV1Entities(t *testing.T) {
 
 	helper.SetClientHTTP(c, reqRes1)
 
-	err := copyV1V1(c, c.Ngsi, c.Client, c.Client2, "Thing")
+	err := copyV1V1(c, c.Ngsi, c.Client, c.Client2, "Thing", nil, &copyStatus{})
 
 	if assert.Error(t, err) {
 		ngsiErr := err.(*ngsierr.NgsiError)
@@ -969,7 +1395,7 @@ func TestCopyV1V1ErrorHTTP2(t *testing.T) {
 	mockDest.ReqRes = append(mockDest.ReqRes, reqRes2)
 	c.Client2.HTTP = mockDest
 
-	err := copyV1V1(c, c.Ngsi, c.Client, c.Client2, "Thing")
+	err := copyV1V1(c, c.Ngsi, c.Client, c.Client2, "Thing", nil, &copyStatus{})
 
 	if assert.Error(t, err) {
 		ngsiErr := err.(*ngsierr.NgsiError)
@@ -1012,7 +1438,7 @@ func TestCopyV1V1ErrorHTTPStatus2(t *testing.T) {
 	mockDest.ReqRes = append(mockDest.ReqRes, reqRes2)
 	c.Client2.HTTP = mockDest
 
-	err := copyV1V1(c, c.Ngsi, c.Client, c.Client2, "Thing")
+	err := copyV1V1(c, c.Ngsi, c.Client, c.Client2, "Thing", nil, &copyStatus{})
 
 	if assert.Error(t, err) {
 		ngsiErr := err.(*ngsierr.NgsiError)
@@ -1055,7 +1481,7 @@ func TestCopyV1V1ErrorUnmarshal(t *testing.T) {
 	mockDest.ReqRes = append(mockDest.ReqRes, reqRes2)
 	c.Client2.HTTP = mockDest
 
-	err := copyV1V1(c, c.Ngsi, c.Client, c.Client2, "Thing")
+	err := copyV1V1(c, c.Ngsi, c.Client, c.Client2, "Thing", nil, &copyStatus{})
 
 	if assert.Error(t, err) {
 		ngsiErr := err.(*ngsierr.NgsiError)
@@ -1098,7 +1524,7 @@ func TestCopyV1V1ErrorStatusCode(t *testing.T) {
 	mockDest.ReqRes = append(mockDest.ReqRes, reqRes2)
 	c.Client2.HTTP = mockDest
 
-	err := copyV1V1(c, c.Ngsi, c.Client, c.Client2, "Thing")
+	err := copyV1V1(c, c.Ngsi, c.Client, c.Client2, "Thing", nil, &copyStatus{})
 
 	if assert.Error(t, err) {
 		ngsiErr := err.(*ngsierr.NgsiError)
@@ -1107,6 +1533,77 @@ func TestCopyV1V1ErrorStatusCode(t *testing.T) {
 	}
 }
 
+func TestCopyV1V1ErrorStart(t *testing.T) {
+	conf := `{
+		"version": "1",
+		"servers": {
+			"orion-src": {
+				"serverHost": "https://orion-src",
+				"ngsiType": "v2"
+			},
+			"orion-dest": {
+				"serverHost": "https://orion-dest",
+				"ngsiType": "v2"
+			}
+		}
+	}`
+	c := setupTestWithConfig([]string{"cp", "--host", "orion-src", "--host2", "orion-dest", "--type", "Thing", "--ngsiV1", "--run"}, conf)
+
+	status := &copyStatus{checkpoint: &copyCheckpoint{Type: "Thing", Filter: "type=Thing"}}
+
+	err := copyV1V1(c, c.Ngsi, c.Client, c.Client2, "Thing", nil, status)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 1, ngsiErr.ErrNo)
+		assert.Equal(t, "filter in checkpoint mismatch: type=Thing", ngsiErr.Message)
+	}
+}
+
+func TestCopyV1V1ErrorCommit(t *testing.T) {
+	conf := `{
+		"version": "1",
+		"servers": {
+			"orion-src": {
+				"serverHost": "https://orion-src",
+				"ngsiType": "v2"
+			},
+			"orion-dest": {
+				"serverHost": "https://orion-dest",
+				"ngsiType": "v2"
+			}
+		}
+	}`
+	c := setupTestWithConfig([]string{"cp", "--host", "orion-src", "--host2", "orion-dest", "--type", "Thing", "--ngsiV1", "--run"}, conf)
+	c.Ngsi.Ioutil = &helper.MockIoutilLib{WriteFileErr: errors.New("write file error")}
+
+	reqRes1 := helper.MockHTTPReqRes{}
+	reqRes1.Res.StatusCode = http.StatusOK
+	reqRes1.ResBody = []byte(`{"contextResponses":[{"contextElement":{"type":"Thing","isPattern":"false","id":"thing001","attributes":[{"name":"abc","type":"Text","value":"001"}]},"statusCode":{"code":"200","reasonPhrase":"OK"}}],"errorCode":{"code":"200","reasonPhrase":"OK","details":"Count: 1"}}`)
+	reqRes1.Path = "/v1/queryContext"
+
+	reqRes2 := helper.MockHTTPReqRes{}
+	reqRes2.Res.StatusCode = http.StatusOK
+	reqRes2.ResBody = []byte(`{"contextResponses":[{"contextElement":{"type":"Thing","isPattern":"false","id":"thing001","attributes":[{"name":"abc","type":"Text","value":"001"}]},"statusCode":{"code":"200","reasonPhrase":"OK"}}],"errorCode":{"code":"200","reasonPhrase":"OK","details":"Count: 1"}}`)
+	reqRes2.Path = "/v1/updateContext"
+
+	mockSource := helper.NewMockHTTP()
+	mockSource.ReqRes = append(mockSource.ReqRes, reqRes1)
+	c.Client.HTTP = mockSource
+
+	mockDest := helper.NewMockHTTP()
+	mockDest.ReqRes = append(mockDest.ReqRes, reqRes2)
+	c.Client2.HTTP = mockDest
+
+	err := copyV1V1(c, c.Ngsi, c.Client, c.Client2, "Thing", nil, &copyStatus{file: "cp.json"})
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 3, ngsiErr.ErrNo)
+		assert.Equal(t, "write file error", ngsiErr.Message)
+	}
+}
+
 func TestLDLDCopyPage(t *testing.T) {
 	conf := `{
 		"version": "1",
@@ -1136,7 +1633,8 @@ func TestLDLDCopyPage(t *testing.T) {
 	reqRes3 := helper.MockHTTPReqRes{}
 	reqRes3.Res.StatusCode = http.StatusOK
 	reqRes3.ResBody = []byte(`[{"id":"device001"}]`)
-	reqRes3.ResHeader = http.Header{"Ngsild-Results-Count": []string{"1"}}
+	reqRes3.ResHeader = http.Header{"Ngsild-Results-Count": []string{"200"}}
+	reqRes3.RawQuery = helper.StrPtr("count=true&limit=100&offset=100&type=Thing")
 	reqRes3.Path = "/ngsi-ld/v1/entities"
 
 	reqRes4 := helper.MockHTTPReqRes{}
@@ -1153,11 +1651,11 @@ func TestLDLDCopyPage(t *testing.T) {
 	mockDest.ReqRes = append(mockDest.ReqRes, reqRes4)
 	c.Client2.HTTP = mockDest
 
-	err := copyLDLD(c, c.Ngsi, c.Client, c.Client2, "Thing")
+	err := copyLDLD(c, c.Ngsi, c.Client, c.Client2, "Thing", nil, &copyStatus{})
 
 	if assert.NoError(t, err) {
 		actual := helper.GetStdoutString(c)
-		expected := "201\n"
+		expected := "200\n"
 		assert.Equal(t, expected, actual)
 	}
 }
@@ -1196,7 +1694,7 @@ func TestLDLDCopyPageCountZero(t *testing.T) {
 	mockDest.ReqRes = append(mockDest.ReqRes, reqRes2)
 	c.Client2.HTTP = mockDest
 
-	err := copyLDLD(c, c.Ngsi, c.Client, c.Client2, "Thing")
+	err := copyLDLD(c, c.Ngsi, c.Client, c.Client2, "Thing", nil, &copyStatus{})
 
 	if assert.NoError(t, err) {
 		actual := helper.GetStdoutString(c)
@@ -1239,7 +1737,7 @@ func TestLDLDCopyPageContext(t *testing.T) {
 	mockDest.ReqRes = append(mockDest.ReqRes, reqRes2)
 	c.Client2.HTTP = mockDest
 
-	err := copyLDLD(c, c.Ngsi, c.Client, c.Client2, "Thing")
+	err := copyLDLD(c, c.Ngsi, c.Client, c.Client2, "Thing", nil, &copyStatus{})
 
 	if assert.NoError(t, err) {
 		actual := helper.GetStdoutString(c)
@@ -1273,7 +1771,7 @@ func TestLDLDCopyErrorHTTP1(t *testing.T) {
 
 	helper.SetClientHTTP(c, reqRes1)
 
-	err := copyLDLD(c, c.Ngsi, c.Client, c.Client2, "Thing")
+	err := copyLDLD(c, c.Ngsi, c.Client, c.Client2, "Thing", nil, &copyStatus{})
 
 	if assert.Error(t, err) {
 		ngsiErr := err.(*ngsierr.NgsiError)
@@ -1306,7 +1804,7 @@ func TestLDLDCopyErrorHTTPStatus1(t *testing.T) {
 
 	helper.SetClientHTTP(c, reqRes1)
 
-	err := copyLDLD(c, c.Ngsi, c.Client, c.Client2, "Thing")
+	err := copyLDLD(c, c.Ngsi, c.Client, c.Client2, "Thing", nil, &copyStatus{})
 
 	if assert.Error(t, err) {
 		ngsiErr := err.(*ngsierr.NgsiError)
@@ -1338,7 +1836,7 @@ func TestLDLDCopyErrorCount(t *testing.T) {
 
 	helper.SetClientHTTP(c, reqRes1)
 
-	err := copyLDLD(c, c.Ngsi, c.Client, c.Client2, "Thing")
+	err := copyLDLD(c, c.Ngsi, c.Client, c.Client2, "Thing", nil, &copyStatus{})
 
 	if assert.Error(t, err) {
 		ngsiErr := err.(*ngsierr.NgsiError)
@@ -1347,7 +1845,94 @@ func TestLDLDCopyErrorCount(t *testing.T) {
 	}
 }
 
-func TestLDLDCopyErrorContext(t *testing.T) {
+func TestLDLDCopyErrorContext(t *testing.T) {
+	conf := `{
+		"version": "1",
+		"servers": {
+			"orion-src": {
+				"serverHost": "https://orion-src",
+				"ngsiType": "ld"
+			},
+			"orion-dest": {
+				"serverHost": "https://orion-dest",
+				"ngsiType": "ld"
+			}
+		}
+	}`
+	c := setupTestWithConfig([]string{"cp", "--host", "orion-src", "--host2", "orion-dest", "--context2", "abc", "--type", "Thing", "--run"}, conf)
+
+	reqRes1 := helper.MockHTTPReqRes{}
+	reqRes1.Res.StatusCode = http.StatusOK
+	reqRes1.ResBody = []byte(`[{"id":"device001"}]`)
+	reqRes1.ResHeader = http.Header{"Ngsild-Results-Count": []string{"3"}}
+	reqRes1.Path = "/ngsi-ld/v1/entities"
+
+	reqRes2 := helper.MockHTTPReqRes{}
+	reqRes2.Res.StatusCode = http.StatusOK
+	reqRes2.Path = "/ngsi-ld/v1/entityOperations/create"
+
+	mockSource := helper.NewMockHTTP()
+	mockSource.ReqRes = append(mockSource.ReqRes, reqRes1)
+	c.Client.HTTP = mockSource
+
+	mockDest := helper.NewMockHTTP()
+	mockDest.ReqRes = append(mockDest.ReqRes, reqRes2)
+	c.Client2.HTTP = mockDest
+
+	err := copyLDLD(c, c.Ngsi, c.Client, c.Client2, "Thing", nil, &copyStatus{})
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 4, ngsiErr.ErrNo)
+		assert.Equal(t, "abc not found", ngsiErr.Message)
+	}
+}
+
+func TestLDLDCopyErrorHTTP2(t *testing.T) {
+	conf := `{
+		"version": "1",
+		"servers": {
+			"orion-src": {
+				"serverHost": "https://orion-src",
+				"ngsiType": "ld"
+			},
+			"orion-dest": {
+				"serverHost": "https://orion-dest",
+				"ngsiType": "ld"
+			}
+		}
+	}`
+	c := setupTestWithConfig([]string{"cp", "--host", "orion-src", "--host2", "orion-dest", "--type", "Thing", "--run"}, conf)
+
+	reqRes1 := helper.MockHTTPReqRes{}
+	reqRes1.Res.StatusCode = http.StatusOK
+	reqRes1.ResBody = []byte(`[{"id":"device001"}]`)
+	reqRes1.ResHeader = http.Header{"Ngsild-Results-Count": []string{"3"}}
+	reqRes1.Path = "/ngsi-ld/v1/entities"
+
+	reqRes2 := helper.MockHTTPReqRes{}
+	reqRes2.Res.StatusCode = http.StatusOK
+	reqRes2.Path = "/ngsi-ld/v1/entityOperations/create"
+	reqRes2.Err = errors.New("http error")
+
+	mockSource := helper.NewMockHTTP()
+	mockSource.ReqRes = append(mockSource.ReqRes, reqRes1)
+	c.Client.HTTP = mockSource
+
+	mockDest := helper.NewMockHTTP()
+	mockDest.ReqRes = append(mockDest.ReqRes, reqRes2)
+	c.Client2.HTTP = mockDest
+
+	err := copyLDLD(c, c.Ngsi, c.Client, c.Client2, "Thing", nil, &copyStatus{})
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 5, ngsiErr.ErrNo)
+		assert.Equal(t, "http error", ngsiErr.Message)
+	}
+}
+
+func TestLDLDCopyErrorHTTPStatus2(t *testing.T) {
 	conf := `{
 		"version": "1",
 		"servers": {
@@ -1361,7 +1946,7 @@ func TestLDLDCopyErrorContext(t *testing.T) {
 			}
 		}
 	}`
-	c := setupTestWithConfig([]string{"cp", "--host", "orion-src", "--host2", "orion-dest", "--context2", "abc", "--type", "Thing", "--run"}, conf)
+	c := setupTestWithConfig([]string{"cp", "--host", "orion-src", "--host2", "orion-dest", "--type", "Thing", "--run"}, conf)
 
 	reqRes1 := helper.MockHTTPReqRes{}
 	reqRes1.Res.StatusCode = http.StatusOK
@@ -1370,8 +1955,9 @@ func TestLDLDCopyErrorContext(t *testing.T) {
 	reqRes1.Path = "/ngsi-ld/v1/entities"
 
 	reqRes2 := helper.MockHTTPReqRes{}
-	reqRes2.Res.StatusCode = http.StatusOK
+	reqRes2.Res.StatusCode = http.StatusBadRequest
 	reqRes2.Path = "/ngsi-ld/v1/entityOperations/create"
+	reqRes2.ResBody = []byte(`{"code":"400","reasonPhrase":"Bad Request"}`)
 
 	mockSource := helper.NewMockHTTP()
 	mockSource.ReqRes = append(mockSource.ReqRes, reqRes1)
@@ -1381,16 +1967,16 @@ func TestLDLDCopyErrorContext(t *testing.T) {
 	mockDest.ReqRes = append(mockDest.ReqRes, reqRes2)
 	c.Client2.HTTP = mockDest
 
-	err := copyLDLD(c, c.Ngsi, c.Client, c.Client2, "Thing")
+	err := copyLDLD(c, c.Ngsi, c.Client, c.Client2, "Thing", nil, &copyStatus{})
 
 	if assert.Error(t, err) {
 		ngsiErr := err.(*ngsierr.NgsiError)
-		assert.Equal(t, 4, ngsiErr.ErrNo)
-		assert.Equal(t, "abc not found", ngsiErr.Message)
+		assert.Equal(t, 6, ngsiErr.ErrNo)
+		assert.Equal(t, " {\"code\":\"400\",\"reasonPhrase\":\"Bad Request\"}", ngsiErr.Message)
 	}
 }
 
-func TestLDLDCopyErrorHTTP2(t *testing.T) {
+func TestLDLDCopyErrorStart(t *testing.T) {
 	conf := `{
 		"version": "1",
 		"servers": {
@@ -1406,35 +1992,18 @@ func TestLDLDCopyErrorHTTP2(t *testing.T) {
 	}`
 	c := setupTestWithConfig([]string{"cp", "--host", "orion-src", "--host2", "orion-dest", "--type", "Thing", "--run"}, conf)
 
-	reqRes1 := helper.MockHTTPReqRes{}
-	reqRes1.Res.StatusCode = http.StatusOK
-	reqRes1.ResBody = []byte(`[{"id":"device001"}]`)
-	reqRes1.ResHeader = http.Header{"Ngsild-Results-Count": []string{"3"}}
-	reqRes1.Path = "/ngsi-ld/v1/entities"
-
-	reqRes2 := helper.MockHTTPReqRes{}
-	reqRes2.Res.StatusCode = http.StatusOK
-	reqRes2.Path = "/ngsi-ld/v1/entityOperations/create"
-	reqRes2.Err = errors.New("http error")
-
-	mockSource := helper.NewMockHTTP()
-	mockSource.ReqRes = append(mockSource.ReqRes, reqRes1)
-	c.Client.HTTP = mockSource
-
-	mockDest := helper.NewMockHTTP()
-	mockDest.ReqRes = append(mockDest.ReqRes, reqRes2)
-	c.Client2.HTTP = mockDest
+	status := &copyStatus{checkpoint: &copyCheckpoint{Type: "Thing", Filter: "type=Thing"}}
 
-	err := copyLDLD(c, c.Ngsi, c.Client, c.Client2, "Thing")
+	err := copyLDLD(c, c.Ngsi, c.Client, c.Client2, "Thing", nil, status)
 
 	if assert.Error(t, err) {
 		ngsiErr := err.(*ngsierr.NgsiError)
-		assert.Equal(t, 5, ngsiErr.ErrNo)
-		assert.Equal(t, "http error", ngsiErr.Message)
+		assert.Equal(t, 1, ngsiErr.ErrNo)
+		assert.Equal(t, "filter in checkpoint mismatch: type=Thing", ngsiErr.Message)
 	}
 }
 
-func TestLDLDCopyErrorHTTPStatus2(t *testing.T) {
+func TestLDLDCopyErrorCommit(t *testing.T) {
 	conf := `{
 		"version": "1",
 		"servers": {
@@ -1449,17 +2018,17 @@ func TestLDLDCopyErrorHTTPStatus2(t *testing.T) {
 		}
 	}`
 	c := setupTestWithConfig([]string{"cp", "--host", "orion-src", "--host2", "orion-dest", "--type", "Thing", "--run"}, conf)
+	c.Ngsi.Ioutil = &helper.MockIoutilLib{WriteFileErr: errors.New("write file error")}
 
 	reqRes1 := helper.MockHTTPReqRes{}
 	reqRes1.Res.StatusCode = http.StatusOK
 	reqRes1.ResBody = []byte(`[{"id":"device001"}]`)
-	reqRes1.ResHeader = http.Header{"Ngsild-Results-Count": []string{"3"}}
+	reqRes1.ResHeader = http.Header{"Ngsild-Results-Count": []string{"1"}}
 	reqRes1.Path = "/ngsi-ld/v1/entities"
 
 	reqRes2 := helper.MockHTTPReqRes{}
-	reqRes2.Res.StatusCode = http.StatusBadRequest
+	reqRes2.Res.StatusCode = http.StatusCreated
 	reqRes2.Path = "/ngsi-ld/v1/entityOperations/create"
-	reqRes2.ResBody = []byte(`{"code":"400","reasonPhrase":"Bad Request"}`)
 
 	mockSource := helper.NewMockHTTP()
 	mockSource.ReqRes = append(mockSource.ReqRes, reqRes1)
@@ -1469,12 +2038,12 @@ func TestLDLDCopyErrorHTTPStatus2(t *testing.T) {
 	mockDest.ReqRes = append(mockDest.ReqRes, reqRes2)
 	c.Client2.HTTP = mockDest
 
-	err := copyLDLD(c, c.Ngsi, c.Client, c.Client2, "Thing")
+	err := copyLDLD(c, c.Ngsi, c.Client, c.Client2, "Thing", nil, &copyStatus{file: "cp.json"})
 
 	if assert.Error(t, err) {
 		ngsiErr := err.(*ngsierr.NgsiError)
-		assert.Equal(t, 6, ngsiErr.ErrNo)
-		assert.Equal(t, " {\"code\":\"400\",\"reasonPhrase\":\"Bad Request\"}", ngsiErr.Message)
+		assert.Equal(t, 3, ngsiErr.ErrNo)
+		assert.Equal(t, "write file error", ngsiErr.Message)
 	}
 }
 
@@ -1507,7 +2076,7 @@ func TestV2LDCopyPage(t *testing.T) {
 	reqRes3 := helper.MockHTTPReqRes{}
 	reqRes3.Res.StatusCode = http.StatusOK
 	reqRes3.ResBody = []byte(`[{"id":"device001","type":"T"}]`)
-	reqRes3.ResHeader = http.Header{"Fiware-Total-Count": []string{"1"}}
+	reqRes3.ResHeader = http.Header{"Fiware-Total-Count": []string{"200"}}
 	reqRes3.Path = "/v2/entities"
 
 	reqRes4 := helper.MockHTTPReqRes{}
@@ -1524,11 +2093,11 @@ func TestV2LDCopyPage(t *testing.T) {
 	mockDest.ReqRes = append(mockDest.ReqRes, reqRes4)
 	c.Client2.HTTP = mockDest
 
-	err := copyV2LD(c, c.Ngsi, c.Client, c.Client2, "Thing")
+	err := copyV2LD(c, c.Ngsi, c.Client, c.Client2, "Thing", nil, &copyStatus{})
 
 	if assert.NoError(t, err) {
 		actual := helper.GetStdoutString(c)
-		expected := "201\n"
+		expected := "200\n"
 		assert.Equal(t, expected, actual)
 	}
 }
@@ -1564,7 +2133,7 @@ func TestV2LDCopyPageSkipForwarding(t *testing.T) {
 	reqRes3 := helper.MockHTTPReqRes{}
 	reqRes3.Res.StatusCode = http.StatusOK
 	reqRes3.ResBody = []byte(`[{"id":"device001","type":"T"}]`)
-	reqRes3.ResHeader = http.Header{"Fiware-Total-Count": []string{"1"}}
+	reqRes3.ResHeader = http.Header{"Fiware-Total-Count": []string{"200"}}
 	reqRes3.Path = "/v2/entities"
 
 	reqRes4 := helper.MockHTTPReqRes{}
@@ -1581,11 +2150,11 @@ func TestV2LDCopyPageSkipForwarding(t *testing.T) {
 	mockDest.ReqRes = append(mockDest.ReqRes, reqRes4)
 	c.Client2.HTTP = mockDest
 
-	err := copyV2LD(c, c.Ngsi, c.Client, c.Client2, "Thing")
+	err := copyV2LD(c, c.Ngsi, c.Client, c.Client2, "Thing", nil, &copyStatus{})
 
 	if assert.NoError(t, err) {
 		actual := helper.GetStdoutString(c)
-		expected := "201\n"
+		expected := "200\n"
 		assert.Equal(t, expected, actual)
 	}
 }
@@ -1616,7 +2185,7 @@ func TestV2LDCopyPageCountZero(t *testing.T) {
 	mockSource.ReqRes = append(mockSource.ReqRes, reqRes1)
 	c.Client.HTTP = mockSource
 
-	err := copyV2LD(c, c.Ngsi, c.Client, c.Client2, "Thing")
+	err := copyV2LD(c, c.Ngsi, c.Client, c.Client2, "Thing", nil, &copyStatus{})
 
 	if assert.NoError(t, err) {
 		actual := helper.GetStdoutString(c)
@@ -1659,7 +2228,7 @@ func TestV2LDCopyPageContext(t *testing.T) {
 	mockDest.ReqRes = append(mockDest.ReqRes, reqRes2)
 	c.Client2.HTTP = mockDest
 
-	err := copyV2LD(c, c.Ngsi, c.Client, c.Client2, "Thing")
+	err := copyV2LD(c, c.Ngsi, c.Client, c.Client2, "Thing", nil, &copyStatus{})
 
 	if assert.NoError(t, err) {
 		actual := helper.GetStdoutString(c)
@@ -1693,7 +2262,7 @@ func TestV2LDCopyErrorHTTP1(t *testing.T) {
 
 	helper.SetClientHTTP(c, reqRes1)
 
-	err := copyV2LD(c, c.Ngsi, c.Client, c.Client2, "Thing")
+	err := copyV2LD(c, c.Ngsi, c.Client, c.Client2, "Thing", nil, &copyStatus{})
 
 	if assert.Error(t, err) {
 		ngsiErr := err.(*ngsierr.NgsiError)
@@ -1726,7 +2295,7 @@ func TestV2LDCopyErrorHTTPStatus1(t *testing.T) {
 
 	helper.SetClientHTTP(c, reqRes1)
 
-	err := copyV2LD(c, c.Ngsi, c.Client, c.Client2, "Thing")
+	err := copyV2LD(c, c.Ngsi, c.Client, c.Client2, "Thing", nil, &copyStatus{})
 
 	if assert.Error(t, err) {
 		ngsiErr := err.(*ngsierr.NgsiError)
@@ -1758,7 +2327,7 @@ func TestV2LDCopyErrorCount(t *testing.T) {
 
 	helper.SetClientHTTP(c, reqRes1)
 
-	err := copyV2LD(c, c.Ngsi, c.Client, c.Client2, "Thing")
+	err := copyV2LD(c, c.Ngsi, c.Client, c.Client2, "Thing", nil, &copyStatus{})
 
 	if assert.Error(t, err) {
 		ngsiErr := err.(*ngsierr.NgsiError)
@@ -1791,7 +2360,7 @@ func TestV2LDCopyErrornormalized2LD(t *testing.T) {
 
 	helper.SetClientHTTP(c, reqRes1)
 
-	err := copyV2LD(c, c.Ngsi, c.Client, c.Client2, "Thing")
+	err := copyV2LD(c, c.Ngsi, c.Client, c.Client2, "Thing", nil, &copyStatus{})
 
 	if assert.Error(t, err) {
 		ngsiErr := err.(*ngsierr.NgsiError)
@@ -1824,7 +2393,7 @@ func TestV2LDCopyErrorContext(t *testing.T) {
 
 	helper.SetClientHTTP(c, reqRes1)
 
-	err := copyV2LD(c, c.Ngsi, c.Client, c.Client2, "Thing")
+	err := copyV2LD(c, c.Ngsi, c.Client, c.Client2, "Thing", nil, &copyStatus{})
 
 	if assert.Error(t, err) {
 		ngsiErr := err.(*ngsierr.NgsiError)
@@ -1868,7 +2437,7 @@ func TestV2LDCopyErrorHTTP2(t *testing.T) {
 	mockDest.ReqRes = append(mockDest.ReqRes, reqRes2)
 	c.Client2.HTTP = mockDest
 
-	err := copyV2LD(c, c.Ngsi, c.Client, c.Client2, "Thing")
+	err := copyV2LD(c, c.Ngsi, c.Client, c.Client2, "Thing", nil, &copyStatus{})
 
 	if assert.Error(t, err) {
 		ngsiErr := err.(*ngsierr.NgsiError)
@@ -1912,7 +2481,7 @@ func TestV2LDCopyErrorHTTPStatus2(t *testing.T) {
 	mockDest.ReqRes = append(mockDest.ReqRes, reqRes2)
 	c.Client2.HTTP = mockDest
 
-	err := copyV2LD(c, c.Ngsi, c.Client, c.Client2, "Thing")
+	err := copyV2LD(c, c.Ngsi, c.Client, c.Client2, "Thing", nil, &copyStatus{})
 
 	if assert.Error(t, err) {
 		ngsiErr := err.(*ngsierr.NgsiError)
@@ -1921,6 +2490,77 @@ func TestV2LDCopyErrorHTTPStatus2(t *testing.T) {
 	}
 }
 
+func TestV2LDCopyErrorStart(t *testing.T) {
+	conf := `{
+		"version": "1",
+		"servers": {
+			"orion-src": {
+				"serverHost": "https://orion-src",
+				"ngsiType": "v2"
+			},
+			"orion-dest": {
+				"serverHost": "https://orion-dest",
+				"ngsiType": "ld"
+			}
+		}
+	}`
+	c := setupTestWithConfig([]string{"cp", "--host", "orion-src", "--host2", "orion-dest", "--type", "Thing", "--run"}, conf)
+
+	status := &copyStatus{checkpoint: &copyCheckpoint{Type: "Thing", Filter: "type=Thing"}}
+
+	err := copyV2LD(c, c.Ngsi, c.Client, c.Client2, "Thing", nil, status)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 1, ngsiErr.ErrNo)
+		assert.Equal(t, "filter in checkpoint mismatch: type=Thing", ngsiErr.Message)
+	}
+}
+
+func TestV2LDCopyErrorCommit(t *testing.T) {
+	conf := `{
+		"version": "1",
+		"servers": {
+			"orion-src": {
+				"serverHost": "https://orion-src",
+				"ngsiType": "v2"
+			},
+			"orion-dest": {
+				"serverHost": "https://orion-dest",
+				"ngsiType": "ld"
+			}
+		}
+	}`
+	c := setupTestWithConfig([]string{"cp", "--host", "orion-src", "--host2", "orion-dest", "--type", "Thing", "--run"}, conf)
+	c.Ngsi.Ioutil = &helper.MockIoutilLib{WriteFileErr: errors.New("write file error")}
+
+	reqRes1 := helper.MockHTTPReqRes{}
+	reqRes1.Res.StatusCode = http.StatusOK
+	reqRes1.ResBody = []byte(`[{"id":"device001","type":"T"}]`)
+	reqRes1.ResHeader = http.Header{"Fiware-Total-Count": []string{"1"}}
+	reqRes1.Path = "/v2/entities"
+
+	reqRes2 := helper.MockHTTPReqRes{}
+	reqRes2.Res.StatusCode = http.StatusCreated
+	reqRes2.Path = "/ngsi-ld/v1/entityOperations/create"
+
+	mockSource := helper.NewMockHTTP()
+	mockSource.ReqRes = append(mockSource.ReqRes, reqRes1)
+	c.Client.HTTP = mockSource
+
+	mockDest := helper.NewMockHTTP()
+	mockDest.ReqRes = append(mockDest.ReqRes, reqRes2)
+	c.Client2.HTTP = mockDest
+
+	err := copyV2LD(c, c.Ngsi, c.Client, c.Client2, "Thing", nil, &copyStatus{file: "cp.json"})
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 3, ngsiErr.ErrNo)
+		assert.Equal(t, "write file error", ngsiErr.Message)
+	}
+}
+
 func TestNormalized2LD(t *testing.T) {
 	_ = helper.SetupTestInitCmd(nil)
 
@@ -2440,52 +3080,170 @@ func TestLdRelationship(t *testing.T) {
 	}
 }
 
-func TestNgsildUri(t *testing.T) {
+func TestNormalizeDate(t *testing.T) {
 	cases := []struct {
-		typePart string
-		idPart   string
+		arg      string
 		expected string
 	}{
-		{typePart: "Device", idPart: "device:001", expected: "urn:ngsi-ld:device:001"},
-		{typePart: "Device", idPart: "device001", expected: "urn:ngsi-ld:Device:device001"},
+		{arg: "2014-10-01T00:00:00.00Z", expected: "2014-10-01T00:00:00.00Z"},
+		{arg: "2014-10-01T00:00:00.00", expected: "2014-10-01T00:00:00.00Z"},
 	}
+
 	for _, c := range cases {
-		actual := ngsildUri(c.typePart, c.idPart)
-		assert.Equal(t, c.expected, actual)
+		actual := normalizeDate(c.arg)
+		assert.Equal(t, "DateTime", actual.Type)
+		assert.Equal(t, c.expected, actual.Value)
 	}
 }
 
-func TestLdId(t *testing.T) {
-	cases := []struct {
-		entityId   string
-		entityType string
-		expected   string
-	}{
-		{entityId: "http://letsfiware.jp/ns/data-models#fiware", entityType: "", expected: "http://letsfiware.jp/ns/data-models#fiware"},
-		{entityId: "https://letsfiware.jp/ns/data-models#fiware", entityType: "", expected: "https://letsfiware.jp/ns/data-models#fiware"},
-		{entityId: "urn:ngsi-ld:Device:001", entityType: "", expected: "urn:ngsi-ld:Device:001"},
-		{entityId: "Device001", entityType: "Device", expected: "urn:ngsi-ld:Device:Device001"},
-		{entityId: "device001", entityType: "Device", expected: "urn:ngsi-ld:Device:device001"},
+func TestCopyV2V2Mapping(t *testing.T) {
+	conf := `{
+		"version": "1",
+		"servers": {
+			"orion-src": {
+				"serverHost": "https://orion-src",
+				"ngsiType": "v2"
+			},
+			"orion-dest": {
+				"serverHost": "https://orion-dest",
+				"ngsiType": "v2"
+			}
+		}
+	}`
+	mapping := `{"id":[{"regex":"^device","replace":"sensor"}],"type":[{"regex":"^Thing$","replace":"Sensor"}],"rename":{"temperature":"temp"},"delete":["*.metadata"],"set":{"source":{"type":"Text","value":"cp"}}}`
+	c := setupTestWithConfig([]string{"cp", "--host", "orion-src", "--host2", "orion-dest", "--type", "Thing", "--map", mapping, "--run"}, conf)
+
+	reqRes1 := helper.MockHTTPReqRes{}
+	reqRes1.Res.StatusCode = http.StatusOK
+	reqRes1.ResBody = []byte(`[{"id":"device001","type":"Thing","temperature":{"type":"Number","value":21.5,"metadata":{}}}]`)
+	reqRes1.ResHeader = http.Header{"Fiware-Total-Count": []string{"1"}}
+	reqRes1.Path = "/v2/entities"
+
+	reqRes2 := helper.MockHTTPReqRes{}
+	reqRes2.Res.StatusCode = http.StatusNoContent
+	reqRes2.Path = "/v2/op/update"
+	reqRes2.ReqData = []byte(`{"actionType":"append","entities":[{"id":"sensor001","source":{"type":"Text","value":"cp"},"temp":{"type":"Number","value":21.5},"type":"Sensor"}]}`)
+
+	mockSource := helper.NewMockHTTP()
+	mockSource.ReqRes = append(mockSource.ReqRes, reqRes1)
+	c.Client.HTTP = mockSource
+
+	mockDest := helper.NewMockHTTP()
+	mockDest.ReqRes = append(mockDest.ReqRes, reqRes2)
+	c.Client2.HTTP = mockDest
+
+	err := copy(c, c.Ngsi, c.Client)
+
+	if assert.NoError(t, err) {
+		actual := helper.GetStdoutString(c)
+		expected := "1\ncopied: 1, skipped: 0, failed: 0\n"
+		assert.Equal(t, expected, actual)
 	}
+}
 
-	for _, c := range cases {
-		actual := ldId(c.entityId, c.entityType)
-		assert.Equal(t, c.expected, actual)
+func TestCopyV2LDMapping(t *testing.T) {
+	conf := `{
+		"version": "1",
+		"servers": {
+			"orion-src": {
+				"serverHost": "https://orion-src",
+				"ngsiType": "v2"
+			},
+			"orion-dest": {
+				"serverHost": "https://orion-dest",
+				"ngsiType": "ld"
+			}
+		}
+	}`
+	mapping := `{"rename":{"temperature":"temp"},"delete":["*.metadata"]}`
+	c := setupTestWithConfig([]string{"cp", "--host", "orion-src", "--host2", "orion-dest", "--type", "Thing", "--map", mapping, "--run"}, conf)
+
+	reqRes1 := helper.MockHTTPReqRes{}
+	reqRes1.Res.StatusCode = http.StatusOK
+	reqRes1.ResBody = []byte(`[{"id":"urn:ngsi-ld:Thing:001","type":"Thing","temperature":{"type":"Number","value":21.5,"metadata":{}}}]`)
+	reqRes1.ResHeader = http.Header{"Fiware-Total-Count": []string{"1"}}
+	reqRes1.Path = "/v2/entities"
+
+	reqRes2 := helper.MockHTTPReqRes{}
+	reqRes2.Res.StatusCode = http.StatusCreated
+	reqRes2.Path = "/ngsi-ld/v1/entityOperations/create"
+	reqRes2.ReqData = []byte(`[{"id":"urn:ngsi-ld:Thing:001","temp":{"type":"Property","value":21.5},"type":"Thing"}]`)
+
+	mockSource := helper.NewMockHTTP()
+	mockSource.ReqRes = append(mockSource.ReqRes, reqRes1)
+	c.Client.HTTP = mockSource
+
+	mockDest := helper.NewMockHTTP()
+	mockDest.ReqRes = append(mockDest.ReqRes, reqRes2)
+	c.Client2.HTTP = mockDest
+
+	err := copy(c, c.Ngsi, c.Client)
+
+	if assert.NoError(t, err) {
+		actual := helper.GetStdoutString(c)
+		expected := "1\ncopied: 1, skipped: 0, failed: 0\n"
+		assert.Equal(t, expected, actual)
 	}
 }
 
-func TestNormalizeDate(t *testing.T) {
-	cases := []struct {
-		arg      string
-		expected string
-	}{
-		{arg: "2014-10-01T00:00:00.00Z", expected: "2014-10-01T00:00:00.00Z"},
-		{arg: "2014-10-01T00:00:00.00", expected: "2014-10-01T00:00:00.00Z"},
+func TestCopyErrorMapping(t *testing.T) {
+	conf := `{
+		"version": "1",
+		"servers": {
+			"orion-src": {
+				"serverHost": "https://orion-src",
+				"ngsiType": "v2"
+			},
+			"orion-dest": {
+				"serverHost": "https://orion-dest",
+				"ngsiType": "v2"
+			}
+		}
+	}`
+	c := setupTestWithConfig([]string{"cp", "--host", "orion-src", "--host2", "orion-dest", "--type", "Thing", "--ngsiV1", "--map", "{}"}, conf)
+
+	err := copy(c, c.Ngsi, c.Client)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 5, ngsiErr.ErrNo)
+		assert.Equal(t, "--map cannot be used with --ngsiV1", ngsiErr.Message)
 	}
+}
 
-	for _, c := range cases {
-		actual := normalizeDate(c.arg)
-		assert.Equal(t, "DateTime", actual.Type)
-		assert.Equal(t, c.expected, actual.Value)
+func TestCopyV2V2ErrorMapping(t *testing.T) {
+	conf := `{
+		"version": "1",
+		"servers": {
+			"orion-src": {
+				"serverHost": "https://orion-src",
+				"ngsiType": "v2"
+			},
+			"orion-dest": {
+				"serverHost": "https://orion-dest",
+				"ngsiType": "v2"
+			}
+		}
+	}`
+	c := setupTestWithConfig([]string{"cp", "--host", "orion-src", "--host2", "orion-dest", "--type", "Thing", "--run"}, conf)
+
+	reqRes1 := helper.MockHTTPReqRes{}
+	reqRes1.Res.StatusCode = http.StatusOK
+	reqRes1.ResBody = []byte(`[{"id":"device001"}]`)
+	reqRes1.ResHeader = http.Header{"Fiware-Total-Count": []string{"1"}}
+	reqRes1.Path = "/v2/entities"
+
+	mockSource := helper.NewMockHTTP()
+	mockSource.ReqRes = append(mockSource.ReqRes, reqRes1)
+	c.Client.HTTP = mockSource
+
+	helper.SetJSONDecodeErr(c.Ngsi, 0)
+
+	err := copyV2V2(c, c.Ngsi, c.Client, c.Client2, "Thing", &copyMapping{}, &copyStatus{})
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 2, ngsiErr.ErrNo)
+		assert.Equal(t, "json error", ngsiErr.Message)
 	}
 }
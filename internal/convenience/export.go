@@ -0,0 +1,337 @@
+/*
+MIT License
+
+Copyright (c) 2020-2024 Kazuhito Suda
+
+This file is part of NGSI Go
+
+https://github.com/lets-fiware/ngsi-go
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+
+*/
+
+package convenience
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"time"
+
+	"github.com/lets-fiware/ngsi-go/internal/ngsicli"
+	"github.com/lets-fiware/ngsi-go/internal/ngsierr"
+	"github.com/lets-fiware/ngsi-go/internal/ngsilib"
+)
+
+const (
+	exportLimit    = 100
+	exportManifest = "manifest.json"
+	exportVersion  = "1"
+)
+
+// archiveManifest is stored as manifest.json in an archive. Entities, subscriptions, registrations
+// and @contexts are stored as pages of JSON arrays named <kind>/NNNNNN.json.
+type archiveManifest struct {
+	Version       string `json:"version"`
+	NgsiType      string `json:"ngsiType"`
+	Service       string `json:"service,omitempty"`
+	Path          string `json:"path,omitempty"`
+	Entities      int    `json:"entities"`
+	Subscriptions int    `json:"subscriptions"`
+	Registrations int    `json:"registrations"`
+	Contexts      int    `json:"contexts"`
+}
+
+type archiveExporter struct {
+	zip      *zip.Writer
+	pages    map[string]int
+	modified time.Time
+}
+
+func export(c *ngsicli.Context, ngsi *ngsilib.NGSI, client *ngsilib.Client) error {
+	const funcName = "export"
+
+	// The archive is written to a temporary file next to --file and renamed into place when it is
+	// complete, so that neither a large archive is kept in memory nor a broken one is left behind.
+	file := c.String("file")
+	f, err := ngsi.Ioutil.CreateTemp(filepath.Dir(file), filepath.Base(file)+".*.tmp")
+	if err != nil {
+		return ngsierr.New(funcName, 1, err.Error(), err)
+	}
+	done := false
+	defer func() {
+		if !done {
+			_ = f.Close()
+			_ = ngsi.Ioutil.Remove(f.Name())
+		}
+	}()
+
+	a := &archiveExporter{zip: ngsi.ZipLib.NewWriter(f), pages: make(map[string]int), modified: ngsi.TimeLib.Now()}
+
+	manifest := archiveManifest{
+		Version:  exportVersion,
+		NgsiType: "v2",
+		Service:  c.String("service"),
+		Path:     c.String("path"),
+	}
+
+	if client.IsNgsiLd() {
+		manifest.NgsiType = "ld"
+		manifest.Contexts, err = a.contexts(ngsi, client)
+		if err != nil {
+			return ngsierr.New(funcName, 2, err.Error(), err)
+		}
+	}
+
+	manifest.Entities, err = a.entities(c, client)
+	if err != nil {
+		return ngsierr.New(funcName, 3, err.Error(), err)
+	}
+
+	manifest.Subscriptions, err = a.list(client, "subscriptions", "/subscriptions", url.Values{})
+	if err != nil {
+		return ngsierr.New(funcName, 4, err.Error(), err)
+	}
+
+	path := "/registrations"
+	if client.IsNgsiLd() {
+		path = "/csourceRegistrations"
+	}
+	manifest.Registrations, err = a.list(client, "registrations", path, url.Values{})
+	if err != nil {
+		return ngsierr.New(funcName, 5, err.Error(), err)
+	}
+
+	b, err := ngsilib.JSONMarshal(&manifest)
+	if err != nil {
+		return ngsierr.New(funcName, 6, err.Error(), err)
+	}
+	if err = a.write(exportManifest, b); err != nil {
+		return ngsierr.New(funcName, 7, err.Error(), err)
+	}
+	if err = a.zip.Close(); err != nil {
+		return ngsierr.New(funcName, 8, err.Error(), err)
+	}
+
+	if err = f.Close(); err != nil {
+		return ngsierr.New(funcName, 9, err.Error(), err)
+	}
+	if err = ngsi.Ioutil.Rename(f.Name(), file); err != nil {
+		return ngsierr.New(funcName, 10, err.Error(), err)
+	}
+	done = true
+
+	fmt.Fprintf(ngsi.StdWriter, "entities: %d, subscriptions: %d, registrations: %d, contexts: %d\n",
+		manifest.Entities, manifest.Subscriptions, manifest.Registrations, manifest.Contexts)
+
+	return nil
+}
+
+// entities stores the entities of the types given by --type. Without --type, all entities are stored.
+// As NGSI-LD brokers need an entity type to query entities, the types are listed first for NGSI-LD.
+func (a *archiveExporter) entities(c *ngsicli.Context, client *ngsilib.Client) (int, error) {
+	const funcName = "exportEntities"
+
+	var types []string
+
+	if c.IsSet("type") {
+		types = []string{c.String("type")}
+	} else if client.IsNgsiLd() {
+		client.SetPath("/types")
+		client.SetQuery(&url.Values{})
+		client.SetAcceptJSON()
+
+		res, body, err := client.HTTPGet()
+		if err != nil {
+			return 0, ngsierr.New(funcName, 1, err.Error(), err)
+		}
+		if res.StatusCode != http.StatusOK {
+			return 0, ngsierr.New(funcName, 2, fmt.Sprintf("%s %s", res.Status, string(body)), nil)
+		}
+
+		var list struct {
+			TypeList []string `json:"typeList"`
+		}
+		if err = ngsilib.JSONUnmarshal(body, &list); err != nil {
+			return 0, ngsierr.New(funcName, 3, err.Error(), err)
+		}
+		types = list.TypeList
+	} else {
+		types = []string{""}
+	}
+
+	total := 0
+	for _, t := range types {
+		v := url.Values{}
+		if t != "" {
+			v.Set("type", t)
+		}
+		n, err := a.list(client, "entities", "/entities", v)
+		if err != nil {
+			return 0, ngsierr.New(funcName, 4, err.Error(), err)
+		}
+		total += n
+	}
+
+	return total, nil
+}
+
+// list stores the items got from path page by page. For NGSI-LD, the items are requested as
+// application/ld+json so that each item has its own @context.
+func (a *archiveExporter) list(client *ngsilib.Client, kind, path string, v url.Values) (int, error) {
+	const funcName = "exportList"
+
+	if client.IsNgsiLd() {
+		v.Set("count", "true")
+		client.SetHeader("Accept", "application/ld+json")
+	} else {
+		v.Set("options", "count")
+		client.SetAcceptJSON()
+	}
+
+	total := 0
+
+	for offset := 0; ; offset += exportLimit {
+		client.SetPath(path)
+		v.Set("limit", fmt.Sprintf("%d", exportLimit))
+		v.Set("offset", fmt.Sprintf("%d", offset))
+		client.SetQuery(&v)
+
+		res, body, err := client.HTTPGet()
+		if err != nil {
+			return 0, ngsierr.New(funcName, 1, err.Error(), err)
+		}
+		if res.StatusCode != http.StatusOK {
+			return 0, ngsierr.New(funcName, 2, fmt.Sprintf("%s %s", res.Status, string(body)), nil)
+		}
+		count, err := client.ResultsCount(res)
+		if err != nil {
+			return 0, ngsierr.New(funcName, 3, "ResultsCount error", err)
+		}
+
+		var items []json.RawMessage
+		if err = ngsilib.JSONUnmarshal(body, &items); err != nil {
+			return 0, ngsierr.New(funcName, 4, err.Error(), err)
+		}
+		if len(items) == 0 {
+			break
+		}
+
+		if err = a.page(kind, body); err != nil {
+			return 0, ngsierr.New(funcName, 5, err.Error(), err)
+		}
+		total += len(items)
+
+		if offset+exportLimit >= count {
+			break
+		}
+	}
+
+	return total, nil
+}
+
+// contexts stores the @contexts created by users on Orion-LD. A broker which doesn't serve
+// /jsonldContexts is treated as having no @contexts.
+func (a *archiveExporter) contexts(ngsi *ngsilib.NGSI, client *ngsilib.Client) (int, error) {
+	const funcName = "exportContexts"
+
+	client.SetPath("/jsonldContexts")
+	v := url.Values{}
+	v.Set("details", "true")
+	client.SetQuery(&v)
+	client.SetAcceptJSON()
+
+	res, body, err := client.HTTPGet()
+	if err != nil {
+		return 0, ngsierr.New(funcName, 1, err.Error(), err)
+	}
+	if res.StatusCode == http.StatusNotFound {
+		ngsi.Logging(ngsilib.LogInfo, "jsonldContexts not found\n")
+		return 0, nil
+	}
+	if res.StatusCode != http.StatusOK {
+		return 0, ngsierr.New(funcName, 2, fmt.Sprintf("%s %s", res.Status, string(body)), nil)
+	}
+
+	var list []struct {
+		ID     string `json:"id"`
+		Origin string `json:"origin"`
+	}
+	if err = ngsilib.JSONUnmarshal(body, &list); err != nil {
+		return 0, ngsierr.New(funcName, 3, err.Error(), err)
+	}
+
+	var contexts []json.RawMessage
+
+	for _, e := range list {
+		if e.Origin != "UserCreated" {
+			continue
+		}
+		client.SetPath("/jsonldContexts/" + e.ID)
+		client.SetQuery(&url.Values{})
+
+		res, body, err := client.HTTPGet()
+		if err != nil {
+			return 0, ngsierr.New(funcName, 4, err.Error(), err)
+		}
+		if res.StatusCode != http.StatusOK {
+			return 0, ngsierr.New(funcName, 5, fmt.Sprintf("%s %s", res.Status, string(body)), nil)
+		}
+		contexts = append(contexts, json.RawMessage(body))
+	}
+
+	for i := 0; i < len(contexts); i += exportLimit {
+		j := i + exportLimit
+		if j > len(contexts) {
+			j = len(contexts)
+		}
+		b, err := ngsilib.JSONMarshal(contexts[i:j])
+		if err != nil {
+			return 0, ngsierr.New(funcName, 6, err.Error(), err)
+		}
+		if err = a.page("contexts", b); err != nil {
+			return 0, ngsierr.New(funcName, 7, err.Error(), err)
+		}
+	}
+
+	return len(contexts), nil
+}
+
+func (a *archiveExporter) page(kind string, b []byte) error {
+	a.pages[kind]++
+	return a.write(fmt.Sprintf("%s/%06d.json", kind, a.pages[kind]), b)
+}
+
+func (a *archiveExporter) write(name string, b []byte) error {
+	const funcName = "exportWrite"
+
+	w, err := a.zip.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: a.modified})
+	if err != nil {
+		return ngsierr.New(funcName, 1, err.Error(), err)
+	}
+	if _, err = w.Write(b); err != nil {
+		return ngsierr.New(funcName, 2, err.Error(), err)
+	}
+
+	return nil
+}
@@ -0,0 +1,625 @@
+/*
+MIT License
+
+Copyright (c) 2020-2024 Kazuhito Suda
+
+This file is part of NGSI Go
+
+https://github.com/lets-fiware/ngsi-go
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+
+*/
+
+package convenience
+
+import (
+	"archive/zip"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/lets-fiware/ngsi-go/internal/assert"
+	"github.com/lets-fiware/ngsi-go/internal/helper"
+	"github.com/lets-fiware/ngsi-go/internal/ngsierr"
+)
+
+func readTestArchive(t *testing.T, file string) map[string]string {
+	zr, err := zip.OpenReader(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = zr.Close() }()
+
+	files := map[string]string{}
+	for _, f := range zr.File {
+		rc, _ := f.Open()
+		b, _ := io.ReadAll(rc)
+		_ = rc.Close()
+		files[f.Name] = string(b)
+	}
+	return files
+}
+
+func TestExportV2(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "archive.zip")
+	c := setupTest([]string{"export", "--host", "orion", "--service", "openiot", "--path", "/device", "--file", file})
+
+	reqRes1 := helper.MockHTTPReqRes{}
+	reqRes1.Res.StatusCode = http.StatusOK
+	reqRes1.ResBody = []byte(`[{"id":"device001","type":"Device"},{"id":"device002","type":"Device"}]`)
+	reqRes1.ResHeader = http.Header{"Fiware-Total-Count": []string{"2"}}
+	reqRes1.Path = "/v2/entities"
+	reqRes1.RawQuery = helper.StrPtr("limit=100&offset=0&options=count")
+
+	reqRes2 := helper.MockHTTPReqRes{}
+	reqRes2.Res.StatusCode = http.StatusOK
+	reqRes2.ResBody = []byte(`[{"id":"5f0a44789dd803416ae9b0d1","notification":{"http":{"url":"http://receiver:1028/"}}}]`)
+	reqRes2.ResHeader = http.Header{"Fiware-Total-Count": []string{"1"}}
+	reqRes2.Path = "/v2/subscriptions"
+
+	reqRes3 := helper.MockHTTPReqRes{}
+	reqRes3.Res.StatusCode = http.StatusOK
+	reqRes3.ResBody = []byte(`[]`)
+	reqRes3.ResHeader = http.Header{"Fiware-Total-Count": []string{"0"}}
+	reqRes3.Path = "/v2/registrations"
+
+	helper.SetClientHTTP(c, reqRes1, reqRes2, reqRes3)
+
+	err := export(c, c.Ngsi, c.Client)
+
+	if assert.NoError(t, err) {
+		actual := helper.GetStdoutString(c)
+		expected := "entities: 2, subscriptions: 1, registrations: 0, contexts: 0\n"
+		assert.Equal(t, expected, actual)
+
+		files := readTestArchive(t, file)
+		assert.Equal(t, 3, len(files))
+		assert.Equal(t, `{"version":"1","ngsiType":"v2","service":"openiot","path":"/device","entities":2,"subscriptions":1,"registrations":0,"contexts":0}`, files["manifest.json"])
+		assert.Equal(t, string(reqRes1.ResBody), files["entities/000001.json"])
+		assert.Equal(t, string(reqRes2.ResBody), files["subscriptions/000001.json"])
+	}
+}
+
+func TestExportV2Type(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "archive.zip")
+	c := setupTest([]string{"export", "--host", "orion", "--type", "Device", "--file", file})
+
+	mock := helper.NewMockHTTP()
+	for _, offset := range []string{"0", "100"} {
+		reqRes := helper.MockHTTPReqRes{}
+		reqRes.Res.StatusCode = http.StatusOK
+		reqRes.ResBody = []byte(`[{"id":"device00` + offset + `","type":"Device"}]`)
+		reqRes.ResHeader = http.Header{"Fiware-Total-Count": []string{"101"}}
+		reqRes.Path = "/v2/entities"
+		reqRes.RawQuery = helper.StrPtr("limit=100&offset=" + offset + "&options=count&type=Device")
+		mock.ReqRes = append(mock.ReqRes, reqRes)
+	}
+	for _, path := range []string{"/v2/subscriptions", "/v2/registrations"} {
+		reqRes := helper.MockHTTPReqRes{}
+		reqRes.Res.StatusCode = http.StatusOK
+		reqRes.ResBody = []byte(`[]`)
+		reqRes.ResHeader = http.Header{"Fiware-Total-Count": []string{"0"}}
+		reqRes.Path = path
+		mock.ReqRes = append(mock.ReqRes, reqRes)
+	}
+	c.Client.HTTP = mock
+
+	err := export(c, c.Ngsi, c.Client)
+
+	if assert.NoError(t, err) {
+		actual := helper.GetStdoutString(c)
+		expected := "entities: 2, subscriptions: 0, registrations: 0, contexts: 0\n"
+		assert.Equal(t, expected, actual)
+
+		files := readTestArchive(t, file)
+		assert.Equal(t, `[{"id":"device000","type":"Device"}]`, files["entities/000001.json"])
+		assert.Equal(t, `[{"id":"device00100","type":"Device"}]`, files["entities/000002.json"])
+	}
+}
+
+func TestExportLD(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "archive.zip")
+	c := setupTest([]string{"export", "--host", "orion-ld", "--file", file})
+
+	reqRes1 := helper.MockHTTPReqRes{}
+	reqRes1.Res.StatusCode = http.StatusOK
+	reqRes1.ResBody = []byte(`[{"id":"c1","origin":"UserCreated"},{"id":"c2","origin":"Cached"}]`)
+	reqRes1.Path = "/ngsi-ld/v1/jsonldContexts"
+	reqRes1.RawQuery = helper.StrPtr("details=true")
+
+	reqRes2 := helper.MockHTTPReqRes{}
+	reqRes2.Res.StatusCode = http.StatusOK
+	reqRes2.ResBody = []byte(`{"@context":{"name":"https://example.org/name"}}`)
+	reqRes2.Path = "/ngsi-ld/v1/jsonldContexts/c1"
+
+	reqRes3 := helper.MockHTTPReqRes{}
+	reqRes3.Res.StatusCode = http.StatusOK
+	reqRes3.ResBody = []byte(`{"id":"urn:ngsi-ld:EntityTypeList:1","type":"EntityTypeList","typeList":["Building","Device"]}`)
+	reqRes3.Path = "/ngsi-ld/v1/types"
+
+	reqRes4 := helper.MockHTTPReqRes{}
+	reqRes4.Res.StatusCode = http.StatusOK
+	reqRes4.ResBody = []byte(`[{"id":"urn:ngsi-ld:Building:001","type":"Building"}]`)
+	reqRes4.ResHeader = http.Header{"Ngsild-Results-Count": []string{"1"}}
+	reqRes4.Path = "/ngsi-ld/v1/entities"
+	reqRes4.RawQuery = helper.StrPtr("count=true&limit=100&offset=0&type=Building")
+
+	reqRes5 := helper.MockHTTPReqRes{}
+	reqRes5.Res.StatusCode = http.StatusOK
+	reqRes5.ResBody = []byte(`[{"id":"urn:ngsi-ld:Device:001","type":"Device"}]`)
+	reqRes5.ResHeader = http.Header{"Ngsild-Results-Count": []string{"1"}}
+	reqRes5.Path = "/ngsi-ld/v1/entities"
+	reqRes5.RawQuery = helper.StrPtr("count=true&limit=100&offset=0&type=Device")
+
+	reqRes6 := helper.MockHTTPReqRes{}
+	reqRes6.Res.StatusCode = http.StatusOK
+	reqRes6.ResBody = []byte(`[]`)
+	reqRes6.ResHeader = http.Header{"Ngsild-Results-Count": []string{"0"}}
+	reqRes6.Path = "/ngsi-ld/v1/subscriptions"
+
+	reqRes7 := helper.MockHTTPReqRes{}
+	reqRes7.Res.StatusCode = http.StatusOK
+	reqRes7.ResBody = []byte(`[{"id":"urn:ngsi-ld:ContextSourceRegistration:001","type":"ContextSourceRegistration"}]`)
+	reqRes7.ResHeader = http.Header{"Ngsild-Results-Count": []string{"1"}}
+	reqRes7.Path = "/ngsi-ld/v1/csourceRegistrations"
+
+	helper.SetClientHTTP(c, reqRes1, reqRes2, reqRes3, reqRes4, reqRes5, reqRes6, reqRes7)
+
+	err := export(c, c.Ngsi, c.Client)
+
+	if assert.NoError(t, err) {
+		actual := helper.GetStdoutString(c)
+		expected := "entities: 2, subscriptions: 0, registrations: 1, contexts: 1\n"
+		assert.Equal(t, expected, actual)
+
+		files := readTestArchive(t, file)
+		assert.Equal(t, 5, len(files))
+		assert.Equal(t, `{"version":"1","ngsiType":"ld","entities":2,"subscriptions":0,"registrations":1,"contexts":1}`, files["manifest.json"])
+		assert.Equal(t, `[{"@context":{"name":"https://example.org/name"}}]`, files["contexts/000001.json"])
+		assert.Equal(t, string(reqRes4.ResBody), files["entities/000001.json"])
+		assert.Equal(t, string(reqRes5.ResBody), files["entities/000002.json"])
+		assert.Equal(t, string(reqRes7.ResBody), files["registrations/000001.json"])
+		assert.Equal(t, "application/ld+json", c.Client.Headers["Accept"])
+	}
+}
+
+func TestExportLDContextsNotFound(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "archive.zip")
+	c := setupTest([]string{"export", "--host", "orion-ld", "--type", "Device", "--file", file})
+
+	reqRes1 := helper.MockHTTPReqRes{}
+	reqRes1.Res.StatusCode = http.StatusNotFound
+	reqRes1.Path = "/ngsi-ld/v1/jsonldContexts"
+
+	mock := helper.NewMockHTTP()
+	mock.ReqRes = append(mock.ReqRes, reqRes1)
+	for _, path := range []string{"/ngsi-ld/v1/entities", "/ngsi-ld/v1/subscriptions", "/ngsi-ld/v1/csourceRegistrations"} {
+		reqRes := helper.MockHTTPReqRes{}
+		reqRes.Res.StatusCode = http.StatusOK
+		reqRes.ResBody = []byte(`[]`)
+		reqRes.ResHeader = http.Header{"Ngsild-Results-Count": []string{"0"}}
+		reqRes.Path = path
+		mock.ReqRes = append(mock.ReqRes, reqRes)
+	}
+	c.Client.HTTP = mock
+
+	err := export(c, c.Ngsi, c.Client)
+
+	if assert.NoError(t, err) {
+		actual := helper.GetStdoutString(c)
+		expected := "entities: 0, subscriptions: 0, registrations: 0, contexts: 0\n"
+		assert.Equal(t, expected, actual)
+	}
+}
+
+func TestExportErrorContexts(t *testing.T) {
+	c := setupTest([]string{"export", "--host", "orion-ld", "--file", "archive.zip"})
+
+	reqRes := helper.MockHTTPReqRes{}
+	reqRes.Err = errors.New("http error")
+	helper.SetClientHTTP(c, reqRes)
+
+	err := export(c, c.Ngsi, c.Client)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 2, ngsiErr.ErrNo)
+		assert.Equal(t, "http error", ngsiErr.Message)
+	}
+}
+
+func TestExportErrorEntities(t *testing.T) {
+	c := setupTest([]string{"export", "--host", "orion", "--file", "archive.zip"})
+
+	reqRes := helper.MockHTTPReqRes{}
+	reqRes.Err = errors.New("http error")
+	helper.SetClientHTTP(c, reqRes)
+
+	err := export(c, c.Ngsi, c.Client)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 3, ngsiErr.ErrNo)
+		assert.Equal(t, "http error", ngsiErr.Message)
+	}
+}
+
+func TestExportErrorSubscriptions(t *testing.T) {
+	c := setupTest([]string{"export", "--host", "orion", "--file", "archive.zip"})
+
+	reqRes1 := helper.MockHTTPReqRes{}
+	reqRes1.Res.StatusCode = http.StatusOK
+	reqRes1.ResBody = []byte(`[]`)
+	reqRes1.ResHeader = http.Header{"Fiware-Total-Count": []string{"0"}}
+
+	reqRes2 := helper.MockHTTPReqRes{}
+	reqRes2.Err = errors.New("http error")
+
+	helper.SetClientHTTP(c, reqRes1, reqRes2)
+
+	err := export(c, c.Ngsi, c.Client)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 4, ngsiErr.ErrNo)
+		assert.Equal(t, "http error", ngsiErr.Message)
+	}
+}
+
+func TestExportErrorRegistrations(t *testing.T) {
+	c := setupTest([]string{"export", "--host", "orion", "--file", "archive.zip"})
+
+	reqRes1 := helper.MockHTTPReqRes{}
+	reqRes1.Res.StatusCode = http.StatusOK
+	reqRes1.ResBody = []byte(`[]`)
+	reqRes1.ResHeader = http.Header{"Fiware-Total-Count": []string{"0"}}
+
+	reqRes2 := helper.MockHTTPReqRes{}
+	reqRes2.Err = errors.New("http error")
+
+	helper.SetClientHTTP(c, reqRes1, reqRes1, reqRes2)
+
+	err := export(c, c.Ngsi, c.Client)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 5, ngsiErr.ErrNo)
+		assert.Equal(t, "http error", ngsiErr.Message)
+	}
+}
+
+func TestExportErrorManifest(t *testing.T) {
+	c := setupTest([]string{"export", "--host", "orion", "--file", "archive.zip"})
+
+	reqRes := helper.MockHTTPReqRes{}
+	reqRes.Res.StatusCode = http.StatusOK
+	reqRes.ResBody = []byte(`[]`)
+	reqRes.ResHeader = http.Header{"Fiware-Total-Count": []string{"0"}}
+
+	helper.SetClientHTTP(c, reqRes, reqRes, reqRes)
+	helper.SetJSONEncodeErr(c.Ngsi, 0)
+
+	err := export(c, c.Ngsi, c.Client)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 6, ngsiErr.ErrNo)
+		assert.Equal(t, "json error", ngsiErr.Message)
+	}
+}
+
+func TestExportErrorClose(t *testing.T) {
+	c := setupTest([]string{"export", "--host", "orion", "--file", "archive.zip"})
+
+	reqRes := helper.MockHTTPReqRes{}
+	reqRes.Res.StatusCode = http.StatusOK
+	reqRes.ResBody = []byte(`[]`)
+	reqRes.ResHeader = http.Header{"Fiware-Total-Count": []string{"0"}}
+
+	helper.SetClientHTTP(c, reqRes, reqRes, reqRes)
+	c.Ngsi.ZipLib = &helper.MockZipLib{WriteErr: errors.New("write error")}
+
+	err := export(c, c.Ngsi, c.Client)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 8, ngsiErr.ErrNo)
+		assert.Equal(t, "write error", ngsiErr.Message)
+	}
+}
+
+func TestExportErrorCreateTemp(t *testing.T) {
+	c := setupTest([]string{"export", "--host", "orion", "--file", "archive.zip"})
+
+	c.Ngsi.Ioutil = &helper.MockIoutilLib{CreateTempErr: errors.New("create error")}
+
+	err := export(c, c.Ngsi, c.Client)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 1, ngsiErr.ErrNo)
+		assert.Equal(t, "create error", ngsiErr.Message)
+	}
+}
+
+func TestExportErrorRename(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "archive.zip")
+	c := setupTest([]string{"export", "--host", "orion", "--file", file})
+
+	reqRes := helper.MockHTTPReqRes{}
+	reqRes.Res.StatusCode = http.StatusOK
+	reqRes.ResBody = []byte(`[]`)
+	reqRes.ResHeader = http.Header{"Fiware-Total-Count": []string{"0"}}
+
+	helper.SetClientHTTP(c, reqRes, reqRes, reqRes)
+	c.Ngsi.Ioutil = &helper.MockIoutilLib{RenameErr: errors.New("rename error")}
+
+	err := export(c, c.Ngsi, c.Client)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 10, ngsiErr.ErrNo)
+		assert.Equal(t, "rename error", ngsiErr.Message)
+		files, _ := os.ReadDir(dir)
+		assert.Equal(t, 0, len(files))
+	}
+}
+
+func TestExportEntitiesErrorTypesHTTP(t *testing.T) {
+	c := setupTest([]string{"export", "--host", "orion-ld", "--file", "archive.zip"})
+
+	reqRes := helper.MockHTTPReqRes{}
+	reqRes.Err = errors.New("http error")
+	helper.SetClientHTTP(c, reqRes)
+
+	a := &archiveExporter{pages: map[string]int{}}
+	_, err := a.entities(c, c.Client)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 1, ngsiErr.ErrNo)
+		assert.Equal(t, "http error", ngsiErr.Message)
+	}
+}
+
+func TestExportEntitiesErrorTypesStatus(t *testing.T) {
+	c := setupTest([]string{"export", "--host", "orion-ld", "--file", "archive.zip"})
+
+	reqRes := helper.MockHTTPReqRes{}
+	reqRes.Res.StatusCode = http.StatusBadRequest
+	reqRes.Res.Status = "400 Bad Request"
+	reqRes.ResBody = []byte("error")
+	helper.SetClientHTTP(c, reqRes)
+
+	a := &archiveExporter{pages: map[string]int{}}
+	_, err := a.entities(c, c.Client)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 2, ngsiErr.ErrNo)
+		assert.Equal(t, "400 Bad Request error", ngsiErr.Message)
+	}
+}
+
+func TestExportEntitiesErrorTypesJSON(t *testing.T) {
+	c := setupTest([]string{"export", "--host", "orion-ld", "--file", "archive.zip"})
+
+	reqRes := helper.MockHTTPReqRes{}
+	reqRes.Res.StatusCode = http.StatusOK
+	reqRes.ResBody = []byte(`{"typeList":[]}`)
+	helper.SetClientHTTP(c, reqRes)
+	helper.SetJSONDecodeErr(c.Ngsi, 0)
+
+	a := &archiveExporter{pages: map[string]int{}}
+	_, err := a.entities(c, c.Client)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 3, ngsiErr.ErrNo)
+		assert.Equal(t, "json error", ngsiErr.Message)
+	}
+}
+
+func TestExportEntitiesErrorList(t *testing.T) {
+	c := setupTest([]string{"export", "--host", "orion", "--file", "archive.zip"})
+
+	reqRes := helper.MockHTTPReqRes{}
+	reqRes.Err = errors.New("http error")
+	helper.SetClientHTTP(c, reqRes)
+
+	a := &archiveExporter{pages: map[string]int{}}
+	_, err := a.entities(c, c.Client)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 4, ngsiErr.ErrNo)
+		assert.Equal(t, "http error", ngsiErr.Message)
+	}
+}
+
+func TestExportListErrorStatus(t *testing.T) {
+	c := setupTest([]string{"export", "--host", "orion", "--file", "archive.zip"})
+
+	reqRes := helper.MockHTTPReqRes{}
+	reqRes.Res.StatusCode = http.StatusBadRequest
+	reqRes.Res.Status = "400 Bad Request"
+	reqRes.ResBody = []byte("error")
+	helper.SetClientHTTP(c, reqRes)
+
+	a := &archiveExporter{pages: map[string]int{}}
+	_, err := a.list(c.Client, "entities", "/entities", url.Values{})
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 2, ngsiErr.ErrNo)
+		assert.Equal(t, "400 Bad Request error", ngsiErr.Message)
+	}
+}
+
+func TestExportListErrorResultsCount(t *testing.T) {
+	c := setupTest([]string{"export", "--host", "orion", "--file", "archive.zip"})
+
+	reqRes := helper.MockHTTPReqRes{}
+	reqRes.Res.StatusCode = http.StatusOK
+	reqRes.ResBody = []byte(`[]`)
+	helper.SetClientHTTP(c, reqRes)
+
+	a := &archiveExporter{pages: map[string]int{}}
+	_, err := a.list(c.Client, "entities", "/entities", url.Values{})
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 3, ngsiErr.ErrNo)
+		assert.Equal(t, "ResultsCount error", ngsiErr.Message)
+	}
+}
+
+func TestExportListErrorJSON(t *testing.T) {
+	c := setupTest([]string{"export", "--host", "orion", "--file", "archive.zip"})
+
+	reqRes := helper.MockHTTPReqRes{}
+	reqRes.Res.StatusCode = http.StatusOK
+	reqRes.ResBody = []byte(`[]`)
+	reqRes.ResHeader = http.Header{"Fiware-Total-Count": []string{"0"}}
+	helper.SetClientHTTP(c, reqRes)
+	helper.SetJSONDecodeErr(c.Ngsi, 0)
+
+	a := &archiveExporter{pages: map[string]int{}}
+	_, err := a.list(c.Client, "entities", "/entities", url.Values{})
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 4, ngsiErr.ErrNo)
+		assert.Equal(t, "json error", ngsiErr.Message)
+	}
+}
+
+func TestExportContextsErrorStatus(t *testing.T) {
+	c := setupTest([]string{"export", "--host", "orion-ld", "--file", "archive.zip"})
+
+	reqRes := helper.MockHTTPReqRes{}
+	reqRes.Res.StatusCode = http.StatusBadRequest
+	reqRes.Res.Status = "400 Bad Request"
+	reqRes.ResBody = []byte("error")
+	helper.SetClientHTTP(c, reqRes)
+
+	a := &archiveExporter{pages: map[string]int{}}
+	_, err := a.contexts(c.Ngsi, c.Client)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 2, ngsiErr.ErrNo)
+		assert.Equal(t, "400 Bad Request error", ngsiErr.Message)
+	}
+}
+
+func TestExportContextsErrorJSON(t *testing.T) {
+	c := setupTest([]string{"export", "--host", "orion-ld", "--file", "archive.zip"})
+
+	reqRes := helper.MockHTTPReqRes{}
+	reqRes.Res.StatusCode = http.StatusOK
+	reqRes.ResBody = []byte(`[]`)
+	helper.SetClientHTTP(c, reqRes)
+	helper.SetJSONDecodeErr(c.Ngsi, 0)
+
+	a := &archiveExporter{pages: map[string]int{}}
+	_, err := a.contexts(c.Ngsi, c.Client)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 3, ngsiErr.ErrNo)
+		assert.Equal(t, "json error", ngsiErr.Message)
+	}
+}
+
+func TestExportContextsErrorGetHTTP(t *testing.T) {
+	c := setupTest([]string{"export", "--host", "orion-ld", "--file", "archive.zip"})
+
+	reqRes1 := helper.MockHTTPReqRes{}
+	reqRes1.Res.StatusCode = http.StatusOK
+	reqRes1.ResBody = []byte(`[{"id":"c1","origin":"UserCreated"}]`)
+
+	reqRes2 := helper.MockHTTPReqRes{}
+	reqRes2.Err = errors.New("http error")
+
+	helper.SetClientHTTP(c, reqRes1, reqRes2)
+
+	a := &archiveExporter{pages: map[string]int{}}
+	_, err := a.contexts(c.Ngsi, c.Client)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 4, ngsiErr.ErrNo)
+		assert.Equal(t, "http error", ngsiErr.Message)
+	}
+}
+
+func TestExportContextsErrorGetStatus(t *testing.T) {
+	c := setupTest([]string{"export", "--host", "orion-ld", "--file", "archive.zip"})
+
+	reqRes1 := helper.MockHTTPReqRes{}
+	reqRes1.Res.StatusCode = http.StatusOK
+	reqRes1.ResBody = []byte(`[{"id":"c1","origin":"UserCreated"}]`)
+
+	reqRes2 := helper.MockHTTPReqRes{}
+	reqRes2.Res.StatusCode = http.StatusNotFound
+	reqRes2.Res.Status = "404 Not Found"
+	reqRes2.ResBody = []byte("error")
+
+	helper.SetClientHTTP(c, reqRes1, reqRes2)
+
+	a := &archiveExporter{pages: map[string]int{}}
+	_, err := a.contexts(c.Ngsi, c.Client)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 5, ngsiErr.ErrNo)
+		assert.Equal(t, "404 Not Found error", ngsiErr.Message)
+	}
+}
+
+func TestExportContextsErrorMarshal(t *testing.T) {
+	c := setupTest([]string{"export", "--host", "orion-ld", "--file", "archive.zip"})
+
+	reqRes1 := helper.MockHTTPReqRes{}
+	reqRes1.Res.StatusCode = http.StatusOK
+	reqRes1.ResBody = []byte(`[{"id":"c1","origin":"UserCreated"}]`)
+
+	reqRes2 := helper.MockHTTPReqRes{}
+	reqRes2.Res.StatusCode = http.StatusOK
+	reqRes2.ResBody = []byte(`{"@context":{}}`)
+
+	helper.SetClientHTTP(c, reqRes1, reqRes2)
+	helper.SetJSONEncodeErr(c.Ngsi, 0)
+
+	a := &archiveExporter{pages: map[string]int{}}
+	_, err := a.contexts(c.Ngsi, c.Client)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 6, ngsiErr.ErrNo)
+		assert.Equal(t, "json error", ngsiErr.Message)
+	}
+}
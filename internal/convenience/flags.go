@@ -105,6 +105,113 @@ var (
 		Name:  "skipForwarding",
 		Usage: "skip forwarding to CPrs (v2)",
 	}
+	multiAttrFlag = &ngsicli.StringFlag{
+		Name:  "multiAttr",
+		Usage: "handling of multi-attributes from LD to v2 (default, suffix, error)",
+	}
+	mapFlag = &ngsicli.StringFlag{
+		Name:  "map",
+		Usage: "mapping rules for entities (@file or JSON)",
+	}
+	checkpointFlag = &ngsicli.StringFlag{
+		Name:  "checkpoint",
+		Usage: "checkpoint `FILE`",
+	}
+	resumeFlag = &ngsicli.BoolFlag{
+		Name:  "resume",
+		Usage: "resume copy from checkpoint",
+	}
+)
+
+// flags for export and import commands
+var (
+	exportTypeFlag = &ngsicli.StringFlag{
+		Name:    "type",
+		Aliases: []string{"t"},
+		Usage:   "Entity Type",
+	}
+	archiveFileRFlag = &ngsicli.StringFlag{
+		Name:     "file",
+		Aliases:  []string{"f"},
+		Usage:    "archive `FILE`",
+		Required: true,
+	}
+	importReplaceURLFlag = &ngsicli.StringFlag{
+		Name:  "replaceURL",
+		Usage: "replace prefix of notification URLs (FROM=TO,...)",
+	}
+	applyFileRFlag = &ngsicli.StringFlag{
+		Name:     "file",
+		Aliases:  []string{"f"},
+		Usage:    "manifest `FILE` (.json, .yaml, .yml) or directory",
+		Required: true,
+	}
+	applyPruneFlag = &ngsicli.BoolFlag{
+		Name:  "prune",
+		Usage: "delete subscriptions and registrations not in manifests",
+	}
+	applyDiffFlag = &ngsicli.BoolFlag{
+		Name:  "diff",
+		Usage: "print the plan without applying it",
+	}
+)
+
+// flags for watch command
+var (
+	watchIDFlag = &ngsicli.StringFlag{
+		Name:    "id",
+		Aliases: []string{"i"},
+		Usage:   "entity id",
+	}
+	watchIDPatternFlag = &ngsicli.StringFlag{
+		Name:  "idPattern",
+		Usage: "idPattern",
+	}
+	watchQueryFlag = &ngsicli.StringFlag{
+		Name:    "query",
+		Aliases: []string{"q"},
+		Usage:   "filtering by attribute value",
+	}
+	watchAttrsFlag = &ngsicli.StringFlag{
+		Name:  "attrs",
+		Usage: "attributes",
+	}
+	watchPortFlag = &ngsicli.StringFlag{
+		Name:  "port",
+		Value: "1028",
+		Usage: "port for embedded receiver",
+	}
+	watchURLFlag = &ngsicli.StringFlag{
+		Name:  "url",
+		Value: "/",
+		Usage: "url for embedded receiver",
+	}
+	watchCallbackHostFlag = &ngsicli.StringFlag{
+		Name:  "callbackHost",
+		Usage: "`HOST` in notification url (default: local address of the route to the broker)",
+	}
+	watchPollFlag = &ngsicli.BoolFlag{
+		Name:  "poll",
+		Usage: "poll entities instead of subscribing",
+	}
+	watchIntervalFlag = &ngsicli.StringFlag{
+		Name:  "interval",
+		Value: "5s",
+		Usage: "polling `DURATION`",
+	}
+	watchProbeFlag = &ngsicli.StringFlag{
+		Name:  "probe",
+		Value: "0",
+		Usage: "check the notification status every `DURATION` and poll on failure (0: off)",
+	}
+)
+
+// flag for shell command
+var (
+	shellHistoryFileFlag = &ngsicli.StringFlag{
+		Name:  "historyFile",
+		Usage: "history `FILE` (default: ngsi-go-history in config directory)",
+	}
 )
 
 // flag for receiver
@@ -147,6 +254,38 @@ var (
 		Name:  "header",
 		Usage: "print receive header",
 	}
+	receiverStoreFlag = &ngsicli.StringFlag{
+		Name:  "store",
+		Usage: "append notifications to JSON lines `FILE`",
+	}
+	receiverStatusFlag = &ngsicli.StringFlag{
+		Name:  "status",
+		Usage: "comma-separated HTTP status `CODES` to answer in turn, the last one repeated",
+	}
+	receiverDelayFlag = &ngsicli.StringFlag{
+		Name:  "delay",
+		Usage: "`DURATION` to wait before answering",
+	}
+	receiverForwardFlag = &ngsicli.StringFlag{
+		Name:  "forward",
+		Usage: "forward notifications to `URL`",
+	}
+	receiverReplayFlag = &ngsicli.StringFlag{
+		Name:  "replay",
+		Usage: "send notifications stored in `FILE` to --forward URL",
+	}
+	receiverExpectFlag = &ngsicli.StringFlag{
+		Name:  "expect",
+		Usage: "jq style `EXPRESSION` which every notification must satisfy",
+	}
+	receiverCountFlag = &ngsicli.Int64Flag{
+		Name:  "count",
+		Usage: "exit after `N` notifications",
+	}
+	receiverDeadlineFlag = &ngsicli.StringFlag{
+		Name:  "deadline",
+		Usage: "exit after `DURATION`, failing if --count notifications have not been received",
+	}
 )
 
 // flag for registration proxy
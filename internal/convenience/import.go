@@ -0,0 +1,362 @@
+/*
+MIT License
+
+Copyright (c) 2020-2024 Kazuhito Suda
+
+This file is part of NGSI Go
+
+https://github.com/lets-fiware/ngsi-go
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+
+*/
+
+package convenience
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/lets-fiware/ngsi-go/internal/ngsicli"
+	"github.com/lets-fiware/ngsi-go/internal/ngsierr"
+	"github.com/lets-fiware/ngsi-go/internal/ngsilib"
+)
+
+// importMaxSize is the largest member of an archive read into memory. A page holds at most 100 items,
+// so a larger member is not an archive written by export.
+var importMaxSize int64 = 100 << 20
+
+type archiveImporter struct {
+	ngsi     *ngsilib.NGSI
+	client   *ngsilib.Client
+	files    []*zip.File
+	replaces [][2]string
+}
+
+func importArchive(c *ngsicli.Context, ngsi *ngsilib.NGSI, client *ngsilib.Client) error {
+	const funcName = "importArchive"
+
+	replaces, err := importReplaceURL(c.String("replaceURL"))
+	if err != nil {
+		return ngsierr.New(funcName, 1, err.Error(), err)
+	}
+
+	f, err := ngsi.Ioutil.Open(c.String("file"))
+	if err != nil {
+		return ngsierr.New(funcName, 2, err.Error(), err)
+	}
+	defer func() { _ = f.Close() }()
+
+	fi, err := f.Stat()
+	if err != nil {
+		return ngsierr.New(funcName, 3, err.Error(), err)
+	}
+
+	zr, err := ngsi.ZipLib.NewReader(f, fi.Size())
+	if err != nil {
+		return ngsierr.New(funcName, 4, err.Error(), err)
+	}
+
+	a := &archiveImporter{ngsi: ngsi, client: client, files: zr.File, replaces: replaces}
+	sort.Slice(a.files, func(i, j int) bool { return a.files[i].Name < a.files[j].Name })
+
+	var manifest archiveManifest
+	if err = a.read(exportManifest, &manifest); err != nil {
+		return ngsierr.New(funcName, 5, err.Error(), err)
+	}
+	if manifest.Version != exportVersion {
+		return ngsierr.New(funcName, 6, "unsupported archive version: "+manifest.Version, nil)
+	}
+	ngsiType := "v2"
+	if client.IsNgsiLd() {
+		ngsiType = "ld"
+	}
+	if manifest.NgsiType != ngsiType {
+		return ngsierr.New(funcName, 7, fmt.Sprintf("archive is for %s, but %s is %s", manifest.NgsiType, c.String("host"), ngsiType), nil)
+	}
+
+	contexts, err := a.items("contexts", a.context)
+	if err != nil {
+		return ngsierr.New(funcName, 8, err.Error(), err)
+	}
+	entities, err := a.entities()
+	if err != nil {
+		return ngsierr.New(funcName, 9, err.Error(), err)
+	}
+	registrations, err := a.items("registrations", a.registration)
+	if err != nil {
+		return ngsierr.New(funcName, 10, err.Error(), err)
+	}
+	// Subscriptions are created last so that restoring entities doesn't trigger notifications.
+	subscriptions, err := a.items("subscriptions", a.subscription)
+	if err != nil {
+		return ngsierr.New(funcName, 11, err.Error(), err)
+	}
+
+	fmt.Fprintf(ngsi.StdWriter, "entities: %d, subscriptions: %d, registrations: %d, contexts: %d\n", entities, subscriptions, registrations, contexts)
+
+	return nil
+}
+
+// importReplaceURL parses --replaceURL. The value is a comma-separated list of FROM=TO.
+func importReplaceURL(s string) ([][2]string, error) {
+	const funcName = "importReplaceURL"
+
+	var replaces [][2]string
+
+	if s == "" {
+		return nil, nil
+	}
+	for _, e := range strings.Split(s, ",") {
+		from, to, ok := strings.Cut(e, "=")
+		if !ok || from == "" {
+			return nil, ngsierr.New(funcName, 1, "replaceURL error: "+e, nil)
+		}
+		replaces = append(replaces, [2]string{from, to})
+	}
+
+	return replaces, nil
+}
+
+func (a *archiveImporter) read(name string, v interface{}) error {
+	const funcName = "importRead"
+
+	for _, f := range a.files {
+		if f.Name != name {
+			continue
+		}
+		if f.UncompressedSize64 > uint64(importMaxSize) {
+			return ngsierr.New(funcName, 1, name+" too large", nil)
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return ngsierr.New(funcName, 2, err.Error(), err)
+		}
+		defer func() { _ = rc.Close() }()
+
+		// The buffer grows with the data actually read instead of the size in the header.
+		buf := &bytes.Buffer{}
+		if _, err = a.ngsi.Ioutil.Copy(buf, io.LimitReader(rc, importMaxSize)); err != nil {
+			return ngsierr.New(funcName, 3, err.Error(), err)
+		}
+		if err = ngsilib.JSONUnmarshal(buf.Bytes(), v); err != nil {
+			return ngsierr.New(funcName, 4, name+": "+err.Error(), err)
+		}
+		return nil
+	}
+
+	return ngsierr.New(funcName, 5, name+" not found", nil)
+}
+
+func (a *archiveImporter) pages(kind string) []string {
+	var names []string
+
+	for _, f := range a.files {
+		if strings.HasPrefix(f.Name, kind+"/") {
+			names = append(names, f.Name)
+		}
+	}
+
+	return names
+}
+
+// entities upserts the entities page by page through batch operations.
+func (a *archiveImporter) entities() (int, error) {
+	const funcName = "importEntities"
+
+	total := 0
+
+	for _, name := range a.pages("entities") {
+		var entities []json.RawMessage
+		if err := a.read(name, &entities); err != nil {
+			return 0, ngsierr.New(funcName, 1, err.Error(), err)
+		}
+
+		var res *http.Response
+		var body []byte
+		var err error
+
+		if a.client.IsNgsiLd() {
+			a.client.SetPath("/entityOperations/upsert")
+			a.client.SetQuery(&url.Values{})
+			a.client.SetContentLdJSON()
+			a.client.RemoveHeader("Link")
+			var b []byte
+			b, err = ngsilib.JSONMarshal(entities)
+			if err != nil {
+				return 0, ngsierr.New(funcName, 2, err.Error(), err)
+			}
+			res, body, err = a.client.HTTPPost(b)
+		} else {
+			a.client.SetQuery(&url.Values{})
+			res, body, err = a.client.OpUpdate(entities, "append", false, false)
+		}
+		if err != nil && !ngsilib.IsDryRun(err) {
+			return 0, ngsierr.New(funcName, 3, err.Error(), err)
+		}
+		if err == nil && res.StatusCode != http.StatusCreated && res.StatusCode != http.StatusNoContent {
+			return 0, ngsierr.New(funcName, 4, fmt.Sprintf("%s: %s %s", name, res.Status, string(body)), nil)
+		}
+
+		total += len(entities)
+	}
+
+	return total, nil
+}
+
+func (a *archiveImporter) items(kind string, f func(item json.RawMessage) error) (int, error) {
+	const funcName = "importItems"
+
+	total := 0
+
+	for _, name := range a.pages(kind) {
+		var items []json.RawMessage
+		if err := a.read(name, &items); err != nil {
+			return 0, ngsierr.New(funcName, 1, err.Error(), err)
+		}
+		for _, item := range items {
+			if err := f(item); err != nil {
+				return 0, ngsierr.New(funcName, 2, err.Error(), err)
+			}
+			total++
+		}
+	}
+
+	return total, nil
+}
+
+func (a *archiveImporter) context(item json.RawMessage) error {
+	return a.post("/jsonldContexts", item)
+}
+
+// registration creates a registration. Registrations of NGSIv2 get a new id.
+func (a *archiveImporter) registration(item json.RawMessage) error {
+	const funcName = "importRegistration"
+
+	var reg map[string]interface{}
+	if err := ngsilib.JSONUnmarshal(item, &reg); err != nil {
+		return ngsierr.New(funcName, 1, err.Error(), err)
+	}
+
+	path := "/csourceRegistrations"
+	if a.client.IsNgsiLd() {
+		deleteKeys(reg, "status", "createdAt", "modifiedAt", "timesSent", "timesFailed", "lastSuccess", "lastFailure")
+	} else {
+		path = "/registrations"
+		deleteKeys(reg, "id", "forwardingInformation")
+	}
+
+	b, err := ngsilib.JSONMarshal(reg)
+	if err != nil {
+		return ngsierr.New(funcName, 2, err.Error(), err)
+	}
+
+	return a.post(path, b)
+}
+
+// subscription creates a subscription after replacing the prefixes of its notification URLs.
+// Subscriptions of NGSIv2 get a new id.
+func (a *archiveImporter) subscription(item json.RawMessage) error {
+	const funcName = "importSubscription"
+
+	var sub map[string]interface{}
+	if err := ngsilib.JSONUnmarshal(item, &sub); err != nil {
+		return ngsierr.New(funcName, 1, err.Error(), err)
+	}
+
+	notification, _ := sub["notification"].(map[string]interface{})
+
+	if a.client.IsNgsiLd() {
+		deleteKeys(sub, "status", "createdAt", "modifiedAt")
+		deleteKeys(notification, "status", "timesSent", "lastNotification", "lastSuccess", "lastFailure")
+		a.replaceURL(notification, "endpoint", "uri")
+	} else {
+		deleteKeys(sub, "id")
+		if status, _ := sub["status"].(string); status != "active" && status != "inactive" && status != "oneshot" {
+			delete(sub, "status")
+		}
+		deleteKeys(notification, "timesSent", "lastNotification", "lastSuccess", "lastSuccessCode", "lastFailure", "lastFailureReason", "failsCounter")
+		for _, k := range []string{"http", "httpCustom", "mqtt", "mqttCustom"} {
+			a.replaceURL(notification, k, "url")
+		}
+	}
+
+	b, err := ngsilib.JSONMarshal(sub)
+	if err != nil {
+		return ngsierr.New(funcName, 2, err.Error(), err)
+	}
+
+	return a.post("/subscriptions", b)
+}
+
+func (a *archiveImporter) replaceURL(notification map[string]interface{}, key, name string) {
+	m, ok := notification[key].(map[string]interface{})
+	if !ok {
+		return
+	}
+	u, ok := m[name].(string)
+	if !ok {
+		return
+	}
+	for _, r := range a.replaces {
+		if strings.HasPrefix(u, r[0]) {
+			m[name] = r[1] + strings.TrimPrefix(u, r[0])
+			return
+		}
+	}
+}
+
+func (a *archiveImporter) post(path string, b []byte) error {
+	const funcName = "importPost"
+
+	a.client.SetPath(path)
+	a.client.SetQuery(&url.Values{})
+	if a.client.IsNgsiLd() {
+		a.client.SetContentLdJSON()
+		a.client.RemoveHeader("Link")
+	} else {
+		a.client.SetContentJSON()
+	}
+
+	res, body, err := a.client.HTTPPost(b)
+	if ngsilib.IsDryRun(err) {
+		return nil
+	}
+	if err != nil {
+		return ngsierr.New(funcName, 1, err.Error(), err)
+	}
+	if res.StatusCode != http.StatusCreated {
+		return ngsierr.New(funcName, 2, fmt.Sprintf("%s %s %s", path, res.Status, string(body)), nil)
+	}
+
+	return nil
+}
+
+func deleteKeys(m map[string]interface{}, keys ...string) {
+	for _, k := range keys {
+		delete(m, k)
+	}
+}
@@ -0,0 +1,585 @@
+/*
+MIT License
+
+Copyright (c) 2020-2024 Kazuhito Suda
+
+This file is part of NGSI Go
+
+https://github.com/lets-fiware/ngsi-go
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+
+*/
+
+package convenience
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/lets-fiware/ngsi-go/internal/assert"
+	"github.com/lets-fiware/ngsi-go/internal/helper"
+	"github.com/lets-fiware/ngsi-go/internal/ngsicli"
+	"github.com/lets-fiware/ngsi-go/internal/ngsierr"
+)
+
+func setTestArchive(t *testing.T, files ...string) string {
+	buf := &bytes.Buffer{}
+	zw := zip.NewWriter(buf)
+	for i := 0; i < len(files); i += 2 {
+		w, _ := zw.Create(files[i])
+		_, _ = w.Write([]byte(files[i+1]))
+	}
+	_ = zw.Close()
+	file := filepath.Join(t.TempDir(), "archive.zip")
+	_ = os.WriteFile(file, buf.Bytes(), 0600)
+	return file
+}
+
+func TestImportV2(t *testing.T) {
+	file := setTestArchive(t,
+		"subscriptions/000001.json", `[{"id":"5f0a44789dd803416ae9b0d1","status":"failed","notification":{"http":{"url":"http://old:1028/notify"},"timesSent":3,"lastNotification":"2020-09-01T00:00:00.000Z"}}]`,
+		"entities/000001.json", `[{"id":"device001","type":"Device"}]`,
+		"entities/000002.json", `[{"id":"device002","type":"Device"}]`,
+		"registrations/000001.json", `[{"id":"5f0a44789dd803416ae9b0d2","provider":{"http":{"url":"http://provider"}},"forwardingInformation":{"timesSent":1}}]`,
+		"manifest.json", `{"version":"1","ngsiType":"v2","entities":2,"subscriptions":1,"registrations":1,"contexts":0}`,
+	)
+	c := setupTest([]string{"import", "--host", "orion", "--file", file, "--replaceURL", "http://old:1028=http://new:1028"})
+
+	reqRes1 := helper.MockHTTPReqRes{}
+	reqRes1.Res.StatusCode = http.StatusNoContent
+	reqRes1.Path = "/v2/op/update"
+	reqRes1.ReqData = []byte(`{"actionType":"append","entities":[{"id":"device001","type":"Device"}]}`)
+
+	reqRes2 := helper.MockHTTPReqRes{}
+	reqRes2.Res.StatusCode = http.StatusNoContent
+	reqRes2.Path = "/v2/op/update"
+	reqRes2.ReqData = []byte(`{"actionType":"append","entities":[{"id":"device002","type":"Device"}]}`)
+
+	reqRes3 := helper.MockHTTPReqRes{}
+	reqRes3.Res.StatusCode = http.StatusCreated
+	reqRes3.Path = "/v2/registrations"
+	reqRes3.ReqData = []byte(`{"provider":{"http":{"url":"http://provider"}}}`)
+
+	reqRes4 := helper.MockHTTPReqRes{}
+	reqRes4.Res.StatusCode = http.StatusCreated
+	reqRes4.Path = "/v2/subscriptions"
+	reqRes4.ReqData = []byte(`{"notification":{"http":{"url":"http://new:1028/notify"}}}`)
+
+	helper.SetClientHTTP(c, reqRes1, reqRes2, reqRes3, reqRes4)
+
+	err := importArchive(c, c.Ngsi, c.Client)
+
+	if assert.NoError(t, err) {
+		actual := helper.GetStdoutString(c)
+		expected := "entities: 2, subscriptions: 1, registrations: 1, contexts: 0\n"
+		assert.Equal(t, expected, actual)
+	}
+}
+
+func TestImportLD(t *testing.T) {
+	file := setTestArchive(t,
+		"manifest.json", `{"version":"1","ngsiType":"ld","entities":1,"subscriptions":1,"registrations":1,"contexts":1}`,
+		"contexts/000001.json", `[{"@context":{"name":"https://example.org/name"}}]`,
+		"entities/000001.json", `[{"id":"urn:ngsi-ld:Device:001","type":"Device"}]`,
+		"registrations/000001.json", `[{"id":"urn:ngsi-ld:ContextSourceRegistration:001","type":"ContextSourceRegistration","status":"ok","timesSent":1}]`,
+		"subscriptions/000001.json", `[{"id":"urn:ngsi-ld:Subscription:001","type":"Subscription","status":"active","notification":{"endpoint":{"uri":"http://old:1028/notify"},"timesSent":3}}]`,
+	)
+	c := setupTest([]string{"import", "--host", "orion-ld", "--file", file, "--replaceURL", "http://old:1028=http://new:1028"})
+
+	reqRes1 := helper.MockHTTPReqRes{}
+	reqRes1.Res.StatusCode = http.StatusCreated
+	reqRes1.Path = "/ngsi-ld/v1/jsonldContexts"
+	reqRes1.ReqData = []byte(`{"@context":{"name":"https://example.org/name"}}`)
+
+	reqRes2 := helper.MockHTTPReqRes{}
+	reqRes2.Res.StatusCode = http.StatusCreated
+	reqRes2.Path = "/ngsi-ld/v1/entityOperations/upsert"
+	reqRes2.ReqData = []byte(`[{"id":"urn:ngsi-ld:Device:001","type":"Device"}]`)
+
+	reqRes3 := helper.MockHTTPReqRes{}
+	reqRes3.Res.StatusCode = http.StatusCreated
+	reqRes3.Path = "/ngsi-ld/v1/csourceRegistrations"
+	reqRes3.ReqData = []byte(`{"id":"urn:ngsi-ld:ContextSourceRegistration:001","type":"ContextSourceRegistration"}`)
+
+	reqRes4 := helper.MockHTTPReqRes{}
+	reqRes4.Res.StatusCode = http.StatusCreated
+	reqRes4.Path = "/ngsi-ld/v1/subscriptions"
+	reqRes4.ReqData = []byte(`{"id":"urn:ngsi-ld:Subscription:001","notification":{"endpoint":{"uri":"http://new:1028/notify"}},"type":"Subscription"}`)
+
+	helper.SetClientHTTP(c, reqRes1, reqRes2, reqRes3, reqRes4)
+
+	err := importArchive(c, c.Ngsi, c.Client)
+
+	if assert.NoError(t, err) {
+		actual := helper.GetStdoutString(c)
+		expected := "entities: 1, subscriptions: 1, registrations: 1, contexts: 1\n"
+		assert.Equal(t, expected, actual)
+		assert.Equal(t, "application/ld+json", c.Client.Headers["Content-Type"])
+	}
+}
+
+func TestImportDryRun(t *testing.T) {
+	file := setTestArchive(t,
+		"manifest.json", `{"version":"1","ngsiType":"v2","entities":1,"subscriptions":1,"registrations":0,"contexts":0}`,
+		"entities/000001.json", `[{"id":"device001","type":"Device"}]`,
+		"subscriptions/000001.json", `[{"notification":{"http":{"url":"http://receiver"}}}]`,
+	)
+	c := setupTest([]string{"--dryRun", "import", "--host", "orion", "--file", file})
+
+	err := importArchive(c, c.Ngsi, c.Client)
+
+	if assert.NoError(t, err) {
+		actual := helper.GetStdoutString(c)
+		expected := "POST https://orion/v2/op/update\n" +
+			"Accept: */*\nContent-Type: application/json\n\n" +
+			"{\"actionType\":\"append\",\"entities\":[{\"id\":\"device001\",\"type\":\"Device\"}]}\n\n" +
+			"POST https://orion/v2/subscriptions\n" +
+			"Accept: */*\nContent-Type: application/json\n\n" +
+			"{\"notification\":{\"http\":{\"url\":\"http://receiver\"}}}\n\n" +
+			"entities: 1, subscriptions: 1, registrations: 0, contexts: 0\n"
+		assert.Equal(t, expected, actual)
+	}
+}
+
+func TestImportErrorReplaceURL(t *testing.T) {
+	c := setupTest([]string{"import", "--host", "orion", "--file", "archive.zip", "--replaceURL", "http://old"})
+
+	err := importArchive(c, c.Ngsi, c.Client)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 1, ngsiErr.ErrNo)
+		assert.Equal(t, "replaceURL error: http://old", ngsiErr.Message)
+	}
+}
+
+func TestImportErrorOpen(t *testing.T) {
+	c := setupTest([]string{"import", "--host", "orion", "--file", "archive.zip"})
+
+	c.Ngsi.Ioutil = &helper.MockIoutilLib{OpenErr: errors.New("open error")}
+
+	err := importArchive(c, c.Ngsi, c.Client)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 2, ngsiErr.ErrNo)
+		assert.Equal(t, "open error", ngsiErr.Message)
+	}
+}
+
+func TestImportErrorStat(t *testing.T) {
+	file := setTestArchive(t, "manifest.json", `{}`)
+	c := setupTest([]string{"import", "--host", "orion", "--file", file})
+
+	c.Ngsi.Ioutil = &closedFileIoutil{}
+
+	err := importArchive(c, c.Ngsi, c.Client)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 3, ngsiErr.ErrNo)
+	}
+}
+
+func TestImportErrorZip(t *testing.T) {
+	file := setTestArchive(t, "manifest.json", `{}`)
+	c := setupTest([]string{"import", "--host", "orion", "--file", file})
+
+	c.Ngsi.ZipLib = &helper.MockZipLib{Zip: errors.New("zip error")}
+
+	err := importArchive(c, c.Ngsi, c.Client)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 4, ngsiErr.ErrNo)
+		assert.Equal(t, "zip error", ngsiErr.Message)
+	}
+}
+
+func TestImportErrorManifestNotFound(t *testing.T) {
+	file := setTestArchive(t, "entities/000001.json", `[]`)
+	c := setupTest([]string{"import", "--host", "orion", "--file", file})
+
+	err := importArchive(c, c.Ngsi, c.Client)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 5, ngsiErr.ErrNo)
+		assert.Equal(t, "manifest.json not found", ngsiErr.Message)
+	}
+}
+
+func TestImportErrorVersion(t *testing.T) {
+	file := setTestArchive(t, "manifest.json", `{"version":"2","ngsiType":"v2"}`)
+	c := setupTest([]string{"import", "--host", "orion", "--file", file})
+
+	err := importArchive(c, c.Ngsi, c.Client)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 6, ngsiErr.ErrNo)
+		assert.Equal(t, "unsupported archive version: 2", ngsiErr.Message)
+	}
+}
+
+func TestImportErrorNgsiType(t *testing.T) {
+	file := setTestArchive(t, "manifest.json", `{"version":"1","ngsiType":"ld"}`)
+	c := setupTest([]string{"import", "--host", "orion", "--file", file})
+
+	err := importArchive(c, c.Ngsi, c.Client)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 7, ngsiErr.ErrNo)
+		assert.Equal(t, "archive is for ld, but orion is v2", ngsiErr.Message)
+	}
+}
+
+func TestImportErrorContexts(t *testing.T) {
+	file := setTestArchive(t,
+		"manifest.json", `{"version":"1","ngsiType":"ld"}`,
+		"contexts/000001.json", `[{"@context":{}}]`,
+	)
+	c := setupTest([]string{"import", "--host", "orion-ld", "--file", file})
+
+	reqRes := helper.MockHTTPReqRes{}
+	reqRes.Err = errors.New("http error")
+	helper.SetClientHTTP(c, reqRes)
+
+	err := importArchive(c, c.Ngsi, c.Client)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 8, ngsiErr.ErrNo)
+		assert.Equal(t, "http error", ngsiErr.Message)
+	}
+}
+
+func TestImportErrorEntities(t *testing.T) {
+	file := setTestArchive(t,
+		"manifest.json", `{"version":"1","ngsiType":"v2"}`,
+		"entities/000001.json", `[{"id":"device001","type":"Device"}]`,
+	)
+	c := setupTest([]string{"import", "--host", "orion", "--file", file})
+
+	reqRes := helper.MockHTTPReqRes{}
+	reqRes.Err = errors.New("http error")
+	helper.SetClientHTTP(c, reqRes)
+
+	err := importArchive(c, c.Ngsi, c.Client)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 9, ngsiErr.ErrNo)
+		assert.Equal(t, "http error", ngsiErr.Message)
+	}
+}
+
+func TestImportErrorRegistrations(t *testing.T) {
+	file := setTestArchive(t,
+		"manifest.json", `{"version":"1","ngsiType":"v2"}`,
+		"registrations/000001.json", `[{"id":"5f0a44789dd803416ae9b0d2"}]`,
+	)
+	c := setupTest([]string{"import", "--host", "orion", "--file", file})
+
+	reqRes := helper.MockHTTPReqRes{}
+	reqRes.Err = errors.New("http error")
+	helper.SetClientHTTP(c, reqRes)
+
+	err := importArchive(c, c.Ngsi, c.Client)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 10, ngsiErr.ErrNo)
+		assert.Equal(t, "http error", ngsiErr.Message)
+	}
+}
+
+func TestImportErrorSubscriptions(t *testing.T) {
+	file := setTestArchive(t,
+		"manifest.json", `{"version":"1","ngsiType":"v2"}`,
+		"subscriptions/000001.json", `[{"id":"5f0a44789dd803416ae9b0d1"}]`,
+	)
+	c := setupTest([]string{"import", "--host", "orion", "--file", file})
+
+	reqRes := helper.MockHTTPReqRes{}
+	reqRes.Err = errors.New("http error")
+	helper.SetClientHTTP(c, reqRes)
+
+	err := importArchive(c, c.Ngsi, c.Client)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 11, ngsiErr.ErrNo)
+		assert.Equal(t, "http error", ngsiErr.Message)
+	}
+}
+
+func TestImportReplaceURL(t *testing.T) {
+	actual, err := importReplaceURL("http://a=http://b,mqtt://c=mqtt://d")
+
+	if assert.NoError(t, err) {
+		expected := [][2]string{{"http://a", "http://b"}, {"mqtt://c", "mqtt://d"}}
+		assert.Equal(t, expected, actual)
+	}
+}
+
+func TestImportReplaceURLErrorEmpty(t *testing.T) {
+	_, err := importReplaceURL("=http://b")
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 1, ngsiErr.ErrNo)
+		assert.Equal(t, "replaceURL error: =http://b", ngsiErr.Message)
+	}
+}
+
+func TestImportReadErrorTooLargeHeader(t *testing.T) {
+	file := setTestArchive(t, "manifest.json", `{"version":"1"}`)
+	c := setupTest([]string{"import", "--host", "orion", "--file", file})
+
+	a := newTestArchiveImporter(t, c, file)
+	a.files[0].UncompressedSize64 = uint64(importMaxSize) + 1
+
+	var manifest archiveManifest
+	err := a.read(exportManifest, &manifest)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 1, ngsiErr.ErrNo)
+		assert.Equal(t, "manifest.json too large", ngsiErr.Message)
+	}
+}
+
+func TestImportReadErrorCopy(t *testing.T) {
+	file := setTestArchive(t, "manifest.json", `{}`)
+	c := setupTest([]string{"import", "--host", "orion", "--file", file})
+
+	a := newTestArchiveImporter(t, c, file)
+	a.ngsi.Ioutil = &helper.MockIoutilLib{CopyErr: errors.New("read error")}
+
+	var manifest archiveManifest
+	err := a.read(exportManifest, &manifest)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 3, ngsiErr.ErrNo)
+		assert.Equal(t, "read error", ngsiErr.Message)
+	}
+}
+
+func TestImportReadErrorJSON(t *testing.T) {
+	file := setTestArchive(t, "manifest.json", `{}`)
+	c := setupTest([]string{"import", "--host", "orion", "--file", file})
+
+	a := newTestArchiveImporter(t, c, file)
+	helper.SetJSONDecodeErr(c.Ngsi, 0)
+
+	var manifest archiveManifest
+	err := a.read(exportManifest, &manifest)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 4, ngsiErr.ErrNo)
+		assert.Equal(t, "manifest.json: json error", ngsiErr.Message)
+	}
+}
+
+func TestImportEntitiesErrorRead(t *testing.T) {
+	file := setTestArchive(t, "entities/000001.json", `{}`)
+	c := setupTest([]string{"import", "--host", "orion", "--file", file})
+
+	a := newTestArchiveImporter(t, c, file)
+
+	_, err := a.entities()
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 1, ngsiErr.ErrNo)
+	}
+}
+
+func TestImportEntitiesErrorMarshalLD(t *testing.T) {
+	file := setTestArchive(t, "entities/000001.json", `[{"id":"urn:ngsi-ld:Device:001","type":"Device"}]`)
+	c := setupTest([]string{"import", "--host", "orion-ld", "--file", file})
+
+	a := newTestArchiveImporter(t, c, file)
+	helper.SetJSONEncodeErr(c.Ngsi, 0)
+
+	_, err := a.entities()
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 2, ngsiErr.ErrNo)
+		assert.Equal(t, "json error", ngsiErr.Message)
+	}
+}
+
+func TestImportEntitiesErrorStatus(t *testing.T) {
+	file := setTestArchive(t, "entities/000001.json", `[{"id":"urn:ngsi-ld:Device:001","type":"Device"}]`)
+	c := setupTest([]string{"import", "--host", "orion-ld", "--file", file})
+
+	a := newTestArchiveImporter(t, c, file)
+
+	reqRes := helper.MockHTTPReqRes{}
+	reqRes.Res.StatusCode = http.StatusMultiStatus
+	reqRes.Res.Status = "207 Multi-Status"
+	reqRes.ResBody = []byte("error")
+	helper.SetClientHTTP(c, reqRes)
+
+	_, err := a.entities()
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 4, ngsiErr.ErrNo)
+		assert.Equal(t, "entities/000001.json: 207 Multi-Status error", ngsiErr.Message)
+	}
+}
+
+func TestImportItemsErrorRead(t *testing.T) {
+	file := setTestArchive(t, "subscriptions/000001.json", `{}`)
+	c := setupTest([]string{"import", "--host", "orion", "--file", file})
+
+	a := newTestArchiveImporter(t, c, file)
+
+	_, err := a.items("subscriptions", a.subscription)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 1, ngsiErr.ErrNo)
+	}
+}
+
+func TestImportRegistrationErrorUnmarshal(t *testing.T) {
+	c := setupTest([]string{"import", "--host", "orion", "--file", "archive.zip"})
+
+	a := &archiveImporter{ngsi: c.Ngsi, client: c.Client}
+
+	err := a.registration([]byte(`[]`))
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 1, ngsiErr.ErrNo)
+	}
+}
+
+func TestImportRegistrationErrorMarshal(t *testing.T) {
+	c := setupTest([]string{"import", "--host", "orion", "--file", "archive.zip"})
+
+	a := &archiveImporter{ngsi: c.Ngsi, client: c.Client}
+	helper.SetJSONEncodeErr(c.Ngsi, 0)
+
+	err := a.registration([]byte(`{}`))
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 2, ngsiErr.ErrNo)
+		assert.Equal(t, "json error", ngsiErr.Message)
+	}
+}
+
+func TestImportSubscriptionErrorUnmarshal(t *testing.T) {
+	c := setupTest([]string{"import", "--host", "orion", "--file", "archive.zip"})
+
+	a := &archiveImporter{ngsi: c.Ngsi, client: c.Client}
+
+	err := a.subscription([]byte(`[]`))
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 1, ngsiErr.ErrNo)
+	}
+}
+
+func TestImportSubscriptionErrorMarshal(t *testing.T) {
+	c := setupTest([]string{"import", "--host", "orion", "--file", "archive.zip"})
+
+	a := &archiveImporter{ngsi: c.Ngsi, client: c.Client}
+	helper.SetJSONEncodeErr(c.Ngsi, 0)
+
+	err := a.subscription([]byte(`{}`))
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 2, ngsiErr.ErrNo)
+		assert.Equal(t, "json error", ngsiErr.Message)
+	}
+}
+
+func TestImportSubscriptionKeepStatus(t *testing.T) {
+	c := setupTest([]string{"import", "--host", "orion", "--file", "archive.zip"})
+
+	reqRes := helper.MockHTTPReqRes{}
+	reqRes.Res.StatusCode = http.StatusCreated
+	reqRes.ReqData = []byte(`{"notification":{"httpCustom":{"url":"http://other:1028/"}},"status":"inactive"}`)
+	helper.SetClientHTTP(c, reqRes)
+
+	a := &archiveImporter{ngsi: c.Ngsi, client: c.Client, replaces: [][2]string{{"http://old", "http://new"}}}
+
+	err := a.subscription([]byte(`{"id":"5f0a44789dd803416ae9b0d1","status":"inactive","notification":{"httpCustom":{"url":"http://other:1028/"}}}`))
+
+	assert.NoError(t, err)
+}
+
+func TestImportPostErrorStatus(t *testing.T) {
+	c := setupTest([]string{"import", "--host", "orion", "--file", "archive.zip"})
+
+	reqRes := helper.MockHTTPReqRes{}
+	reqRes.Res.StatusCode = http.StatusBadRequest
+	reqRes.Res.Status = "400 Bad Request"
+	reqRes.ResBody = []byte("error")
+	helper.SetClientHTTP(c, reqRes)
+
+	a := &archiveImporter{ngsi: c.Ngsi, client: c.Client}
+
+	err := a.post("/subscriptions", []byte(`{}`))
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 2, ngsiErr.ErrNo)
+		assert.Equal(t, "/subscriptions 400 Bad Request error", ngsiErr.Message)
+	}
+}
+
+func newTestArchiveImporter(t *testing.T, c *ngsicli.Context, file string) *archiveImporter {
+	zr, err := zip.OpenReader(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = zr.Close() })
+	return &archiveImporter{ngsi: c.Ngsi, client: c.Client, files: zr.File}
+}
+
+type closedFileIoutil struct {
+	helper.MockIoutilLib
+}
+
+func (i *closedFileIoutil) Open(name string) (*os.File, error) {
+	f, err := os.Open(name)
+	if err == nil {
+		_ = f.Close()
+	}
+	return f, err
+}
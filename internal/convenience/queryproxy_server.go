@@ -94,6 +94,10 @@ func queryProxyServer(c *ngsicli.Context, ngsi *ngsilib.NGSI, client *ngsilib.Cl
 	}
 
 	stat := &queryProxyStat{mutex: &sync.Mutex{}, startTime: time.Now()}
+	mutex := &sync.Mutex{}
+
+	refresher := ngsi.StartTokenRefresher(client, mutex)
+	defer refresher.Stop()
 
 	mux := http.NewServeMux()
 	mux.Handle("/", &queryProxyRootHandler{ngsi: ngsi})
@@ -104,7 +108,7 @@ func queryProxyServer(c *ngsicli.Context, ngsi *ngsilib.NGSI, client *ngsilib.Cl
 		client:  client,
 		http:    ngsi.HTTP,
 		verbose: c.Bool("verbose"),
-		mutex:   &sync.Mutex{},
+		mutex:   mutex,
 		stat:    stat,
 	})
 
@@ -36,8 +36,12 @@ import (
 	"io"
 	"net"
 	"net/http"
+	"net/url"
 	"sort"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/lets-fiware/ngsi-go/internal/ngsicli"
 	"github.com/lets-fiware/ngsi-go/internal/ngsierr"
@@ -54,9 +58,6 @@ func receiver(c *ngsicli.Context, ngsi *ngsilib.NGSI, client *ngsilib.Client) er
 	path := c.String("url")
 	url := addr + path
 
-	pretty := c.Bool("pretty")
-	header := c.Bool("header")
-
 	if c.Bool("https") {
 		if !c.IsSet("key") {
 			return ngsierr.New(funcName, 1, "no key file provided", nil)
@@ -69,8 +70,20 @@ func receiver(c *ngsicli.Context, ngsi *ngsilib.NGSI, client *ngsilib.Client) er
 		url = "http://" + url
 	}
 
+	h, err := newReceiverHandler(c, ngsi)
+	if err != nil {
+		return ngsierr.New(funcName, 5, err.Error(), err)
+	}
+
+	if c.IsSet("replay") {
+		if err = h.replay(c.String("replay")); err != nil {
+			return ngsierr.New(funcName, 6, err.Error(), err)
+		}
+		return nil
+	}
+
 	mux := http.NewServeMux()
-	mux.Handle(path, &receiverHandler{ngsi: ngsi, pretty: pretty, header: header})
+	mux.Handle(path, h)
 
 	addrs, _ := ngsi.NetLib.InterfaceAddrs()
 	ip := []string{}
@@ -89,29 +102,126 @@ func receiver(c *ngsicli.Context, ngsi *ngsilib.NGSI, client *ngsilib.Client) er
 
 	ngsi.Logging(ngsilib.LogInfo, url+"\n")
 
-	if c.Bool("https") {
-		err := ngsi.NetLib.ListenAndServeTLS(addr, c.String("cert"), c.String("key"), mux)
-		if err != nil {
-			return ngsierr.New(funcName, 3, err.Error(), err)
+	serve := make(chan error, 1)
+	go func() {
+		if c.Bool("https") {
+			err := ngsi.NetLib.ListenAndServeTLS(addr, c.String("cert"), c.String("key"), mux)
+			if err != nil {
+				serve <- ngsierr.New(funcName, 3, err.Error(), err)
+				return
+			}
+		} else {
+			err := ngsi.NetLib.ListenAndServe(addr, mux)
+			if err != nil {
+				serve <- ngsierr.New(funcName, 4, err.Error(), err)
+				return
+			}
 		}
-	} else {
-		err := ngsi.NetLib.ListenAndServe(addr, mux)
+		serve <- nil
+	}()
+
+	return h.wait(serve)
+}
+
+type receiverHandler struct {
+	ngsi     *ngsilib.NGSI
+	http     ngsilib.HTTPRequest
+	pretty   bool
+	header   bool
+	store    string
+	status   []int
+	delay    time.Duration
+	forward  *url.URL
+	expect   *ngsilib.JSONFilter
+	count    int64
+	deadline time.Duration
+	mutex    sync.Mutex
+	received int64
+	done     chan error
+}
+
+// receiverRecord is a notification stored by --store and sent again by --replay
+type receiverRecord struct {
+	Timestamp string            `json:"timestamp"`
+	Path      string            `json:"path"`
+	Headers   map[string]string `json:"headers"`
+	Body      json.RawMessage   `json:"body"`
+}
+
+// receiverSkipHeaders are the headers which are not forwarded
+var receiverSkipHeaders = []string{"Accept-Encoding", "Connection", "Content-Length", "Host", "User-Agent"}
+
+func newReceiverHandler(c *ngsicli.Context, ngsi *ngsilib.NGSI) (*receiverHandler, error) {
+	const funcName = "newReceiverHandler"
+
+	h := &receiverHandler{
+		ngsi:   ngsi,
+		http:   ngsi.HTTP,
+		pretty: c.Bool("pretty"),
+		header: c.Bool("header"),
+		store:  c.String("store"),
+		count:  c.Int64("count"),
+		done:   make(chan error, 1),
+	}
+
+	if c.IsSet("status") {
+		for _, s := range strings.Split(c.String("status"), ",") {
+			code, err := strconv.Atoi(strings.TrimSpace(s))
+			if err != nil || code < 200 || code > 599 {
+				return nil, ngsierr.New(funcName, 1, "status error: "+c.String("status"), err)
+			}
+			h.status = append(h.status, code)
+		}
+	}
+
+	if c.IsSet("delay") {
+		d, err := time.ParseDuration(c.String("delay"))
+		if err != nil || d < 0 {
+			return nil, ngsierr.New(funcName, 2, "delay error: "+c.String("delay"), err)
+		}
+		h.delay = d
+	}
+
+	if c.IsSet("forward") {
+		u, err := url.Parse(c.String("forward"))
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, ngsierr.New(funcName, 3, "forward error: "+c.String("forward"), err)
+		}
+		h.forward = u
+	}
+
+	if c.IsSet("expect") {
+		f, err := ngsilib.NewJSONFilter(c.String("expect"))
 		if err != nil {
-			return ngsierr.New(funcName, 4, err.Error(), err)
+			return nil, ngsierr.New(funcName, 4, err.Error(), err)
+		}
+		h.expect = f
+		if h.count == 0 {
+			h.count = 1
 		}
 	}
 
-	return nil
-}
+	if h.count < 0 {
+		return nil, ngsierr.New(funcName, 5, fmt.Sprintf("count error: %d", h.count), nil)
+	}
 
-type receiverHandler struct {
-	ngsi   *ngsilib.NGSI
-	pretty bool
-	header bool
+	if c.IsSet("deadline") {
+		d, err := time.ParseDuration(c.String("deadline"))
+		if err != nil || d <= 0 {
+			return nil, ngsierr.New(funcName, 6, "deadline error: "+c.String("deadline"), err)
+		}
+		h.deadline = d
+	}
+
+	return h, nil
 }
 
 func (h *receiverHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	const funcName = "receiverHandler"
+
 	status := http.StatusNoContent
+	var result error
+	finished := false
 
 	switch r.Method {
 	default:
@@ -136,6 +246,12 @@ func (h *receiverHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		header = "[" + strings.TrimSpace(header) + "]"
 		h.ngsi.Logging(ngsilib.LogInfo, header)
 
+		headers := map[string]string{}
+		for _, k := range key {
+			headers[k] = r.Header.Get(k)
+		}
+
+		h.mutex.Lock()
 		if h.header {
 			for _, k := range key {
 				fmt.Fprintf(h.ngsi.StdWriter, "%s: %s\n", k, r.Header.Get(k))
@@ -146,15 +262,220 @@ func (h *receiverHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		b := buf.Bytes()
 		h.ngsi.Logging(ngsilib.LogInfo, string(b))
 
-		if h.pretty && ngsilib.IsJSON(b) {
-			newBuf := new(bytes.Buffer)
-			err := json.Indent(newBuf, b, "", "  ")
-			if err == nil {
-				b = newBuf.Bytes()
+		receiverPrint(h.ngsi, b, h.pretty)
+		h.received++
+		n := h.received
+
+		var storeErr error
+		if h.store != "" {
+			storeErr = h.record(r.URL.Path, headers, b)
+		}
+		h.mutex.Unlock()
+
+		if storeErr != nil {
+			result = ngsierr.New(funcName, 1, fmt.Sprintf("notification %d: %s", n, storeErr.Error()), storeErr)
+			finished = true
+		}
+
+		if h.forward != nil {
+			res, resBody, err := h.send(headers, b)
+			if err != nil {
+				h.ngsi.Logging(ngsilib.LogErr, ngsierr.SprintMsg(funcName, 2, err.Error())+"\n")
+			} else if res.StatusCode/100 != 2 {
+				h.ngsi.Logging(ngsilib.LogErr, ngsierr.SprintMsg(funcName, 3, fmt.Sprintf("%s %s", res.Status, string(resBody)))+"\n")
+			}
+		}
+
+		if h.expect != nil && result == nil {
+			if err := h.match(b); err != nil {
+				result = ngsierr.New(funcName, 4, fmt.Sprintf("notification %d: %s", n, err.Error()), err)
+				finished = true
 			}
 		}
-		fmt.Fprintf(h.ngsi.StdWriter, "%s\n", string(b))
-		h.ngsi.StdoutFlush()
+		if h.count > 0 && n == h.count {
+			finished = true
+		}
+
+		if storeErr != nil {
+			status = http.StatusInternalServerError
+		} else if len(h.status) > 0 {
+			status = h.status[len(h.status)-1]
+			if n <= int64(len(h.status)) {
+				status = h.status[n-1]
+			}
+		}
+
+		if h.delay > 0 {
+			time.Sleep(h.delay)
+		}
 	}
 	w.WriteHeader(status)
+
+	if finished {
+		h.finish(result)
+	}
+}
+
+func receiverPrint(ngsi *ngsilib.NGSI, b []byte, pretty bool) {
+	if pretty && ngsilib.IsJSON(b) {
+		newBuf := new(bytes.Buffer)
+		err := json.Indent(newBuf, b, "", "  ")
+		if err == nil {
+			b = newBuf.Bytes()
+		}
+	}
+	fmt.Fprintf(ngsi.StdWriter, "%s\n", string(b))
+	ngsi.StdoutFlush()
+}
+
+// record appends a notification to the --store file as a line of JSON. It is called with h.mutex
+// held so that the lines are in the order in which the notifications were counted.
+func (h *receiverHandler) record(path string, headers map[string]string, b []byte) error {
+	const funcName = "receiverRecord"
+
+	body := json.RawMessage(b)
+	if !ngsilib.IsJSON(b) {
+		s, err := ngsilib.JSONMarshal(string(b))
+		if err != nil {
+			return ngsierr.New(funcName, 1, err.Error(), err)
+		}
+		body = s
+	}
+
+	rec := receiverRecord{
+		Timestamp: h.ngsi.TimeLib.Now().UTC().Format("2006-01-02T15:04:05.000Z"),
+		Path:      path,
+		Headers:   headers,
+		Body:      body,
+	}
+
+	line, err := ngsilib.JSONMarshal(&rec)
+	if err != nil {
+		return ngsierr.New(funcName, 2, err.Error(), err)
+	}
+
+	err = h.ngsi.Ioutil.AppendFile(h.store, append(line, '\n'), 0600)
+	if err != nil {
+		return ngsierr.New(funcName, 3, err.Error(), err)
+	}
+
+	return nil
+}
+
+// send posts a notification to the --forward url.
+func (h *receiverHandler) send(headers map[string]string, b []byte) (*http.Response, []byte, error) {
+	hdr := map[string]string{}
+	for k, v := range headers {
+		if !ngsilib.Contains(receiverSkipHeaders, http.CanonicalHeaderKey(k)) {
+			hdr[k] = v
+		}
+	}
+	return h.http.Request(http.MethodPost, h.forward, hdr, b)
+}
+
+// match reports an error when a notification doesn't satisfy --expect.
+func (h *receiverHandler) match(b []byte) error {
+	const funcName = "receiverMatch"
+
+	var v interface{}
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+	if err := d.Decode(&v); err != nil {
+		return ngsierr.New(funcName, 1, "not JSON: "+err.Error(), err)
+	}
+
+	ok, err := h.expect.Match(v)
+	if err != nil {
+		return ngsierr.New(funcName, 2, err.Error(), err)
+	}
+	if !ok {
+		return ngsierr.New(funcName, 3, "doesn't match expect", nil)
+	}
+
+	return nil
+}
+
+func (h *receiverHandler) finish(err error) {
+	select {
+	case h.done <- err:
+	default:
+	}
+}
+
+// wait returns when the receiver stops, when --count notifications have been received, when a
+// notification doesn't satisfy --expect or when --deadline has passed. An error of the receiver
+// is returned as it is.
+func (h *receiverHandler) wait(serve <-chan error) error {
+	const funcName = "receiverWait"
+
+	var deadline <-chan time.Time
+	if h.deadline > 0 {
+		deadline = time.After(h.deadline)
+	}
+
+	select {
+	case err := <-serve:
+		return err
+	case err := <-h.done:
+		if err != nil {
+			return ngsierr.New(funcName, 1, err.Error(), err)
+		}
+		return nil
+	case <-deadline:
+		if h.count > 0 {
+			h.mutex.Lock()
+			n := h.received
+			h.mutex.Unlock()
+			return ngsierr.New(funcName, 2, fmt.Sprintf("deadline exceeded: %d of %d notifications received", n, h.count), nil)
+		}
+		return nil
+	}
+}
+
+// replay sends the notifications stored in file to the --forward url in the order they were received.
+func (h *receiverHandler) replay(file string) error {
+	const funcName = "receiverReplay"
+
+	if h.forward == nil {
+		return ngsierr.New(funcName, 1, "specify --forward to replay notifications", nil)
+	}
+
+	b, err := h.ngsi.Ioutil.ReadFile(file)
+	if err != nil {
+		return ngsierr.New(funcName, 2, err.Error(), err)
+	}
+
+	n := 0
+	for i, line := range strings.Split(string(b), "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		var rec receiverRecord
+		if err = ngsilib.JSONUnmarshal([]byte(line), &rec); err != nil {
+			return ngsierr.New(funcName, 3, fmt.Sprintf("line %d: %s", i+1, err.Error()), err)
+		}
+
+		body := []byte(rec.Body)
+		if strings.HasPrefix(string(body), `"`) {
+			var s string
+			if err = ngsilib.JSONUnmarshal(body, &s); err != nil {
+				return ngsierr.New(funcName, 4, fmt.Sprintf("line %d: %s", i+1, err.Error()), err)
+			}
+			body = []byte(s)
+		}
+
+		res, resBody, err := h.send(rec.Headers, body)
+		if err != nil {
+			return ngsierr.New(funcName, 5, fmt.Sprintf("line %d: %s", i+1, err.Error()), err)
+		}
+		if res.StatusCode/100 != 2 {
+			return ngsierr.New(funcName, 6, fmt.Sprintf("line %d: %s %s", i+1, res.Status, string(resBody)), nil)
+		}
+		n++
+	}
+
+	fmt.Fprintf(h.ngsi.StdWriter, "%d notifications replayed\n", n)
+
+	return nil
 }
@@ -34,7 +34,9 @@ import (
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/lets-fiware/ngsi-go/internal/assert"
 	"github.com/lets-fiware/ngsi-go/internal/helper"
@@ -207,3 +209,365 @@ func TestReceiverHanderHeader(t *testing.T) {
 	expected2 := "Fiware-Service: openiot\n\n{\"subscriptionId\":\"5fd412e8ecb082767349b975\",\"data\":[{\"id\":\"device001\",\"type\":\"device\",\"temperature\":{\"type\":\"Number\",\"value\":25,\"metadata\":{}}}]}\n"
 	assert.Equal(t, expected2, helper.GetStdoutString(c))
 }
+
+func TestReceiverErrorHandler(t *testing.T) {
+	c := setupTest([]string{"receiver", "--status", "600"})
+
+	err := receiver(c, c.Ngsi, c.Client)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 5, ngsiErr.ErrNo)
+		assert.Equal(t, "status error: 600", ngsiErr.Message)
+	}
+}
+
+func TestReceiverReplay(t *testing.T) {
+	c := setupTest([]string{"receiver", "--replay", "notifications.jsonl", "--forward", "http://consumer:8080/notify"})
+
+	c.Ngsi.Ioutil = &helper.MockIoutilLib{ReadFileData: []byte(receiverTestRecords)}
+	mock := helper.NewMockHTTP()
+	mock.ReqRes = []helper.MockHTTPReqRes{
+		{Res: http.Response{StatusCode: http.StatusNoContent}, Path: "/notify", ReqData: []byte(`{"subscriptionId":"5fd412e8ecb082767349b975","data":[]}`)},
+		{Res: http.Response{StatusCode: http.StatusOK}, Path: "/notify", ReqData: []byte("text")},
+	}
+	c.Ngsi.HTTP = mock
+
+	err := receiver(c, c.Ngsi, c.Client)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, "2 notifications replayed\n", helper.GetStdoutString(c))
+	}
+}
+
+func TestReceiverErrorReplay(t *testing.T) {
+	c := setupTest([]string{"receiver", "--replay", "notifications.jsonl"})
+
+	err := receiver(c, c.Ngsi, c.Client)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 6, ngsiErr.ErrNo)
+		assert.Equal(t, "specify --forward to replay notifications", ngsiErr.Message)
+	}
+}
+
+const receiverTestRecords = `{"timestamp":"2026-10-01T10:00:00.000Z","path":"/","headers":{"Content-Length":"55","Content-Type":"application/json","Fiware-Service":"openiot"},"body":{"subscriptionId":"5fd412e8ecb082767349b975","data":[]}}
+
+{"timestamp":"2026-10-01T10:00:01.000Z","path":"/","headers":{"Content-Type":"text/plain"},"body":"text"}
+`
+
+func TestNewReceiverHandler(t *testing.T) {
+	c := setupTest([]string{"receiver", "--store", "notifications.jsonl", "--status", "500, 204", "--delay", "10ms", "--forward", "https://consumer/notify", "--expect", ".data", "--deadline", "1m"})
+
+	h, err := newReceiverHandler(c, c.Ngsi)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, "notifications.jsonl", h.store)
+		assert.Equal(t, []int{500, 204}, h.status)
+		assert.Equal(t, 10*time.Millisecond, h.delay)
+		assert.Equal(t, "https://consumer/notify", h.forward.String())
+		assert.Equal(t, int64(1), h.count)
+		assert.Equal(t, time.Minute, h.deadline)
+	}
+}
+
+func TestNewReceiverHandlerError(t *testing.T) {
+	cases := []struct {
+		args     []string
+		errno    int
+		expected string
+	}{
+		{args: []string{"--status", "204,abc"}, errno: 1, expected: "status error: 204,abc"},
+		{args: []string{"--status", "100"}, errno: 1, expected: "status error: 100"},
+		{args: []string{"--delay", "1x"}, errno: 2, expected: "delay error: 1x"},
+		{args: []string{"--delay", "-1s"}, errno: 2, expected: "delay error: -1s"},
+		{args: []string{"--forward", "consumer:8080"}, errno: 3, expected: "forward error: consumer:8080"},
+		{args: []string{"--forward", "http://"}, errno: 3, expected: "forward error: http://"},
+		{args: []string{"--expect", ".data |"}, errno: 4, expected: "unexpected end of expression"},
+		{args: []string{"--count", "-1"}, errno: 5, expected: "count error: -1"},
+		{args: []string{"--deadline", "0s"}, errno: 6, expected: "deadline error: 0s"},
+	}
+
+	for _, tc := range cases {
+		c := setupTest(append([]string{"receiver"}, tc.args...))
+
+		_, err := newReceiverHandler(c, c.Ngsi)
+
+		if assert.Error(t, err, tc.args) {
+			ngsiErr := err.(*ngsierr.NgsiError)
+			assert.Equal(t, tc.errno, ngsiErr.ErrNo, tc.args)
+			assert.Equal(t, tc.expected, ngsiErr.Message, tc.args)
+		}
+	}
+}
+
+func receiverTestPost(h *receiverHandler, body string) int {
+	req := httptest.NewRequest(http.MethodPost, "http://receiver/notify", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Fiware-Service", "openiot")
+
+	got := httptest.NewRecorder()
+	h.ServeHTTP(got, req)
+
+	return got.Code
+}
+
+func TestReceiverHanderStore(t *testing.T) {
+	c := setupTest([]string{"receiver", "--store", "notifications.jsonl"})
+
+	buf := &bytes.Buffer{}
+	c.Ngsi.Ioutil = &helper.MockIoutilLib{AppendData: buf}
+	c.Ngsi.TimeLib = &helper.MockTimeLib{DateTime: "2026-10-01T10:00:00.000Z"}
+
+	h, _ := newReceiverHandler(c, c.Ngsi)
+
+	assert.Equal(t, http.StatusNoContent, receiverTestPost(h, `{"data":[]}`))
+	assert.Equal(t, http.StatusNoContent, receiverTestPost(h, `text`))
+
+	expected := `{"timestamp":"2026-10-01T10:00:00.000Z","path":"/notify","headers":{"Content-Type":"application/json","Fiware-Service":"openiot"},"body":{"data":[]}}` + "\n" +
+		`{"timestamp":"2026-10-01T10:00:00.000Z","path":"/notify","headers":{"Content-Type":"application/json","Fiware-Service":"openiot"},"body":"text"}` + "\n"
+	assert.Equal(t, expected, buf.String())
+}
+
+func TestReceiverHanderStoreError(t *testing.T) {
+	c := setupTest([]string{"receiver", "--store", "notifications.jsonl"})
+
+	c.Ngsi.Ioutil = &helper.MockIoutilLib{AppendErr: errors.New("AppendFile error")}
+
+	h, _ := newReceiverHandler(c, c.Ngsi)
+
+	assert.Equal(t, http.StatusInternalServerError, receiverTestPost(h, `{"data":[]}`))
+
+	serve := make(chan error, 1)
+	err := h.wait(serve)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 1, ngsiErr.ErrNo)
+		assert.Equal(t, "notification 1: AppendFile error", ngsiErr.Message)
+		ngsiErr = ngsiErr.Err.(*ngsierr.NgsiError)
+		assert.Equal(t, "receiverHandler", ngsiErr.Function)
+		assert.Equal(t, 1, ngsiErr.ErrNo)
+	}
+}
+
+func TestReceiverHanderForward(t *testing.T) {
+	c := setupTest([]string{"receiver", "--forward", "http://consumer:8080/notify"})
+
+	mock := helper.NewMockHTTP()
+	mock.ReqRes = []helper.MockHTTPReqRes{
+		{Res: http.Response{StatusCode: http.StatusNoContent}, Path: "/notify", ReqData: []byte(`{"data":[]}`)},
+		{Res: http.Response{StatusCode: http.StatusInternalServerError, Status: "500 Internal Server Error"}, ResBody: []byte("error")},
+		{Err: errors.New("http error")},
+	}
+	c.Ngsi.HTTP = mock
+
+	h, _ := newReceiverHandler(c, c.Ngsi)
+
+	assert.Equal(t, http.StatusNoContent, receiverTestPost(h, `{"data":[]}`))
+	assert.Equal(t, http.StatusNoContent, receiverTestPost(h, `{"data":[]}`))
+	assert.Equal(t, http.StatusNoContent, receiverTestPost(h, `{"data":[]}`))
+
+	log := c.Ngsi.LogWriter.(*bytes.Buffer).String()
+	assert.Equal(t, true, strings.Contains(log, "receiverHandler003 500 Internal Server Error error\n"))
+	assert.Equal(t, true, strings.Contains(log, "receiverHandler002 http error\n"))
+}
+
+func TestReceiverHanderStatus(t *testing.T) {
+	c := setupTest([]string{"receiver", "--status", "500,503,200", "--delay", "1ms"})
+
+	h, _ := newReceiverHandler(c, c.Ngsi)
+
+	assert.Equal(t, http.StatusInternalServerError, receiverTestPost(h, `{}`))
+	assert.Equal(t, http.StatusServiceUnavailable, receiverTestPost(h, `{}`))
+	assert.Equal(t, http.StatusOK, receiverTestPost(h, `{}`))
+	assert.Equal(t, http.StatusOK, receiverTestPost(h, `{}`))
+}
+
+func TestReceiverHanderCount(t *testing.T) {
+	c := setupTest([]string{"receiver", "--count", "2", "--expect", ".data[0].temperature.value > 20"})
+
+	h, _ := newReceiverHandler(c, c.Ngsi)
+
+	receiverTestPost(h, `{"data":[{"temperature":{"value":25}}]}`)
+	assert.Equal(t, 0, len(h.done))
+
+	receiverTestPost(h, `{"data":[{"temperature":{"value":21.5}}]}`)
+
+	err := h.wait(nil)
+
+	assert.NoError(t, err)
+}
+
+func TestReceiverHanderExpectMismatch(t *testing.T) {
+	c := setupTest([]string{"receiver", "--count", "2", "--expect", ".data[0].temperature.value > 20"})
+
+	h, _ := newReceiverHandler(c, c.Ngsi)
+
+	receiverTestPost(h, `{"data":[{"temperature":{"value":19}}]}`)
+
+	err := h.wait(nil)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 1, ngsiErr.ErrNo)
+		assert.Equal(t, "notification 1: doesn't match expect", ngsiErr.Message)
+	}
+}
+
+func TestReceiverMatchError(t *testing.T) {
+	cases := []struct {
+		body     string
+		errno    int
+		expected string
+	}{
+		{body: `text`, errno: 1, expected: "not JSON: invalid character 'e' in literal true (expecting 'r')"},
+		{body: `{"data":"text"}`, errno: 2, expected: "cannot index string with number"},
+		{body: `{"data":[]}`, errno: 3, expected: "doesn't match expect"},
+	}
+
+	for _, tc := range cases {
+		c := setupTest([]string{"receiver", "--expect", ".data[0].temperature.value > 20"})
+
+		h, _ := newReceiverHandler(c, c.Ngsi)
+
+		err := h.match([]byte(tc.body))
+
+		if assert.Error(t, err, tc.body) {
+			ngsiErr := err.(*ngsierr.NgsiError)
+			assert.Equal(t, tc.errno, ngsiErr.ErrNo, tc.body)
+			assert.Equal(t, tc.expected, ngsiErr.Message, tc.body)
+		}
+	}
+}
+
+func TestReceiverRecordError(t *testing.T) {
+	c := setupTest([]string{"receiver", "--store", "notifications.jsonl"})
+
+	h, _ := newReceiverHandler(c, c.Ngsi)
+
+	helper.SetJSONEncodeErr(c.Ngsi, 0)
+	err := h.record("/", map[string]string{}, []byte("text"))
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 1, ngsiErr.ErrNo)
+		assert.Equal(t, "json error", ngsiErr.Message)
+	}
+
+	helper.SetJSONEncodeErr(c.Ngsi, 0)
+	err = h.record("/", map[string]string{}, []byte("{}"))
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 2, ngsiErr.ErrNo)
+		assert.Equal(t, "json error", ngsiErr.Message)
+	}
+}
+
+func TestReceiverWaitServe(t *testing.T) {
+	c := setupTest([]string{"receiver"})
+
+	h, _ := newReceiverHandler(c, c.Ngsi)
+
+	serve := make(chan error, 1)
+	serve <- errors.New("serve error")
+
+	err := h.wait(serve)
+
+	if assert.Error(t, err) {
+		assert.Equal(t, "serve error", err.Error())
+	}
+}
+
+func TestReceiverWaitDeadline(t *testing.T) {
+	c := setupTest([]string{"receiver", "--deadline", "1ms"})
+
+	h, _ := newReceiverHandler(c, c.Ngsi)
+
+	err := h.wait(nil)
+
+	assert.NoError(t, err)
+}
+
+func TestReceiverWaitErrorDeadline(t *testing.T) {
+	c := setupTest([]string{"receiver", "--count", "3", "--deadline", "1ms"})
+
+	h, _ := newReceiverHandler(c, c.Ngsi)
+
+	receiverTestPost(h, `{}`)
+
+	err := h.wait(nil)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 2, ngsiErr.ErrNo)
+		assert.Equal(t, "deadline exceeded: 1 of 3 notifications received", ngsiErr.Message)
+	}
+}
+
+func TestReceiverReplayError(t *testing.T) {
+	cases := []struct {
+		data     string
+		reqRes   []helper.MockHTTPReqRes
+		errno    int
+		expected string
+	}{
+		{data: `{"body":`, errno: 3, expected: "line 1: unexpected EOF"},
+		{data: "\n" + `{"body":{}}`, reqRes: []helper.MockHTTPReqRes{{Err: errors.New("http error")}}, errno: 5, expected: "line 2: http error"},
+		{data: `{"body":{}}`, reqRes: []helper.MockHTTPReqRes{{Res: http.Response{StatusCode: http.StatusBadRequest, Status: "400 Bad Request"}, ResBody: []byte("error")}}, errno: 6, expected: "line 1: 400 Bad Request error"},
+	}
+
+	for _, tc := range cases {
+		c := setupTest([]string{"receiver", "--forward", "http://consumer/"})
+
+		c.Ngsi.Ioutil = &helper.MockIoutilLib{ReadFileData: []byte(tc.data)}
+		mock := helper.NewMockHTTP()
+		mock.ReqRes = tc.reqRes
+		c.Ngsi.HTTP = mock
+
+		h, _ := newReceiverHandler(c, c.Ngsi)
+
+		err := h.replay("notifications.jsonl")
+
+		if assert.Error(t, err, tc.data) {
+			ngsiErr := err.(*ngsierr.NgsiError)
+			assert.Equal(t, tc.errno, ngsiErr.ErrNo, tc.data)
+			assert.Equal(t, tc.expected, ngsiErr.Message, tc.data)
+		}
+	}
+}
+
+func TestReceiverReplayErrorReadFile(t *testing.T) {
+	c := setupTest([]string{"receiver", "--forward", "http://consumer/"})
+
+	c.Ngsi.Ioutil = &helper.MockIoutilLib{ReadFileErr: errors.New("ReadFile error")}
+
+	h, _ := newReceiverHandler(c, c.Ngsi)
+
+	err := h.replay("notifications.jsonl")
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 2, ngsiErr.ErrNo)
+		assert.Equal(t, "ReadFile error", ngsiErr.Message)
+	}
+}
+
+func TestReceiverReplayErrorBody(t *testing.T) {
+	c := setupTest([]string{"receiver", "--forward", "http://consumer/"})
+
+	c.Ngsi.Ioutil = &helper.MockIoutilLib{ReadFileData: []byte(`{"body":"text"}`)}
+
+	h, _ := newReceiverHandler(c, c.Ngsi)
+
+	helper.SetJSONDecodeErr(c.Ngsi, 1)
+	err := h.replay("notifications.jsonl")
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 4, ngsiErr.ErrNo)
+		assert.Equal(t, "line 1: json error", ngsiErr.Message)
+	}
+}
@@ -135,11 +135,16 @@ func regProxyServer(c *ngsicli.Context, ngsi *ngsilib.NGSI, client *ngsilib.Clie
 		mutex:     &sync.Mutex{},
 		startTime: time.Now(),
 	}
+	mutex := &sync.Mutex{}
+
+	refresher := ngsi.StartTokenRefresher(client, mutex)
+	defer refresher.Stop()
+
 	regProxyHandler := &regProxyHandler{
 		ngsi:   ngsi,
 		client: client,
 		http:   ngsi.HTTP,
-		mutex:  &sync.Mutex{},
+		mutex:  mutex,
 		config: config,
 		stat:   stat,
 	}
@@ -63,12 +63,16 @@ func remove(c *ngsicli.Context, ngsi *ngsilib.NGSI, client *ngsilib.Client) erro
 		f = removeLD
 	}
 
+	if _, err := ngsicli.ParallelWorkers(c); err != nil {
+		return ngsierr.New(funcName, 2, err.Error(), err)
+	}
+
 	entities := strings.Split(entityType, ",")
 
 	for _, e := range entities {
 		err := f(c, ngsi, client, e)
 		if err != nil {
-			return ngsierr.New(funcName, 2, err.Error(), err)
+			return ngsierr.New(funcName, 3, err.Error(), err)
 		}
 		ngsi.StdoutFlush()
 	}
@@ -76,12 +80,117 @@ func remove(c *ngsicli.Context, ngsi *ngsilib.NGSI, client *ngsilib.Client) erro
 	return nil
 }
 
+const removeLimit = 100
+
+// removePage is a page of entities to be removed with a client used to remove them
+type removePage struct {
+	client   *ngsilib.Client
+	entities ngsilib.EntitiesRespose
+}
+
+// removeFetchFunc gets a page of entities at offset and returns it with the number of entities matched
+type removeFetchFunc func(offset int) (ngsilib.EntitiesRespose, int, error)
+
+// removeDeleteFunc deletes entities. It is called concurrently with --parallel.
+type removeDeleteFunc func(client *ngsilib.Client, entities ngsilib.EntitiesRespose) error
+
+// removePages fetches pages of entities and deletes them through a pool of --parallel workers.
+// With one worker, the first page is fetched again after each deletion. With more workers, pages
+// are fetched from the last one so that deletions in flight do not shift the offsets of pages
+// not fetched yet. With --dryRun, pages are fetched from the last one as nothing is deleted.
+// Errors returned by fetch and del are returned as they are.
+func removePages(c *ngsicli.Context, ngsi *ngsilib.NGSI, client *ngsilib.Client, fetch removeFetchFunc, del removeDeleteFunc) error {
+	entities, count, err := fetch(0)
+	if err != nil {
+		return err
+	}
+
+	if !c.IsSet("run") && !client.DryRun {
+		fmt.Fprintf(ngsi.StdWriter, "%d entities will be removed. run remove with --run option\n", count)
+		return nil
+	}
+
+	parallel := int(c.Int64("parallel"))
+	fromLast := parallel > 1 || client.DryRun
+
+	fetched := true
+	offset := 0
+	if fromLast && count > 0 {
+		offset = ((count - 1) / removeLimit) * removeLimit
+	}
+
+	produce := func() (interface{}, error) {
+		page := &removePage{}
+		if fromLast {
+			if count == 0 || offset < 0 {
+				return nil, nil
+			}
+			if offset > 0 {
+				e, _, err := fetch(offset)
+				if err != nil {
+					return nil, err
+				}
+				page.entities = e
+			} else {
+				page.entities = entities
+			}
+			offset -= removeLimit
+		} else {
+			if !fetched {
+				e, n, err := fetch(0)
+				if err != nil {
+					return nil, err
+				}
+				entities, count = e, n
+			}
+			if count == 0 {
+				return nil, nil
+			}
+			page.entities = entities
+			fetched = false
+		}
+		page.client = client.Clone()
+		return page, nil
+	}
+
+	work := func(job interface{}) (interface{}, error) {
+		page := job.(*removePage)
+		err := del(page.client, page.entities)
+		if ngsilib.IsDryRun(err) {
+			return page, nil
+		}
+		return page, err
+	}
+
+	total := 0
+
+	consume := func(result interface{}, err error) error {
+		if err != nil {
+			return err
+		}
+		total += len(result.(*removePage).entities)
+		return nil
+	}
+
+	err = ngsilib.Pipeline(parallel, produce, work, consume)
+	if err != nil {
+		return err
+	}
+
+	if client.DryRun {
+		fmt.Fprintf(ngsi.StdWriter, "%d entities would be removed\n", total)
+		return nil
+	}
+
+	fmt.Fprintf(ngsi.StdWriter, "%d\n", total)
+
+	return nil
+}
+
 func removeV2(c *ngsicli.Context, ngsi *ngsilib.NGSI, client *ngsilib.Client, entityType string) error {
 	const funcName = "removeV2"
 
-	limit := 100
-	total := 0
-	for {
+	fetch := func(offset int) (ngsilib.EntitiesRespose, int, error) {
 		client.SetPath("/entities")
 
 		v := url.Values{}
@@ -91,107 +200,90 @@ func removeV2(c *ngsicli.Context, ngsi *ngsilib.NGSI, client *ngsilib.Client, en
 		} else {
 			v.Set("options", "count")
 		}
-		v.Set("limit", fmt.Sprintf("%d", limit))
+		v.Set("limit", fmt.Sprintf("%d", removeLimit))
+		if offset > 0 {
+			v.Set("offset", fmt.Sprintf("%d", offset))
+		}
 		v.Set("attrs", "__NONE")
 		client.SetQuery(&v)
 
 		res, body, err := client.HTTPGet()
 		if err != nil {
-			return ngsierr.New(funcName, 1, err.Error(), err)
+			return nil, 0, ngsierr.New(funcName, 1, err.Error(), err)
 		}
 		if res.StatusCode != http.StatusOK {
-			return ngsierr.New(funcName, 2, fmt.Sprintf("%s %s", res.Status, string(body)), nil)
+			return nil, 0, ngsierr.New(funcName, 2, fmt.Sprintf("%s %s", res.Status, string(body)), nil)
 		}
 
 		count, err := client.ResultsCount(res)
 		if err != nil {
-			return ngsierr.New(funcName, 3, "ResultsCount error", nil)
-		}
-
-		if !c.IsSet("run") {
-			fmt.Fprintf(ngsi.StdWriter, "%d entities will be removed. run remove with --run option\n", count)
-			return nil
-		}
-
-		if count == 0 {
-			break
-		}
-		if count >= limit {
-			total += limit
-		} else {
-			total += count
+			return nil, 0, ngsierr.New(funcName, 3, "ResultsCount error", nil)
 		}
 
 		var entities ngsilib.EntitiesRespose
-		err = ngsilib.JSONUnmarshalDecode(body, &entities, false)
-		if err != nil {
-			return ngsierr.New(funcName, 4, err.Error(), err)
+		if count > 0 && (c.IsSet("run") || client.DryRun) {
+			err = ngsilib.JSONUnmarshalDecode(body, &entities, false)
+			if err != nil {
+				return nil, 0, ngsierr.New(funcName, 4, err.Error(), err)
+			}
 		}
+		return entities, count, nil
+	}
 
-		res, body, err = client.OpUpdate(&entities, "delete", false, false)
+	del := func(client *ngsilib.Client, entities ngsilib.EntitiesRespose) error {
+		res, body, err := client.OpUpdate(&entities, "delete", false, false)
 		if err != nil {
 			return ngsierr.New(funcName, 5, err.Error(), err)
 		}
 		if res.StatusCode != http.StatusNoContent {
 			return ngsierr.New(funcName, 6, fmt.Sprintf("%s %s", res.Status, string(body)), nil)
 		}
-
-		client.RemoveHeader("Content-Type")
+		return nil
 	}
 
-	fmt.Fprintf(ngsi.StdWriter, "%d\n", total)
-
-	return nil
+	return removePages(c, ngsi, client, fetch, del)
 }
 
 func removeLD(c *ngsicli.Context, ngsi *ngsilib.NGSI, client *ngsilib.Client, entityType string) error {
 	const funcName = "removeLD"
 
-	limit := 100
-	total := 0
-	for {
+	fetch := func(offset int) (ngsilib.EntitiesRespose, int, error) {
 		// get count
 		client.SetPath("/entities")
 
 		v := url.Values{}
 		v.Set("type", entityType)
 		v.Set("count", "true")
-		v.Set("limit", fmt.Sprintf("%d", limit))
+		v.Set("limit", fmt.Sprintf("%d", removeLimit))
+		if offset > 0 {
+			v.Set("offset", fmt.Sprintf("%d", offset))
+		}
 		client.SetQuery(&v)
 
 		res, body, err := client.HTTPGet()
 		if err != nil {
-			return ngsierr.New(funcName, 1, err.Error(), err)
+			return nil, 0, ngsierr.New(funcName, 1, err.Error(), err)
 		}
 		if res.StatusCode != http.StatusOK {
-			return ngsierr.New(funcName, 2, fmt.Sprintf("%s %s", res.Status, string(body)), nil)
+			return nil, 0, ngsierr.New(funcName, 2, fmt.Sprintf("%s %s", res.Status, string(body)), nil)
 		}
 
 		count, err := client.ResultsCount(res)
 		if err != nil {
-			return ngsierr.New(funcName, 3, "ResultsCount error", nil)
-		}
-
-		if !c.IsSet("run") {
-			fmt.Fprintf(ngsi.StdWriter, "%d entities will be removed. run remove with --run option\n", count)
-			return nil
-		}
-
-		if count == 0 {
-			break
-		}
-		if count >= limit {
-			total += limit
-		} else {
-			total += count
+			return nil, 0, ngsierr.New(funcName, 3, "ResultsCount error", nil)
 		}
 
 		var entities ngsilib.EntitiesRespose
-		err = ngsilib.JSONUnmarshalDecode(body, &entities, false)
-		if err != nil {
-			return ngsierr.New(funcName, 4, err.Error(), err)
+		if count > 0 && (c.IsSet("run") || client.DryRun) {
+			err = ngsilib.JSONUnmarshalDecode(body, &entities, false)
+			if err != nil {
+				return nil, 0, ngsierr.New(funcName, 4, err.Error(), err)
+			}
 		}
+		return entities, count, nil
+	}
 
+	del := func(client *ngsilib.Client, entities ngsilib.EntitiesRespose) error {
 		data := []string{}
 		for _, e := range entities {
 			data = append(data, e["id"].(string))
@@ -202,74 +294,63 @@ func removeLD(c *ngsicli.Context, ngsi *ngsilib.NGSI, client *ngsilib.Client, en
 		}
 
 		client.SetPath("/entityOperations/delete")
-		v = url.Values{}
+		v := url.Values{}
 		client.SetQuery(&v)
 		client.SetContentType()
 
-		res, body, err = client.HTTPPost(b)
+		res, body, err := client.HTTPPost(b)
 		if err != nil {
 			return ngsierr.New(funcName, 6, err.Error(), err)
 		}
 		if res.StatusCode != http.StatusNoContent {
 			return ngsierr.New(funcName, 7, fmt.Sprintf("%s %s", res.Status, string(body)), nil)
 		}
-
-		client.RemoveHeader("Content-Type")
+		return nil
 	}
 
-	fmt.Fprintf(ngsi.StdWriter, "%d\n", total)
-
-	return nil
+	return removePages(c, ngsi, client, fetch, del)
 }
 
 func removeV1(c *ngsicli.Context, ngsi *ngsilib.NGSI, client *ngsilib.Client, entityType string) error {
 	const funcName = "removeV1"
 
-	limit := 100
-	total := 0
-	for {
+	fetch := func(offset int) (ngsilib.EntitiesRespose, int, error) {
 		client.SetPath("/entities")
 
 		v := url.Values{}
 		v.Set("type", entityType)
 		v.Set("options", "count")
-		v.Set("limit", fmt.Sprintf("%d", limit))
+		v.Set("limit", fmt.Sprintf("%d", removeLimit))
+		if offset > 0 {
+			v.Set("offset", fmt.Sprintf("%d", offset))
+		}
 		v.Set("attrs", "__NONE")
 		client.SetQuery(&v)
 
 		res, body, err := client.HTTPGet()
 		if err != nil {
-			return ngsierr.New(funcName, 1, err.Error(), err)
+			return nil, 0, ngsierr.New(funcName, 1, err.Error(), err)
 		}
 		if res.StatusCode != http.StatusOK {
-			return ngsierr.New(funcName, 2, fmt.Sprintf("%s %s", res.Status, string(body)), nil)
+			return nil, 0, ngsierr.New(funcName, 2, fmt.Sprintf("%s %s", res.Status, string(body)), nil)
 		}
 
 		count, err := client.ResultsCount(res)
 		if err != nil {
-			return ngsierr.New(funcName, 3, "ResultsCount error", nil)
-		}
-
-		if !c.IsSet("run") {
-			fmt.Fprintf(ngsi.StdWriter, "%d entities will be removed. run remove with --run option\n", count)
-			return nil
-		}
-
-		if count == 0 {
-			break
-		}
-		if count >= limit {
-			total += limit
-		} else {
-			total += count
+			return nil, 0, ngsierr.New(funcName, 3, "ResultsCount error", nil)
 		}
 
 		var entities ngsilib.EntitiesRespose
-		err = ngsilib.JSONUnmarshalDecode(body, &entities, false)
-		if err != nil {
-			return ngsierr.New(funcName, 4, err.Error(), err)
+		if count > 0 && (c.IsSet("run") || client.DryRun) {
+			err = ngsilib.JSONUnmarshalDecode(body, &entities, false)
+			if err != nil {
+				return nil, 0, ngsierr.New(funcName, 4, err.Error(), err)
+			}
 		}
+		return entities, count, nil
+	}
 
+	del := func(client *ngsilib.Client, entities ngsilib.EntitiesRespose) error {
 		var req ngsilib.V1Request
 		req.UpdateAction = "DELETE"
 		for _, e := range entities {
@@ -283,12 +364,12 @@ func removeV1(c *ngsicli.Context, ngsi *ngsilib.NGSI, client *ngsilib.Client, en
 		client.SetPath("/v1/updateContext")
 		client.SetContentJSON()
 
-		body, err = ngsilib.JSONMarshal(req)
+		body, err := ngsilib.JSONMarshal(req)
 		if err != nil {
 			return ngsierr.New(funcName, 5, err.Error(), err)
 		}
 
-		res, body, err = client.HTTPPost(body)
+		res, body, err := client.HTTPPost(body)
 		if err != nil {
 			return ngsierr.New(funcName, 6, err.Error(), err)
 		}
@@ -311,11 +392,8 @@ func removeV1(c *ngsicli.Context, ngsi *ngsilib.NGSI, client *ngsilib.Client, en
 				return ngsierr.New(funcName, 10, fmt.Sprintf("error %s %s", e.StatusCode.Code, e.StatusCode.ReasonPhrase), err)
 			}
 		}
-
-		client.RemoveHeader("Content-Type")
+		return nil
 	}
 
-	fmt.Fprintf(ngsi.StdWriter, "%d\n", total)
-
-	return nil
+	return removePages(c, ngsi, client, fetch, del)
 }
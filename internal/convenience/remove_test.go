@@ -31,7 +31,11 @@ package convenience
 
 import (
 	"errors"
+	"fmt"
 	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
 	"testing"
 
 	"github.com/lets-fiware/ngsi-go/internal/assert"
@@ -205,11 +209,172 @@ func TestRemoveErrorRemove(t *testing.T) {
 
 	err := remove(c, c.Ngsi, c.Client)
 
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 3, ngsiErr.ErrNo)
+		assert.Equal(t, "http error", ngsiErr.Message)
+	}
+}
+
+func TestRemoveErrorParallel(t *testing.T) {
+	c := setupTest([]string{"rm", "--host", "orion", "--type", "Thing", "--parallel", "100"})
+
+	err := remove(c, c.Ngsi, c.Client)
+
 	if assert.Error(t, err) {
 		ngsiErr := err.(*ngsierr.NgsiError)
 		assert.Equal(t, 2, ngsiErr.ErrNo)
+		assert.Equal(t, "parallel error: 100 (1-64)", ngsiErr.Message)
+	}
+}
+
+func TestRemoveV2Parallel(t *testing.T) {
+	c := setupTest([]string{"rm", "--host", "orion", "--type", "Thing", "--parallel", "3", "--run"})
+
+	mock := &removeMockHTTP{count: 250}
+	c.Client.HTTP = mock
+
+	err := removeV2(c, c.Ngsi, c.Client, "Thing")
+
+	if assert.NoError(t, err) {
+		actual := helper.GetStdoutString(c)
+		expected := "3\n"
+		assert.Equal(t, expected, actual)
+		assert.Equal(t, []string{"0", "200", "100"}, mock.offsets)
+		assert.Equal(t, 3, mock.deleted)
+	}
+}
+
+func TestRemoveLDParallel(t *testing.T) {
+	c := setupTest([]string{"rm", "--host", "orion-ld", "--type", "Thing", "--parallel", "2", "--run"})
+
+	mock := &removeMockHTTP{count: 100}
+	c.Client.HTTP = mock
+
+	err := removeLD(c, c.Ngsi, c.Client, "Thing")
+
+	if assert.NoError(t, err) {
+		actual := helper.GetStdoutString(c)
+		expected := "1\n"
+		assert.Equal(t, expected, actual)
+		assert.Equal(t, []string{"0"}, mock.offsets)
+		assert.Equal(t, 1, mock.deleted)
+	}
+}
+
+func TestRemoveV2DryRun(t *testing.T) {
+	c := setupTest([]string{"--dryRun", "rm", "--host", "orion", "--type", "Thing"})
+
+	mock := &removeMockHTTP{count: 250}
+	c.Client.HTTP = mock
+
+	err := removeV2(c, c.Ngsi, c.Client, "Thing")
+
+	if assert.NoError(t, err) {
+		actual := helper.GetStdoutString(c)
+		expected := "POST https://orion/v2/op/update?attrs=__NONE&limit=100&offset=200&options=count&type=Thing\n" +
+			"Accept: */*\nContent-Type: application/json\n\n" +
+			"{\"actionType\":\"delete\",\"entities\":[{\"id\":\"urn:ngsi-ld:Thing:200\",\"type\":\"Thing\"}]}\n\n" +
+			"POST https://orion/v2/op/update?attrs=__NONE&limit=100&offset=100&options=count&type=Thing\n" +
+			"Accept: */*\nContent-Type: application/json\n\n" +
+			"{\"actionType\":\"delete\",\"entities\":[{\"id\":\"urn:ngsi-ld:Thing:100\",\"type\":\"Thing\"}]}\n\n" +
+			"POST https://orion/v2/op/update?attrs=__NONE&limit=100&offset=100&options=count&type=Thing\n" +
+			"Accept: */*\nContent-Type: application/json\n\n" +
+			"{\"actionType\":\"delete\",\"entities\":[{\"id\":\"urn:ngsi-ld:Thing:0\",\"type\":\"Thing\"}]}\n\n" +
+			"3 entities would be removed\n"
+		assert.Equal(t, expected, actual)
+		assert.Equal(t, []string{"0", "200", "100"}, mock.offsets)
+		assert.Equal(t, 0, mock.deleted)
+	}
+}
+
+func TestRemoveV2ParallelCountZero(t *testing.T) {
+	c := setupTest([]string{"rm", "--host", "orion", "--type", "Thing", "--parallel", "3", "--run"})
+
+	mock := &removeMockHTTP{count: 0}
+	c.Client.HTTP = mock
+
+	err := removeV2(c, c.Ngsi, c.Client, "Thing")
+
+	if assert.NoError(t, err) {
+		actual := helper.GetStdoutString(c)
+		expected := "0\n"
+		assert.Equal(t, expected, actual)
+		assert.Equal(t, 0, mock.deleted)
+	}
+}
+
+func TestRemoveV2ParallelErrorFetch(t *testing.T) {
+	c := setupTest([]string{"rm", "--host", "orion", "--type", "Thing", "--parallel", "3", "--run"})
+
+	mock := &removeMockHTTP{count: 250, getErr: "200"}
+	c.Client.HTTP = mock
+
+	err := removeV2(c, c.Ngsi, c.Client, "Thing")
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 1, ngsiErr.ErrNo)
 		assert.Equal(t, "http error", ngsiErr.Message)
+		assert.Equal(t, 0, mock.deleted)
+	}
+}
+
+func TestRemoveV2ParallelErrorDelete(t *testing.T) {
+	c := setupTest([]string{"rm", "--host", "orion", "--type", "Thing", "--parallel", "3", "--run"})
+
+	mock := &removeMockHTTP{count: 250, deleteStatus: http.StatusBadRequest}
+	c.Client.HTTP = mock
+
+	err := removeV2(c, c.Ngsi, c.Client, "Thing")
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 6, ngsiErr.ErrNo)
+		assert.Equal(t, "400 Bad Request ", ngsiErr.Message)
+		assert.Equal(t, "", helper.GetStdoutString(c))
+	}
+}
+
+// removeMockHTTP serves pages of entities for GET and accepts deletions for POST concurrently
+type removeMockHTTP struct {
+	mutex        sync.Mutex
+	count        int
+	offsets      []string
+	deleted      int
+	getErr       string
+	deleteStatus int
+}
+
+func (h *removeMockHTTP) Request(method string, u *url.URL, headers map[string]string, body interface{}) (*http.Response, []byte, error) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	res := &http.Response{Header: http.Header{}}
+
+	if method == http.MethodGet {
+		offset := u.Query().Get("offset")
+		if offset == "" {
+			offset = "0"
+		}
+		if offset == h.getErr {
+			return nil, nil, errors.New("http error")
+		}
+		h.offsets = append(h.offsets, offset)
+		res.StatusCode = http.StatusOK
+		res.Header.Set("Fiware-Total-Count", strconv.Itoa(h.count))
+		res.Header.Set("Ngsild-Results-Count", strconv.Itoa(h.count))
+		return res, []byte(`[{"id":"urn:ngsi-ld:Thing:` + offset + `","type":"Thing"}]`), nil
+	}
+
+	if h.deleteStatus != 0 {
+		res.StatusCode = h.deleteStatus
+		res.Status = fmt.Sprintf("%d %s", h.deleteStatus, http.StatusText(h.deleteStatus))
+		return res, nil, nil
 	}
+	h.deleted++
+	res.StatusCode = http.StatusNoContent
+	return res, nil, nil
 }
 
 func TestRemoveV2TestRun(t *testing.T) {
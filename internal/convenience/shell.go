@@ -0,0 +1,595 @@
+/*
+MIT License
+
+Copyright (c) 2020-2024 Kazuhito Suda
+
+This file is part of NGSI Go
+
+https://github.com/lets-fiware/ngsi-go
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+
+*/
+
+package convenience
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/lets-fiware/ngsi-go/internal/ngsicli"
+	"github.com/lets-fiware/ngsi-go/internal/ngsierr"
+	"github.com/lets-fiware/ngsi-go/internal/ngsilib"
+)
+
+const (
+	shellHistoryFile = "ngsi-go-history"
+	shellHistoryMax  = 1000
+	shellLimit       = 100
+)
+
+var shellBuiltins = []string{"exit", "help", "history", "host", "path", "quit", "service"}
+
+// shellSecretFlags are the flags whose values are replaced with *** in the command history
+var shellSecretFlags = []string{"--password", "--oAuthToken", "--oAuthToken2", "--clientSecret", "--token", "--apikey", "--data", "-d"}
+
+// shellSession keeps the current host, tenant and scope of an interactive shell. NGSI loaded when
+// the shell started, including the token cache, is shared by all the command lines of the session.
+type shellSession struct {
+	c           *ngsicli.Context
+	ngsi        *ngsilib.NGSI
+	host        string
+	service     string
+	path        string
+	history     []string
+	historyFile string
+}
+
+func shell(c *ngsicli.Context, ngsi *ngsilib.NGSI, client *ngsilib.Client) error {
+	const funcName = "shell"
+
+	s := &shellSession{c: c, ngsi: ngsi, host: c.String("host"), service: c.String("service"), path: c.String("path")}
+
+	if s.host != "" {
+		if _, err := ngsi.GetServerInfo(s.host, false); err != nil {
+			return ngsierr.New(funcName, 1, err.Error(), err)
+		}
+	}
+
+	if err := s.loadHistory(c.String("historyFile")); err != nil {
+		return ngsierr.New(funcName, 2, err.Error(), err)
+	}
+
+	r, ok := ngsi.StdReader.(io.RuneReader)
+	if !ok {
+		r = bufio.NewReader(ngsi.StdReader)
+	}
+
+	readLine := func() (string, error) { return shellReadLine(r) }
+	if ngsi.TermLib.IsTerminal(os.Stdin.Fd()) {
+		stdout := ngsi.StdWriter
+		w := &shellWriter{w: stdout, newline: true}
+		ngsi.StdWriter = bufio.NewWriter(w)
+		defer func() { ngsi.StdWriter = stdout }()
+
+		e := &lineEditor{ngsi: ngsi, reader: r, complete: s.complete}
+		readLine = func() (string, error) {
+			if !w.newline {
+				fmt.Fprintln(ngsi.StdWriter)
+			}
+			return e.readLine(s.prompt(), s.history)
+		}
+	}
+
+	for {
+		line, err := readLine()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return ngsierr.New(funcName, 3, err.Error(), err)
+		}
+
+		words, err := shellSplit(line)
+		if err != nil {
+			ngsi.Logging(ngsilib.LogErr, ngsierr.Message(err)+"\n")
+			continue
+		}
+		if len(words) == 0 {
+			continue
+		}
+		s.addHistory(shellRedact(line, words))
+
+		if s.execute(words) {
+			break
+		}
+	}
+
+	return nil
+}
+
+// shellWriter tells whether the output of a command ends with a newline, so that the prompt is
+// not drawn over the last line of the output.
+type shellWriter struct {
+	w       io.Writer
+	newline bool
+}
+
+func (w *shellWriter) Write(p []byte) (int, error) {
+	n, err := w.w.Write(p)
+	if n > 0 {
+		w.newline = p[n-1] == '\n'
+	}
+	if b, ok := w.w.(*bufio.Writer); ok {
+		_ = b.Flush()
+	}
+	return n, err
+}
+
+// shellReadLine reads a line when stdin is not a terminal, e.g. when commands are piped to the shell.
+func shellReadLine(r io.RuneReader) (string, error) {
+	var line []rune
+	for {
+		ch, _, err := r.ReadRune()
+		if err == io.EOF && len(line) > 0 {
+			return string(line), nil
+		}
+		if err != nil {
+			return "", err
+		}
+		if ch == '\n' {
+			return strings.TrimSuffix(string(line), "\r"), nil
+		}
+		line = append(line, ch)
+	}
+}
+
+func (s *shellSession) prompt() string {
+	var current []string
+	for _, v := range []string{s.host, s.service, s.path} {
+		if v != "" {
+			current = append(current, v)
+		}
+	}
+	if len(current) == 0 {
+		return "ngsi> "
+	}
+	return "ngsi [" + strings.Join(current, " ") + "]> "
+}
+
+// execute runs a command line. It returns true when the shell is to be terminated.
+func (s *shellSession) execute(words []string) bool {
+	ngsi := s.ngsi
+
+	switch {
+	case words[0] == "exit" || words[0] == "quit":
+		return true
+	case words[0] == "host":
+		if len(words) > 1 {
+			if _, err := ngsi.GetServerInfo(words[1], false); err != nil {
+				ngsi.Logging(ngsilib.LogErr, ngsierr.Message(err)+"\n")
+				return false
+			}
+			s.host = words[1]
+		}
+		fmt.Fprintln(ngsi.StdWriter, s.host)
+	case words[0] == "service":
+		s.service = shellSetValue(s.service, words)
+		fmt.Fprintln(ngsi.StdWriter, s.service)
+	case words[0] == "path":
+		s.path = shellSetValue(s.path, words)
+		fmt.Fprintln(ngsi.StdWriter, s.path)
+	case words[0] == "history":
+		for i, line := range s.history {
+			fmt.Fprintf(ngsi.StdWriter, "%5d  %s\n", i+1, line)
+		}
+	case words[0] == "help" && len(words) == 1:
+		s.run([]string{"--help"})
+		fmt.Fprint(ngsi.StdWriter, shellHelp)
+	case words[0] == "shell":
+		ngsi.Logging(ngsilib.LogErr, "shell cannot be run in shell\n")
+	default:
+		s.run(s.args(words))
+	}
+	ngsi.StdoutFlush()
+
+	return false
+}
+
+const shellHelp = `
+SHELL COMMANDS:
+   host [NAME]       print or change the current host
+   service [NAME|-]  print, change or clear the current FIWARE Service
+   path [PATH|-]     print, change or clear the current FIWARE ServicePath
+   history           print the command history
+   exit, quit        exit the shell
+`
+
+func shellSetValue(value string, words []string) string {
+	if len(words) < 2 {
+		return value
+	}
+	if words[1] == "-" {
+		return ""
+	}
+	return words[1]
+}
+
+// args adds the current host, tenant and scope to a command line when the command takes them and
+// they are not specified on it.
+func (s *shellSession) args(words []string) []string {
+	flags, set := s.c.App.CommandFlags(words)
+
+	args := append([]string{}, words...)
+	for _, f := range flags {
+		var value string
+		switch f.FlagName() {
+		case "host":
+			value = s.host
+		case "service":
+			value = s.service
+		case "path":
+			value = s.path
+		}
+		if value != "" && !set[f.FlagName()] {
+			args = append(args, "--"+f.FlagName(), value)
+		}
+	}
+	return args
+}
+
+func (s *shellSession) run(args []string) {
+	err := s.c.App.RunSession(s.ngsi, append([]string{"ngsi"}, args...))
+	if err != nil {
+		s.ngsi.StdoutFlush()
+		s.ngsi.Logging(ngsilib.LogErr, strings.TrimRight(ngsierr.Message(err), "\n")+"\n")
+	}
+}
+
+// loadHistory loads the command history. It is kept in the config directory unless --historyFile is specified.
+func (s *shellSession) loadHistory(file string) error {
+	const funcName = "loadHistory"
+
+	if file == "" {
+		config := *s.ngsi.ConfigFile.FileName()
+		if config == "" {
+			return nil
+		}
+		file = filepath.Join(filepath.Dir(config), shellHistoryFile)
+	}
+	s.historyFile = file
+
+	b, err := s.ngsi.Ioutil.ReadFile(file)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return ngsierr.New(funcName, 1, err.Error(), err)
+	}
+
+	for _, line := range strings.Split(string(b), "\n") {
+		if line != "" {
+			s.history = append(s.history, line)
+		}
+	}
+	if len(s.history) > shellHistoryMax {
+		s.history = s.history[len(s.history)-shellHistoryMax:]
+	}
+
+	return nil
+}
+
+func (s *shellSession) addHistory(line string) {
+	if len(s.history) > 0 && s.history[len(s.history)-1] == line {
+		return
+	}
+	s.history = append(s.history, line)
+	if len(s.history) > shellHistoryMax {
+		s.history = s.history[1:]
+	}
+
+	if s.historyFile != "" {
+		if err := s.ngsi.Ioutil.AppendFile(s.historyFile, []byte(line+"\n"), 0600); err != nil {
+			s.ngsi.Logging(ngsilib.LogWarn, ngsierr.Message(err)+"\n")
+		}
+	}
+}
+
+// complete returns the candidates for the last word of text and the word itself
+func (s *shellSession) complete(text string) ([]string, string) {
+	words, err := shellSplit(text)
+	if err != nil {
+		return nil, ""
+	}
+
+	word := ""
+	if len(words) > 0 && !strings.HasSuffix(text, " ") {
+		word = words[len(words)-1]
+		words = words[:len(words)-1]
+	}
+
+	if len(words) == 1 && words[0] == "host" {
+		return s.hosts(word), word
+	}
+
+	candidates, f := s.c.App.Complete(words, word)
+	if len(words) == 0 {
+		for _, b := range shellBuiltins {
+			if strings.HasPrefix(b, word) {
+				candidates = append(candidates, b)
+			}
+		}
+		sort.Strings(candidates)
+	}
+
+	if f != nil && len(candidates) == 0 {
+		usage := strings.ToLower(f.FlagUsage())
+		switch {
+		case f.FlagName() == "host" || f.FlagName() == "host2":
+			candidates = s.hosts(word)
+		case f.FlagName() == "type" && strings.HasPrefix(usage, "entity type"):
+			candidates = s.entityTypes(words, word)
+		case f.FlagName() == "id" && strings.HasPrefix(usage, "entity id"):
+			candidates = s.entityIDs(words, word)
+		}
+	}
+
+	return candidates, word
+}
+
+func (s *shellSession) hosts(word string) []string {
+	var candidates []string
+	for host := range *s.ngsi.AllServersList() {
+		if strings.HasPrefix(host, word) {
+			candidates = append(candidates, host)
+		}
+	}
+	sort.Strings(candidates)
+	return candidates
+}
+
+// brokerClient returns a client for the broker of a command line, or nil when it is not a broker.
+func (s *shellSession) brokerClient(words []string) *ngsilib.Client {
+	host := shellFlagValue(words, "--host", "-h", s.host)
+	service := shellFlagValue(words, "--service", "-s", s.service)
+	path := shellFlagValue(words, "--path", "-p", s.path)
+	if host == "" {
+		return nil
+	}
+
+	client, err := s.ngsi.NewClient(host, &ngsilib.CmdFlags{Tenant: &service, Scope: &path}, false, false)
+	if err != nil || !(client.IsNgsiV2() || client.IsNgsiLd()) {
+		return nil
+	}
+	return client
+}
+
+func shellFlagValue(words []string, name, alias, value string) string {
+	for i := 0; i < len(words)-1; i++ {
+		if words[i] == name || words[i] == alias {
+			value = words[i+1]
+		}
+	}
+	return value
+}
+
+// entityTypes returns the entity types in the broker which start with word
+func (s *shellSession) entityTypes(words []string, word string) []string {
+	client := s.brokerClient(words)
+	if client == nil {
+		return nil
+	}
+
+	client.SetPath("/types")
+	if client.IsNgsiV2() {
+		v := url.Values{}
+		v.Set("options", "values")
+		v.Set("limit", fmt.Sprint(shellLimit))
+		client.SetQuery(&v)
+	}
+
+	body := s.get(client)
+	if body == nil {
+		return nil
+	}
+
+	var types []string
+	if client.IsNgsiV2() {
+		if err := ngsilib.JSONUnmarshal(body, &types); err != nil {
+			return nil
+		}
+	} else {
+		var typeList struct {
+			TypeList []string `json:"typeList"`
+		}
+		if err := ngsilib.JSONUnmarshal(body, &typeList); err != nil {
+			return nil
+		}
+		types = typeList.TypeList
+	}
+
+	return shellCandidates(types, word)
+}
+
+// entityIDs returns the entity ids in the broker which start with word
+func (s *shellSession) entityIDs(words []string, word string) []string {
+	client := s.brokerClient(words)
+	if client == nil {
+		return nil
+	}
+
+	client.SetPath("/entities")
+	v := url.Values{}
+	v.Set("limit", fmt.Sprint(shellLimit))
+	if t := shellFlagValue(words, "--type", "-t", ""); t != "" {
+		v.Set("type", t)
+	}
+	if word != "" || client.IsNgsiLd() {
+		v.Set("idPattern", "^"+regexp.QuoteMeta(word))
+	}
+	if client.IsNgsiV2() {
+		v.Set("attrs", "id")
+	}
+	client.SetQuery(&v)
+
+	body := s.get(client)
+	if body == nil {
+		return nil
+	}
+
+	var entities []struct {
+		ID string `json:"id"`
+	}
+	if err := ngsilib.JSONUnmarshal(body, &entities); err != nil {
+		return nil
+	}
+
+	var ids []string
+	for _, e := range entities {
+		ids = append(ids, e.ID)
+	}
+	return shellCandidates(ids, word)
+}
+
+// get sends a request for candidates. Requests are sent even when the previous command line had --dryRun.
+func (s *shellSession) get(client *ngsilib.Client) []byte {
+	dryRun := s.ngsi.DryRun
+	s.ngsi.DryRun = false
+	defer func() { s.ngsi.DryRun = dryRun }()
+
+	res, body, err := client.HTTPGet()
+	if err != nil || res.StatusCode != http.StatusOK {
+		return nil
+	}
+	return body
+}
+
+func shellCandidates(values []string, word string) []string {
+	var candidates []string
+	for _, v := range values {
+		if strings.HasPrefix(v, word) {
+			candidates = append(candidates, v)
+		}
+	}
+	sort.Strings(candidates)
+	return candidates
+}
+
+// shellSplit splits a command line into words. Single quotes, double quotes and backslash escapes
+// are handled like a POSIX shell, so that JSON data can be written on a command line.
+func shellSplit(line string) ([]string, error) {
+	const funcName = "shellSplit"
+
+	var words []string
+	var word strings.Builder
+	inWord := false
+	var quote rune
+	escape := false
+
+	for _, ch := range line {
+		switch {
+		case escape:
+			if quote == '"' && ch != '"' && ch != '\\' {
+				word.WriteRune('\\')
+			}
+			word.WriteRune(ch)
+			escape = false
+		case quote == '\'':
+			if ch == '\'' {
+				quote = 0
+			} else {
+				word.WriteRune(ch)
+			}
+		case quote == '"':
+			switch ch {
+			case '"':
+				quote = 0
+			case '\\':
+				escape = true
+			default:
+				word.WriteRune(ch)
+			}
+		case ch == '\\':
+			escape = true
+			inWord = true
+		case ch == '\'' || ch == '"':
+			quote = ch
+			inWord = true
+		case ch == ' ' || ch == '\t':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(ch)
+			inWord = true
+		}
+	}
+
+	if quote != 0 || escape {
+		return nil, ngsierr.New(funcName, 1, "unterminated quote", nil)
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+
+	return words, nil
+}
+
+// shellRedact returns line with the values of shellSecretFlags replaced with ***. A line without them
+// is returned as it is. Otherwise, the line is made from words again.
+func shellRedact(line string, words []string) string {
+	redacted := false
+	out := make([]string, len(words))
+	for i, w := range words {
+		out[i] = shellQuote(w)
+		if i > 0 && ngsilib.Contains(shellSecretFlags, words[i-1]) {
+			out[i] = "***"
+			redacted = true
+		}
+		for _, f := range shellSecretFlags {
+			if strings.HasPrefix(w, f+"=") {
+				out[i] = f + "=***"
+				redacted = true
+			}
+		}
+	}
+	if !redacted {
+		return line
+	}
+	return strings.Join(out, " ")
+}
+
+// shellQuote quotes a word so that shellSplit reads it as it is
+func shellQuote(w string) string {
+	if w != "" && !strings.ContainsAny(w, " \t'\"\\") {
+		return w
+	}
+	return "'" + strings.ReplaceAll(w, "'", `'\''`) + "'"
+}
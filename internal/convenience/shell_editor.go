@@ -0,0 +1,302 @@
+/*
+MIT License
+
+Copyright (c) 2020-2024 Kazuhito Suda
+
+This file is part of NGSI Go
+
+https://github.com/lets-fiware/ngsi-go
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+
+*/
+
+package convenience
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/lets-fiware/ngsi-go/internal/ngsierr"
+	"github.com/lets-fiware/ngsi-go/internal/ngsilib"
+)
+
+const (
+	keyCtrlA     = 0x01
+	keyCtrlB     = 0x02
+	keyCtrlC     = 0x03
+	keyCtrlD     = 0x04
+	keyCtrlE     = 0x05
+	keyCtrlF     = 0x06
+	keyCtrlH     = 0x08
+	keyTab       = 0x09
+	keyLF        = 0x0a
+	keyCtrlK     = 0x0b
+	keyCR        = 0x0d
+	keyCtrlN     = 0x0e
+	keyCtrlP     = 0x10
+	keyCtrlU     = 0x15
+	keyCtrlW     = 0x17
+	keyEscape    = 0x1b
+	keyBackspace = 0x7f
+)
+
+// lineEditor reads a line from a terminal in raw mode. It supports emacs-like key bindings,
+// the command history and tab completion.
+type lineEditor struct {
+	ngsi     *ngsilib.NGSI
+	reader   io.RuneReader
+	complete func(text string) ([]string, string)
+
+	prompt string
+	buf    []rune
+	pos    int
+}
+
+func (e *lineEditor) readLine(prompt string, history []string) (string, error) {
+	const funcName = "readLine"
+
+	restore, err := e.ngsi.TermLib.MakeRaw(os.Stdin.Fd())
+	if err != nil {
+		return "", ngsierr.New(funcName, 1, err.Error(), err)
+	}
+	defer restore()
+
+	e.prompt = prompt
+	e.buf = nil
+	e.pos = 0
+
+	index := len(history)
+	var saved []rune
+
+	e.draw()
+
+	for {
+		ch, _, err := e.reader.ReadRune()
+		if err != nil {
+			e.newline()
+			if err == io.EOF && len(e.buf) > 0 {
+				return string(e.buf), nil
+			}
+			return "", err
+		}
+
+		switch ch {
+		case keyCR, keyLF:
+			e.newline()
+			return string(e.buf), nil
+		case keyCtrlC:
+			fmt.Fprint(e.ngsi.StdWriter, "^C")
+			e.newline()
+			e.buf, e.pos, index = nil, 0, len(history)
+		case keyCtrlD:
+			if len(e.buf) == 0 {
+				e.newline()
+				return "", io.EOF
+			}
+			e.delete()
+		case keyCtrlA:
+			e.pos = 0
+		case keyCtrlE:
+			e.pos = len(e.buf)
+		case keyCtrlB:
+			e.left()
+		case keyCtrlF:
+			e.right()
+		case keyCtrlH, keyBackspace:
+			if e.pos > 0 {
+				e.pos--
+				e.delete()
+			}
+		case keyCtrlK:
+			e.buf = e.buf[:e.pos]
+		case keyCtrlU:
+			e.buf = append([]rune{}, e.buf[e.pos:]...)
+			e.pos = 0
+		case keyCtrlW:
+			e.deleteWord()
+		case keyCtrlP:
+			index, saved = e.history(history, index, index-1, saved)
+		case keyCtrlN:
+			index, saved = e.history(history, index, index+1, saved)
+		case keyTab:
+			e.tab()
+		case keyEscape:
+			switch e.escape() {
+			case 'A':
+				index, saved = e.history(history, index, index-1, saved)
+			case 'B':
+				index, saved = e.history(history, index, index+1, saved)
+			case 'C':
+				e.right()
+			case 'D':
+				e.left()
+			case 'H':
+				e.pos = 0
+			case 'F':
+				e.pos = len(e.buf)
+			case '~':
+				e.delete()
+			}
+		default:
+			if unicode.IsPrint(ch) {
+				e.insert([]rune{ch})
+			}
+		}
+
+		e.draw()
+	}
+}
+
+// escape reads an escape sequence and returns a key of it: 'A' to 'D' for the arrow keys, 'H' and 'F'
+// for Home and End, and '~' for Delete. Zero is returned for other sequences.
+func (e *lineEditor) escape() rune {
+	ch, _, err := e.reader.ReadRune()
+	if err != nil || (ch != '[' && ch != 'O') {
+		return 0
+	}
+
+	var param []rune
+	for {
+		ch, _, err = e.reader.ReadRune()
+		if err != nil {
+			return 0
+		}
+		if ch < 0x30 || ch > 0x3f {
+			break
+		}
+		param = append(param, ch)
+	}
+	if ch != '~' {
+		return ch
+	}
+
+	switch string(param) {
+	case "1", "7":
+		return 'H'
+	case "4", "8":
+		return 'F'
+	case "3":
+		return '~'
+	}
+	return 0
+}
+
+func (e *lineEditor) draw() {
+	fmt.Fprintf(e.ngsi.StdWriter, "\r%s%s\x1b[K", e.prompt, string(e.buf))
+	if n := len(e.buf) - e.pos; n > 0 {
+		fmt.Fprintf(e.ngsi.StdWriter, "\x1b[%dD", n)
+	}
+	e.ngsi.StdoutFlush()
+}
+
+func (e *lineEditor) newline() {
+	fmt.Fprint(e.ngsi.StdWriter, "\r\n")
+	e.ngsi.StdoutFlush()
+}
+
+func (e *lineEditor) insert(s []rune) {
+	buf := append(append(append([]rune{}, e.buf[:e.pos]...), s...), e.buf[e.pos:]...)
+	e.buf = buf
+	e.pos += len(s)
+}
+
+func (e *lineEditor) delete() {
+	if e.pos < len(e.buf) {
+		e.buf = append(e.buf[:e.pos], e.buf[e.pos+1:]...)
+	}
+}
+
+func (e *lineEditor) deleteWord() {
+	i := e.pos
+	for i > 0 && e.buf[i-1] == ' ' {
+		i--
+	}
+	for i > 0 && e.buf[i-1] != ' ' {
+		i--
+	}
+	e.buf = append(e.buf[:i], e.buf[e.pos:]...)
+	e.pos = i
+}
+
+func (e *lineEditor) left() {
+	if e.pos > 0 {
+		e.pos--
+	}
+}
+
+func (e *lineEditor) right() {
+	if e.pos < len(e.buf) {
+		e.pos++
+	}
+}
+
+// history replaces the line with an entry of the command history. The line being edited is saved
+// while going through the history, and is restored when coming back.
+func (e *lineEditor) history(history []string, index, next int, saved []rune) (int, []rune) {
+	if next < 0 || next > len(history) {
+		return index, saved
+	}
+	if index == len(history) {
+		saved = e.buf
+	}
+	if next == len(history) {
+		e.buf = saved
+	} else {
+		e.buf = []rune(history[next])
+	}
+	e.pos = len(e.buf)
+	return next, saved
+}
+
+// tab completes the word before the cursor. When there are candidates which do not share a longer
+// prefix, they are listed.
+func (e *lineEditor) tab() {
+	candidates, word := e.complete(string(e.buf[:e.pos]))
+
+	switch len(candidates) {
+	case 0:
+		return
+	case 1:
+		e.insert([]rune(strings.TrimPrefix(candidates[0], word) + " "))
+		return
+	}
+
+	prefix := candidates[0]
+	for _, c := range candidates[1:] {
+		for !strings.HasPrefix(c, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+	for !utf8.ValidString(prefix) {
+		prefix = prefix[:len(prefix)-1]
+	}
+	if len(prefix) > len(word) {
+		e.insert([]rune(strings.TrimPrefix(prefix, word)))
+		return
+	}
+
+	e.newline()
+	fmt.Fprint(e.ngsi.StdWriter, strings.Join(candidates, "  "))
+	e.newline()
+}
@@ -0,0 +1,263 @@
+/*
+MIT License
+
+Copyright (c) 2020-2024 Kazuhito Suda
+
+This file is part of NGSI Go
+
+https://github.com/lets-fiware/ngsi-go
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+
+*/
+
+package convenience
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/lets-fiware/ngsi-go/internal/assert"
+	"github.com/lets-fiware/ngsi-go/internal/helper"
+	"github.com/lets-fiware/ngsi-go/internal/ngsicli"
+	"github.com/lets-fiware/ngsi-go/internal/ngsierr"
+)
+
+func setupTestLineEditor(keys string, candidates ...string) (*lineEditor, *ngsicli.Context) {
+	c := setupTest([]string{"version", "--host", "orion"})
+	term := &helper.MockTermLib{Terminal: true}
+	c.Ngsi.TermLib = term
+
+	complete := func(text string) ([]string, string) {
+		i := strings.LastIndex(text, " ")
+		word := text[i+1:]
+		var matched []string
+		for _, c := range candidates {
+			if strings.HasPrefix(c, word) {
+				matched = append(matched, c)
+			}
+		}
+		return matched, word
+	}
+
+	return &lineEditor{ngsi: c.Ngsi, reader: strings.NewReader(keys), complete: complete}, c
+}
+
+func TestLineEditor(t *testing.T) {
+	e, c := setupTestLineEditor("list\r")
+
+	actual, err := e.readLine("> ", nil)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, "list", actual)
+		assert.Equal(t, "\r> \x1b[K\r> l\x1b[K\r> li\x1b[K\r> lis\x1b[K\r> list\x1b[K\r\n", helper.GetStdoutString(c))
+		assert.Equal(t, false, c.Ngsi.TermLib.(*helper.MockTermLib).Raw)
+	}
+}
+
+func TestLineEditorCursor(t *testing.T) {
+	cases := []struct {
+		keys     string
+		expected string
+	}{
+		{keys: "ac\x02b\x06d\r", expected: "abcd"},
+		{keys: "bc\x01a\x05d\r", expected: "abcd"},
+		{keys: "ac\x1b[Db\x1b[Cd\r", expected: "abcd"},
+		{keys: "bc\x1b[Ha\x1b[Fd\r", expected: "abcd"},
+		{keys: "bc\x1bOHa\x1bOFd\r", expected: "abcd"},
+		{keys: "bc\x1b[1~a\x1b[4~d\r", expected: "abcd"},
+		{keys: "bc\x1b[7~a\x1b[8~d\r", expected: "abcd"},
+		{keys: "\x02\x06\x1b[D\x1b[Cabcd\n", expected: "abcd"},
+	}
+
+	for _, c := range cases {
+		e, _ := setupTestLineEditor(c.keys)
+
+		actual, err := e.readLine("> ", nil)
+
+		if assert.NoError(t, err) {
+			assert.Equal(t, c.expected, actual)
+		}
+	}
+}
+
+func TestLineEditorDelete(t *testing.T) {
+	cases := []struct {
+		keys     string
+		expected string
+	}{
+		{keys: "abxc\x02\x7f\r", expected: "abc"},
+		{keys: "abxc\x02\x02\x04\r", expected: "abc"},
+		{keys: "abxc\x02\x02\x1b[3~\r", expected: "abc"},
+		{keys: "\x7f\x08abc\x08\r", expected: "ab"},
+		{keys: "abc\x04\x1b[3~\r", expected: "abc"},
+		{keys: "abc def\x02\x02\x0b\r", expected: "abc d"},
+		{keys: "abc def\x02\x02\x15\r", expected: "ef"},
+		{keys: "abc def  \x17\r", expected: "abc "},
+		{keys: "abc def\x02\x02\x17\r", expected: "abc ef"},
+		{keys: "abc\x03def\r", expected: "def"},
+	}
+
+	for _, c := range cases {
+		e, _ := setupTestLineEditor(c.keys)
+
+		actual, err := e.readLine("> ", nil)
+
+		if assert.NoError(t, err) {
+			assert.Equal(t, c.expected, actual)
+		}
+	}
+}
+
+func TestLineEditorIgnore(t *testing.T) {
+	cases := []string{
+		"ab\x1bxc\r",
+		"ab\x1b[Zc\r",
+		"ab\x1b[5~c\r",
+		"ab\x1b[2;5Pc\r",
+		"ab\x1b[2;5~c\r",
+		"ab\x00c\r",
+	}
+
+	for _, keys := range cases {
+		e, _ := setupTestLineEditor(keys)
+
+		actual, err := e.readLine("> ", nil)
+
+		if assert.NoError(t, err) {
+			assert.Equal(t, "abc", actual)
+		}
+	}
+}
+
+func TestLineEditorEscapeEOF(t *testing.T) {
+	for _, keys := range []string{"ab\x1b", "ab\x1b[", "ab\x1b[1"} {
+		e, _ := setupTestLineEditor(keys)
+
+		actual, err := e.readLine("> ", nil)
+
+		if assert.NoError(t, err) {
+			assert.Equal(t, "ab", actual)
+		}
+	}
+}
+
+func TestLineEditorHistory(t *testing.T) {
+	history := []string{"list types", "version"}
+
+	cases := []struct {
+		keys     string
+		expected string
+	}{
+		{keys: "\x1b[A\r", expected: "version"},
+		{keys: "\x1b[A\x1b[A\x1b[A\r", expected: "list types"},
+		{keys: "\x10\x10\x0e\r", expected: "version"},
+		{keys: "ls\x1b[A\x1b[B\x1b[B\r", expected: "ls"},
+		{keys: "\x10 --host orion\r", expected: "version --host orion"},
+	}
+
+	for _, c := range cases {
+		e, _ := setupTestLineEditor(c.keys)
+
+		actual, err := e.readLine("> ", history)
+
+		if assert.NoError(t, err) {
+			assert.Equal(t, c.expected, actual)
+		}
+	}
+}
+
+func TestLineEditorTab(t *testing.T) {
+	cases := []struct {
+		keys       string
+		candidates []string
+		expected   string
+	}{
+		{keys: "ver\t\r", candidates: []string{"version"}, expected: "version "},
+		{keys: "list en\t--type D\t\r", candidates: []string{"entities", "Device", "Door"}, expected: "list entities --type D"},
+		{keys: "list --host orion-\t\r", candidates: []string{"orion-ld", "orion-alias", "orion"}, expected: "list --host orion-"},
+		{keys: "list --host o\t\r", candidates: []string{"orion-ld", "orion-alias"}, expected: "list --host orion-"},
+		{keys: "x\t\r", candidates: nil, expected: "x"},
+		{keys: "\t\r", candidates: []string{"日", "本"}, expected: ""},
+	}
+
+	for _, c := range cases {
+		e, _ := setupTestLineEditor(c.keys, c.candidates...)
+
+		actual, err := e.readLine("> ", nil)
+
+		if assert.NoError(t, err) {
+			assert.Equal(t, c.expected, actual)
+		}
+	}
+}
+
+func TestLineEditorTabList(t *testing.T) {
+	e, c := setupTestLineEditor("D\t\r", "Device", "Door")
+
+	actual, err := e.readLine("> ", nil)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, "D", actual)
+		assert.Equal(t, true, strings.Contains(helper.GetStdoutString(c), "\r\nDevice  Door\r\n\r> D\x1b[K"))
+	}
+}
+
+func TestLineEditorEOF(t *testing.T) {
+	e, _ := setupTestLineEditor("\x04")
+
+	_, err := e.readLine("> ", nil)
+
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestLineEditorEOFWithLine(t *testing.T) {
+	e, _ := setupTestLineEditor("version")
+
+	actual, err := e.readLine("> ", nil)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, "version", actual)
+	}
+}
+
+func TestLineEditorRedraw(t *testing.T) {
+	e, c := setupTestLineEditor("ab\x02\r")
+
+	_, err := e.readLine("> ", nil)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, true, strings.Contains(helper.GetStdoutString(c), "\r> ab\x1b[K\x1b[1D"))
+	}
+}
+
+func TestLineEditorErrorMakeRaw(t *testing.T) {
+	e, c := setupTestLineEditor("")
+	c.Ngsi.TermLib = &helper.MockTermLib{RawErr: errors.New("raw error")}
+
+	_, err := e.readLine("> ", nil)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 1, ngsiErr.ErrNo)
+		assert.Equal(t, "raw error", ngsiErr.Message)
+	}
+}
@@ -0,0 +1,570 @@
+/*
+MIT License
+
+Copyright (c) 2020-2024 Kazuhito Suda
+
+This file is part of NGSI Go
+
+https://github.com/lets-fiware/ngsi-go
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+
+*/
+
+package convenience
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/lets-fiware/ngsi-go/internal/assert"
+	"github.com/lets-fiware/ngsi-go/internal/helper"
+	"github.com/lets-fiware/ngsi-go/internal/ngsicli"
+	"github.com/lets-fiware/ngsi-go/internal/ngsierr"
+)
+
+func setupTestShell(args []string, input string) *ngsicli.Context {
+	c := setupTest(append([]string{"shell"}, args...))
+	c.Ngsi.StdReader = io.MultiReader(strings.NewReader(input))
+	c.Ngsi.Ioutil = &helper.MockIoutilLib{ReadFileErr: os.ErrNotExist, AppendData: &bytes.Buffer{}}
+	return c
+}
+
+func getShellLog(c *ngsicli.Context) string {
+	return c.Ngsi.LogWriter.(*bytes.Buffer).String()
+}
+
+func TestShell(t *testing.T) {
+	c := setupTestShell([]string{"--host", "orion"}, "service openiot\npath /\nversion\nversion --host orion\nhistory\nexit\nversion\n")
+
+	reqRes := helper.MockHTTPReqRes{}
+	reqRes.Res.StatusCode = http.StatusOK
+	reqRes.Path = "/version"
+	reqRes.ResBody = []byte(`{"orion":{"version":"3.7.0"}}` + "\n")
+	helper.AddReqRes(c.Ngsi, reqRes)
+	helper.AddReqRes(c.Ngsi, reqRes)
+
+	err := shell(c, c.Ngsi, c.Client)
+
+	if assert.NoError(t, err) {
+		actual := helper.GetStdoutString(c)
+		expected := "openiot\n/\n" +
+			"{\"orion\":{\"version\":\"3.7.0\"}}\n" +
+			"{\"orion\":{\"version\":\"3.7.0\"}}\n" +
+			"    1  service openiot\n    2  path /\n    3  version\n    4  version --host orion\n    5  history\n"
+		assert.Equal(t, expected, actual)
+	}
+}
+
+func TestShellBuiltins(t *testing.T) {
+	c := setupTestShell(nil, "host\nhost orion-ld\nservice\nservice openiot\nservice -\npath /device\npath -\n\n")
+
+	err := shell(c, c.Ngsi, c.Client)
+
+	if assert.NoError(t, err) {
+		actual := helper.GetStdoutString(c)
+		expected := "\norion-ld\n\nopeniot\n\n/device\n\n"
+		assert.Equal(t, expected, actual)
+	}
+}
+
+func TestShellHelp(t *testing.T) {
+	c := setupTestShell(nil, "help")
+
+	err := shell(c, c.Ngsi, c.Client)
+
+	if assert.NoError(t, err) {
+		actual := helper.GetStdoutString(c)
+		assert.Equal(t, true, strings.Contains(actual, "   shell "))
+		assert.Equal(t, true, strings.HasSuffix(actual, shellHelp))
+	}
+}
+
+func TestShellErrors(t *testing.T) {
+	c := setupTestShell(nil, "host unknown\necho \"a\nshell\nunknown\n")
+
+	err := shell(c, c.Ngsi, c.Client)
+
+	if assert.NoError(t, err) {
+		actual := getShellLog(c)
+		expected := "GetServerInfo003 unknown not found\nshellSplit001 unterminated quote\nshell cannot be run in shell\nRun001 unknown not found\n"
+		assert.Equal(t, expected, actual)
+	}
+}
+
+func TestShellHistoryFile(t *testing.T) {
+	c := setupTestShell([]string{"--historyFile", "/tmp/history"}, "history\nhistory\nquit\n")
+
+	buf := &bytes.Buffer{}
+	c.Ngsi.Ioutil = &helper.MockIoutilLib{ReadFileData: []byte("version\n\nlist types\n"), AppendData: buf}
+
+	err := shell(c, c.Ngsi, c.Client)
+
+	if assert.NoError(t, err) {
+		actual := helper.GetStdoutString(c)
+		expected := "    1  version\n    2  list types\n    3  history\n    1  version\n    2  list types\n    3  history\n"
+		assert.Equal(t, expected, actual)
+		assert.Equal(t, "history\nquit\n", buf.String())
+	}
+}
+
+func TestShellHistoryRedact(t *testing.T) {
+	c := setupTestShell([]string{"--historyFile", "/tmp/history"}, "history --token abc\nquit\n")
+
+	buf := &bytes.Buffer{}
+	c.Ngsi.Ioutil = &helper.MockIoutilLib{ReadFileErr: os.ErrNotExist, AppendData: buf}
+
+	err := shell(c, c.Ngsi, c.Client)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, "    1  history --token ***\n", helper.GetStdoutString(c))
+		assert.Equal(t, "history --token ***\nquit\n", buf.String())
+	}
+}
+
+func TestShellRedact(t *testing.T) {
+	cases := []struct {
+		line     string
+		expected string
+	}{
+		{line: "list   types", expected: "list   types"},
+		{line: "create entity -d '{\"id\":\"E1\"}'", expected: "create entity -d ***"},
+		{line: "token --host keyrock --password \"p w\" --service 'my service'", expected: "token --host keyrock --password *** --service 'my service'"},
+		{line: "token --clientSecret=abc --oAuthToken x", expected: "token --clientSecret=*** --oAuthToken ***"},
+		{line: "x --data '' it\\'s", expected: "x --data *** 'it'\\''s'"},
+		{line: "x --apikey", expected: "x --apikey"},
+	}
+
+	for _, tc := range cases {
+		words, err := shellSplit(tc.line)
+		if assert.NoError(t, err) {
+			assert.Equal(t, tc.expected, shellRedact(tc.line, words), tc.line)
+		}
+	}
+}
+
+func TestShellQuote(t *testing.T) {
+	for _, w := range []string{"abc", "", "a b", "it's", `a"b`, `a\b`, "a\tb"} {
+		words, err := shellSplit(shellQuote(w))
+		if assert.NoError(t, err) {
+			assert.Equal(t, []string{w}, words, w)
+		}
+	}
+}
+
+func TestShellHistoryConfigDir(t *testing.T) {
+	c := setupTestShell(nil, "exit\n")
+
+	config := "/home/fiware/.config/fiware/ngsi-go-config.json"
+	c.Ngsi.ConfigFile.SetFileName(&config)
+	c.Ngsi.Ioutil = &helper.MockIoutilLib{ReadFileErr: os.ErrNotExist, AppendErr: errors.New("append error")}
+
+	s := &shellSession{c: c, ngsi: c.Ngsi}
+	err := s.loadHistory("")
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, "/home/fiware/.config/fiware/ngsi-go-history", s.historyFile)
+		s.addHistory("exit")
+		assert.Equal(t, []string{"exit"}, s.history)
+		assert.Equal(t, "append error\n", getShellLog(c))
+	}
+}
+
+func TestShellHistoryMax(t *testing.T) {
+	c := setupTestShell(nil, "")
+
+	history := strings.Repeat("version\n", shellHistoryMax+1)
+	c.Ngsi.Ioutil = &helper.MockIoutilLib{ReadFileData: []byte(history)}
+
+	s := &shellSession{c: c, ngsi: c.Ngsi}
+	err := s.loadHistory("/tmp/history")
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, shellHistoryMax, len(s.history))
+		s.historyFile = ""
+		s.addHistory("list types")
+		assert.Equal(t, shellHistoryMax, len(s.history))
+		assert.Equal(t, "list types", s.history[shellHistoryMax-1])
+	}
+}
+
+func TestShellTerminal(t *testing.T) {
+	c := setupTestShell([]string{"--host", "orion"}, "vers\t\r\x04")
+	c.Ngsi.TermLib = &helper.MockTermLib{Terminal: true}
+	stdout := c.Ngsi.StdWriter
+
+	reqRes := helper.MockHTTPReqRes{}
+	reqRes.Res.StatusCode = http.StatusOK
+	reqRes.Path = "/version"
+	reqRes.ResBody = []byte(`{"orion":{"version":"3.7.0"}}`)
+	helper.AddReqRes(c.Ngsi, reqRes)
+
+	err := shell(c, c.Ngsi, c.Client)
+
+	if assert.NoError(t, err) {
+		actual := helper.GetStdoutString(c)
+		assert.Equal(t, true, strings.Contains(actual, "\rngsi [orion]> version \x1b[K\r\n{\"orion\":{\"version\":\"3.7.0\"}}\n\rngsi [orion]> "))
+		assert.Equal(t, stdout, c.Ngsi.StdWriter)
+	}
+}
+
+func TestShellErrorHost(t *testing.T) {
+	c := setupTestShell([]string{"--host", "unknown"}, "")
+
+	err := shell(c, c.Ngsi, c.Client)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 1, ngsiErr.ErrNo)
+		assert.Equal(t, "unknown not found", ngsiErr.Message)
+	}
+}
+
+func TestShellErrorHistory(t *testing.T) {
+	c := setupTestShell([]string{"--historyFile", "/tmp/history"}, "")
+	c.Ngsi.Ioutil = &helper.MockIoutilLib{ReadFileErr: errors.New("read error")}
+
+	err := shell(c, c.Ngsi, c.Client)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 2, ngsiErr.ErrNo)
+		assert.Equal(t, "read error", ngsiErr.Message)
+	}
+}
+
+func TestShellErrorReadLine(t *testing.T) {
+	c := setupTestShell(nil, "")
+	c.Ngsi.TermLib = &helper.MockTermLib{Terminal: true, RawErr: errors.New("raw error")}
+
+	err := shell(c, c.Ngsi, c.Client)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 3, ngsiErr.ErrNo)
+		assert.Equal(t, "raw error", ngsiErr.Message)
+	}
+}
+
+func TestShellReadLine(t *testing.T) {
+	r := strings.NewReader("version\r\nlist types")
+
+	line, err := shellReadLine(r)
+	assert.NoError(t, err)
+	assert.Equal(t, "version", line)
+
+	line, err = shellReadLine(r)
+	assert.NoError(t, err)
+	assert.Equal(t, "list types", line)
+
+	_, err = shellReadLine(r)
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestShellPrompt(t *testing.T) {
+	s := &shellSession{}
+	assert.Equal(t, "ngsi> ", s.prompt())
+
+	s = &shellSession{host: "orion", path: "/device"}
+	assert.Equal(t, "ngsi [orion /device]> ", s.prompt())
+}
+
+func TestShellArgs(t *testing.T) {
+	c := setupTest([]string{"version", "--host", "orion"})
+	s := &shellSession{c: c, ngsi: c.Ngsi, host: "orion", service: "openiot", path: "/device"}
+
+	actual := s.args([]string{"export", "--service", "fiware", "--file", "a.zip"})
+
+	expected := []string{"export", "--service", "fiware", "--file", "a.zip", "--host", "orion", "--path", "/device"}
+	assert.Equal(t, expected, actual)
+}
+
+func TestShellArgsNoCommand(t *testing.T) {
+	c := setupTest([]string{"version", "--host", "orion"})
+	s := &shellSession{c: c, ngsi: c.Ngsi, host: "orion"}
+
+	actual := s.args([]string{"man"})
+
+	assert.Equal(t, []string{"man"}, actual)
+}
+
+func TestShellWriter(t *testing.T) {
+	buf := &bytes.Buffer{}
+	w := &shellWriter{w: buf}
+
+	_, _ = w.Write([]byte("abc\n"))
+	assert.Equal(t, true, w.newline)
+	_, _ = w.Write([]byte("abc"))
+	assert.Equal(t, false, w.newline)
+	assert.Equal(t, "abc\nabc", buf.String())
+}
+
+func TestShellSplit(t *testing.T) {
+	cases := []struct {
+		line     string
+		expected []string
+	}{
+		{line: "", expected: nil},
+		{line: "  list   types ", expected: []string{"list", "types"}},
+		{line: `create entity --data '{"id":"E1", "type":"T"}'`, expected: []string{"create", "entity", "--data", `{"id":"E1", "type":"T"}`}},
+		{line: `get entity --id "urn:a b" --attrs \"x\"`, expected: []string{"get", "entity", "--id", "urn:a b", "--attrs", `"x"`}},
+		{line: `a "b\"c\\d\n" ''`, expected: []string{"a", `b"c\d\n`, ""}},
+		{line: "a\\ b\tc", expected: []string{"a b", "c"}},
+	}
+
+	for _, c := range cases {
+		actual, err := shellSplit(c.line)
+		if assert.NoError(t, err) {
+			assert.Equal(t, c.expected, actual)
+		}
+	}
+}
+
+func TestShellSplitError(t *testing.T) {
+	for _, line := range []string{`a "b`, "a 'b", `a\`} {
+		_, err := shellSplit(line)
+
+		if assert.Error(t, err) {
+			ngsiErr := err.(*ngsierr.NgsiError)
+			assert.Equal(t, 1, ngsiErr.ErrNo)
+			assert.Equal(t, "unterminated quote", ngsiErr.Message)
+		}
+	}
+}
+
+func setupTestShellSession(host string) *shellSession {
+	c := setupTest([]string{"version", "--host", "orion"})
+	return &shellSession{c: c, ngsi: c.Ngsi, host: host}
+}
+
+func TestShellCompleteCommands(t *testing.T) {
+	s := setupTestShellSession("")
+
+	actual, word := s.complete("h")
+
+	assert.Equal(t, []string{"health", "help", "history", "host"}, actual)
+	assert.Equal(t, "h", word)
+}
+
+func TestShellCompleteFlags(t *testing.T) {
+	s := setupTestShellSession("")
+
+	actual, word := s.complete("health --host orion --")
+
+	assert.Equal(t, []string{"--help"}, actual)
+	assert.Equal(t, "--", word)
+
+	actual, word = s.complete("export --host orion --s")
+
+	assert.Equal(t, []string{"--service"}, actual)
+	assert.Equal(t, "--s", word)
+}
+
+func TestShellCompleteHostBuiltin(t *testing.T) {
+	s := setupTestShellSession("")
+
+	actual, word := s.complete("host orion-")
+
+	assert.Equal(t, []string{"orion-alias", "orion-ld"}, actual)
+	assert.Equal(t, "orion-", word)
+}
+
+func TestShellCompleteHostFlag(t *testing.T) {
+	s := setupTestShellSession("")
+
+	actual, word := s.complete("version --host p")
+
+	assert.Equal(t, []string{"perseo", "perseo-core"}, actual)
+	assert.Equal(t, "p", word)
+}
+
+func TestShellCompleteEntityTypesV2(t *testing.T) {
+	s := setupTestShellSession("orion")
+
+	reqRes := helper.MockHTTPReqRes{}
+	reqRes.Res.StatusCode = http.StatusOK
+	reqRes.Path = "/v2/types"
+	reqRes.RawQuery = helper.StrPtr("limit=100&options=values")
+	reqRes.ResBody = []byte(`["Room","Device","Door"]`)
+	helper.AddReqRes(s.ngsi, reqRes)
+
+	actual, word := s.complete("watch --type D")
+
+	assert.Equal(t, []string{"Device", "Door"}, actual)
+	assert.Equal(t, "D", word)
+}
+
+func TestShellCompleteEntityTypesLd(t *testing.T) {
+	s := setupTestShellSession("orion")
+
+	reqRes := helper.MockHTTPReqRes{}
+	reqRes.Res.StatusCode = http.StatusOK
+	reqRes.Path = "/ngsi-ld/v1/types"
+	reqRes.ResBody = []byte(`{"id":"urn:ngsi-ld:EntityTypeList:1","type":"EntityTypeList","typeList":["Room","Device"]}`)
+	helper.AddReqRes(s.ngsi, reqRes)
+
+	actual, _ := s.complete("watch --host orion-ld --type ")
+
+	assert.Equal(t, []string{"Device", "Room"}, actual)
+}
+
+func TestShellCompleteEntityIDsV2(t *testing.T) {
+	s := setupTestShellSession("orion")
+	s.ngsi.DryRun = true
+
+	reqRes := helper.MockHTTPReqRes{}
+	reqRes.Res.StatusCode = http.StatusOK
+	reqRes.Path = "/v2/entities"
+	reqRes.RawQuery = helper.StrPtr("attrs=id&idPattern=%5Eurn%3Angsi-ld%3ADevice%3A0&limit=100&type=Device")
+	reqRes.ResBody = []byte(`[{"id":"urn:ngsi-ld:Device:002","type":"Device"},{"id":"urn:ngsi-ld:Device:001","type":"Device"}]`)
+	helper.AddReqRes(s.ngsi, reqRes)
+
+	actual, _ := s.complete("watch --type Device --id urn:ngsi-ld:Device:0")
+
+	assert.Equal(t, []string{"urn:ngsi-ld:Device:001", "urn:ngsi-ld:Device:002"}, actual)
+	assert.Equal(t, true, s.ngsi.DryRun)
+}
+
+func TestShellCompleteEntityIDsLd(t *testing.T) {
+	s := setupTestShellSession("orion-ld")
+
+	reqRes := helper.MockHTTPReqRes{}
+	reqRes.Res.StatusCode = http.StatusOK
+	reqRes.Path = "/ngsi-ld/v1/entities"
+	reqRes.RawQuery = helper.StrPtr("idPattern=%5E&limit=100&type=Device")
+	reqRes.ResBody = []byte(`[{"id":"urn:ngsi-ld:Device:001","type":"Device"}]`)
+	helper.AddReqRes(s.ngsi, reqRes)
+
+	actual, _ := s.complete("watch -t Device --id ")
+
+	assert.Equal(t, []string{"urn:ngsi-ld:Device:001"}, actual)
+}
+
+func TestShellCompleteNoCandidates(t *testing.T) {
+	cases := []struct {
+		host string
+		text string
+	}{
+		{host: "", text: "watch --type "},
+		{host: "unknown", text: "watch --type "},
+		{host: "keyrock", text: "watch --id "},
+		{host: "orion", text: "watch --url "},
+		{host: "orion", text: "get entity --data '{"},
+	}
+
+	for _, c := range cases {
+		s := setupTestShellSession(c.host)
+
+		actual, _ := s.complete(c.text)
+
+		assert.Equal(t, 0, len(actual))
+	}
+}
+
+func TestShellCompleteErrorHTTP(t *testing.T) {
+	s := setupTestShellSession("orion")
+
+	reqRes := helper.MockHTTPReqRes{}
+	reqRes.Err = errors.New("http error")
+	helper.AddReqRes(s.ngsi, reqRes)
+
+	actual, _ := s.complete("watch --type ")
+
+	assert.Equal(t, 0, len(actual))
+}
+
+func TestShellCompleteErrorStatus(t *testing.T) {
+	s := setupTestShellSession("orion")
+
+	reqRes := helper.MockHTTPReqRes{}
+	reqRes.Res.StatusCode = http.StatusBadRequest
+	helper.AddReqRes(s.ngsi, reqRes)
+
+	actual, _ := s.complete("watch --id ")
+
+	assert.Equal(t, 0, len(actual))
+}
+
+func TestShellCompleteErrorJSON(t *testing.T) {
+	cases := []struct {
+		host string
+		text string
+	}{
+		{host: "orion", text: "watch --type "},
+		{host: "orion-ld", text: "watch --type "},
+		{host: "orion", text: "watch --id "},
+	}
+
+	for _, c := range cases {
+		s := setupTestShellSession(c.host)
+
+		reqRes := helper.MockHTTPReqRes{}
+		reqRes.Res.StatusCode = http.StatusOK
+		reqRes.ResBody = []byte(`{"error":`)
+		helper.AddReqRes(s.ngsi, reqRes)
+
+		actual, _ := s.complete(c.text)
+
+		assert.Equal(t, 0, len(actual))
+	}
+}
+
+func TestShellWriterBuffered(t *testing.T) {
+	buf := &bytes.Buffer{}
+	w := &shellWriter{w: bufio.NewWriter(buf)}
+
+	_, _ = w.Write([]byte("abc\n"))
+
+	assert.Equal(t, "abc\n", buf.String())
+}
+
+func TestShellHistoryNoConfig(t *testing.T) {
+	c := setupTestShell(nil, "")
+
+	config := ""
+	c.Ngsi.ConfigFile.SetFileName(&config)
+
+	s := &shellSession{c: c, ngsi: c.Ngsi}
+	err := s.loadHistory("")
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, "", s.historyFile)
+	}
+}
+
+func TestShellCompleteEntityIDsAll(t *testing.T) {
+	s := setupTestShellSession("orion")
+
+	reqRes := helper.MockHTTPReqRes{}
+	reqRes.Res.StatusCode = http.StatusOK
+	reqRes.Path = "/v2/entities"
+	reqRes.RawQuery = helper.StrPtr("attrs=id&limit=100")
+	reqRes.ResBody = []byte(`[{"id":"E1","type":"T"}]`)
+	helper.AddReqRes(s.ngsi, reqRes)
+
+	actual, _ := s.complete("watch --id ")
+
+	assert.Equal(t, []string{"E1"}, actual)
+}
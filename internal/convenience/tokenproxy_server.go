@@ -124,6 +124,8 @@ func tokenProxyServer(c *ngsicli.Context, ngsi *ngsilib.NGSI, client *ngsilib.Cl
 	clientID := c.String("clientId")
 	clientSecret := c.String("clientSecret")
 
+	// The token proxy has no token of its own. It relays the token requests of its clients to
+	// Keyrock with the client credentials, so no token refresher is started.
 	config := &tokenProxyConfig{
 		idmHost:       u,
 		RevokeURL:     revokeURL,
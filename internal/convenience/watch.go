@@ -0,0 +1,537 @@
+/*
+MIT License
+
+Copyright (c) 2020-2024 Kazuhito Suda
+
+This file is part of NGSI Go
+
+https://github.com/lets-fiware/ngsi-go
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+
+*/
+
+package convenience
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/lets-fiware/ngsi-go/internal/ngsicli"
+	"github.com/lets-fiware/ngsi-go/internal/ngsierr"
+	"github.com/lets-fiware/ngsi-go/internal/ngsilib"
+)
+
+const watchLimit = 100
+
+// watchExpires is the lifetime of the temporary subscription. It is renewed at half of it so that a
+// subscription left behind by a killed process goes away by itself.
+var watchExpires = 10 * time.Minute
+
+// watcher follows changes to the entities selected by --type, --id, --idPattern and --query.
+type watcher struct {
+	ngsi     *ngsilib.NGSI
+	client   *ngsilib.Client
+	pretty   bool
+	verbose  bool
+	modified map[string]string
+}
+
+func watch(c *ngsicli.Context, ngsi *ngsilib.NGSI, client *ngsilib.Client) error {
+	const funcName = "watch"
+
+	interval, err := time.ParseDuration(c.String("interval"))
+	if err != nil || interval <= 0 {
+		return ngsierr.New(funcName, 1, "interval error: "+c.String("interval"), err)
+	}
+
+	probe, err := time.ParseDuration(c.String("probe"))
+	if err != nil || probe < 0 {
+		return ngsierr.New(funcName, 2, "probe error: "+c.String("probe"), err)
+	}
+
+	w := &watcher{ngsi: ngsi, client: client, pretty: c.Bool("pretty"), verbose: c.Bool("verbose")}
+
+	stop := make(chan os.Signal, 1)
+	ngsi.SignalLib.Notify(stop, os.Interrupt, syscall.SIGTERM)
+	defer ngsi.SignalLib.Stop(stop)
+
+	if !c.Bool("poll") {
+		reason := "no callback address"
+		if notifyURL := watchNotifyURL(c, ngsi, client); notifyURL != "" {
+			reason, err = w.listen(c, stop, notifyURL, probe)
+			if err != nil {
+				return ngsierr.New(funcName, 3, err.Error(), err)
+			}
+			if reason == "" {
+				return nil
+			}
+		}
+		fmt.Fprintf(ngsi.Stderr, "%s, polling every %s\n", reason, interval)
+	}
+
+	if err = w.poll(c, stop, interval); err != nil {
+		return ngsierr.New(funcName, 4, err.Error(), err)
+	}
+	return nil
+}
+
+// watchNotifyURL returns the url at which the broker reaches the embedded receiver, or "" when
+// there is no address to give the broker. Without --callbackHost, it uses the local address of the
+// route to the broker, or else the first non-loopback IPv4 address.
+func watchNotifyURL(c *ngsicli.Context, ngsi *ngsilib.NGSI, client *ngsilib.Client) string {
+	host := c.String("callbackHost")
+	if host == "" {
+		host = watchLocalAddr(ngsi, client)
+	}
+	if host == "" {
+		addrs, _ := ngsi.NetLib.InterfaceAddrs()
+		for _, a := range addrs {
+			if ipnet, ok := a.(*net.IPNet); ok && !ipnet.IP.IsLoopback() && ipnet.IP.To4() != nil {
+				host = ipnet.IP.String()
+				break
+			}
+		}
+		if host == "" {
+			return ""
+		}
+	}
+	return "http://" + net.JoinHostPort(host, c.String("port")) + c.String("url")
+}
+
+// watchLocalAddr returns the local address used to reach the broker. Dialing UDP sends no packet;
+// it only selects the route.
+func watchLocalAddr(ngsi *ngsilib.NGSI, client *ngsilib.Client) string {
+	if client.URL == nil || client.URL.Hostname() == "" {
+		return ""
+	}
+	port := client.URL.Port()
+	if port == "" {
+		port = "80"
+	}
+	conn, err := ngsi.NetLib.DialTimeout("udp", net.JoinHostPort(client.URL.Hostname(), port), time.Second)
+	if err != nil {
+		return ""
+	}
+	defer func() { _ = conn.Close() }()
+
+	if addr, ok := conn.LocalAddr().(*net.UDPAddr); ok && !addr.IP.IsUnspecified() {
+		return addr.IP.String()
+	}
+	return ""
+}
+
+// listen starts the embedded receiver and creates a temporary subscription to it, renewing the
+// subscription until a signal is received. It returns why watch should poll instead when the broker
+// rejects the subscription, the receiver cannot serve, or the broker reports that it fails to
+// deliver notifications. The delivery is checked every probe when probe is not 0.
+func (w *watcher) listen(c *ngsicli.Context, stop <-chan os.Signal, notifyURL string, probe time.Duration) (string, error) {
+	const funcName = "watchListen"
+
+	h := &receiverHandler{ngsi: w.ngsi, pretty: w.pretty}
+	mux := http.NewServeMux()
+	mux.Handle(c.String("url"), h)
+
+	done := make(chan error, 1)
+	go func() {
+		done <- w.ngsi.NetLib.ListenAndServe(":"+c.String("port"), mux)
+	}()
+
+	id, err := w.subscribe(c, notifyURL)
+	if err != nil {
+		return "subscription rejected: " + err.Error(), nil
+	}
+
+	if w.verbose {
+		fmt.Fprintf(w.ngsi.Stderr, "%s %s\n", id, notifyURL)
+	}
+
+	var check <-chan time.Time
+	if probe > 0 {
+		ticker := time.NewTicker(probe)
+		defer ticker.Stop()
+		check = ticker.C
+	}
+	renew := time.NewTicker(watchExpires / 2)
+	defer renew.Stop()
+
+	reason := ""
+	var renewErr, checkErr error
+loop:
+	for {
+		select {
+		case <-stop:
+			break loop
+		case serveErr := <-done:
+			if serveErr != nil {
+				reason = "receiver error: " + serveErr.Error()
+			}
+			break loop
+		case <-check:
+			if reason, checkErr = w.delivery(id); checkErr != nil || reason != "" {
+				break loop
+			}
+		case <-renew.C:
+			if renewErr = w.renew(id); renewErr != nil {
+				break loop
+			}
+		}
+	}
+
+	if err = w.unsubscribe(id); err != nil {
+		return "", ngsierr.New(funcName, 1, err.Error(), err)
+	}
+	if renewErr != nil {
+		return "", ngsierr.New(funcName, 2, renewErr.Error(), renewErr)
+	}
+	if checkErr != nil {
+		return "", ngsierr.New(funcName, 3, checkErr.Error(), checkErr)
+	}
+	return reason, nil
+}
+
+// subscribe creates a subscription which notifies the changes to notifyURL and returns its id.
+func (w *watcher) subscribe(c *ngsicli.Context, notifyURL string) (string, error) {
+	const funcName = "watchSubscribe"
+
+	client := w.client
+
+	entity := map[string]interface{}{"type": c.String("type")}
+	if c.IsSet("id") {
+		entity["id"] = c.String("id")
+	} else if c.IsSet("idPattern") {
+		entity["idPattern"] = c.String("idPattern")
+	} else if client.IsNgsiV2() {
+		entity["idPattern"] = ".*"
+	}
+
+	var attrs []string
+	if c.IsSet("attrs") {
+		attrs = strings.Split(c.String("attrs"), ",")
+	}
+
+	var sub map[string]interface{}
+	var path string
+
+	if client.IsNgsiLd() {
+		path = "/ngsi-ld/v1/subscriptions/"
+		notification := map[string]interface{}{
+			"format":   "normalized",
+			"endpoint": map[string]interface{}{"uri": notifyURL, "accept": "application/json"},
+		}
+		sub = map[string]interface{}{
+			"type":         "Subscription",
+			"description":  "ngsi watch",
+			"entities":     []interface{}{entity},
+			"notification": notification,
+			"expiresAt":    w.expires(),
+		}
+		if attrs != nil {
+			sub["watchedAttributes"] = attrs
+			notification["attributes"] = attrs
+		}
+		if c.IsSet("query") {
+			sub["q"] = c.String("query")
+		}
+	} else {
+		path = "/v2/subscriptions/"
+		subject := map[string]interface{}{"entities": []interface{}{entity}}
+		notification := map[string]interface{}{"http": map[string]interface{}{"url": notifyURL}}
+		sub = map[string]interface{}{
+			"description":  "ngsi watch",
+			"subject":      subject,
+			"notification": notification,
+			"expires":      w.expires(),
+		}
+		condition := map[string]interface{}{}
+		if attrs != nil {
+			condition["attrs"] = attrs
+			notification["attrs"] = attrs
+		}
+		if c.IsSet("query") {
+			condition["expression"] = map[string]interface{}{"q": c.String("query")}
+		}
+		if len(condition) > 0 {
+			subject["condition"] = condition
+		}
+	}
+
+	b, err := ngsilib.JSONMarshalEncode(sub, false)
+	if err != nil {
+		return "", ngsierr.New(funcName, 1, err.Error(), err)
+	}
+
+	client.SetPath("/subscriptions")
+	client.SetQuery(&url.Values{})
+	client.SetContentJSON()
+
+	res, body, err := client.HTTPPost(b)
+	if err != nil {
+		return "", ngsierr.New(funcName, 2, err.Error(), err)
+	}
+	if res.StatusCode != http.StatusCreated {
+		return "", ngsierr.New(funcName, 3, fmt.Sprintf("%s %s", res.Status, string(body)), nil)
+	}
+
+	id := strings.TrimPrefix(res.Header.Get("Location"), path)
+
+	w.ngsi.Logging(ngsilib.LogInfo, fmt.Sprintf("%s is created\n", res.Header.Get("Location")))
+
+	return id, nil
+}
+
+// expires returns the expiration time of the temporary subscription.
+func (w *watcher) expires() string {
+	return w.ngsi.TimeLib.Now().Add(watchExpires).UTC().Format("2006-01-02T15:04:05.000Z")
+}
+
+// renew extends the expiration time of the subscription created by subscribe.
+func (w *watcher) renew(id string) error {
+	const funcName = "watchRenew"
+
+	client := w.client
+
+	key := "expires"
+	if client.IsNgsiLd() {
+		key = "expiresAt"
+	}
+
+	b, err := ngsilib.JSONMarshal(map[string]string{key: w.expires()})
+	if err != nil {
+		return ngsierr.New(funcName, 1, err.Error(), err)
+	}
+
+	client.SetPath("/subscriptions/" + id)
+	client.SetQuery(&url.Values{})
+	client.SetContentJSON()
+
+	res, body, err := client.HTTPPatch(b)
+	if err != nil {
+		return ngsierr.New(funcName, 2, err.Error(), err)
+	}
+	if res.StatusCode != http.StatusNoContent {
+		return ngsierr.New(funcName, 3, fmt.Sprintf("%s %s", res.Status, string(body)), nil)
+	}
+
+	return nil
+}
+
+// delivery gets the subscription created by subscribe and returns why the broker fails to notify
+// the embedded receiver, or "" when it doesn't report a failure. Orion reports a failure with the
+// status "failed", failsCounter or a lastFailure later than lastSuccess.
+func (w *watcher) delivery(id string) (string, error) {
+	const funcName = "watchDelivery"
+
+	client := w.client
+
+	client.SetPath("/subscriptions/" + id)
+	client.SetQuery(&url.Values{})
+
+	res, body, err := client.HTTPGet()
+	if err != nil {
+		return "", ngsierr.New(funcName, 1, err.Error(), err)
+	}
+	if res.StatusCode != http.StatusOK {
+		return "", ngsierr.New(funcName, 2, fmt.Sprintf("%s %s", res.Status, string(body)), nil)
+	}
+
+	var sub struct {
+		Status       string `json:"status"`
+		Notification struct {
+			Status            string  `json:"status"`
+			FailsCounter      float64 `json:"failsCounter"`
+			LastFailure       string  `json:"lastFailure"`
+			LastFailureReason string  `json:"lastFailureReason"`
+			LastSuccess       string  `json:"lastSuccess"`
+		} `json:"notification"`
+	}
+	if err := ngsilib.JSONUnmarshal(body, &sub); err != nil {
+		return "", ngsierr.New(funcName, 3, err.Error(), err)
+	}
+
+	n := sub.Notification
+	if sub.Status == "failed" || n.Status == "failed" || n.FailsCounter > 0 || watchLater(n.LastFailure, n.LastSuccess) {
+		if n.LastFailureReason != "" {
+			return "notification failed: " + n.LastFailureReason, nil
+		}
+		return "notification failed", nil
+	}
+	return "", nil
+}
+
+// watchLater reports whether the time t1 is later than t2. An empty t2 is earlier than any time.
+func watchLater(t1, t2 string) bool {
+	if t1 == "" {
+		return false
+	}
+	if t2 == "" {
+		return true
+	}
+	tm1, err1 := time.Parse(time.RFC3339Nano, t1)
+	tm2, err2 := time.Parse(time.RFC3339Nano, t2)
+	if err1 != nil || err2 != nil {
+		return t1 > t2
+	}
+	return tm1.After(tm2)
+}
+
+// unsubscribe deletes the subscription created by subscribe.
+func (w *watcher) unsubscribe(id string) error {
+	const funcName = "watchUnsubscribe"
+
+	client := w.client
+
+	client.SetPath("/subscriptions/" + id)
+	client.SetQuery(&url.Values{})
+
+	res, body, err := client.HTTPDelete(nil)
+	if err != nil {
+		return ngsierr.New(funcName, 1, err.Error(), err)
+	}
+	if res.StatusCode != http.StatusNoContent {
+		return ngsierr.New(funcName, 2, fmt.Sprintf("%s %s", res.Status, string(body)), nil)
+	}
+
+	w.ngsi.Logging(ngsilib.LogInfo, fmt.Sprintf("%s is deleted\n", id))
+
+	return nil
+}
+
+// poll gets the entities every interval until a signal is received.
+func (w *watcher) poll(c *ngsicli.Context, stop <-chan os.Signal, interval time.Duration) error {
+	const funcName = "watchPoll"
+
+	for {
+		if err := w.fetch(c); err != nil {
+			return ngsierr.New(funcName, 1, err.Error(), err)
+		}
+		select {
+		case <-stop:
+			return nil
+		case <-time.After(interval):
+		}
+	}
+}
+
+// watchEntity has the members of an entity used to find out whether it was modified.
+type watchEntity struct {
+	ID           string `json:"id"`
+	Type         string `json:"type"`
+	ModifiedAt   string `json:"modifiedAt"`
+	DateModified *struct {
+		Value string `json:"value"`
+	} `json:"dateModified"`
+}
+
+// fetch gets the entities and prints those whose dateModified (v2) or modifiedAt (LD) has changed
+// since the previous fetch, in the form of a notification. The first fetch only records them.
+func (w *watcher) fetch(c *ngsicli.Context) error {
+	const funcName = "watchFetch"
+
+	client := w.client
+
+	v := url.Values{}
+	v.Set("type", c.String("type"))
+	if c.IsSet("id") {
+		v.Set("id", c.String("id"))
+	}
+	if c.IsSet("idPattern") {
+		v.Set("idPattern", c.String("idPattern"))
+	}
+	if c.IsSet("query") {
+		v.Set("q", c.String("query"))
+	}
+	if client.IsNgsiLd() {
+		if c.IsSet("attrs") {
+			v.Set("attrs", c.String("attrs"))
+		}
+		v.Set("options", "sysAttrs")
+		v.Set("count", "true")
+	} else {
+		if c.IsSet("attrs") {
+			v.Set("attrs", c.String("attrs")+",dateModified")
+		} else {
+			v.Set("attrs", "dateModified,*")
+		}
+		v.Set("options", "count")
+	}
+	client.SetAcceptJSON()
+
+	modified := map[string]string{}
+	changed := []string{}
+
+	for offset := 0; ; offset += watchLimit {
+		client.SetPath("/entities")
+		v.Set("limit", fmt.Sprintf("%d", watchLimit))
+		v.Set("offset", fmt.Sprintf("%d", offset))
+		client.SetQuery(&v)
+
+		res, body, err := client.HTTPGet()
+		if err != nil {
+			return ngsierr.New(funcName, 1, err.Error(), err)
+		}
+		if res.StatusCode != http.StatusOK {
+			return ngsierr.New(funcName, 2, fmt.Sprintf("%s %s", res.Status, string(body)), nil)
+		}
+		count, err := client.ResultsCount(res)
+		if err != nil {
+			return ngsierr.New(funcName, 3, "ResultsCount error", err)
+		}
+
+		var items []json.RawMessage
+		if err = ngsilib.JSONUnmarshal(body, &items); err != nil {
+			return ngsierr.New(funcName, 4, err.Error(), err)
+		}
+
+		for _, item := range items {
+			var e watchEntity
+			if err = ngsilib.JSONUnmarshal(item, &e); err != nil {
+				return ngsierr.New(funcName, 5, err.Error(), err)
+			}
+			key := e.Type + "\t" + e.ID
+			t := e.ModifiedAt
+			if e.DateModified != nil {
+				t = e.DateModified.Value
+			}
+			modified[key] = t
+			if prev, ok := w.modified[key]; w.modified != nil && (!ok || prev != t) {
+				changed = append(changed, string(item))
+			}
+		}
+
+		if len(items) == 0 || offset+watchLimit >= count {
+			break
+		}
+	}
+
+	w.modified = modified
+
+	if len(changed) > 0 {
+		receiverPrint(w.ngsi, []byte(`{"data":[`+strings.Join(changed, ",")+`]}`), w.pretty)
+	}
+
+	return nil
+}
@@ -0,0 +1,881 @@
+/*
+MIT License
+
+Copyright (c) 2020-2024 Kazuhito Suda
+
+This file is part of NGSI Go
+
+https://github.com/lets-fiware/ngsi-go
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+
+*/
+
+package convenience
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/lets-fiware/ngsi-go/internal/assert"
+	"github.com/lets-fiware/ngsi-go/internal/helper"
+	"github.com/lets-fiware/ngsi-go/internal/ngsierr"
+	"github.com/lets-fiware/ngsi-go/internal/ngsilib"
+)
+
+const watchV2Page1 = `[{"id":"Room1","type":"Room","temperature":{"type":"Number","value":23,"metadata":{}},"dateModified":{"type":"DateTime","value":"2026-10-01T10:00:00.000Z","metadata":{}}},{"id":"Room2","type":"Room","temperature":{"type":"Number","value":21,"metadata":{}},"dateModified":{"type":"DateTime","value":"2026-10-01T10:00:00.000Z","metadata":{}}}]`
+const watchV2Page2 = `[{"id":"Room1","type":"Room","temperature":{"type":"Number","value":25,"metadata":{}},"dateModified":{"type":"DateTime","value":"2026-10-01T10:00:05.000Z","metadata":{}}},{"id":"Room2","type":"Room","temperature":{"type":"Number","value":21,"metadata":{}},"dateModified":{"type":"DateTime","value":"2026-10-01T10:00:00.000Z","metadata":{}}},{"id":"Room3","type":"Room","temperature":{"type":"Number","value":19,"metadata":{}},"dateModified":{"type":"DateTime","value":"2026-10-01T10:00:04.000Z","metadata":{}}}]`
+
+func TestWatchPollV2(t *testing.T) {
+	c := setupTest([]string{"watch", "--host", "orion", "--type", "Room", "--poll"})
+
+	c.Ngsi.SignalLib = &helper.MockSignalLib{Signal: os.Interrupt}
+
+	reqRes := helper.MockHTTPReqRes{}
+	reqRes.Res.StatusCode = http.StatusOK
+	reqRes.Path = "/v2/entities"
+	reqRes.ResHeader = http.Header{"Fiware-Total-Count": []string{"2"}}
+	reqRes.ResBody = []byte(watchV2Page1)
+	reqRes.RawQuery = helper.StrPtr("attrs=dateModified%2C%2A&limit=100&offset=0&options=count&type=Room")
+
+	helper.SetClientHTTP(c, reqRes)
+
+	err := watch(c, c.Ngsi, c.Client)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, "", helper.GetStdoutString(c))
+		assert.Equal(t, "", helper.GetStderrString(c))
+	}
+}
+
+func TestWatchNoCallbackAddress(t *testing.T) {
+	c := setupTest([]string{"watch", "--host", "orion", "--type", "Room"})
+
+	c.Ngsi.SignalLib = &helper.MockSignalLib{Signal: os.Interrupt}
+	c.Ngsi.NetLib = &helper.MockNetLib{AddrErr: errors.New("InterfaceAddrs error")}
+
+	reqRes := helper.MockHTTPReqRes{}
+	reqRes.Res.StatusCode = http.StatusOK
+	reqRes.Path = "/v2/entities"
+	reqRes.ResHeader = http.Header{"Fiware-Total-Count": []string{"2"}}
+	reqRes.ResBody = []byte(watchV2Page1)
+
+	helper.SetClientHTTP(c, reqRes)
+
+	err := watch(c, c.Ngsi, c.Client)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, "no callback address, polling every 5s\n", helper.GetStderrString(c))
+	}
+}
+
+func TestWatchSubscribeV2(t *testing.T) {
+	c := setupTest([]string{"watch", "--host", "orion", "--type", "Room", "--callbackHost", "192.168.1.1", "--verbose"})
+
+	c.Ngsi.SignalLib = &helper.MockSignalLib{Signal: os.Interrupt}
+	c.Ngsi.TimeLib = &helper.MockTimeLib{DateTime: "2026-10-01T10:00:00.000Z"}
+
+	reqRes1 := helper.MockHTTPReqRes{}
+	reqRes1.Res.StatusCode = http.StatusCreated
+	reqRes1.Path = "/v2/subscriptions"
+	reqRes1.ReqData = []byte(`{"description":"ngsi watch","expires":"2026-10-01T10:10:00.000Z","notification":{"http":{"url":"http://192.168.1.1:1028/"}},"subject":{"entities":[{"idPattern":".*","type":"Room"}]}}`)
+	reqRes1.ResHeader = http.Header{"Location": []string{"/v2/subscriptions/5f0a44789dd803416ae36d29"}}
+
+	reqRes2 := helper.MockHTTPReqRes{}
+	reqRes2.Res.StatusCode = http.StatusNoContent
+	reqRes2.Path = "/v2/subscriptions/5f0a44789dd803416ae36d29"
+
+	helper.SetClientHTTP(c, reqRes1, reqRes2)
+
+	err := watch(c, c.Ngsi, c.Client)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, "5f0a44789dd803416ae36d29 http://192.168.1.1:1028/\n", helper.GetStderrString(c))
+	}
+}
+
+func TestWatchSubscribeV2AttrsQuery(t *testing.T) {
+	c := setupTest([]string{"watch", "--host", "orion", "--type", "Room", "--id", "Room1", "--attrs", "temperature,humidity", "--query", "temperature>30", "--callbackHost", "ngsi", "--port", "8000", "--url", "/notify"})
+
+	c.Ngsi.SignalLib = &helper.MockSignalLib{Signal: os.Interrupt}
+	c.Ngsi.TimeLib = &helper.MockTimeLib{DateTime: "2026-10-01T10:00:00.000Z"}
+
+	reqRes1 := helper.MockHTTPReqRes{}
+	reqRes1.Res.StatusCode = http.StatusCreated
+	reqRes1.Path = "/v2/subscriptions"
+	reqRes1.ReqData = []byte(`{"description":"ngsi watch","expires":"2026-10-01T10:10:00.000Z","notification":{"attrs":["temperature","humidity"],"http":{"url":"http://ngsi:8000/notify"}},"subject":{"condition":{"attrs":["temperature","humidity"],"expression":{"q":"temperature>30"}},"entities":[{"id":"Room1","type":"Room"}]}}`)
+	reqRes1.ResHeader = http.Header{"Location": []string{"/v2/subscriptions/5f0a44789dd803416ae36d29"}}
+
+	reqRes2 := helper.MockHTTPReqRes{}
+	reqRes2.Res.StatusCode = http.StatusNoContent
+	reqRes2.Path = "/v2/subscriptions/5f0a44789dd803416ae36d29"
+
+	helper.SetClientHTTP(c, reqRes1, reqRes2)
+
+	err := watch(c, c.Ngsi, c.Client)
+
+	assert.NoError(t, err)
+}
+
+func TestWatchSubscribeLD(t *testing.T) {
+	c := setupTest([]string{"watch", "--host", "orion-ld", "--type", "Room", "--idPattern", "^Room", "--attrs", "temperature", "--query", "temperature>30", "--callbackHost", "192.168.1.1"})
+
+	c.Ngsi.SignalLib = &helper.MockSignalLib{Signal: os.Interrupt}
+	c.Ngsi.TimeLib = &helper.MockTimeLib{DateTime: "2026-10-01T10:00:00.000Z"}
+
+	reqRes1 := helper.MockHTTPReqRes{}
+	reqRes1.Res.StatusCode = http.StatusCreated
+	reqRes1.Path = "/ngsi-ld/v1/subscriptions"
+	reqRes1.ReqData = []byte(`{"description":"ngsi watch","entities":[{"idPattern":"^Room","type":"Room"}],"expiresAt":"2026-10-01T10:10:00.000Z","notification":{"attributes":["temperature"],"endpoint":{"accept":"application/json","uri":"http://192.168.1.1:1028/"},"format":"normalized"},"q":"temperature>30","type":"Subscription","watchedAttributes":["temperature"]}`)
+	reqRes1.ResHeader = http.Header{"Location": []string{"/ngsi-ld/v1/subscriptions/urn:ngsi-ld:Subscription:001"}}
+
+	reqRes2 := helper.MockHTTPReqRes{}
+	reqRes2.Res.StatusCode = http.StatusNoContent
+	reqRes2.Path = "/ngsi-ld/v1/subscriptions/urn:ngsi-ld:Subscription:001"
+
+	helper.SetClientHTTP(c, reqRes1, reqRes2)
+
+	err := watch(c, c.Ngsi, c.Client)
+
+	assert.NoError(t, err)
+}
+
+func TestWatchErrorInterval(t *testing.T) {
+	c := setupTest([]string{"watch", "--host", "orion", "--type", "Room", "--interval", "0s"})
+
+	err := watch(c, c.Ngsi, c.Client)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 1, ngsiErr.ErrNo)
+		assert.Equal(t, "interval error: 0s", ngsiErr.Message)
+	}
+}
+
+func TestWatchErrorPoll(t *testing.T) {
+	c := setupTest([]string{"watch", "--host", "orion", "--type", "Room", "--poll"})
+
+	reqRes := helper.MockHTTPReqRes{}
+	reqRes.Res.StatusCode = http.StatusBadRequest
+	reqRes.Path = "/v2/entities"
+	reqRes.ResBody = []byte("error")
+
+	helper.SetClientHTTP(c, reqRes)
+
+	err := watch(c, c.Ngsi, c.Client)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 4, ngsiErr.ErrNo)
+		assert.Equal(t, " error", ngsiErr.Message)
+	}
+}
+
+func TestWatchSubscriptionRejected(t *testing.T) {
+	c := setupTest([]string{"watch", "--host", "orion", "--type", "Room", "--callbackHost", "192.168.1.1"})
+
+	c.Ngsi.SignalLib = &helper.MockSignalLib{Signal: os.Interrupt}
+
+	reqRes1 := helper.MockHTTPReqRes{}
+	reqRes1.Res.StatusCode = http.StatusBadRequest
+	reqRes1.Path = "/v2/subscriptions"
+	reqRes1.ResBody = []byte("error")
+
+	reqRes2 := helper.MockHTTPReqRes{}
+	reqRes2.Res.StatusCode = http.StatusOK
+	reqRes2.Path = "/v2/entities"
+	reqRes2.ResHeader = http.Header{"Fiware-Total-Count": []string{"2"}}
+	reqRes2.ResBody = []byte(watchV2Page1)
+
+	helper.SetClientHTTP(c, reqRes1, reqRes2)
+
+	err := watch(c, c.Ngsi, c.Client)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, "subscription rejected:  error, polling every 5s\n", helper.GetStderrString(c))
+	}
+}
+
+func TestWatchDeliveryFailed(t *testing.T) {
+	c := setupTest([]string{"watch", "--host", "orion", "--type", "Room", "--callbackHost", "192.168.1.1", "--probe", "1ms"})
+
+	c.Ngsi.NetLib = &watchNetLib{MockNetLib: &helper.MockNetLib{}}
+	c.Ngsi.SignalLib = &watchSignalLib{}
+
+	reqRes1 := helper.MockHTTPReqRes{}
+	reqRes1.Res.StatusCode = http.StatusCreated
+	reqRes1.Path = "/v2/subscriptions"
+	reqRes1.ResHeader = http.Header{"Location": []string{"/v2/subscriptions/5f0a44789dd803416ae36d29"}}
+
+	reqRes2 := helper.MockHTTPReqRes{}
+	reqRes2.Res.StatusCode = http.StatusOK
+	reqRes2.Path = "/v2/subscriptions/5f0a44789dd803416ae36d29"
+	reqRes2.ResBody = []byte(`{"id":"5f0a44789dd803416ae36d29","status":"failed","notification":{"failsCounter":1,"lastFailure":"2026-10-01T10:00:00.000Z","lastFailureReason":"Timeout was reached"}}`)
+
+	reqRes3 := helper.MockHTTPReqRes{}
+	reqRes3.Res.StatusCode = http.StatusNoContent
+	reqRes3.Path = "/v2/subscriptions/5f0a44789dd803416ae36d29"
+
+	reqRes4 := helper.MockHTTPReqRes{}
+	reqRes4.Res.StatusCode = http.StatusOK
+	reqRes4.Path = "/v2/entities"
+	reqRes4.ResHeader = http.Header{"Fiware-Total-Count": []string{"2"}}
+	reqRes4.ResBody = []byte(watchV2Page1)
+
+	helper.SetClientHTTP(c, reqRes1, reqRes2, reqRes3, reqRes4)
+
+	err := watch(c, c.Ngsi, c.Client)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, "notification failed: Timeout was reached, polling every 5s\n", helper.GetStderrString(c))
+	}
+}
+
+func TestWatchErrorProbe(t *testing.T) {
+	c := setupTest([]string{"watch", "--host", "orion", "--type", "Room", "--probe", "-1s"})
+
+	err := watch(c, c.Ngsi, c.Client)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 2, ngsiErr.ErrNo)
+		assert.Equal(t, "probe error: -1s", ngsiErr.Message)
+	}
+}
+
+func TestWatchReceiverError(t *testing.T) {
+	c := setupTest([]string{"watch", "--host", "orion", "--type", "Room", "--callbackHost", "192.168.1.1"})
+
+	c.Ngsi.NetLib = &helper.MockNetLib{ListenAndServeErr: errors.New("ListenAndServe error")}
+	c.Ngsi.SignalLib = &watchSignalLib{}
+
+	reqRes1 := helper.MockHTTPReqRes{}
+	reqRes1.Res.StatusCode = http.StatusCreated
+	reqRes1.Path = "/v2/subscriptions"
+	reqRes1.ResHeader = http.Header{"Location": []string{"/v2/subscriptions/5f0a44789dd803416ae36d29"}}
+
+	reqRes2 := helper.MockHTTPReqRes{}
+	reqRes2.Res.StatusCode = http.StatusNoContent
+	reqRes2.Path = "/v2/subscriptions/5f0a44789dd803416ae36d29"
+
+	reqRes3 := helper.MockHTTPReqRes{}
+	reqRes3.Res.StatusCode = http.StatusOK
+	reqRes3.Path = "/v2/entities"
+	reqRes3.ResHeader = http.Header{"Fiware-Total-Count": []string{"2"}}
+	reqRes3.ResBody = []byte(watchV2Page1)
+
+	helper.SetClientHTTP(c, reqRes1, reqRes2, reqRes3)
+
+	err := watch(c, c.Ngsi, c.Client)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, "receiver error: ListenAndServe error, polling every 5s\n", helper.GetStderrString(c))
+	}
+}
+
+func TestWatchErrorListen(t *testing.T) {
+	c := setupTest([]string{"watch", "--host", "orion", "--type", "Room", "--callbackHost", "192.168.1.1", "--probe", "1ms"})
+
+	c.Ngsi.NetLib = &watchNetLib{MockNetLib: &helper.MockNetLib{}}
+
+	reqRes1 := helper.MockHTTPReqRes{}
+	reqRes1.Res.StatusCode = http.StatusCreated
+	reqRes1.Path = "/v2/subscriptions"
+	reqRes1.ResHeader = http.Header{"Location": []string{"/v2/subscriptions/5f0a44789dd803416ae36d29"}}
+
+	reqRes2 := helper.MockHTTPReqRes{}
+	reqRes2.Res.StatusCode = http.StatusNotFound
+	reqRes2.Path = "/v2/subscriptions/5f0a44789dd803416ae36d29"
+	reqRes2.ResBody = []byte("error")
+
+	reqRes3 := helper.MockHTTPReqRes{}
+	reqRes3.Res.StatusCode = http.StatusNoContent
+	reqRes3.Path = "/v2/subscriptions/5f0a44789dd803416ae36d29"
+
+	helper.SetClientHTTP(c, reqRes1, reqRes2, reqRes3)
+
+	err := watch(c, c.Ngsi, c.Client)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 3, ngsiErr.ErrNo)
+		assert.Equal(t, " error", ngsiErr.Message)
+	}
+}
+
+func TestWatchNotifyURL(t *testing.T) {
+	c := setupTest([]string{"watch", "--host", "orion", "--type", "Room", "--callbackHost", "fe80::1", "--port", "8000", "--url", "/notify"})
+
+	actual := watchNotifyURL(c, c.Ngsi, c.Client)
+
+	assert.Equal(t, "http://[fe80::1]:8000/notify", actual)
+}
+
+func TestWatchNotifyURLRoute(t *testing.T) {
+	c := setupTest([]string{"watch", "--host", "orion", "--type", "Room"})
+
+	c.Ngsi.NetLib = ngsilib.NewNetLib()
+	c.Client.URL = &url.URL{Scheme: "http", Host: "127.0.0.1:1026"}
+
+	actual := watchNotifyURL(c, c.Ngsi, c.Client)
+
+	assert.Equal(t, "http://127.0.0.1:1028/", actual)
+}
+
+func TestWatchNotifyURLDialError(t *testing.T) {
+	c := setupTest([]string{"watch", "--host", "orion", "--type", "Room"})
+
+	c.Ngsi.NetLib = &helper.MockNetLib{DialErr: errors.New("dial error"), AddrErr: errors.New("InterfaceAddrs error")}
+
+	actual := watchNotifyURL(c, c.Ngsi, c.Client)
+
+	assert.Equal(t, "", actual)
+}
+
+func TestWatchLocalAddrNoURL(t *testing.T) {
+	c := setupTest([]string{"watch", "--host", "orion", "--type", "Room"})
+
+	c.Client.URL = nil
+
+	actual := watchLocalAddr(c.Ngsi, c.Client)
+
+	assert.Equal(t, "", actual)
+}
+
+func TestWatchNotifyURLInterfaceAddrs(t *testing.T) {
+	c := setupTest([]string{"watch", "--host", "orion", "--type", "Room"})
+
+	c.Ngsi.NetLib = &helper.MockNetLib{AddrErr: errors.New("InterfaceAddrs error")}
+
+	actual := watchNotifyURL(c, c.Ngsi, c.Client)
+
+	assert.Equal(t, "", actual)
+}
+
+func TestWatchListenServeError(t *testing.T) {
+	c := setupTest([]string{"watch", "--host", "orion", "--type", "Room"})
+
+	c.Ngsi.NetLib = &helper.MockNetLib{ListenAndServeErr: errors.New("ListenAndServe error")}
+
+	reqRes1 := helper.MockHTTPReqRes{}
+	reqRes1.Res.StatusCode = http.StatusCreated
+	reqRes1.Path = "/v2/subscriptions"
+	reqRes1.ResHeader = http.Header{"Location": []string{"/v2/subscriptions/5f0a44789dd803416ae36d29"}}
+
+	reqRes2 := helper.MockHTTPReqRes{}
+	reqRes2.Res.StatusCode = http.StatusNoContent
+	reqRes2.Path = "/v2/subscriptions/5f0a44789dd803416ae36d29"
+
+	helper.SetClientHTTP(c, reqRes1, reqRes2)
+
+	w := &watcher{ngsi: c.Ngsi, client: c.Client}
+	actual, err := w.listen(c, nil, "http://192.168.1.1:1028/", 0)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, "receiver error: ListenAndServe error", actual)
+	}
+}
+
+func TestWatchListenServeStopped(t *testing.T) {
+	c := setupTest([]string{"watch", "--host", "orion", "--type", "Room"})
+
+	c.Ngsi.NetLib = &helper.MockNetLib{}
+
+	reqRes1 := helper.MockHTTPReqRes{}
+	reqRes1.Res.StatusCode = http.StatusCreated
+	reqRes1.Path = "/v2/subscriptions"
+	reqRes1.ResHeader = http.Header{"Location": []string{"/v2/subscriptions/5f0a44789dd803416ae36d29"}}
+
+	reqRes2 := helper.MockHTTPReqRes{}
+	reqRes2.Res.StatusCode = http.StatusNoContent
+	reqRes2.Path = "/v2/subscriptions/5f0a44789dd803416ae36d29"
+
+	helper.SetClientHTTP(c, reqRes1, reqRes2)
+
+	w := &watcher{ngsi: c.Ngsi, client: c.Client}
+	actual, err := w.listen(c, nil, "http://192.168.1.1:1028/", 0)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, "", actual)
+	}
+}
+
+func TestWatchListenErrorUnsubscribe(t *testing.T) {
+	c := setupTest([]string{"watch", "--host", "orion", "--type", "Room"})
+
+	c.Ngsi.NetLib = &helper.MockNetLib{ListenAndServeErr: errors.New("ListenAndServe error")}
+
+	reqRes1 := helper.MockHTTPReqRes{}
+	reqRes1.Res.StatusCode = http.StatusCreated
+	reqRes1.Path = "/v2/subscriptions"
+	reqRes1.ResHeader = http.Header{"Location": []string{"/v2/subscriptions/5f0a44789dd803416ae36d29"}}
+
+	reqRes2 := helper.MockHTTPReqRes{}
+	reqRes2.Res.StatusCode = http.StatusNotFound
+	reqRes2.Path = "/v2/subscriptions/5f0a44789dd803416ae36d29"
+	reqRes2.ResBody = []byte("error")
+
+	helper.SetClientHTTP(c, reqRes1, reqRes2)
+
+	w := &watcher{ngsi: c.Ngsi, client: c.Client}
+	_, err := w.listen(c, nil, "http://192.168.1.1:1028/", 0)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 1, ngsiErr.ErrNo)
+		assert.Equal(t, " error", ngsiErr.Message)
+	}
+}
+
+func TestWatchListenRenew(t *testing.T) {
+	c := setupTest([]string{"watch", "--host", "orion", "--type", "Room"})
+
+	c.Ngsi.NetLib = &watchNetLib{MockNetLib: &helper.MockNetLib{}}
+	c.Ngsi.TimeLib = &helper.MockTimeLib{DateTime: "2026-10-01T10:00:00.000Z"}
+
+	expires := watchExpires
+	watchExpires = 2 * time.Millisecond
+	defer func() { watchExpires = expires }()
+
+	reqRes1 := helper.MockHTTPReqRes{}
+	reqRes1.Res.StatusCode = http.StatusCreated
+	reqRes1.Path = "/v2/subscriptions"
+	reqRes1.ResHeader = http.Header{"Location": []string{"/v2/subscriptions/5f0a44789dd803416ae36d29"}}
+
+	reqRes2 := helper.MockHTTPReqRes{}
+	reqRes2.Res.StatusCode = http.StatusNoContent
+	reqRes2.Path = "/v2/subscriptions/5f0a44789dd803416ae36d29"
+	reqRes2.ReqData = []byte(`{"expires":"2026-10-01T10:00:00.002Z"}`)
+
+	reqRes3 := helper.MockHTTPReqRes{}
+	reqRes3.Res.StatusCode = http.StatusNotFound
+	reqRes3.Path = "/v2/subscriptions/5f0a44789dd803416ae36d29"
+	reqRes3.ResBody = []byte("error")
+
+	reqRes4 := helper.MockHTTPReqRes{}
+	reqRes4.Res.StatusCode = http.StatusNoContent
+	reqRes4.Path = "/v2/subscriptions/5f0a44789dd803416ae36d29"
+
+	helper.SetClientHTTP(c, reqRes1, reqRes2, reqRes3, reqRes4)
+
+	w := &watcher{ngsi: c.Ngsi, client: c.Client}
+	_, err := w.listen(c, nil, "http://192.168.1.1:1028/", 0)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 2, ngsiErr.ErrNo)
+		assert.Equal(t, " error", ngsiErr.Message)
+	}
+}
+
+func TestWatchDelivery(t *testing.T) {
+	cases := []struct {
+		body     string
+		expected string
+	}{
+		{body: `{"status":"active","notification":{"timesSent":3,"lastSuccess":"2026-10-01T10:00:00.000Z"}}`, expected: ""},
+		{body: `{"status":"active","notification":{"lastFailure":"2026-10-01T10:00:00.000Z","lastSuccess":"2026-10-01T10:00:01.000Z"}}`, expected: ""},
+		{body: `{"status":"active","notification":{"lastFailure":"2026-10-01T10:00:02.000Z","lastSuccess":"2026-10-01T10:00:01.000Z"}}`, expected: "notification failed"},
+		{body: `{"status":"failed","notification":{}}`, expected: "notification failed"},
+		{body: `{"notification":{"status":"failed","lastFailureReason":"Connection refused"}}`, expected: "notification failed: Connection refused"},
+		{body: `{"notification":{"failsCounter":2}}`, expected: "notification failed"},
+	}
+
+	for _, tc := range cases {
+		c := setupTest([]string{"watch", "--host", "orion", "--type", "Room"})
+
+		reqRes := helper.MockHTTPReqRes{}
+		reqRes.Res.StatusCode = http.StatusOK
+		reqRes.Path = "/v2/subscriptions/5f0a44789dd803416ae36d29"
+		reqRes.ResBody = []byte(tc.body)
+
+		helper.SetClientHTTP(c, reqRes)
+
+		w := &watcher{ngsi: c.Ngsi, client: c.Client}
+		actual, err := w.delivery("5f0a44789dd803416ae36d29")
+
+		if assert.NoError(t, err) {
+			assert.Equal(t, tc.expected, actual, tc.body)
+		}
+	}
+}
+
+func TestWatchDeliveryErrorHTTP(t *testing.T) {
+	c := setupTest([]string{"watch", "--host", "orion", "--type", "Room"})
+
+	reqRes := helper.MockHTTPReqRes{}
+	reqRes.Err = errors.New("http error")
+
+	helper.SetClientHTTP(c, reqRes)
+
+	w := &watcher{ngsi: c.Ngsi, client: c.Client}
+	_, err := w.delivery("5f0a44789dd803416ae36d29")
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 1, ngsiErr.ErrNo)
+		assert.Equal(t, "http error", ngsiErr.Message)
+	}
+}
+
+func TestWatchDeliveryErrorStatus(t *testing.T) {
+	c := setupTest([]string{"watch", "--host", "orion", "--type", "Room"})
+
+	reqRes := helper.MockHTTPReqRes{}
+	reqRes.Res.StatusCode = http.StatusNotFound
+	reqRes.ResBody = []byte("error")
+
+	helper.SetClientHTTP(c, reqRes)
+
+	w := &watcher{ngsi: c.Ngsi, client: c.Client}
+	_, err := w.delivery("5f0a44789dd803416ae36d29")
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 2, ngsiErr.ErrNo)
+		assert.Equal(t, " error", ngsiErr.Message)
+	}
+}
+
+func TestWatchDeliveryErrorUnmarshal(t *testing.T) {
+	c := setupTest([]string{"watch", "--host", "orion", "--type", "Room"})
+
+	reqRes := helper.MockHTTPReqRes{}
+	reqRes.Res.StatusCode = http.StatusOK
+	reqRes.ResBody = []byte("{")
+
+	helper.SetClientHTTP(c, reqRes)
+
+	w := &watcher{ngsi: c.Ngsi, client: c.Client}
+	_, err := w.delivery("5f0a44789dd803416ae36d29")
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 3, ngsiErr.ErrNo)
+	}
+}
+
+func TestWatchLater(t *testing.T) {
+	assert.Equal(t, false, watchLater("", ""))
+	assert.Equal(t, true, watchLater("2026-10-01T10:00:00.000Z", ""))
+	assert.Equal(t, false, watchLater("2026-10-01T10:00:00Z", "2026-10-01T10:00:00.000Z"))
+	assert.Equal(t, true, watchLater("2026-10-01T10:00:00.5Z", "2026-10-01T10:00:00.000Z"))
+	assert.Equal(t, true, watchLater("b", "a"))
+}
+
+func TestWatchRenewLD(t *testing.T) {
+	c := setupTest([]string{"watch", "--host", "orion-ld", "--type", "Room"})
+
+	c.Ngsi.TimeLib = &helper.MockTimeLib{DateTime: "2026-10-01T10:00:00.000Z"}
+
+	reqRes := helper.MockHTTPReqRes{}
+	reqRes.Res.StatusCode = http.StatusNoContent
+	reqRes.Path = "/ngsi-ld/v1/subscriptions/urn:ngsi-ld:Subscription:001"
+	reqRes.ReqData = []byte(`{"expiresAt":"2026-10-01T10:10:00.000Z"}`)
+
+	helper.SetClientHTTP(c, reqRes)
+
+	w := &watcher{ngsi: c.Ngsi, client: c.Client}
+	err := w.renew("urn:ngsi-ld:Subscription:001")
+
+	assert.NoError(t, err)
+}
+
+func TestWatchRenewErrorMarshal(t *testing.T) {
+	c := setupTest([]string{"watch", "--host", "orion", "--type", "Room"})
+
+	helper.SetJSONEncodeErr(c.Ngsi, 0)
+
+	w := &watcher{ngsi: c.Ngsi, client: c.Client}
+	err := w.renew("5f0a44789dd803416ae36d29")
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 1, ngsiErr.ErrNo)
+		assert.Equal(t, "json error", ngsiErr.Message)
+	}
+}
+
+func TestWatchRenewErrorHTTP(t *testing.T) {
+	c := setupTest([]string{"watch", "--host", "orion", "--type", "Room"})
+
+	reqRes := helper.MockHTTPReqRes{}
+	reqRes.Err = errors.New("http error")
+
+	helper.SetClientHTTP(c, reqRes)
+
+	w := &watcher{ngsi: c.Ngsi, client: c.Client}
+	err := w.renew("5f0a44789dd803416ae36d29")
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 2, ngsiErr.ErrNo)
+		assert.Equal(t, "http error", ngsiErr.Message)
+	}
+}
+
+func TestWatchSubscribeErrorMarshal(t *testing.T) {
+	c := setupTest([]string{"watch", "--host", "orion", "--type", "Room"})
+
+	helper.SetJSONEncodeErr(c.Ngsi, 0)
+
+	w := &watcher{ngsi: c.Ngsi, client: c.Client}
+	_, err := w.subscribe(c, "http://192.168.1.1:1028/")
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 1, ngsiErr.ErrNo)
+		assert.Equal(t, "json error", ngsiErr.Message)
+	}
+}
+
+func TestWatchSubscribeErrorHTTP(t *testing.T) {
+	c := setupTest([]string{"watch", "--host", "orion", "--type", "Room"})
+
+	reqRes := helper.MockHTTPReqRes{}
+	reqRes.Err = errors.New("http error")
+
+	helper.SetClientHTTP(c, reqRes)
+
+	w := &watcher{ngsi: c.Ngsi, client: c.Client}
+	_, err := w.subscribe(c, "http://192.168.1.1:1028/")
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 2, ngsiErr.ErrNo)
+		assert.Equal(t, "http error", ngsiErr.Message)
+	}
+}
+
+func TestWatchUnsubscribeErrorHTTP(t *testing.T) {
+	c := setupTest([]string{"watch", "--host", "orion", "--type", "Room"})
+
+	reqRes := helper.MockHTTPReqRes{}
+	reqRes.Err = errors.New("http error")
+
+	helper.SetClientHTTP(c, reqRes)
+
+	w := &watcher{ngsi: c.Ngsi, client: c.Client}
+	err := w.unsubscribe("5f0a44789dd803416ae36d29")
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 1, ngsiErr.ErrNo)
+		assert.Equal(t, "http error", ngsiErr.Message)
+	}
+}
+
+func TestWatchFetchV2(t *testing.T) {
+	c := setupTest([]string{"watch", "--host", "orion", "--type", "Room", "--attrs", "temperature"})
+
+	reqRes1 := helper.MockHTTPReqRes{}
+	reqRes1.Res.StatusCode = http.StatusOK
+	reqRes1.Path = "/v2/entities"
+	reqRes1.ResHeader = http.Header{"Fiware-Total-Count": []string{"2"}}
+	reqRes1.ResBody = []byte(watchV2Page1)
+	reqRes1.RawQuery = helper.StrPtr("attrs=temperature%2CdateModified&limit=100&offset=0&options=count&type=Room")
+
+	reqRes2 := helper.MockHTTPReqRes{}
+	reqRes2.Res.StatusCode = http.StatusOK
+	reqRes2.Path = "/v2/entities"
+	reqRes2.ResHeader = http.Header{"Fiware-Total-Count": []string{"3"}}
+	reqRes2.ResBody = []byte(watchV2Page2)
+
+	helper.SetClientHTTP(c, reqRes1, reqRes2)
+
+	w := &watcher{ngsi: c.Ngsi, client: c.Client}
+
+	err := w.fetch(c)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "", helper.GetStdoutString(c))
+	}
+
+	err = w.fetch(c)
+	if assert.NoError(t, err) {
+		expected := `{"data":[{"id":"Room1","type":"Room","temperature":{"type":"Number","value":25,"metadata":{}},"dateModified":{"type":"DateTime","value":"2026-10-01T10:00:05.000Z","metadata":{}}},{"id":"Room3","type":"Room","temperature":{"type":"Number","value":19,"metadata":{}},"dateModified":{"type":"DateTime","value":"2026-10-01T10:00:04.000Z","metadata":{}}}]}` + "\n"
+		assert.Equal(t, expected, helper.GetStdoutString(c))
+	}
+}
+
+func TestWatchFetchLDPretty(t *testing.T) {
+	c := setupTest([]string{"watch", "--host", "orion-ld", "--type", "Room", "--id", "urn:ngsi-ld:Room:001", "--query", "temperature>30", "--attrs", "temperature", "--pretty"})
+
+	reqRes1 := helper.MockHTTPReqRes{}
+	reqRes1.Res.StatusCode = http.StatusOK
+	reqRes1.Path = "/ngsi-ld/v1/entities"
+	reqRes1.ResHeader = http.Header{"Ngsild-Results-Count": []string{"1"}}
+	reqRes1.ResBody = []byte(`[{"id":"urn:ngsi-ld:Room:001","type":"Room","modifiedAt":"2026-10-01T10:00:00.000Z","temperature":{"type":"Property","value":31}}]`)
+	reqRes1.RawQuery = helper.StrPtr("attrs=temperature&count=true&id=urn%3Angsi-ld%3ARoom%3A001&limit=100&offset=0&options=sysAttrs&q=temperature%3E30&type=Room")
+
+	reqRes2 := helper.MockHTTPReqRes{}
+	reqRes2.Res.StatusCode = http.StatusOK
+	reqRes2.Path = "/ngsi-ld/v1/entities"
+	reqRes2.ResHeader = http.Header{"Ngsild-Results-Count": []string{"1"}}
+	reqRes2.ResBody = []byte(`[{"id":"urn:ngsi-ld:Room:001","type":"Room","modifiedAt":"2026-10-01T10:00:05.000Z","temperature":{"type":"Property","value":32}}]`)
+
+	helper.SetClientHTTP(c, reqRes1, reqRes2)
+
+	w := &watcher{ngsi: c.Ngsi, client: c.Client, pretty: true}
+
+	err := w.fetch(c)
+	assert.NoError(t, err)
+
+	err = w.fetch(c)
+	if assert.NoError(t, err) {
+		expected := "{\n  \"data\": [\n    {\n      \"id\": \"urn:ngsi-ld:Room:001\",\n      \"type\": \"Room\",\n      \"modifiedAt\": \"2026-10-01T10:00:05.000Z\",\n      \"temperature\": {\n        \"type\": \"Property\",\n        \"value\": 32\n      }\n    }\n  ]\n}\n"
+		assert.Equal(t, expected, helper.GetStdoutString(c))
+	}
+}
+
+func TestWatchFetchPage(t *testing.T) {
+	c := setupTest([]string{"watch", "--host", "orion", "--type", "Room", "--idPattern", "^Room"})
+
+	reqRes1 := helper.MockHTTPReqRes{}
+	reqRes1.Res.StatusCode = http.StatusOK
+	reqRes1.Path = "/v2/entities"
+	reqRes1.ResHeader = http.Header{"Fiware-Total-Count": []string{"102"}}
+	reqRes1.ResBody = []byte(watchV2Page1)
+	reqRes1.RawQuery = helper.StrPtr("attrs=dateModified%2C%2A&idPattern=%5ERoom&limit=100&offset=0&options=count&type=Room")
+
+	reqRes2 := helper.MockHTTPReqRes{}
+	reqRes2.Res.StatusCode = http.StatusOK
+	reqRes2.Path = "/v2/entities"
+	reqRes2.ResHeader = http.Header{"Fiware-Total-Count": []string{"102"}}
+	reqRes2.ResBody = []byte(`[]`)
+	reqRes2.RawQuery = helper.StrPtr("attrs=dateModified%2C%2A&idPattern=%5ERoom&limit=100&offset=100&options=count&type=Room")
+
+	helper.SetClientHTTP(c, reqRes1, reqRes2)
+
+	w := &watcher{ngsi: c.Ngsi, client: c.Client}
+
+	err := w.fetch(c)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, 2, len(w.modified))
+	}
+}
+
+func TestWatchFetchErrorHTTP(t *testing.T) {
+	c := setupTest([]string{"watch", "--host", "orion", "--type", "Room"})
+
+	reqRes := helper.MockHTTPReqRes{}
+	reqRes.Err = errors.New("http error")
+
+	helper.SetClientHTTP(c, reqRes)
+
+	w := &watcher{ngsi: c.Ngsi, client: c.Client}
+	err := w.fetch(c)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 1, ngsiErr.ErrNo)
+		assert.Equal(t, "http error", ngsiErr.Message)
+	}
+}
+
+func TestWatchFetchErrorResultsCount(t *testing.T) {
+	c := setupTest([]string{"watch", "--host", "orion", "--type", "Room"})
+
+	reqRes := helper.MockHTTPReqRes{}
+	reqRes.Res.StatusCode = http.StatusOK
+	reqRes.Path = "/v2/entities"
+	reqRes.ResBody = []byte(watchV2Page1)
+
+	helper.SetClientHTTP(c, reqRes)
+
+	w := &watcher{ngsi: c.Ngsi, client: c.Client}
+	err := w.fetch(c)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 3, ngsiErr.ErrNo)
+		assert.Equal(t, "ResultsCount error", ngsiErr.Message)
+	}
+}
+
+func TestWatchFetchErrorUnmarshal(t *testing.T) {
+	c := setupTest([]string{"watch", "--host", "orion", "--type", "Room"})
+
+	reqRes := helper.MockHTTPReqRes{}
+	reqRes.Res.StatusCode = http.StatusOK
+	reqRes.Path = "/v2/entities"
+	reqRes.ResHeader = http.Header{"Fiware-Total-Count": []string{"2"}}
+	reqRes.ResBody = []byte(watchV2Page1)
+
+	helper.SetClientHTTP(c, reqRes)
+	helper.SetJSONDecodeErr(c.Ngsi, 0)
+
+	w := &watcher{ngsi: c.Ngsi, client: c.Client}
+	err := w.fetch(c)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 4, ngsiErr.ErrNo)
+		assert.Equal(t, "json error", ngsiErr.Message)
+	}
+}
+
+func TestWatchFetchErrorUnmarshalEntity(t *testing.T) {
+	c := setupTest([]string{"watch", "--host", "orion", "--type", "Room"})
+
+	reqRes := helper.MockHTTPReqRes{}
+	reqRes.Res.StatusCode = http.StatusOK
+	reqRes.Path = "/v2/entities"
+	reqRes.ResHeader = http.Header{"Fiware-Total-Count": []string{"2"}}
+	reqRes.ResBody = []byte(watchV2Page1)
+
+	helper.SetClientHTTP(c, reqRes)
+	helper.SetJSONDecodeErr(c.Ngsi, 1)
+
+	w := &watcher{ngsi: c.Ngsi, client: c.Client}
+	err := w.fetch(c)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 5, ngsiErr.ErrNo)
+		assert.Equal(t, "json error", ngsiErr.Message)
+	}
+}
+
+// watchNetLib blocks in ListenAndServe as the real server does.
+type watchNetLib struct {
+	*helper.MockNetLib
+}
+
+func (n *watchNetLib) ListenAndServe(addr string, handler http.Handler) error {
+	select {}
+}
+
+// watchSignalLib sends an interrupt a while after it is registered, so that the polling which
+// follows the probe stops after its first fetch.
+type watchSignalLib struct{}
+
+func (s *watchSignalLib) Notify(c chan<- os.Signal, sig ...os.Signal) {
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		c <- os.Interrupt
+	}()
+}
+
+func (s *watchSignalLib) Stop(c chan<- os.Signal) {
+}
@@ -0,0 +1,63 @@
+/*
+MIT License
+
+Copyright (c) 2020-2024 Kazuhito Suda
+
+This file is part of NGSI Go
+
+https://github.com/lets-fiware/ngsi-go
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+
+*/
+
+package helper
+
+import "errors"
+
+type MockExecLib struct {
+	Secrets map[string]string
+	Err     error
+	Args    [][]string
+}
+
+// Output emulates a credential helper which stores secrets with "store NAME" and returns them with "get NAME".
+func (e *MockExecLib) Output(name string, args []string, stdin []byte) ([]byte, error) {
+	e.Args = append(e.Args, append([]string{name}, args...))
+	if e.Err != nil {
+		return nil, e.Err
+	}
+	if e.Secrets == nil {
+		e.Secrets = make(map[string]string)
+	}
+	if len(args) < 2 {
+		return nil, nil
+	}
+	switch args[len(args)-2] {
+	case "store":
+		e.Secrets[args[len(args)-1]] = string(stdin)
+	case "get":
+		v, ok := e.Secrets[args[len(args)-1]]
+		if !ok {
+			return nil, errors.New("not found")
+		}
+		return []byte(v + "\n"), nil
+	}
+	return nil, nil
+}
@@ -36,6 +36,7 @@ import (
 
 type MockFilePathLib struct {
 	PathAbsErr error
+	GlobErr    error
 }
 
 func (i *MockFilePathLib) FilePathAbs(path string) (string, error) {
@@ -49,3 +50,10 @@ func (i *MockFilePathLib) FilePathJoin(elem ...string) string {
 func (i *MockFilePathLib) FilePathBase(path string) string {
 	return filepath.Base(path)
 }
+
+func (i *MockFilePathLib) FilePathGlob(pattern string) ([]string, error) {
+	if i.GlobErr != nil {
+		return nil, i.GlobErr
+	}
+	return filepath.Glob(pattern)
+}
@@ -62,3 +62,23 @@ func TestFilePathFilePathBase(t *testing.T) {
 
 	assert.Equal(t, "entities", s)
 }
+
+func TestFilePathFilePathGlob(t *testing.T) {
+	m := &MockFilePathLib{}
+
+	s, err := m.FilePathGlob("filepath_lib*.go")
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, []string{"filepath_lib.go", "filepath_lib_test.go"}, s)
+	}
+}
+
+func TestFilePathFilePathGlobError(t *testing.T) {
+	m := &MockFilePathLib{GlobErr: errors.New("glob error")}
+
+	_, err := m.FilePathGlob("*.go")
+
+	if assert.Error(t, err) {
+		assert.Equal(t, "glob error", err.Error())
+	}
+}
@@ -179,6 +179,9 @@ func SetupTestWithConfigAndCache(app *ngsicli.App, args []string, config, cache
 
 	ngsi.HTTP = NewMockHTTP()
 	ngsi.NetLib = &MockNetLib{}
+	ngsi.SignalLib = &MockSignalLib{}
+	ngsi.TermLib = &MockTermLib{}
+	ngsi.ExecLib = &MockExecLib{}
 
 	buffer := &bytes.Buffer{}
 	stderrBuffer := &bytes.Buffer{}
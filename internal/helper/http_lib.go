@@ -34,6 +34,7 @@ import (
 	"net/http"
 	"net/url"
 	"reflect"
+	"sync"
 
 	"github.com/lets-fiware/ngsi-go/internal/ngsicli"
 	"github.com/lets-fiware/ngsi-go/internal/ngsierr"
@@ -43,6 +44,7 @@ import (
 // MockHTTPReqRes is ...
 type MockHTTP struct {
 	index  int
+	mutex  sync.Mutex
 	ReqRes []MockHTTPReqRes
 }
 
@@ -86,8 +88,10 @@ func (h *MockHTTP) Request(method string, url *url.URL, headers map[string]strin
 	if len(h.ReqRes) == 0 {
 		return nil, nil, ngsierr.New(funcName, 1, "ReqRes length is 0", nil)
 	}
+	h.mutex.Lock()
 	r := h.ReqRes[h.index]
 	h.index++
+	h.mutex.Unlock()
 
 	if r.Err != nil {
 		return nil, nil, r.Err
@@ -47,6 +47,7 @@ type MockIoLib struct {
 	Tokens     *string
 	TruncIndex int
 	Trunc      []error
+	Env        map[string]string
 }
 
 func (io *MockIoLib) Open() (err error) {
@@ -110,7 +111,7 @@ func (io *MockIoLib) FileName() *string {
 }
 
 func (io *MockIoLib) Getenv(key string) string {
-	return ""
+	return io.Env[key]
 }
 
 func (io *MockIoLib) FilePathAbs(path string) (string, error) {
@@ -194,6 +194,14 @@ func TestIoLibGetenv(t *testing.T) {
 	assert.Equal(t, "", e)
 }
 
+func TestIoLibGetenvValue(t *testing.T) {
+	i := &MockIoLib{Env: map[string]string{"fiware": "orion"}}
+
+	e := i.Getenv("fiware")
+
+	assert.Equal(t, "orion", e)
+}
+
 func TestIoLibFilePathAbs(t *testing.T) {
 	i := &MockIoLib{}
 
@@ -30,18 +30,24 @@ SOFTWARE.
 package helper
 
 import (
+	"bytes"
 	"io"
 	"os"
 )
 
 type MockIoutilLib struct {
-	CopyErr      error
-	ReadFullErr  error
-	ReadFullData []byte
-	WriteFileErr error
-	ReadFileErr  error
-	ReadFileData []byte
-	WriteSkip    bool
+	CopyErr       error
+	ReadFullErr   error
+	ReadFullData  []byte
+	WriteFileErr  error
+	AppendErr     error
+	AppendData    *bytes.Buffer
+	ReadFileErr   error
+	ReadFileData  []byte
+	WriteSkip     bool
+	OpenErr       error
+	CreateTempErr error
+	RenameErr     error
 }
 
 func (i *MockIoutilLib) Copy(dst io.Writer, src io.Reader) (int64, error) {
@@ -72,6 +78,23 @@ func (i *MockIoutilLib) WriteFile(filename string, data []byte, perm os.FileMode
 	return os.WriteFile(filename, data, perm)
 }
 
+func (i *MockIoutilLib) AppendFile(filename string, data []byte, perm os.FileMode) error {
+	if i.AppendErr != nil {
+		return i.AppendErr
+	}
+	if i.AppendData != nil {
+		_, err := i.AppendData.Write(data)
+		return err
+	}
+	f, err := os.OpenFile(filename, os.O_APPEND|os.O_CREATE|os.O_WRONLY, perm)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(data)
+	_ = f.Close()
+	return err
+}
+
 func (i *MockIoutilLib) ReadFile(filename string) ([]byte, error) {
 	if i.ReadFileErr != nil {
 		return nil, i.ReadFileErr
@@ -81,3 +104,28 @@ func (i *MockIoutilLib) ReadFile(filename string) ([]byte, error) {
 	}
 	return os.ReadFile(filename)
 }
+
+func (i *MockIoutilLib) Open(name string) (*os.File, error) {
+	if i.OpenErr != nil {
+		return nil, i.OpenErr
+	}
+	return os.Open(name)
+}
+
+func (i *MockIoutilLib) CreateTemp(dir, pattern string) (*os.File, error) {
+	if i.CreateTempErr != nil {
+		return nil, i.CreateTempErr
+	}
+	return os.CreateTemp(dir, pattern)
+}
+
+func (i *MockIoutilLib) Rename(oldpath, newpath string) error {
+	if i.RenameErr != nil {
+		return i.RenameErr
+	}
+	return os.Rename(oldpath, newpath)
+}
+
+func (i *MockIoutilLib) Remove(name string) error {
+	return os.Remove(name)
+}
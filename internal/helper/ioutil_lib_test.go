@@ -132,6 +132,37 @@ func TestIoutilLibWriteFileError(t *testing.T) {
 	}
 }
 
+func TestIoutilLibAppendFile(t *testing.T) {
+	i := &MockIoutilLib{}
+
+	err := i.AppendFile("", nil, fs.FileMode(os.O_RDONLY))
+
+	if assert.Error(t, err) {
+		assert.Equal(t, "open : no such file or directory", err.Error())
+	}
+}
+
+func TestIoutilLibAppendFileData(t *testing.T) {
+	buf := &bytes.Buffer{}
+	i := &MockIoutilLib{AppendData: buf}
+
+	err := i.AppendFile("", []byte("data"), fs.FileMode(os.O_RDONLY))
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, "data", buf.String())
+	}
+}
+
+func TestIoutilLibAppendFileError(t *testing.T) {
+	i := &MockIoutilLib{AppendErr: errors.New("AppendFile error")}
+
+	err := i.AppendFile("", nil, fs.FileMode(os.O_RDONLY))
+
+	if assert.Error(t, err) {
+		assert.Equal(t, "AppendFile error", err.Error())
+	}
+}
+
 func TestIoutilLibReadFile(t *testing.T) {
 	i := &MockIoutilLib{}
 
@@ -161,3 +192,60 @@ func TestIoutilLibReadFileData(t *testing.T) {
 		assert.Equal(t, "fiware", string(actual))
 	}
 }
+
+func TestIoutilLibOpen(t *testing.T) {
+	i := &MockIoutilLib{}
+	filename := t.TempDir() + "/open"
+	_ = os.WriteFile(filename, nil, 0600)
+
+	f, err := i.Open(filename)
+
+	if assert.NoError(t, err) {
+		_ = f.Close()
+	}
+}
+
+func TestIoutilLibOpenError(t *testing.T) {
+	i := &MockIoutilLib{OpenErr: errors.New("Open error")}
+
+	_, err := i.Open("")
+
+	if assert.Error(t, err) {
+		assert.Equal(t, "Open error", err.Error())
+	}
+}
+
+func TestIoutilLibCreateTemp(t *testing.T) {
+	i := &MockIoutilLib{}
+	dir := t.TempDir()
+
+	f, err := i.CreateTemp(dir, "archive.*.tmp")
+
+	if assert.NoError(t, err) {
+		_ = f.Close()
+		err = i.Rename(f.Name(), dir+"/archive.zip")
+		assert.NoError(t, err)
+		err = i.Remove(dir + "/archive.zip")
+		assert.NoError(t, err)
+	}
+}
+
+func TestIoutilLibCreateTempError(t *testing.T) {
+	i := &MockIoutilLib{CreateTempErr: errors.New("CreateTemp error")}
+
+	_, err := i.CreateTemp("", "")
+
+	if assert.Error(t, err) {
+		assert.Equal(t, "CreateTemp error", err.Error())
+	}
+}
+
+func TestIoutilLibRenameError(t *testing.T) {
+	i := &MockIoutilLib{RenameErr: errors.New("Rename error")}
+
+	err := i.Rename("", "")
+
+	if assert.Error(t, err) {
+		assert.Equal(t, "Rename error", err.Error())
+	}
+}
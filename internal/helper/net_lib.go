@@ -32,12 +32,16 @@ package helper
 import (
 	"net"
 	"net/http"
+	"time"
 )
 
 type MockNetLib struct {
 	AddrErr              error
 	ListenAndServeErr    error
 	ListenAndServeTLSErr error
+	DialErr              error
+	DialAddr             []string
+	ListenErr            error
 }
 
 func (n *MockNetLib) InterfaceAddrs() ([]net.Addr, error) {
@@ -54,3 +58,19 @@ func (n *MockNetLib) ListenAndServe(addr string, handler http.Handler) error {
 func (n *MockNetLib) ListenAndServeTLS(addr, certFile, keyFile string, handler http.Handler) error {
 	return n.ListenAndServeTLSErr
 }
+
+func (n *MockNetLib) DialTimeout(network, address string, timeout time.Duration) (net.Conn, error) {
+	n.DialAddr = append(n.DialAddr, address)
+	if n.DialErr != nil {
+		return nil, n.DialErr
+	}
+	conn, _ := net.Pipe()
+	return conn, nil
+}
+
+func (n *MockNetLib) Listen(network, address string) (net.Listener, error) {
+	if n.ListenErr != nil {
+		return nil, n.ListenErr
+	}
+	return net.Listen(network, address)
+}
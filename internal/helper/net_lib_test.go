@@ -33,6 +33,7 @@ import (
 	"errors"
 	"net"
 	"testing"
+	"time"
 
 	"github.com/lets-fiware/ngsi-go/internal/assert"
 )
@@ -77,3 +78,44 @@ func TestListenAndServeTLS(t *testing.T) {
 		assert.Equal(t, "ListenAndServeTLS error", err.Error())
 	}
 }
+
+func TestDialTimeout(t *testing.T) {
+	n := &MockNetLib{}
+
+	conn, err := n.DialTimeout("tcp", "localhost:1028", time.Second)
+
+	if assert.NoError(t, err) {
+		assert.NotEqual(t, nil, conn)
+		assert.Equal(t, []string{"localhost:1028"}, n.DialAddr)
+	}
+}
+
+func TestDialTimeoutError(t *testing.T) {
+	n := &MockNetLib{DialErr: errors.New("dial error")}
+
+	_, err := n.DialTimeout("tcp", "localhost:1028", time.Second)
+
+	if assert.Error(t, err) {
+		assert.Equal(t, "dial error", err.Error())
+	}
+}
+
+func TestListen(t *testing.T) {
+	n := &MockNetLib{}
+
+	l, err := n.Listen("tcp", "127.0.0.1:0")
+
+	if assert.NoError(t, err) {
+		l.Close()
+	}
+}
+
+func TestListenError(t *testing.T) {
+	n := &MockNetLib{ListenErr: errors.New("listen error")}
+
+	_, err := n.Listen("tcp", "127.0.0.1:0")
+
+	if assert.Error(t, err) {
+		assert.Equal(t, "listen error", err.Error())
+	}
+}
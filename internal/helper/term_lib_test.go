@@ -0,0 +1,63 @@
+/*
+MIT License
+
+Copyright (c) 2020-2024 Kazuhito Suda
+
+This file is part of NGSI Go
+
+https://github.com/lets-fiware/ngsi-go
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+
+*/
+
+package helper
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/lets-fiware/ngsi-go/internal/assert"
+)
+
+func TestMockTermLib(t *testing.T) {
+	term := &MockTermLib{Terminal: true}
+
+	assert.Equal(t, true, term.IsTerminal(0))
+
+	restore, err := term.MakeRaw(0)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, true, term.Raw)
+		restore()
+		assert.Equal(t, false, term.Raw)
+	}
+}
+
+func TestMockTermLibError(t *testing.T) {
+	term := &MockTermLib{RawErr: errors.New("raw error")}
+
+	assert.Equal(t, false, term.IsTerminal(0))
+
+	_, err := term.MakeRaw(0)
+
+	if assert.Error(t, err) {
+		assert.Equal(t, "raw error", err.Error())
+	}
+}
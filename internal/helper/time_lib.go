@@ -65,3 +65,7 @@ func (t *MockTimeLib) Format(layout string) string {
 	}
 	return t.TTime.Format(layout)
 }
+
+func (t *MockTimeLib) Sleep(d time.Duration) {
+	t.UnixTime += int64(d / time.Second)
+}
@@ -31,6 +31,7 @@ package helper
 
 import (
 	"testing"
+	"time"
 
 	"github.com/lets-fiware/ngsi-go/internal/assert"
 )
@@ -89,3 +90,11 @@ func TestTImeLibFormat(t *testing.T) {
 
 	assert.Equal(t, "2099-01-02T15:04:05.000Z", actual)
 }
+
+func TestTImeLibSleep(t *testing.T) {
+	s := MockTimeLib{UnixTime: 10}
+
+	s.Sleep(5 * time.Second)
+
+	assert.Equal(t, int64(15), s.UnixTime)
+}
@@ -40,6 +40,7 @@ import (
 type MockZipLib struct {
 	Zip       error
 	ZipReader *zip.Reader
+	WriteErr  error
 }
 
 func (z *MockZipLib) NewReader(r io.ReaderAt, size int64) (*zip.Reader, error) {
@@ -52,6 +53,21 @@ func (z *MockZipLib) NewReader(r io.ReaderAt, size int64) (*zip.Reader, error) {
 	return zip.NewReader(r, size)
 }
 
+func (z *MockZipLib) NewWriter(w io.Writer) *zip.Writer {
+	if z.WriteErr != nil {
+		return zip.NewWriter(&mockZipWriter{err: z.WriteErr})
+	}
+	return zip.NewWriter(w)
+}
+
+type mockZipWriter struct {
+	err error
+}
+
+func (w *mockZipWriter) Write(p []byte) (int, error) {
+	return 0, w.err
+}
+
 type MockMultiPart struct {
 	CreatePartErr error
 	CloseErr      error
@@ -31,6 +31,7 @@ package helper
 
 import (
 	"archive/zip"
+	"bytes"
 	"errors"
 	"testing"
 
@@ -81,6 +82,29 @@ func TestZipLibNewReaderErrorZip(t *testing.T) {
 	assert.Equal(t, "zip error", err.Error())
 }
 
+func TestZipLibNewZipWriter(t *testing.T) {
+	w := &bytes.Buffer{}
+	z := &MockZipLib{}
+
+	actual := z.NewWriter(w)
+
+	assert.NoError(t, actual.Close())
+}
+
+func TestZipLibNewZipWriterErrorWrite(t *testing.T) {
+	w := &mockIolib{}
+	z := &MockZipLib{WriteErr: errors.New("write error")}
+
+	actual := z.NewWriter(w)
+
+	_, _ = actual.Create("test")
+	err := actual.Close()
+
+	if assert.Error(t, err) {
+		assert.Equal(t, "write error", err.Error())
+	}
+}
+
 func TestZipLibNewWriter(t *testing.T) {
 	w := &mockIolib{}
 	m := &MockMultiPart{}
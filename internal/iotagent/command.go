@@ -68,6 +68,7 @@ var ServicesCmd = ngsicli.Command{
 				servicesOffsetFlag,
 				resourceFlag,
 				ngsicli.PrettyFlag,
+				ngsicli.OutputFlag,
 			},
 			Action: func(c *ngsicli.Context, ngsi *ngsilib.NGSI, client *ngsilib.Client) error {
 				return idasServicesList(c, ngsi, client)
@@ -145,6 +146,7 @@ var DevicesCmd = ngsicli.Command{
 				devicesEntity,
 				devicesProtocol,
 				ngsicli.PrettyFlag,
+				ngsicli.OutputFlag,
 			},
 			Action: func(c *ngsicli.Context, ngsi *ngsilib.NGSI, client *ngsilib.Client) error {
 				return idasDevicesList(c, ngsi, client)
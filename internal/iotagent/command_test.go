@@ -40,6 +40,7 @@ import (
 	"github.com/lets-fiware/ngsi-go/internal/helper"
 	"github.com/lets-fiware/ngsi-go/internal/ngsicli"
 	"github.com/lets-fiware/ngsi-go/internal/ngsierr"
+	"github.com/lets-fiware/ngsi-go/internal/ngsilib"
 )
 
 func TestNewNgsiApp(t *testing.T) {
@@ -89,3 +90,59 @@ func TestNGSICommand(t *testing.T) {
 		}
 	}
 }
+
+func TestListOutput(t *testing.T) {
+	cases := []struct {
+		args     []string
+		path     string
+		resBody  string
+		list     func(*ngsicli.Context, *ngsilib.NGSI, *ngsilib.Client) error
+		expected map[string]string
+	}{
+		{
+			args:    []string{"devices", "list", "--host", "iota"},
+			path:    "/iot/devices",
+			resBody: `{"count":1,"devices":[{"device_id":"sensor001","service":"openiot","service_path":"/","entity_name":"urn:ngsi-ld:WeatherObserved:sensor001","entity_type":"Sensor","transport":"HTTP","attributes":[{"object_id":"d","name":"dateObserved","type":"DateTime"},{"object_id":"t","name":"temperature","type":"Number"},{"object_id":"h","name":"relativeHumidity","type":"Number"},{"object_id":"p","name":"atmosphericPressure","type":"Number"}],"lazy":[],"commands":[],"static_attributes":[{"name":"location","type":"geo:json","value":{"type":"Point","coordinates":[139.7671,35.68117]}}],"explicitAttrs":false}]}`,
+			list:    idasDevicesList,
+			expected: map[string]string{
+				"csv": "device_id,service,service_path,entity_name,entity_type,protocol,transport\nsensor001,openiot,/,urn:ngsi-ld:WeatherObserved:sensor001,Sensor,,HTTP\n",
+				"tsv": "device_id\tservice\tservice_path\tentity_name\tentity_type\tprotocol\ttransport\nsensor001\topeniot\t/\turn:ngsi-ld:WeatherObserved:sensor001\tSensor\t\tHTTP\n",
+				"table": "" +
+					"device_id  service  service_path  entity_name                            entity_type  protocol  transport\n" +
+					"sensor001  openiot  /             urn:ngsi-ld:WeatherObserved:sensor001  Sensor                 HTTP\n",
+			},
+		},
+		{
+			args:    []string{"services", "list", "--host", "iota"},
+			path:    "/iot/services",
+			resBody: `{"count":1,"services":[{"commands":[],"lazy":[],"attributes":[],"_id":"601e25597d7b3d691be82d23","resource":"/iot/d","apikey":"apikey","service":"openiot","subservice":"/","__v":0,"static_attributes":[],"internal_attributes":[],"entity_type":"Event"}]}`,
+			list:    idasServicesList,
+			expected: map[string]string{
+				"csv": "apikey,resource,entity_type,service,subservice,cbroker\napikey,/iot/d,Event,openiot,/,\n",
+				"tsv": "apikey\tresource\tentity_type\tservice\tsubservice\tcbroker\napikey\t/iot/d\tEvent\topeniot\t/\t\n",
+				"table": "" +
+					"apikey  resource  entity_type  service  subservice  cbroker\n" +
+					"apikey  /iot/d    Event        openiot  /           \n",
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		for _, format := range ngsilib.TableFormats {
+			c := setupTest(append(tc.args, "--output", format))
+
+			reqRes := helper.MockHTTPReqRes{}
+			reqRes.Res.StatusCode = http.StatusOK
+			reqRes.Path = tc.path
+			reqRes.ResBody = []byte(tc.resBody)
+
+			helper.SetClientHTTP(c, reqRes)
+
+			err := tc.list(c, c.Ngsi, c.Client)
+
+			if assert.NoError(t, err) {
+				assert.Equal(t, tc.expected[format], helper.GetStdoutString(c))
+			}
+		}
+	}
+}
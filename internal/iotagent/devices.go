@@ -55,6 +55,13 @@ func idasDevicesList(c *ngsicli.Context, ngsi *ngsilib.NGSI, client *ngsilib.Cli
 		return ngsierr.New(funcName, 2, fmt.Sprintf("%s %s", res.Status, string(body)), nil)
 	}
 
+	if c.IsSet("output") {
+		if err := ngsilib.PrintJSONTable(ngsi.StdWriter, c.String("output"), body, "devices", []string{"device_id", "service", "service_path", "entity_name", "entity_type", "protocol", "transport"}); err != nil {
+			return ngsierr.New(funcName, 4, err.Error(), err)
+		}
+		return nil
+	}
+
 	if c.Bool("pretty") {
 		newBuf := new(bytes.Buffer)
 		err := ngsi.JSONConverter.Indent(newBuf, body, "", "  ")
@@ -137,6 +137,26 @@ func TestIdasDevicesListErrorPretty(t *testing.T) {
 	}
 }
 
+func TestIdasDevicesListErrorOutput(t *testing.T) {
+	c := setupTest([]string{"devices", "list", "--host", "iota", "--output", "csv"})
+	c.GetStringFlag("output").Value = "xml"
+
+	reqRes := helper.MockHTTPReqRes{}
+	reqRes.Res.StatusCode = http.StatusOK
+	reqRes.Path = "/iot/devices"
+	reqRes.ResBody = []byte(`{"count":1,"devices":[{"device_id":"sensor001","service":"openiot","service_path":"/","entity_name":"urn:ngsi-ld:WeatherObserved:sensor001","entity_type":"Sensor","transport":"HTTP","attributes":[{"object_id":"d","name":"dateObserved","type":"DateTime"},{"object_id":"t","name":"temperature","type":"Number"},{"object_id":"h","name":"relativeHumidity","type":"Number"},{"object_id":"p","name":"atmosphericPressure","type":"Number"}],"lazy":[],"commands":[],"static_attributes":[{"name":"location","type":"geo:json","value":{"type":"Point","coordinates":[139.7671,35.68117]}}],"explicitAttrs":false}]}`)
+
+	helper.SetClientHTTP(c, reqRes)
+
+	err := idasDevicesList(c, c.Ngsi, c.Client)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 4, ngsiErr.ErrNo)
+		assert.Equal(t, "unknown output format: xml (csv, tsv, table)", ngsiErr.Message)
+	}
+}
+
 func TestIdasDevicesGet(t *testing.T) {
 	c := setupTest([]string{"devices", "get", "--host", "iota", "--id", "sensor001"})
 
@@ -67,6 +67,13 @@ func idasServicesList(c *ngsicli.Context, ngsi *ngsilib.NGSI, client *ngsilib.Cl
 		return ngsierr.New(funcName, 2, fmt.Sprintf("%s %s", res.Status, string(body)), nil)
 	}
 
+	if c.IsSet("output") {
+		if err := ngsilib.PrintJSONTable(ngsi.StdWriter, c.String("output"), body, "services", []string{"apikey", "resource", "entity_type", "service", "subservice", "cbroker"}); err != nil {
+			return ngsierr.New(funcName, 4, err.Error(), err)
+		}
+		return nil
+	}
+
 	if c.Bool("pretty") {
 		newBuf := new(bytes.Buffer)
 		err := ngsi.JSONConverter.Indent(newBuf, body, "", "  ")
@@ -137,6 +137,26 @@ func TestIdasServicesListErrorPretty(t *testing.T) {
 	}
 }
 
+func TestIdasServicesListErrorOutput(t *testing.T) {
+	c := setupTest([]string{"services", "list", "--host", "iota", "--output", "csv"})
+	c.GetStringFlag("output").Value = "xml"
+
+	reqRes := helper.MockHTTPReqRes{}
+	reqRes.Res.StatusCode = http.StatusOK
+	reqRes.Path = "/iot/services"
+	reqRes.ResBody = []byte(`{"count":1,"services":[{"commands":[],"lazy":[],"attributes":[],"_id":"601e25597d7b3d691be82d23","resource":"/iot/d","apikey":"apikey","service":"openiot","subservice":"/","__v":0,"static_attributes":[],"internal_attributes":[],"entity_type":"Event"}]}`)
+
+	helper.SetClientHTTP(c, reqRes)
+
+	err := idasServicesList(c, c.Ngsi, c.Client)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 4, ngsiErr.ErrNo)
+		assert.Equal(t, "unknown output format: xml (csv, tsv, table)", ngsiErr.Message)
+	}
+}
+
 func TestIdasServicesCreateData(t *testing.T) {
 	data := `{"services":[{"apikey":"apikey","cbroker":"http://orion:1026","entity_type":"Thing","resource":"/iot/d"}]}`
 	c := setupTest([]string{"services", "create", "--host", "iota", "--data", data})
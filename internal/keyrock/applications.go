@@ -76,6 +76,13 @@ func applicationsList(c *ngsicli.Context, ngsi *ngsilib.NGSI, client *ngsilib.Cl
 		return ngsierr.New(funcName, 2, fmt.Sprintf("error %s %s", res.Status, string(body)), nil)
 	}
 
+	if c.IsSet("output") {
+		if err := ngsilib.PrintJSONTable(ngsi.StdWriter, c.String("output"), body, "applications", []string{"id", "name", "description", "url", "redirect_uri"}); err != nil {
+			return ngsierr.New(funcName, 5, err.Error(), err)
+		}
+		return nil
+	}
+
 	if c.Bool("verbose") || c.Bool("pretty") {
 		if c.Bool("pretty") {
 			newBuf := new(bytes.Buffer)
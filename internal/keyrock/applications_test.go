@@ -176,6 +176,26 @@ func TestApplicationsListErrorID(t *testing.T) {
 	}
 }
 
+func TestApplicationsListErrorOutput(t *testing.T) {
+	c := setupTest([]string{"applications", "list", "--host", "keyrock", "--output", "csv"})
+	c.GetStringFlag("output").Value = "xml"
+
+	reqRes := helper.MockHTTPReqRes{}
+	reqRes.Res.StatusCode = http.StatusOK
+	reqRes.Path = "/v1/applications"
+	reqRes.ResBody = []byte(`{"applications":[{"id":"0fbfa58c-e5b6-41c3-b748-ab29f1567a9c","name":"Test_application2","description":"Description","image":"default","url":"http://localhost","redirect_uri":"http://localhost/login","grant_type":"client_credentials,password,implicit,authorization_code,refresh_token","response_type":"code,token","token_types":"bearer,jwt,permanent","client_type":null},{"id":"fd7fe349-f7da-4c27-b404-74da17641025","name":"Test_application1","description":"description","image":"default","url":"http://localhost","redirect_uri":"http://localhost/login","grant_type":"password,authorization_code,implicit","response_type":"code,token","token_types":"bearer","client_type":null}]}`)
+
+	helper.SetClientHTTP(c, reqRes)
+
+	err := applicationsList(c, c.Ngsi, c.Client)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 5, ngsiErr.ErrNo)
+		assert.Equal(t, "unknown output format: xml (csv, tsv, table)", ngsiErr.Message)
+	}
+}
+
 func TestApplicationsGet(t *testing.T) {
 	c := setupTest([]string{"applications", "get", "--host", "keyrock", "--aid", "0fbfa58c-e5b6-41c3-b748-ab29f1567a9c"})
 
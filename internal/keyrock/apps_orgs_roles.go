@@ -62,6 +62,13 @@ func appsOrgsRolesList(c *ngsicli.Context, ngsi *ngsilib.NGSI, client *ngsilib.C
 		return ngsierr.New(funcName, 2, fmt.Sprintf("error %s %s", res.Status, string(body)), nil)
 	}
 
+	if c.IsSet("output") {
+		if err := ngsilib.PrintJSONTable(ngsi.StdWriter, c.String("output"), body, "role_organization_assignments", nil); err != nil {
+			return ngsierr.New(funcName, 5, err.Error(), err)
+		}
+		return nil
+	}
+
 	if c.Bool("verbose") || c.Bool("pretty") {
 		if c.Bool("pretty") {
 			newBuf := new(bytes.Buffer)
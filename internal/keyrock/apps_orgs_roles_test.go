@@ -177,6 +177,26 @@ func TestAppsOrgsRolesListErrorID(t *testing.T) {
 	}
 }
 
+func TestAppsOrgsRolesListErrorOutput(t *testing.T) {
+	c := setupTest([]string{"applications", "organizations", "list", "--host", "keyrock", "--aid", "0fbfa58c-e5b6-41c3-b748-ab29f1567a9c", "--output", "csv"})
+	c.GetStringFlag("output").Value = "xml"
+
+	reqRes := helper.MockHTTPReqRes{}
+	reqRes.Res.StatusCode = http.StatusOK
+	reqRes.Path = "/v1/applications/0fbfa58c-e5b6-41c3-b748-ab29f1567a9c/organizations"
+	reqRes.ResBody = []byte(`{"role_organization_assignments":[{"organization_id":"33cf4d3c-8dfb-4bed-bf37-7647f45528ec","role_organization":"owner","role_id":"purchaser"},{"organization_id":"33cf4d3c-8dfb-4bed-bf37-7647f45528ec","role_organization":"owner","role_id":"ee2ec16f-694b-447f-b61a-e293b6fe5f7b"},{"organization_id":"33cf4d3c-8dfb-4bed-bf37-7647f45528ec","role_organization":"member","role_id":"33fd15c0-e919-47b0-9e05-5f47999f6d91"},{"organization_id":"3e20722f-d420-422d-89ba-3ae87bc1c0cd","role_organization":"owner","role_id":"provider"},{"organization_id":"3e20722f-d420-422d-89ba-3ae87bc1c0cd","role_organization":"owner","role_id":"33fd15c0-e919-47b0-9e05-5f47999f6d91"},{"organization_id":"3e20722f-d420-422d-89ba-3ae87bc1c0cd","role_organization":"member","role_id":"ee2ec16f-694b-447f-b61a-e293b6fe5f7b"}]}`)
+
+	helper.SetClientHTTP(c, reqRes)
+
+	err := appsOrgsRolesList(c, c.Ngsi, c.Client)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 5, ngsiErr.ErrNo)
+		assert.Equal(t, "unknown output format: xml (csv, tsv, table)", ngsiErr.Message)
+	}
+}
+
 func TestAppsOrgsRolesGet(t *testing.T) {
 	c := setupTest([]string{"applications", "organizations", "get", "--host", "keyrock", "--aid", "0fbfa58c-e5b6-41c3-b748-ab29f1567a9c", "--oid", "33cf4d3c-8dfb-4bed-bf37-7647f45528ec"})
 
@@ -67,6 +67,7 @@ var ApplicationsCmd = ngsicli.Command{
 			Flags: []ngsicli.Flag{
 				ngsicli.VerboseFlag,
 				ngsicli.PrettyFlag,
+				ngsicli.OutputFlag,
 			},
 			Action: func(c *ngsicli.Context, ngsi *ngsilib.NGSI, client *ngsilib.Client) error {
 				return applicationsList(c, ngsi, client)
@@ -173,6 +174,7 @@ var appsOrgsCmd = ngsicli.Command{
 			Flags: []ngsicli.Flag{
 				ngsicli.VerboseFlag,
 				ngsicli.PrettyFlag,
+				ngsicli.OutputFlag,
 			},
 			RequiredFlags: []string{"aid"},
 			Action: func(c *ngsicli.Context, ngsi *ngsilib.NGSI, client *ngsilib.Client) error {
@@ -240,6 +242,7 @@ var iotAgentCmd = ngsicli.Command{
 			Flags: []ngsicli.Flag{
 				ngsicli.VerboseFlag,
 				ngsicli.PrettyFlag,
+				ngsicli.OutputFlag,
 			},
 			RequiredFlags: []string{"aid"},
 			Action: func(c *ngsicli.Context, ngsi *ngsilib.NGSI, client *ngsilib.Client) error {
@@ -318,6 +321,7 @@ var OrganizationsCmd = ngsicli.Command{
 			Flags: []ngsicli.Flag{
 				ngsicli.VerboseFlag,
 				ngsicli.PrettyFlag,
+				ngsicli.OutputFlag,
 			},
 			Action: func(c *ngsicli.Context, ngsi *ngsilib.NGSI, client *ngsilib.Client) error {
 				return organizationsList(c, ngsi, client)
@@ -400,6 +404,7 @@ var orgsUsersCmd = ngsicli.Command{
 			Flags: []ngsicli.Flag{
 				ngsicli.VerboseFlag,
 				ngsicli.PrettyFlag,
+				ngsicli.OutputFlag,
 			},
 			RequiredFlags: []string{"oid"},
 			Action: func(c *ngsicli.Context, ngsi *ngsilib.NGSI, client *ngsilib.Client) error {
@@ -465,6 +470,7 @@ var UsersCmd = ngsicli.Command{
 			Flags: []ngsicli.Flag{
 				ngsicli.VerboseFlag,
 				ngsicli.PrettyFlag,
+				ngsicli.OutputFlag,
 			},
 			Action: func(c *ngsicli.Context, ngsi *ngsilib.NGSI, client *ngsilib.Client) error {
 				return usersList(c, ngsi, client)
@@ -549,6 +555,7 @@ var rolesCmd = ngsicli.Command{
 			Flags: []ngsicli.Flag{
 				ngsicli.VerboseFlag,
 				ngsicli.PrettyFlag,
+				ngsicli.OutputFlag,
 			},
 			RequiredFlags: []string{"aid"},
 			Action: func(c *ngsicli.Context, ngsi *ngsilib.NGSI, client *ngsilib.Client) error {
@@ -620,6 +627,7 @@ var rolesCmd = ngsicli.Command{
 			Flags: []ngsicli.Flag{
 				keyrockRolesIDRFlag,
 				ngsicli.PrettyFlag,
+				ngsicli.OutputFlag,
 			},
 			RequiredFlags: []string{"aid", "rid"},
 			Action: func(c *ngsicli.Context, ngsi *ngsilib.NGSI, client *ngsilib.Client) error {
@@ -673,6 +681,7 @@ var permissionsCmd = ngsicli.Command{
 				keyrockPermissionIDFlag,
 				ngsicli.VerboseFlag,
 				ngsicli.PrettyFlag,
+				ngsicli.OutputFlag,
 			},
 			RequiredFlags: []string{"aid"},
 			Action: func(c *ngsicli.Context, ngsi *ngsilib.NGSI, client *ngsilib.Client) error {
@@ -762,6 +771,7 @@ var pepProxiesCmd = ngsicli.Command{
 			ServerList: []string{"keyrock"},
 			Flags: []ngsicli.Flag{
 				ngsicli.PrettyFlag,
+				ngsicli.OutputFlag,
 			},
 			Action: func(c *ngsicli.Context, ngsi *ngsilib.NGSI, client *ngsilib.Client) error {
 				return pepProxiesList(c, ngsi, client)
@@ -826,6 +836,7 @@ var appsUsersCmd = ngsicli.Command{
 			Flags: []ngsicli.Flag{
 				ngsicli.VerboseFlag,
 				ngsicli.PrettyFlag,
+				ngsicli.OutputFlag,
 			},
 			RequiredFlags: []string{"aid"},
 			Action: func(c *ngsicli.Context, ngsi *ngsilib.NGSI, client *ngsilib.Client) error {
@@ -891,6 +902,7 @@ var trustedAppsCmd = ngsicli.Command{
 			ServerList: []string{"keyrock"},
 			Flags: []ngsicli.Flag{
 				ngsicli.PrettyFlag,
+				ngsicli.OutputFlag,
 			},
 			RequiredFlags: []string{"aid"},
 			Action: func(c *ngsicli.Context, ngsi *ngsilib.NGSI, client *ngsilib.Client) error {
@@ -39,6 +39,7 @@ import (
 	"github.com/lets-fiware/ngsi-go/internal/helper"
 	"github.com/lets-fiware/ngsi-go/internal/ngsicli"
 	"github.com/lets-fiware/ngsi-go/internal/ngsierr"
+	"github.com/lets-fiware/ngsi-go/internal/ngsilib"
 )
 
 func TestNewNgsiApp(t *testing.T) {
@@ -179,3 +180,217 @@ func TestNGSICommand(t *testing.T) {
 		}
 	}
 }
+
+func TestListOutput(t *testing.T) {
+	cases := []struct {
+		args     []string
+		path     string
+		resBody  string
+		list     func(*ngsicli.Context, *ngsilib.NGSI, *ngsilib.Client) error
+		expected map[string]string
+	}{
+		{
+			args:    []string{"applications", "list", "--host", "keyrock"},
+			path:    "/v1/applications",
+			resBody: `{"applications":[{"id":"0fbfa58c-e5b6-41c3-b748-ab29f1567a9c","name":"Test_application2","description":"Description","image":"default","url":"http://localhost","redirect_uri":"http://localhost/login","grant_type":"client_credentials,password,implicit,authorization_code,refresh_token","response_type":"code,token","token_types":"bearer,jwt,permanent","client_type":null},{"id":"fd7fe349-f7da-4c27-b404-74da17641025","name":"Test_application1","description":"description","image":"default","url":"http://localhost","redirect_uri":"http://localhost/login","grant_type":"password,authorization_code,implicit","response_type":"code,token","token_types":"bearer","client_type":null}]}`,
+			list:    applicationsList,
+			expected: map[string]string{
+				"csv": "id,name,description,url,redirect_uri\n0fbfa58c-e5b6-41c3-b748-ab29f1567a9c,Test_application2,Description,http://localhost,http://localhost/login\nfd7fe349-f7da-4c27-b404-74da17641025,Test_application1,description,http://localhost,http://localhost/login\n",
+				"tsv": "id\tname\tdescription\turl\tredirect_uri\n0fbfa58c-e5b6-41c3-b748-ab29f1567a9c\tTest_application2\tDescription\thttp://localhost\thttp://localhost/login\nfd7fe349-f7da-4c27-b404-74da17641025\tTest_application1\tdescription\thttp://localhost\thttp://localhost/login\n",
+				"table": "" +
+					"id                                    name               description  url               redirect_uri\n" +
+					"0fbfa58c-e5b6-41c3-b748-ab29f1567a9c  Test_application2  Description  http://localhost  http://localhost/login\n" +
+					"fd7fe349-f7da-4c27-b404-74da17641025  Test_application1  description  http://localhost  http://localhost/login\n",
+			},
+		},
+		{
+			args:    []string{"applications", "organizations", "list", "--host", "keyrock", "--aid", "0fbfa58c-e5b6-41c3-b748-ab29f1567a9c"},
+			path:    "/v1/applications/0fbfa58c-e5b6-41c3-b748-ab29f1567a9c/organizations",
+			resBody: `{"role_organization_assignments":[{"organization_id":"33cf4d3c-8dfb-4bed-bf37-7647f45528ec","role_organization":"owner","role_id":"purchaser"},{"organization_id":"33cf4d3c-8dfb-4bed-bf37-7647f45528ec","role_organization":"owner","role_id":"ee2ec16f-694b-447f-b61a-e293b6fe5f7b"},{"organization_id":"33cf4d3c-8dfb-4bed-bf37-7647f45528ec","role_organization":"member","role_id":"33fd15c0-e919-47b0-9e05-5f47999f6d91"},{"organization_id":"3e20722f-d420-422d-89ba-3ae87bc1c0cd","role_organization":"owner","role_id":"provider"},{"organization_id":"3e20722f-d420-422d-89ba-3ae87bc1c0cd","role_organization":"owner","role_id":"33fd15c0-e919-47b0-9e05-5f47999f6d91"},{"organization_id":"3e20722f-d420-422d-89ba-3ae87bc1c0cd","role_organization":"member","role_id":"ee2ec16f-694b-447f-b61a-e293b6fe5f7b"}]}`,
+			list:    appsOrgsRolesList,
+			expected: map[string]string{
+				"csv": "organization_id,role_id,role_organization\n33cf4d3c-8dfb-4bed-bf37-7647f45528ec,purchaser,owner\n33cf4d3c-8dfb-4bed-bf37-7647f45528ec,ee2ec16f-694b-447f-b61a-e293b6fe5f7b,owner\n33cf4d3c-8dfb-4bed-bf37-7647f45528ec,33fd15c0-e919-47b0-9e05-5f47999f6d91,member\n3e20722f-d420-422d-89ba-3ae87bc1c0cd,provider,owner\n3e20722f-d420-422d-89ba-3ae87bc1c0cd,33fd15c0-e919-47b0-9e05-5f47999f6d91,owner\n3e20722f-d420-422d-89ba-3ae87bc1c0cd,ee2ec16f-694b-447f-b61a-e293b6fe5f7b,member\n",
+				"tsv": "organization_id\trole_id\trole_organization\n33cf4d3c-8dfb-4bed-bf37-7647f45528ec\tpurchaser\towner\n33cf4d3c-8dfb-4bed-bf37-7647f45528ec\tee2ec16f-694b-447f-b61a-e293b6fe5f7b\towner\n33cf4d3c-8dfb-4bed-bf37-7647f45528ec\t33fd15c0-e919-47b0-9e05-5f47999f6d91\tmember\n3e20722f-d420-422d-89ba-3ae87bc1c0cd\tprovider\towner\n3e20722f-d420-422d-89ba-3ae87bc1c0cd\t33fd15c0-e919-47b0-9e05-5f47999f6d91\towner\n3e20722f-d420-422d-89ba-3ae87bc1c0cd\tee2ec16f-694b-447f-b61a-e293b6fe5f7b\tmember\n",
+				"table": "" +
+					"organization_id                       role_id                               role_organization\n" +
+					"33cf4d3c-8dfb-4bed-bf37-7647f45528ec  purchaser                             owner\n" +
+					"33cf4d3c-8dfb-4bed-bf37-7647f45528ec  ee2ec16f-694b-447f-b61a-e293b6fe5f7b  owner\n" +
+					"33cf4d3c-8dfb-4bed-bf37-7647f45528ec  33fd15c0-e919-47b0-9e05-5f47999f6d91  member\n" +
+					"3e20722f-d420-422d-89ba-3ae87bc1c0cd  provider                              owner\n" +
+					"3e20722f-d420-422d-89ba-3ae87bc1c0cd  33fd15c0-e919-47b0-9e05-5f47999f6d91  owner\n" +
+					"3e20722f-d420-422d-89ba-3ae87bc1c0cd  ee2ec16f-694b-447f-b61a-e293b6fe5f7b  member\n",
+			},
+		},
+		{
+			args:    []string{"applications", "iota", "list", "--host", "keyrock", "--aid", "0fbfa58c-e5b6-41c3-b748-ab29f1567a9c"},
+			path:    "/v1/applications/0fbfa58c-e5b6-41c3-b748-ab29f1567a9c/iot_agents",
+			resBody: `{"iot_agents":[{"id":"iot_sensor_47886fa2-883e-4550-bb56-0138ae9862b7"},{"id":"iot_sensor_74df472c-0fd1-4b8a-8f13-c6848307bc7d"},{"id":"iot_sensor_f95fc041-7102-4be7-8948-37fb571afa89"}]}	`,
+			list:    iotAgentsList,
+			expected: map[string]string{
+				"csv": "id\niot_sensor_47886fa2-883e-4550-bb56-0138ae9862b7\niot_sensor_74df472c-0fd1-4b8a-8f13-c6848307bc7d\niot_sensor_f95fc041-7102-4be7-8948-37fb571afa89\n",
+				"tsv": "id\niot_sensor_47886fa2-883e-4550-bb56-0138ae9862b7\niot_sensor_74df472c-0fd1-4b8a-8f13-c6848307bc7d\niot_sensor_f95fc041-7102-4be7-8948-37fb571afa89\n",
+				"table": "" +
+					"id\n" +
+					"iot_sensor_47886fa2-883e-4550-bb56-0138ae9862b7\n" +
+					"iot_sensor_74df472c-0fd1-4b8a-8f13-c6848307bc7d\n" +
+					"iot_sensor_f95fc041-7102-4be7-8948-37fb571afa89\n",
+			},
+		},
+		{
+			args:    []string{"organizations", "list", "--host", "keyrock"},
+			path:    "/v1/organizations",
+			resBody: `{"organizations":[{"role":"owner","Organization":{"id":"33cf4d3c-8dfb-4bed-bf37-7647f45528ec","name":"Testorganization2","description":"description2","image":"default","website":null}},{"role":"owner","Organization":{"id":"3e20722f-d420-422d-89ba-3ae87bc1c0cd","name":"Testorganization","description":"description","image":"default","website":null}}]}`,
+			list:    organizationsList,
+			expected: map[string]string{
+				"csv": "Organization.id,Organization.name,Organization.description,role\n33cf4d3c-8dfb-4bed-bf37-7647f45528ec,Testorganization2,description2,owner\n3e20722f-d420-422d-89ba-3ae87bc1c0cd,Testorganization,description,owner\n",
+				"tsv": "Organization.id\tOrganization.name\tOrganization.description\trole\n33cf4d3c-8dfb-4bed-bf37-7647f45528ec\tTestorganization2\tdescription2\towner\n3e20722f-d420-422d-89ba-3ae87bc1c0cd\tTestorganization\tdescription\towner\n",
+				"table": "" +
+					"Organization.id                       Organization.name  Organization.description  role\n" +
+					"33cf4d3c-8dfb-4bed-bf37-7647f45528ec  Testorganization2  description2              owner\n" +
+					"3e20722f-d420-422d-89ba-3ae87bc1c0cd  Testorganization   description               owner\n",
+			},
+		},
+		{
+			args:    []string{"organizations", "users", "list", "--host", "keyrock", "--oid", "3e20722f-d420-422d-89ba-3ae87bc1c0cd"},
+			path:    "/v1/organizations/3e20722f-d420-422d-89ba-3ae87bc1c0cd/users",
+			resBody: `{"organization_users":[{"user_id":"2d6f5391-6130-48d8-a9d0-01f20699a7eb","organization_id":"3e20722f-d420-422d-89ba-3ae87bc1c0cd","role":"owner"},{"user_id":"admin","organization_id":"3e20722f-d420-422d-89ba-3ae87bc1c0cd","role":"member"}]}`,
+			list:    orgUsersList,
+			expected: map[string]string{
+				"csv": "organization_id,role,user_id\n3e20722f-d420-422d-89ba-3ae87bc1c0cd,owner,2d6f5391-6130-48d8-a9d0-01f20699a7eb\n3e20722f-d420-422d-89ba-3ae87bc1c0cd,member,admin\n",
+				"tsv": "organization_id\trole\tuser_id\n3e20722f-d420-422d-89ba-3ae87bc1c0cd\towner\t2d6f5391-6130-48d8-a9d0-01f20699a7eb\n3e20722f-d420-422d-89ba-3ae87bc1c0cd\tmember\tadmin\n",
+				"table": "" +
+					"organization_id                       role    user_id\n" +
+					"3e20722f-d420-422d-89ba-3ae87bc1c0cd  owner   2d6f5391-6130-48d8-a9d0-01f20699a7eb\n" +
+					"3e20722f-d420-422d-89ba-3ae87bc1c0cd  member  admin\n",
+			},
+		},
+		{
+			args:    []string{"applications", "pep", "list", "--host", "keyrock", "--aid", "0fbfa58c-e5b6-41c3-b748-ab29f1567a9c"},
+			path:    "/v1/applications/0fbfa58c-e5b6-41c3-b748-ab29f1567a9c/pep_proxies",
+			resBody: `{"pep_proxy":{"id":"pep_proxy_2d19d297-e555-4e3a-a18d-22deda37036a","oauth_client_id":"fd7fe349-f7da-4c27-b404-74da17641025"}}`,
+			list:    pepProxiesList,
+			expected: map[string]string{
+				"csv": "id,oauth_client_id\npep_proxy_2d19d297-e555-4e3a-a18d-22deda37036a,fd7fe349-f7da-4c27-b404-74da17641025\n",
+				"tsv": "id\toauth_client_id\npep_proxy_2d19d297-e555-4e3a-a18d-22deda37036a\tfd7fe349-f7da-4c27-b404-74da17641025\n",
+				"table": "" +
+					"id                                              oauth_client_id\n" +
+					"pep_proxy_2d19d297-e555-4e3a-a18d-22deda37036a  fd7fe349-f7da-4c27-b404-74da17641025\n",
+			},
+		},
+		{
+			args:    []string{"applications", "permissions", "list", "--host", "keyrock", "--aid", "0fbfa58c-e5b6-41c3-b748-ab29f1567a9c"},
+			path:    "/v1/applications/0fbfa58c-e5b6-41c3-b748-ab29f1567a9c/permissions",
+			resBody: `{"permissions":[{"id":"6","name":"Getandassignonlypublicownedroles","description":null,"action":null,"resource":null,"xml":null},{"id":"5","name":"Getandassignallpublicapplicationroles","description":null,"action":null,"resource":null,"xml":null},{"id":"4","name":"Manageauthorizations","description":null,"action":null,"resource":null,"xml":null},{"id":"3","name":"Manageroles","description":null,"action":null,"resource":null,"xml":null},{"id":"2","name":"Managetheapplication","description":null,"action":null,"resource":null,"xml":null},{"id":"33fd15c0-e919-47b0-9e05-5f47999f6d91","name":"permission1","description":null,"action":"GET","resource":"login","xml":null},{"id":"1","name":"Getandassignallinternalapplicationroles","description":null,"action":null,"resource":null,"xml":null}]}`,
+			list:    permissionsList,
+			expected: map[string]string{
+				"csv": "id,name,action,resource\n6,Getandassignonlypublicownedroles,,\n5,Getandassignallpublicapplicationroles,,\n4,Manageauthorizations,,\n3,Manageroles,,\n2,Managetheapplication,,\n33fd15c0-e919-47b0-9e05-5f47999f6d91,permission1,GET,login\n1,Getandassignallinternalapplicationroles,,\n",
+				"tsv": "id\tname\taction\tresource\n6\tGetandassignonlypublicownedroles\t\t\n5\tGetandassignallpublicapplicationroles\t\t\n4\tManageauthorizations\t\t\n3\tManageroles\t\t\n2\tManagetheapplication\t\t\n33fd15c0-e919-47b0-9e05-5f47999f6d91\tpermission1\tGET\tlogin\n1\tGetandassignallinternalapplicationroles\t\t\n",
+				"table": "" +
+					"id                                    name                                     action  resource\n" +
+					"6                                     Getandassignonlypublicownedroles                 \n" +
+					"5                                     Getandassignallpublicapplicationroles            \n" +
+					"4                                     Manageauthorizations                             \n" +
+					"3                                     Manageroles                                      \n" +
+					"2                                     Managetheapplication                             \n" +
+					"33fd15c0-e919-47b0-9e05-5f47999f6d91  permission1                              GET     login\n" +
+					"1                                     Getandassignallinternalapplicationroles          \n",
+			},
+		},
+		{
+			args:    []string{"applications", "roles", "permissions", "--host", "keyrock", "--aid", "0fbfa58c-e5b6-41c3-b748-ab29f1567a9c", "--rid", "33fd15c0-e919-47b0-9e05-5f47999f6d91"},
+			path:    "/v1/applications/0fbfa58c-e5b6-41c3-b748-ab29f1567a9c/roles/33fd15c0-e919-47b0-9e05-5f47999f6d91/permissions",
+			resBody: `{"role_permission_assignments":[{"id":"15ca810b-27d1-44a1-8491-a3fb4b6bc6f3","is_internal":false,"name":"newnamepermission","description":"newdescriptionpermission","action":null,"resource":null,"xml":"xmlrule"},{"id":"3","is_internal":true,"name":"Manageroles","description":null,"action":null,"resource":null,"xml":null},{"id":"1","is_internal":true,"name":"Getandassignallinternalapplicationroles","description":null,"action":null,"resource":null,"xml":null}]}`,
+			list:    appsRolePermList,
+			expected: map[string]string{
+				"csv": "id,action,description,is_internal,name,resource,xml\n15ca810b-27d1-44a1-8491-a3fb4b6bc6f3,,newdescriptionpermission,false,newnamepermission,,xmlrule\n3,,,true,Manageroles,,\n1,,,true,Getandassignallinternalapplicationroles,,\n",
+				"tsv": "id\taction\tdescription\tis_internal\tname\tresource\txml\n15ca810b-27d1-44a1-8491-a3fb4b6bc6f3\t\tnewdescriptionpermission\tfalse\tnewnamepermission\t\txmlrule\n3\t\t\ttrue\tManageroles\t\t\n1\t\t\ttrue\tGetandassignallinternalapplicationroles\t\t\n",
+				"table": "" +
+					"id                                    action  description               is_internal  name                                     resource  xml\n" +
+					"15ca810b-27d1-44a1-8491-a3fb4b6bc6f3          newdescriptionpermission  false        newnamepermission                                  xmlrule\n" +
+					"3                                                                       true         Manageroles                                        \n" +
+					"1                                                                       true         Getandassignallinternalapplicationroles            \n",
+			},
+		},
+		{
+			args:    []string{"applications", "users", "list", "--host", "keyrock", "--aid", "0fbfa58c-e5b6-41c3-b748-ab29f1567a9c"},
+			path:    "/v1/applications/0fbfa58c-e5b6-41c3-b748-ab29f1567a9c/users",
+			resBody: `{"role_user_assignments":[{"user_id":"2d6f5391-6130-48d8-a9d0-01f20699a7eb","role_id":"provider"},{"user_id":"admin","role_id":"purchaser"},{"user_id":"2d6f5391-6130-48d8-a9d0-01f20699a7eb","role_id":"ee2ec16f-694b-447f-b61a-e293b6fe5f7b"},{"user_id":"admin","role_id":"ee2ec16f-694b-447f-b61a-e293b6fe5f7b"}]} `,
+			list:    appsUsersList,
+			expected: map[string]string{
+				"csv": "role_id,user_id\nprovider,2d6f5391-6130-48d8-a9d0-01f20699a7eb\npurchaser,admin\nee2ec16f-694b-447f-b61a-e293b6fe5f7b,2d6f5391-6130-48d8-a9d0-01f20699a7eb\nee2ec16f-694b-447f-b61a-e293b6fe5f7b,admin\n",
+				"tsv": "role_id\tuser_id\nprovider\t2d6f5391-6130-48d8-a9d0-01f20699a7eb\npurchaser\tadmin\nee2ec16f-694b-447f-b61a-e293b6fe5f7b\t2d6f5391-6130-48d8-a9d0-01f20699a7eb\nee2ec16f-694b-447f-b61a-e293b6fe5f7b\tadmin\n",
+				"table": "" +
+					"role_id                               user_id\n" +
+					"provider                              2d6f5391-6130-48d8-a9d0-01f20699a7eb\n" +
+					"purchaser                             admin\n" +
+					"ee2ec16f-694b-447f-b61a-e293b6fe5f7b  2d6f5391-6130-48d8-a9d0-01f20699a7eb\n" +
+					"ee2ec16f-694b-447f-b61a-e293b6fe5f7b  admin\n",
+			},
+		},
+		{
+			args:    []string{"applications", "roles", "list", "--host", "keyrock", "--aid", "0fbfa58c-e5b6-41c3-b748-ab29f1567a9c"},
+			path:    "/v1/applications/0fbfa58c-e5b6-41c3-b748-ab29f1567a9c/roles",
+			resBody: `{"roles":[{"id":"purchaser","name":"Purchaser"},{"id":"provider","name":"Provider"},{"id":"ee2ec16f-694b-447f-b61a-e293b6fe5f7b","name":"role2"},{"id":"33fd15c0-e919-47b0-9e05-5f47999f6d91","name":"role1"}]}`,
+			list:    rolesList,
+			expected: map[string]string{
+				"csv": "id,name\npurchaser,Purchaser\nprovider,Provider\nee2ec16f-694b-447f-b61a-e293b6fe5f7b,role2\n33fd15c0-e919-47b0-9e05-5f47999f6d91,role1\n",
+				"tsv": "id\tname\npurchaser\tPurchaser\nprovider\tProvider\nee2ec16f-694b-447f-b61a-e293b6fe5f7b\trole2\n33fd15c0-e919-47b0-9e05-5f47999f6d91\trole1\n",
+				"table": "" +
+					"id                                    name\n" +
+					"purchaser                             Purchaser\n" +
+					"provider                              Provider\n" +
+					"ee2ec16f-694b-447f-b61a-e293b6fe5f7b  role2\n" +
+					"33fd15c0-e919-47b0-9e05-5f47999f6d91  role1\n",
+			},
+		},
+		{
+			args:    []string{"applications", "trusted", "list", "--host", "keyrock", "--aid", "0fbfa58c-e5b6-41c3-b748-ab29f1567a9c"},
+			path:    "/v1/applications/0fbfa58c-e5b6-41c3-b748-ab29f1567a9c/trusted_applications",
+			resBody: `{"trusted_applications":["8692ec57-8514-4ef6-a347-3d1ac6409f79","78b4763f-139a-4820-a42b-3e265fb9d56e","462ee067-f10a-4c9c-aefe-079038830043","6781fd6c-9dd3-46d7-bdcc-4ca2af1ae42d"]}`,
+			list:    trustedAppList,
+			expected: map[string]string{
+				"csv": "id\n8692ec57-8514-4ef6-a347-3d1ac6409f79\n78b4763f-139a-4820-a42b-3e265fb9d56e\n462ee067-f10a-4c9c-aefe-079038830043\n6781fd6c-9dd3-46d7-bdcc-4ca2af1ae42d\n",
+				"tsv": "id\n8692ec57-8514-4ef6-a347-3d1ac6409f79\n78b4763f-139a-4820-a42b-3e265fb9d56e\n462ee067-f10a-4c9c-aefe-079038830043\n6781fd6c-9dd3-46d7-bdcc-4ca2af1ae42d\n",
+				"table": "" +
+					"id\n" +
+					"8692ec57-8514-4ef6-a347-3d1ac6409f79\n" +
+					"78b4763f-139a-4820-a42b-3e265fb9d56e\n" +
+					"462ee067-f10a-4c9c-aefe-079038830043\n" +
+					"6781fd6c-9dd3-46d7-bdcc-4ca2af1ae42d\n",
+			},
+		},
+		{
+			args:    []string{"users", "list", "--host", "keyrock"},
+			path:    "/v1/users",
+			resBody: `{"users":[{"id":"2d6f5391-6130-48d8-a9d0-01f20699a7eb","username":"alice","email":"alice@test.com","enabled":true,"gravatar":false,"date_password":"2018-03-20T09:31:07.000Z","description":null,"website":null},{"id":"admin","username":"admin","email":"admin@test.com","enabled":true,"gravatar":false,"date_password":"2018-03-20T08:40:14.000Z","description":null,"website":null}]}`,
+			list:    usersList,
+			expected: map[string]string{
+				"csv": "id,username,email,enabled,admin\n2d6f5391-6130-48d8-a9d0-01f20699a7eb,alice,alice@test.com,true,\nadmin,admin,admin@test.com,true,\n",
+				"tsv": "id\tusername\temail\tenabled\tadmin\n2d6f5391-6130-48d8-a9d0-01f20699a7eb\talice\talice@test.com\ttrue\t\nadmin\tadmin\tadmin@test.com\ttrue\t\n",
+				"table": "" +
+					"id                                    username  email           enabled  admin\n" +
+					"2d6f5391-6130-48d8-a9d0-01f20699a7eb  alice     alice@test.com  true     \n" +
+					"admin                                 admin     admin@test.com  true     \n",
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		for _, format := range ngsilib.TableFormats {
+			c := setupTest(append(tc.args, "--output", format))
+
+			reqRes := helper.MockHTTPReqRes{}
+			reqRes.Res.StatusCode = http.StatusOK
+			reqRes.Path = tc.path
+			reqRes.ResBody = []byte(tc.resBody)
+
+			helper.SetClientHTTP(c, reqRes)
+
+			err := tc.list(c, c.Ngsi, c.Client)
+
+			if assert.NoError(t, err) {
+				assert.Equal(t, tc.expected[format], helper.GetStdoutString(c))
+			}
+		}
+	}
+}
@@ -195,6 +195,26 @@ func TestIotAgentsListErrorID(t *testing.T) {
 	}
 }
 
+func TestIotAgentsListErrorOutput(t *testing.T) {
+	c := setupTest([]string{"applications", "iota", "list", "--host", "keyrock", "--aid", "0fbfa58c-e5b6-41c3-b748-ab29f1567a9c", "--output", "csv"})
+	c.GetStringFlag("output").Value = "xml"
+
+	reqRes := helper.MockHTTPReqRes{}
+	reqRes.Res.StatusCode = http.StatusOK
+	reqRes.Path = "/v1/applications/0fbfa58c-e5b6-41c3-b748-ab29f1567a9c/iot_agents"
+	reqRes.ResBody = []byte(`{"iot_agents":[{"id":"iot_sensor_47886fa2-883e-4550-bb56-0138ae9862b7"},{"id":"iot_sensor_74df472c-0fd1-4b8a-8f13-c6848307bc7d"},{"id":"iot_sensor_f95fc041-7102-4be7-8948-37fb571afa89"}]}	`)
+
+	helper.SetClientHTTP(c, reqRes)
+
+	err := iotAgentsList(c, c.Ngsi, c.Client)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 5, ngsiErr.ErrNo)
+		assert.Equal(t, "unknown output format: xml (csv, tsv, table)", ngsiErr.Message)
+	}
+}
+
 func TestIotAgentssGet(t *testing.T) {
 	c := setupTest([]string{"applications", "iota", "get", "--host", "keyrock", "--aid", "0fbfa58c-e5b6-41c3-b748-ab29f1567a9c", "--iid", "iot_sensor_47886fa2-883e-4550-bb56-0138ae9862b7"})
 
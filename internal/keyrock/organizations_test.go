@@ -193,6 +193,26 @@ func TestOrganizationsListErrorID(t *testing.T) {
 	}
 }
 
+func TestOrganizationsListErrorOutput(t *testing.T) {
+	c := setupTest([]string{"organizations", "list", "--host", "keyrock", "--output", "csv"})
+	c.GetStringFlag("output").Value = "xml"
+
+	reqRes := helper.MockHTTPReqRes{}
+	reqRes.Res.StatusCode = http.StatusOK
+	reqRes.Path = "/v1/organizations"
+	reqRes.ResBody = []byte(`{"organizations":[{"role":"owner","Organization":{"id":"33cf4d3c-8dfb-4bed-bf37-7647f45528ec","name":"Testorganization2","description":"description2","image":"default","website":null}},{"role":"owner","Organization":{"id":"3e20722f-d420-422d-89ba-3ae87bc1c0cd","name":"Testorganization","description":"description","image":"default","website":null}}]}`)
+
+	helper.SetClientHTTP(c, reqRes)
+
+	err := organizationsList(c, c.Ngsi, c.Client)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 5, ngsiErr.ErrNo)
+		assert.Equal(t, "unknown output format: xml (csv, tsv, table)", ngsiErr.Message)
+	}
+}
+
 func TestOrganizationsGet(t *testing.T) {
 	c := setupTest([]string{"organizations", "get", "--host", "keyrock", "--oid", "3e20722f-d420-422d-89ba-3ae87bc1c0cd"})
 
@@ -175,6 +175,26 @@ func TestOrgUsersListErrorID(t *testing.T) {
 	}
 }
 
+func TestOrgUsersListErrorOutput(t *testing.T) {
+	c := setupTest([]string{"organizations", "users", "list", "--host", "keyrock", "--oid", "3e20722f-d420-422d-89ba-3ae87bc1c0cd", "--output", "csv"})
+	c.GetStringFlag("output").Value = "xml"
+
+	reqRes := helper.MockHTTPReqRes{}
+	reqRes.Res.StatusCode = http.StatusOK
+	reqRes.Path = "/v1/organizations/3e20722f-d420-422d-89ba-3ae87bc1c0cd/users"
+	reqRes.ResBody = []byte(`{"organization_users":[{"user_id":"2d6f5391-6130-48d8-a9d0-01f20699a7eb","organization_id":"3e20722f-d420-422d-89ba-3ae87bc1c0cd","role":"owner"},{"user_id":"admin","organization_id":"3e20722f-d420-422d-89ba-3ae87bc1c0cd","role":"member"}]}`)
+
+	helper.SetClientHTTP(c, reqRes)
+
+	err := orgUsersList(c, c.Ngsi, c.Client)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 5, ngsiErr.ErrNo)
+		assert.Equal(t, "unknown output format: xml (csv, tsv, table)", ngsiErr.Message)
+	}
+}
+
 func TestOrgUsersGet(t *testing.T) {
 	c := setupTest([]string{"organizations", "users", "get", "--host", "keyrock", "--oid", "3e20722f-d420-422d-89ba-3ae87bc1c0cd", "--uid", "admin"})
 
@@ -155,6 +155,26 @@ func TestPepProxiesListErrorPretty(t *testing.T) {
 	}
 }
 
+func TestPepProxiesListErrorOutput(t *testing.T) {
+	c := setupTest([]string{"applications", "pep", "list", "--host", "keyrock", "--aid", "0fbfa58c-e5b6-41c3-b748-ab29f1567a9c", "--output", "csv"})
+	c.GetStringFlag("output").Value = "xml"
+
+	reqRes := helper.MockHTTPReqRes{}
+	reqRes.Res.StatusCode = http.StatusOK
+	reqRes.Path = "/v1/applications/0fbfa58c-e5b6-41c3-b748-ab29f1567a9c/pep_proxies"
+	reqRes.ResBody = []byte(`{"pep_proxy":{"id":"pep_proxy_2d19d297-e555-4e3a-a18d-22deda37036a","oauth_client_id":"fd7fe349-f7da-4c27-b404-74da17641025"}}`)
+
+	helper.SetClientHTTP(c, reqRes)
+
+	err := pepProxiesList(c, c.Ngsi, c.Client)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 4, ngsiErr.ErrNo)
+		assert.Equal(t, "unknown output format: xml (csv, tsv, table)", ngsiErr.Message)
+	}
+}
+
 func TestPepProxiesCreate(t *testing.T) {
 	c := setupTest([]string{"applications", "pep", "create", "--host", "keyrock", "--aid", "0fbfa58c-e5b6-41c3-b748-ab29f1567a9c", "--run"})
 
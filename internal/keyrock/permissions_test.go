@@ -176,6 +176,26 @@ func TestPermissionsListErrorID(t *testing.T) {
 	}
 }
 
+func TestPermissionsListErrorOutput(t *testing.T) {
+	c := setupTest([]string{"applications", "permissions", "list", "--host", "keyrock", "--aid", "0fbfa58c-e5b6-41c3-b748-ab29f1567a9c", "--output", "csv"})
+	c.GetStringFlag("output").Value = "xml"
+
+	reqRes := helper.MockHTTPReqRes{}
+	reqRes.Res.StatusCode = http.StatusOK
+	reqRes.Path = "/v1/applications/0fbfa58c-e5b6-41c3-b748-ab29f1567a9c/permissions"
+	reqRes.ResBody = []byte(`{"permissions":[{"id":"6","name":"Getandassignonlypublicownedroles","description":null,"action":null,"resource":null,"xml":null},{"id":"5","name":"Getandassignallpublicapplicationroles","description":null,"action":null,"resource":null,"xml":null},{"id":"4","name":"Manageauthorizations","description":null,"action":null,"resource":null,"xml":null},{"id":"3","name":"Manageroles","description":null,"action":null,"resource":null,"xml":null},{"id":"2","name":"Managetheapplication","description":null,"action":null,"resource":null,"xml":null},{"id":"33fd15c0-e919-47b0-9e05-5f47999f6d91","name":"permission1","description":null,"action":"GET","resource":"login","xml":null},{"id":"1","name":"Getandassignallinternalapplicationroles","description":null,"action":null,"resource":null,"xml":null}]}`)
+
+	helper.SetClientHTTP(c, reqRes)
+
+	err := permissionsList(c, c.Ngsi, c.Client)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 5, ngsiErr.ErrNo)
+		assert.Equal(t, "unknown output format: xml (csv, tsv, table)", ngsiErr.Message)
+	}
+}
+
 func TestPermissionsGet(t *testing.T) {
 	c := setupTest([]string{"applications", "permissions", "get", "--host", "keyrock", "--aid", "0fbfa58c-e5b6-41c3-b748-ab29f1567a9c", "--pid", "33fd15c0-e919-47b0-9e05-5f47999f6d91"})
 
@@ -56,6 +56,13 @@ func appsRolePermList(c *ngsicli.Context, ngsi *ngsilib.NGSI, client *ngsilib.Cl
 		return ngsierr.New(funcName, 2, fmt.Sprintf("error %s %s", res.Status, string(body)), nil)
 	}
 
+	if c.IsSet("output") {
+		if err := ngsilib.PrintJSONTable(ngsi.StdWriter, c.String("output"), body, "role_permission_assignments", nil); err != nil {
+			return ngsierr.New(funcName, 4, err.Error(), err)
+		}
+		return nil
+	}
+
 	if c.Bool("pretty") {
 		newBuf := new(bytes.Buffer)
 		err := ngsi.JSONConverter.Indent(newBuf, body, "", "  ")
@@ -156,6 +156,26 @@ func TestAppsRolePermListErrorPretty(t *testing.T) {
 	}
 }
 
+func TestAppsRolePermListErrorOutput(t *testing.T) {
+	c := setupTest([]string{"applications", "roles", "permissions", "--host", "keyrock", "--aid", "0fbfa58c-e5b6-41c3-b748-ab29f1567a9c", "--rid", "33fd15c0-e919-47b0-9e05-5f47999f6d91", "--output", "csv"})
+	c.GetStringFlag("output").Value = "xml"
+
+	reqRes := helper.MockHTTPReqRes{}
+	reqRes.Res.StatusCode = http.StatusOK
+	reqRes.Path = "/v1/applications/0fbfa58c-e5b6-41c3-b748-ab29f1567a9c/roles/33fd15c0-e919-47b0-9e05-5f47999f6d91/permissions"
+	reqRes.ResBody = []byte(`{"role_permission_assignments":[{"id":"15ca810b-27d1-44a1-8491-a3fb4b6bc6f3","is_internal":false,"name":"newnamepermission","description":"newdescriptionpermission","action":null,"resource":null,"xml":"xmlrule"},{"id":"3","is_internal":true,"name":"Manageroles","description":null,"action":null,"resource":null,"xml":null},{"id":"1","is_internal":true,"name":"Getandassignallinternalapplicationroles","description":null,"action":null,"resource":null,"xml":null}]}`)
+
+	helper.SetClientHTTP(c, reqRes)
+
+	err := appsRolePermList(c, c.Ngsi, c.Client)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 4, ngsiErr.ErrNo)
+		assert.Equal(t, "unknown output format: xml (csv, tsv, table)", ngsiErr.Message)
+	}
+}
+
 func TestAppsRolePermAssign(t *testing.T) {
 	c := setupTest([]string{"applications", "roles", "assign", "--host", "keyrock", "--aid", "0fbfa58c-e5b6-41c3-b748-ab29f1567a9c", "--rid", "33fd15c0-e919-47b0-9e05-5f47999f6d91", "--pid", "0118ccb7-756e-42f9-8a19-5b4e83ca8c46"})
 
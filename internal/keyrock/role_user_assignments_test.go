@@ -177,6 +177,26 @@ func TestAppsUsersListErrorID(t *testing.T) {
 	}
 }
 
+func TestAppsUsersListErrorOutput(t *testing.T) {
+	c := setupTest([]string{"applications", "users", "list", "--host", "keyrock", "--aid", "0fbfa58c-e5b6-41c3-b748-ab29f1567a9c", "--output", "csv"})
+	c.GetStringFlag("output").Value = "xml"
+
+	reqRes := helper.MockHTTPReqRes{}
+	reqRes.Res.StatusCode = http.StatusOK
+	reqRes.Path = "/v1/applications/0fbfa58c-e5b6-41c3-b748-ab29f1567a9c/users"
+	reqRes.ResBody = []byte(`{"role_user_assignments":[{"user_id":"2d6f5391-6130-48d8-a9d0-01f20699a7eb","role_id":"provider"},{"user_id":"admin","role_id":"purchaser"},{"user_id":"2d6f5391-6130-48d8-a9d0-01f20699a7eb","role_id":"ee2ec16f-694b-447f-b61a-e293b6fe5f7b"},{"user_id":"admin","role_id":"ee2ec16f-694b-447f-b61a-e293b6fe5f7b"}]} `)
+
+	helper.SetClientHTTP(c, reqRes)
+
+	err := appsUsersList(c, c.Ngsi, c.Client)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 5, ngsiErr.ErrNo)
+		assert.Equal(t, "unknown output format: xml (csv, tsv, table)", ngsiErr.Message)
+	}
+}
+
 func TestAppsUsersGet(t *testing.T) {
 	c := setupTest([]string{"applications", "users", "get", "--host", "keyrock", "--aid", "0fbfa58c-e5b6-41c3-b748-ab29f1567a9c", "--uid", "2d6f5391-6130-48d8-a9d0-01f20699a7eb"})
 
@@ -177,6 +177,26 @@ func TestRolesListErrorID(t *testing.T) {
 	}
 }
 
+func TestRolesListErrorOutput(t *testing.T) {
+	c := setupTest([]string{"applications", "roles", "list", "--host", "keyrock", "--aid", "0fbfa58c-e5b6-41c3-b748-ab29f1567a9c", "--output", "csv"})
+	c.GetStringFlag("output").Value = "xml"
+
+	reqRes := helper.MockHTTPReqRes{}
+	reqRes.Res.StatusCode = http.StatusOK
+	reqRes.Path = "/v1/applications/0fbfa58c-e5b6-41c3-b748-ab29f1567a9c/roles"
+	reqRes.ResBody = []byte(`{"roles":[{"id":"purchaser","name":"Purchaser"},{"id":"provider","name":"Provider"},{"id":"ee2ec16f-694b-447f-b61a-e293b6fe5f7b","name":"role2"},{"id":"33fd15c0-e919-47b0-9e05-5f47999f6d91","name":"role1"}]}`)
+
+	helper.SetClientHTTP(c, reqRes)
+
+	err := rolesList(c, c.Ngsi, c.Client)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 5, ngsiErr.ErrNo)
+		assert.Equal(t, "unknown output format: xml (csv, tsv, table)", ngsiErr.Message)
+	}
+}
+
 func TestRolesGet(t *testing.T) {
 	c := setupTest([]string{"applications", "roles", "get", "--host", "keyrock", "--aid", "0fbfa58c-e5b6-41c3-b748-ab29f1567a9c", "--rid", "33fd15c0-e919-47b0-9e05-5f47999f6d91"})
 
@@ -155,6 +155,26 @@ func TestTrustedAppListErrorPretty(t *testing.T) {
 	}
 }
 
+func TestTrustedAppListErrorOutput(t *testing.T) {
+	c := setupTest([]string{"applications", "trusted", "list", "--host", "keyrock", "--aid", "0fbfa58c-e5b6-41c3-b748-ab29f1567a9c", "--output", "csv"})
+	c.GetStringFlag("output").Value = "xml"
+
+	reqRes := helper.MockHTTPReqRes{}
+	reqRes.Res.StatusCode = http.StatusOK
+	reqRes.Path = "/v1/applications/0fbfa58c-e5b6-41c3-b748-ab29f1567a9c/trusted_applications"
+	reqRes.ResBody = []byte(`{"trusted_applications":["8692ec57-8514-4ef6-a347-3d1ac6409f79","78b4763f-139a-4820-a42b-3e265fb9d56e","462ee067-f10a-4c9c-aefe-079038830043","6781fd6c-9dd3-46d7-bdcc-4ca2af1ae42d"]}`)
+
+	helper.SetClientHTTP(c, reqRes)
+
+	err := trustedAppList(c, c.Ngsi, c.Client)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 4, ngsiErr.ErrNo)
+		assert.Equal(t, "unknown output format: xml (csv, tsv, table)", ngsiErr.Message)
+	}
+}
+
 func TestTrustedAppAdd(t *testing.T) {
 	c := setupTest([]string{"applications", "trusted", "add", "--host", "keyrock", "--aid", "0fbfa58c-e5b6-41c3-b748-ab29f1567a9c", "--tid", "0118ccb7-756e-42f9-8a19-5b4e83ca8c46"})
 
@@ -175,6 +175,26 @@ func TestUsersListErrorID(t *testing.T) {
 	}
 }
 
+func TestUsersListErrorOutput(t *testing.T) {
+	c := setupTest([]string{"users", "list", "--host", "keyrock", "--output", "csv"})
+	c.GetStringFlag("output").Value = "xml"
+
+	reqRes := helper.MockHTTPReqRes{}
+	reqRes.Res.StatusCode = http.StatusOK
+	reqRes.Path = "/v1/users"
+	reqRes.ResBody = []byte(`{"users":[{"id":"2d6f5391-6130-48d8-a9d0-01f20699a7eb","username":"alice","email":"alice@test.com","enabled":true,"gravatar":false,"date_password":"2018-03-20T09:31:07.000Z","description":null,"website":null},{"id":"admin","username":"admin","email":"admin@test.com","enabled":true,"gravatar":false,"date_password":"2018-03-20T08:40:14.000Z","description":null,"website":null}]}`)
+
+	helper.SetClientHTTP(c, reqRes)
+
+	err := usersList(c, c.Ngsi, c.Client)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 5, ngsiErr.ErrNo)
+		assert.Equal(t, "unknown output format: xml (csv, tsv, table)", ngsiErr.Message)
+	}
+}
+
 func TestUsersGet(t *testing.T) {
 	c := setupTest([]string{"users", "get", "--host", "keyrock", "--uid", "2d6f5391-6130-48d8-a9d0-01f20699a7eb"})
 
@@ -32,6 +32,7 @@ package management
 import (
 	"bytes"
 	"fmt"
+	"net/url"
 	"strings"
 
 	"github.com/lets-fiware/ngsi-go/internal/ngsicli"
@@ -50,6 +51,11 @@ func brokersList(c *ngsicli.Context, ngsi *ngsilib.NGSI, client *ngsilib.Client)
 			return ngsierr.New(funcName, 1, host+" not found", err)
 		}
 		clearText := c.Bool("clearText")
+		if clearText {
+			if err := ngsi.OpenServerSecrets(info); err != nil {
+				return ngsierr.New(funcName, 2, err.Error(), err)
+			}
+		}
 		printBrokerInfo(ngsi, info, clearText)
 		return nil
 	}
@@ -57,13 +63,13 @@ func brokersList(c *ngsicli.Context, ngsi *ngsilib.NGSI, client *ngsilib.Client)
 	if c.IsSet("json") || c.Bool("pretty") {
 		lists, err := ngsi.AllServersList().BrokerInfoJSON("")
 		if err != nil {
-			return ngsierr.New(funcName, 2, err.Error(), err)
+			return ngsierr.New(funcName, 3, err.Error(), err)
 		}
 		if c.Bool("pretty") {
 			newBuf := new(bytes.Buffer)
 			err := ngsi.JSONConverter.Indent(newBuf, []byte(*lists), "", "  ")
 			if err != nil {
-				return ngsierr.New(funcName, 3, err.Error(), err)
+				return ngsierr.New(funcName, 4, err.Error(), err)
 			}
 			fmt.Fprintln(ngsi.StdWriter, newBuf.String())
 		} else {
@@ -106,6 +112,11 @@ func brokersGet(c *ngsicli.Context, ngsi *ngsilib.NGSI, client *ngsilib.Client)
 			return ngsierr.New(funcName, 3, host+" not found", err)
 		}
 		clearText := c.Bool("clearText")
+		if clearText {
+			if err := ngsi.OpenServerSecrets(info); err != nil {
+				return ngsierr.New(funcName, 4, err.Error(), err)
+			}
+		}
 		printBrokerInfo(ngsi, info, clearText)
 	}
 
@@ -159,6 +170,10 @@ func brokersAdd(c *ngsicli.Context, ngsi *ngsilib.NGSI, client *ngsilib.Client)
 		}
 	}
 
+	if err := absFilePaths(ngsi, param); err != nil {
+		return ngsierr.New(funcName, 8, err.Error(), err)
+	}
+
 	setPreviousArgs(ngsi, host)
 
 	err := ngsi.CreateServer(host, param)
@@ -194,6 +209,10 @@ func brokersUpdate(c *ngsicli.Context, ngsi *ngsilib.NGSI, client *ngsilib.Clien
 		}
 	}
 
+	if err := absFilePaths(ngsi, param); err != nil {
+		return ngsierr.New(funcName, 3, err.Error(), err)
+	}
+
 	setPreviousArgs(ngsi, host)
 
 	err := ngsi.UpdateServer(host, param)
@@ -295,6 +314,14 @@ func printBrokerInfo(ngsi *ngsilib.NGSI, info *ngsilib.Server, clearText bool) {
 		{"XAuthToken", info.XAuthToken},
 		{"Token", info.Token},
 		{"APIPath", info.APIPath},
+		{"RetryMax", info.RetryMax},
+		{"RetryBackoff", info.RetryBackoff},
+		{"RetryOn", info.RetryOn},
+		{"CACert", info.CACert},
+		{"ClientCert", info.ClientCert},
+		{"ClientKey", info.ClientKey},
+		{"Proxy", obfuscateURL(info.Proxy, clearText)},
+		{"NoProxy", info.NoProxy},
 	}
 
 	for _, item := range items {
@@ -304,6 +331,21 @@ func printBrokerInfo(ngsi *ngsilib.NGSI, info *ngsilib.Server, clearText bool) {
 	}
 }
 
+func absFilePaths(ngsi *ngsilib.NGSI, param map[string]string) error {
+	const funcName = "absFilePaths"
+
+	for _, key := range []string{"caCert", "clientCert", "clientKey"} {
+		if path, ok := param[key]; ok {
+			path, err := ngsi.FilePath.FilePathAbs(path)
+			if err != nil {
+				return ngsierr.New(funcName, 1, err.Error(), err)
+			}
+			param[key] = path
+		}
+	}
+	return nil
+}
+
 func obfuscateText(text string, clearText bool) string {
 	if !clearText {
 		s := ""
@@ -315,6 +357,15 @@ func obfuscateText(text string, clearText bool) string {
 	return text
 }
 
+func obfuscateURL(text string, clearText bool) string {
+	if !clearText {
+		if u, err := url.Parse(text); err == nil {
+			return u.Redacted()
+		}
+	}
+	return text
+}
+
 func setPreviousArgs(ngsi *ngsilib.NGSI, host string) {
 	if ngsi.GetPreviousArgs().UsePreviousArgs {
 		ngsi.PreviousArgs.Host = host
@@ -48,6 +48,7 @@ var ManagementApp = &ngsicli.App{
 		&ServerCmd,
 		&ContextCmd,
 		&SettingsCmd,
+		&ProfileCmd,
 		&TokenCmd,
 		&LicenseCmd,
 	},
@@ -110,6 +111,14 @@ var BrokersCmd = ngsicli.Command{
 				ngsicli.ScopeFlag,
 				ngsicli.SafeStringFlag,
 				ngsicli.XAuthTokenFlag,
+				retryMaxFlag,
+				retryBackoffFlag,
+				retryOnFlag,
+				caCertFlag,
+				clientCertFlag,
+				clientKeyFlag,
+				proxyFlag,
+				noProxyFlag,
 				brokerOverWrite,
 			},
 			Action: func(c *ngsicli.Context, ngsi *ngsilib.NGSI, client *ngsilib.Client) error {
@@ -140,6 +149,14 @@ var BrokersCmd = ngsicli.Command{
 				ngsicli.ScopeFlag,
 				ngsicli.SafeStringFlag,
 				ngsicli.XAuthTokenFlag,
+				retryMaxFlag,
+				retryBackoffFlag,
+				retryOnFlag,
+				caCertFlag,
+				clientCertFlag,
+				clientKeyFlag,
+				proxyFlag,
+				noProxyFlag,
 			},
 			Action: func(c *ngsicli.Context, ngsi *ngsilib.NGSI, client *ngsilib.Client) error {
 				return brokersUpdate(c, ngsi, client)
@@ -216,6 +233,14 @@ var ServerCmd = ngsicli.Command{
 				ngsicli.ScopeFlag,
 				ngsicli.SafeStringFlag,
 				ngsicli.XAuthTokenFlag,
+				retryMaxFlag,
+				retryBackoffFlag,
+				retryOnFlag,
+				caCertFlag,
+				clientCertFlag,
+				clientKeyFlag,
+				proxyFlag,
+				noProxyFlag,
 				serverOverWrite,
 			},
 			Action: func(c *ngsicli.Context, ngsi *ngsilib.NGSI, client *ngsilib.Client) error {
@@ -244,6 +269,14 @@ var ServerCmd = ngsicli.Command{
 				ngsicli.ScopeFlag,
 				ngsicli.SafeStringFlag,
 				ngsicli.XAuthTokenFlag,
+				retryMaxFlag,
+				retryBackoffFlag,
+				retryOnFlag,
+				caCertFlag,
+				clientCertFlag,
+				clientKeyFlag,
+				proxyFlag,
+				noProxyFlag,
 			},
 			Action: func(c *ngsicli.Context, ngsi *ngsilib.NGSI, client *ngsilib.Client) error {
 				return serverUpdate(c, ngsi, client)
@@ -377,6 +410,85 @@ var SettingsCmd = ngsicli.Command{
 				return settingsPreviousArgs(c, ngsi, client)
 			},
 		},
+		{
+			Name:  "encrypt",
+			Usage: "Encrypt secrets in config and token cache",
+			Flags: []ngsicli.Flag{
+				secretsHelperFlag,
+			},
+			Action: func(c *ngsicli.Context, ngsi *ngsilib.NGSI, client *ngsilib.Client) error {
+				return settingsEncrypt(c, ngsi, client)
+			},
+		},
+		{
+			Name:  "decrypt",
+			Usage: "Decrypt secrets in config and token cache",
+			Flags: []ngsicli.Flag{},
+			Action: func(c *ngsicli.Context, ngsi *ngsilib.NGSI, client *ngsilib.Client) error {
+				return settingsDecrypt(c, ngsi, client)
+			},
+		},
+	},
+}
+
+var ProfileCmd = ngsicli.Command{
+	Name:     "profile",
+	Category: "MANAGEMENT",
+	Usage:    "manage profiles",
+	Subcommands: []*ngsicli.Command{
+		{
+			Name:  "list",
+			Usage: "List profiles",
+			Flags: []ngsicli.Flag{
+				ngsicli.JsonFlag,
+				ngsicli.PrettyFlag,
+			},
+			Action: func(c *ngsicli.Context, ngsi *ngsilib.NGSI, client *ngsilib.Client) error {
+				return profileList(c, ngsi, client)
+			},
+		},
+		{
+			Name:  "use",
+			Usage: "Use profile",
+			Flags: []ngsicli.Flag{
+				profileNameFlag,
+			},
+			Action: func(c *ngsicli.Context, ngsi *ngsilib.NGSI, client *ngsilib.Client) error {
+				return profileUse(c, ngsi, client)
+			},
+		},
+		{
+			Name:  "add",
+			Usage: "Add profile",
+			Flags: []ngsicli.Flag{
+				profileNameRFlag,
+				protectedFlag,
+			},
+			Action: func(c *ngsicli.Context, ngsi *ngsilib.NGSI, client *ngsilib.Client) error {
+				return profileAdd(c, ngsi, client)
+			},
+		},
+		{
+			Name:  "update",
+			Usage: "Update profile",
+			Flags: []ngsicli.Flag{
+				profileNameRFlag,
+				protectedRFlag,
+			},
+			Action: func(c *ngsicli.Context, ngsi *ngsilib.NGSI, client *ngsilib.Client) error {
+				return profileUpdate(c, ngsi, client)
+			},
+		},
+		{
+			Name:  "delete",
+			Usage: "Delete profile",
+			Flags: []ngsicli.Flag{
+				profileNameRFlag,
+			},
+			Action: func(c *ngsicli.Context, ngsi *ngsilib.NGSI, client *ngsilib.Client) error {
+				return profileDelete(c, ngsi, client)
+			},
+		},
 	},
 }
 
@@ -389,6 +501,7 @@ var TokenCmd = ngsicli.Command{
 		ngsicli.PrettyFlag,
 		expiresFlag,
 		revokeFlag,
+		decodeFlag,
 	},
 	Category: "MANAGEMENT",
 	Action: func(c *ngsicli.Context, ngsi *ngsilib.NGSI, client *ngsilib.Client) error {
@@ -73,6 +73,7 @@ func TestNGSICommand(t *testing.T) {
 		{args: []string{"settings", "clear"}, rc: 0},
 		{args: []string{"settings", "delete"}, rc: 1},
 		{args: []string{"settings", "previousArgs"}, rc: 1},
+		{args: []string{"settings", "decrypt"}, rc: 1},
 		{args: []string{"token", "--host", "orion"}, rc: 1},
 		{args: []string{"license"}, rc: 0},
 	}
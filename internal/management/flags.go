@@ -147,6 +147,38 @@ var (
 		Name:  "token",
 		Usage: "token `VALUE`",
 	}
+	retryMaxFlag = &ngsicli.StringFlag{
+		Name:  "retryMax",
+		Usage: "maximum number of attempts per request (1-10)",
+	}
+	retryBackoffFlag = &ngsicli.StringFlag{
+		Name:  "retryBackoff",
+		Usage: "initial backoff `DURATION` between attempts (e.g. 500ms, 2s)",
+	}
+	retryOnFlag = &ngsicli.StringFlag{
+		Name:  "retryOn",
+		Usage: "comma-separated HTTP status `CODES` to retry on",
+	}
+	caCertFlag = &ngsicli.StringFlag{
+		Name:  "caCert",
+		Usage: "CA certificate bundle `FILE` to verify the server",
+	}
+	clientCertFlag = &ngsicli.StringFlag{
+		Name:  "clientCert",
+		Usage: "client certificate `FILE` for mutual TLS",
+	}
+	clientKeyFlag = &ngsicli.StringFlag{
+		Name:  "clientKey",
+		Usage: "client private key `FILE` for mutual TLS",
+	}
+	proxyFlag = &ngsicli.StringFlag{
+		Name:  "proxy",
+		Usage: "proxy `URL` (http, https or socks5)",
+	}
+	noProxyFlag = &ngsicli.StringFlag{
+		Name:  "noProxy",
+		Usage: "comma-separated `HOSTS` to connect without proxy",
+	}
 	brokerOverWrite = &ngsicli.BoolFlag{
 		Name:    "overWrite",
 		Aliases: []string{"O"},
@@ -273,6 +305,32 @@ var (
 	}
 )
 
+// flag for profile
+var (
+	profileNameFlag = &ngsicli.StringFlag{
+		Name:    "name",
+		Aliases: []string{"n"},
+		Usage:   "profile name",
+	}
+	profileNameRFlag = &ngsicli.StringFlag{
+		Name:     "name",
+		Aliases:  []string{"n"},
+		Usage:    "profile name",
+		Required: true,
+	}
+	protectedFlag = &ngsicli.StringFlag{
+		Name:    "protected",
+		Usage:   "ask for confirmation before changing data (`VALUE`: on/off)",
+		Choices: []string{"on", "off"},
+	}
+	protectedRFlag = &ngsicli.StringFlag{
+		Name:     "protected",
+		Usage:    "ask for confirmation before changing data (`VALUE`: on/off)",
+		Choices:  []string{"on", "off"},
+		Required: true,
+	}
+)
+
 var (
 	onFlag = &ngsicli.BoolFlag{
 		Name:    "on",
@@ -284,6 +342,10 @@ var (
 		Aliases: []string{"d"},
 		Usage:   "off (disable)",
 	}
+	secretsHelperFlag = &ngsicli.StringFlag{
+		Name:  "helper",
+		Usage: "credential helper `PROGRAM` which stores the secrets instead of the passphrase",
+	}
 )
 
 var (
@@ -297,4 +359,8 @@ var (
 		Aliases: []string{"r"},
 		Usage:   "revoke token",
 	}
+	decodeFlag = &ngsicli.BoolFlag{
+		Name:  "decode",
+		Usage: "decode JWT header and claims without verifying signature",
+	}
 )
@@ -45,3 +45,28 @@ func setupTestWithConfig(args []string, config string) *ngsicli.Context {
 func setupTestWithConfigAndCache(args []string, config, cache string) *ngsicli.Context {
 	return helper.SetupTestWithConfigAndCache(NewNgsiApp(), args, config, cache)
 }
+
+var secretsConfigData = `{
+  "version": "1",
+  "servers": {
+    "orion": {
+      "serverType": "broker",
+      "serverHost": "https://orion",
+      "ngsiType": "v2",
+      "idmType": "basic",
+      "username": "fiware",
+      "password": "helper:orion/password"
+    },
+    "comet": {
+      "serverType": "comet",
+      "serverHost": "https://comet",
+      "idmType": "basic",
+      "username": "fiware",
+      "password": "helper:comet/password"
+    }
+  },
+  "secrets": {
+    "backend": "helper",
+    "helper": "ngsi-helper"
+  }
+}`
@@ -0,0 +1,168 @@
+/*
+MIT License
+
+Copyright (c) 2020-2024 Kazuhito Suda
+
+This file is part of NGSI Go
+
+https://github.com/lets-fiware/ngsi-go
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+
+*/
+
+package management
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+
+	"github.com/lets-fiware/ngsi-go/internal/ngsicli"
+	"github.com/lets-fiware/ngsi-go/internal/ngsierr"
+	"github.com/lets-fiware/ngsi-go/internal/ngsilib"
+)
+
+type profileInfo struct {
+	Name      string   `json:"name"`
+	Current   bool     `json:"current"`
+	Protected bool     `json:"protected"`
+	Host      string   `json:"host,omitempty"`
+	Tenant    string   `json:"tenant,omitempty"`
+	Scope     string   `json:"scope,omitempty"`
+	Servers   []string `json:"servers"`
+}
+
+func profileList(c *ngsicli.Context, ngsi *ngsilib.NGSI, client *ngsilib.Client) error {
+	const funcName = "profileList"
+
+	current := ngsi.CurrentProfile()
+
+	profiles := []profileInfo{}
+	for _, name := range ngsi.ProfileNames() {
+		p, err := ngsi.GetProfile(name)
+		if err != nil {
+			return ngsierr.New(funcName, 1, err.Error(), err)
+		}
+		servers := make([]string, 0, len(p.Servers))
+		for host := range p.Servers {
+			servers = append(servers, host)
+		}
+		sort.Strings(servers)
+		profiles = append(profiles, profileInfo{
+			Name:      name,
+			Current:   name == current,
+			Protected: p.Protected,
+			Host:      p.Host,
+			Tenant:    p.Tenant,
+			Scope:     p.Scope,
+			Servers:   servers,
+		})
+	}
+
+	if c.IsSet("json") || c.Bool("pretty") {
+		b, err := ngsilib.JSONMarshal(profiles)
+		if err != nil {
+			return ngsierr.New(funcName, 2, err.Error(), err)
+		}
+		if c.Bool("pretty") {
+			newBuf := new(bytes.Buffer)
+			err := ngsi.JSONConverter.Indent(newBuf, b, "", "  ")
+			if err != nil {
+				return ngsierr.New(funcName, 3, err.Error(), err)
+			}
+			fmt.Fprintln(ngsi.StdWriter, newBuf.String())
+		} else {
+			fmt.Fprint(ngsi.StdWriter, string(b))
+		}
+		return nil
+	}
+
+	for _, p := range profiles {
+		mark := " "
+		if p.Current {
+			mark = "*"
+		}
+		if p.Protected {
+			fmt.Fprintf(ngsi.StdWriter, "%s %s (protected)\n", mark, p.Name)
+		} else {
+			fmt.Fprintf(ngsi.StdWriter, "%s %s\n", mark, p.Name)
+		}
+	}
+
+	return nil
+}
+
+func profileAdd(c *ngsicli.Context, ngsi *ngsilib.NGSI, client *ngsilib.Client) error {
+	const funcName = "profileAdd"
+
+	name := c.String("name")
+	protected := c.String("protected") == "on"
+
+	if err := ngsi.AddProfile(name, protected); err != nil {
+		return ngsierr.New(funcName, 1, err.Error(), err)
+	}
+
+	return nil
+}
+
+func profileUpdate(c *ngsicli.Context, ngsi *ngsilib.NGSI, client *ngsilib.Client) error {
+	const funcName = "profileUpdate"
+
+	name := c.String("name")
+	protected := c.String("protected") == "on"
+
+	if err := ngsi.UpdateProfile(name, protected); err != nil {
+		return ngsierr.New(funcName, 1, err.Error(), err)
+	}
+
+	return nil
+}
+
+func profileDelete(c *ngsicli.Context, ngsi *ngsilib.NGSI, client *ngsilib.Client) error {
+	const funcName = "profileDelete"
+
+	name := c.String("name")
+
+	if err := ngsi.DeleteProfile(name); err != nil {
+		return ngsierr.New(funcName, 1, err.Error(), err)
+	}
+
+	return nil
+}
+
+func profileUse(c *ngsicli.Context, ngsi *ngsilib.NGSI, client *ngsilib.Client) error {
+	const funcName = "profileUse"
+
+	var name string
+
+	if c.IsSet("name") && c.Args().Len() == 0 {
+		name = c.String("name")
+	} else if !c.IsSet("name") && c.Args().Len() == 1 {
+		name = c.Args().Get(0)
+	} else {
+		return ngsierr.New(funcName, 1, "specify a profile name", nil)
+	}
+
+	if err := ngsi.UseProfile(name); err != nil {
+		return ngsierr.New(funcName, 2, err.Error(), err)
+	}
+
+	return nil
+}
@@ -0,0 +1,271 @@
+/*
+MIT License
+
+Copyright (c) 2020-2024 Kazuhito Suda
+
+This file is part of NGSI Go
+
+https://github.com/lets-fiware/ngsi-go
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+
+*/
+
+package management
+
+import (
+	"testing"
+
+	"github.com/lets-fiware/ngsi-go/internal/assert"
+	"github.com/lets-fiware/ngsi-go/internal/helper"
+	"github.com/lets-fiware/ngsi-go/internal/ngsierr"
+)
+
+var profileConfigData = `{
+  "version": "1",
+  "settings": {
+    "usePreviousArgs": true,
+    "host": "orion"
+  },
+  "servers": {
+    "orion": {
+      "serverType": "broker",
+      "serverHost": "http://orion-dev:1026",
+      "ngsiType": "v2"
+    }
+  },
+  "profile": "prod",
+  "profiles": {
+    "prod": {
+      "protected": true,
+      "host": "orion",
+      "tenant": "city",
+      "servers": {
+        "orion": {
+          "serverType": "broker",
+          "serverHost": "http://orion-prod:1026",
+          "ngsiType": "v2"
+        },
+        "comet": {
+          "serverType": "comet",
+          "serverHost": "http://comet-prod:8666"
+        }
+      }
+    },
+    "staging": {
+      "servers": {}
+    }
+  }
+}`
+
+func TestProfileList(t *testing.T) {
+	c := setupTestWithConfig([]string{"profile", "list"}, profileConfigData)
+
+	err := profileList(c, c.Ngsi, c.Client)
+
+	if assert.NoError(t, err) {
+		actual := helper.GetStdoutString(c)
+		expected := "  default\n* prod (protected)\n  staging\n"
+		assert.Equal(t, expected, actual)
+	}
+}
+
+func TestProfileListGlobalFlag(t *testing.T) {
+	c := setupTestWithConfig([]string{"--profile", "staging", "profile", "list"}, profileConfigData)
+
+	err := profileList(c, c.Ngsi, c.Client)
+
+	if assert.NoError(t, err) {
+		actual := helper.GetStdoutString(c)
+		expected := "  default\n  prod (protected)\n* staging\n"
+		assert.Equal(t, expected, actual)
+	}
+}
+
+func TestProfileListJSON(t *testing.T) {
+	c := setupTestWithConfig([]string{"profile", "list", "--json"}, profileConfigData)
+
+	err := profileList(c, c.Ngsi, c.Client)
+
+	if assert.NoError(t, err) {
+		actual := helper.GetStdoutString(c)
+		expected := `[{"name":"default","current":false,"protected":false,"host":"orion","servers":["orion"]},{"name":"prod","current":true,"protected":true,"host":"orion","tenant":"city","servers":["comet","orion"]},{"name":"staging","current":false,"protected":false,"servers":[]}]`
+		assert.Equal(t, expected, actual)
+	}
+}
+
+func TestProfileListPretty(t *testing.T) {
+	c := setupTestWithConfig([]string{"--profile", "staging", "profile", "list", "--pretty"}, profileConfigData)
+
+	err := profileList(c, c.Ngsi, c.Client)
+
+	if assert.NoError(t, err) {
+		actual := helper.GetStdoutString(c)
+		expected := "[\n  {\n    \"name\": \"default\",\n    \"current\": false,\n    \"protected\": false,\n    \"host\": \"orion\",\n    \"servers\": [\n      \"orion\"\n    ]\n  },\n  {\n    \"name\": \"prod\",\n    \"current\": false,\n    \"protected\": true,\n    \"host\": \"orion\",\n    \"tenant\": \"city\",\n    \"servers\": [\n      \"comet\",\n      \"orion\"\n    ]\n  },\n  {\n    \"name\": \"staging\",\n    \"current\": true,\n    \"protected\": false,\n    \"servers\": []\n  }\n]\n"
+		assert.Equal(t, expected, actual)
+	}
+}
+
+func TestProfileListErrorJSON(t *testing.T) {
+	c := setupTestWithConfig([]string{"profile", "list", "--json"}, profileConfigData)
+
+	helper.SetJSONEncodeErr(c.Ngsi, 0)
+
+	err := profileList(c, c.Ngsi, c.Client)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 2, ngsiErr.ErrNo)
+		assert.Equal(t, "json error", ngsiErr.Message)
+	}
+}
+
+func TestProfileListErrorPretty(t *testing.T) {
+	c := setupTestWithConfig([]string{"profile", "list", "--pretty"}, profileConfigData)
+
+	helper.SetJSONIndentError(c.Ngsi)
+
+	err := profileList(c, c.Ngsi, c.Client)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 3, ngsiErr.ErrNo)
+		assert.Equal(t, "json error", ngsiErr.Message)
+	}
+}
+
+func TestProfileAdd(t *testing.T) {
+	c := setupTestWithConfig([]string{"profile", "add", "--name", "dev", "--protected", "on"}, profileConfigData)
+
+	err := profileAdd(c, c.Ngsi, c.Client)
+
+	if assert.NoError(t, err) {
+		p, err := c.Ngsi.GetProfile("dev")
+		if assert.NoError(t, err) {
+			assert.Equal(t, true, p.Protected)
+		}
+	}
+}
+
+func TestProfileAddError(t *testing.T) {
+	c := setupTestWithConfig([]string{"profile", "add", "--name", "prod"}, profileConfigData)
+
+	err := profileAdd(c, c.Ngsi, c.Client)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 1, ngsiErr.ErrNo)
+		assert.Equal(t, "prod already exists", ngsiErr.Message)
+	}
+}
+
+func TestProfileUpdate(t *testing.T) {
+	c := setupTestWithConfig([]string{"profile", "update", "--name", "prod", "--protected", "off"}, profileConfigData)
+
+	err := profileUpdate(c, c.Ngsi, c.Client)
+
+	if assert.NoError(t, err) {
+		p, err := c.Ngsi.GetProfile("prod")
+		if assert.NoError(t, err) {
+			assert.Equal(t, false, p.Protected)
+		}
+	}
+}
+
+func TestProfileUpdateError(t *testing.T) {
+	c := setupTestWithConfig([]string{"profile", "update", "--name", "dev", "--protected", "on"}, profileConfigData)
+
+	err := profileUpdate(c, c.Ngsi, c.Client)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 1, ngsiErr.ErrNo)
+		assert.Equal(t, "profile not found: dev", ngsiErr.Message)
+	}
+}
+
+func TestProfileDelete(t *testing.T) {
+	c := setupTestWithConfig([]string{"profile", "delete", "--name", "staging"}, profileConfigData)
+
+	err := profileDelete(c, c.Ngsi, c.Client)
+
+	if assert.NoError(t, err) {
+		_, err := c.Ngsi.GetProfile("staging")
+		assert.Error(t, err)
+	}
+}
+
+func TestProfileDeleteError(t *testing.T) {
+	c := setupTestWithConfig([]string{"profile", "delete", "--name", "prod"}, profileConfigData)
+
+	err := profileDelete(c, c.Ngsi, c.Client)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 1, ngsiErr.ErrNo)
+		assert.Equal(t, "prod is protected", ngsiErr.Message)
+	}
+}
+
+func TestProfileUse(t *testing.T) {
+	c := setupTestWithConfig([]string{"profile", "use", "default"}, profileConfigData)
+
+	err := profileUse(c, c.Ngsi, c.Client)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, "default", c.Ngsi.CurrentProfile())
+		assert.Equal(t, "http://orion-dev:1026", c.Ngsi.ServerList["orion"].ServerHost)
+		assert.Equal(t, "", c.Ngsi.PreviousArgs.Tenant)
+	}
+}
+
+func TestProfileUseName(t *testing.T) {
+	c := setupTestWithConfig([]string{"profile", "use", "--name", "staging"}, profileConfigData)
+
+	err := profileUse(c, c.Ngsi, c.Client)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, "staging", c.Ngsi.CurrentProfile())
+		assert.Equal(t, 0, len(c.Ngsi.ServerList))
+	}
+}
+
+func TestProfileUseErrorArgs(t *testing.T) {
+	c := setupTestWithConfig([]string{"profile", "use"}, profileConfigData)
+
+	err := profileUse(c, c.Ngsi, c.Client)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 1, ngsiErr.ErrNo)
+		assert.Equal(t, "specify a profile name", ngsiErr.Message)
+	}
+}
+
+func TestProfileUseErrorNotFound(t *testing.T) {
+	c := setupTestWithConfig([]string{"profile", "use", "dev"}, profileConfigData)
+
+	err := profileUse(c, c.Ngsi, c.Client)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 2, ngsiErr.ErrNo)
+		assert.Equal(t, "profile not found: dev", ngsiErr.Message)
+	}
+}
@@ -50,18 +50,23 @@ func serverList(c *ngsicli.Context, ngsi *ngsilib.NGSI, client *ngsilib.Client)
 			return ngsierr.New(funcName, 1, host+" not found", err)
 		}
 		clearText := c.Bool("clearText")
+		if clearText {
+			if err := ngsi.OpenServerSecrets(info); err != nil {
+				return ngsierr.New(funcName, 2, err.Error(), err)
+			}
+		}
 		printServerInfo(ngsi, info, clearText)
 	} else {
 		if c.IsSet("json") || c.Bool("pretty") {
 			lists, err := ngsi.AllServersList().ServerInfoJSON("", ngsicli.CmdMode)
 			if err != nil {
-				return ngsierr.New(funcName, 2, err.Error(), err)
+				return ngsierr.New(funcName, 3, err.Error(), err)
 			}
 			if c.Bool("pretty") {
 				newBuf := new(bytes.Buffer)
 				err := ngsi.JSONConverter.Indent(newBuf, []byte(*lists), "", "  ")
 				if err != nil {
-					return ngsierr.New(funcName, 3, err.Error(), err)
+					return ngsierr.New(funcName, 4, err.Error(), err)
 				}
 				fmt.Fprintln(ngsi.StdWriter, newBuf.String())
 			} else {
@@ -105,6 +110,11 @@ func serverGet(c *ngsicli.Context, ngsi *ngsilib.NGSI, client *ngsilib.Client) e
 			return ngsierr.New(funcName, 3, host+" not found", err)
 		}
 		clearText := c.Bool("clearText")
+		if clearText {
+			if err := ngsi.OpenServerSecrets(info); err != nil {
+				return ngsierr.New(funcName, 4, err.Error(), err)
+			}
+		}
 		printServerInfo(ngsi, info, clearText)
 	}
 
@@ -168,6 +178,10 @@ func serverAdd(c *ngsicli.Context, ngsi *ngsilib.NGSI, client *ngsilib.Client) e
 		param["idmHost"] = strings.TrimRight(param["serverHost"], "/") + "/v1/auth/tokens"
 	}
 
+	if err := absFilePaths(ngsi, param); err != nil {
+		return ngsierr.New(funcName, 8, err.Error(), err)
+	}
+
 	setPreviousArgs(ngsi, host)
 
 	err := ngsi.CreateServer(host, param)
@@ -203,6 +217,10 @@ func serverUpdate(c *ngsicli.Context, ngsi *ngsilib.NGSI, client *ngsilib.Client
 		}
 	}
 
+	if err := absFilePaths(ngsi, param); err != nil {
+		return ngsierr.New(funcName, 3, err.Error(), err)
+	}
+
 	setPreviousArgs(ngsi, host)
 
 	err := ngsi.UpdateServer(host, param)
@@ -294,6 +312,14 @@ func printServerInfo(ngsi *ngsilib.NGSI, info *ngsilib.Server, clearText bool) {
 		{"XAuthToken", info.XAuthToken},
 		{"Token", info.Token},
 		{"APIPath", info.APIPath},
+		{"RetryMax", info.RetryMax},
+		{"RetryBackoff", info.RetryBackoff},
+		{"RetryOn", info.RetryOn},
+		{"CACert", info.CACert},
+		{"ClientCert", info.ClientCert},
+		{"ClientKey", info.ClientKey},
+		{"Proxy", obfuscateURL(info.Proxy, clearText)},
+		{"NoProxy", info.NoProxy},
 	}
 
 	for _, item := range items {
@@ -31,6 +31,7 @@ package management
 
 import (
 	"errors"
+	"strings"
 	"testing"
 
 	"github.com/lets-fiware/ngsi-go/internal/assert"
@@ -108,7 +109,7 @@ func TestServersListErrorJSON(t *testing.T) {
 
 	if assert.Error(t, err) {
 		ngsiErr := err.(*ngsierr.NgsiError)
-		assert.Equal(t, 2, ngsiErr.ErrNo)
+		assert.Equal(t, 3, ngsiErr.ErrNo)
 		assert.Equal(t, "json.Marshl error", ngsiErr.Message)
 	}
 }
@@ -122,7 +123,7 @@ func TestServersListErrorJSONPretty(t *testing.T) {
 
 	if assert.Error(t, err) {
 		ngsiErr := err.(*ngsierr.NgsiError)
-		assert.Equal(t, 3, ngsiErr.ErrNo)
+		assert.Equal(t, 4, ngsiErr.ErrNo)
 		assert.Equal(t, "json error", ngsiErr.Message)
 	}
 }
@@ -213,6 +214,19 @@ func TestServersAdd(t *testing.T) {
 	}
 }
 
+func TestServersAddTLS(t *testing.T) {
+	c := setupTest([]string{"server", "add", "--host", "sth", "--serverHost", "https://sth", "--serverType", "comet", "--caCert", "/certs/ca.pem", "--clientCert", "/certs/cert.pem", "--clientKey", "/certs/key.pem"})
+
+	err := serverAdd(c, c.Ngsi, c.Client)
+
+	if assert.NoError(t, err) {
+		list := c.Ngsi.AllServersList()
+		assert.Equal(t, "/certs/ca.pem", (*list)["sth"].CACert)
+		assert.Equal(t, "/certs/cert.pem", (*list)["sth"].ClientCert)
+		assert.Equal(t, "/certs/key.pem", (*list)["sth"].ClientKey)
+	}
+}
+
 func TestServersAddOverWrite(t *testing.T) {
 	c := setupTest([]string{"server", "add", "--host", "comet", "--serverHost", "http://overwrite", "--serverType", "comet", "--overWrite"})
 
@@ -290,6 +304,20 @@ func TestServersAddErrorAlreadyExists(t *testing.T) {
 	}
 }
 
+func TestServersAddErrorFilePathAbs(t *testing.T) {
+	c := setupTest([]string{"server", "add", "--host", "sth", "--serverHost", "https://sth", "--serverType", "comet", "--caCert", "ca.pem"})
+
+	c.Ngsi.FilePath = &helper.MockFilePathLib{PathAbsErr: errors.New("file path abs error")}
+
+	err := serverAdd(c, c.Ngsi, c.Client)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 8, ngsiErr.ErrNo)
+		assert.Equal(t, "file path abs error", ngsiErr.Message)
+	}
+}
+
 func TestServersAddErrorServerHost(t *testing.T) {
 	c := setupTest([]string{"server", "add", "--host", "server", "http://comet", "--serverType", "comet", "--service", "Foo"})
 
@@ -349,6 +377,17 @@ func TestServersUpdate(t *testing.T) {
 	}
 }
 
+func TestServersUpdateTLS(t *testing.T) {
+	c := setupTest([]string{"server", "update", "--host", "comet", "--caCert", "/certs/ca.pem"})
+
+	err := serverUpdate(c, c.Ngsi, c.Client)
+
+	if assert.NoError(t, err) {
+		list := c.Ngsi.AllServersList()
+		assert.Equal(t, "/certs/ca.pem", (*list)["comet"].CACert)
+	}
+}
+
 func TestServersUpdateService(t *testing.T) {
 	c := setupTest([]string{"server", "update", "--host", "comet", "--service", "Foo"})
 
@@ -384,6 +423,20 @@ func TestServersUpdateErrorUpdateBroker(t *testing.T) {
 	}
 }
 
+func TestServersUpdateErrorFilePathAbs(t *testing.T) {
+	c := setupTest([]string{"server", "update", "--host", "comet", "--caCert", "ca.pem"})
+
+	c.Ngsi.FilePath = &helper.MockFilePathLib{PathAbsErr: errors.New("file path abs error")}
+
+	err := serverUpdate(c, c.Ngsi, c.Client)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 3, ngsiErr.ErrNo)
+		assert.Equal(t, "file path abs error", ngsiErr.Message)
+	}
+}
+
 func TestServersDelete(t *testing.T) {
 	c := setupTest([]string{"server", "delete", "--host", "ql"})
 
@@ -570,3 +623,51 @@ func TestPrintServerInfoError(t *testing.T) {
 	expected := "server type error\n"
 	assert.Equal(t, expected, actual)
 }
+
+func TestServersListHostClearTextSecrets(t *testing.T) {
+	c := setupTestWithConfig([]string{"server", "list", "--host", "comet", "--clearText"}, secretsConfigData)
+	c.Ngsi.ExecLib = &helper.MockExecLib{Secrets: map[string]string{"comet/password": "1234"}}
+
+	err := serverList(c, c.Ngsi, c.Client)
+
+	if assert.NoError(t, err) {
+		actual := helper.GetStdoutString(c)
+		assert.Equal(t, true, strings.Contains(actual, "Password 1234\n"))
+	}
+}
+
+func TestServersListHostErrorSecrets(t *testing.T) {
+	c := setupTestWithConfig([]string{"server", "list", "--host", "comet", "--clearText"}, secretsConfigData)
+
+	err := serverList(c, c.Ngsi, c.Client)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 2, ngsiErr.ErrNo)
+		assert.Equal(t, "ngsi-helper get: not found", ngsiErr.Message)
+	}
+}
+
+func TestServersGetClearTextSecrets(t *testing.T) {
+	c := setupTestWithConfig([]string{"server", "get", "--host", "comet", "--clearText"}, secretsConfigData)
+	c.Ngsi.ExecLib = &helper.MockExecLib{Secrets: map[string]string{"comet/password": "1234"}}
+
+	err := serverGet(c, c.Ngsi, c.Client)
+
+	if assert.NoError(t, err) {
+		actual := helper.GetStdoutString(c)
+		assert.Equal(t, true, strings.Contains(actual, "Password 1234\n"))
+	}
+}
+
+func TestServersGetErrorSecrets(t *testing.T) {
+	c := setupTestWithConfig([]string{"server", "get", "--host", "comet", "--clearText"}, secretsConfigData)
+
+	err := serverGet(c, c.Ngsi, c.Client)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 4, ngsiErr.ErrNo)
+		assert.Equal(t, "ngsi-helper get: not found", ngsiErr.Message)
+	}
+}
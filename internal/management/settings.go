@@ -47,6 +47,9 @@ func settingsList(c *ngsicli.Context, ngsi *ngsilib.NGSI, client *ngsilib.Client
 	if !d.UsePreviousArgs {
 		fmt.Fprintln(ngsi.StdWriter, "PreviousArgs off")
 	}
+	if profile := ngsi.CurrentProfile(); profile != ngsilib.DefaultProfile {
+		printItem(ngsi.StdWriter, "Profile", profile, all)
+	}
 	printItem(ngsi.StdWriter, "Host", d.Host, all)
 	printItem(ngsi.StdWriter, "FIWARE-Service", d.Tenant, all)
 	printItem(ngsi.StdWriter, "FIWARE-ServicePath", d.Scope, all)
@@ -55,6 +58,9 @@ func settingsList(c *ngsicli.Context, ngsi *ngsilib.NGSI, client *ngsilib.Client
 	printItem(ngsi.StdWriter, "Stderr", d.Stderr, all)
 	printItem(ngsi.StdWriter, "LogFile", d.Logfile, all)
 	printItem(ngsi.StdWriter, "LogLevel", d.Loglevel, all)
+	printItem(ngsi.StdWriter, "RetryMax", d.RetryMax, all)
+	printItem(ngsi.StdWriter, "RetryBackoff", d.RetryBackoff, all)
+	printItem(ngsi.StdWriter, "RetryOn", d.RetryOn, all)
 
 	return nil
 }
@@ -90,6 +96,12 @@ func settingsDelete(c *ngsicli.Context, ngsi *ngsilib.NGSI, client *ngsilib.Clie
 		case "logfile", "loglevel":
 			d.Logfile = ""
 			d.Loglevel = ""
+		case "retrymax":
+			d.RetryMax = ""
+		case "retrybackoff":
+			d.RetryBackoff = ""
+		case "retryon":
+			d.RetryOn = ""
 		}
 	}
 
@@ -119,6 +131,9 @@ func settingsClear(c *ngsicli.Context, ngsi *ngsilib.NGSI, client *ngsilib.Clien
 	d.Stderr = ""
 	d.Logfile = ""
 	d.Loglevel = ""
+	d.RetryMax = ""
+	d.RetryBackoff = ""
+	d.RetryOn = ""
 
 	err := ngsi.SavePreviousArgs()
 	if err != nil {
@@ -149,6 +164,9 @@ func settingsPreviousArgs(c *ngsicli.Context, ngsi *ngsilib.NGSI, client *ngsili
 	d.Stderr = ""
 	d.Logfile = ""
 	d.Loglevel = ""
+	d.RetryMax = ""
+	d.RetryBackoff = ""
+	d.RetryOn = ""
 
 	err := ngsi.SavePreviousArgs()
 	if err != nil {
@@ -163,3 +181,25 @@ func printItem(w io.Writer, k, v string, f bool) {
 		fmt.Fprintf(w, "%s: %s\n", k, v)
 	}
 }
+
+func settingsEncrypt(c *ngsicli.Context, ngsi *ngsilib.NGSI, client *ngsilib.Client) error {
+	const funcName = "settingsEncrypt"
+
+	err := ngsi.EncryptSecrets(c.String("helper"))
+	if err != nil {
+		return ngsierr.New(funcName, 1, err.Error(), err)
+	}
+
+	return nil
+}
+
+func settingsDecrypt(c *ngsicli.Context, ngsi *ngsilib.NGSI, client *ngsilib.Client) error {
+	const funcName = "settingsDecrypt"
+
+	err := ngsi.DecryptSecrets()
+	if err != nil {
+		return ngsierr.New(funcName, 1, err.Error(), err)
+	}
+
+	return nil
+}
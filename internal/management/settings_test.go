@@ -51,6 +51,17 @@ func TestSettingsList(t *testing.T) {
 	}
 }
 
+func TestSettingsListProfile(t *testing.T) {
+	c := setupTestWithConfig([]string{"settings", "list"}, profileConfigData)
+
+	err := settingsList(c, c.Ngsi, c.Client)
+
+	if assert.NoError(t, err) {
+		actual := helper.GetStdoutString(c)
+		assert.Equal(t, "Profile: prod\nHost: orion\nFIWARE-Service: city\n", actual)
+	}
+}
+
 func TestSettingsListPreviousArgsOff(t *testing.T) {
 	conf := `{
 		"version": "1",
@@ -88,13 +99,29 @@ func TestSettingsListAll(t *testing.T) {
 
 	if assert.NoError(t, err) {
 		actual := helper.GetStdoutString(c)
-		expected := "Host: \nFIWARE-Service: \nFIWARE-ServicePath: \nToken: \nSyslog: \nStderr: \nLogFile: \nLogLevel: \n"
+		expected := "Host: \nFIWARE-Service: \nFIWARE-ServicePath: \nToken: \nSyslog: \nStderr: \nLogFile: \nLogLevel: \nRetryMax: \nRetryBackoff: \nRetryOn: \n"
+		assert.Equal(t, expected, actual)
+	}
+}
+
+func TestSettingsListRetry(t *testing.T) {
+	c := setupTest([]string{"settings", "list"})
+
+	c.Ngsi.PreviousArgs.RetryMax = "3"
+	c.Ngsi.PreviousArgs.RetryBackoff = "500ms"
+	c.Ngsi.PreviousArgs.RetryOn = "503"
+
+	err := settingsList(c, c.Ngsi, c.Client)
+
+	if assert.NoError(t, err) {
+		actual := helper.GetStdoutString(c)
+		expected := "RetryMax: 3\nRetryBackoff: 500ms\nRetryOn: 503\n"
 		assert.Equal(t, expected, actual)
 	}
 }
 
 func TestSettingsDelete(t *testing.T) {
-	c := setupTest([]string{"settings", "delete", "--items", "host,service,path,token,syslog,stderr,logfile,loglevel"})
+	c := setupTest([]string{"settings", "delete", "--items", "host,service,path,token,syslog,stderr,logfile,loglevel,retryMax,retryBackoff,retryOn"})
 
 	err := settingsDelete(c, c.Ngsi, c.Client)
 
@@ -229,3 +256,54 @@ func TestPrintItem3(t *testing.T) {
 
 	assert.Equal(t, "host: \n", buf.String())
 }
+
+func TestSettingsEncrypt(t *testing.T) {
+	c := setupTest([]string{"settings", "encrypt", "--helper", "ngsi-helper"})
+	exec := &helper.MockExecLib{}
+	c.Ngsi.ExecLib = exec
+	c.Ngsi.ConfigFile = &helper.MockIoLib{Filename: helper.StrPtr("ngsi-config.json")}
+
+	err := settingsEncrypt(c, c.Ngsi, c.Client)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, "helper", c.Ngsi.SecretsConfig().Backend)
+		assert.Equal(t, "ngsi-helper", c.Ngsi.SecretsConfig().Helper)
+	}
+}
+
+func TestSettingsEncryptError(t *testing.T) {
+	c := setupTest([]string{"settings", "encrypt"})
+
+	err := settingsEncrypt(c, c.Ngsi, c.Client)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 1, ngsiErr.ErrNo)
+		assert.Equal(t, "passphrase required. set it to NGSI_GO_PASSPHRASE", ngsiErr.Message)
+	}
+}
+
+func TestSettingsDecrypt(t *testing.T) {
+	c := setupTestWithConfig([]string{"settings", "decrypt"}, secretsConfigData)
+	c.Ngsi.ExecLib = &helper.MockExecLib{Secrets: map[string]string{"orion/password": "1234", "comet/password": "5678"}}
+
+	err := settingsDecrypt(c, c.Ngsi, c.Client)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, true, c.Ngsi.SecretsConfig() == nil)
+		assert.Equal(t, "1234", c.Ngsi.ServerList["orion"].Password)
+		assert.Equal(t, "5678", c.Ngsi.ServerList["comet"].Password)
+	}
+}
+
+func TestSettingsDecryptError(t *testing.T) {
+	c := setupTest([]string{"settings", "decrypt"})
+
+	err := settingsDecrypt(c, c.Ngsi, c.Client)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 1, ngsiErr.ErrNo)
+		assert.Equal(t, "secrets not encrypted", ngsiErr.Message)
+	}
+}
@@ -62,24 +62,29 @@ func tokenCommand(c *ngsicli.Context, ngsi *ngsilib.NGSI, client *ngsilib.Client
 		time = 0
 	}
 
-	if c.Bool("verbose") || c.Bool("pretty") {
+	if c.Bool("decode") || c.Bool("verbose") || c.Bool("pretty") {
 		var b []byte
-		if token.Type == ngsilib.CKeyrockIDM {
-			b, err = getKeyrockUserInfo(tokenClient, token.Token)
+		if c.Bool("decode") {
+			b, err = decodeToken(token.Token)
 			if err != nil {
 				return ngsierr.New(funcName, 3, err.Error(), err)
 			}
+		} else if token.Type == ngsilib.CKeyrockIDM {
+			b, err = getKeyrockUserInfo(tokenClient, token.Token)
+			if err != nil {
+				return ngsierr.New(funcName, 4, err.Error(), err)
+			}
 		} else {
 			b, err = ngsi.GetTokenInfo(token)
 			if err != nil {
-				return ngsierr.New(funcName, 4, err.Error(), err)
+				return ngsierr.New(funcName, 5, err.Error(), err)
 			}
 		}
 		if c.Bool("pretty") {
 			newBuf := new(bytes.Buffer)
 			err := ngsi.JSONConverter.Indent(newBuf, b, "", "  ")
 			if err != nil {
-				return ngsierr.New(funcName, 5, err.Error(), err)
+				return ngsierr.New(funcName, 6, err.Error(), err)
 			}
 			fmt.Fprintln(ngsi.StdWriter, newBuf.String())
 		} else {
@@ -97,7 +102,7 @@ func tokenCommand(c *ngsicli.Context, ngsi *ngsilib.NGSI, client *ngsilib.Client
 func revokeTokenCommand(c *ngsicli.Context, ngsi *ngsilib.NGSI) error {
 	const funcName = "revokeTokenCommand"
 
-	if c.IsSetOR([]string{"verbose", "pretty", "expires"}) {
+	if c.IsSetOR([]string{"verbose", "pretty", "expires", "decode"}) {
 		return ngsierr.New(funcName, 1, "only --revoke can be specified", nil)
 	}
 
@@ -121,6 +126,21 @@ func revokeTokenCommand(c *ngsicli.Context, ngsi *ngsilib.NGSI) error {
 	return nil
 }
 
+func decodeToken(token string) ([]byte, error) {
+	const funcName = "decodeToken"
+
+	jwt, err := ngsilib.DecodeJWT(token)
+	if err != nil {
+		return nil, ngsierr.New(funcName, 1, err.Error(), err)
+	}
+
+	b, err := ngsilib.JSONMarshal(jwt)
+	if err != nil {
+		return nil, ngsierr.New(funcName, 2, err.Error(), err)
+	}
+	return b, nil
+}
+
 func getKeyrockUserInfo(client *ngsilib.Client, token string) ([]byte, error) {
 	const funcName = "getKeyrockUserInfo"
 
@@ -375,7 +375,7 @@ func TestTokenCommandErrorKeyrock(t *testing.T) {
 
 	if assert.Error(t, err) {
 		ngsiErr := err.(*ngsierr.NgsiError)
-		assert.Equal(t, 3, ngsiErr.ErrNo)
+		assert.Equal(t, 4, ngsiErr.ErrNo)
 		assert.Equal(t, "token is empty", ngsiErr.Message)
 		assert.Error(t, err)
 	}
@@ -400,7 +400,7 @@ func TestTokenCommandBasic(t *testing.T) {
 
 	if assert.Error(t, err) {
 		ngsiErr := err.(*ngsierr.NgsiError)
-		assert.Equal(t, 4, ngsiErr.ErrNo)
+		assert.Equal(t, 5, ngsiErr.ErrNo)
 		assert.Equal(t, "no information available", ngsiErr.Message)
 		assert.Error(t, err)
 	}
@@ -436,11 +436,110 @@ func TestTokenCommandErrorJSONPretty(t *testing.T) {
 
 	if assert.Error(t, err) {
 		ngsiErr := err.(*ngsierr.NgsiError)
-		assert.Equal(t, 5, ngsiErr.ErrNo)
+		assert.Equal(t, 6, ngsiErr.ErrNo)
 		assert.Equal(t, "json error", ngsiErr.Message)
 	}
 }
 
+func TestTokenCommandDecode(t *testing.T) {
+	conf := `{
+		"version": "1",
+		"servers": {
+			"orion": {
+				"serverHost": "http://orion",
+				"ngsiType": "v2",
+				"idmType": "tokenproxy",
+				"idmHost": "/token",
+				"username": "testuser",
+				"password": "1234"
+			}
+		}
+	}`
+	c := setupTestWithConfig([]string{"token", "--host", "orion", "--decode"}, conf)
+
+	reqRes := helper.MockHTTPReqRes{}
+	reqRes.Res.StatusCode = http.StatusOK
+	reqRes.ResBody = []byte(`{"access_token":"eyJhbGciOiJSUzI1NiIsInR5cCI6IkpXVCJ9.eyJpc3MiOiJodHRwOi8vaWRtL3JlYWxtcy9maXdhcmUiLCJzdWIiOiJ1MSIsImF1ZCI6WyJuZ3NpIiwiYWNjb3VudCJdLCJleHAiOjE3MDAwMDAwMDAsImlhdCI6MTY5OTk5MDAwMCwicmVhbG1fYWNjZXNzIjp7InJvbGVzIjpbImFkbWluIl19LCJyZXNvdXJjZV9hY2Nlc3MiOnsib3Jpb24iOnsicm9sZXMiOlsicmVhZCJdfX19.c2ln","expires_in":1156,"refresh_token":"7cb75b47782195839ecbc7c7457f18abed853fe1","scope":["bearer"],"token_type":"Bearer"}`)
+
+	mock := helper.NewMockHTTP()
+	mock.ReqRes = append(mock.ReqRes, reqRes)
+	c.Ngsi.HTTP = mock
+
+	err := tokenCommand(c, c.Ngsi, c.Client)
+
+	if assert.NoError(t, err) {
+		actual := helper.GetStdoutString(c)
+		expected := "{\"header\":{\"alg\":\"RS256\",\"typ\":\"JWT\"},\"claims\":{\"iss\":\"http://idm/realms/fiware\",\"sub\":\"u1\",\"aud\":[\"ngsi\",\"account\"],\"exp\":1700000000,\"iat\":1699990000,\"realm_access\":{\"roles\":[\"admin\"]},\"resource_access\":{\"orion\":{\"roles\":[\"read\"]}}},\"issuer\":\"http://idm/realms/fiware\",\"subject\":\"u1\",\"audience\":[\"ngsi\",\"account\"],\"roles\":[\"admin\",\"orion:read\"],\"issuedAt\":\"2023-11-14T19:26:40Z\",\"expires\":\"2023-11-14T22:13:20Z\"}\n"
+		assert.Equal(t, expected, actual)
+	}
+}
+
+func TestTokenCommandDecodePretty(t *testing.T) {
+	conf := `{
+		"version": "1",
+		"servers": {
+			"orion": {
+				"serverHost": "http://orion",
+				"ngsiType": "v2",
+				"idmType": "tokenproxy",
+				"idmHost": "/token",
+				"username": "testuser",
+				"password": "1234"
+			}
+		}
+	}`
+	c := setupTestWithConfig([]string{"token", "--host", "orion", "--decode", "--pretty"}, conf)
+
+	reqRes := helper.MockHTTPReqRes{}
+	reqRes.Res.StatusCode = http.StatusOK
+	reqRes.ResBody = []byte(`{"access_token":"eyJhbGciOiJSUzI1NiIsInR5cCI6IkpXVCJ9.eyJpc3MiOiJodHRwOi8vaWRtL3JlYWxtcy9maXdhcmUiLCJzdWIiOiJ1MSIsImF1ZCI6WyJuZ3NpIiwiYWNjb3VudCJdLCJleHAiOjE3MDAwMDAwMDAsImlhdCI6MTY5OTk5MDAwMCwicmVhbG1fYWNjZXNzIjp7InJvbGVzIjpbImFkbWluIl19LCJyZXNvdXJjZV9hY2Nlc3MiOnsib3Jpb24iOnsicm9sZXMiOlsicmVhZCJdfX19.c2ln","expires_in":1156,"refresh_token":"7cb75b47782195839ecbc7c7457f18abed853fe1","scope":["bearer"],"token_type":"Bearer"}`)
+
+	mock := helper.NewMockHTTP()
+	mock.ReqRes = append(mock.ReqRes, reqRes)
+	c.Ngsi.HTTP = mock
+
+	err := tokenCommand(c, c.Ngsi, c.Client)
+
+	if assert.NoError(t, err) {
+		actual := helper.GetStdoutString(c)
+		expected := "{\n  \"header\": {\n    \"alg\": \"RS256\",\n    \"typ\": \"JWT\"\n  },\n  \"claims\": {\n    \"iss\": \"http://idm/realms/fiware\",\n    \"sub\": \"u1\",\n    \"aud\": [\n      \"ngsi\",\n      \"account\"\n    ],\n    \"exp\": 1700000000,\n    \"iat\": 1699990000,\n    \"realm_access\": {\n      \"roles\": [\n        \"admin\"\n      ]\n    },\n    \"resource_access\": {\n      \"orion\": {\n        \"roles\": [\n          \"read\"\n        ]\n      }\n    }\n  },\n  \"issuer\": \"http://idm/realms/fiware\",\n  \"subject\": \"u1\",\n  \"audience\": [\n    \"ngsi\",\n    \"account\"\n  ],\n  \"roles\": [\n    \"admin\",\n    \"orion:read\"\n  ],\n  \"issuedAt\": \"2023-11-14T19:26:40Z\",\n  \"expires\": \"2023-11-14T22:13:20Z\"\n}\n"
+		assert.Equal(t, expected, actual)
+	}
+}
+
+func TestTokenCommandErrorDecode(t *testing.T) {
+	conf := `{
+		"version": "1",
+		"servers": {
+			"orion": {
+				"serverHost": "http://orion",
+				"ngsiType": "v2",
+				"idmType": "tokenproxy",
+				"idmHost": "/token",
+				"username": "testuser",
+				"password": "1234"
+			}
+		}
+	}`
+	c := setupTestWithConfig([]string{"token", "--host", "orion", "--decode"}, conf)
+
+	reqRes := helper.MockHTTPReqRes{}
+	reqRes.Res.StatusCode = http.StatusOK
+	reqRes.ResBody = []byte(`{"access_token":"c312d32a36a8a1df219a807a79323bb31941f462","expires_in":1156,"refresh_token":"7cb75b47782195839ecbc7c7457f18abed853fe1","scope":["bearer"],"token_type":"Bearer"}`)
+
+	mock := helper.NewMockHTTP()
+	mock.ReqRes = append(mock.ReqRes, reqRes)
+	c.Ngsi.HTTP = mock
+
+	err := tokenCommand(c, c.Ngsi, c.Client)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 3, ngsiErr.ErrNo)
+		assert.Equal(t, "token is not a JWT", ngsiErr.Message)
+	}
+}
+
 func TestRevokeTokenCommand(t *testing.T) {
 	conf := `{
 		"version": "1",
@@ -583,6 +682,54 @@ func TestRevokeTokenCommandErrorRevokeToken(t *testing.T) {
 	}
 }
 
+func TestDecodeToken(t *testing.T) {
+	setupTest([]string{"token"})
+
+	actual, err := decodeToken("eyJhbGciOiJIUzI1NiJ9.eyJhdWQiOiJuZ3NpIiwicm9sZXMiOlsicjEiXX0.")
+
+	if assert.NoError(t, err) {
+		expected := `{"header":{"alg":"HS256"},"claims":{"aud":"ngsi","roles":["r1"]},"audience":["ngsi"],"roles":["r1"]}`
+		assert.Equal(t, expected, string(actual))
+	}
+}
+
+func TestDecodeTokenErrorJWT(t *testing.T) {
+	setupTest([]string{"token"})
+
+	_, err := decodeToken("")
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 1, ngsiErr.ErrNo)
+		assert.Equal(t, "token is not a JWT", ngsiErr.Message)
+	}
+}
+
+func TestDecodeTokenErrorJSON(t *testing.T) {
+	c := setupTest([]string{"token"})
+	helper.SetJSONEncodeErr(c.Ngsi, 0)
+
+	_, err := decodeToken("eyJhbGciOiJIUzI1NiJ9.eyJhdWQiOiJuZ3NpIiwicm9sZXMiOlsicjEiXX0.")
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 2, ngsiErr.ErrNo)
+		assert.Equal(t, "json error", ngsiErr.Message)
+	}
+}
+
+func TestRevokeTokenCommandErrorDecode(t *testing.T) {
+	c := setupTest([]string{"token", "--host", "orion", "--revoke", "--decode"})
+
+	err := revokeTokenCommand(c, c.Ngsi)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 1, ngsiErr.ErrNo)
+		assert.Equal(t, "only --revoke can be specified", ngsiErr.Message)
+	}
+}
+
 func TestGetKeyrockUserInfoError(t *testing.T) {
 	reqRes := helper.MockHTTPReqRes{}
 	reqRes.Res.StatusCode = http.StatusOK
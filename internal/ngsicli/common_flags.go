@@ -29,17 +29,31 @@ SOFTWARE.
 
 package ngsicli
 
+import (
+	"fmt"
+
+	"github.com/lets-fiware/ngsi-go/internal/ngsierr"
+)
+
 var GlobalFlags = []Flag{
 	SyslogFlag,
 	StderrFlag,
 	ConfigDirFlag,
 	ConfigFlag,
 	CacheFlag,
+	ProfileFlag,
 	MarginFlag,
 	TimeOutFlag,
 	MaxCountFlag,
 	BatchFlag,
 	InsecureSkipVerifyFlag,
+	DryRunFlag,
+	FilterFlag,
+	JSONPathFlag,
+	TemplateFlag,
+	RetryMaxFlag,
+	RetryBackoffFlag,
+	RetryOnFlag,
 	CmdNameFlag,
 }
 
@@ -67,6 +81,10 @@ var (
 		Name:  "cache",
 		Usage: "cache `FILE` name",
 	}
+	ProfileFlag = &StringFlag{
+		Name:  "profile",
+		Usage: "configuration profile `NAME`",
+	}
 	HelpFlag = &BoolFlag{
 		Name:  "help",
 		Usage: "show help",
@@ -102,6 +120,34 @@ var (
 		Name:  "insecureSkipVerify",
 		Usage: "TLS/SSL skip certificate verification",
 	}
+	DryRunFlag = &BoolFlag{
+		Name:  "dryRun",
+		Usage: "print requests that change data instead of sending them",
+	}
+	FilterFlag = &StringFlag{
+		Name:  "filter",
+		Usage: "jq style filter `EXPRESSION` applied to JSON output",
+	}
+	JSONPathFlag = &StringFlag{
+		Name:  "jsonPath",
+		Usage: "JSONPath `EXPRESSION` applied to JSON output",
+	}
+	TemplateFlag = &StringFlag{
+		Name:  "template",
+		Usage: "Go text/template `TEMPLATE` applied to each item of JSON output",
+	}
+	RetryMaxFlag = &StringFlag{
+		Name:  "retryMax",
+		Usage: "maximum number of attempts per request (1-10)",
+	}
+	RetryBackoffFlag = &StringFlag{
+		Name:  "retryBackoff",
+		Usage: "initial backoff `DURATION` between attempts (e.g. 500ms, 2s)",
+	}
+	RetryOnFlag = &StringFlag{
+		Name:  "retryOn",
+		Usage: "comma-separated HTTP status `CODES` to retry on",
+	}
 )
 
 var (
@@ -152,6 +198,11 @@ var (
 		Name:  "ngsiType",
 		Usage: "NGSI type: v2 or ld",
 	}
+	OutputFlag = &StringFlag{
+		Name:    "output",
+		Usage:   "output format (`FORMAT`: csv, tsv or table)",
+		Choices: []string{"csv", "tsv", "table"},
+	}
 )
 
 var (
@@ -176,4 +227,24 @@ var (
 		Usage: "run command",
 		Value: false,
 	}
+	ParallelFlag = &Int64Flag{
+		Name:  "parallel",
+		Usage: "number of parallel workers (1-64)",
+		Value: 1,
+	}
 )
+
+// ParallelWorkers returns the number of workers given by ParallelFlag
+func ParallelWorkers(c *Context) (int, error) {
+	const funcName = "ParallelWorkers"
+
+	n := c.Int64("parallel")
+	if c.IsSet("parallel") && (n < 1 || n > 64) {
+		return 0, ngsierr.New(funcName, 1, fmt.Sprintf("parallel error: %d (1-64)", n), nil)
+	}
+	if n < 1 {
+		n = 1
+	}
+
+	return int(n), nil
+}
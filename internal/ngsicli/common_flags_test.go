@@ -0,0 +1,74 @@
+/*
+MIT License
+
+Copyright (c) 2020-2024 Kazuhito Suda
+
+This file is part of NGSI Go
+
+https://github.com/lets-fiware/ngsi-go
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+
+*/
+
+package ngsicli
+
+import (
+	"testing"
+
+	"github.com/lets-fiware/ngsi-go/internal/assert"
+	"github.com/lets-fiware/ngsi-go/internal/ngsierr"
+)
+
+func TestParallelWorkers(t *testing.T) {
+	cases := []struct {
+		flags    []Flag
+		expected int
+	}{
+		{flags: []Flag{&Int64Flag{Name: "parallel", Value: 1}}, expected: 1},
+		{flags: []Flag{&Int64Flag{Name: "parallel", Value: 1, Set: true}}, expected: 1},
+		{flags: []Flag{&Int64Flag{Name: "parallel", Value: 64, Set: true}}, expected: 64},
+		{flags: []Flag{}, expected: 1},
+	}
+
+	for _, tc := range cases {
+		c := &Context{Flags: tc.flags}
+
+		actual, err := ParallelWorkers(c)
+
+		if assert.NoError(t, err) {
+			assert.Equal(t, tc.expected, actual)
+		}
+	}
+}
+
+func TestParallelWorkersError(t *testing.T) {
+	cases := []int64{0, 65}
+
+	for _, n := range cases {
+		c := &Context{Flags: []Flag{&Int64Flag{Name: "parallel", Value: n, Set: true}}}
+
+		_, err := ParallelWorkers(c)
+
+		if assert.Error(t, err) {
+			ngsiErr := err.(*ngsierr.NgsiError)
+			assert.Equal(t, 1, ngsiErr.ErrNo)
+		}
+	}
+}
@@ -0,0 +1,163 @@
+/*
+MIT License
+
+Copyright (c) 2020-2024 Kazuhito Suda
+
+This file is part of NGSI Go
+
+https://github.com/lets-fiware/ngsi-go
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+
+*/
+
+package ngsicli
+
+import (
+	"sort"
+	"strings"
+)
+
+// cmdLine is a command line walked along the command tree
+type cmdLine struct {
+	cmd     *Command
+	flags   []Flag
+	set     map[string]bool
+	value   Flag
+	unknown bool
+}
+
+// walkCmdLine walks the command tree along args. The global flags are available until a command is found.
+// When the last arg is a flag which takes a value, the flag is set to value.
+func walkCmdLine(r *App, args []string) *cmdLine {
+	line := &cmdLine{flags: r.Flags, set: map[string]bool{}}
+
+	for i := 0; i < len(args); i++ {
+		name, alias, opt := isOption(args[i])
+		if opt {
+			f := findFlag(line.flags, name, alias)
+			if f == nil {
+				continue
+			}
+			line.set[f.FlagName()] = true
+			if _, ok := f.(*BoolFlag); ok {
+				continue
+			}
+			if i == len(args)-1 {
+				line.value = f
+			}
+			i++
+			continue
+		}
+		if line.cmd == nil {
+			line.cmd = searchSubCommnad(r.Commands, name)
+			if line.cmd == nil {
+				line.unknown = true
+				break
+			}
+			line.flags = line.cmd.Flags
+		} else if line.cmd.Subcommands != nil {
+			cmd := searchSubCommnad(line.cmd.Subcommands, name)
+			if cmd == nil {
+				line.unknown = true
+				break
+			}
+			line.cmd = cmd
+			line.flags = append(append([]Flag{}, line.flags...), cmd.Flags...)
+		}
+	}
+
+	return line
+}
+
+func findFlag(flags []Flag, name, alias string) Flag {
+	for _, f := range flags {
+		if f.Check(name, alias) {
+			return f
+		}
+	}
+	return nil
+}
+
+// CommandFlags returns the flags available to the command of args and whether each of them is specified in args
+func (r *App) CommandFlags(args []string) ([]Flag, map[string]bool) {
+	line := walkCmdLine(r, args)
+	if line.cmd == nil {
+		return nil, line.set
+	}
+	return line.flags, line.set
+}
+
+// Complete returns the candidates for word which follows args on a command line.
+// When word is the value of a flag, the flag is returned so that the caller can add candidates
+// which depend on the configuration or on a broker.
+func (r *App) Complete(args []string, word string) ([]string, Flag) {
+	line := walkCmdLine(r, args)
+
+	var candidates []string
+
+	switch {
+	case line.unknown:
+		return nil, nil
+	case line.value != nil:
+		if f, ok := line.value.(*StringFlag); ok {
+			candidates = completeWords(f.Choices, word)
+		}
+		return candidates, line.value
+	case strings.HasPrefix(word, "-"):
+		flags := line.flags
+		if line.cmd != nil {
+			flags = append(append([]Flag{}, flags...), &BoolFlag{Name: "help"})
+		}
+		for _, f := range flags {
+			if !f.FlagHidden() && !line.set[f.FlagName()] {
+				candidates = append(candidates, "--"+f.FlagName())
+			}
+		}
+		candidates = completeWords(candidates, word)
+	case line.cmd == nil:
+		candidates = completeCommands(r.Commands, word)
+	case line.cmd.Subcommands != nil:
+		candidates = completeCommands(line.cmd.Subcommands, word)
+	}
+
+	return candidates, nil
+}
+
+func completeCommands(cmds []*Command, word string) []string {
+	var names []string
+	for _, cmd := range cmds {
+		if !cmd.Hidden {
+			names = append(names, cmd.Name)
+		}
+	}
+	return completeWords(names, word)
+}
+
+// completeWords returns the words which start with prefix in sorted order
+func completeWords(words []string, prefix string) []string {
+	var candidates []string
+	for _, w := range words {
+		if strings.HasPrefix(w, prefix) {
+			candidates = append(candidates, w)
+		}
+	}
+	sort.Strings(candidates)
+	return candidates
+}
@@ -0,0 +1,183 @@
+/*
+MIT License
+
+Copyright (c) 2020-2024 Kazuhito Suda
+
+This file is part of NGSI Go
+
+https://github.com/lets-fiware/ngsi-go
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+
+*/
+
+package ngsicli
+
+import (
+	"testing"
+
+	"github.com/lets-fiware/ngsi-go/internal/assert"
+)
+
+func setupTestCompleteApp() *App {
+	return &App{
+		Flags: []Flag{
+			&StringFlag{Name: "stderr", Choices: []string{"off", "err", "info", "debug"}},
+			&BoolFlag{Name: "batch", Aliases: []string{"B"}},
+			&Int64Flag{Name: "margin", Hidden: true},
+		},
+		Commands: []*Command{
+			{
+				Name: "list",
+				Subcommands: []*Command{
+					{
+						Name:  "entities",
+						Flags: []Flag{&StringFlag{Name: "type", Aliases: []string{"t"}}, &BoolFlag{Name: "count"}},
+					},
+					{Name: "types"},
+				},
+				Flags: []Flag{&StringFlag{Name: "host", Aliases: []string{"h"}}},
+			},
+			{Name: "version", Flags: []Flag{&StringFlag{Name: "host", Aliases: []string{"h"}}}},
+			{Name: "hidden", Hidden: true},
+		},
+	}
+}
+
+func TestCompleteCommands(t *testing.T) {
+	r := setupTestCompleteApp()
+
+	actual, f := r.Complete([]string{"--batch"}, "")
+
+	assert.Equal(t, []string{"list", "version"}, actual)
+	assert.Equal(t, nil, f)
+}
+
+func TestCompleteCommandsPrefix(t *testing.T) {
+	r := setupTestCompleteApp()
+
+	actual, _ := r.Complete([]string{}, "v")
+
+	assert.Equal(t, []string{"version"}, actual)
+}
+
+func TestCompleteSubcommands(t *testing.T) {
+	r := setupTestCompleteApp()
+
+	actual, _ := r.Complete([]string{"list", "--host", "orion"}, "")
+
+	assert.Equal(t, []string{"entities", "types"}, actual)
+}
+
+func TestCompleteGlobalFlags(t *testing.T) {
+	r := setupTestCompleteApp()
+
+	actual, _ := r.Complete([]string{"-B"}, "--")
+
+	assert.Equal(t, []string{"--stderr"}, actual)
+}
+
+func TestCompleteFlags(t *testing.T) {
+	r := setupTestCompleteApp()
+
+	actual, _ := r.Complete([]string{"list", "entities", "--count"}, "-")
+
+	assert.Equal(t, []string{"--help", "--host", "--type"}, actual)
+}
+
+func TestCompleteFlagsPrefix(t *testing.T) {
+	r := setupTestCompleteApp()
+
+	actual, _ := r.Complete([]string{"list", "entities", "--unknown"}, "--t")
+
+	assert.Equal(t, []string{"--type"}, actual)
+}
+
+func TestCompleteChoices(t *testing.T) {
+	r := setupTestCompleteApp()
+
+	actual, f := r.Complete([]string{"--stderr"}, "")
+
+	assert.Equal(t, []string{"debug", "err", "info", "off"}, actual)
+	assert.Equal(t, "stderr", f.FlagName())
+}
+
+func TestCompleteValue(t *testing.T) {
+	r := setupTestCompleteApp()
+
+	actual, f := r.Complete([]string{"list", "entities", "-t"}, "B")
+
+	assert.Equal(t, 0, len(actual))
+	assert.Equal(t, "type", f.FlagName())
+}
+
+func TestCompleteValueInt64(t *testing.T) {
+	r := setupTestCompleteApp()
+
+	actual, f := r.Complete([]string{"--margin"}, "")
+
+	assert.Equal(t, 0, len(actual))
+	assert.Equal(t, "margin", f.FlagName())
+}
+
+func TestCompleteUnknownCommand(t *testing.T) {
+	r := setupTestCompleteApp()
+
+	actual, f := r.Complete([]string{"unknown"}, "")
+
+	assert.Equal(t, 0, len(actual))
+	assert.Equal(t, nil, f)
+}
+
+func TestCompleteUnknownSubcommand(t *testing.T) {
+	r := setupTestCompleteApp()
+
+	actual, _ := r.Complete([]string{"list", "unknown"}, "")
+
+	assert.Equal(t, 0, len(actual))
+}
+
+func TestCompleteNoSubcommands(t *testing.T) {
+	r := setupTestCompleteApp()
+
+	actual, _ := r.Complete([]string{"version"}, "")
+
+	assert.Equal(t, 0, len(actual))
+}
+
+func TestAppCommandFlags(t *testing.T) {
+	r := setupTestCompleteApp()
+
+	flags, set := r.CommandFlags([]string{"list", "entities", "--host", "orion"})
+
+	assert.Equal(t, 3, len(flags))
+	assert.Equal(t, "host", flags[0].FlagName())
+	assert.Equal(t, "type", flags[1].FlagName())
+	assert.Equal(t, true, set["host"])
+	assert.Equal(t, false, set["type"])
+}
+
+func TestAppCommandFlagsNoCommand(t *testing.T) {
+	r := setupTestCompleteApp()
+
+	flags, set := r.CommandFlags([]string{"--batch"})
+
+	assert.Equal(t, 0, len(flags))
+	assert.Equal(t, true, set["batch"])
+}
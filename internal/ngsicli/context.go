@@ -172,6 +172,17 @@ func (c *Context) IsSetOR(params []string) bool {
 	return false
 }
 
+// IsOutputFiltered returns true when the JSON output is passed through --filter, --jsonPath or --template
+func (c *Context) IsOutputFiltered() bool {
+	names := []string{"filter", "jsonPath", "template"}
+	for _, f := range c.GlobalFlags {
+		if ngsilib.Contains(names, f.FlagName()) && f.IsSet() {
+			return true
+		}
+	}
+	return c.IsSetOR(names)
+}
+
 func (c *Context) IsSetAND(params []string) bool {
 	for _, param := range params {
 		if !c.IsSet(param) {
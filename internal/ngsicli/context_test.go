@@ -211,6 +211,38 @@ func TestIsSetORFalse(t *testing.T) {
 	assert.Equal(t, expected, actual)
 }
 
+func TestIsOutputFilteredGlobalFlags(t *testing.T) {
+	c := &Context{
+		GlobalFlags: []Flag{
+			&StringFlag{Name: "filter", Set: false},
+			&StringFlag{Name: "template", Set: true},
+		},
+	}
+
+	assert.Equal(t, true, c.IsOutputFiltered())
+}
+
+func TestIsOutputFilteredFlags(t *testing.T) {
+	c := &Context{
+		Flags: []Flag{
+			&StringFlag{Name: "jsonPath", Set: true},
+		},
+	}
+
+	assert.Equal(t, true, c.IsOutputFiltered())
+}
+
+func TestIsOutputFilteredFalse(t *testing.T) {
+	c := &Context{
+		GlobalFlags: []Flag{
+			&StringFlag{Name: "filter", Set: false},
+			&StringFlag{Name: "host", Set: true},
+		},
+	}
+
+	assert.Equal(t, false, c.IsOutputFiltered())
+}
+
 func TestIsSetANDTrue(t *testing.T) {
 	c := &Context{
 		Flags: []Flag{
@@ -138,8 +138,7 @@ func commandList(c *Context) string {
 		}
 		for _, cmd := range cat {
 			if !cmd.Hidden {
-				s := cmd.Name + "          "
-				msg += fmt.Sprintf("     %s  %s\n", s[:max], cmd.Usage)
+				msg += fmt.Sprintf("     %-*s  %s\n", max, cmd.Name, cmd.Usage)
 			}
 		}
 	}
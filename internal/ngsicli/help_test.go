@@ -179,6 +179,21 @@ func TestCommandList(t *testing.T) {
 
 	assert.Equal(t, expected, actual)
 }
+
+func TestCommandListLongName(t *testing.T) {
+	cmd := []*Command{
+		{Name: "cp", Usage: "copy entities"},
+		{Name: "subscriptions", Usage: "diagnose subscriptions"},
+	}
+
+	c := &Context{App: &App{Commands: cmd}}
+
+	actual := commandList(c)
+	expected := "COMMANDS:\n   help, h  Shows a list of commands or help for one command\n   :\n     cp             copy entities\n     subscriptions  diagnose subscriptions\n\n"
+
+	assert.Equal(t, expected, actual)
+}
+
 func TestSubCommandList(t *testing.T) {
 	cmd := &Command{
 		Subcommands: []*Command{
@@ -509,6 +509,10 @@ func (t *MockTimeLib) Format(layout string) string {
 	return t.tTime.Format(layout)
 }
 
+func (t *MockTimeLib) Sleep(d time.Duration) {
+	t.unixTime += int64(d / time.Second)
+}
+
 type MockNetLib struct {
 	AddrErr              error
 	ListenAndServeErr    error
@@ -528,16 +532,25 @@ func (n *MockNetLib) ListenAndServe(addr string, handler http.Handler) error {
 func (n *MockNetLib) ListenAndServeTLS(addr, certFile, keyFile string, handler http.Handler) error {
 	return n.ListenAndServeTLSErr
 }
+func (n *MockNetLib) DialTimeout(network, address string, timeout time.Duration) (net.Conn, error) {
+	return nil, nil
+}
+func (n *MockNetLib) Listen(network, address string) (net.Listener, error) {
+	return nil, nil
+}
 
 // MockIoutilLib
 type MockIoutilLib struct {
-	CopyErr      error
-	ReadFullErr  error
-	ReadFullData []byte
-	WriteFileErr error
-	ReadFileErr  error
-	ReadFileData []byte
-	WriteSkip    bool
+	CopyErr       error
+	ReadFullErr   error
+	ReadFullData  []byte
+	WriteFileErr  error
+	ReadFileErr   error
+	ReadFileData  []byte
+	WriteSkip     bool
+	OpenErr       error
+	CreateTempErr error
+	RenameErr     error
 }
 
 func (i *MockIoutilLib) Copy(dst io.Writer, src io.Reader) (int64, error) {
@@ -568,6 +581,22 @@ func (i *MockIoutilLib) WriteFile(filename string, data []byte, perm os.FileMode
 	return os.WriteFile(filename, data, perm)
 }
 
+func (i *MockIoutilLib) AppendFile(filename string, data []byte, perm os.FileMode) error {
+	if i.WriteSkip {
+		return nil
+	}
+	if i.WriteFileErr != nil {
+		return i.WriteFileErr
+	}
+	f, err := os.OpenFile(filename, os.O_APPEND|os.O_CREATE|os.O_WRONLY, perm)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(data)
+	_ = f.Close()
+	return err
+}
+
 func (i *MockIoutilLib) ReadFile(filename string) ([]byte, error) {
 	if i.ReadFileErr != nil {
 		return nil, i.ReadFileErr
@@ -578,6 +607,31 @@ func (i *MockIoutilLib) ReadFile(filename string) ([]byte, error) {
 	return os.ReadFile(filename)
 }
 
+func (i *MockIoutilLib) Open(name string) (*os.File, error) {
+	if i.OpenErr != nil {
+		return nil, i.OpenErr
+	}
+	return os.Open(name)
+}
+
+func (i *MockIoutilLib) CreateTemp(dir, pattern string) (*os.File, error) {
+	if i.CreateTempErr != nil {
+		return nil, i.CreateTempErr
+	}
+	return os.CreateTemp(dir, pattern)
+}
+
+func (i *MockIoutilLib) Rename(oldpath, newpath string) error {
+	if i.RenameErr != nil {
+		return i.RenameErr
+	}
+	return os.Rename(oldpath, newpath)
+}
+
+func (i *MockIoutilLib) Remove(name string) error {
+	return os.Remove(name)
+}
+
 // MockFilePathLib
 type MockFilePathLib struct {
 	PathAbsErr error
@@ -595,6 +649,10 @@ func (i *MockFilePathLib) FilePathBase(path string) string {
 	return filepath.Base(path)
 }
 
+func (i *MockFilePathLib) FilePathGlob(pattern string) ([]string, error) {
+	return filepath.Glob(pattern)
+}
+
 // MockZipLib
 type MockZipLib struct {
 	Zip       error
@@ -611,6 +669,10 @@ func (z *MockZipLib) NewReader(r io.ReaderAt, size int64) (*zip.Reader, error) {
 	return zip.NewReader(r, size)
 }
 
+func (z *MockZipLib) NewWriter(w io.Writer) *zip.Writer {
+	return zip.NewWriter(w)
+}
+
 type MockMultiPart struct {
 	CreatePartErr error
 	CloseErr      error
@@ -707,6 +769,10 @@ var (
 		Name:  "insecureSkipVerify",
 		Usage: "TLS/SSL skip certificate verification",
 	}
+	dryRunFlag = &BoolFlag{
+		Name:  "dryRun",
+		Usage: "print requests that change data instead of sending them",
+	}
 	hostFlag = &StringFlag{
 		Name:    "host",
 		Usage:   "broker or server host `VALUE`",
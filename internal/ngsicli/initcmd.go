@@ -33,6 +33,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"text/template"
 	"time"
 
 	"github.com/lets-fiware/ngsi-go/internal/ngsierr"
@@ -61,6 +62,10 @@ func InitCmd(c *Context) (*ngsilib.NGSI, error) {
 		s := c.String("configDir")
 		ngsi.ConfigDir = &s
 	}
+	if c.IsSet("profile") {
+		s := c.String("profile")
+		ngsi.ProfileFlag = &s
+	}
 
 	err := ngsi.InitConfig(file)
 	if err != nil {
@@ -82,6 +87,7 @@ func InitCmd(c *Context) (*ngsilib.NGSI, error) {
 	initHiddenOptions(ngsi, c)
 
 	ngsi.InsecureSkipVerify = c.Bool("insecureSkipVerify")
+	ngsi.DryRun = c.Bool("dryRun")
 
 	ngsi.Logging(ngsilib.LogInfo, fmt.Sprintf("%s (git_hash:%s)\n", Version, Revision))
 
@@ -91,6 +97,46 @@ func InitCmd(c *Context) (*ngsilib.NGSI, error) {
 		return nil, ngsierr.New(funcName, 5, err.Error(), err)
 	}
 
+	err = initRetryOption(ngsi, c, prevArgs)
+	if err != nil {
+		return nil, ngsierr.New(funcName, 6, err.Error(), err)
+	}
+
+	err = initFilterOption(ngsi, c)
+	if err != nil {
+		return nil, ngsierr.New(funcName, 7, err.Error(), err)
+	}
+
+	return ngsi, nil
+}
+
+// initSession applies the global options of a command line run in a shell session to NGSI.
+// The options which load the config, the token cache or the logger again are not available.
+func initSession(c *Context, ngsi *ngsilib.NGSI) (*ngsilib.NGSI, error) {
+	const funcName = "initSession"
+
+	for _, name := range []string{"configDir", "config", "cache", "profile", "batch", "syslog", "stderr"} {
+		if c.IsSet(name) {
+			return nil, ngsierr.New(funcName, 1, "--"+name+" cannot be specified in shell", nil)
+		}
+	}
+
+	initHiddenOptions(ngsi, c)
+	ngsi.ResetProfileConfirmation()
+
+	ngsi.InsecureSkipVerify = c.Bool("insecureSkipVerify")
+	ngsi.DryRun = c.Bool("dryRun")
+
+	err := initRetryOption(ngsi, c, ngsi.GetPreviousArgs())
+	if err != nil {
+		return nil, ngsierr.New(funcName, 2, err.Error(), err)
+	}
+
+	err = initFilterOption(ngsi, c)
+	if err != nil {
+		return nil, ngsierr.New(funcName, 3, err.Error(), err)
+	}
+
 	return ngsi, nil
 }
 
@@ -172,6 +218,34 @@ func initHiddenOptions(ngsi *ngsilib.NGSI, c *Context) {
 	}
 }
 
+func initRetryOption(ngsi *ngsilib.NGSI, c *Context, prevArgs *ngsilib.Settings) error {
+	const funcName = "initRetryOption"
+
+	items := []struct {
+		name  string
+		value *string
+	}{
+		{"retryMax", &prevArgs.RetryMax},
+		{"retryBackoff", &prevArgs.RetryBackoff},
+		{"retryOn", &prevArgs.RetryOn},
+	}
+
+	for _, item := range items {
+		if c.IsSet(item.name) {
+			*item.value = c.String(item.name)
+			ngsi.Updated = true
+		}
+	}
+
+	retry := ngsilib.NewRetryPolicy()
+	if err := retry.SetParams(prevArgs.RetryMax, prevArgs.RetryBackoff, prevArgs.RetryOn); err != nil {
+		return ngsierr.New(funcName, 1, err.Error(), err)
+	}
+	ngsi.Retry = retry
+
+	return nil
+}
+
 func initCacheFileOption(ngsi *ngsilib.NGSI, c *Context, prevArgs *ngsilib.Settings) *string {
 	var cache *string
 
@@ -187,3 +261,47 @@ func initCacheFileOption(ngsi *ngsilib.NGSI, c *Context, prevArgs *ngsilib.Setti
 
 	return cache
 }
+
+func initFilterOption(ngsi *ngsilib.NGSI, c *Context) error {
+	const funcName = "initFilterOption"
+
+	if !c.IsOutputFiltered() {
+		return nil
+	}
+	if c.IsSet("filter") && c.IsSet("jsonPath") {
+		return ngsierr.New(funcName, 1, "specify either --filter or --jsonPath", nil)
+	}
+
+	var filter *ngsilib.JSONFilter
+	var err error
+	if c.IsSet("filter") {
+		filter, err = ngsilib.NewJSONFilter(c.String("filter"))
+		if err != nil {
+			return ngsierr.New(funcName, 2, err.Error(), err)
+		}
+	} else if c.IsSet("jsonPath") {
+		filter, err = ngsilib.NewJSONPath(c.String("jsonPath"))
+		if err != nil {
+			return ngsierr.New(funcName, 3, err.Error(), err)
+		}
+	}
+
+	var tmpl *template.Template
+	if c.IsSet("template") {
+		b, err := ngsi.ReadAll(c.String("template"))
+		if err != nil {
+			return ngsierr.New(funcName, 4, err.Error(), err)
+		}
+		tmpl, err = ngsilib.NewTemplate(string(b))
+		if err != nil {
+			return ngsierr.New(funcName, 5, err.Error(), err)
+		}
+	}
+
+	// Requests printed by --dryRun are not JSON, so they are printed as they are.
+	if !ngsi.DryRun {
+		ngsi.StdWriter = ngsilib.NewFilterWriter(ngsi.StdWriter, filter, tmpl)
+	}
+
+	return nil
+}
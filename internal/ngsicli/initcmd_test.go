@@ -97,6 +97,41 @@ func TestInitCmdConfigDir(t *testing.T) {
 	}
 }
 
+func TestInitCmdProfile(t *testing.T) {
+	_ = setupTestInitNGSI()
+
+	f := ProfileFlag.Copy(true)
+	err := f.SetValue("default")
+	assert.NoError(t, err)
+
+	c := &Context{Flags: []Flag{f}}
+
+	ngsi, err := InitCmd(c)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, "default", *ngsi.ProfileFlag)
+		assert.Equal(t, "default", ngsi.CurrentProfile())
+	}
+}
+
+func TestInitCmdErrorProfile(t *testing.T) {
+	_ = setupTestInitNGSI()
+
+	f := ProfileFlag.Copy(true)
+	err := f.SetValue("prod")
+	assert.NoError(t, err)
+
+	c := &Context{Flags: []Flag{f}}
+
+	_, err = InitCmd(c)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 2, ngsiErr.ErrNo)
+		assert.Equal(t, "profile not found: prod", ngsiErr.Message)
+	}
+}
+
 func TestInitCmdConfig(t *testing.T) {
 	_ = setupTestInitNGSI()
 
@@ -146,6 +181,22 @@ func TestInitCmdinsecureSkipVerifyFlagTrue(t *testing.T) {
 	}
 }
 
+func TestInitCmdDryRunFlag(t *testing.T) {
+	_ = setupTestInitNGSI()
+
+	f := dryRunFlag.Copy(true)
+	err := f.SetValue(true)
+	assert.NoError(t, err)
+
+	c := &Context{Flags: []Flag{f}}
+
+	ngsi, err := InitCmd(c)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, true, ngsi.DryRun)
+	}
+}
+
 func TestInitCmdErrorConfigDirConfig(t *testing.T) {
 	_ = setupTestInitNGSI()
 
@@ -501,6 +552,173 @@ func TestInitHiddenOptionsMaxCount(t *testing.T) {
 	}
 }
 
+func TestInitRetryOption(t *testing.T) {
+	ngsi := setupTestInitNGSI()
+
+	f := RetryMaxFlag.Copy(true)
+	_ = f.SetValue("3")
+	c := &Context{Flags: []Flag{f}}
+
+	prevArgs := &ngsilib.Settings{RetryOn: "503"}
+
+	err := initRetryOption(ngsi, c, prevArgs)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, 3, ngsi.Retry.MaxAttempts)
+		assert.Equal(t, []int{503}, ngsi.Retry.RetryOn)
+		assert.Equal(t, "3", prevArgs.RetryMax)
+		assert.Equal(t, true, ngsi.Updated)
+	}
+}
+
+func TestInitRetryOptionError(t *testing.T) {
+	ngsi := setupTestInitNGSI()
+
+	f := RetryBackoffFlag.Copy(true)
+	_ = f.SetValue("1y")
+	c := &Context{Flags: []Flag{f}}
+
+	prevArgs := &ngsilib.Settings{}
+
+	err := initRetryOption(ngsi, c, prevArgs)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 1, ngsiErr.ErrNo)
+		assert.Equal(t, "retryBackoff error: 1y", ngsiErr.Message)
+	}
+}
+
+func TestInitCmdErrorInitFilterOption(t *testing.T) {
+	_ = setupTestInitNGSI()
+
+	f := FilterFlag.Copy(true)
+	_ = f.SetValue(".[")
+	c := &Context{Flags: []Flag{f}}
+
+	_, err := InitCmd(c)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 7, ngsiErr.ErrNo)
+		assert.Equal(t, "unexpected end of expression", ngsiErr.Message)
+	}
+}
+
+func TestInitFilterOption(t *testing.T) {
+	ngsi := setupTestInitNGSI()
+	w := ngsi.StdWriter
+
+	f := FilterFlag.Copy(true)
+	_ = f.SetValue(".id")
+	c := &Context{Flags: []Flag{f}}
+
+	err := initFilterOption(ngsi, c)
+
+	if assert.NoError(t, err) {
+		fw, ok := ngsi.StdWriter.(*ngsilib.FilterWriter)
+		if assert.Equal(t, true, ok) {
+			assert.Equal(t, w, fw.Writer)
+		}
+	}
+}
+
+func TestInitFilterOptionJSONPath(t *testing.T) {
+	ngsi := setupTestInitNGSI()
+
+	f := JSONPathFlag.Copy(true)
+	_ = f.SetValue("$.id")
+	c := &Context{Flags: []Flag{f}}
+
+	err := initFilterOption(ngsi, c)
+
+	if assert.NoError(t, err) {
+		_, ok := ngsi.StdWriter.(*ngsilib.FilterWriter)
+		assert.Equal(t, true, ok)
+	}
+}
+
+func TestInitFilterOptionNotSet(t *testing.T) {
+	ngsi := setupTestInitNGSI()
+	w := ngsi.StdWriter
+
+	c := &Context{}
+
+	err := initFilterOption(ngsi, c)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, w, ngsi.StdWriter)
+	}
+}
+
+func TestInitFilterOptionDryRun(t *testing.T) {
+	ngsi := setupTestInitNGSI()
+	ngsi.DryRun = true
+	w := ngsi.StdWriter
+
+	f := FilterFlag.Copy(true)
+	_ = f.SetValue(".id")
+	c := &Context{Flags: []Flag{f}}
+
+	err := initFilterOption(ngsi, c)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, w, ngsi.StdWriter)
+	}
+}
+
+func TestInitFilterOptionTemplate(t *testing.T) {
+	ngsi := setupTestInitNGSI()
+
+	f := TemplateFlag.Copy(true)
+	_ = f.SetValue("{{.id}}")
+	c := &Context{Flags: []Flag{f}}
+
+	err := initFilterOption(ngsi, c)
+
+	if assert.NoError(t, err) {
+		fw, ok := ngsi.StdWriter.(*ngsilib.FilterWriter)
+		if assert.Equal(t, true, ok) {
+			assert.Equal(t, (*ngsilib.JSONFilter)(nil), fw.Filter)
+			assert.Equal(t, "template", fw.Template.Name())
+		}
+	}
+}
+
+func TestInitFilterOptionErrorBoth(t *testing.T) {
+	ngsi := setupTestInitNGSI()
+
+	f1 := FilterFlag.Copy(true)
+	_ = f1.SetValue(".id")
+	f2 := JSONPathFlag.Copy(true)
+	_ = f2.SetValue("$.id")
+	c := &Context{Flags: []Flag{f1, f2}}
+
+	err := initFilterOption(ngsi, c)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 1, ngsiErr.ErrNo)
+		assert.Equal(t, "specify either --filter or --jsonPath", ngsiErr.Message)
+	}
+}
+
+func TestInitFilterOptionErrorJSONPath(t *testing.T) {
+	ngsi := setupTestInitNGSI()
+
+	f := JSONPathFlag.Copy(true)
+	_ = f.SetValue(".id")
+	c := &Context{Flags: []Flag{f}}
+
+	err := initFilterOption(ngsi, c)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 3, ngsiErr.ErrNo)
+		assert.Equal(t, "JSONPath must start with $: .id", ngsiErr.Message)
+	}
+}
+
 func TestInitCacheFileOption(t *testing.T) {
 	ngsi := setupTestInitNGSI()
 
@@ -540,3 +758,118 @@ func TestInitCacheFileOptionCacheFlag(t *testing.T) {
 
 	assert.Equal(t, "ngsi-cache.json", *actual)
 }
+
+func TestInitFilterOptionErrorReadAll(t *testing.T) {
+	ngsi := setupTestInitNGSI()
+
+	f := TemplateFlag.Copy(true)
+	_ = f.SetValue("@")
+	c := &Context{Flags: []Flag{f}}
+
+	err := initFilterOption(ngsi, c)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 4, ngsiErr.ErrNo)
+		assert.Equal(t, "file name error", ngsiErr.Message)
+	}
+}
+
+func TestInitFilterOptionErrorTemplate(t *testing.T) {
+	ngsi := setupTestInitNGSI()
+
+	f := TemplateFlag.Copy(true)
+	_ = f.SetValue("{{.id")
+	c := &Context{Flags: []Flag{f}}
+
+	err := initFilterOption(ngsi, c)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 5, ngsiErr.ErrNo)
+		assert.Equal(t, "template: template:1: unclosed action", ngsiErr.Message)
+	}
+}
+
+func TestInitSession(t *testing.T) {
+	c := setupTestInitCmd()
+
+	fs := []Flag{InsecureSkipVerifyFlag.Copy(true), DryRunFlag.Copy(true), TimeOutFlag.Copy(true)}
+	_ = fs[0].SetValue(true)
+	_ = fs[1].SetValue(true)
+	_ = fs[2].SetValue(int64(30))
+	ctx := &Context{Flags: fs}
+
+	ngsi, err := initSession(ctx, c.Ngsi)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, c.Ngsi, ngsi)
+		assert.Equal(t, true, ngsi.InsecureSkipVerify)
+		assert.Equal(t, true, ngsi.DryRun)
+		assert.Equal(t, 30*time.Second, ngsi.Timeout)
+	}
+}
+
+func TestInitSessionErrorFlag(t *testing.T) {
+	c := setupTestInitCmd()
+
+	f := SyslogFlag.Copy(true)
+	_ = f.SetValue("info")
+	ctx := &Context{Flags: []Flag{f}}
+
+	_, err := initSession(ctx, c.Ngsi)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 1, ngsiErr.ErrNo)
+		assert.Equal(t, "--syslog cannot be specified in shell", ngsiErr.Message)
+	}
+}
+
+func TestInitSessionErrorProfile(t *testing.T) {
+	c := setupTestInitCmd()
+
+	f := ProfileFlag.Copy(true)
+	_ = f.SetValue("prod")
+	ctx := &Context{Flags: []Flag{f}}
+
+	_, err := initSession(ctx, c.Ngsi)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 1, ngsiErr.ErrNo)
+		assert.Equal(t, "--profile cannot be specified in shell", ngsiErr.Message)
+	}
+}
+
+func TestInitSessionErrorRetry(t *testing.T) {
+	c := setupTestInitCmd()
+
+	f := RetryBackoffFlag.Copy(true)
+	_ = f.SetValue("1y")
+	ctx := &Context{Flags: []Flag{f}}
+
+	_, err := initSession(ctx, c.Ngsi)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 2, ngsiErr.ErrNo)
+		assert.Equal(t, "retryBackoff error: 1y", ngsiErr.Message)
+	}
+}
+
+func TestInitSessionErrorFilter(t *testing.T) {
+	c := setupTestInitCmd()
+
+	f := FilterFlag.Copy(true)
+	_ = f.SetValue(".[")
+	ctx := &Context{Flags: []Flag{f}}
+
+	_, err := initSession(ctx, c.Ngsi)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 3, ngsiErr.ErrNo)
+		assert.Equal(t, "unexpected end of expression", ngsiErr.Message)
+	}
+}
@@ -57,6 +57,8 @@ type App struct {
 	Flags       []Flag
 	Compiled    time.Time
 	Copyright   string
+
+	session *ngsilib.NGSI
 }
 
 type Command struct {
@@ -101,7 +103,20 @@ func (r *App) Run(args []string) error {
 		return nil
 	}
 
+	if w, ok := c.Ngsi.StdWriter.(*ngsilib.FilterWriter); ok {
+		w.Pretty = c.Bool("pretty")
+	}
 	err = command.Action(c, c.Ngsi, c.Client)
+	if ngsilib.IsDryRun(err) {
+		err = nil
+	}
+	if w, ok := c.Ngsi.StdWriter.(*ngsilib.FilterWriter); ok {
+		c.Ngsi.StdWriter = w.Writer
+		e := w.Flush()
+		if err == nil && e != nil {
+			err = ngsierr.New(funcName, 3, e.Error(), e)
+		}
+	}
 	if c.Ngsi.Updated && c.Ngsi.GetPreviousArgs().UsePreviousArgs {
 		e := c.Ngsi.SavePreviousArgs()
 		if e != nil {
@@ -111,6 +126,19 @@ func (r *App) Run(args []string) error {
 	return err
 }
 
+// RunSession runs a command line of a shell session. NGSI initialized by InitCmd when the session
+// started is used as it is, so that the config and the token cache are not loaded again.
+func (r *App) RunSession(ngsi *ngsilib.NGSI, args []string) error {
+	r.session = ngsi
+	w := ngsi.StdWriter
+	defer func() {
+		r.session = nil
+		ngsi.StdWriter = w
+	}()
+
+	return r.Run(args)
+}
+
 func (r *App) Parse(args []string) (*Command, *Context, error) {
 	const funcName = "Parse"
 
@@ -152,7 +180,12 @@ func ngsiRun(r *App, args []string) (*Command, *Context, error) {
 		return nil, nil, nil
 	}
 
-	ngsi, err := InitCmd(c)
+	var ngsi *ngsilib.NGSI
+	if r.session != nil {
+		ngsi, err = initSession(c, r.session)
+	} else {
+		ngsi, err = InitCmd(c)
+	}
 	if err != nil {
 		return nil, nil, ngsierr.New(funcName, 3, err.Error(), err)
 	}
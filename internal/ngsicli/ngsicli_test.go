@@ -32,6 +32,7 @@ package ngsicli
 import (
 	"bytes"
 	"errors"
+	"fmt"
 	"strings"
 	"testing"
 
@@ -67,6 +68,29 @@ func TestRun(t *testing.T) {
 
 }
 
+func TestRunDryRun(t *testing.T) {
+	setInitNGSI(nil)
+
+	version := &Command{
+		Name:  "version",
+		Flags: []Flag{&StringFlag{Name: "host", Value: "orion"}},
+		Action: func(c *Context, ngsi *ngsilib.NGSI, client *ngsilib.Client) error {
+			return ngsierr.New("test", 1, "dry run", ngsilib.ErrDryRun)
+		},
+	}
+	r := &App{
+		Commands: []*Command{
+			{Name: "fiware"},
+			version,
+		},
+	}
+	args := []string{"ngsi", "version", "--host", "orion"}
+
+	err := r.Run(args)
+
+	assert.NoError(t, err)
+}
+
 func TestRunSavePreviousArgs(t *testing.T) {
 	ngsi := setInitNGSI(nil)
 	ngsi.Updated = true
@@ -1296,6 +1320,20 @@ func TestCheckChoicesErrorEither(t *testing.T) {
 	assert.Equal(t, "checkChoices001 specify either off or on to --safeString\n", buf.String())
 }
 
+func TestCheckChoicesErrorOutput(t *testing.T) {
+	buf := &bytes.Buffer{}
+	c := &Context{Ngsi: &ngsilib.NGSI{Stderr: buf, PreviousArgs: &ngsilib.Settings{}}}
+	flag := *OutputFlag
+	flag.Value = "xml"
+	flag.Set = true
+	cmds := &Command{Flags: []Flag{&flag}}
+
+	acutal := checkChoices(cmds.Flags, c)
+
+	assert.Equal(t, true, acutal)
+	assert.Equal(t, "checkChoices001 specify one of csv, tsv and table to --output\n", buf.String())
+}
+
 func TestSetPrevArgsNoHost(t *testing.T) {
 	c := &Context{Ngsi: &ngsilib.NGSI{PreviousArgs: &ngsilib.Settings{UsePreviousArgs: true}}}
 
@@ -1898,3 +1936,124 @@ func setInitNGSI(buf *bytes.Buffer) *ngsilib.NGSI {
 
 	return ngsi
 }
+
+func TestRunFilter(t *testing.T) {
+	buf := &bytes.Buffer{}
+	ngsi := setInitNGSI(nil)
+	ngsi.StdWriter = buf
+
+	version := &Command{
+		Name:  "version",
+		Flags: []Flag{&StringFlag{Name: "host", Value: "orion"}, PrettyFlag},
+		Action: func(c *Context, ngsi *ngsilib.NGSI, client *ngsilib.Client) error {
+			fmt.Fprint(ngsi.StdWriter, `{"orion":{"version":"3.7.0"}}`)
+			return nil
+		},
+	}
+	r := &App{
+		Flags: []Flag{FilterFlag, JSONPathFlag},
+		Commands: []*Command{
+			{Name: "fiware"},
+			version,
+		},
+	}
+	args := []string{"ngsi", "--filter", ".orion", "version", "--host", "orion", "--pretty"}
+
+	err := r.Run(args)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, "{\n  \"version\": \"3.7.0\"\n}\n", buf.String())
+		assert.Equal(t, buf, ngsi.StdWriter)
+	}
+}
+
+func TestRunFilterError(t *testing.T) {
+	buf := &bytes.Buffer{}
+	ngsi := setInitNGSI(nil)
+	ngsi.StdWriter = buf
+
+	version := &Command{
+		Name:  "version",
+		Flags: []Flag{&StringFlag{Name: "host", Value: "orion"}},
+		Action: func(c *Context, ngsi *ngsilib.NGSI, client *ngsilib.Client) error {
+			fmt.Fprint(ngsi.StdWriter, "urn:ngsi-ld:Device:001\n")
+			return nil
+		},
+	}
+	r := &App{
+		Flags: []Flag{FilterFlag, JSONPathFlag},
+		Commands: []*Command{
+			{Name: "fiware"},
+			version,
+		},
+	}
+	args := []string{"ngsi", "--jsonPath", "$.id", "version", "--host", "orion"}
+
+	err := r.Run(args)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 3, ngsiErr.ErrNo)
+		assert.Equal(t, "output is not JSON: invalid character 'u' looking for beginning of value", ngsiErr.Message)
+	}
+}
+
+func TestRunSession(t *testing.T) {
+	c := setupTestInitCmd()
+	buf := &bytes.Buffer{}
+	c.Ngsi.StdWriter = buf
+
+	version := &Command{
+		Name:  "version",
+		Flags: []Flag{&StringFlag{Name: "host", Value: "orion"}},
+		Action: func(c *Context, ngsi *ngsilib.NGSI, client *ngsilib.Client) error {
+			fmt.Fprint(ngsi.StdWriter, `{"orion":{"version":"3.7.0"}}`)
+			return nil
+		},
+	}
+	r := &App{
+		Flags: []Flag{FilterFlag, JSONPathFlag, ConfigFlag},
+		Commands: []*Command{
+			{Name: "fiware"},
+			version,
+		},
+	}
+	args := []string{"ngsi", "--filter", ".orion.version", "version", "--host", "orion"}
+
+	err := r.RunSession(c.Ngsi, args)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, "3.7.0\n", buf.String())
+		assert.Equal(t, buf, c.Ngsi.StdWriter)
+		assert.Equal(t, (*ngsilib.NGSI)(nil), r.session)
+	}
+}
+
+func TestRunSessionError(t *testing.T) {
+	c := setupTestInitCmd()
+	buf := &bytes.Buffer{}
+	c.Ngsi.StdWriter = buf
+
+	version := &Command{
+		Name:  "version",
+		Flags: []Flag{&StringFlag{Name: "host", Value: "orion"}},
+	}
+	r := &App{
+		Flags: []Flag{FilterFlag, JSONPathFlag, ConfigFlag},
+		Commands: []*Command{
+			{Name: "fiware"},
+			version,
+		},
+	}
+	args := []string{"ngsi", "--config", "/tmp/config.json", "version", "--host", "orion"}
+
+	err := r.RunSession(c.Ngsi, args)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 1, ngsiErr.ErrNo)
+		assert.Equal(t, "--config cannot be specified in shell", ngsiErr.Message)
+		assert.Equal(t, buf, c.Ngsi.StdWriter)
+		assert.Equal(t, (*ngsilib.NGSI)(nil), r.session)
+	}
+}
@@ -42,6 +42,9 @@ func batch(c *ngsicli.Context, ngsi *ngsilib.NGSI, client *ngsilib.Client, mode
 	const funcName = "batch"
 
 	if client.IsNgsiLd() {
+		if format := c.String("format"); format == "csv" || format == "tsv" {
+			return ngsierr.New(funcName, 2, format+" format not supported for NGSI-LD", nil)
+		}
 		switch mode {
 		case "create":
 			return batchCreate(c, ngsi, client)
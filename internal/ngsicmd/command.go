@@ -55,6 +55,7 @@ var NgsiApp = &ngsicli.App{
 		&UpdateCmd,
 		&UpsertCmd,
 		&TemplateCmd,
+		&SubscriptionsCmd,
 	},
 }
 
@@ -137,6 +138,7 @@ var LsCmd = ngsicli.Command{
 		uniqueFlag,
 		skipForwardingFlag,
 		linkFlag,
+		pageSizeFlag,
 		ngsicli.VerboseFlag,
 		linesFlag,
 		ngsicli.PrettyFlag,
@@ -552,6 +554,7 @@ var GetCmd = ngsicli.Command{
 				uniqueFlag,
 				ngsicli.VerboseFlag,
 				linesFlag,
+				ngsicli.OutputFlag,
 				entitiesDataFlag,
 				ngsicli.PrettyFlag,
 				ngsicli.SafeStringFlag,
@@ -694,8 +697,10 @@ var ListCmd = ngsicli.Command{
 				linkFlag,
 				acceptJSONFlag,
 				acceptGeoJSONFlag,
+				pageSizeFlag,
 				ngsicli.VerboseFlag,
 				linesFlag,
+				ngsicli.OutputFlag,
 				ngsicli.PrettyFlag,
 				ngsicli.SafeStringFlag,
 			},
@@ -1018,6 +1023,9 @@ var UpsertCmd = ngsicli.Command{
 				updateFlag,
 				linkFlag,
 				contextFlag,
+				dataFormatFlag,
+				csvMappingFlag,
+				ngsicli.ParallelFlag,
 			},
 			RequiredFlags: []string{"data"},
 			Action: func(c *ngsicli.Context, ngsi *ngsilib.NGSI, client *ngsilib.Client) error {
@@ -1117,3 +1125,53 @@ var TemplateCmd = ngsicli.Command{
 		},
 	},
 }
+
+var SubscriptionsCmd = ngsicli.Command{
+	Name:     "subscriptions",
+	Usage:    "diagnose or migrate subscriptions",
+	Category: "CONVENIENCE",
+	Flags: []ngsicli.Flag{
+		ngsicli.HostRFlag,
+		ngsicli.OAuthTokenFlag,
+		ngsicli.TenantFlag,
+		ngsicli.ScopeFlag,
+	},
+	Subcommands: []*ngsicli.Command{
+		{
+			Name:       "health",
+			Usage:      "report failing, unreachable and expiring subscriptions",
+			ServerList: []string{"brokerv2", "brokerld"},
+			Flags: []ngsicli.Flag{
+				probeFlag,
+				expiresWithinFlag,
+				reactivateFlag,
+				deleteFlaggedFlag,
+				ngsicli.RunFlag,
+				ngsicli.JsonFlag,
+				ngsicli.PrettyFlag,
+				ngsicli.SafeStringFlag,
+			},
+			Action: func(c *ngsicli.Context, ngsi *ngsilib.NGSI, client *ngsilib.Client) error {
+				return subscriptionsHealth(c, ngsi, client)
+			},
+		},
+		{
+			Name:       "migrate",
+			Usage:      "migrate subscriptions between NGSIv2 and NGSI-LD brokers",
+			ServerList: []string{"brokerv2", "brokerld"},
+			Flags: []ngsicli.Flag{
+				linkFlag,
+				destinationFlag,
+				tenant2Flag,
+				scope2Flag,
+				context2Flag,
+				ngsicli.RunFlag,
+				ngsicli.SafeStringFlag,
+			},
+			RequiredFlags: []string{"host2"},
+			Action: func(c *ngsicli.Context, ngsi *ngsilib.NGSI, client *ngsilib.Client) error {
+				return subscriptionsMigrate(c, ngsi, client)
+			},
+		},
+	},
+}
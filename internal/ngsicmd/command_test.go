@@ -76,6 +76,8 @@ func TestNGSICommand(t *testing.T) {
 		{args: []string{"list", "entities", "--host", "orion"}, rc: 1},
 		{args: []string{"list", "registrations", "--host", "orion"}, rc: 1},
 		{args: []string{"list", "subscriptions", "--host", "orion"}, rc: 1},
+		{args: []string{"subscriptions", "health", "--host", "orion"}, rc: 1},
+		{args: []string{"subscriptions", "migrate", "--host", "orion", "--host2", "orion"}, rc: 1},
 		{args: []string{"list", "types", "--host", "orion"}, rc: 1},
 		{args: []string{"list", "attributes", "--host", "orion"}, rc: 1},
 		{args: []string{"list", "ldContexts", "--host", "orion-ld"}, rc: 1},
@@ -31,7 +31,9 @@ package ngsicmd
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 
 	"github.com/lets-fiware/ngsi-go/internal/ngsicli"
@@ -42,6 +44,9 @@ import (
 func entitiesList(c *ngsicli.Context, ngsi *ngsilib.NGSI, client *ngsilib.Client) error {
 	const funcName = "entitiesList"
 
+	if c.IsSet("output") && c.IsSetOR([]string{"values", "lines", "acceptGeoJson"}) {
+		return ngsierr.New(funcName, 3, "cannot specfiy values, lines or acceptGeoJson with output", nil)
+	}
 	if client.IsNgsiLd() {
 		if c.IsSetOR([]string{"typePattern", "mq", "metadata", "value", "uniq"}) {
 			return ngsierr.New(funcName, 1, "cannot specfiy typePattern, mq, metadata, value or uniq", nil)
@@ -54,6 +59,16 @@ func entitiesList(c *ngsicli.Context, ngsi *ngsilib.NGSI, client *ngsilib.Client
 	return entitiesListV2(c, ngsi, client)
 }
 
+func entitiesPageSize(c *ngsicli.Context) (int, error) {
+	const funcName = "entitiesPageSize"
+
+	pageSize := c.Int64("pageSize")
+	if pageSize < 1 || pageSize > 1000 {
+		return 0, ngsierr.New(funcName, 1, fmt.Sprintf("pageSize error: %d (1-1000)", pageSize), nil)
+	}
+	return int(pageSize), nil
+}
+
 func entitiesListV2(c *ngsicli.Context, ngsi *ngsilib.NGSI, client *ngsilib.Client) error {
 	const funcName = "entitiesList"
 
@@ -65,10 +80,13 @@ func entitiesListV2(c *ngsicli.Context, ngsi *ngsilib.NGSI, client *ngsilib.Clie
 
 	page := 0
 	count := 0
-	limit := 100
+	limit, err := entitiesPageSize(c)
+	if err != nil {
+		return ngsierr.New(funcName, 7, err.Error(), err)
+	}
 
 	verbose := c.IsSet("verbose")
-	if c.Bool("pretty") || c.Bool("keyValues") || c.IsSetOR([]string{"attrs", "metadata", "orderBy"}) {
+	if c.Bool("pretty") || c.Bool("keyValues") || c.IsSetOR([]string{"attrs", "metadata", "orderBy"}) || c.IsOutputFiltered() {
 		verbose = true
 	}
 	values := c.IsSet("values")
@@ -77,10 +95,23 @@ func entitiesListV2(c *ngsicli.Context, ngsi *ngsilib.NGSI, client *ngsilib.Clie
 	}
 	lines := c.Bool("lines")
 
+	var table *entityTable
+	if c.IsSet("output") {
+		table, err = newEntityTable(ngsi.StdWriter, c.String("output"), c.String("attrs"))
+		if err != nil {
+			return ngsierr.New(funcName, 8, err.Error(), err)
+		}
+		verbose = true
+	}
+
 	buf := ngsilib.NewJsonBuffer()
 	if verbose {
-		buf.BufferOpen(ngsi.StdWriter, false, false)
 		attrs = ""
+		if table == nil {
+			buf.BufferOpen(ngsi.StdWriter, false, c.Bool("pretty"))
+		} else {
+			attrs = entityTableAttrs(c.String("attrs"))
+		}
 	}
 
 	for {
@@ -109,15 +140,18 @@ func entitiesListV2(c *ngsicli.Context, ngsi *ngsilib.NGSI, client *ngsilib.Clie
 		}
 		client.SetQuery(v)
 
-		res, body, err := client.HTTPGet()
+		res, err := client.HTTPGetStream()
 		if err != nil {
 			return ngsierr.New(funcName, 1, err.Error(), err)
 		}
 		if res.StatusCode != http.StatusOK {
+			body, _ := io.ReadAll(res.Body)
+			_ = res.Body.Close()
 			return ngsierr.New(funcName, 2, fmt.Sprintf("%s %s", res.Status, string(body)), nil)
 		}
 
 		if c.IsSet("count") {
+			_ = res.Body.Close()
 			count, err := client.ResultsCount(res)
 			if err != nil {
 				return ngsierr.New(funcName, 3, "ResultsCount error", nil)
@@ -128,22 +162,26 @@ func entitiesListV2(c *ngsicli.Context, ngsi *ngsilib.NGSI, client *ngsilib.Clie
 
 		count, err = client.ResultsCount(res)
 		if err != nil {
+			_ = res.Body.Close()
 			return ngsierr.New(funcName, 4, "ResultsCount error", err)
 		}
 		if count == 0 {
+			_ = res.Body.Close()
 			break
 		}
 
-		if client.IsSafeString() {
-			body, err = ngsilib.JSONSafeStringDecode(body)
-			if err != nil {
-				return ngsierr.New(funcName, 5, err.Error(), err)
-			}
-		}
-
-		err = entitiesPrint(ngsi, body, buf, c.Bool("pretty"), lines, values, verbose, false)
+		printErr, err := entitiesPrintStream(ngsi, client, res.Body, buf, c.Bool("pretty"), lines, values, verbose, false, table)
+		_ = res.Body.Close()
 		if err != nil {
-			return ngsierr.New(funcName, 6, err.Error(), err)
+			return ngsierr.New(funcName, 5, err.Error(), err)
+		}
+		if printErr != nil {
+			return ngsierr.New(funcName, 6, printErr.Error(), printErr)
+		}
+		if table != nil {
+			if err := table.page(); err != nil {
+				return ngsierr.New(funcName, 10, err.Error(), err)
+			}
 		}
 
 		if (page+1)*limit < count {
@@ -152,7 +190,11 @@ func entitiesListV2(c *ngsicli.Context, ngsi *ngsilib.NGSI, client *ngsilib.Clie
 			break
 		}
 	}
-	if verbose {
+	if table != nil {
+		if err := table.close(); err != nil {
+			return ngsierr.New(funcName, 9, err.Error(), err)
+		}
+	} else if verbose {
 		buf.BufferClose()
 	}
 	return nil
@@ -168,16 +210,28 @@ func entitiesListLD(c *ngsicli.Context, ngsi *ngsilib.NGSI, client *ngsilib.Clie
 
 	page := 0
 	count := 0
-	limit := 100
+	limit, err := entitiesPageSize(c)
+	if err != nil {
+		return ngsierr.New(funcName, 7, err.Error(), err)
+	}
 
 	verbose := c.IsSet("verbose")
-	if c.IsSetOR([]string{"pretty", "keyValues", "acceptGeoJson", "attrs", "orderBy"}) {
+	if c.IsSetOR([]string{"pretty", "keyValues", "acceptGeoJson", "attrs", "orderBy"}) || c.IsOutputFiltered() {
 		verbose = true
 	}
 	lines := c.Bool("lines")
 
+	var table *entityTable
+	if c.IsSet("output") {
+		table, err = newEntityTable(ngsi.StdWriter, c.String("output"), c.String("attrs"))
+		if err != nil {
+			return ngsierr.New(funcName, 8, err.Error(), err)
+		}
+		verbose = true
+	}
+
 	buf := ngsilib.NewJsonBuffer()
-	if verbose {
+	if table == nil && verbose {
 		buf.BufferOpen(ngsi.StdWriter, c.Bool("acceptGeoJson"), c.Bool("pretty"))
 	}
 
@@ -191,6 +245,9 @@ func entitiesListLD(c *ngsicli.Context, ngsi *ngsilib.NGSI, client *ngsilib.Clie
 		if idPattern != "" {
 			v.Set("idPattern", idPattern)
 		}
+		if table != nil && c.IsSet("attrs") {
+			v.Set("attrs", entityTableAttrs(c.String("attrs")))
+		}
 		if c.IsSet("count") {
 			v.Set("limit", "0")
 			v.Set("count", "true")
@@ -213,15 +270,18 @@ func entitiesListLD(c *ngsicli.Context, ngsi *ngsilib.NGSI, client *ngsilib.Clie
 			client.SetAcceptGeoJSON()
 		}
 
-		res, body, err := client.HTTPGet()
+		res, err := client.HTTPGetStream()
 		if err != nil {
 			return ngsierr.New(funcName, 1, err.Error(), err)
 		}
 		if res.StatusCode != http.StatusOK {
+			body, _ := io.ReadAll(res.Body)
+			_ = res.Body.Close()
 			return ngsierr.New(funcName, 2, fmt.Sprintf("%s %s", res.Status, string(body)), nil)
 		}
 
 		if c.IsSet("count") {
+			_ = res.Body.Close()
 			count, err := client.ResultsCount(res)
 			if err != nil {
 				return ngsierr.New(funcName, 3, "ResultsCount error", nil)
@@ -232,22 +292,26 @@ func entitiesListLD(c *ngsicli.Context, ngsi *ngsilib.NGSI, client *ngsilib.Clie
 
 		count, err = client.ResultsCount(res)
 		if err != nil {
+			_ = res.Body.Close()
 			return ngsierr.New(funcName, 4, "ResultsCount error", err)
 		}
 		if count == 0 {
+			_ = res.Body.Close()
 			break
 		}
 
-		if client.IsSafeString() {
-			body, err = ngsilib.JSONSafeStringDecode(body)
-			if err != nil {
-				return ngsierr.New(funcName, 5, err.Error(), err)
-			}
-		}
-
-		err = entitiesPrint(ngsi, body, buf, c.Bool("pretty"), lines, false, verbose, c.Bool("acceptGeoJson"))
+		printErr, err := entitiesPrintStream(ngsi, client, res.Body, buf, c.Bool("pretty"), lines, false, verbose, c.Bool("acceptGeoJson"), table)
+		_ = res.Body.Close()
 		if err != nil {
-			return ngsierr.New(funcName, 6, err.Error(), err)
+			return ngsierr.New(funcName, 5, err.Error(), err)
+		}
+		if printErr != nil {
+			return ngsierr.New(funcName, 6, printErr.Error(), printErr)
+		}
+		if table != nil {
+			if err := table.page(); err != nil {
+				return ngsierr.New(funcName, 10, err.Error(), err)
+			}
 		}
 
 		if (page+1)*limit < count {
@@ -256,77 +320,90 @@ func entitiesListLD(c *ngsicli.Context, ngsi *ngsilib.NGSI, client *ngsilib.Clie
 			break
 		}
 	}
-	if verbose {
+	if table != nil {
+		if err := table.close(); err != nil {
+			return ngsierr.New(funcName, 9, err.Error(), err)
+		}
+	} else if verbose {
 		buf.BufferClose()
 	}
 	return nil
 }
 
-func entitiesPrint(ngsi *ngsilib.NGSI, body []byte, buf *ngsilib.JsonBuffer, pretty, lines, values, verbose, geoJSON bool) error {
-	const funcName = "entitiesPrint"
-	const geoJSONFeatures = `{"type":"FeatureCollection","features":`
-	var err error
+// entitiesPrintStream prints the entities in r one by one. It returns printErr when an entity cannot be printed
+// and err when r cannot be decoded.
+func entitiesPrintStream(ngsi *ngsilib.NGSI, client *ngsilib.Client, r io.Reader, buf *ngsilib.JsonBuffer, pretty, lines, values, verbose, geoJSON bool, table *entityTable) (printErr, err error) {
+	const funcName = "entitiesPrintStream"
 
-	if geoJSON {
-		if bytes.HasPrefix(body, []byte(geoJSONFeatures)) && bytes.HasSuffix(body, []byte(`}`)) {
-			body = body[len(geoJSONFeatures) : len(body)-1]
+	_, err = ngsilib.JSONArrayStream(r, geoJSON, func(e json.RawMessage) error {
+		b := []byte(e)
+		if client.IsSafeString() {
+			var err error
+			b, err = ngsilib.JSONSafeStringDecode(b)
+			if err != nil {
+				return ngsierr.New(funcName, 1, err.Error(), err)
+			}
+		}
+		if table != nil {
+			printErr = table.write(b)
 		} else {
-			return ngsierr.New(funcName, 1, "geojson error: "+string(body), err)
+			printErr = entityPrint(ngsi, b, buf, pretty, lines, values, verbose)
 		}
+		return printErr
+	})
+	if printErr != nil {
+		return ngsierr.New(funcName, 3, printErr.Error(), printErr), nil
+	}
+	if err != nil {
+		return nil, ngsierr.New(funcName, 2, err.Error(), err)
 	}
+	return nil, nil
+}
+
+func entityPrint(ngsi *ngsilib.NGSI, b []byte, buf *ngsilib.JsonBuffer, pretty, lines, values, verbose bool) error {
+	const funcName = "entityPrint"
 
 	if lines {
 		if values {
-			var values [][]interface{}
-			err = ngsilib.JSONUnmarshal(body, &values)
+			var value []interface{}
+			err := ngsilib.JSONUnmarshal(b, &value)
 			if err != nil {
-				return ngsierr.New(funcName, 2, err.Error(), err)
+				return ngsierr.New(funcName, 1, err.Error(), err)
 			}
-			for _, e := range values {
-				b, err := ngsilib.JSONMarshal(&e)
-				if err != nil {
-					return ngsierr.New(funcName, 3, err.Error(), err)
-				}
-				fmt.Fprintln(ngsi.StdWriter, string(b))
+			b, err = ngsilib.JSONMarshal(&value)
+			if err != nil {
+				return ngsierr.New(funcName, 2, err.Error(), err)
 			}
 		} else {
-			var entities ngsilib.EntitiesRespose
-			err = ngsilib.JSONUnmarshal(body, &entities)
+			var entity map[string]interface{}
+			err := ngsilib.JSONUnmarshal(b, &entity)
 			if err != nil {
-				return ngsierr.New(funcName, 4, err.Error(), err)
+				return ngsierr.New(funcName, 3, err.Error(), err)
 			}
-			for _, e := range entities {
-				b, err := ngsilib.JSONMarshal(&e)
-				if err != nil {
-					return ngsierr.New(funcName, 5, err.Error(), err)
-				}
-				fmt.Fprintln(ngsi.StdWriter, string(b))
+			b, err = ngsilib.JSONMarshal(&entity)
+			if err != nil {
+				return ngsierr.New(funcName, 4, err.Error(), err)
 			}
 		}
+		fmt.Fprintln(ngsi.StdWriter, string(b))
 	} else if verbose {
 		if pretty {
 			newBuf := new(bytes.Buffer)
-			indent := ""
-			if geoJSON {
-				indent = "  "
-			}
-			err := ngsi.JSONConverter.Indent(newBuf, body, indent, "  ")
+			err := ngsi.JSONConverter.Indent(newBuf, b, buf.BufferPrefix(), "  ")
 			if err != nil {
-				return ngsierr.New(funcName, 6, err.Error(), err)
+				return ngsierr.New(funcName, 5, err.Error(), err)
 			}
-			buf.BufferWrite(newBuf.Bytes())
+			buf.BufferWriteElement(newBuf.Bytes())
 		} else {
-			buf.BufferWrite(body)
+			buf.BufferWriteElement(b)
 		}
 	} else {
-		var entities ngsilib.EntitiesRespose
-		err = ngsilib.JSONUnmarshal(body, &entities)
+		var entity map[string]interface{}
+		err := ngsilib.JSONUnmarshal(b, &entity)
 		if err != nil {
-			return ngsierr.New(funcName, 7, err.Error(), err)
-		}
-		for _, e := range entities {
-			fmt.Fprintln(ngsi.StdWriter, e["id"])
+			return ngsierr.New(funcName, 6, err.Error(), err)
 		}
+		fmt.Fprintln(ngsi.StdWriter, entity["id"])
 	}
 	return nil
 }
@@ -30,6 +30,7 @@ SOFTWARE.
 package ngsicmd
 
 import (
+	"bytes"
 	"errors"
 	"net/http"
 	"testing"
@@ -122,6 +123,94 @@ func TestEntitiesListErrorV2Param(t *testing.T) {
 	}
 }
 
+func TestEntitiesListErrorOutput(t *testing.T) {
+	c := setupTest([]string{"list", "entities", "--host", "orion", "--output", "csv", "--lines"})
+
+	err := entitiesList(c, c.Ngsi, c.Client)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 3, ngsiErr.ErrNo)
+		assert.Equal(t, "cannot specfiy values, lines or acceptGeoJson with output", ngsiErr.Message)
+	}
+}
+
+func TestEntitiesListV2PageSize(t *testing.T) {
+	c := setupTest([]string{"list", "entities", "--host", "orion", "--pageSize", "1000"})
+
+	reqRes := helper.MockHTTPReqRes{}
+	reqRes.Res.StatusCode = http.StatusOK
+	reqRes.Path = "/v2/entities"
+	q := "attrs=__NONE&limit=1000&offset=0&options=count"
+	reqRes.RawQuery = &q
+	reqRes.ResHeader = http.Header{"Fiware-Total-Count": []string{"2"}}
+	reqRes.ResBody = []byte(`[{"id":"device001","type":"Device"},{"id":"device002","type":"Device"}]`)
+
+	helper.SetClientHTTP(c, reqRes)
+
+	err := entitiesList(c, c.Ngsi, c.Client)
+
+	if assert.NoError(t, err) {
+		actual := helper.GetStdoutString(c)
+		expected := "device001\ndevice002\n"
+		assert.Equal(t, expected, actual)
+	}
+}
+
+func TestEntitiesListLDPageSize(t *testing.T) {
+	c := setupTest([]string{"list", "entities", "--host", "orion-ld", "--pageSize", "1", "--lines"})
+
+	reqRes1 := helper.MockHTTPReqRes{}
+	reqRes1.Res.StatusCode = http.StatusOK
+	reqRes1.Path = "/ngsi-ld/v1/entities"
+	q1 := "idPattern=.%2A&limit=1&offset=0&options=count"
+	reqRes1.RawQuery = &q1
+	reqRes1.ResHeader = http.Header{"Ngsild-Results-Count": []string{"2"}}
+	reqRes1.ResBody = []byte(`[{"id":"urn:ngsi-ld:Device:001","type":"Device"}]`)
+
+	reqRes2 := helper.MockHTTPReqRes{}
+	reqRes2.Res.StatusCode = http.StatusOK
+	reqRes2.Path = "/ngsi-ld/v1/entities"
+	q2 := "idPattern=.%2A&limit=1&offset=1&options=count"
+	reqRes2.RawQuery = &q2
+	reqRes2.ResHeader = http.Header{"Ngsild-Results-Count": []string{"2"}}
+	reqRes2.ResBody = []byte(`[{"id":"urn:ngsi-ld:Device:002","type":"Device"}]`)
+
+	helper.SetClientHTTP(c, reqRes1, reqRes2)
+
+	err := entitiesList(c, c.Ngsi, c.Client)
+
+	if assert.NoError(t, err) {
+		actual := helper.GetStdoutString(c)
+		expected := "{\"id\":\"urn:ngsi-ld:Device:001\",\"type\":\"Device\"}\n{\"id\":\"urn:ngsi-ld:Device:002\",\"type\":\"Device\"}\n"
+		assert.Equal(t, expected, actual)
+	}
+}
+
+func TestEntitiesListV2ErrorPageSize(t *testing.T) {
+	c := setupTest([]string{"list", "entities", "--host", "orion", "--pageSize", "1001"})
+
+	err := entitiesListV2(c, c.Ngsi, c.Client)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 7, ngsiErr.ErrNo)
+		assert.Equal(t, "pageSize error: 1001 (1-1000)", ngsiErr.Message)
+	}
+}
+
+func TestEntitiesListLDErrorPageSize(t *testing.T) {
+	c := setupTest([]string{"list", "entities", "--host", "orion-ld", "--pageSize", "0"})
+
+	err := entitiesListLD(c, c.Ngsi, c.Client)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 7, ngsiErr.ErrNo)
+		assert.Equal(t, "pageSize error: 0 (1-1000)", ngsiErr.Message)
+	}
+}
+
 func TestEntitiesListCountV2(t *testing.T) {
 	c := setupTest([]string{"list", "entities", "--host", "orion", "--count"})
 
@@ -250,6 +339,26 @@ func TestEntitiesListV2Verbose(t *testing.T) {
 	}
 }
 
+func TestEntitiesListV2Template(t *testing.T) {
+	c := setupTest([]string{"--template", "{{.id}},{{.temperature.value}}", "list", "entities", "--host", "orion", "--attrs", "temperature"})
+
+	reqRes := helper.MockHTTPReqRes{}
+	reqRes.Res.StatusCode = http.StatusOK
+	reqRes.Path = "/v2/entities"
+	reqRes.ResHeader = http.Header{"Fiware-Total-Count": []string{"2"}}
+	reqRes.ResBody = []byte(`[{"id":"airqualityobserved_0","type":"AirQualityObserved","temperature":{"type":"Number","value":6.727447926,"metadata":{}}},{"id":"airqualityobserved_1","type":"AirQualityObserved","temperature":{"type":"Number","value":19.012560208,"metadata":{}}}]`)
+
+	helper.SetClientHTTP(c, reqRes)
+
+	err := entitiesListV2(c, c.Ngsi, c.Client)
+
+	if assert.NoError(t, err) {
+		actual := getFilteredStdoutString(t, c)
+		expected := "airqualityobserved_0,6.727447926\nairqualityobserved_1,19.012560208\n"
+		assert.Equal(t, expected, actual)
+	}
+}
+
 func TestEntitiesListV2VerbosePretty(t *testing.T) {
 	c := setupTest([]string{"list", "entities", "--host", "orion", "--verbose", "--attrs", "temperature", "--pretty"})
 
@@ -330,6 +439,185 @@ func TestEntitiesListV2ValuesLines(t *testing.T) {
 	}
 }
 
+func TestEntitiesListV2OutputAttrs(t *testing.T) {
+	cases := []struct {
+		output   string
+		expected string
+	}{
+		{
+			output:   "csv",
+			expected: "id,type,temperature,temperature.unitCode,humidity\nRoom1,Room,21.5,CEL,40\nRoom2,Room,22,,\n",
+		},
+		{
+			output:   "tsv",
+			expected: "id\ttype\ttemperature\ttemperature.unitCode\thumidity\nRoom1\tRoom\t21.5\tCEL\t40\nRoom2\tRoom\t22\t\t\n",
+		},
+		{
+			output: "table",
+			expected: "" +
+				"id     type  temperature  temperature.unitCode  humidity\n" +
+				"Room1  Room  21.5         CEL                   40\n" +
+				"Room2  Room  22                                 \n",
+		},
+	}
+
+	for _, tc := range cases {
+		c := setupTest([]string{"list", "entities", "--host", "orion", "--type", "Room", "--attrs", "temperature,temperature.unitCode,humidity", "--output", tc.output})
+
+		reqRes := helper.MockHTTPReqRes{}
+		reqRes.Res.StatusCode = http.StatusOK
+		reqRes.Path = "/v2/entities"
+		reqRes.ResHeader = http.Header{"Fiware-Total-Count": []string{"2"}}
+		reqRes.ResBody = []byte(`[{"id":"Room1","type":"Room","temperature":{"type":"Number","value":21.5,"metadata":{"unitCode":{"type":"Text","value":"CEL"}}},"humidity":{"type":"Number","value":40,"metadata":{}}},{"id":"Room2","type":"Room","temperature":{"type":"Number","value":22,"metadata":{}}}]`)
+		rawQuery := "attrs=temperature%2Chumidity&limit=100&offset=0&options=count&type=Room"
+		reqRes.RawQuery = &rawQuery
+
+		helper.SetClientHTTP(c, reqRes)
+
+		err := entitiesListV2(c, c.Ngsi, c.Client)
+
+		if assert.NoError(t, err) {
+			assert.Equal(t, tc.expected, helper.GetStdoutString(c))
+		}
+	}
+}
+
+func TestEntitiesListV2OutputTable(t *testing.T) {
+	c := setupTest([]string{"list", "entities", "--host", "orion", "--output", "table"})
+
+	reqRes := helper.MockHTTPReqRes{}
+	reqRes.Res.StatusCode = http.StatusOK
+	reqRes.Path = "/v2/entities"
+	reqRes.ResHeader = http.Header{"Fiware-Total-Count": []string{"2"}}
+	reqRes.ResBody = []byte(`[{"id":"Room1","type":"Room","temperature":{"type":"Number","value":21.5,"metadata":{"unitCode":{"type":"Text","value":"CEL"}}},"humidity":{"type":"Number","value":40,"metadata":{}}},{"id":"Room2","type":"Room","temperature":{"type":"Number","value":22,"metadata":{}}}]`)
+	rawQuery := "limit=100&offset=0&options=count"
+	reqRes.RawQuery = &rawQuery
+
+	helper.SetClientHTTP(c, reqRes)
+
+	err := entitiesListV2(c, c.Ngsi, c.Client)
+
+	if assert.NoError(t, err) {
+		actual := helper.GetStdoutString(c)
+		expected := "id     type  humidity  temperature\n" +
+			"Room1  Room  40        21.5\n" +
+			"Room2  Room            22\n"
+		assert.Equal(t, expected, actual)
+	}
+}
+
+func TestEntitiesListV2OutputPages(t *testing.T) {
+	c := setupTest([]string{"list", "entities", "--host", "orion", "--output", "csv", "--pageSize", "1"})
+
+	reqRes1 := helper.MockHTTPReqRes{}
+	reqRes1.Res.StatusCode = http.StatusOK
+	reqRes1.Path = "/v2/entities"
+	reqRes1.ResHeader = http.Header{"Fiware-Total-Count": []string{"2"}}
+	reqRes1.ResBody = []byte(`[{"id":"Room1","type":"Room","temperature":{"type":"Number","value":21.5,"metadata":{}}}]`)
+	reqRes2 := helper.MockHTTPReqRes{}
+	reqRes2.Res.StatusCode = http.StatusOK
+	reqRes2.Path = "/v2/entities"
+	reqRes2.ResHeader = http.Header{"Fiware-Total-Count": []string{"2"}}
+	reqRes2.ResBody = []byte(`[{"id":"Room2","type":"Room","temperature":{"type":"Number","value":22,"metadata":{}}}]`)
+
+	helper.SetClientHTTP(c, reqRes1, reqRes2)
+
+	err := entitiesListV2(c, c.Ngsi, c.Client)
+
+	if assert.NoError(t, err) {
+		actual := helper.GetStdoutString(c)
+		expected := "id,type,temperature\nRoom1,Room,21.5\nRoom2,Room,22\n"
+		assert.Equal(t, expected, actual)
+	}
+}
+
+func TestEntitiesListV2OutputPagesTable(t *testing.T) {
+	c := setupTest([]string{"list", "entities", "--host", "orion", "--output", "table", "--pageSize", "1"})
+
+	reqRes1 := helper.MockHTTPReqRes{}
+	reqRes1.Res.StatusCode = http.StatusOK
+	reqRes1.Path = "/v2/entities"
+	reqRes1.ResHeader = http.Header{"Fiware-Total-Count": []string{"2"}}
+	reqRes1.ResBody = []byte(`[{"id":"Room1","type":"Room","temperature":{"type":"Number","value":21.5,"metadata":{}}}]`)
+	reqRes2 := helper.MockHTTPReqRes{}
+	reqRes2.Res.StatusCode = http.StatusOK
+	reqRes2.Path = "/v2/entities"
+	reqRes2.ResHeader = http.Header{"Fiware-Total-Count": []string{"2"}}
+	reqRes2.ResBody = []byte(`[{"id":"Room2","type":"Room","temperature":{"type":"Number","value":22,"metadata":{}},"humidity":{"type":"Number","value":40,"metadata":{}}}]`)
+
+	helper.SetClientHTTP(c, reqRes1, reqRes2)
+
+	err := entitiesListV2(c, c.Ngsi, c.Client)
+
+	if assert.NoError(t, err) {
+		actual := helper.GetStdoutString(c)
+		expected := "" +
+			"id     type  humidity  temperature\n" +
+			"Room1  Room            21.5\n" +
+			"Room2  Room  40        22\n"
+		assert.Equal(t, expected, actual)
+	}
+}
+
+func TestEntitiesListV2ErrorOutputPagesAttribute(t *testing.T) {
+	c := setupTest([]string{"list", "entities", "--host", "orion", "--output", "csv", "--pageSize", "1"})
+
+	reqRes1 := helper.MockHTTPReqRes{}
+	reqRes1.Res.StatusCode = http.StatusOK
+	reqRes1.Path = "/v2/entities"
+	reqRes1.ResHeader = http.Header{"Fiware-Total-Count": []string{"2"}}
+	reqRes1.ResBody = []byte(`[{"id":"Room1","type":"Room","temperature":{"type":"Number","value":21.5,"metadata":{}}}]`)
+	reqRes2 := helper.MockHTTPReqRes{}
+	reqRes2.Res.StatusCode = http.StatusOK
+	reqRes2.Path = "/v2/entities"
+	reqRes2.ResHeader = http.Header{"Fiware-Total-Count": []string{"2"}}
+	reqRes2.ResBody = []byte(`[{"id":"Room2","type":"Room","temperature":{"type":"Number","value":22,"metadata":{}},"humidity":{"type":"Number","value":40,"metadata":{}}}]`)
+
+	helper.SetClientHTTP(c, reqRes1, reqRes2)
+
+	err := entitiesListV2(c, c.Ngsi, c.Client)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 6, ngsiErr.ErrNo)
+		assert.Equal(t, "id,type,temperature\nRoom1,Room,21.5\n", helper.GetStdoutString(c))
+	}
+}
+
+func TestEntitiesListV2ErrorOutputPage(t *testing.T) {
+	c := setupTest([]string{"list", "entities", "--host", "orion", "--output", "csv"})
+
+	reqRes := helper.MockHTTPReqRes{}
+	reqRes.Res.StatusCode = http.StatusOK
+	reqRes.Path = "/v2/entities"
+	reqRes.ResHeader = http.Header{"Fiware-Total-Count": []string{"1"}}
+	reqRes.ResBody = []byte(`[{"id":"Room1","type":"Room"}]`)
+
+	helper.SetClientHTTP(c, reqRes)
+	c.Ngsi.StdWriter = &errTableWriter{}
+
+	err := entitiesListV2(c, c.Ngsi, c.Client)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 10, ngsiErr.ErrNo)
+		assert.Equal(t, "write error", ngsiErr.Message)
+	}
+}
+
+func TestEntitiesListV2ErrorOutput(t *testing.T) {
+	c := setupTest([]string{"list", "entities", "--host", "orion", "--output", "csv"})
+	c.GetStringFlag("output").Value = "xml"
+
+	err := entitiesListV2(c, c.Ngsi, c.Client)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 8, ngsiErr.ErrNo)
+		assert.Equal(t, "unknown output format: xml (csv, tsv, table)", ngsiErr.Message)
+	}
+}
+
 func TestEntitiesListV2ResultsCount(t *testing.T) {
 	c := setupTest([]string{"list", "entities", "--host", "orion", "--type", "Device"})
 
@@ -646,6 +934,86 @@ func TestEntitiesListLDVerboseLines(t *testing.T) {
 	}
 }
 
+func TestEntitiesListLDOutputTSV(t *testing.T) {
+	c := setupTest([]string{"list", "entities", "--host", "orion-ld", "--type", "TemperatureSensor", "--attrs", "temperature,temperature.unitCode", "--output", "tsv"})
+
+	reqRes := helper.MockHTTPReqRes{}
+	reqRes.Res.StatusCode = http.StatusOK
+	reqRes.Path = "/ngsi-ld/v1/entities"
+	reqRes.ResHeader = http.Header{"Ngsild-Results-Count": []string{"2"}}
+	reqRes.ResBody = []byte(`[{"id":"urn:ngsi-ld:TemperatureSensor:001","type":"TemperatureSensor","category":{"type":"Property","value":"sensor"},"temperature":{"type":"Property","value":25,"unitCode":"CEL"}},{"id":"urn:ngsi-ld:TemperatureSensor:002","type":"TemperatureSensor","category":{"type":"Property","value":"sensor"},"temperature":{"type":"Property","value":26,"unitCode":"CEL"}}]`)
+	rawQuery := "attrs=temperature&limit=100&offset=0&options=count&type=TemperatureSensor"
+	reqRes.RawQuery = &rawQuery
+
+	helper.SetClientHTTP(c, reqRes)
+
+	err := entitiesListLD(c, c.Ngsi, c.Client)
+
+	if assert.NoError(t, err) {
+		actual := helper.GetStdoutString(c)
+		expected := "id\ttype\ttemperature\ttemperature.unitCode\n" +
+			"urn:ngsi-ld:TemperatureSensor:001\tTemperatureSensor\t25\tCEL\n" +
+			"urn:ngsi-ld:TemperatureSensor:002\tTemperatureSensor\t26\tCEL\n"
+		assert.Equal(t, expected, actual)
+	}
+}
+
+func TestEntitiesListLDOutputCSV(t *testing.T) {
+	c := setupTest([]string{"list", "entities", "--host", "orion-ld", "--type", "TemperatureSensor", "--output", "csv"})
+
+	reqRes := helper.MockHTTPReqRes{}
+	reqRes.Res.StatusCode = http.StatusOK
+	reqRes.Path = "/ngsi-ld/v1/entities"
+	reqRes.ResHeader = http.Header{"Ngsild-Results-Count": []string{"2"}}
+	reqRes.ResBody = []byte(`[{"id":"urn:ngsi-ld:TemperatureSensor:001","type":"TemperatureSensor","category":{"type":"Property","value":"sensor"},"temperature":{"type":"Property","value":25,"unitCode":"CEL"}},{"id":"urn:ngsi-ld:TemperatureSensor:002","type":"TemperatureSensor","category":{"type":"Property","value":"sensor"},"temperature":{"type":"Property","value":26,"unitCode":"CEL"}}]`)
+
+	helper.SetClientHTTP(c, reqRes)
+
+	err := entitiesListLD(c, c.Ngsi, c.Client)
+
+	if assert.NoError(t, err) {
+		actual := helper.GetStdoutString(c)
+		expected := "id,type,category,temperature\n" +
+			"urn:ngsi-ld:TemperatureSensor:001,TemperatureSensor,sensor,25\n" +
+			"urn:ngsi-ld:TemperatureSensor:002,TemperatureSensor,sensor,26\n"
+		assert.Equal(t, expected, actual)
+	}
+}
+
+func TestEntitiesListLDErrorOutputPage(t *testing.T) {
+	c := setupTest([]string{"list", "entities", "--host", "orion-ld", "--type", "Room", "--output", "csv"})
+
+	reqRes := helper.MockHTTPReqRes{}
+	reqRes.Res.StatusCode = http.StatusOK
+	reqRes.Path = "/ngsi-ld/v1/entities"
+	reqRes.ResHeader = http.Header{"Ngsild-Results-Count": []string{"1"}}
+	reqRes.ResBody = []byte(`[{"id":"urn:ngsi-ld:Room:1","type":"Room"}]`)
+
+	helper.SetClientHTTP(c, reqRes)
+	c.Ngsi.StdWriter = &errTableWriter{}
+
+	err := entitiesListLD(c, c.Ngsi, c.Client)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 10, ngsiErr.ErrNo)
+		assert.Equal(t, "write error", ngsiErr.Message)
+	}
+}
+
+func TestEntitiesListLDErrorOutput(t *testing.T) {
+	c := setupTest([]string{"list", "entities", "--host", "orion-ld", "--output", "csv"})
+	c.GetStringFlag("output").Value = "xml"
+
+	err := entitiesListLD(c, c.Ngsi, c.Client)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 8, ngsiErr.ErrNo)
+		assert.Equal(t, "unknown output format: xml (csv, tsv, table)", ngsiErr.Message)
+	}
+}
+
 func TestEntitiesListLDResultsCount(t *testing.T) {
 	c := setupTest([]string{"list", "entities", "--host", "orion-ld", "--type", "Device"})
 
@@ -762,7 +1130,7 @@ func TestEntitiesListLDErrorEntitiesPrint(t *testing.T) {
 	reqRes.Res.StatusCode = http.StatusOK
 	reqRes.Path = "/ngsi-ld/v1/entities"
 	reqRes.ResHeader = http.Header{"Ngsild-Results-Count": []string{"3"}}
-	reqRes.ResBody = []byte(`[{@context":"http://atcontext:8000/ngsi-context.jsonld","id":"urn:ngsi-ld:TemperatureSensor:001","type":"TemperatureSensor","temperature":{"type":"Property","value":25,"unitCode":"CEL"}},{"@context":"http://atcontext:8000/ngsi-context.jsonld","id":"urn:ngsi-ld:TemperatureSensor:002","type":"TemperatureSensor","temperature":{"type":"Property","value":26,"unitCode":"CEL"}},{"@context":"http://atcontext:8000/ngsi-context.jsonld","id":"urn:ngsi-ld:TemperatureSensor:003","type":"TemperatureSensor","temperature":{"type":"Property","value":27,"unitCode":"CEL"}}]`)
+	reqRes.ResBody = []byte(`[{"@context":"http://atcontext:8000/ngsi-context.jsonld","id":"urn:ngsi-ld:TemperatureSensor:001","type":"TemperatureSensor","temperature":{"type":"Property","value":25,"unitCode":"CEL"}},{"@context":"http://atcontext:8000/ngsi-context.jsonld","id":"urn:ngsi-ld:TemperatureSensor:002","type":"TemperatureSensor","temperature":{"type":"Property","value":26,"unitCode":"CEL"}},{"@context":"http://atcontext:8000/ngsi-context.jsonld","id":"urn:ngsi-ld:TemperatureSensor:003","type":"TemperatureSensor","temperature":{"type":"Property","value":27,"unitCode":"CEL"}}]`)
 
 	helper.SetClientHTTP(c, reqRes)
 
@@ -777,7 +1145,7 @@ func TestEntitiesListLDErrorEntitiesPrint(t *testing.T) {
 	}
 }
 
-func TestEntitiesPrint(t *testing.T) {
+func TestEntitiesPrintStream(t *testing.T) {
 	c := setupTest([]string{"list", "entities", "--host", "orion"})
 
 	pretty := false
@@ -790,8 +1158,11 @@ func TestEntitiesPrint(t *testing.T) {
 
 	body := []byte(`[{"id":"airqualityobserved_0","type":"AirQualityObserved","temperature":{"type":"Number","value":6.727447926,"metadata":{}}},{"id":"airqualityobserved_1","type":"AirQualityObserved","temperature":{"type":"Number","value":19.012560208,"metadata":{}}},{"id":"airqualityobserved_2","type":"AirQualityObserved","temperature":{"type":"Number","value":-3.196384014,"metadata":{}}},{"id":"airqualityobserved_3","type":"AirQualityObserved","temperature":{"type":"Number","value":7.992932652,"metadata":{}}},{"id":"airqualityobserved_4","type":"AirQualityObserved","temperature":{"type":"Number","value":-6.620346091,"metadata":{}}},{"id":"airqualityobserved_5","type":"AirQualityObserved","temperature":{"type":"Number","value":-16.634766746,"metadata":{}}},{"id":"airqualityobserved_6","type":"AirQualityObserved","temperature":{"type":"Number","value":20.263618173,"metadata":{}}},{"id":"airqualityobserved_7","type":"AirQualityObserved","temperature":{"type":"Number","value":14.285382467,"metadata":{}}},{"id":"airqualityobserved_8","type":"AirQualityObserved","temperature":{"type":"Number","value":6.998595286,"metadata":{}}}]`)
 
-	err := entitiesPrint(c.Ngsi, body, buf, pretty, lines, values, verbose, false)
+	printErr, err := entitiesPrintStream(c.Ngsi, c.Client, bytes.NewReader(body), buf, pretty, lines, values, verbose, false, nil)
+
+	buf.BufferClose()
 
+	assert.NoError(t, printErr)
 	if assert.NoError(t, err) {
 		actual := helper.GetStdoutString(c)
 		expected := "airqualityobserved_0\nairqualityobserved_1\nairqualityobserved_2\nairqualityobserved_3\nairqualityobserved_4\nairqualityobserved_5\nairqualityobserved_6\nairqualityobserved_7\nairqualityobserved_8\n"
@@ -799,7 +1170,7 @@ func TestEntitiesPrint(t *testing.T) {
 	}
 }
 
-func TestEntitiesPrintLinesValues(t *testing.T) {
+func TestEntitiesPrintStreamLinesValues(t *testing.T) {
 	c := setupTest([]string{"list", "entities", "--host", "orion"})
 
 	pretty := false
@@ -812,8 +1183,11 @@ func TestEntitiesPrintLinesValues(t *testing.T) {
 
 	body := []byte(`[[10.148599472],[14.627960669],[-2.461631059],[-15.999248065],[-4.553473866],[1.147149609],[1.003624237],[11.747977585],[-4.264932072]]`)
 
-	err := entitiesPrint(c.Ngsi, body, buf, pretty, lines, values, verbose, false)
+	printErr, err := entitiesPrintStream(c.Ngsi, c.Client, bytes.NewReader(body), buf, pretty, lines, values, verbose, false, nil)
 
+	buf.BufferClose()
+
+	assert.NoError(t, printErr)
 	if assert.NoError(t, err) {
 		actual := helper.GetStdoutString(c)
 		expected := "[10.148599472]\n[14.627960669]\n[-2.461631059]\n[-15.999248065]\n[-4.553473866]\n[1.147149609]\n[1.003624237]\n[11.747977585]\n[-4.264932072]\n"
@@ -821,7 +1195,7 @@ func TestEntitiesPrintLinesValues(t *testing.T) {
 	}
 }
 
-func TestEntitiesPrintVerbosePretty(t *testing.T) {
+func TestEntitiesPrintStreamVerbosePretty(t *testing.T) {
 	c := setupTest([]string{"list", "entities", "--host", "orion"})
 
 	pretty := true
@@ -830,14 +1204,15 @@ func TestEntitiesPrintVerbosePretty(t *testing.T) {
 	verbose := true
 
 	buf := ngsilib.NewJsonBuffer()
-	buf.BufferOpen(c.Ngsi.StdWriter, false, false)
+	buf.BufferOpen(c.Ngsi.StdWriter, false, true)
 
 	body := []byte(`[{"id":"airqualityobserved_0","type":"AirQualityObserved","temperature":{"type":"Number","value":6.727447926,"metadata":{}}},{"id":"airqualityobserved_1","type":"AirQualityObserved","temperature":{"type":"Number","value":19.012560208,"metadata":{}}},{"id":"airqualityobserved_2","type":"AirQualityObserved","temperature":{"type":"Number","value":-3.196384014,"metadata":{}}},{"id":"airqualityobserved_3","type":"AirQualityObserved","temperature":{"type":"Number","value":7.992932652,"metadata":{}}},{"id":"airqualityobserved_4","type":"AirQualityObserved","temperature":{"type":"Number","value":-6.620346091,"metadata":{}}},{"id":"airqualityobserved_5","type":"AirQualityObserved","temperature":{"type":"Number","value":-16.634766746,"metadata":{}}},{"id":"airqualityobserved_6","type":"AirQualityObserved","temperature":{"type":"Number","value":20.263618173,"metadata":{}}},{"id":"airqualityobserved_7","type":"AirQualityObserved","temperature":{"type":"Number","value":14.285382467,"metadata":{}}},{"id":"airqualityobserved_8","type":"AirQualityObserved","temperature":{"type":"Number","value":6.998595286,"metadata":{}}}]`)
 
-	err := entitiesPrint(c.Ngsi, body, buf, pretty, lines, values, verbose, false)
+	printErr, err := entitiesPrintStream(c.Ngsi, c.Client, bytes.NewReader(body), buf, pretty, lines, values, verbose, false, nil)
 
 	buf.BufferClose()
 
+	assert.NoError(t, printErr)
 	if assert.NoError(t, err) {
 		actual := helper.GetStdoutString(c)
 		expected := "[\n  {\n    \"id\": \"airqualityobserved_0\",\n    \"type\": \"AirQualityObserved\",\n    \"temperature\": {\n      \"type\": \"Number\",\n      \"value\": 6.727447926,\n      \"metadata\": {}\n    }\n  },\n  {\n    \"id\": \"airqualityobserved_1\",\n    \"type\": \"AirQualityObserved\",\n    \"temperature\": {\n      \"type\": \"Number\",\n      \"value\": 19.012560208,\n      \"metadata\": {}\n    }\n  },\n  {\n    \"id\": \"airqualityobserved_2\",\n    \"type\": \"AirQualityObserved\",\n    \"temperature\": {\n      \"type\": \"Number\",\n      \"value\": -3.196384014,\n      \"metadata\": {}\n    }\n  },\n  {\n    \"id\": \"airqualityobserved_3\",\n    \"type\": \"AirQualityObserved\",\n    \"temperature\": {\n      \"type\": \"Number\",\n      \"value\": 7.992932652,\n      \"metadata\": {}\n    }\n  },\n  {\n    \"id\": \"airqualityobserved_4\",\n    \"type\": \"AirQualityObserved\",\n    \"temperature\": {\n      \"type\": \"Number\",\n      \"value\": -6.620346091,\n      \"metadata\": {}\n    }\n  },\n  {\n    \"id\": \"airqualityobserved_5\",\n    \"type\": \"AirQualityObserved\",\n    \"temperature\": {\n      \"type\": \"Number\",\n      \"value\": -16.634766746,\n      \"metadata\": {}\n    }\n  },\n  {\n    \"id\": \"airqualityobserved_6\",\n    \"type\": \"AirQualityObserved\",\n    \"temperature\": {\n      \"type\": \"Number\",\n      \"value\": 20.263618173,\n      \"metadata\": {}\n    }\n  },\n  {\n    \"id\": \"airqualityobserved_7\",\n    \"type\": \"AirQualityObserved\",\n    \"temperature\": {\n      \"type\": \"Number\",\n      \"value\": 14.285382467,\n      \"metadata\": {}\n    }\n  },\n  {\n    \"id\": \"airqualityobserved_8\",\n    \"type\": \"AirQualityObserved\",\n    \"temperature\": {\n      \"type\": \"Number\",\n      \"value\": 6.998595286,\n      \"metadata\": {}\n    }\n  }\n]"
@@ -845,7 +1220,7 @@ func TestEntitiesPrintVerbosePretty(t *testing.T) {
 	}
 }
 
-func TestEntitiesPrintVerbose(t *testing.T) {
+func TestEntitiesPrintStreamVerbose(t *testing.T) {
 	c := setupTest([]string{"list", "entities", "--host", "orion"})
 
 	pretty := false
@@ -858,10 +1233,11 @@ func TestEntitiesPrintVerbose(t *testing.T) {
 
 	body := []byte(`[{"id":"airqualityobserved_0","type":"AirQualityObserved","temperature":{"type":"Number","value":6.727447926,"metadata":{}}},{"id":"airqualityobserved_1","type":"AirQualityObserved","temperature":{"type":"Number","value":19.012560208,"metadata":{}}},{"id":"airqualityobserved_2","type":"AirQualityObserved","temperature":{"type":"Number","value":-3.196384014,"metadata":{}}},{"id":"airqualityobserved_3","type":"AirQualityObserved","temperature":{"type":"Number","value":7.992932652,"metadata":{}}},{"id":"airqualityobserved_4","type":"AirQualityObserved","temperature":{"type":"Number","value":-6.620346091,"metadata":{}}},{"id":"airqualityobserved_5","type":"AirQualityObserved","temperature":{"type":"Number","value":-16.634766746,"metadata":{}}},{"id":"airqualityobserved_6","type":"AirQualityObserved","temperature":{"type":"Number","value":20.263618173,"metadata":{}}},{"id":"airqualityobserved_7","type":"AirQualityObserved","temperature":{"type":"Number","value":14.285382467,"metadata":{}}},{"id":"airqualityobserved_8","type":"AirQualityObserved","temperature":{"type":"Number","value":6.998595286,"metadata":{}}}]`)
 
-	err := entitiesPrint(c.Ngsi, body, buf, pretty, lines, values, verbose, false)
+	printErr, err := entitiesPrintStream(c.Ngsi, c.Client, bytes.NewReader(body), buf, pretty, lines, values, verbose, false, nil)
 
 	buf.BufferClose()
 
+	assert.NoError(t, printErr)
 	if assert.NoError(t, err) {
 		actual := helper.GetStdoutString(c)
 		expected := "[{\"id\":\"airqualityobserved_0\",\"type\":\"AirQualityObserved\",\"temperature\":{\"type\":\"Number\",\"value\":6.727447926,\"metadata\":{}}},{\"id\":\"airqualityobserved_1\",\"type\":\"AirQualityObserved\",\"temperature\":{\"type\":\"Number\",\"value\":19.012560208,\"metadata\":{}}},{\"id\":\"airqualityobserved_2\",\"type\":\"AirQualityObserved\",\"temperature\":{\"type\":\"Number\",\"value\":-3.196384014,\"metadata\":{}}},{\"id\":\"airqualityobserved_3\",\"type\":\"AirQualityObserved\",\"temperature\":{\"type\":\"Number\",\"value\":7.992932652,\"metadata\":{}}},{\"id\":\"airqualityobserved_4\",\"type\":\"AirQualityObserved\",\"temperature\":{\"type\":\"Number\",\"value\":-6.620346091,\"metadata\":{}}},{\"id\":\"airqualityobserved_5\",\"type\":\"AirQualityObserved\",\"temperature\":{\"type\":\"Number\",\"value\":-16.634766746,\"metadata\":{}}},{\"id\":\"airqualityobserved_6\",\"type\":\"AirQualityObserved\",\"temperature\":{\"type\":\"Number\",\"value\":20.263618173,\"metadata\":{}}},{\"id\":\"airqualityobserved_7\",\"type\":\"AirQualityObserved\",\"temperature\":{\"type\":\"Number\",\"value\":14.285382467,\"metadata\":{}}},{\"id\":\"airqualityobserved_8\",\"type\":\"AirQualityObserved\",\"temperature\":{\"type\":\"Number\",\"value\":6.998595286,\"metadata\":{}}}]"
@@ -869,7 +1245,7 @@ func TestEntitiesPrintVerbose(t *testing.T) {
 	}
 }
 
-func TestEntitiesPrintGeoJSON(t *testing.T) {
+func TestEntitiesPrintStreamGeoJSON(t *testing.T) {
 	c := setupTest([]string{"list", "entities", "--host", "orion"})
 
 	pretty := false
@@ -878,186 +1254,218 @@ func TestEntitiesPrintGeoJSON(t *testing.T) {
 	verbose := true
 
 	buf := ngsilib.NewJsonBuffer()
-	buf.BufferOpen(c.Ngsi.StdWriter, false, false)
+	buf.BufferOpen(c.Ngsi.StdWriter, true, false)
 
-	body := []byte(`{"type":"FeatureCollection","features":[{"id":"urn:ngsi-ld:TemperatureSensor:001","type":"Feature","properties":{"type":"TemperatureSensor","temperature":{"type":"Property","value":25,"unitCode":"CEL"},"location":{"type":"GeoProperty","value":{"type":"Point","coordinates":[139.76,35.68]}}}]}`)
+	body := []byte(`{"type":"FeatureCollection","features":[{"id":"urn:ngsi-ld:TemperatureSensor:001","type":"Feature","properties":{"type":"TemperatureSensor"}},{"id":"urn:ngsi-ld:TemperatureSensor:002","type":"Feature","properties":{"type":"TemperatureSensor"}}]}`)
 
-	err := entitiesPrint(c.Ngsi, body, buf, pretty, lines, values, verbose, false)
+	printErr, err := entitiesPrintStream(c.Ngsi, c.Client, bytes.NewReader(body), buf, pretty, lines, values, verbose, true, nil)
 
 	buf.BufferClose()
 
+	assert.NoError(t, printErr)
 	if assert.NoError(t, err) {
 		actual := helper.GetStdoutString(c)
-		expected := "[\"type\":\"FeatureCollection\",\"features\":[{\"id\":\"urn:ngsi-ld:TemperatureSensor:001\",\"type\":\"Feature\",\"properties\":{\"type\":\"TemperatureSensor\",\"temperature\":{\"type\":\"Property\",\"value\":25,\"unitCode\":\"CEL\"},\"location\":{\"type\":\"GeoProperty\",\"value\":{\"type\":\"Point\",\"coordinates\":[139.76,35.68]}}}]]"
+		expected := `{"type":"FeatureCollection","features":[{"id":"urn:ngsi-ld:TemperatureSensor:001","type":"Feature","properties":{"type":"TemperatureSensor"}},{"id":"urn:ngsi-ld:TemperatureSensor:002","type":"Feature","properties":{"type":"TemperatureSensor"}}]}`
 		assert.Equal(t, expected, actual)
 	}
 }
 
-func TestEntitiesPrintErrorGeoJSON(t *testing.T) {
+func TestEntitiesPrintStreamGeoJSONPretty(t *testing.T) {
 	c := setupTest([]string{"list", "entities", "--host", "orion"})
 
+	pretty := true
+	lines := false
+	values := false
+	verbose := true
+
+	buf := ngsilib.NewJsonBuffer()
+	buf.BufferOpen(c.Ngsi.StdWriter, true, true)
+
+	body := []byte(`{"type":"FeatureCollection","features":[{"id":"urn:ngsi-ld:TemperatureSensor:001","type":"Feature"},{"id":"urn:ngsi-ld:TemperatureSensor:002","type":"Feature"}]}`)
+
+	printErr, err := entitiesPrintStream(c.Ngsi, c.Client, bytes.NewReader(body), buf, pretty, lines, values, verbose, true, nil)
+
+	buf.BufferClose()
+
+	assert.NoError(t, printErr)
+	if assert.NoError(t, err) {
+		actual := helper.GetStdoutString(c)
+		expected := "{\n  \"type\": \"FeatureCollection\",\n  \"features\": [\n    {\n      \"id\": \"urn:ngsi-ld:TemperatureSensor:001\",\n      \"type\": \"Feature\"\n    },\n    {\n      \"id\": \"urn:ngsi-ld:TemperatureSensor:002\",\n      \"type\": \"Feature\"\n    }\n  ]\n}"
+		assert.Equal(t, expected, actual)
+	}
+}
+
+func TestEntitiesPrintStreamSafeString(t *testing.T) {
+	c := setupTest([]string{"list", "entities", "--host", "orion", "--safeString", "on"})
+
 	pretty := false
 	lines := true
-	values := true
+	values := false
 	verbose := false
-	geoJSON := true
 
 	buf := ngsilib.NewJsonBuffer()
 	buf.BufferOpen(c.Ngsi.StdWriter, false, false)
 
-	body := []byte(`{}`)
+	body := []byte(`[{"id":"device%3C001","type":"Device"}]`)
 
-	helper.SetJSONDecodeErr(c.Ngsi, 0)
+	printErr, err := entitiesPrintStream(c.Ngsi, c.Client, bytes.NewReader(body), buf, pretty, lines, values, verbose, false, nil)
+
+	buf.BufferClose()
+
+	assert.NoError(t, printErr)
+	if assert.NoError(t, err) {
+		actual := helper.GetStdoutString(c)
+		expected := "{\"id\":\"device<001\",\"type\":\"Device\"}\n"
+		assert.Equal(t, expected, actual)
+	}
+}
+
+func TestEntitiesPrintStreamErrorGeoJSON(t *testing.T) {
+	c := setupTest([]string{"list", "entities", "--host", "orion"})
+
+	buf := ngsilib.NewJsonBuffer()
+	buf.BufferOpen(c.Ngsi.StdWriter, true, false)
+
+	body := []byte(`{}`)
 
-	err := entitiesPrint(c.Ngsi, body, buf, pretty, lines, values, verbose, geoJSON)
+	printErr, err := entitiesPrintStream(c.Ngsi, c.Client, bytes.NewReader(body), buf, false, false, false, true, true, nil)
 
+	assert.NoError(t, printErr)
 	if assert.Error(t, err) {
 		ngsiErr := err.(*ngsierr.NgsiError)
-		assert.Equal(t, 1, ngsiErr.ErrNo)
-		assert.Equal(t, "geojson error: {}", ngsiErr.Message)
+		assert.Equal(t, 2, ngsiErr.ErrNo)
+		assert.Equal(t, "features not found", ngsiErr.Message)
 	}
 }
 
-func TestEntitiesPrintErrorVerboseLinesValuesDecode(t *testing.T) {
+func TestEntitiesPrintStreamErrorJSON(t *testing.T) {
 	c := setupTest([]string{"list", "entities", "--host", "orion"})
 
-	pretty := false
-	lines := true
-	values := true
-	verbose := false
+	buf := ngsilib.NewJsonBuffer()
+	buf.BufferOpen(c.Ngsi.StdWriter, false, false)
+
+	body := []byte(`[{"id":"device001"},{"id":]`)
+
+	printErr, err := entitiesPrintStream(c.Ngsi, c.Client, bytes.NewReader(body), buf, false, false, false, true, false, nil)
+
+	assert.NoError(t, printErr)
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 2, ngsiErr.ErrNo)
+		assert.Equal(t, "invalid character ']' looking for beginning of value (27) ce001\"},{\"id\":]", ngsiErr.Message)
+	}
+}
+
+func TestEntityPrintErrorLinesValuesDecode(t *testing.T) {
+	c := setupTest([]string{"list", "entities", "--host", "orion"})
 
 	buf := ngsilib.NewJsonBuffer()
 	buf.BufferOpen(c.Ngsi.StdWriter, false, false)
 
-	body := []byte(`[[10.148599472],[14.627960669],[-2.461631059],[-15.999248065],[-4.553473866],[1.147149609],[1.003624237],[11.747977585],[-4.264932072]]`)
+	body := []byte(`[10.148599472]`)
 
 	helper.SetJSONDecodeErr(c.Ngsi, 0)
 
-	err := entitiesPrint(c.Ngsi, body, buf, pretty, lines, values, verbose, false)
+	err := entityPrint(c.Ngsi, body, buf, false, true, true, false)
 
 	if assert.Error(t, err) {
 		ngsiErr := err.(*ngsierr.NgsiError)
-		assert.Equal(t, 2, ngsiErr.ErrNo)
+		assert.Equal(t, 1, ngsiErr.ErrNo)
 		assert.Equal(t, "json error", ngsiErr.Message)
 	}
 }
 
-func TestEntitiesPrintErrorVerboseLinesValuesEncode(t *testing.T) {
+func TestEntityPrintErrorLinesValuesEncode(t *testing.T) {
 	c := setupTest([]string{"list", "entities", "--host", "orion"})
 
-	pretty := false
-	lines := true
-	values := true
-	verbose := false
-
 	buf := ngsilib.NewJsonBuffer()
 	buf.BufferOpen(c.Ngsi.StdWriter, false, false)
 
-	body := []byte(`[[10.148599472],[14.627960669],[-2.461631059],[-15.999248065],[-4.553473866],[1.147149609],[1.003624237],[11.747977585],[-4.264932072]]`)
+	body := []byte(`[10.148599472]`)
 
 	helper.SetJSONEncodeErr(c.Ngsi, 0)
 
-	err := entitiesPrint(c.Ngsi, body, buf, pretty, lines, values, verbose, false)
+	err := entityPrint(c.Ngsi, body, buf, false, true, true, false)
 
 	if assert.Error(t, err) {
 		ngsiErr := err.(*ngsierr.NgsiError)
-		assert.Equal(t, 3, ngsiErr.ErrNo)
+		assert.Equal(t, 2, ngsiErr.ErrNo)
 		assert.Equal(t, "json error", ngsiErr.Message)
 	}
 }
 
-func TestEntitiesPrintErrorVerboseLinesDecode(t *testing.T) {
+func TestEntityPrintErrorLinesDecode(t *testing.T) {
 	c := setupTest([]string{"list", "entities", "--host", "orion"})
 
-	pretty := false
-	lines := true
-	values := false
-	verbose := false
-
 	buf := ngsilib.NewJsonBuffer()
 	buf.BufferOpen(c.Ngsi.StdWriter, false, false)
 
-	body := []byte(`[{"id":"airqualityobserved_0","type":"AirQualityObserved","temperature":{"type":"Number","value":6.727447926,"metadata":{}}},{"id":"airqualityobserved_1","type":"AirQualityObserved","temperature":{"type":"Number","value":19.012560208,"metadata":{}}},{"id":"airqualityobserved_2","type":"AirQualityObserved","temperature":{"type":"Number","value":-3.196384014,"metadata":{}}},{"id":"airqualityobserved_3","type":"AirQualityObserved","temperature":{"type":"Number","value":7.992932652,"metadata":{}}},{"id":"airqualityobserved_4","type":"AirQualityObserved","temperature":{"type":"Number","value":-6.620346091,"metadata":{}}},{"id":"airqualityobserved_5","type":"AirQualityObserved","temperature":{"type":"Number","value":-16.634766746,"metadata":{}}},{"id":"airqualityobserved_6","type":"AirQualityObserved","temperature":{"type":"Number","value":20.263618173,"metadata":{}}},{"id":"airqualityobserved_7","type":"AirQualityObserved","temperature":{"type":"Number","value":14.285382467,"metadata":{}}},{"id":"airqualityobserved_8","type":"AirQualityObserved","temperature":{"type":"Number","value":6.998595286,"metadata":{}}}]`)
+	body := []byte(`{"id":"airqualityobserved_0","type":"AirQualityObserved"}`)
 
 	helper.SetJSONDecodeErr(c.Ngsi, 0)
 
-	err := entitiesPrint(c.Ngsi, body, buf, pretty, lines, values, verbose, false)
+	err := entityPrint(c.Ngsi, body, buf, false, true, false, false)
 
 	if assert.Error(t, err) {
 		ngsiErr := err.(*ngsierr.NgsiError)
-		assert.Equal(t, 4, ngsiErr.ErrNo)
+		assert.Equal(t, 3, ngsiErr.ErrNo)
 		assert.Equal(t, "json error", ngsiErr.Message)
 	}
 }
 
-func TestEntitiesPrintErrorVerboseLinesEncode(t *testing.T) {
+func TestEntityPrintErrorLinesEncode(t *testing.T) {
 	c := setupTest([]string{"list", "entities", "--host", "orion"})
 
-	pretty := false
-	lines := true
-	values := false
-	verbose := false
-
 	buf := ngsilib.NewJsonBuffer()
 	buf.BufferOpen(c.Ngsi.StdWriter, false, false)
 
-	body := []byte(`[{"id":"airqualityobserved_0","type":"AirQualityObserved","temperature":{"type":"Number","value":6.727447926,"metadata":{}}},{"id":"airqualityobserved_1","type":"AirQualityObserved","temperature":{"type":"Number","value":19.012560208,"metadata":{}}},{"id":"airqualityobserved_2","type":"AirQualityObserved","temperature":{"type":"Number","value":-3.196384014,"metadata":{}}},{"id":"airqualityobserved_3","type":"AirQualityObserved","temperature":{"type":"Number","value":7.992932652,"metadata":{}}},{"id":"airqualityobserved_4","type":"AirQualityObserved","temperature":{"type":"Number","value":-6.620346091,"metadata":{}}},{"id":"airqualityobserved_5","type":"AirQualityObserved","temperature":{"type":"Number","value":-16.634766746,"metadata":{}}},{"id":"airqualityobserved_6","type":"AirQualityObserved","temperature":{"type":"Number","value":20.263618173,"metadata":{}}},{"id":"airqualityobserved_7","type":"AirQualityObserved","temperature":{"type":"Number","value":14.285382467,"metadata":{}}},{"id":"airqualityobserved_8","type":"AirQualityObserved","temperature":{"type":"Number","value":6.998595286,"metadata":{}}}]`)
+	body := []byte(`{"id":"airqualityobserved_0","type":"AirQualityObserved"}`)
 
 	helper.SetJSONEncodeErr(c.Ngsi, 0)
 
-	err := entitiesPrint(c.Ngsi, body, buf, pretty, lines, values, verbose, false)
+	err := entityPrint(c.Ngsi, body, buf, false, true, false, false)
 
 	if assert.Error(t, err) {
 		ngsiErr := err.(*ngsierr.NgsiError)
-		assert.Equal(t, 5, ngsiErr.ErrNo)
+		assert.Equal(t, 4, ngsiErr.ErrNo)
 		assert.Equal(t, "json error", ngsiErr.Message)
 	}
 }
 
-func TestEntitiesPrintErrorVerbosePretty(t *testing.T) {
+func TestEntityPrintErrorVerbosePretty(t *testing.T) {
 	c := setupTest([]string{"list", "entities", "--host", "orion"})
 
-	pretty := true
-	lines := false
-	values := false
-	verbose := true
-
 	buf := ngsilib.NewJsonBuffer()
-	buf.BufferOpen(c.Ngsi.StdWriter, false, false)
+	buf.BufferOpen(c.Ngsi.StdWriter, false, true)
 
-	body := []byte(`[{"id":"airqualityobserved_0","type":"AirQualityObserved","temperature":{"type":"Number","value":6.727447926,"metadata":{}}},{"id":"airqualityobserved_1","type":"AirQualityObserved","temperature":{"type":"Number","value":19.012560208,"metadata":{}}},{"id":"airqualityobserved_2","type":"AirQualityObserved","temperature":{"type":"Number","value":-3.196384014,"metadata":{}}},{"id":"airqualityobserved_3","type":"AirQualityObserved","temperature":{"type":"Number","value":7.992932652,"metadata":{}}},{"id":"airqualityobserved_4","type":"AirQualityObserved","temperature":{"type":"Number","value":-6.620346091,"metadata":{}}},{"id":"airqualityobserved_5","type":"AirQualityObserved","temperature":{"type":"Number","value":-16.634766746,"metadata":{}}},{"id":"airqualityobserved_6","type":"AirQualityObserved","temperature":{"type":"Number","value":20.263618173,"metadata":{}}},{"id":"airqualityobserved_7","type":"AirQualityObserved","temperature":{"type":"Number","value":14.285382467,"metadata":{}}},{"id":"airqualityobserved_8","type":"AirQualityObserved","temperature":{"type":"Number","value":6.998595286,"metadata":{}}}]`)
+	body := []byte(`{"id":"airqualityobserved_0","type":"AirQualityObserved"}`)
 
 	helper.SetJSONIndentError(c.Ngsi)
 
-	err := entitiesPrint(c.Ngsi, body, buf, pretty, lines, values, verbose, false)
+	err := entityPrint(c.Ngsi, body, buf, true, false, false, true)
 
 	if assert.Error(t, err) {
 		ngsiErr := err.(*ngsierr.NgsiError)
-		assert.Equal(t, 6, ngsiErr.ErrNo)
+		assert.Equal(t, 5, ngsiErr.ErrNo)
 		assert.Equal(t, "json error", ngsiErr.Message)
 	}
 }
 
-func TestEntitiesPrintErrorUnmarshal(t *testing.T) {
+func TestEntityPrintErrorUnmarshal(t *testing.T) {
 	c := setupTest([]string{"list", "entities", "--host", "orion"})
 
-	pretty := false
-	lines := false
-	values := false
-	verbose := false
-
 	buf := ngsilib.NewJsonBuffer()
 	buf.BufferOpen(c.Ngsi.StdWriter, false, false)
 
-	body := []byte(`[{"id":"airqualityobserved_0","type":"AirQualityObserved","temperature":{"type":"Number","value":6.727447926,"metadata":{}}},{"id":"airqualityobserved_1","type":"AirQualityObserved","temperature":{"type":"Number","value":19.012560208,"metadata":{}}},{"id":"airqualityobserved_2","type":"AirQualityObserved","temperature":{"type":"Number","value":-3.196384014,"metadata":{}}},{"id":"airqualityobserved_3","type":"AirQualityObserved","temperature":{"type":"Number","value":7.992932652,"metadata":{}}},{"id":"airqualityobserved_4","type":"AirQualityObserved","temperature":{"type":"Number","value":-6.620346091,"metadata":{}}},{"id":"airqualityobserved_5","type":"AirQualityObserved","temperature":{"type":"Number","value":-16.634766746,"metadata":{}}},{"id":"airqualityobserved_6","type":"AirQualityObserved","temperature":{"type":"Number","value":20.263618173,"metadata":{}}},{"id":"airqualityobserved_7","type":"AirQualityObserved","temperature":{"type":"Number","value":14.285382467,"metadata":{}}},{"id":"airqualityobserved_8","type":"AirQualityObserved","temperature":{"type":"Number","value":6.998595286,"metadata":{}}}]`)
+	body := []byte(`{"id":"airqualityobserved_0","type":"AirQualityObserved"}`)
 
 	helper.SetJSONDecodeErr(c.Ngsi, 0)
 
-	err := entitiesPrint(c.Ngsi, body, buf, pretty, lines, values, verbose, false)
+	err := entityPrint(c.Ngsi, body, buf, false, false, false, false)
 
 	if assert.Error(t, err) {
 		ngsiErr := err.(*ngsierr.NgsiError)
-		assert.Equal(t, 7, ngsiErr.ErrNo)
+		assert.Equal(t, 6, ngsiErr.ErrNo)
 		assert.Equal(t, "json error", ngsiErr.Message)
 	}
 }
@@ -0,0 +1,174 @@
+/*
+MIT License
+
+Copyright (c) 2020-2024 Kazuhito Suda
+
+This file is part of NGSI Go
+
+https://github.com/lets-fiware/ngsi-go
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+
+*/
+
+package ngsicmd
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/lets-fiware/ngsi-go/internal/ngsierr"
+	"github.com/lets-fiware/ngsi-go/internal/ngsilib"
+)
+
+// entityTable prints entities as rows of a table. Without columns, the entities of the first page
+// are buffered so that the columns can be made from all attributes found in them. The rows of
+// the following pages are printed as soon as they are written, and an entity having an attribute
+// that is not in the columns is an error. The table format buffers all rows until close so that
+// the columns are made from all entities and aligned once.
+type entityTable struct {
+	writer   *ngsilib.TableWriter
+	align    bool
+	columns  []string
+	known    map[string]bool
+	entities []interface{}
+}
+
+func newEntityTable(w io.Writer, format, attrs string) (*entityTable, error) {
+	const funcName = "newEntityTable"
+
+	writer, err := ngsilib.NewTableWriter(w, format)
+	if err != nil {
+		return nil, ngsierr.New(funcName, 1, err.Error(), err)
+	}
+
+	t := &entityTable{writer: writer, align: strings.EqualFold(format, "table")}
+
+	if attrs != "" {
+		t.columns = append([]string{"id", "type"}, strings.Split(attrs, ",")...)
+		if err := t.writer.Write(t.columns); err != nil {
+			return nil, ngsierr.New(funcName, 2, err.Error(), err)
+		}
+	}
+	return t, nil
+}
+
+// entityTableAttrs returns attribute names for the attrs parameter from columns of dotted paths
+func entityTableAttrs(attrs string) string {
+	if attrs == "" {
+		return ""
+	}
+
+	names := []string{}
+	found := map[string]bool{}
+	for _, column := range strings.Split(attrs, ",") {
+		name, _, _ := strings.Cut(column, ".")
+		if !found[name] {
+			names = append(names, name)
+			found[name] = true
+		}
+	}
+	return strings.Join(names, ",")
+}
+
+func (t *entityTable) write(b []byte) error {
+	const funcName = "entityTableWrite"
+
+	var entity interface{}
+	if err := ngsilib.JSONUnmarshal(b, &entity); err != nil {
+		return ngsierr.New(funcName, 1, err.Error(), err)
+	}
+
+	if t.columns == nil {
+		t.entities = append(t.entities, entity)
+		return nil
+	}
+	if t.known != nil {
+		for _, name := range ngsilib.TableColumns([]interface{}{entity}) {
+			if !t.known[name] {
+				msg := fmt.Sprintf("%s of %s is not in the columns made from the first page: use --attrs or --output table", name, ngsilib.TableCell(entity, "id"))
+				return ngsierr.New(funcName, 2, msg, nil)
+			}
+		}
+	}
+	if err := t.row(entity); err != nil {
+		return ngsierr.New(funcName, 3, err.Error(), err)
+	}
+	return nil
+}
+
+func (t *entityTable) row(entity interface{}) error {
+	row := make([]string, len(t.columns))
+	for i, column := range t.columns {
+		row[i] = ngsilib.TableCell(entity, column)
+	}
+	return t.writer.Write(row)
+}
+
+// page prints the rows written so far. It is called at the end of each page.
+// The rows of the table format are printed by close.
+func (t *entityTable) page() error {
+	const funcName = "entityTablePage"
+
+	if t.align {
+		return nil
+	}
+	if err := t.flush(); err != nil {
+		return ngsierr.New(funcName, 1, err.Error(), err)
+	}
+	return nil
+}
+
+func (t *entityTable) close() error {
+	const funcName = "entityTableClose"
+
+	if err := t.flush(); err != nil {
+		return ngsierr.New(funcName, 1, err.Error(), err)
+	}
+	return nil
+}
+
+func (t *entityTable) flush() error {
+	const funcName = "entityTableFlush"
+
+	if t.columns == nil {
+		t.columns = []string{"id", "type"}
+		t.known = map[string]bool{"id": true, "type": true, "@context": true}
+		for _, column := range ngsilib.TableColumns(t.entities) {
+			if !t.known[column] {
+				t.columns = append(t.columns, column)
+				t.known[column] = true
+			}
+		}
+		if err := t.writer.Write(t.columns); err != nil {
+			return ngsierr.New(funcName, 1, err.Error(), err)
+		}
+		for _, entity := range t.entities {
+			if err := t.row(entity); err != nil {
+				return ngsierr.New(funcName, 2, err.Error(), err)
+			}
+		}
+		t.entities = nil
+	}
+	if err := t.writer.Flush(); err != nil {
+		return ngsierr.New(funcName, 3, err.Error(), err)
+	}
+	return nil
+}
@@ -0,0 +1,305 @@
+/*
+MIT License
+
+Copyright (c) 2020-2024 Kazuhito Suda
+
+This file is part of NGSI Go
+
+https://github.com/lets-fiware/ngsi-go
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+
+*/
+
+package ngsicmd
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/lets-fiware/ngsi-go/internal/assert"
+	"github.com/lets-fiware/ngsi-go/internal/helper"
+	"github.com/lets-fiware/ngsi-go/internal/ngsierr"
+	"github.com/lets-fiware/ngsi-go/internal/ngsilib"
+)
+
+type errTableWriter struct{}
+
+func (w *errTableWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("write error")
+}
+
+func TestNewEntityTable(t *testing.T) {
+	buf := new(bytes.Buffer)
+
+	actual, err := newEntityTable(buf, "csv", "temperature,temperature.unitCode")
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, []string{"id", "type", "temperature", "temperature.unitCode"}, actual.columns)
+		_ = actual.close()
+		assert.Equal(t, "id,type,temperature,temperature.unitCode\n", buf.String())
+	}
+}
+
+func TestNewEntityTableErrorFormat(t *testing.T) {
+	_, err := newEntityTable(new(bytes.Buffer), "xml", "")
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 1, ngsiErr.ErrNo)
+		assert.Equal(t, "unknown output format: xml (csv, tsv, table)", ngsiErr.Message)
+	}
+}
+
+func TestNewEntityTableErrorWrite(t *testing.T) {
+	_, err := newEntityTable(&errTableWriter{}, "tsv", "temperature")
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 2, ngsiErr.ErrNo)
+		assert.Equal(t, "write error", ngsiErr.Message)
+	}
+}
+
+func TestEntityTableAttrs(t *testing.T) {
+	assert.Equal(t, "", entityTableAttrs(""))
+	assert.Equal(t, "temperature,humidity", entityTableAttrs("temperature,temperature.unitCode,humidity"))
+}
+
+func TestEntityTableWrite(t *testing.T) {
+	_ = setupTest([]string{"list", "entities", "--host", "orion"})
+	buf := new(bytes.Buffer)
+	table, _ := newEntityTable(buf, "csv", "temperature")
+
+	err := table.write([]byte(`{"id":"Room1","type":"Room","temperature":{"type":"Number","value":21.5}}`))
+
+	if assert.NoError(t, err) {
+		_ = table.close()
+		assert.Equal(t, "id,type,temperature\nRoom1,Room,21.5\n", buf.String())
+	}
+}
+
+func TestEntityTableWriteErrorJSON(t *testing.T) {
+	c := setupTest([]string{"list", "entities", "--host", "orion"})
+	table, _ := newEntityTable(new(bytes.Buffer), "csv", "")
+
+	helper.SetJSONDecodeErr(c.Ngsi, 0)
+
+	err := table.write([]byte(`{}`))
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 1, ngsiErr.ErrNo)
+		assert.Equal(t, "json error", ngsiErr.Message)
+	}
+}
+
+func TestEntityTableWriteErrorRow(t *testing.T) {
+	_ = setupTest([]string{"list", "entities", "--host", "orion"})
+	table := &entityTable{columns: []string{"id"}}
+	table.writer, _ = ngsilib.NewTableWriter(&errTableWriter{}, "tsv")
+
+	err := table.write([]byte(`{"id":"Room1"}`))
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 3, ngsiErr.ErrNo)
+		assert.Equal(t, "write error", ngsiErr.Message)
+	}
+}
+
+func TestEntityTableWriteErrorUnknownAttribute(t *testing.T) {
+	_ = setupTest([]string{"list", "entities", "--host", "orion"})
+	buf := new(bytes.Buffer)
+	table, _ := newEntityTable(buf, "csv", "")
+
+	_ = table.write([]byte(`{"id":"Room1","type":"Room","temperature":{"type":"Number","value":21}}`))
+	_ = table.page()
+
+	err := table.write([]byte(`{"id":"Room2","type":"Room","temperature":{"type":"Number","value":22},"humidity":{"type":"Number","value":40}}`))
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 2, ngsiErr.ErrNo)
+		assert.Equal(t, "humidity of Room2 is not in the columns made from the first page: use --attrs or --output table", ngsiErr.Message)
+	}
+}
+
+func TestEntityTableClose(t *testing.T) {
+	_ = setupTest([]string{"list", "entities", "--host", "orion"})
+	buf := new(bytes.Buffer)
+	table, _ := newEntityTable(buf, "csv", "")
+
+	_ = table.write([]byte(`{"@context":"https://uri.etsi.org/ngsi-ld/v1/ngsi-ld-core-context.jsonld","id":"Room1","type":"Room","temperature":{"type":"Property","value":21}}`))
+	_ = table.write([]byte(`{"id":"Room2","type":"Room","humidity":{"type":"Property","value":40}}`))
+	err := table.close()
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, "id,type,humidity,temperature\nRoom1,Room,,21\nRoom2,Room,40,\n", buf.String())
+	}
+}
+
+func TestEntityTableCloseNoEntities(t *testing.T) {
+	buf := new(bytes.Buffer)
+	table, _ := newEntityTable(buf, "csv", "")
+
+	err := table.close()
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, "id,type\n", buf.String())
+	}
+}
+
+func TestEntityTableCloseErrorHeader(t *testing.T) {
+	table := &entityTable{}
+	table.writer, _ = ngsilib.NewTableWriter(&errTableWriter{}, "tsv")
+
+	err := table.close()
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 1, ngsiErr.ErrNo)
+		assert.Equal(t, "write error", ngsiErr.Message)
+	}
+}
+
+func TestEntityTableCloseErrorFlush(t *testing.T) {
+	table := &entityTable{columns: []string{"id"}}
+	table.writer, _ = ngsilib.NewTableWriter(&errTableWriter{}, "csv")
+	_ = table.writer.Write([]string{"id"})
+
+	err := table.close()
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 1, ngsiErr.ErrNo)
+		assert.Equal(t, "write error", ngsiErr.Message)
+	}
+}
+
+func TestEntityTablePage(t *testing.T) {
+	_ = setupTest([]string{"list", "entities", "--host", "orion"})
+	buf := new(bytes.Buffer)
+	table, _ := newEntityTable(buf, "csv", "")
+
+	_ = table.write([]byte(`{"id":"Room1","type":"Room","temperature":{"type":"Number","value":21}}`))
+	assert.Equal(t, "", buf.String())
+
+	err := table.page()
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, "id,type,temperature\nRoom1,Room,21\n", buf.String())
+		_ = table.write([]byte(`{"@context":"https://uri.etsi.org/ngsi-ld/v1/ngsi-ld-core-context.jsonld","id":"Room2","type":"Room","temperature":{"type":"Number","value":22}}`))
+		err = table.page()
+		assert.NoError(t, err)
+		assert.Equal(t, "id,type,temperature\nRoom1,Room,21\nRoom2,Room,22\n", buf.String())
+		assert.Equal(t, 0, len(table.entities))
+	}
+}
+
+func TestEntityTablePageTable(t *testing.T) {
+	_ = setupTest([]string{"list", "entities", "--host", "orion"})
+	buf := new(bytes.Buffer)
+	table, _ := newEntityTable(buf, "table", "")
+
+	_ = table.write([]byte(`{"id":"Room1","type":"Room","temperature":{"type":"Number","value":21}}`))
+	err := table.page()
+	assert.NoError(t, err)
+	assert.Equal(t, "", buf.String())
+	_ = table.write([]byte(`{"id":"LivingRoom2","type":"Room","humidity":{"type":"Number","value":40}}`))
+	_ = table.page()
+
+	err = table.close()
+
+	if assert.NoError(t, err) {
+		expected := "" +
+			"id           type  humidity  temperature\n" +
+			"Room1        Room            21\n" +
+			"LivingRoom2  Room  40        \n"
+		assert.Equal(t, expected, buf.String())
+	}
+}
+
+func TestEntityTablePageError(t *testing.T) {
+	table := &entityTable{}
+	table.writer, _ = ngsilib.NewTableWriter(&errTableWriter{}, "tsv")
+
+	err := table.page()
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 1, ngsiErr.ErrNo)
+		assert.Equal(t, "write error", ngsiErr.Message)
+	}
+}
+
+func TestEntityTableFlushErrorHeader(t *testing.T) {
+	table := &entityTable{}
+	table.writer, _ = ngsilib.NewTableWriter(&errTableWriter{}, "tsv")
+
+	err := table.flush()
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 1, ngsiErr.ErrNo)
+		assert.Equal(t, "write error", ngsiErr.Message)
+	}
+}
+
+func TestEntityTableFlushErrorRow(t *testing.T) {
+	table := &entityTable{entities: []interface{}{map[string]interface{}{"id": "Room1"}}}
+	w := &limitTableWriter{n: 1}
+	table.writer, _ = ngsilib.NewTableWriter(w, "tsv")
+
+	err := table.flush()
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 2, ngsiErr.ErrNo)
+		assert.Equal(t, "write error", ngsiErr.Message)
+	}
+}
+
+func TestEntityTableFlushErrorFlush(t *testing.T) {
+	table := &entityTable{columns: []string{"id"}}
+	table.writer, _ = ngsilib.NewTableWriter(&errTableWriter{}, "csv")
+	_ = table.writer.Write([]string{"id"})
+
+	err := table.flush()
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 3, ngsiErr.ErrNo)
+		assert.Equal(t, "write error", ngsiErr.Message)
+	}
+}
+
+// limitTableWriter fails after n writes
+type limitTableWriter struct {
+	n int
+}
+
+func (w *limitTableWriter) Write(p []byte) (int, error) {
+	if w.n == 0 {
+		return 0, errors.New("write error")
+	}
+	w.n--
+	return len(p), nil
+}
@@ -295,6 +295,15 @@ var (
 		Aliases:  []string{"d"},
 		Required: true,
 	}
+	dataFormatFlag = &ngsicli.StringFlag{
+		Name:    "format",
+		Usage:   "data format (`FORMAT`: json, csv or tsv)",
+		Choices: []string{"json", "csv", "tsv"},
+	}
+	csvMappingFlag = &ngsicli.StringFlag{
+		Name:  "mapping",
+		Usage: "mapping of csv columns to entity id, type and attributes",
+	}
 )
 
 // Temporal entity
@@ -414,6 +423,11 @@ var (
 		Name:  "lastN",
 		Usage: "number of data entries to retrieve since the final date backwards",
 	}
+	pageSizeFlag = &ngsicli.Int64Flag{
+		Name:  "pageSize",
+		Usage: "number of entities per request (1-1000)",
+		Value: 100,
+	}
 )
 
 // flags for options
@@ -552,4 +566,39 @@ var (
 		Usage:  "url to be invoked when a notification is generated (v2)",
 		Hidden: true,
 	}
+	probeFlag = &ngsicli.StringFlag{
+		Name:    "probe",
+		Usage:   "probe notification urls (tcp, http)",
+		Choices: []string{"tcp", "http"},
+	}
+	expiresWithinFlag = &ngsicli.StringFlag{
+		Name:  "expiresWithin",
+		Usage: "flag subscriptions expiring within period (e.g. 1day, 12hours)",
+	}
+	reactivateFlag = &ngsicli.BoolFlag{
+		Name:  "reactivate",
+		Usage: "reactivate failing subscriptions",
+	}
+	deleteFlaggedFlag = &ngsicli.BoolFlag{
+		Name:  "delete",
+		Usage: "delete failing or expired subscriptions",
+	}
+	destinationFlag = &ngsicli.StringFlag{
+		Name:     "host2",
+		Aliases:  []string{"d"},
+		Usage:    "destination broker `VALUE`",
+		Required: true,
+	}
+	tenant2Flag = &ngsicli.StringFlag{
+		Name:  "service2",
+		Usage: "FIWARE Service for destination",
+	}
+	scope2Flag = &ngsicli.StringFlag{
+		Name:  "path2",
+		Usage: "FIWARE ServicePath for destination",
+	}
+	context2Flag = &ngsicli.StringFlag{
+		Name:  "context2",
+		Usage: "@context for destination",
+	}
 )
@@ -30,10 +30,21 @@ SOFTWARE.
 package ngsicmd
 
 import (
+	"testing"
+
+	"github.com/lets-fiware/ngsi-go/internal/assert"
 	"github.com/lets-fiware/ngsi-go/internal/helper"
 	"github.com/lets-fiware/ngsi-go/internal/ngsicli"
+	"github.com/lets-fiware/ngsi-go/internal/ngsilib"
 )
 
 func setupTest(args []string) *ngsicli.Context {
 	return helper.SetupTest(NewNgsiApp(), args)
 }
+
+func getFilteredStdoutString(t *testing.T, c *ngsicli.Context) string {
+	w := c.Ngsi.StdWriter.(*ngsilib.FilterWriter)
+	c.Ngsi.StdWriter = w.Writer
+	assert.NoError(t, w.Flush())
+	return helper.GetStdoutString(c)
+}
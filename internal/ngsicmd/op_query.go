@@ -31,6 +31,7 @@ package ngsicmd
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"net/http"
 
@@ -51,13 +52,25 @@ func opQuery(c *ngsicli.Context, ngsi *ngsilib.NGSI, client *ngsilib.Client) err
 	limit := 100
 
 	verbose := c.IsSet("verbose")
-	if c.Bool("pretty") {
+	if c.Bool("pretty") || c.IsOutputFiltered() {
 		verbose = true
 	}
 	lines := c.Bool("lines")
 
+	var table *entityTable
+	if c.IsSet("output") {
+		if c.IsSetOR([]string{"values", "lines"}) {
+			return ngsierr.New(funcName, 13, "cannot specfiy values or lines with output", nil)
+		}
+		var err error
+		table, err = newEntityTable(ngsi.StdWriter, c.String("output"), "")
+		if err != nil {
+			return ngsierr.New(funcName, 14, err.Error(), err)
+		}
+	}
+
 	buf := ngsilib.NewJsonBuffer()
-	if verbose {
+	if table == nil && verbose {
 		buf.BufferOpen(ngsi.StdWriter, false, false)
 	}
 
@@ -90,7 +103,7 @@ func opQuery(c *ngsicli.Context, ngsi *ngsilib.NGSI, client *ngsilib.Client) err
 			return ngsierr.New(funcName, 1, err.Error(), err)
 		}
 
-		res, body, err := client.HTTPPost(b)
+		res, body, err := client.HTTPQuery(b)
 		if err != nil {
 			return ngsierr.New(funcName, 2, err.Error(), err)
 		}
@@ -122,7 +135,21 @@ func opQuery(c *ngsicli.Context, ngsi *ngsilib.NGSI, client *ngsilib.Client) err
 			}
 		}
 
-		if lines {
+		if table != nil {
+			var entities []json.RawMessage
+			err = ngsilib.JSONUnmarshal(body, &entities)
+			if err != nil {
+				return ngsierr.New(funcName, 15, err.Error(), err)
+			}
+			for _, e := range entities {
+				if err := table.write(e); err != nil {
+					return ngsierr.New(funcName, 16, err.Error(), err)
+				}
+			}
+			if err := table.page(); err != nil {
+				return ngsierr.New(funcName, 17, err.Error(), err)
+			}
+		} else if lines {
 			if c.IsSet("values") {
 				var values [][]interface{}
 				err = ngsilib.JSONUnmarshal(body, &values)
@@ -179,7 +206,11 @@ func opQuery(c *ngsicli.Context, ngsi *ngsilib.NGSI, client *ngsilib.Client) err
 		}
 	}
 
-	if verbose {
+	if table != nil {
+		if err := table.close(); err != nil {
+			return ngsierr.New(funcName, 18, err.Error(), err)
+		}
+	} else if verbose {
 		buf.BufferClose()
 	}
 	return nil
@@ -59,6 +59,27 @@ func TestOpQuery(t *testing.T) {
 	}
 }
 
+func TestOpQueryDryRun(t *testing.T) {
+	c := setupTest([]string{"--dryRun", "get", "entities", "--host", "orion", "--data", "{\"entities\":[{\"idPattern\":\".*\",\"type\":\"Sensor\"}]}"})
+
+	reqRes := helper.MockHTTPReqRes{}
+	reqRes.Res.StatusCode = http.StatusOK
+	reqRes.Path = "/v2/op/query"
+	reqRes.ResBody = []byte(`[{"id": "Sensor001","type":"Sensor"}]`)
+	reqRes.ResHeader = http.Header{"Fiware-Total-Count": []string{"1"}}
+
+	helper.SetClientHTTP(c, reqRes)
+
+	err := opQuery(c, c.Ngsi, c.Client)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, true, c.Client.DryRun)
+		actual := helper.GetStdoutString(c)
+		expected := "Sensor001\n"
+		assert.Equal(t, expected, actual)
+	}
+}
+
 func TestOpQueryCountZero(t *testing.T) {
 	c := setupTest([]string{"get", "entities", "--host", "orion", "--data", "{\"entities\":[{\"idPattern\":\".*\",\"type\":\"Sensor\"}]}"})
 
@@ -185,6 +206,26 @@ func TestOpQueryVerbose(t *testing.T) {
 	}
 }
 
+func TestOpQueryTemplate(t *testing.T) {
+	c := setupTest([]string{"--template", "{{.id}} {{.temperature.value}}", "get", "entities", "--host", "orion", "--data", "{\"entities\":[{\"idPattern\":\".*\",\"type\":\"Sensor\"}]}"})
+
+	reqRes := helper.MockHTTPReqRes{}
+	reqRes.Res.StatusCode = http.StatusOK
+	reqRes.Path = "/v2/op/query"
+	reqRes.ResBody = []byte(`[{"id":"Sensor001","type":"Sensor","temperature":{"type":"Number","value":21,"metadata":{}}},{"id":"Sensor002","type":"Sensor"}]`)
+	reqRes.ResHeader = http.Header{"Fiware-Total-Count": []string{"2"}}
+
+	helper.SetClientHTTP(c, reqRes)
+
+	err := opQuery(c, c.Ngsi, c.Client)
+
+	if assert.NoError(t, err) {
+		actual := getFilteredStdoutString(t, c)
+		expected := "Sensor001 21\nSensor002 \n"
+		assert.Equal(t, expected, actual)
+	}
+}
+
 func TestOpQueryVerbosePretty(t *testing.T) {
 	c := setupTest([]string{"get", "entities", "--host", "orion", "--data", "{\"entities\":[{\"idPattern\":\".*\",\"type\":\"Sensor\"}]}", "--verbose", "--pretty"})
 
@@ -205,6 +246,46 @@ func TestOpQueryVerbosePretty(t *testing.T) {
 	}
 }
 
+func TestOpQueryOutput(t *testing.T) {
+	c := setupTest([]string{"get", "entities", "--host", "orion", "--data", "{\"entities\":[{\"idPattern\":\".*\",\"type\":\"Sensor\"}]}", "--output", "csv"})
+
+	reqRes := helper.MockHTTPReqRes{}
+	reqRes.Res.StatusCode = http.StatusOK
+	reqRes.Path = "/v2/op/query"
+	reqRes.ResBody = []byte(`[{"id":"Sensor001","type":"Sensor","temperature":{"type":"Number","value":21,"metadata":{}}},{"id":"Sensor002","type":"Sensor","humidity":{"type":"Number","value":40,"metadata":{}}}]`)
+	reqRes.ResHeader = http.Header{"Fiware-Total-Count": []string{"2"}}
+
+	helper.SetClientHTTP(c, reqRes)
+
+	err := opQuery(c, c.Ngsi, c.Client)
+
+	if assert.NoError(t, err) {
+		actual := helper.GetStdoutString(c)
+		expected := "id,type,humidity,temperature\nSensor001,Sensor,,21\nSensor002,Sensor,40,\n"
+		assert.Equal(t, expected, actual)
+	}
+}
+
+func TestOpQueryOutputCountZero(t *testing.T) {
+	c := setupTest([]string{"get", "entities", "--host", "orion", "--data", "{\"entities\":[{\"idPattern\":\".*\",\"type\":\"Sensor\"}]}", "--output", "csv"})
+
+	reqRes := helper.MockHTTPReqRes{}
+	reqRes.Res.StatusCode = http.StatusOK
+	reqRes.Path = "/v2/op/query"
+	reqRes.ResBody = []byte(`[]`)
+	reqRes.ResHeader = http.Header{"Fiware-Total-Count": []string{"0"}}
+
+	helper.SetClientHTTP(c, reqRes)
+
+	err := opQuery(c, c.Ngsi, c.Client)
+
+	if assert.NoError(t, err) {
+		actual := helper.GetStdoutString(c)
+		expected := "id,type\n"
+		assert.Equal(t, expected, actual)
+	}
+}
+
 func TestOpQueryCount(t *testing.T) {
 	c := setupTest([]string{"get", "entities", "--host", "orion", "--data", "{\"entities\":[{\"idPattern\":\".*\",\"type\":\"Sensor\"}]}", "--verbose", "--count"})
 
@@ -478,3 +559,116 @@ func TestOpQueryErrorUnmarshal(t *testing.T) {
 		assert.Equal(t, "json error", ngsiErr.Message)
 	}
 }
+
+func TestOpQueryErrorOutputLines(t *testing.T) {
+	c := setupTest([]string{"get", "entities", "--host", "orion", "--data", "{\"entities\":[{\"idPattern\":\".*\",\"type\":\"Sensor\"}]}", "--output", "csv", "--lines"})
+
+	err := opQuery(c, c.Ngsi, c.Client)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 13, ngsiErr.ErrNo)
+		assert.Equal(t, "cannot specfiy values or lines with output", ngsiErr.Message)
+	}
+}
+
+func TestOpQueryErrorOutput(t *testing.T) {
+	c := setupTest([]string{"get", "entities", "--host", "orion", "--data", "{\"entities\":[{\"idPattern\":\".*\",\"type\":\"Sensor\"}]}", "--output", "csv"})
+	c.GetStringFlag("output").Value = "xml"
+
+	err := opQuery(c, c.Ngsi, c.Client)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 14, ngsiErr.ErrNo)
+		assert.Equal(t, "unknown output format: xml (csv, tsv, table)", ngsiErr.Message)
+	}
+}
+
+func TestOpQueryErrorOutputUnmarshal(t *testing.T) {
+	c := setupTest([]string{"get", "entities", "--host", "orion", "--data", "{\"entities\":[{\"idPattern\":\".*\",\"type\":\"Sensor\"}]}", "--output", "csv"})
+
+	reqRes := helper.MockHTTPReqRes{}
+	reqRes.Res.StatusCode = http.StatusOK
+	reqRes.Path = "/v2/op/query"
+	reqRes.ResBody = []byte(`[{"id":"Sensor001","type":"Sensor","temperature":{"type":"Number","value":21,"metadata":{}}},{"id":"Sensor002","type":"Sensor","humidity":{"type":"Number","value":40,"metadata":{}}}]`)
+	reqRes.ResHeader = http.Header{"Fiware-Total-Count": []string{"2"}}
+
+	helper.SetClientHTTP(c, reqRes)
+
+	helper.SetJSONDecodeErr(c.Ngsi, 0)
+
+	err := opQuery(c, c.Ngsi, c.Client)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 15, ngsiErr.ErrNo)
+		assert.Equal(t, "json error", ngsiErr.Message)
+	}
+}
+
+func TestOpQueryErrorOutputWrite(t *testing.T) {
+	c := setupTest([]string{"get", "entities", "--host", "orion", "--data", "{\"entities\":[{\"idPattern\":\".*\",\"type\":\"Sensor\"}]}", "--output", "csv"})
+
+	reqRes := helper.MockHTTPReqRes{}
+	reqRes.Res.StatusCode = http.StatusOK
+	reqRes.Path = "/v2/op/query"
+	reqRes.ResBody = []byte(`[{"id":"Sensor001","type":"Sensor","temperature":{"type":"Number","value":21,"metadata":{}}},{"id":"Sensor002","type":"Sensor","humidity":{"type":"Number","value":40,"metadata":{}}}]`)
+	reqRes.ResHeader = http.Header{"Fiware-Total-Count": []string{"2"}}
+
+	helper.SetClientHTTP(c, reqRes)
+
+	helper.SetJSONDecodeErr(c.Ngsi, 1)
+
+	err := opQuery(c, c.Ngsi, c.Client)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 16, ngsiErr.ErrNo)
+		assert.Equal(t, "json error", ngsiErr.Message)
+	}
+}
+
+func TestOpQueryErrorOutputPage(t *testing.T) {
+	c := setupTest([]string{"get", "entities", "--host", "orion", "--data", "{\"entities\":[{\"idPattern\":\".*\",\"type\":\"Sensor\"}]}", "--output", "csv"})
+
+	reqRes := helper.MockHTTPReqRes{}
+	reqRes.Res.StatusCode = http.StatusOK
+	reqRes.Path = "/v2/op/query"
+	reqRes.ResBody = []byte(`[{"id":"Sensor001","type":"Sensor","temperature":{"type":"Number","value":21,"metadata":{}}},{"id":"Sensor002","type":"Sensor","humidity":{"type":"Number","value":40,"metadata":{}}}]`)
+	reqRes.ResHeader = http.Header{"Fiware-Total-Count": []string{"2"}}
+
+	helper.SetClientHTTP(c, reqRes)
+
+	c.Ngsi.StdWriter = &errTableWriter{}
+
+	err := opQuery(c, c.Ngsi, c.Client)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 17, ngsiErr.ErrNo)
+		assert.Equal(t, "write error", ngsiErr.Message)
+	}
+}
+
+func TestOpQueryErrorOutputClose(t *testing.T) {
+	c := setupTest([]string{"get", "entities", "--host", "orion", "--data", "{\"entities\":[{\"idPattern\":\".*\",\"type\":\"Sensor\"}]}", "--output", "csv"})
+
+	reqRes := helper.MockHTTPReqRes{}
+	reqRes.Res.StatusCode = http.StatusOK
+	reqRes.Path = "/v2/op/query"
+	reqRes.ResBody = []byte(`[]`)
+	reqRes.ResHeader = http.Header{"Fiware-Total-Count": []string{"0"}}
+
+	helper.SetClientHTTP(c, reqRes)
+
+	c.Ngsi.StdWriter = &errTableWriter{}
+
+	err := opQuery(c, c.Ngsi, c.Client)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 18, ngsiErr.ErrNo)
+		assert.Equal(t, "write error", ngsiErr.Message)
+	}
+}
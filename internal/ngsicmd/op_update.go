@@ -42,6 +42,11 @@ import (
 func opUpdate(c *ngsicli.Context, ngsi *ngsilib.NGSI, client *ngsilib.Client, actionType string) (err error) {
 	const funcName = "opUpdate"
 
+	switch c.String("format") {
+	case "csv", "tsv":
+		return opUpdateCSV(c, ngsi, client, actionType)
+	}
+
 	keyValues := c.Bool("keyValues")
 	safeStirng := client.IsSafeString()
 	lines := false
@@ -69,51 +74,65 @@ func opUpdate(c *ngsicli.Context, ngsi *ngsilib.NGSI, client *ngsilib.Client, ac
 		lines = false
 	}
 
+	parallel, err := ngsicli.ParallelWorkers(c)
+	if err != nil {
+		return ngsierr.New(funcName, 4, err.Error(), err)
+	}
+
 	dec := json.NewDecoder(&reader)
 
 	if !lines {
 		_, _ = dec.Token()
 	}
 
-	var entities []interface{}
+	produce := func() (interface{}, error) {
+		var entities []interface{}
 
-	for dec.More() {
-		entity := make(map[string]interface{})
-		err := dec.Decode(&entity)
-		if err != nil {
-			if err, ok := err.(*json.SyntaxError); ok {
-				return ngsierr.New(funcName, 4, fmt.Sprintf("%s (%d)", err.Error(), err.Offset), err)
+		for dec.More() && len(entities) < 100 {
+			entity := make(map[string]interface{})
+			err := dec.Decode(&entity)
+			if err != nil {
+				if err, ok := err.(*json.SyntaxError); ok {
+					return nil, ngsierr.New(funcName, 5, fmt.Sprintf("%s (%d)", err.Error(), err.Offset), err)
+				}
+				return nil, ngsierr.New(funcName, 6, err.Error(), err)
 			}
-			return ngsierr.New(funcName, 5, err.Error(), err)
+			entities = append(entities, entity)
 		}
-		entities = append(entities, entity)
 
-		if len(entities) >= 100 {
-			res, body, err := client.OpUpdate(entities, actionType, keyValues, safeStirng)
-			if err != nil {
-				return ngsierr.New(funcName, 6, err.Error(), err)
-			}
-			if res.StatusCode != http.StatusNoContent {
-				return ngsierr.New(funcName, 7, fmt.Sprintf("%s %s", res.Status, string(body)), nil)
-			}
-			entities = nil
+		if len(entities) == 0 {
+			return nil, nil
 		}
+		return entities, nil
 	}
 
-	if len(entities) > 0 {
-		res, body, err := client.OpUpdate(entities, actionType, keyValues, safeStirng)
+	work := func(job interface{}) (interface{}, error) {
+		res, body, err := client.Clone().OpUpdate(job, actionType, keyValues, safeStirng)
+		if ngsilib.IsDryRun(err) {
+			return nil, nil
+		}
 		if err != nil {
-			return ngsierr.New(funcName, 8, err.Error(), err)
+			return nil, ngsierr.New(funcName, 7, err.Error(), err)
 		}
 		if res.StatusCode != http.StatusNoContent {
-			return ngsierr.New(funcName, 9, fmt.Sprintf("%s %s", res.Status, string(body)), nil)
+			return nil, ngsierr.New(funcName, 8, fmt.Sprintf("%s %s", res.Status, string(body)), nil)
 		}
+		return nil, nil
+	}
+
+	consume := func(result interface{}, err error) error {
+		return err
+	}
+
+	err = ngsilib.Pipeline(parallel, produce, work, consume)
+	if err != nil {
+		return err
 	}
 
 	if !lines {
 		_, err = dec.Token()
 		if err != nil {
-			return ngsierr.New(funcName, 10, err.Error(), err)
+			return ngsierr.New(funcName, 9, err.Error(), err)
 		}
 	}
 
@@ -0,0 +1,357 @@
+/*
+MIT License
+
+Copyright (c) 2020-2024 Kazuhito Suda
+
+This file is part of NGSI Go
+
+https://github.com/lets-fiware/ngsi-go
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+
+*/
+
+package ngsicmd
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/lets-fiware/ngsi-go/internal/ngsicli"
+	"github.com/lets-fiware/ngsi-go/internal/ngsierr"
+	"github.com/lets-fiware/ngsi-go/internal/ngsilib"
+)
+
+type csvMapping struct {
+	ID         string                    `json:"id"`
+	Type       string                    `json:"type,omitempty"`
+	EntityType string                    `json:"entityType,omitempty"`
+	Attrs      map[string]csvMappingAttr `json:"attrs,omitempty"`
+}
+
+type csvMappingAttr struct {
+	Column string `json:"column,omitempty"`
+	Type   string `json:"type,omitempty"`
+}
+
+type csvColumn struct {
+	name     string
+	index    int
+	ngsiType string
+}
+
+type csvColumns struct {
+	id         int
+	typ        int
+	entityType string
+	attrs      []csvColumn
+}
+
+type csvRejectedRow struct {
+	line    int
+	message string
+}
+
+type csvJob struct {
+	entities []interface{}
+	lines    []int
+	rejected []csvRejectedRow
+}
+
+func opUpdateCSV(c *ngsicli.Context, ngsi *ngsilib.NGSI, client *ngsilib.Client, actionType string) error {
+	const funcName = "opUpdateCSV"
+
+	if !c.IsSet("mapping") {
+		return ngsierr.New(funcName, 1, "specify --mapping with --format "+c.String("format"), nil)
+	}
+
+	mapping, err := csvReadMapping(ngsi, c.String("mapping"))
+	if err != nil {
+		return ngsierr.New(funcName, 2, err.Error(), err)
+	}
+
+	parallel, err := ngsicli.ParallelWorkers(c)
+	if err != nil {
+		return ngsierr.New(funcName, 3, err.Error(), err)
+	}
+
+	safeString := client.IsSafeString()
+
+	client.SetHeader("Content-Type", "application/json")
+
+	fileReader, err := ngsi.GetReader(c.String("data"))
+	if err != nil {
+		return ngsierr.New(funcName, 4, err.Error(), err)
+	}
+	defer func() { _ = fileReader.Close() }()
+
+	reader := fileReader.File()
+	r := csv.NewReader(&reader)
+	if c.String("format") == "tsv" {
+		r.Comma = '\t'
+		r.LazyQuotes = true
+	}
+	r.FieldsPerRecord = -1
+
+	header, err := r.Read()
+	if err != nil {
+		return ngsierr.New(funcName, 5, err.Error(), err)
+	}
+
+	columns, err := mapping.columns(header)
+	if err != nil {
+		return ngsierr.New(funcName, 6, err.Error(), err)
+	}
+
+	eof := false
+
+	produce := func() (interface{}, error) {
+		job := &csvJob{}
+
+		for !eof && len(job.entities) < 100 {
+			record, err := r.Read()
+			if err == io.EOF {
+				eof = true
+				break
+			}
+			if err != nil {
+				var perr *csv.ParseError
+				if errors.As(err, &perr) {
+					job.rejected = append(job.rejected, csvRejectedRow{line: perr.StartLine, message: perr.Err.Error()})
+					continue
+				}
+				return nil, ngsierr.New(funcName, 7, err.Error(), err)
+			}
+			line, _ := r.FieldPos(0)
+			entity, err := columns.entity(record)
+			if err != nil {
+				job.rejected = append(job.rejected, csvRejectedRow{line: line, message: err.Error()})
+				continue
+			}
+			job.entities = append(job.entities, entity)
+			job.lines = append(job.lines, line)
+		}
+
+		if len(job.entities) == 0 && len(job.rejected) == 0 {
+			return nil, nil
+		}
+		return job, nil
+	}
+
+	work := func(j interface{}) (interface{}, error) {
+		job := j.(*csvJob)
+		if len(job.entities) == 0 {
+			return job, nil
+		}
+
+		res, body, err := client.Clone().OpUpdate(job.entities, actionType, false, safeString)
+		if ngsilib.IsDryRun(err) {
+			return job, nil
+		}
+		if err != nil {
+			return nil, ngsierr.New(funcName, 8, err.Error(), err)
+		}
+		if res.StatusCode == http.StatusNoContent {
+			return job, nil
+		}
+
+		// The batch was rejected as a whole. Send the entities one by one to find the rejected rows.
+		for i, entity := range job.entities {
+			res, body, err = client.Clone().OpUpdate([]interface{}{entity}, actionType, false, safeString)
+			if err != nil {
+				return nil, ngsierr.New(funcName, 9, err.Error(), err)
+			}
+			if res.StatusCode != http.StatusNoContent {
+				job.rejected = append(job.rejected, csvRejectedRow{line: job.lines[i], message: fmt.Sprintf("%s %s", res.Status, string(body))})
+			}
+		}
+		return job, nil
+	}
+
+	var rejected []csvRejectedRow
+
+	consume := func(result interface{}, err error) error {
+		if err != nil {
+			return err
+		}
+		rejected = append(rejected, result.(*csvJob).rejected...)
+		return nil
+	}
+
+	err = ngsilib.Pipeline(parallel, produce, work, consume)
+	if err != nil {
+		return err
+	}
+
+	if len(rejected) > 0 {
+		sort.SliceStable(rejected, func(i, j int) bool { return rejected[i].line < rejected[j].line })
+		for _, row := range rejected {
+			fmt.Fprintf(ngsi.StdWriter, "row %d: %s\n", row.line, row.message)
+		}
+		return ngsierr.New(funcName, 10, fmt.Sprintf("%d rows rejected", len(rejected)), nil)
+	}
+
+	return nil
+}
+
+func csvReadMapping(ngsi *ngsilib.NGSI, s string) (*csvMapping, error) {
+	const funcName = "csvReadMapping"
+
+	b, err := ngsi.ReadAll(s)
+	if err != nil {
+		return nil, ngsierr.New(funcName, 1, err.Error(), err)
+	}
+
+	var mapping csvMapping
+	err = ngsilib.JSONUnmarshal(b, &mapping)
+	if err != nil {
+		return nil, ngsierr.New(funcName, 2, err.Error(), err)
+	}
+
+	if mapping.ID == "" {
+		return nil, ngsierr.New(funcName, 3, "mapping: id not found", nil)
+	}
+	if mapping.Type == "" && mapping.EntityType == "" {
+		return nil, ngsierr.New(funcName, 4, "mapping: type or entityType not found", nil)
+	}
+
+	return &mapping, nil
+}
+
+func (m *csvMapping) columns(header []string) (*csvColumns, error) {
+	const funcName = "csvMappingColumns"
+
+	index := make(map[string]int)
+	for i, name := range header {
+		if i == 0 {
+			name = strings.TrimPrefix(name, "\ufeff")
+		}
+		index[strings.TrimSpace(name)] = i
+	}
+
+	columns := &csvColumns{typ: -1, entityType: m.EntityType}
+
+	i, ok := index[m.ID]
+	if !ok {
+		return nil, ngsierr.New(funcName, 1, "column not found: "+m.ID, nil)
+	}
+	columns.id = i
+
+	if m.Type != "" {
+		i, ok := index[m.Type]
+		if !ok {
+			return nil, ngsierr.New(funcName, 2, "column not found: "+m.Type, nil)
+		}
+		columns.typ = i
+	}
+
+	for name, attr := range m.Attrs {
+		column := attr.Column
+		if column == "" {
+			column = name
+		}
+		i, ok := index[column]
+		if !ok {
+			return nil, ngsierr.New(funcName, 3, "column not found: "+column, nil)
+		}
+		ngsiType := attr.Type
+		if ngsiType == "" {
+			ngsiType = "Text"
+		}
+		columns.attrs = append(columns.attrs, csvColumn{name: name, index: i, ngsiType: ngsiType})
+	}
+	sort.Slice(columns.attrs, func(i, j int) bool { return columns.attrs[i].name < columns.attrs[j].name })
+
+	return columns, nil
+}
+
+func (cols *csvColumns) entity(record []string) (map[string]interface{}, error) {
+	const funcName = "csvColumnsEntity"
+
+	cell := func(i int) string {
+		if i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	id := cell(cols.id)
+	if id == "" {
+		return nil, ngsierr.New(funcName, 1, "id is empty", nil)
+	}
+
+	entityType := cols.entityType
+	if cols.typ >= 0 {
+		if t := cell(cols.typ); t != "" {
+			entityType = t
+		}
+	}
+	if entityType == "" {
+		return nil, ngsierr.New(funcName, 2, "type is empty", nil)
+	}
+
+	entity := map[string]interface{}{"id": id, "type": entityType}
+
+	for _, attr := range cols.attrs {
+		s := cell(attr.index)
+		if s == "" {
+			continue
+		}
+		value, err := csvAttrValue(attr.ngsiType, s)
+		if err != nil {
+			return nil, ngsierr.New(funcName, 3, fmt.Sprintf("%s: %s", attr.name, err.Error()), err)
+		}
+		entity[attr.name] = map[string]interface{}{"type": attr.ngsiType, "value": value}
+	}
+
+	return entity, nil
+}
+
+func csvAttrValue(ngsiType, s string) (interface{}, error) {
+	const funcName = "csvAttrValue"
+
+	switch ngsiType {
+	case "Number":
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return nil, ngsierr.New(funcName, 1, "not a number: "+s, err)
+		}
+		return f, nil
+	case "Boolean":
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return nil, ngsierr.New(funcName, 2, "not a boolean: "+s, err)
+		}
+		return b, nil
+	case "StructuredValue", "geo:json":
+		var v interface{}
+		err := ngsilib.JSONUnmarshal([]byte(s), &v)
+		if err != nil {
+			return nil, ngsierr.New(funcName, 3, "not JSON: "+s, err)
+		}
+		return v, nil
+	}
+	return s, nil
+}
@@ -0,0 +1,390 @@
+/*
+MIT License
+
+Copyright (c) 2020-2024 Kazuhito Suda
+
+This file is part of NGSI Go
+
+https://github.com/lets-fiware/ngsi-go
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+
+*/
+
+package ngsicmd
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/lets-fiware/ngsi-go/internal/assert"
+	"github.com/lets-fiware/ngsi-go/internal/helper"
+	"github.com/lets-fiware/ngsi-go/internal/ngsierr"
+)
+
+const testCSVMapping = `{"id":"asset_id","type":"kind","entityType":"Device","attrs":{"temperature":{"column":"temp","type":"Number"},"ok":{"type":"Boolean"},"location":{"column":"loc","type":"geo:json"},"name":{}}}`
+
+func TestOpUpdateCSV(t *testing.T) {
+	data := "asset_id,kind,temp,ok,loc,name\n" +
+		"A1,Sensor,21.5,true,\"{\"\"type\"\":\"\"Point\"\",\"\"coordinates\"\":[1,2]}\",sensor 1\n" +
+		"A2,,,,,\n"
+	c := setupTest([]string{"upsert", "entities", "--host", "orion", "--format", "csv", "--mapping", testCSVMapping, "--data", data})
+
+	reqRes := helper.MockHTTPReqRes{}
+	reqRes.Res.StatusCode = http.StatusNoContent
+	reqRes.Path = "/v2/op/update"
+	reqRes.ReqData = []byte(`{"actionType":"append","entities":[{"id":"A1","location":{"type":"geo:json","value":{"coordinates":[1,2],"type":"Point"}},"name":{"type":"Text","value":"sensor 1"},"ok":{"type":"Boolean","value":true},"temperature":{"type":"Number","value":21.5},"type":"Sensor"},{"id":"A2","type":"Device"}]}`)
+
+	helper.SetClientHTTP(c, reqRes)
+
+	err := opUpdate(c, c.Ngsi, c.Client, "append")
+
+	assert.NoError(t, err)
+}
+
+func TestOpUpdateCSVTSV(t *testing.T) {
+	data := "\ufeffasset_id\ttemp\nA1\t21\n"
+	c := setupTest([]string{"upsert", "entities", "--host", "orion", "--format", "tsv", "--mapping", `{"id":"asset_id","entityType":"Device","attrs":{"temp":{"type":"Number"}}}`, "--data", data})
+
+	reqRes := helper.MockHTTPReqRes{}
+	reqRes.Res.StatusCode = http.StatusNoContent
+	reqRes.Path = "/v2/op/update"
+	reqRes.ReqData = []byte(`{"actionType":"append","entities":[{"id":"A1","temp":{"type":"Number","value":21},"type":"Device"}]}`)
+
+	helper.SetClientHTTP(c, reqRes)
+
+	err := opUpdate(c, c.Ngsi, c.Client, "append")
+
+	assert.NoError(t, err)
+}
+
+func TestOpUpdateCSVOver100(t *testing.T) {
+	data := "asset_id\n"
+	for i := 0; i < 250; i++ {
+		data += fmt.Sprintf("urn:ngsi-ld:Device:%d\n", i)
+	}
+	c := setupTest([]string{"upsert", "entities", "--host", "orion", "--parallel", "3", "--format", "csv", "--mapping", `{"id":"asset_id","entityType":"Device"}`, "--data", data})
+
+	reqRes := helper.MockHTTPReqRes{}
+	reqRes.Res.StatusCode = http.StatusNoContent
+	reqRes.Path = "/v2/op/update"
+
+	helper.SetClientHTTP(c, reqRes, reqRes, reqRes)
+
+	err := opUpdate(c, c.Ngsi, c.Client, "append")
+
+	assert.NoError(t, err)
+}
+
+func TestOpUpdateCSVDryRun(t *testing.T) {
+	c := setupTest([]string{"--dryRun", "upsert", "entities", "--host", "orion", "--format", "csv", "--mapping", `{"id":"asset_id","entityType":"Device"}`, "--data", "asset_id\nA1\n"})
+
+	err := opUpdate(c, c.Ngsi, c.Client, "append")
+
+	if assert.NoError(t, err) {
+		actual := helper.GetStdoutString(c)
+		expected := "POST https://orion/v2/op/update\n" +
+			"Accept: */*\nContent-Type: application/json\n\n" +
+			"{\"actionType\":\"append\",\"entities\":[{\"id\":\"A1\",\"type\":\"Device\"}]}\n\n"
+		assert.Equal(t, expected, actual)
+	}
+}
+
+func TestOpUpdateCSVRejectedRows(t *testing.T) {
+	data := "asset_id,kind,temp,ok,loc,name\n" +
+		",Sensor,1,,,\n" +
+		"A2,Sensor,abc,,,\n" +
+		"A3,Sensor,,yes,,\n" +
+		"A4,Sensor,,,{,\n" +
+		"A5,Sensor,,,,a\"b\n" +
+		"A6,Sensor,1,,,\n"
+	c := setupTest([]string{"upsert", "entities", "--host", "orion", "--format", "csv", "--mapping", testCSVMapping, "--data", data})
+
+	reqRes := helper.MockHTTPReqRes{}
+	reqRes.Res.StatusCode = http.StatusNoContent
+	reqRes.Path = "/v2/op/update"
+
+	helper.SetClientHTTP(c, reqRes)
+
+	err := opUpdate(c, c.Ngsi, c.Client, "append")
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 10, ngsiErr.ErrNo)
+		assert.Equal(t, "5 rows rejected", ngsiErr.Message)
+		actual := helper.GetStdoutString(c)
+		expected := "row 2: id is empty\n" +
+			"row 3: temperature: not a number: abc\n" +
+			"row 4: ok: not a boolean: yes\n" +
+			"row 5: location: not JSON: {\n" +
+			"row 6: bare \" in non-quoted-field\n"
+		assert.Equal(t, expected, actual)
+	}
+}
+
+func TestOpUpdateCSVRejectedByBroker(t *testing.T) {
+	c := setupTest([]string{"upsert", "entities", "--host", "orion", "--format", "csv", "--mapping", `{"id":"asset_id","entityType":"Device"}`, "--data", "asset_id\nA1\nA2\n"})
+
+	reqRes1 := helper.MockHTTPReqRes{}
+	reqRes1.Res.StatusCode = http.StatusBadRequest
+	reqRes1.Res.Status = "400 Bad Request"
+	reqRes1.ResBody = []byte(`{"error":"BadRequest","description":"Invalid characters in entity id"}`)
+	reqRes1.Path = "/v2/op/update"
+
+	reqRes2 := helper.MockHTTPReqRes{}
+	reqRes2.Res.StatusCode = http.StatusNoContent
+	reqRes2.Path = "/v2/op/update"
+
+	helper.SetClientHTTP(c, reqRes1, reqRes2, reqRes1)
+
+	err := opUpdate(c, c.Ngsi, c.Client, "append")
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 10, ngsiErr.ErrNo)
+		assert.Equal(t, "1 rows rejected", ngsiErr.Message)
+		actual := helper.GetStdoutString(c)
+		expected := "row 3: 400 Bad Request {\"error\":\"BadRequest\",\"description\":\"Invalid characters in entity id\"}\n"
+		assert.Equal(t, expected, actual)
+	}
+}
+
+func TestOpUpdateCSVErrorMapping(t *testing.T) {
+	c := setupTest([]string{"upsert", "entities", "--host", "orion", "--format", "csv", "--data", "asset_id\nA1\n"})
+
+	err := opUpdate(c, c.Ngsi, c.Client, "append")
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 1, ngsiErr.ErrNo)
+		assert.Equal(t, "specify --mapping with --format csv", ngsiErr.Message)
+	}
+}
+
+func TestOpUpdateCSVErrorReadMapping(t *testing.T) {
+	c := setupTest([]string{"upsert", "entities", "--host", "orion", "--format", "csv", "--mapping", "@", "--data", "asset_id\nA1\n"})
+
+	err := opUpdate(c, c.Ngsi, c.Client, "append")
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 2, ngsiErr.ErrNo)
+		assert.Equal(t, "file name error", ngsiErr.Message)
+	}
+}
+
+func TestOpUpdateCSVErrorParallel(t *testing.T) {
+	c := setupTest([]string{"upsert", "entities", "--host", "orion", "--parallel", "0", "--format", "csv", "--mapping", `{"id":"asset_id","entityType":"Device"}`, "--data", "asset_id\nA1\n"})
+
+	err := opUpdate(c, c.Ngsi, c.Client, "append")
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 3, ngsiErr.ErrNo)
+		assert.Equal(t, "parallel error: 0 (1-64)", ngsiErr.Message)
+	}
+}
+
+func TestOpUpdateCSVErrorReadData(t *testing.T) {
+	c := setupTest([]string{"upsert", "entities", "--host", "orion", "--format", "csv", "--mapping", `{"id":"asset_id","entityType":"Device"}`, "--data", "@"})
+
+	err := opUpdate(c, c.Ngsi, c.Client, "append")
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 4, ngsiErr.ErrNo)
+		assert.Equal(t, "file name error", ngsiErr.Message)
+	}
+}
+
+func TestOpUpdateCSVErrorHeader(t *testing.T) {
+	c := setupTest([]string{"upsert", "entities", "--host", "orion", "--format", "csv", "--mapping", `{"id":"asset_id","entityType":"Device"}`, "--data", "\"asset_id\n"})
+
+	err := opUpdate(c, c.Ngsi, c.Client, "append")
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 5, ngsiErr.ErrNo)
+		assert.Equal(t, "parse error on line 1, column 11: extraneous or missing \" in quoted-field", ngsiErr.Message)
+	}
+}
+
+func TestOpUpdateCSVErrorColumns(t *testing.T) {
+	c := setupTest([]string{"upsert", "entities", "--host", "orion", "--format", "csv", "--mapping", `{"id":"id","entityType":"Device"}`, "--data", "asset_id\nA1\n"})
+
+	err := opUpdate(c, c.Ngsi, c.Client, "append")
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 6, ngsiErr.ErrNo)
+		assert.Equal(t, "column not found: id", ngsiErr.Message)
+	}
+}
+
+func TestOpUpdateCSVErrorHTTP(t *testing.T) {
+	c := setupTest([]string{"upsert", "entities", "--host", "orion", "--format", "csv", "--mapping", `{"id":"asset_id","entityType":"Device"}`, "--data", "asset_id\nA1\n"})
+
+	reqRes := helper.MockHTTPReqRes{}
+	reqRes.Res.StatusCode = http.StatusNoContent
+	reqRes.Err = errors.New("error")
+	reqRes.Path = "/v2/op/update"
+
+	helper.SetClientHTTP(c, reqRes)
+
+	err := opUpdate(c, c.Ngsi, c.Client, "append")
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 8, ngsiErr.ErrNo)
+		assert.Equal(t, "error", ngsiErr.Message)
+	}
+}
+
+func TestOpUpdateCSVErrorHTTPRetry(t *testing.T) {
+	c := setupTest([]string{"upsert", "entities", "--host", "orion", "--format", "csv", "--mapping", `{"id":"asset_id","entityType":"Device"}`, "--data", "asset_id\nA1\n"})
+
+	reqRes1 := helper.MockHTTPReqRes{}
+	reqRes1.Res.StatusCode = http.StatusBadRequest
+	reqRes1.Path = "/v2/op/update"
+
+	reqRes2 := helper.MockHTTPReqRes{}
+	reqRes2.Res.StatusCode = http.StatusNoContent
+	reqRes2.Err = errors.New("error")
+	reqRes2.Path = "/v2/op/update"
+
+	helper.SetClientHTTP(c, reqRes1, reqRes2)
+
+	err := opUpdate(c, c.Ngsi, c.Client, "append")
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 9, ngsiErr.ErrNo)
+		assert.Equal(t, "error", ngsiErr.Message)
+	}
+}
+
+func TestCSVReadMappingErrorJSON(t *testing.T) {
+	c := setupTest([]string{"upsert", "entities", "--host", "orion", "--data", "{}"})
+
+	helper.SetJSONDecodeErr(c.Ngsi, 0)
+
+	_, err := csvReadMapping(c.Ngsi, `{"id":"asset_id","entityType":"Device"}`)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 2, ngsiErr.ErrNo)
+		assert.Equal(t, "json error", ngsiErr.Message)
+	}
+}
+
+func TestCSVReadMappingErrorID(t *testing.T) {
+	c := setupTest([]string{"upsert", "entities", "--host", "orion", "--data", "{}"})
+
+	_, err := csvReadMapping(c.Ngsi, `{"entityType":"Device"}`)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 3, ngsiErr.ErrNo)
+		assert.Equal(t, "mapping: id not found", ngsiErr.Message)
+	}
+}
+
+func TestCSVReadMappingErrorType(t *testing.T) {
+	c := setupTest([]string{"upsert", "entities", "--host", "orion", "--data", "{}"})
+
+	_, err := csvReadMapping(c.Ngsi, `{"id":"asset_id"}`)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 4, ngsiErr.ErrNo)
+		assert.Equal(t, "mapping: type or entityType not found", ngsiErr.Message)
+	}
+}
+
+func TestCSVMappingColumnsErrorType(t *testing.T) {
+	m := &csvMapping{ID: "asset_id", Type: "kind"}
+
+	_, err := m.columns([]string{"asset_id"})
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 2, ngsiErr.ErrNo)
+		assert.Equal(t, "column not found: kind", ngsiErr.Message)
+	}
+}
+
+func TestCSVMappingColumnsErrorAttr(t *testing.T) {
+	m := &csvMapping{ID: "asset_id", EntityType: "Device", Attrs: map[string]csvMappingAttr{"temperature": {Column: "temp"}}}
+
+	_, err := m.columns([]string{"asset_id"})
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 3, ngsiErr.ErrNo)
+		assert.Equal(t, "column not found: temp", ngsiErr.Message)
+	}
+}
+
+func TestCSVColumnsEntityErrorType(t *testing.T) {
+	cols := &csvColumns{id: 0, typ: 1}
+
+	_, err := cols.entity([]string{"A1"})
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 2, ngsiErr.ErrNo)
+		assert.Equal(t, "type is empty", ngsiErr.Message)
+	}
+}
+
+func TestCSVAttrValue(t *testing.T) {
+	setupTest([]string{"upsert", "entities", "--host", "orion", "--data", "{}"})
+
+	cases := []struct {
+		ngsiType string
+		s        string
+		expected interface{}
+	}{
+		{ngsiType: "Number", s: "1.5", expected: 1.5},
+		{ngsiType: "Boolean", s: "false", expected: false},
+		{ngsiType: "StructuredValue", s: `{"a":1}`, expected: map[string]interface{}{"a": float64(1)}},
+		{ngsiType: "DateTime", s: "2026-01-01T00:00:00Z", expected: "2026-01-01T00:00:00Z"},
+	}
+
+	for _, c := range cases {
+		actual, err := csvAttrValue(c.ngsiType, c.s)
+		if assert.NoError(t, err) {
+			assert.Equal(t, c.expected, actual)
+		}
+	}
+}
+
+func TestBatchErrorCSVLD(t *testing.T) {
+	c := setupTest([]string{"upsert", "entities", "--host", "orion-ld", "--format", "csv", "--data", "asset_id\nA1\n"})
+
+	err := batch(c, c.Ngsi, c.Client, "upsert")
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 2, ngsiErr.ErrNo)
+		assert.Equal(t, "csv format not supported for NGSI-LD", ngsiErr.Message)
+	}
+}
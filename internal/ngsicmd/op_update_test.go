@@ -80,6 +80,20 @@ func TestOpUpdateArrayDataOver100(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestOpUpdateDryRun(t *testing.T) {
+	c := setupTest([]string{"--dryRun", "create", "entities", "--host", "orion", "--data", `[{"id":"urn:ngsi-ld:Product:1","type":"Product"}]`})
+
+	err := opUpdate(c, c.Ngsi, c.Client, "append_strict")
+
+	if assert.NoError(t, err) {
+		actual := helper.GetStdoutString(c)
+		expected := "POST https://orion/v2/op/update\n" +
+			"Accept: */*\nContent-Type: application/json\n\n" +
+			"{\"actionType\":\"append_strict\",\"entities\":[{\"id\":\"urn:ngsi-ld:Product:1\",\"type\":\"Product\"}]}\n\n"
+		assert.Equal(t, expected, actual)
+	}
+}
+
 func TestOpUpdateLineData(t *testing.T) {
 	c := setupTest([]string{"create", "entities", "--host", "orion", "--data", testOpUpdateLineData})
 
@@ -139,7 +153,7 @@ func TestOpUpdateErrorJSONDelim2(t *testing.T) {
 
 	if assert.Error(t, err) {
 		ngsiErr := err.(*ngsierr.NgsiError)
-		assert.Equal(t, 4, ngsiErr.ErrNo)
+		assert.Equal(t, 5, ngsiErr.ErrNo)
 		assert.Equal(t, "invalid character '{' looking for beginning of object key string (2)", ngsiErr.Message)
 	}
 }
@@ -151,7 +165,7 @@ func TestOpUpdateErrorDecode(t *testing.T) {
 
 	if assert.Error(t, err) {
 		ngsiErr := err.(*ngsierr.NgsiError)
-		assert.Equal(t, 5, ngsiErr.ErrNo)
+		assert.Equal(t, 6, ngsiErr.ErrNo)
 		assert.Equal(t, "json: cannot unmarshal array into Go value of type map[string]interface {}", ngsiErr.Message)
 	}
 }
@@ -179,7 +193,7 @@ func TestOpUpdateArrayErrorHTTP(t *testing.T) {
 
 	if assert.Error(t, err) {
 		ngsiErr := err.(*ngsierr.NgsiError)
-		assert.Equal(t, 6, ngsiErr.ErrNo)
+		assert.Equal(t, 7, ngsiErr.ErrNo)
 		assert.Equal(t, "error", ngsiErr.Message)
 	}
 }
@@ -207,7 +221,7 @@ func TestOpUpdateArrayErrorStatusCode(t *testing.T) {
 
 	if assert.Error(t, err) {
 		ngsiErr := err.(*ngsierr.NgsiError)
-		assert.Equal(t, 7, ngsiErr.ErrNo)
+		assert.Equal(t, 8, ngsiErr.ErrNo)
 		assert.Equal(t, " ", ngsiErr.Message)
 	}
 }
@@ -226,7 +240,7 @@ func TestOpUpdateErrorHTTP(t *testing.T) {
 
 	if assert.Error(t, err) {
 		ngsiErr := err.(*ngsierr.NgsiError)
-		assert.Equal(t, 8, ngsiErr.ErrNo)
+		assert.Equal(t, 7, ngsiErr.ErrNo)
 		assert.Equal(t, "http error", ngsiErr.Message)
 	}
 }
@@ -245,7 +259,7 @@ func TestOpUpdateErrorHTTPStatus(t *testing.T) {
 
 	if assert.Error(t, err) {
 		ngsiErr := err.(*ngsierr.NgsiError)
-		assert.Equal(t, 9, ngsiErr.ErrNo)
+		assert.Equal(t, 8, ngsiErr.ErrNo)
 		assert.Equal(t, " error", ngsiErr.Message)
 	}
 }
@@ -263,7 +277,7 @@ func TestOpUpdateArrayDataError(t *testing.T) {
 
 	if assert.Error(t, err) {
 		ngsiErr := err.(*ngsierr.NgsiError)
-		assert.Equal(t, 10, ngsiErr.ErrNo)
+		assert.Equal(t, 9, ngsiErr.ErrNo)
 		assert.Equal(t, "EOF", ngsiErr.Message)
 	}
 }
@@ -315,3 +329,35 @@ var testOpUpdateArrayDataError = `[
 var testOpUpdateLineData = `{"id":"urn:ngsi-ld:Product:001","type":"Product","name":{"type":"Text","value":"Brandy"},"size":{"type":"Text","value":"M"},"price":{"type":"Integer","value":1299}}
 {"id":"urn:ngsi-ld:Product:002","type":"Product","name":{"type":"Text","value":"Port"},"size":{"type":"Text","value":"M"},"price":{"type":"Integer","value":1199}}
 {"id":"urn:ngsi-ld:Product:003","type":"Product","offerPrice":{"type":"Integer","value":59}}`
+
+func TestOpUpdateParallel(t *testing.T) {
+	testData := "["
+	for i := 0; i < 250; i++ {
+		testData = testData + fmt.Sprintf("{\"id\":\"urn:ngsi-ld:Product:%d\",\"type\":\"Product\"},", i)
+	}
+	testData = testData[:len(testData)-1] + "]"
+
+	c := setupTest([]string{"upsert", "entities", "--host", "orion", "--parallel", "3", "--data", testData})
+
+	reqRes := helper.MockHTTPReqRes{}
+	reqRes.Res.StatusCode = http.StatusNoContent
+	reqRes.Path = "/v2/op/update"
+
+	helper.SetClientHTTP(c, reqRes, reqRes, reqRes)
+
+	err := opUpdate(c, c.Ngsi, c.Client, "append")
+
+	assert.NoError(t, err)
+}
+
+func TestOpUpdateErrorParallel(t *testing.T) {
+	c := setupTest([]string{"upsert", "entities", "--host", "orion", "--parallel", "0", "--data", "{}"})
+
+	err := opUpdate(c, c.Ngsi, c.Client, "append")
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 4, ngsiErr.ErrNo)
+		assert.Equal(t, "parallel error: 0 (1-64)", ngsiErr.Message)
+	}
+}
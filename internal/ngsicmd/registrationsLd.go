@@ -127,7 +127,7 @@ func registrationsListLd(c *ngsicli.Context, ngsi *ngsilib.NGSI, client *ngsilib
 		}
 	}
 
-	if c.IsSet("json") || c.Bool("pretty") {
+	if c.IsSet("json") || c.Bool("pretty") || c.IsOutputFiltered() {
 		if len(registrations) > 0 {
 			b, err := ngsilib.JSONMarshal(registrations)
 			if err != nil {
@@ -59,6 +59,26 @@ func TestRegistrationsListV2(t *testing.T) {
 	}
 }
 
+func TestRegistrationsListV2Filter(t *testing.T) {
+	c := setupTest([]string{"--filter", ".[].description", "list", "registrations", "--host", "orion"})
+
+	reqRes := helper.MockHTTPReqRes{}
+	reqRes.Res.StatusCode = http.StatusOK
+	reqRes.ResBody = []byte(`[{"id":"5f5dcb551e715bc7f1ad79e3","description":"sensor source","endpoint":"http://raspi","information":[{"entities":[{"id":"urn:ngsi-ld:Device:device001","type":"Device"}],"properties":["temperature","pressure","humidity"]}],"type":"ContextSourceRegistration"}]`)
+	reqRes.ResHeader = http.Header{"Fiware-Total-Count": []string{"1"}}
+	reqRes.Path = "/v2/registrations"
+
+	helper.SetClientHTTP(c, reqRes)
+
+	err := registrationsListV2(c, c.Ngsi, c.Client)
+
+	if assert.NoError(t, err) {
+		actual := getFilteredStdoutString(t, c)
+		expected := "sensor source\n"
+		assert.Equal(t, expected, actual)
+	}
+}
+
 func TestRegistrationsListV2CountZero(t *testing.T) {
 	c := setupTest([]string{"list", "registrations", "--host", "orion"})
 
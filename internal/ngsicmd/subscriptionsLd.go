@@ -71,6 +71,9 @@ type notificationParamsLd struct {
 	LastNotification string `json:"lastNotification,omitempty"`
 	LastFailure      string `json:"lastFailure,omitempty"`
 	LastSuccess      string `json:"lastSuccess,omitempty"`
+
+	ConsecutiveErrors *int64 `json:"consecutiveErrors,omitempty"`
+	LastErrorReason   string `json:"lastErrorReason,omitempty"`
 }
 
 // 5.2.15 Endpoint
@@ -197,7 +200,7 @@ func subscriptionsListLd(c *ngsicli.Context, ngsi *ngsilib.NGSI, client *ngsilib
 
 	if c.IsSet("count") {
 		fmt.Fprintf(ngsi.StdWriter, "%d\n", len(subscriptions))
-	} else if c.IsSet("json") || c.Bool("pretty") {
+	} else if c.IsSet("json") || c.Bool("pretty") || c.IsOutputFiltered() {
 		if len(subscriptions) > 0 {
 			b, err := ngsilib.JSONMarshal(subscriptions)
 			if err != nil {
@@ -113,6 +113,7 @@ type subscriptionResposeV2 struct {
 	} `json:"subject"`
 	Notification struct {
 		TimesSent         *int64                    `json:"timesSent,omitempty"`
+		FailsCounter      *int64                    `json:"failsCounter,omitempty"`
 		LastNotification  string                    `json:"lastNotification,omitempty"`
 		LastSuccess       string                    `json:"lastSuccess,omitempty"`
 		LastSuccessCode   *int                      `json:"lastSuccessCode,omitempty"`
@@ -216,7 +217,7 @@ func subscriptionsListV2(c *ngsicli.Context, ngsi *ngsilib.NGSI, client *ngsilib
 
 	if c.IsSet("count") {
 		fmt.Fprintf(ngsi.StdWriter, "%d\n", len(subscriptions))
-	} else if c.IsSet("json") || c.Bool("pretty") {
+	} else if c.IsSet("json") || c.Bool("pretty") || c.IsOutputFiltered() {
 		if len(subscriptions) > 0 {
 			b, err := ngsilib.JSONMarshal(subscriptions)
 			if err != nil {
@@ -59,6 +59,26 @@ func TestSubscriptionssubscriptionsListV2(t *testing.T) {
 	}
 }
 
+func TestSubscriptionssubscriptionsListV2Template(t *testing.T) {
+	c := setupTest([]string{"--template", "{{.id}} {{.status}}", "list", "subscriptions", "--host", "orion"})
+
+	reqRes := helper.MockHTTPReqRes{}
+	reqRes.Res.StatusCode = http.StatusOK
+	reqRes.ResBody = []byte(subscriptionData)
+	reqRes.Path = "/v2/subscriptions"
+	reqRes.ResHeader = http.Header{"Fiware-Total-Count": []string{"6"}}
+
+	helper.SetClientHTTP(c, reqRes)
+
+	err := subscriptionsListV2(c, c.Ngsi, c.Client)
+
+	if assert.NoError(t, err) {
+		actual := getFilteredStdoutString(t, c)
+		expected := "3ea2e78f675f2d199d3025ff expired\n5f64060ef6752d199d302600 expired\n1f32db4bf6752d199d302601 expired\n3978fabd87752d199d302602 expired\n9f6c254ac4a6068bb276774e inactive\n4f6c2576c4a6068bb276774f active\n"
+		assert.Equal(t, expected, actual)
+	}
+}
+
 func TestSubscriptionssubscriptionsListV2Count(t *testing.T) {
 	c := setupTest([]string{"list", "subscriptions", "--host", "orion", "--count"})
 
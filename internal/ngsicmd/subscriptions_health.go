@@ -0,0 +1,489 @@
+/*
+MIT License
+
+Copyright (c) 2020-2024 Kazuhito Suda
+
+This file is part of NGSI Go
+
+https://github.com/lets-fiware/ngsi-go
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+
+*/
+
+package ngsicmd
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lets-fiware/ngsi-go/internal/ngsicli"
+	"github.com/lets-fiware/ngsi-go/internal/ngsierr"
+	"github.com/lets-fiware/ngsi-go/internal/ngsilib"
+)
+
+const healthProbeTimeout = 5 * time.Second
+
+type subscriptionHealth struct {
+	ID                string   `json:"id"`
+	Description       string   `json:"description,omitempty"`
+	Status            string   `json:"status"`
+	FailsCounter      int64    `json:"failsCounter"`
+	LastSuccessCode   *int     `json:"lastSuccessCode,omitempty"`
+	LastFailureReason string   `json:"lastFailureReason,omitempty"`
+	Expires           string   `json:"expires,omitempty"`
+	URL               string   `json:"url,omitempty"`
+	Problems          []string `json:"problems,omitempty"`
+	failing           bool
+	expired           bool
+}
+
+type subscriptionHealthGroup struct {
+	Status            string `json:"status"`
+	FailsCounter      int64  `json:"failsCounter"`
+	LastSuccessCode   *int   `json:"lastSuccessCode,omitempty"`
+	LastFailureReason string `json:"lastFailureReason,omitempty"`
+	Count             int    `json:"count"`
+}
+
+type subscriptionHealthReport struct {
+	Subscriptions int                       `json:"subscriptions"`
+	Groups        []subscriptionHealthGroup `json:"groups"`
+	Flagged       []*subscriptionHealth     `json:"flagged"`
+}
+
+func subscriptionsHealth(c *ngsicli.Context, ngsi *ngsilib.NGSI, client *ngsilib.Client) error {
+	const funcName = "subscriptionsHealth"
+
+	if c.Bool("reactivate") && c.Bool("delete") {
+		return ngsierr.New(funcName, 1, "specify either --reactivate or --delete", nil)
+	}
+
+	var window time.Time
+	if c.IsSet("expiresWithin") {
+		s, err := ngsilib.GetExpirationDate(c.String("expiresWithin"))
+		if err != nil {
+			return ngsierr.New(funcName, 2, err.Error(), err)
+		}
+		window, _ = time.Parse(time.RFC3339Nano, s)
+	}
+
+	var subs []*subscriptionHealth
+	var err error
+	if client.IsNgsiV2() {
+		subs, err = subscriptionsHealthV2(client)
+	} else {
+		subs, err = subscriptionsHealthLd(client)
+	}
+	if err != nil {
+		return ngsierr.New(funcName, 3, err.Error(), err)
+	}
+
+	now := ngsi.TimeLib.Now()
+	probe := c.String("probe")
+	probed := map[string]string{}
+
+	report := &subscriptionHealthReport{Subscriptions: len(subs), Groups: subscriptionsHealthGroups(subs), Flagged: []*subscriptionHealth{}}
+
+	for _, s := range subs {
+		if s.failing {
+			p := "failing"
+			if s.FailsCounter > 0 {
+				p = fmt.Sprintf("failing (%d)", s.FailsCounter)
+			}
+			if s.LastFailureReason != "" {
+				p += ": " + s.LastFailureReason
+			}
+			s.Problems = append(s.Problems, p)
+		}
+		if expires, err := time.Parse(time.RFC3339Nano, s.Expires); err == nil {
+			if !expires.After(now) {
+				s.expired = true
+			} else if !window.IsZero() && expires.Before(window) {
+				s.Problems = append(s.Problems, "expires soon")
+			}
+		}
+		if s.expired {
+			s.Problems = append(s.Problems, "expired")
+		}
+		if probe != "" && s.URL != "" {
+			p, ok := probed[s.URL]
+			if !ok {
+				p = subscriptionsHealthProbe(ngsi, probe, s.URL)
+				probed[s.URL] = p
+			}
+			if p != "" {
+				s.Problems = append(s.Problems, p)
+			}
+		}
+		if len(s.Problems) > 0 {
+			report.Flagged = append(report.Flagged, s)
+		}
+	}
+
+	if c.IsSet("json") || c.Bool("pretty") || c.IsOutputFiltered() {
+		b, err := ngsilib.JSONMarshal(report)
+		if err != nil {
+			return ngsierr.New(funcName, 4, err.Error(), err)
+		}
+		if c.Bool("pretty") {
+			newBuf := new(bytes.Buffer)
+			err := ngsi.JSONConverter.Indent(newBuf, b, "", "  ")
+			if err != nil {
+				return ngsierr.New(funcName, 5, err.Error(), err)
+			}
+			fmt.Fprintln(ngsi.StdWriter, newBuf.String())
+		} else {
+			fmt.Fprintln(ngsi.StdWriter, string(b))
+		}
+	} else {
+		if err := subscriptionsHealthPrint(ngsi, report); err != nil {
+			return ngsierr.New(funcName, 6, err.Error(), err)
+		}
+	}
+
+	if c.Bool("reactivate") || c.Bool("delete") {
+		if err := subscriptionsHealthFix(c, ngsi, client, report.Flagged); err != nil {
+			return ngsierr.New(funcName, 7, err.Error(), err)
+		}
+	}
+
+	return nil
+}
+
+func subscriptionsHealthV2(client *ngsilib.Client) ([]*subscriptionHealth, error) {
+	const funcName = "subscriptionsHealthV2"
+
+	page := 0
+	limit := 100
+
+	var subs []*subscriptionHealth
+
+	for {
+		client.SetPath("/subscriptions")
+
+		v := url.Values{}
+		v.Set("options", "count")
+		v.Set("limit", fmt.Sprintf("%d", limit))
+		v.Set("offset", fmt.Sprintf("%d", page*limit))
+		client.SetQuery(&v)
+
+		res, body, err := client.HTTPGet()
+		if err != nil {
+			return nil, ngsierr.New(funcName, 1, err.Error(), err)
+		}
+		if res.StatusCode != http.StatusOK {
+			return nil, ngsierr.New(funcName, 2, fmt.Sprintf("%s %s", res.Status, string(body)), nil)
+		}
+		count, err := client.ResultsCount(res)
+		if err != nil {
+			return nil, ngsierr.New(funcName, 3, "ResultsCount error", err)
+		}
+		if count == 0 {
+			break
+		}
+		var list []subscriptionResposeV2
+		if err := ngsilib.JSONUnmarshalDecode(body, &list, client.IsSafeString()); err != nil {
+			return nil, ngsierr.New(funcName, 4, err.Error(), err)
+		}
+		for _, e := range list {
+			s := &subscriptionHealth{
+				ID:                e.ID,
+				Description:       e.Description,
+				Status:            e.Status,
+				LastSuccessCode:   e.Notification.LastSuccessCode,
+				LastFailureReason: e.Notification.LastFailureReason,
+				Expires:           e.Expires,
+				expired:           e.Status == "expired",
+			}
+			if e.Notification.FailsCounter != nil {
+				s.FailsCounter = *e.Notification.FailsCounter
+			}
+			s.failing = e.Status == "failed" || s.FailsCounter > 0
+			if e.Notification.HTTP != nil {
+				s.URL = e.Notification.HTTP.URL
+			} else if e.Notification.HTTPCustom != nil {
+				s.URL = e.Notification.HTTPCustom.URL
+			}
+			subs = append(subs, s)
+		}
+
+		if (page+1)*limit < count {
+			page = page + 1
+		} else {
+			break
+		}
+	}
+
+	return subs, nil
+}
+
+func subscriptionsHealthLd(client *ngsilib.Client) ([]*subscriptionHealth, error) {
+	const funcName = "subscriptionsHealthLd"
+
+	page := 0
+	limit := 100
+
+	var subs []*subscriptionHealth
+
+	for {
+		client.SetPath("/subscriptions/")
+
+		v := url.Values{}
+		v.Set("count", "true")
+		v.Set("limit", fmt.Sprintf("%d", limit))
+		v.Set("offset", fmt.Sprintf("%d", page*limit))
+		client.SetQuery(&v)
+
+		res, body, err := client.HTTPGet()
+		if err != nil {
+			return nil, ngsierr.New(funcName, 1, err.Error(), err)
+		}
+		if res.StatusCode != http.StatusOK {
+			return nil, ngsierr.New(funcName, 2, fmt.Sprintf("%s %s", res.Status, string(body)), nil)
+		}
+		count, err := client.ResultsCount(res)
+		if err != nil {
+			return nil, ngsierr.New(funcName, 3, "ResultsCount error", err)
+		}
+		if count == 0 {
+			break
+		}
+		var list []subscriptionLd
+		if err := ngsilib.JSONUnmarshalDecode(body, &list, client.IsSafeString()); err != nil {
+			return nil, ngsierr.New(funcName, 4, err.Error(), err)
+		}
+		for _, e := range list {
+			s := &subscriptionHealth{
+				ID:          e.ID,
+				Description: e.Description,
+				Status:      e.Status,
+				Expires:     e.Expires,
+				expired:     e.Status == "expired",
+			}
+			if s.Status == "" && e.IsActive != nil {
+				s.Status = "active"
+				if !*e.IsActive {
+					s.Status = "paused"
+				}
+			}
+			if n := e.Notification; n != nil {
+				if n.ConsecutiveErrors != nil {
+					s.FailsCounter = *n.ConsecutiveErrors
+				}
+				s.LastFailureReason = n.LastErrorReason
+				s.failing = n.Status == "failed" || s.FailsCounter > 0
+				if n.Endpoint != nil {
+					s.URL = n.Endpoint.URI
+				}
+			}
+			subs = append(subs, s)
+		}
+
+		if (page+1)*limit < count {
+			page = page + 1
+		} else {
+			break
+		}
+	}
+
+	return subs, nil
+}
+
+func subscriptionsHealthGroups(subs []*subscriptionHealth) []subscriptionHealthGroup {
+	groups := []subscriptionHealthGroup{}
+	index := map[string]int{}
+
+	for _, s := range subs {
+		key := fmt.Sprintf("%s\t%d\t%s\t%s", s.Status, s.FailsCounter, healthCode(s.LastSuccessCode), s.LastFailureReason)
+		if i, ok := index[key]; ok {
+			groups[i].Count++
+			continue
+		}
+		index[key] = len(groups)
+		groups = append(groups, subscriptionHealthGroup{
+			Status:            s.Status,
+			FailsCounter:      s.FailsCounter,
+			LastSuccessCode:   s.LastSuccessCode,
+			LastFailureReason: s.LastFailureReason,
+			Count:             1,
+		})
+	}
+
+	sort.SliceStable(groups, func(i, j int) bool {
+		if groups[i].Status != groups[j].Status {
+			return groups[i].Status < groups[j].Status
+		}
+		return groups[i].FailsCounter > groups[j].FailsCounter
+	})
+
+	return groups
+}
+
+func subscriptionsHealthProbe(ngsi *ngsilib.NGSI, probe, rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return "unreachable: invalid url"
+	}
+
+	if probe == "http" && (u.Scheme == "http" || u.Scheme == "https") {
+		var res *http.Response
+		if r, ok := ngsi.HTTP.(ngsilib.HTTPTimeoutRequest); ok {
+			res, _, err = r.RequestTimeout(http.MethodHead, u, nil, nil, healthProbeTimeout)
+		} else {
+			res, _, err = ngsi.HTTP.Request(http.MethodHead, u, nil, nil)
+		}
+		if err != nil {
+			return "unreachable: " + err.Error()
+		}
+		if res.StatusCode >= http.StatusInternalServerError {
+			return "unhealthy: " + res.Status
+		}
+		return ""
+	}
+
+	port := u.Port()
+	if port == "" {
+		port = map[string]string{"https": "443", "mqtt": "1883", "mqtts": "8883"}[u.Scheme]
+		if port == "" {
+			port = "80"
+		}
+	}
+	conn, err := ngsi.NetLib.DialTimeout("tcp", net.JoinHostPort(u.Hostname(), port), healthProbeTimeout)
+	if err != nil {
+		return "unreachable: " + err.Error()
+	}
+	_ = conn.Close()
+
+	return ""
+}
+
+func subscriptionsHealthPrint(ngsi *ngsilib.NGSI, report *subscriptionHealthReport) error {
+	const funcName = "subscriptionsHealthPrint"
+
+	w, _ := ngsilib.NewTableWriter(ngsi.StdWriter, "table")
+
+	rows := [][]string{{"STATUS", "FAILS", "LAST SUCCESS CODE", "LAST FAILURE REASON", "COUNT"}}
+	for _, g := range report.Groups {
+		rows = append(rows, []string{g.Status, strconv.FormatInt(g.FailsCounter, 10), healthCode(g.LastSuccessCode), healthString(g.LastFailureReason), strconv.Itoa(g.Count)})
+	}
+	if len(report.Flagged) > 0 {
+		rows = append(rows, []string{}, []string{"ID", "STATUS", "EXPIRES", "URL", "PROBLEMS"})
+		for _, s := range report.Flagged {
+			rows = append(rows, []string{s.ID, s.Status, healthString(s.Expires), healthString(s.URL), strings.Join(s.Problems, ", ")})
+		}
+	}
+	for _, row := range rows {
+		if err := w.Write(row); err != nil {
+			return ngsierr.New(funcName, 1, err.Error(), err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return ngsierr.New(funcName, 2, err.Error(), err)
+	}
+
+	fmt.Fprintf(ngsi.StdWriter, "%d subscriptions, %d flagged\n", report.Subscriptions, len(report.Flagged))
+
+	return nil
+}
+
+func subscriptionsHealthFix(c *ngsicli.Context, ngsi *ngsilib.NGSI, client *ngsilib.Client, flagged []*subscriptionHealth) error {
+	const funcName = "subscriptionsHealthFix"
+
+	action := "deleted"
+	if c.Bool("reactivate") {
+		action = "reactivated"
+	}
+
+	// Only failing or expired subscriptions are fixed. Those flagged only because they expire soon or
+	// their url doesn't respond are left as they are. An expired subscription stays expired until
+	// its expiration date is updated, so it is not reactivated.
+	var targets []*subscriptionHealth
+	for _, s := range flagged {
+		if action == "reactivated" && (!s.failing || s.expired) {
+			continue
+		}
+		if action == "deleted" && !s.failing && !s.expired {
+			continue
+		}
+		targets = append(targets, s)
+	}
+
+	if !c.Bool("run") {
+		fmt.Fprintf(ngsi.StdWriter, "%d subscriptions will be %s. run health with --run option\n", len(targets), action)
+		return nil
+	}
+
+	client.SetQuery(&url.Values{})
+	client.SetContentJSON()
+
+	payload := []byte(`{"isActive":true}`)
+	if client.IsNgsiV2() {
+		payload = []byte(`{"status":"active"}`)
+	}
+
+	for _, s := range targets {
+		client.SetPath("/subscriptions/" + s.ID)
+
+		var res *http.Response
+		var body []byte
+		var err error
+		if action == "reactivated" {
+			res, body, err = client.HTTPPatch(payload)
+		} else {
+			res, body, err = client.HTTPDelete(nil)
+		}
+		if ngsilib.IsDryRun(err) {
+			continue
+		}
+		if err != nil {
+			return ngsierr.New(funcName, 1, err.Error(), err)
+		}
+		if res.StatusCode != http.StatusNoContent {
+			return ngsierr.New(funcName, 2, fmt.Sprintf("%s %s %s", res.Status, string(body), s.ID), nil)
+		}
+
+		ngsi.Logging(ngsilib.LogInfo, fmt.Sprintf("%s is %s, FIWARE-Service: %s", s.ID, action, c.String("service")))
+	}
+
+	fmt.Fprintf(ngsi.StdWriter, "%d subscriptions %s\n", len(targets), action)
+
+	return nil
+}
+
+func healthCode(code *int) string {
+	if code == nil {
+		return "-"
+	}
+	return strconv.Itoa(*code)
+}
+
+func healthString(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
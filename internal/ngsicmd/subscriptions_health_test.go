@@ -0,0 +1,817 @@
+/*
+MIT License
+
+Copyright (c) 2020-2024 Kazuhito Suda
+
+This file is part of NGSI Go
+
+https://github.com/lets-fiware/ngsi-go
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+
+*/
+
+package ngsicmd
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lets-fiware/ngsi-go/internal/assert"
+	"github.com/lets-fiware/ngsi-go/internal/helper"
+	"github.com/lets-fiware/ngsi-go/internal/ngsierr"
+)
+
+var subscriptionsHealthV2Data = `[
+{"id":"s1","status":"active","expires":"2027-01-01T00:00:00.000Z","notification":{"lastSuccessCode":200,"http":{"url":"http://a:1028/n"}},"subject":{"entities":[]}},
+{"id":"s2","status":"active","notification":{"lastSuccessCode":200,"http":{"url":"http://a:1028/n"}},"subject":{"entities":[]}},
+{"id":"s3","status":"failed","notification":{"failsCounter":3,"lastFailureReason":"Couldn't connect to server","httpCustom":{"url":"http://b/n"}},"subject":{"entities":[]}},
+{"id":"s4","status":"expired","expires":"2026-09-01T00:00:00.000Z","notification":{"http":{"url":"https://c/n"}},"subject":{"entities":[]}},
+{"id":"s5","status":"active","expires":"2026-10-01T12:00:00.000Z","notification":{"lastSuccessCode":200,"http":{"url":"mqtt://d"}},"subject":{"entities":[]}}
+]`
+
+var subscriptionsHealthLdData = `[
+{"id":"urn:1","type":"Subscription","isActive":true,"notification":{"status":"ok","endpoint":{"uri":"http://a:1028/n"}}},
+{"id":"urn:2","type":"Subscription","isActive":false,"expires":"2026-09-01T00:00:00Z","notification":{"status":"failed","consecutiveErrors":2,"lastErrorReason":"timeout","endpoint":{"uri":"http://b/n"}}}
+]`
+
+func TestSubscriptionsHealthV2(t *testing.T) {
+	c := setupTest([]string{"subscriptions", "health", "--host", "orion", "--expiresWithin", "1day"})
+	c.Ngsi.TimeLib = &helper.MockTimeLib{DateTime: "2026-10-01T10:00:00.000Z"}
+
+	reqRes := helper.MockHTTPReqRes{}
+	reqRes.Res.StatusCode = http.StatusOK
+	reqRes.ResBody = []byte(subscriptionsHealthV2Data)
+	reqRes.Path = "/v2/subscriptions"
+	reqRes.ResHeader = http.Header{"Fiware-Total-Count": []string{"5"}}
+
+	helper.SetClientHTTP(c, reqRes)
+
+	err := subscriptionsHealth(c, c.Ngsi, c.Client)
+
+	if assert.NoError(t, err) {
+		actual := helper.GetStdoutString(c)
+		expected := "" +
+			"STATUS   FAILS  LAST SUCCESS CODE  LAST FAILURE REASON         COUNT\n" +
+			"active   0      200                -                           3\n" +
+			"expired  0      -                  -                           1\n" +
+			"failed   3      -                  Couldn't connect to server  1\n" +
+			"\n" +
+			"ID  STATUS   EXPIRES                   URL          PROBLEMS\n" +
+			"s3  failed   -                         http://b/n   failing (3): Couldn't connect to server\n" +
+			"s4  expired  2026-09-01T00:00:00.000Z  https://c/n  expired\n" +
+			"s5  active   2026-10-01T12:00:00.000Z  mqtt://d     expires soon\n" +
+			"5 subscriptions, 3 flagged\n"
+		assert.Equal(t, expected, actual)
+	}
+}
+
+func TestSubscriptionsHealthV2JSON(t *testing.T) {
+	c := setupTest([]string{"subscriptions", "health", "--host", "orion", "--json"})
+	c.Ngsi.TimeLib = &helper.MockTimeLib{DateTime: "2026-10-01T10:00:00.000Z"}
+
+	reqRes := helper.MockHTTPReqRes{}
+	reqRes.Res.StatusCode = http.StatusOK
+	reqRes.ResBody = []byte(`[{"id":"s3","status":"failed","notification":{"failsCounter":3,"http":{"url":"http://b/n"}},"subject":{"entities":[]}}]`)
+	reqRes.Path = "/v2/subscriptions"
+	reqRes.ResHeader = http.Header{"Fiware-Total-Count": []string{"1"}}
+
+	helper.SetClientHTTP(c, reqRes)
+
+	err := subscriptionsHealth(c, c.Ngsi, c.Client)
+
+	if assert.NoError(t, err) {
+		actual := helper.GetStdoutString(c)
+		expected := `{"subscriptions":1,"groups":[{"status":"failed","failsCounter":3,"count":1}],"flagged":[{"id":"s3","status":"failed","failsCounter":3,"url":"http://b/n","problems":["failing (3)"]}]}` + "\n"
+		assert.Equal(t, expected, actual)
+	}
+}
+
+func TestSubscriptionsHealthV2Pretty(t *testing.T) {
+	c := setupTest([]string{"subscriptions", "health", "--host", "orion", "--pretty"})
+	c.Ngsi.TimeLib = &helper.MockTimeLib{DateTime: "2026-10-01T10:00:00.000Z"}
+
+	reqRes := helper.MockHTTPReqRes{}
+	reqRes.Res.StatusCode = http.StatusOK
+	reqRes.ResBody = []byte(`[]`)
+	reqRes.Path = "/v2/subscriptions"
+	reqRes.ResHeader = http.Header{"Fiware-Total-Count": []string{"0"}}
+
+	helper.SetClientHTTP(c, reqRes)
+
+	err := subscriptionsHealth(c, c.Ngsi, c.Client)
+
+	if assert.NoError(t, err) {
+		actual := helper.GetStdoutString(c)
+		expected := "{\n  \"subscriptions\": 0,\n  \"groups\": [],\n  \"flagged\": []\n}\n"
+		assert.Equal(t, expected, actual)
+	}
+}
+
+func TestSubscriptionsHealthV2Page(t *testing.T) {
+	c := setupTest([]string{"subscriptions", "health", "--host", "orion"})
+	c.Ngsi.TimeLib = &helper.MockTimeLib{DateTime: "2026-10-01T10:00:00.000Z"}
+
+	reqRes1 := helper.MockHTTPReqRes{}
+	reqRes1.Res.StatusCode = http.StatusOK
+	reqRes1.ResBody = []byte(`[{"id":"s1","status":"active","notification":{},"subject":{"entities":[]}}]`)
+	reqRes1.Path = "/v2/subscriptions"
+	reqRes1.ResHeader = http.Header{"Fiware-Total-Count": []string{"101"}}
+	reqRes2 := helper.MockHTTPReqRes{}
+	reqRes2.Res.StatusCode = http.StatusOK
+	reqRes2.ResBody = []byte(`[{"id":"s2","status":"active","notification":{},"subject":{"entities":[]}}]`)
+	reqRes2.Path = "/v2/subscriptions"
+	reqRes2.RawQuery = helper.StrPtr("limit=100&offset=100&options=count")
+	reqRes2.ResHeader = http.Header{"Fiware-Total-Count": []string{"101"}}
+
+	helper.SetClientHTTP(c, reqRes1, reqRes2)
+
+	err := subscriptionsHealth(c, c.Ngsi, c.Client)
+
+	if assert.NoError(t, err) {
+		actual := helper.GetStdoutString(c)
+		expected := "STATUS  FAILS  LAST SUCCESS CODE  LAST FAILURE REASON  COUNT\nactive  0      -                  -                    2\n2 subscriptions, 0 flagged\n"
+		assert.Equal(t, expected, actual)
+	}
+}
+
+func TestSubscriptionsHealthLd(t *testing.T) {
+	c := setupTest([]string{"subscriptions", "health", "--host", "orion-ld"})
+	c.Ngsi.TimeLib = &helper.MockTimeLib{DateTime: "2026-10-01T10:00:00.000Z"}
+
+	reqRes := helper.MockHTTPReqRes{}
+	reqRes.Res.StatusCode = http.StatusOK
+	reqRes.ResBody = []byte(subscriptionsHealthLdData)
+	reqRes.Path = "/ngsi-ld/v1/subscriptions/"
+	reqRes.ResHeader = http.Header{"Ngsild-Results-Count": []string{"2"}}
+
+	helper.SetClientHTTP(c, reqRes)
+
+	err := subscriptionsHealth(c, c.Ngsi, c.Client)
+
+	if assert.NoError(t, err) {
+		actual := helper.GetStdoutString(c)
+		expected := "" +
+			"STATUS  FAILS  LAST SUCCESS CODE  LAST FAILURE REASON  COUNT\n" +
+			"active  0      -                  -                    1\n" +
+			"paused  2      -                  timeout              1\n" +
+			"\n" +
+			"ID     STATUS  EXPIRES               URL         PROBLEMS\n" +
+			"urn:2  paused  2026-09-01T00:00:00Z  http://b/n  failing (2): timeout, expired\n" +
+			"2 subscriptions, 1 flagged\n"
+		assert.Equal(t, expected, actual)
+	}
+}
+
+func TestSubscriptionsHealthLdPage(t *testing.T) {
+	c := setupTest([]string{"subscriptions", "health", "--host", "orion-ld", "--json"})
+	c.Ngsi.TimeLib = &helper.MockTimeLib{DateTime: "2026-10-01T10:00:00.000Z"}
+
+	reqRes1 := helper.MockHTTPReqRes{}
+	reqRes1.Res.StatusCode = http.StatusOK
+	reqRes1.ResBody = []byte(`[{"id":"urn:1","status":"active"}]`)
+	reqRes1.Path = "/ngsi-ld/v1/subscriptions/"
+	reqRes1.ResHeader = http.Header{"Ngsild-Results-Count": []string{"101"}}
+	reqRes2 := helper.MockHTTPReqRes{}
+	reqRes2.Res.StatusCode = http.StatusOK
+	reqRes2.ResBody = []byte(`[{"id":"urn:2","status":"active"}]`)
+	reqRes2.Path = "/ngsi-ld/v1/subscriptions/"
+	reqRes2.RawQuery = helper.StrPtr("count=true&limit=100&offset=100")
+	reqRes2.ResHeader = http.Header{"Ngsild-Results-Count": []string{"101"}}
+
+	helper.SetClientHTTP(c, reqRes1, reqRes2)
+
+	err := subscriptionsHealth(c, c.Ngsi, c.Client)
+
+	if assert.NoError(t, err) {
+		actual := helper.GetStdoutString(c)
+		expected := `{"subscriptions":2,"groups":[{"status":"active","failsCounter":0,"count":2}],"flagged":[]}` + "\n"
+		assert.Equal(t, expected, actual)
+	}
+}
+
+func TestSubscriptionsHealthProbeTCP(t *testing.T) {
+	c := setupTest([]string{"subscriptions", "health", "--host", "orion", "--probe", "tcp"})
+	c.Ngsi.TimeLib = &helper.MockTimeLib{DateTime: "2026-10-01T10:00:00.000Z"}
+	netLib := &helper.MockNetLib{DialErr: errors.New("connection refused")}
+	c.Ngsi.NetLib = netLib
+
+	reqRes := helper.MockHTTPReqRes{}
+	reqRes.Res.StatusCode = http.StatusOK
+	reqRes.ResBody = []byte(subscriptionsHealthV2Data)
+	reqRes.Path = "/v2/subscriptions"
+	reqRes.ResHeader = http.Header{"Fiware-Total-Count": []string{"5"}}
+
+	helper.SetClientHTTP(c, reqRes)
+
+	err := subscriptionsHealth(c, c.Ngsi, c.Client)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, []string{"a:1028", "b:80", "c:443", "d:1883"}, netLib.DialAddr)
+		actual := helper.GetStdoutString(c)
+		expected := "5 subscriptions, 5 flagged\n"
+		assert.Equal(t, expected, actual[len(actual)-len(expected):])
+	}
+}
+
+func TestSubscriptionsHealthProbeHTTP(t *testing.T) {
+	c := setupTest([]string{"subscriptions", "health", "--host", "orion", "--probe", "http"})
+	c.Ngsi.TimeLib = &helper.MockTimeLib{DateTime: "2026-10-01T10:00:00.000Z"}
+
+	reqRes := helper.MockHTTPReqRes{}
+	reqRes.Res.StatusCode = http.StatusOK
+	reqRes.ResBody = []byte(`[
+{"id":"s1","status":"active","notification":{"http":{"url":"http://a/n"}},"subject":{"entities":[]}},
+{"id":"s2","status":"active","notification":{"http":{"url":"http://b/n"}},"subject":{"entities":[]}},
+{"id":"s3","status":"active","notification":{"http":{"url":"http://c/n"}},"subject":{"entities":[]}},
+{"id":"s4","status":"active","notification":{"http":{"url":"mqtt://d"}},"subject":{"entities":[]}}
+]`)
+	reqRes.Path = "/v2/subscriptions"
+	reqRes.ResHeader = http.Header{"Fiware-Total-Count": []string{"4"}}
+
+	helper.SetClientHTTP(c, reqRes)
+
+	probe1 := helper.MockHTTPReqRes{}
+	probe1.Res.StatusCode = http.StatusMethodNotAllowed
+	probe1.Path = "/n"
+	probe2 := helper.MockHTTPReqRes{}
+	probe2.Res.StatusCode = http.StatusServiceUnavailable
+	probe2.Res.Status = "503 Service Unavailable"
+	probe2.Path = "/n"
+	probe3 := helper.MockHTTPReqRes{}
+	probe3.Path = "/n"
+	probe3.Err = errors.New("connection refused")
+	helper.AddReqRes(c.Ngsi, probe1)
+	helper.AddReqRes(c.Ngsi, probe2)
+	helper.AddReqRes(c.Ngsi, probe3)
+
+	err := subscriptionsHealth(c, c.Ngsi, c.Client)
+
+	if assert.NoError(t, err) {
+		actual := helper.GetStdoutString(c)
+		expected := "" +
+			"STATUS  FAILS  LAST SUCCESS CODE  LAST FAILURE REASON  COUNT\n" +
+			"active  0      -                  -                    4\n" +
+			"\n" +
+			"ID  STATUS  EXPIRES  URL         PROBLEMS\n" +
+			"s2  active  -        http://b/n  unhealthy: 503 Service Unavailable\n" +
+			"s3  active  -        http://c/n  unreachable: connection refused\n" +
+			"4 subscriptions, 2 flagged\n"
+		assert.Equal(t, expected, actual)
+	}
+}
+
+func TestSubscriptionsHealthProbeHTTPTimeout(t *testing.T) {
+	c := setupTest([]string{"subscriptions", "health", "--host", "orion", "--probe", "http"})
+	c.Ngsi.TimeLib = &helper.MockTimeLib{DateTime: "2026-10-01T10:00:00.000Z"}
+
+	reqRes := helper.MockHTTPReqRes{}
+	reqRes.Res.StatusCode = http.StatusOK
+	reqRes.ResBody = []byte(`[{"id":"s1","status":"active","notification":{"http":{"url":"http://a/n"}},"subject":{"entities":[]}}]`)
+	reqRes.Path = "/v2/subscriptions"
+	reqRes.ResHeader = http.Header{"Fiware-Total-Count": []string{"1"}}
+
+	helper.SetClientHTTP(c, reqRes)
+
+	probe := helper.MockHTTPReqRes{}
+	probe.Path = "/n"
+	probe.Err = errors.New("context deadline exceeded")
+	helper.AddReqRes(c.Ngsi, probe)
+	mock := &healthTimeoutHTTP{MockHTTP: c.Ngsi.HTTP.(*helper.MockHTTP)}
+	c.Ngsi.HTTP = mock
+
+	err := subscriptionsHealth(c, c.Ngsi, c.Client)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, healthProbeTimeout, mock.timeout)
+		assert.Equal(t, true, strings.Contains(helper.GetStdoutString(c), "unreachable: context deadline exceeded"))
+	}
+}
+
+// healthTimeoutHTTP records the timeout of the request sent by the url probe.
+type healthTimeoutHTTP struct {
+	*helper.MockHTTP
+	timeout time.Duration
+}
+
+func (h *healthTimeoutHTTP) RequestTimeout(method string, url *url.URL, headers map[string]string, body interface{}, timeout time.Duration) (*http.Response, []byte, error) {
+	h.timeout = timeout
+	return h.MockHTTP.Request(method, url, headers, body)
+}
+
+func TestSubscriptionsHealthProbeInvalidURL(t *testing.T) {
+	c := setupTest([]string{"subscriptions", "health", "--host", "orion", "--probe", "tcp", "--json"})
+	c.Ngsi.TimeLib = &helper.MockTimeLib{DateTime: "2026-10-01T10:00:00.000Z"}
+
+	reqRes := helper.MockHTTPReqRes{}
+	reqRes.Res.StatusCode = http.StatusOK
+	reqRes.ResBody = []byte(`[{"id":"s1","status":"active","notification":{"http":{"url":"/n"}},"subject":{"entities":[]}}]`)
+	reqRes.Path = "/v2/subscriptions"
+	reqRes.ResHeader = http.Header{"Fiware-Total-Count": []string{"1"}}
+
+	helper.SetClientHTTP(c, reqRes)
+
+	err := subscriptionsHealth(c, c.Ngsi, c.Client)
+
+	if assert.NoError(t, err) {
+		actual := helper.GetStdoutString(c)
+		expected := `{"subscriptions":1,"groups":[{"status":"active","failsCounter":0,"count":1}],"flagged":[{"id":"s1","status":"active","failsCounter":0,"url":"/n","problems":["unreachable: invalid url"]}]}` + "\n"
+		assert.Equal(t, expected, actual)
+	}
+}
+
+func TestSubscriptionsHealthReactivateDryRun(t *testing.T) {
+	c := setupTest([]string{"subscriptions", "health", "--host", "orion", "--reactivate"})
+	c.Ngsi.TimeLib = &helper.MockTimeLib{DateTime: "2026-10-01T10:00:00.000Z"}
+
+	reqRes := helper.MockHTTPReqRes{}
+	reqRes.Res.StatusCode = http.StatusOK
+	reqRes.ResBody = []byte(subscriptionsHealthV2Data)
+	reqRes.Path = "/v2/subscriptions"
+	reqRes.ResHeader = http.Header{"Fiware-Total-Count": []string{"5"}}
+
+	helper.SetClientHTTP(c, reqRes)
+
+	err := subscriptionsHealth(c, c.Ngsi, c.Client)
+
+	if assert.NoError(t, err) {
+		actual := helper.GetStdoutString(c)
+		expected := "5 subscriptions, 2 flagged\n1 subscriptions will be reactivated. run health with --run option\n"
+		assert.Equal(t, expected, actual[len(actual)-len(expected):])
+	}
+}
+
+func TestSubscriptionsHealthReactivateV2(t *testing.T) {
+	c := setupTest([]string{"subscriptions", "health", "--host", "orion", "--reactivate", "--run"})
+	c.Ngsi.TimeLib = &helper.MockTimeLib{DateTime: "2026-10-01T10:00:00.000Z"}
+
+	reqRes1 := helper.MockHTTPReqRes{}
+	reqRes1.Res.StatusCode = http.StatusOK
+	reqRes1.ResBody = []byte(subscriptionsHealthV2Data)
+	reqRes1.Path = "/v2/subscriptions"
+	reqRes1.ResHeader = http.Header{"Fiware-Total-Count": []string{"5"}}
+	reqRes2 := helper.MockHTTPReqRes{}
+	reqRes2.Res.StatusCode = http.StatusNoContent
+	reqRes2.Path = "/v2/subscriptions/s3"
+	reqRes2.ReqData = []byte(`{"status":"active"}`)
+
+	helper.SetClientHTTP(c, reqRes1, reqRes2)
+
+	err := subscriptionsHealth(c, c.Ngsi, c.Client)
+
+	if assert.NoError(t, err) {
+		actual := helper.GetStdoutString(c)
+		expected := "5 subscriptions, 2 flagged\n1 subscriptions reactivated\n"
+		assert.Equal(t, expected, actual[len(actual)-len(expected):])
+	}
+}
+
+func TestSubscriptionsHealthReactivateLd(t *testing.T) {
+	c := setupTest([]string{"subscriptions", "health", "--host", "orion-ld", "--reactivate", "--run"})
+	c.Ngsi.TimeLib = &helper.MockTimeLib{DateTime: "2026-10-01T10:00:00.000Z"}
+
+	reqRes1 := helper.MockHTTPReqRes{}
+	reqRes1.Res.StatusCode = http.StatusOK
+	reqRes1.ResBody = []byte(`[{"id":"urn:2","isActive":false,"notification":{"status":"failed","endpoint":{"uri":"http://b/n"}}}]`)
+	reqRes1.Path = "/ngsi-ld/v1/subscriptions/"
+	reqRes1.ResHeader = http.Header{"Ngsild-Results-Count": []string{"1"}}
+	reqRes2 := helper.MockHTTPReqRes{}
+	reqRes2.Res.StatusCode = http.StatusNoContent
+	reqRes2.Path = "/ngsi-ld/v1/subscriptions/urn:2"
+	reqRes2.ReqData = []byte(`{"isActive":true}`)
+
+	helper.SetClientHTTP(c, reqRes1, reqRes2)
+
+	err := subscriptionsHealth(c, c.Ngsi, c.Client)
+
+	if assert.NoError(t, err) {
+		actual := helper.GetStdoutString(c)
+		expected := "1 subscriptions, 1 flagged\n1 subscriptions reactivated\n"
+		assert.Equal(t, expected, actual[len(actual)-len(expected):])
+	}
+}
+
+func TestSubscriptionsHealthDelete(t *testing.T) {
+	c := setupTest([]string{"subscriptions", "health", "--host", "orion", "--delete", "--run"})
+	c.Ngsi.TimeLib = &helper.MockTimeLib{DateTime: "2026-10-01T10:00:00.000Z"}
+
+	reqRes1 := helper.MockHTTPReqRes{}
+	reqRes1.Res.StatusCode = http.StatusOK
+	reqRes1.ResBody = []byte(subscriptionsHealthV2Data)
+	reqRes1.Path = "/v2/subscriptions"
+	reqRes1.ResHeader = http.Header{"Fiware-Total-Count": []string{"5"}}
+	reqRes2 := helper.MockHTTPReqRes{}
+	reqRes2.Res.StatusCode = http.StatusNoContent
+	reqRes2.Path = "/v2/subscriptions/s3"
+	reqRes3 := helper.MockHTTPReqRes{}
+	reqRes3.Res.StatusCode = http.StatusNoContent
+	reqRes3.Path = "/v2/subscriptions/s4"
+
+	helper.SetClientHTTP(c, reqRes1, reqRes2, reqRes3)
+
+	err := subscriptionsHealth(c, c.Ngsi, c.Client)
+
+	if assert.NoError(t, err) {
+		actual := helper.GetStdoutString(c)
+		expected := "5 subscriptions, 2 flagged\n2 subscriptions deleted\n"
+		assert.Equal(t, expected, actual[len(actual)-len(expected):])
+	}
+}
+
+func TestSubscriptionsHealthDeleteDryRun(t *testing.T) {
+	c := setupTest([]string{"--dryRun", "subscriptions", "health", "--host", "orion", "--delete", "--run"})
+	c.Ngsi.TimeLib = &helper.MockTimeLib{DateTime: "2026-10-01T10:00:00.000Z"}
+
+	reqRes1 := helper.MockHTTPReqRes{}
+	reqRes1.Res.StatusCode = http.StatusOK
+	reqRes1.ResBody = []byte(subscriptionsHealthV2Data)
+	reqRes1.Path = "/v2/subscriptions"
+	reqRes1.ResHeader = http.Header{"Fiware-Total-Count": []string{"5"}}
+
+	helper.SetClientHTTP(c, reqRes1)
+
+	err := subscriptionsHealth(c, c.Ngsi, c.Client)
+
+	if assert.NoError(t, err) {
+		actual := helper.GetStdoutString(c)
+		assert.Equal(t, true, strings.Contains(actual, "DELETE https://orion/v2/subscriptions/s3\n"))
+		assert.Equal(t, true, strings.Contains(actual, "DELETE https://orion/v2/subscriptions/s4\n"))
+		expected := "2 subscriptions deleted\n"
+		assert.Equal(t, expected, actual[len(actual)-len(expected):])
+	}
+}
+
+func TestSubscriptionsHealthDeleteFailingExpiredOnly(t *testing.T) {
+	c := setupTest([]string{"subscriptions", "health", "--host", "orion", "--expiresWithin", "1day", "--delete", "--run"})
+	c.Ngsi.TimeLib = &helper.MockTimeLib{DateTime: "2026-10-01T10:00:00.000Z"}
+
+	reqRes1 := helper.MockHTTPReqRes{}
+	reqRes1.Res.StatusCode = http.StatusOK
+	reqRes1.ResBody = []byte(subscriptionsHealthV2Data)
+	reqRes1.Path = "/v2/subscriptions"
+	reqRes1.ResHeader = http.Header{"Fiware-Total-Count": []string{"5"}}
+	reqRes2 := helper.MockHTTPReqRes{}
+	reqRes2.Res.StatusCode = http.StatusNoContent
+	reqRes2.Path = "/v2/subscriptions/s3"
+	reqRes3 := helper.MockHTTPReqRes{}
+	reqRes3.Res.StatusCode = http.StatusNoContent
+	reqRes3.Path = "/v2/subscriptions/s4"
+
+	helper.SetClientHTTP(c, reqRes1, reqRes2, reqRes3)
+
+	err := subscriptionsHealth(c, c.Ngsi, c.Client)
+
+	if assert.NoError(t, err) {
+		actual := helper.GetStdoutString(c)
+		expected := "5 subscriptions, 3 flagged\n2 subscriptions deleted\n"
+		assert.Equal(t, expected, actual[len(actual)-len(expected):])
+	}
+}
+
+func TestSubscriptionsHealthFixSkipUnreachable(t *testing.T) {
+	c := setupTest([]string{"subscriptions", "health", "--host", "orion", "--reactivate"})
+
+	flagged := []*subscriptionHealth{
+		{ID: "s1", Problems: []string{"unreachable: invalid url"}},
+		{ID: "s2", Problems: []string{"expires soon"}},
+		{ID: "s3", failing: true, expired: true},
+	}
+
+	err := subscriptionsHealthFix(c, c.Ngsi, c.Client, flagged)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, "0 subscriptions will be reactivated. run health with --run option\n", helper.GetStdoutString(c))
+	}
+}
+
+func TestSubscriptionsHealthErrorBoth(t *testing.T) {
+	c := setupTest([]string{"subscriptions", "health", "--host", "orion", "--reactivate", "--delete"})
+
+	err := subscriptionsHealth(c, c.Ngsi, c.Client)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 1, ngsiErr.ErrNo)
+		assert.Equal(t, "specify either --reactivate or --delete", ngsiErr.Message)
+	}
+}
+
+func TestSubscriptionsHealthErrorExpiresWithin(t *testing.T) {
+	c := setupTest([]string{"subscriptions", "health", "--host", "orion", "--expiresWithin", "soon"})
+
+	err := subscriptionsHealth(c, c.Ngsi, c.Client)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 2, ngsiErr.ErrNo)
+		assert.Equal(t, "error soon", ngsiErr.Message)
+	}
+}
+
+func TestSubscriptionsHealthErrorV2(t *testing.T) {
+	c := setupTest([]string{"subscriptions", "health", "--host", "orion"})
+
+	reqRes := helper.MockHTTPReqRes{}
+	reqRes.Path = "/v2/subscriptions"
+	reqRes.Err = errors.New("http error")
+
+	helper.SetClientHTTP(c, reqRes)
+
+	err := subscriptionsHealth(c, c.Ngsi, c.Client)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 3, ngsiErr.ErrNo)
+		assert.Equal(t, "http error", ngsiErr.Message)
+	}
+}
+
+func TestSubscriptionsHealthErrorJSON(t *testing.T) {
+	c := setupTest([]string{"subscriptions", "health", "--host", "orion", "--json"})
+
+	reqRes := helper.MockHTTPReqRes{}
+	reqRes.Res.StatusCode = http.StatusOK
+	reqRes.ResBody = []byte(`[]`)
+	reqRes.Path = "/v2/subscriptions"
+	reqRes.ResHeader = http.Header{"Fiware-Total-Count": []string{"0"}}
+
+	helper.SetClientHTTP(c, reqRes)
+	helper.SetJSONEncodeErr(c.Ngsi, 0)
+
+	err := subscriptionsHealth(c, c.Ngsi, c.Client)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 4, ngsiErr.ErrNo)
+		assert.Equal(t, "json error", ngsiErr.Message)
+	}
+}
+
+func TestSubscriptionsHealthErrorPretty(t *testing.T) {
+	c := setupTest([]string{"subscriptions", "health", "--host", "orion", "--pretty"})
+
+	reqRes := helper.MockHTTPReqRes{}
+	reqRes.Res.StatusCode = http.StatusOK
+	reqRes.ResBody = []byte(`[]`)
+	reqRes.Path = "/v2/subscriptions"
+	reqRes.ResHeader = http.Header{"Fiware-Total-Count": []string{"0"}}
+
+	helper.SetClientHTTP(c, reqRes)
+	helper.SetJSONIndentError(c.Ngsi)
+
+	err := subscriptionsHealth(c, c.Ngsi, c.Client)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 5, ngsiErr.ErrNo)
+	}
+}
+
+func TestSubscriptionsHealthErrorFix(t *testing.T) {
+	c := setupTest([]string{"subscriptions", "health", "--host", "orion", "--delete", "--run"})
+	c.Ngsi.TimeLib = &helper.MockTimeLib{DateTime: "2026-10-01T10:00:00.000Z"}
+
+	reqRes1 := helper.MockHTTPReqRes{}
+	reqRes1.Res.StatusCode = http.StatusOK
+	reqRes1.ResBody = []byte(subscriptionsHealthV2Data)
+	reqRes1.Path = "/v2/subscriptions"
+	reqRes1.ResHeader = http.Header{"Fiware-Total-Count": []string{"5"}}
+	reqRes2 := helper.MockHTTPReqRes{}
+	reqRes2.Path = "/v2/subscriptions/s3"
+	reqRes2.Err = errors.New("http error")
+
+	helper.SetClientHTTP(c, reqRes1, reqRes2)
+
+	err := subscriptionsHealth(c, c.Ngsi, c.Client)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 7, ngsiErr.ErrNo)
+		assert.Equal(t, "http error", ngsiErr.Message)
+	}
+}
+
+func TestSubscriptionsHealthV2ErrorStatus(t *testing.T) {
+	c := setupTest([]string{"subscriptions", "health", "--host", "orion"})
+
+	reqRes := helper.MockHTTPReqRes{}
+	reqRes.Res.StatusCode = http.StatusBadRequest
+	reqRes.Res.Status = "400 Bad Request"
+	reqRes.ResBody = []byte("error")
+	reqRes.Path = "/v2/subscriptions"
+
+	helper.SetClientHTTP(c, reqRes)
+
+	_, err := subscriptionsHealthV2(c.Client)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 2, ngsiErr.ErrNo)
+		assert.Equal(t, "400 Bad Request error", ngsiErr.Message)
+	}
+}
+
+func TestSubscriptionsHealthV2ErrorResultsCount(t *testing.T) {
+	c := setupTest([]string{"subscriptions", "health", "--host", "orion"})
+
+	reqRes := helper.MockHTTPReqRes{}
+	reqRes.Res.StatusCode = http.StatusOK
+	reqRes.Path = "/v2/subscriptions"
+
+	helper.SetClientHTTP(c, reqRes)
+
+	_, err := subscriptionsHealthV2(c.Client)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 3, ngsiErr.ErrNo)
+		assert.Equal(t, "ResultsCount error", ngsiErr.Message)
+	}
+}
+
+func TestSubscriptionsHealthV2ErrorUnmarshal(t *testing.T) {
+	c := setupTest([]string{"subscriptions", "health", "--host", "orion"})
+
+	reqRes := helper.MockHTTPReqRes{}
+	reqRes.Res.StatusCode = http.StatusOK
+	reqRes.ResBody = []byte(`[]`)
+	reqRes.Path = "/v2/subscriptions"
+	reqRes.ResHeader = http.Header{"Fiware-Total-Count": []string{"1"}}
+
+	helper.SetClientHTTP(c, reqRes)
+	helper.SetJSONDecodeErr(c.Ngsi, 0)
+
+	_, err := subscriptionsHealthV2(c.Client)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 4, ngsiErr.ErrNo)
+		assert.Equal(t, "json error", ngsiErr.Message)
+	}
+}
+
+func TestSubscriptionsHealthLdErrorHTTP(t *testing.T) {
+	c := setupTest([]string{"subscriptions", "health", "--host", "orion-ld"})
+
+	reqRes := helper.MockHTTPReqRes{}
+	reqRes.Path = "/ngsi-ld/v1/subscriptions/"
+	reqRes.Err = errors.New("http error")
+
+	helper.SetClientHTTP(c, reqRes)
+
+	_, err := subscriptionsHealthLd(c.Client)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 1, ngsiErr.ErrNo)
+		assert.Equal(t, "http error", ngsiErr.Message)
+	}
+}
+
+func TestSubscriptionsHealthLdErrorStatus(t *testing.T) {
+	c := setupTest([]string{"subscriptions", "health", "--host", "orion-ld"})
+
+	reqRes := helper.MockHTTPReqRes{}
+	reqRes.Res.StatusCode = http.StatusBadRequest
+	reqRes.Res.Status = "400 Bad Request"
+	reqRes.ResBody = []byte("error")
+	reqRes.Path = "/ngsi-ld/v1/subscriptions/"
+
+	helper.SetClientHTTP(c, reqRes)
+
+	_, err := subscriptionsHealthLd(c.Client)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 2, ngsiErr.ErrNo)
+		assert.Equal(t, "400 Bad Request error", ngsiErr.Message)
+	}
+}
+
+func TestSubscriptionsHealthLdErrorResultsCount(t *testing.T) {
+	c := setupTest([]string{"subscriptions", "health", "--host", "orion-ld"})
+
+	reqRes := helper.MockHTTPReqRes{}
+	reqRes.Res.StatusCode = http.StatusOK
+	reqRes.Path = "/ngsi-ld/v1/subscriptions/"
+
+	helper.SetClientHTTP(c, reqRes)
+
+	_, err := subscriptionsHealthLd(c.Client)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 3, ngsiErr.ErrNo)
+		assert.Equal(t, "ResultsCount error", ngsiErr.Message)
+	}
+}
+
+func TestSubscriptionsHealthLdErrorUnmarshal(t *testing.T) {
+	c := setupTest([]string{"subscriptions", "health", "--host", "orion-ld"})
+
+	reqRes := helper.MockHTTPReqRes{}
+	reqRes.Res.StatusCode = http.StatusOK
+	reqRes.ResBody = []byte(`[]`)
+	reqRes.Path = "/ngsi-ld/v1/subscriptions/"
+	reqRes.ResHeader = http.Header{"Ngsild-Results-Count": []string{"1"}}
+
+	helper.SetClientHTTP(c, reqRes)
+	helper.SetJSONDecodeErr(c.Ngsi, 0)
+
+	_, err := subscriptionsHealthLd(c.Client)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 4, ngsiErr.ErrNo)
+		assert.Equal(t, "json error", ngsiErr.Message)
+	}
+}
+
+func TestSubscriptionsHealthFixErrorStatus(t *testing.T) {
+	c := setupTest([]string{"subscriptions", "health", "--host", "orion", "--reactivate", "--run"})
+
+	reqRes := helper.MockHTTPReqRes{}
+	reqRes.Res.StatusCode = http.StatusNotFound
+	reqRes.Res.Status = "404 Not Found"
+	reqRes.ResBody = []byte("error")
+	reqRes.Path = "/v2/subscriptions/s1"
+
+	helper.SetClientHTTP(c, reqRes)
+
+	err := subscriptionsHealthFix(c, c.Ngsi, c.Client, []*subscriptionHealth{{ID: "s1", failing: true}})
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 2, ngsiErr.ErrNo)
+		assert.Equal(t, "404 Not Found error s1", ngsiErr.Message)
+	}
+}
+
+func TestSubscriptionsHealthPrintErrorWrite(t *testing.T) {
+	c := setupTest([]string{"subscriptions", "health", "--host", "orion"})
+	c.Ngsi.StdWriter = &errTableWriter{}
+
+	report := &subscriptionHealthReport{Flagged: []*subscriptionHealth{{ID: "s1"}}}
+
+	err := subscriptionsHealthPrint(c.Ngsi, report)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 1, ngsiErr.ErrNo)
+		assert.Equal(t, "write error", ngsiErr.Message)
+	}
+}
+
+func TestSubscriptionsHealthPrintErrorFlush(t *testing.T) {
+	c := setupTest([]string{"subscriptions", "health", "--host", "orion"})
+	c.Ngsi.StdWriter = &errTableWriter{}
+
+	err := subscriptionsHealthPrint(c.Ngsi, &subscriptionHealthReport{})
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 2, ngsiErr.ErrNo)
+		assert.Equal(t, "write error", ngsiErr.Message)
+	}
+}
+
+func TestSubscriptionsHealthErrorPrint(t *testing.T) {
+	c := setupTest([]string{"subscriptions", "health", "--host", "orion"})
+	c.Ngsi.StdWriter = &errTableWriter{}
+
+	reqRes := helper.MockHTTPReqRes{}
+	reqRes.Res.StatusCode = http.StatusOK
+	reqRes.ResBody = []byte(`[]`)
+	reqRes.Path = "/v2/subscriptions"
+	reqRes.ResHeader = http.Header{"Fiware-Total-Count": []string{"0"}}
+
+	helper.SetClientHTTP(c, reqRes)
+
+	err := subscriptionsHealth(c, c.Ngsi, c.Client)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 6, ngsiErr.ErrNo)
+		assert.Equal(t, "write error", ngsiErr.Message)
+	}
+}
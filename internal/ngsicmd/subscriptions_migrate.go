@@ -0,0 +1,490 @@
+/*
+MIT License
+
+Copyright (c) 2020-2024 Kazuhito Suda
+
+This file is part of NGSI Go
+
+https://github.com/lets-fiware/ngsi-go
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+
+*/
+
+package ngsicmd
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/lets-fiware/ngsi-go/internal/ngsicli"
+	"github.com/lets-fiware/ngsi-go/internal/ngsierr"
+	"github.com/lets-fiware/ngsi-go/internal/ngsilib"
+)
+
+// subscriptionMigration is a subscription translated for a destination broker.
+// Fields which have no equivalent are dropped and listed in unsupported.
+// When the translated subscription cannot be created, skip has the reason.
+// The description is marked with "migrated from <id>" so that the subscription is found when migrated again.
+type subscriptionMigration struct {
+	id          string
+	description string
+	body        interface{}
+	unsupported []string
+	skip        string
+}
+
+// read-only fields of subscriptions which are not migrated
+var (
+	migrateRuntimeV2 = []string{"timesSent", "lastNotification", "lastFailure", "lastFailureReason", "lastSuccess", "lastSuccessCode", "failsCounter"}
+	migrateRuntimeLd = []string{"status", "timesSent", "lastNotification", "lastFailure", "lastSuccess", "consecutiveErrors", "lastErrorReason"}
+)
+
+func subscriptionsMigrate(c *ngsicli.Context, ngsi *ngsilib.NGSI, client *ngsilib.Client) error {
+	const funcName = "subscriptionsMigrate"
+
+	destination := c.Client2
+
+	if client.IsNgsiV2() == destination.IsNgsiV2() {
+		return ngsierr.New(funcName, 1, "subscriptions are migrated between NGSIv2 and NGSI-LD brokers", nil)
+	}
+
+	if destination.IsNgsiV2() && destination.Scope == "" {
+		destination.Scope = "/"
+		destination.Headers["Fiware-ServicePath"] = "/"
+	}
+
+	var atContext interface{}
+	if c.IsSet("context2") {
+		var err error
+		atContext, err = ngsi.GetAtContext(c.String("context2"))
+		if err != nil {
+			return ngsierr.New(funcName, 2, err.Error(), err)
+		}
+	}
+
+	subs, err := subscriptionsMigrateList(client)
+	if err != nil {
+		return ngsierr.New(funcName, 3, err.Error(), err)
+	}
+
+	// The subscriptions migrated before are matched by the source id in their description so that they aren't created twice.
+	dests, err := subscriptionsMigrateList(destination)
+	if err != nil {
+		return ngsierr.New(funcName, 4, err.Error(), err)
+	}
+	existing := map[string]string{}
+	for _, d := range dests {
+		if source := migrateSource(migrateString(d["description"])); source != "" {
+			if _, ok := existing[source]; !ok {
+				existing[source] = migrateString(d["id"])
+			}
+		}
+	}
+
+	run := c.Bool("run") || destination.DryRun
+
+	migrated, skipped := 0, 0
+
+	for _, s := range subs {
+		var m *subscriptionMigration
+		if client.IsNgsiV2() {
+			m = subscriptionMigrateV2toLd(s)
+			if atContext != nil {
+				m.body.(*subscriptionLd).AtContext = atContext
+			}
+		} else {
+			m = subscriptionMigrateLdToV2(s)
+		}
+
+		if m.skip == "" {
+			if id, ok := existing[m.id]; ok {
+				m.skip = "already migrated to " + id
+			}
+		}
+		if m.skip != "" {
+			fmt.Fprintf(ngsi.StdWriter, "%s skipped: %s\n", m.id, m.skip)
+			skipped++
+			continue
+		}
+
+		if run {
+			id, err := subscriptionsMigrateCreate(destination, m)
+			if err != nil && !ngsilib.IsDryRun(err) {
+				return ngsierr.New(funcName, 5, err.Error(), err)
+			}
+			if id != "" {
+				fmt.Fprintf(ngsi.StdWriter, "%s -> %s\n", m.id, id)
+				ngsi.Logging(ngsilib.LogInfo, fmt.Sprintf("%s is migrated to %s", m.id, id))
+			}
+		} else {
+			fmt.Fprintln(ngsi.StdWriter, m.id)
+		}
+		if len(m.unsupported) > 0 {
+			fmt.Fprintf(ngsi.StdWriter, "  unsupported: %s\n", strings.Join(m.unsupported, ", "))
+		}
+		migrated++
+	}
+
+	if run {
+		fmt.Fprintf(ngsi.StdWriter, "%d subscriptions migrated, %d skipped\n", migrated, skipped)
+	} else {
+		fmt.Fprintf(ngsi.StdWriter, "%d subscriptions will be migrated, %d skipped. run migrate with --run option\n", migrated, skipped)
+	}
+
+	return nil
+}
+
+func subscriptionsMigrateList(client *ngsilib.Client) ([]map[string]interface{}, error) {
+	const funcName = "subscriptionsMigrateList"
+
+	page := 0
+	limit := 100
+
+	var subs []map[string]interface{}
+
+	for {
+		v := url.Values{}
+		if client.IsNgsiV2() {
+			client.SetPath("/subscriptions")
+			v.Set("options", "count")
+		} else {
+			client.SetPath("/subscriptions/")
+			v.Set("count", "true")
+		}
+		v.Set("limit", fmt.Sprintf("%d", limit))
+		v.Set("offset", fmt.Sprintf("%d", page*limit))
+		client.SetQuery(&v)
+
+		res, body, err := client.HTTPGet()
+		if err != nil {
+			return nil, ngsierr.New(funcName, 1, err.Error(), err)
+		}
+		if res.StatusCode != http.StatusOK {
+			return nil, ngsierr.New(funcName, 2, fmt.Sprintf("%s %s", res.Status, string(body)), nil)
+		}
+		count, err := client.ResultsCount(res)
+		if err != nil {
+			return nil, ngsierr.New(funcName, 3, "ResultsCount error", err)
+		}
+		if count == 0 {
+			break
+		}
+		var list []map[string]interface{}
+		if err := ngsilib.JSONUnmarshalDecode(body, &list, client.IsSafeString()); err != nil {
+			return nil, ngsierr.New(funcName, 4, err.Error(), err)
+		}
+		subs = append(subs, list...)
+
+		if (page+1)*limit < count {
+			page = page + 1
+		} else {
+			break
+		}
+	}
+
+	return subs, nil
+}
+
+func subscriptionsMigrateCreate(client *ngsilib.Client, m *subscriptionMigration) (string, error) {
+	const funcName = "subscriptionsMigrateCreate"
+
+	client.SetPath("/subscriptions")
+	client.SetQuery(&url.Values{})
+	if client.IsNgsiV2() {
+		client.SetContentJSON()
+	} else {
+		client.SetContentType()
+	}
+
+	b, err := ngsilib.JSONMarshalEncode(m.body, client.IsSafeString())
+	if err != nil {
+		return "", ngsierr.New(funcName, 1, err.Error(), err)
+	}
+
+	res, body, err := client.HTTPPost(b)
+	if err != nil {
+		return "", ngsierr.New(funcName, 2, err.Error(), err)
+	}
+	if res.StatusCode != http.StatusCreated {
+		return "", ngsierr.New(funcName, 3, fmt.Sprintf("%s %s %s", res.Status, string(body), m.id), nil)
+	}
+
+	location := res.Header.Get("Location")
+
+	return location[strings.LastIndex(location, "/")+1:], nil
+}
+
+// subscriptionMigrateV2toLd translates a NGSIv2 subscription into a NGSI-LD subscription
+func subscriptionMigrateV2toLd(s map[string]interface{}) *subscriptionMigration {
+	m := &subscriptionMigration{id: migrateString(s["id"]), description: migrateDescription(s)}
+	t := &subscriptionLd{Type: "Subscription", Description: m.description}
+	m.body = t
+
+	migrateRest(m, "", s, []string{"id", "description", "subject", "notification", "expires", "throttling", "status"})
+
+	subject, _ := s["subject"].(map[string]interface{})
+	migrateRest(m, "subject.", subject, []string{"entities", "condition"})
+
+	entities, _ := subject["entities"].([]interface{})
+	for i, v := range entities {
+		e, _ := v.(map[string]interface{})
+		path := fmt.Sprintf("subject.entities[%d]", i)
+		if migrateString(e["type"]) == "" {
+			if migrateString(e["typePattern"]) != "" {
+				m.unsupported = append(m.unsupported, path+".typePattern")
+			} else {
+				m.unsupported = append(m.unsupported, path+": no type")
+			}
+			continue
+		}
+		migrateRest(m, path+".", e, []string{"id", "idPattern", "type"})
+		entity := entityInfoLd{IDPattern: migrateLdIDPattern(migrateString(e["idPattern"]), migrateString(e["type"])), Type: migrateString(e["type"])}
+		if id := migrateString(e["id"]); id != "" {
+			entity.ID = ngsilib.LdID(id, entity.Type)
+		}
+		t.Entities = append(t.Entities, entity)
+	}
+
+	condition, _ := subject["condition"].(map[string]interface{})
+	migrateRest(m, "subject.condition.", condition, []string{"attrs", "expression", "notifyOnMetadataChange"})
+	if b, ok := condition["notifyOnMetadataChange"].(bool); ok && !b {
+		m.unsupported = append(m.unsupported, "subject.condition.notifyOnMetadataChange")
+	}
+	t.WatchedAttributes = migrateStrings(condition["attrs"])
+
+	expression, _ := condition["expression"].(map[string]interface{})
+	migrateRest(m, "subject.condition.expression.", expression, []string{"q"})
+	t.Q = migrateString(expression["q"])
+
+	notification, _ := s["notification"].(map[string]interface{})
+	migrateRest(m, "notification.", notification, append([]string{"http", "httpCustom", "attrs", "attrsFormat"}, migrateRuntimeV2...))
+
+	t.Notification = &notificationParamsLd{Attributes: migrateStrings(notification["attrs"])}
+
+	if h, ok := notification["http"].(map[string]interface{}); ok {
+		migrateRest(m, "notification.http.", h, []string{"url"})
+		t.Notification.Endpoint = &endpointLd{URI: migrateString(h["url"])}
+	} else if h, ok := notification["httpCustom"].(map[string]interface{}); ok {
+		migrateRest(m, "notification.httpCustom.", h, []string{"url"})
+		t.Notification.Endpoint = &endpointLd{URI: migrateString(h["url"])}
+	}
+
+	switch f := migrateString(notification["attrsFormat"]); f {
+	case "", "normalized", "keyValues":
+		t.Notification.Format = f
+	default:
+		m.unsupported = append(m.unsupported, "notification.attrsFormat: "+f)
+	}
+
+	t.Expires = migrateString(s["expires"])
+	if v, ok := s["throttling"].(float64); ok && v > 0 {
+		throttling := int64(v)
+		t.Throttling = &throttling
+	}
+
+	switch status := migrateString(s["status"]); status {
+	case "active", "inactive":
+		b := status == "active"
+		t.IsActive = &b
+	case "", "failed", "expired":
+	default:
+		m.unsupported = append(m.unsupported, "status: "+status)
+	}
+
+	if t.Notification.Endpoint == nil || t.Notification.Endpoint.URI == "" {
+		m.skip = "no notification url"
+	} else if len(t.Entities) == 0 && len(t.WatchedAttributes) == 0 {
+		m.skip = "neither entities with type nor condition attrs"
+	}
+
+	return m
+}
+
+// subscriptionMigrateLdToV2 translates a NGSI-LD subscription into a NGSIv2 subscription
+func subscriptionMigrateLdToV2(s map[string]interface{}) *subscriptionMigration {
+	m := &subscriptionMigration{id: migrateString(s["id"]), description: migrateDescription(s)}
+	t := &subscriptionV2{Description: m.description, Subject: &subscriptionSubjectV2{}}
+	m.body = t
+
+	migrateRest(m, "", s, []string{"id", "type", "@context", "jsonldContext", "origin", "createdAt", "modifiedAt", "status", "description", "entities", "watchedAttributes", "q", "notification", "expires", "expiresAt", "throttling", "isActive"})
+
+	entities, _ := s["entities"].([]interface{})
+	for i, v := range entities {
+		e, _ := v.(map[string]interface{})
+		migrateRest(m, fmt.Sprintf("entities[%d].", i), e, []string{"id", "idPattern", "type"})
+		entity := subscriptionEntityV2{IDPattern: migrateV2IDPattern(migrateString(e["idPattern"]), migrateString(e["type"])), Type: migrateString(e["type"])}
+		if id := migrateString(e["id"]); id != "" {
+			entity.ID = ngsilib.V2ID(id, entity.Type)
+		}
+		if entity.ID == "" && entity.IDPattern == "" {
+			entity.IDPattern = ".*"
+		}
+		t.Subject.Entities = append(t.Subject.Entities, entity)
+	}
+	if len(t.Subject.Entities) == 0 {
+		t.Subject.Entities = []subscriptionEntityV2{{IDPattern: ".*"}}
+	}
+
+	attrs := migrateStrings(s["watchedAttributes"])
+	q := migrateString(s["q"])
+	if len(attrs) > 0 || q != "" {
+		t.Subject.Condition = &subscriptionConditionV2{Attrs: attrs}
+		if q != "" {
+			t.Subject.Condition.Expression = &subscriptionExpressionV2{Q: q}
+		}
+	}
+
+	notification, _ := s["notification"].(map[string]interface{})
+	migrateRest(m, "notification.", notification, append([]string{"endpoint", "attributes", "format"}, migrateRuntimeLd...))
+
+	t.Notification = &subscriptionNotificationV2{Attrs: migrateStrings(notification["attributes"])}
+
+	endpoint, _ := notification["endpoint"].(map[string]interface{})
+	migrateRest(m, "notification.endpoint.", endpoint, []string{"uri", "accept"})
+	if accept := migrateString(endpoint["accept"]); accept != "" && accept != "application/json" {
+		m.unsupported = append(m.unsupported, "notification.endpoint.accept: "+accept)
+	}
+	uri := migrateString(endpoint["uri"])
+	if strings.HasPrefix(uri, "http://") || strings.HasPrefix(uri, "https://") {
+		t.Notification.HTTP = &subscriptionHTTPV2{URL: uri}
+	} else if uri != "" {
+		m.unsupported = append(m.unsupported, "notification.endpoint.uri: "+uri)
+	}
+
+	switch f := migrateString(notification["format"]); f {
+	case "", "normalized", "keyValues":
+		t.Notification.AttrsFormat = f
+	default:
+		m.unsupported = append(m.unsupported, "notification.format: "+f)
+	}
+
+	t.Expires = migrateString(s["expires"])
+	if t.Expires == "" {
+		t.Expires = migrateString(s["expiresAt"])
+	}
+	if v, ok := s["throttling"].(float64); ok {
+		t.Throttling = int64(v)
+	}
+	if b, ok := s["isActive"].(bool); ok {
+		t.Status = "active"
+		if !b {
+			t.Status = "inactive"
+		}
+	}
+
+	if t.Notification.HTTP == nil {
+		m.skip = "no http notification url"
+	}
+
+	return m
+}
+
+func migrateDescription(s map[string]interface{}) string {
+	marker := "migrated from " + migrateString(s["id"])
+	if description := migrateString(s["description"]); description != "" {
+		return description + " (" + marker + ")"
+	}
+	return marker
+}
+
+var migrateSourceRegexp = regexp.MustCompile(`(?:^|\()migrated from ([^\s()]+)\)?$`)
+
+// migrateSource returns the id of the source subscription marked in a description by migrateDescription.
+func migrateSource(description string) string {
+	if m := migrateSourceRegexp.FindStringSubmatch(description); m != nil {
+		return m[1]
+	}
+	return ""
+}
+
+// migrateLdIDPattern makes an idPattern anchored with ^ match the ids made by ngsilib.LdID.
+func migrateLdIDPattern(pattern, entityType string) string {
+	p := strings.TrimPrefix(pattern, "^")
+	if p == pattern || entityType == "" || strings.HasPrefix(p, "urn:") || strings.HasPrefix(p, "http") {
+		return pattern
+	}
+	if strings.HasPrefix(strings.ToLower(p), strings.ToLower(entityType)+":") {
+		return "^urn:ngsi-ld:" + p
+	}
+	return "^" + regexp.QuoteMeta("urn:ngsi-ld:"+entityType+":") + p
+}
+
+// migrateV2IDPattern removes the prefix of NGSI-LD ids from an idPattern anchored with ^.
+func migrateV2IDPattern(pattern, entityType string) string {
+	if entityType != "" && strings.HasPrefix(pattern, "^urn:ngsi-ld:"+entityType+":") {
+		return "^" + strings.TrimPrefix(pattern, "^urn:ngsi-ld:"+entityType+":")
+	}
+	if strings.HasPrefix(pattern, "^urn:ngsi-ld:") {
+		return "^" + strings.TrimPrefix(pattern, "^urn:ngsi-ld:")
+	}
+	return pattern
+}
+
+// migrateRest adds the fields of m which are not in known to unsupported.
+// Fields with an empty or default value such as false are ignored.
+func migrateRest(m *subscriptionMigration, path string, v map[string]interface{}, known []string) {
+	var rest []string
+	for k, e := range v {
+		if ngsilib.Contains(known, k) || migrateIsEmpty(e) {
+			continue
+		}
+		rest = append(rest, path+k)
+	}
+	sort.Strings(rest)
+	m.unsupported = append(m.unsupported, rest...)
+}
+
+func migrateIsEmpty(v interface{}) bool {
+	switch v := v.(type) {
+	case nil:
+		return true
+	case bool:
+		return !v
+	case string:
+		return v == ""
+	case float64:
+		return v == 0
+	case []interface{}:
+		return len(v) == 0
+	case map[string]interface{}:
+		return len(v) == 0
+	}
+	return false
+}
+
+func migrateString(v interface{}) string {
+	s, _ := v.(string)
+	return s
+}
+
+func migrateStrings(v interface{}) []string {
+	var list []string
+	a, _ := v.([]interface{})
+	for _, e := range a {
+		if s, ok := e.(string); ok {
+			list = append(list, s)
+		}
+	}
+	return list
+}
@@ -0,0 +1,512 @@
+/*
+MIT License
+
+Copyright (c) 2020-2024 Kazuhito Suda
+
+This file is part of NGSI Go
+
+https://github.com/lets-fiware/ngsi-go
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+
+*/
+
+package ngsicmd
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/lets-fiware/ngsi-go/internal/assert"
+	"github.com/lets-fiware/ngsi-go/internal/helper"
+	"github.com/lets-fiware/ngsi-go/internal/ngsierr"
+)
+
+const migrateTestSubscriptionsV2 = `[
+{"id":"5f01","description":"price","subject":{"entities":[{"idPattern":".*","type":"Product"}],"condition":{"attrs":["price"],"expression":{"q":"price>10"}}},"notification":{"http":{"url":"http://quantumleap:8668/v2/notify"},"attrs":["price"],"attrsFormat":"keyValues","onlyChangedAttrs":false,"timesSent":3,"lastNotification":"2021-01-01T00:00:00.000Z"},"expires":"2030-01-01T00:00:00.000Z","throttling":5,"status":"active"},
+{"id":"5f02","description":"custom","subject":{"entities":[{"idPattern":".*","type":"Product"},{"idPattern":".*","typePattern":"Pro.*"}],"condition":{"attrs":[],"notifyOnMetadataChange":false,"expression":{"mq":"price.unit==EUR"}}},"notification":{"httpCustom":{"url":"http://custom/n","payload":"price changed","headers":{"X":"1"}},"attrsFormat":"values","exceptAttrs":["a"]},"status":"inactive"},
+{"id":"5f03","subject":{"entities":[{"idPattern":".*"}],"condition":{"alterationTypes":["entityCreate"]}},"notification":{"http":{"url":"http://x/n"}},"status":"oneshot"},
+{"id":"5f04","subject":{"entities":[{"idPattern":".*","type":"Product"}]},"notification":{"mqtt":{"url":"mqtt://mosquitto:1883","topic":"t"}},"status":"failed"}
+]`
+
+func migrateTestDest(list, count string, reqRes ...helper.MockHTTPReqRes) *helper.MockHTTP {
+	dest := helper.MockHTTPReqRes{}
+	dest.Res.StatusCode = http.StatusOK
+	dest.ResBody = []byte(list)
+	dest.ResHeader = http.Header{"Fiware-Total-Count": []string{count}, "Ngsild-Results-Count": []string{count}}
+
+	mock := helper.NewMockHTTP()
+	mock.ReqRes = append(mock.ReqRes, dest)
+	mock.ReqRes = append(mock.ReqRes, reqRes...)
+	return mock
+}
+
+func TestSubscriptionsMigrateV2toLd(t *testing.T) {
+	c := setupTest([]string{"subscriptions", "migrate", "--host", "orion", "--host2", "orion-ld", "--run"})
+
+	reqRes1 := helper.MockHTTPReqRes{}
+	reqRes1.Res.StatusCode = http.StatusOK
+	reqRes1.ResBody = []byte(migrateTestSubscriptionsV2)
+	reqRes1.ResHeader = http.Header{"Fiware-Total-Count": []string{"4"}}
+	reqRes1.Path = "/v2/subscriptions"
+	helper.SetClientHTTP(c, reqRes1)
+
+	reqRes2 := helper.MockHTTPReqRes{}
+	reqRes2.Res.StatusCode = http.StatusCreated
+	reqRes2.ResHeader = http.Header{"Location": []string{"/ngsi-ld/v1/subscriptions/urn:ngsi-ld:Subscription:1"}}
+	reqRes2.ReqData = []byte(`{"type":"Subscription","description":"price (migrated from 5f01)","entities":[{"idPattern":".*","type":"Product"}],"watchedAttributes":["price"],"q":"price>10","isActive":true,"notification":{"attributes":["price"],"format":"keyValues","endpoint":{"uri":"http://quantumleap:8668/v2/notify"}},"expires":"2030-01-01T00:00:00.000Z","throttling":5}`)
+	reqRes2.Path = "/ngsi-ld/v1/subscriptions"
+	reqRes3 := helper.MockHTTPReqRes{}
+	reqRes3.Res.StatusCode = http.StatusCreated
+	reqRes3.ResHeader = http.Header{"Location": []string{"/ngsi-ld/v1/subscriptions/urn:ngsi-ld:Subscription:2"}}
+	reqRes3.ReqData = []byte(`{"type":"Subscription","description":"custom (migrated from 5f02)","entities":[{"idPattern":".*","type":"Product"}],"isActive":false,"notification":{"endpoint":{"uri":"http://custom/n"}}}`)
+	c.Client2.HTTP = migrateTestDest(`[]`, "0", reqRes2, reqRes3)
+
+	err := subscriptionsMigrate(c, c.Ngsi, c.Client)
+
+	if assert.NoError(t, err) {
+		actual := helper.GetStdoutString(c)
+		expected := "" +
+			"5f01 -> urn:ngsi-ld:Subscription:1\n" +
+			"5f02 -> urn:ngsi-ld:Subscription:2\n" +
+			"  unsupported: subject.entities[1].typePattern, subject.condition.notifyOnMetadataChange, subject.condition.expression.mq, " +
+			"notification.exceptAttrs, notification.httpCustom.headers, notification.httpCustom.payload, notification.attrsFormat: values\n" +
+			"5f03 skipped: neither entities with type nor condition attrs\n" +
+			"5f04 skipped: no notification url\n" +
+			"2 subscriptions migrated, 2 skipped\n"
+		assert.Equal(t, expected, actual)
+	}
+}
+
+func TestSubscriptionsMigrateV2toLdContext(t *testing.T) {
+	c := setupTest([]string{"subscriptions", "migrate", "--host", "orion", "--host2", "orion-ld", "--context2", "[\"http://context\"]", "--run"})
+
+	reqRes1 := helper.MockHTTPReqRes{}
+	reqRes1.Res.StatusCode = http.StatusOK
+	reqRes1.ResBody = []byte(`[{"id":"5f01","subject":{"condition":{"attrs":["price"]}},"notification":{"http":{"url":"http://x/n"}}}]`)
+	reqRes1.ResHeader = http.Header{"Fiware-Total-Count": []string{"1"}}
+	helper.SetClientHTTP(c, reqRes1)
+
+	reqRes2 := helper.MockHTTPReqRes{}
+	reqRes2.Res.StatusCode = http.StatusCreated
+	reqRes2.ResHeader = http.Header{"Location": []string{"/ngsi-ld/v1/subscriptions/urn:ngsi-ld:Subscription:1"}}
+	reqRes2.ReqData = []byte(`{"type":"Subscription","description":"migrated from 5f01","watchedAttributes":["price"],"notification":{"endpoint":{"uri":"http://x/n"}},"@context":["http://context"]}`)
+	c.Client2.HTTP = migrateTestDest(`[]`, "0", reqRes2)
+
+	err := subscriptionsMigrate(c, c.Ngsi, c.Client)
+
+	if assert.NoError(t, err) {
+		actual := helper.GetStdoutString(c)
+		expected := "5f01 -> urn:ngsi-ld:Subscription:1\n1 subscriptions migrated, 0 skipped\n"
+		assert.Equal(t, expected, actual)
+		assert.Equal(t, "application/ld+json", c.Client2.Headers["Content-Type"])
+	}
+}
+
+func TestSubscriptionsMigrateLdToV2(t *testing.T) {
+	c := setupTest([]string{"subscriptions", "migrate", "--host", "orion-ld", "--host2", "orion", "--service2", "openiot"})
+
+	reqRes1 := helper.MockHTTPReqRes{}
+	reqRes1.Res.StatusCode = http.StatusOK
+	reqRes1.ResBody = []byte(`[
+{"id":"urn:ngsi-ld:Subscription:1","type":"Subscription","description":"temperature","entities":[{"type":"Sensor"}],"watchedAttributes":["temperature"],"q":"temperature>30","isActive":false,"status":"paused","notification":{"attributes":["temperature"],"format":"normalized","endpoint":{"uri":"http://notify/n","accept":"application/json"},"status":"ok","timesSent":1},"expiresAt":"2030-01-01T00:00:00.000Z","throttling":10,"origin":"database","@context":"https://uri.etsi.org/ngsi-ld/v1/ngsi-ld-core-context.jsonld"},
+{"id":"urn:ngsi-ld:Subscription:2","type":"Subscription","entities":[{"id":"urn:ngsi-ld:Sensor:1"}],"timeInterval":60,"geoQ":{"geometry":"Point"},"notification":{"format":"concise","endpoint":{"uri":"http://notify/n","accept":"application/ld+json","receiverInfo":[{"key":"a","value":"b"}]}},"expires":"2030-01-01T00:00:00.000Z","isActive":true},
+{"id":"urn:ngsi-ld:Subscription:3","type":"Subscription","watchedAttributes":["temperature"],"notification":{"endpoint":{"uri":"mqtt://mosquitto:1883/t"}}}
+]`)
+	reqRes1.ResHeader = http.Header{"Ngsild-Results-Count": []string{"3"}}
+	reqRes1.Path = "/ngsi-ld/v1/subscriptions/"
+	helper.SetClientHTTP(c, reqRes1)
+	c.Client2.HTTP = migrateTestDest(`[]`, "0")
+
+	err := subscriptionsMigrate(c, c.Ngsi, c.Client)
+
+	if assert.NoError(t, err) {
+		actual := helper.GetStdoutString(c)
+		expected := "" +
+			"urn:ngsi-ld:Subscription:1\n" +
+			"urn:ngsi-ld:Subscription:2\n" +
+			"  unsupported: geoQ, timeInterval, notification.endpoint.receiverInfo, notification.endpoint.accept: application/ld+json, notification.format: concise\n" +
+			"urn:ngsi-ld:Subscription:3 skipped: no http notification url\n" +
+			"2 subscriptions will be migrated, 1 skipped. run migrate with --run option\n"
+		assert.Equal(t, expected, actual)
+		assert.Equal(t, "openiot", c.Client2.Headers["Fiware-Service"])
+		assert.Equal(t, "/", c.Client2.Headers["Fiware-ServicePath"])
+	}
+}
+
+func TestSubscriptionsMigrateLdToV2Run(t *testing.T) {
+	c := setupTest([]string{"subscriptions", "migrate", "--host", "orion-ld", "--host2", "orion", "--run"})
+
+	reqRes1 := helper.MockHTTPReqRes{}
+	reqRes1.Res.StatusCode = http.StatusOK
+	reqRes1.ResBody = []byte(`[{"id":"urn:ngsi-ld:Subscription:1","type":"Subscription","description":"temperature","entities":[{"type":"Sensor"}],"watchedAttributes":["temperature"],"q":"temperature>30","isActive":false,"notification":{"attributes":["temperature"],"format":"keyValues","endpoint":{"uri":"http://notify/n"}},"expiresAt":"2030-01-01T00:00:00.000Z","throttling":10}]`)
+	reqRes1.ResHeader = http.Header{"Ngsild-Results-Count": []string{"1"}}
+	helper.SetClientHTTP(c, reqRes1)
+
+	reqRes2 := helper.MockHTTPReqRes{}
+	reqRes2.Res.StatusCode = http.StatusCreated
+	reqRes2.ResHeader = http.Header{"Location": []string{"/v2/subscriptions/5f01"}}
+	reqRes2.ReqData = []byte(`{"description":"temperature (migrated from urn:ngsi-ld:Subscription:1)","subject":{"entities":[{"idPattern":".*","type":"Sensor"}],"condition":{"attrs":["temperature"],"expression":{"q":"temperature>30"}}},"notification":{"http":{"url":"http://notify/n"},"attrs":["temperature"],"attrsFormat":"keyValues"},"throttling":10,"expires":"2030-01-01T00:00:00.000Z","status":"inactive"}`)
+	reqRes2.Path = "/v2/subscriptions"
+	c.Client2.HTTP = migrateTestDest(`[]`, "0", reqRes2)
+
+	err := subscriptionsMigrate(c, c.Ngsi, c.Client)
+
+	if assert.NoError(t, err) {
+		actual := helper.GetStdoutString(c)
+		expected := "urn:ngsi-ld:Subscription:1 -> 5f01\n1 subscriptions migrated, 0 skipped\n"
+		assert.Equal(t, expected, actual)
+		assert.Equal(t, "application/json", c.Client2.Headers["Content-Type"])
+	}
+}
+
+func TestSubscriptionsMigrateAlreadyMigrated(t *testing.T) {
+	c := setupTest([]string{"subscriptions", "migrate", "--host", "orion", "--host2", "orion-ld"})
+
+	reqRes1 := helper.MockHTTPReqRes{}
+	reqRes1.Res.StatusCode = http.StatusOK
+	reqRes1.ResBody = []byte(`[{"id":"5f01","description":"price","subject":{"condition":{"attrs":["price"]}},"notification":{"http":{"url":"http://x/n"}}},{"id":"5f02","subject":{"condition":{"attrs":["price"]}},"notification":{"http":{"url":"http://x/n"}}},{"id":"5f03","description":"price","subject":{"condition":{"attrs":["price"]}},"notification":{"http":{"url":"http://x/n"}}}]`)
+	reqRes1.ResHeader = http.Header{"Fiware-Total-Count": []string{"3"}}
+	helper.SetClientHTTP(c, reqRes1)
+
+	c.Client2.HTTP = migrateTestDest(`[{"id":"urn:ngsi-ld:Subscription:1","description":"price"},{"id":"urn:ngsi-ld:Subscription:2","description":"migrated from 5f02"},{"id":"urn:ngsi-ld:Subscription:3","description":"price (migrated from 5f03)"},{"id":"urn:ngsi-ld:Subscription:4"}]`, "4")
+
+	err := subscriptionsMigrate(c, c.Ngsi, c.Client)
+
+	if assert.NoError(t, err) {
+		actual := helper.GetStdoutString(c)
+		expected := "" +
+			"5f01\n" +
+			"5f02 skipped: already migrated to urn:ngsi-ld:Subscription:2\n" +
+			"5f03 skipped: already migrated to urn:ngsi-ld:Subscription:3\n" +
+			"1 subscriptions will be migrated, 2 skipped. run migrate with --run option\n"
+		assert.Equal(t, expected, actual)
+	}
+}
+
+func TestMigrateSource(t *testing.T) {
+	cases := []struct {
+		description string
+		expected    string
+	}{
+		{description: "migrated from 5f01", expected: "5f01"},
+		{description: "price (migrated from urn:ngsi-ld:Subscription:1)", expected: "urn:ngsi-ld:Subscription:1"},
+		{description: "price", expected: ""},
+		{description: "", expected: ""},
+		{description: "price migrated from 5f01", expected: ""},
+	}
+
+	for _, c := range cases {
+		actual := migrateSource(c.description)
+		assert.Equal(t, c.expected, actual)
+	}
+}
+
+func TestSubscriptionsMigrateV2toLdIDs(t *testing.T) {
+	m := subscriptionMigrateV2toLd(map[string]interface{}{
+		"id":          "5f01",
+		"description": "ids",
+		"subject": map[string]interface{}{"entities": []interface{}{
+			map[string]interface{}{"id": "Room1", "type": "Room"},
+			map[string]interface{}{"id": "urn:ngsi-ld:Room:Room2", "type": "Room"},
+			map[string]interface{}{"idPattern": "^Room[0-9]+", "type": "Room"},
+			map[string]interface{}{"idPattern": ".*", "type": "Room"},
+		}},
+		"notification": map[string]interface{}{"http": map[string]interface{}{"url": "http://x/n"}},
+	})
+
+	expected := []entityInfoLd{
+		{ID: "urn:ngsi-ld:Room:Room1", Type: "Room"},
+		{ID: "urn:ngsi-ld:Room:Room2", Type: "Room"},
+		{IDPattern: "^urn:ngsi-ld:Room:Room[0-9]+", Type: "Room"},
+		{IDPattern: ".*", Type: "Room"},
+	}
+	assert.Equal(t, expected, m.body.(*subscriptionLd).Entities)
+}
+
+func TestSubscriptionsMigrateLdToV2IDs(t *testing.T) {
+	m := subscriptionMigrateLdToV2(map[string]interface{}{
+		"id":          "urn:ngsi-ld:Subscription:1",
+		"description": "ids",
+		"entities": []interface{}{
+			map[string]interface{}{"id": "urn:ngsi-ld:Room:Room1", "type": "Room"},
+			map[string]interface{}{"idPattern": "^urn:ngsi-ld:Room:Room[0-9]+", "type": "Room"},
+			map[string]interface{}{"idPattern": "^urn:ngsi-ld:Room[0-9]+"},
+			map[string]interface{}{"idPattern": "Room", "type": "Room"},
+		},
+		"notification": map[string]interface{}{"endpoint": map[string]interface{}{"uri": "http://x/n"}},
+	})
+
+	expected := []subscriptionEntityV2{
+		{ID: "Room1", Type: "Room"},
+		{IDPattern: "^Room[0-9]+", Type: "Room"},
+		{IDPattern: "^Room[0-9]+"},
+		{IDPattern: "Room", Type: "Room"},
+	}
+	assert.Equal(t, expected, m.body.(*subscriptionV2).Subject.Entities)
+}
+
+func TestMigrateLdIDPattern(t *testing.T) {
+	cases := []struct {
+		pattern    string
+		entityType string
+		expected   string
+	}{
+		{pattern: "^Room", entityType: "Room", expected: "^urn:ngsi-ld:Room:Room"},
+		{pattern: "^room:1", entityType: "Room", expected: "^urn:ngsi-ld:room:1"},
+		{pattern: "^urn:ngsi-ld:Room:1", entityType: "Room", expected: "^urn:ngsi-ld:Room:1"},
+		{pattern: "^https://example.org/", entityType: "Room", expected: "^https://example.org/"},
+		{pattern: "^Room", entityType: "", expected: "^Room"},
+		{pattern: "Room", entityType: "Room", expected: "Room"},
+	}
+
+	for _, tc := range cases {
+		assert.Equal(t, tc.expected, migrateLdIDPattern(tc.pattern, tc.entityType), tc.pattern)
+	}
+}
+
+func TestSubscriptionsMigrateLdToV2NoEntities(t *testing.T) {
+	m := subscriptionMigrateLdToV2(map[string]interface{}{"id": "urn:1", "notification": map[string]interface{}{"endpoint": map[string]interface{}{"uri": "https://notify/n"}}})
+
+	assert.Equal(t, "", m.skip)
+	assert.Equal(t, []subscriptionEntityV2{{IDPattern: ".*"}}, m.body.(*subscriptionV2).Subject.Entities)
+}
+
+func TestSubscriptionsMigrateDryRun(t *testing.T) {
+	c := setupTest([]string{"--dryRun", "subscriptions", "migrate", "--host", "orion", "--host2", "orion-ld"})
+
+	reqRes1 := helper.MockHTTPReqRes{}
+	reqRes1.Res.StatusCode = http.StatusOK
+	reqRes1.ResBody = []byte(`[{"id":"5f01","subject":{"condition":{"attrs":["price"]}},"notification":{"http":{"url":"http://x/n"}}}]`)
+	reqRes1.ResHeader = http.Header{"Fiware-Total-Count": []string{"1"}}
+	helper.SetClientHTTP(c, reqRes1)
+	c.Client2.HTTP = migrateTestDest(`[]`, "0")
+
+	err := subscriptionsMigrate(c, c.Ngsi, c.Client)
+
+	if assert.NoError(t, err) {
+		actual := helper.GetStdoutString(c)
+		expected := "" +
+			"POST https://orion-ld/ngsi-ld/v1/subscriptions\n" +
+			"Accept: */*\n" +
+			"Content-Type: application/ld+json\n" +
+			"\n" +
+			"{\"type\":\"Subscription\",\"description\":\"migrated from 5f01\",\"watchedAttributes\":[\"price\"],\"notification\":{\"endpoint\":{\"uri\":\"http://x/n\"}}}\n" +
+			"\n" +
+			"1 subscriptions migrated, 0 skipped\n"
+		assert.Equal(t, expected, actual)
+	}
+}
+
+func TestSubscriptionsMigratePage(t *testing.T) {
+	c := setupTest([]string{"subscriptions", "migrate", "--host", "orion", "--host2", "orion-ld"})
+
+	reqRes1 := helper.MockHTTPReqRes{}
+	reqRes1.Res.StatusCode = http.StatusOK
+	reqRes1.ResBody = []byte(`[{"id":"5f01"}]`)
+	reqRes1.ResHeader = http.Header{"Fiware-Total-Count": []string{"101"}}
+	reqRes1.RawQuery = helper.StrPtr("limit=100&offset=0&options=count")
+	reqRes2 := helper.MockHTTPReqRes{}
+	reqRes2.Res.StatusCode = http.StatusOK
+	reqRes2.ResBody = []byte(`[{"id":"5f02"}]`)
+	reqRes2.ResHeader = http.Header{"Fiware-Total-Count": []string{"101"}}
+	reqRes2.RawQuery = helper.StrPtr("limit=100&offset=100&options=count")
+	helper.SetClientHTTP(c, reqRes1, reqRes2)
+	c.Client2.HTTP = migrateTestDest(`[]`, "0")
+
+	err := subscriptionsMigrate(c, c.Ngsi, c.Client)
+
+	if assert.NoError(t, err) {
+		actual := helper.GetStdoutString(c)
+		expected := "" +
+			"5f01 skipped: no notification url\n" +
+			"5f02 skipped: no notification url\n" +
+			"0 subscriptions will be migrated, 2 skipped. run migrate with --run option\n"
+		assert.Equal(t, expected, actual)
+	}
+}
+
+func TestSubscriptionsMigrateNotFound(t *testing.T) {
+	c := setupTest([]string{"subscriptions", "migrate", "--host", "orion-ld", "--host2", "orion"})
+
+	reqRes1 := helper.MockHTTPReqRes{}
+	reqRes1.Res.StatusCode = http.StatusOK
+	reqRes1.ResBody = []byte(`[]`)
+	reqRes1.ResHeader = http.Header{"Ngsild-Results-Count": []string{"0"}}
+	helper.SetClientHTTP(c, reqRes1)
+	c.Client2.HTTP = migrateTestDest(`[]`, "0")
+
+	err := subscriptionsMigrate(c, c.Ngsi, c.Client)
+
+	if assert.NoError(t, err) {
+		actual := helper.GetStdoutString(c)
+		expected := "0 subscriptions will be migrated, 0 skipped. run migrate with --run option\n"
+		assert.Equal(t, expected, actual)
+	}
+}
+
+func TestSubscriptionsMigrateErrorSameType(t *testing.T) {
+	c := setupTest([]string{"subscriptions", "migrate", "--host", "orion", "--host2", "orion"})
+
+	err := subscriptionsMigrate(c, c.Ngsi, c.Client)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 1, ngsiErr.ErrNo)
+		assert.Equal(t, "subscriptions are migrated between NGSIv2 and NGSI-LD brokers", ngsiErr.Message)
+	}
+}
+
+func TestSubscriptionsMigrateErrorContext(t *testing.T) {
+	c := setupTest([]string{"subscriptions", "migrate", "--host", "orion", "--host2", "orion-ld", "--context2", "unknown"})
+
+	err := subscriptionsMigrate(c, c.Ngsi, c.Client)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 2, ngsiErr.ErrNo)
+		assert.Equal(t, "unknown not found", ngsiErr.Message)
+	}
+}
+
+func TestSubscriptionsMigrateErrorList(t *testing.T) {
+	c := setupTest([]string{"subscriptions", "migrate", "--host", "orion", "--host2", "orion-ld"})
+
+	reqRes1 := helper.MockHTTPReqRes{}
+	reqRes1.Err = errors.New("http error")
+	helper.SetClientHTTP(c, reqRes1)
+
+	err := subscriptionsMigrate(c, c.Ngsi, c.Client)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 3, ngsiErr.ErrNo)
+		assert.Equal(t, "http error", ngsiErr.Message)
+	}
+}
+
+func TestSubscriptionsMigrateErrorListDestination(t *testing.T) {
+	c := setupTest([]string{"subscriptions", "migrate", "--host", "orion", "--host2", "orion-ld"})
+
+	reqRes1 := helper.MockHTTPReqRes{}
+	reqRes1.Res.StatusCode = http.StatusOK
+	reqRes1.ResBody = []byte(`[]`)
+	reqRes1.ResHeader = http.Header{"Fiware-Total-Count": []string{"0"}}
+	helper.SetClientHTTP(c, reqRes1)
+
+	reqRes2 := helper.MockHTTPReqRes{}
+	reqRes2.Err = errors.New("http error")
+	mockDest := helper.NewMockHTTP()
+	mockDest.ReqRes = append(mockDest.ReqRes, reqRes2)
+	c.Client2.HTTP = mockDest
+
+	err := subscriptionsMigrate(c, c.Ngsi, c.Client)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 4, ngsiErr.ErrNo)
+		assert.Equal(t, "http error", ngsiErr.Message)
+	}
+}
+
+func TestSubscriptionsMigrateErrorCreate(t *testing.T) {
+	c := setupTest([]string{"subscriptions", "migrate", "--host", "orion", "--host2", "orion-ld", "--run"})
+
+	reqRes1 := helper.MockHTTPReqRes{}
+	reqRes1.Res.StatusCode = http.StatusOK
+	reqRes1.ResBody = []byte(`[{"id":"5f01","subject":{"condition":{"attrs":["price"]}},"notification":{"http":{"url":"http://x/n"}}}]`)
+	reqRes1.ResHeader = http.Header{"Fiware-Total-Count": []string{"1"}}
+	helper.SetClientHTTP(c, reqRes1)
+
+	reqRes2 := helper.MockHTTPReqRes{}
+	reqRes2.Res.StatusCode = http.StatusBadRequest
+	reqRes2.Res.Status = "400 Bad Request"
+	reqRes2.ResBody = []byte("error")
+	c.Client2.HTTP = migrateTestDest(`[]`, "0", reqRes2)
+
+	err := subscriptionsMigrate(c, c.Ngsi, c.Client)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 5, ngsiErr.ErrNo)
+		assert.Equal(t, "400 Bad Request error 5f01", ngsiErr.Message)
+	}
+}
+
+func TestSubscriptionsMigrateListError(t *testing.T) {
+	cases := []struct {
+		reqRes   helper.MockHTTPReqRes
+		errno    int
+		expected string
+	}{
+		{reqRes: helper.MockHTTPReqRes{Err: errors.New("http error")}, errno: 1, expected: "http error"},
+		{reqRes: helper.MockHTTPReqRes{Res: http.Response{StatusCode: http.StatusBadRequest, Status: "400 Bad Request"}, ResBody: []byte("error")}, errno: 2, expected: "400 Bad Request error"},
+		{reqRes: helper.MockHTTPReqRes{Res: http.Response{StatusCode: http.StatusOK}, ResBody: []byte("[]")}, errno: 3, expected: "ResultsCount error"},
+		{reqRes: helper.MockHTTPReqRes{Res: http.Response{StatusCode: http.StatusOK}, ResBody: []byte("{}"), ResHeader: http.Header{"Fiware-Total-Count": []string{"1"}}}, errno: 4},
+	}
+
+	for _, tc := range cases {
+		c := setupTest([]string{"subscriptions", "migrate", "--host", "orion", "--host2", "orion-ld"})
+		helper.SetClientHTTP(c, tc.reqRes)
+
+		_, err := subscriptionsMigrateList(c.Client)
+
+		if assert.Error(t, err) {
+			ngsiErr := err.(*ngsierr.NgsiError)
+			assert.Equal(t, tc.errno, ngsiErr.ErrNo)
+			if tc.expected != "" {
+				assert.Equal(t, tc.expected, ngsiErr.Message)
+			}
+		}
+	}
+}
+
+func TestSubscriptionsMigrateCreateError(t *testing.T) {
+	c := setupTest([]string{"subscriptions", "migrate", "--host", "orion", "--host2", "orion-ld"})
+
+	reqRes := helper.MockHTTPReqRes{Err: errors.New("http error")}
+	helper.SetClientHTTP(c, reqRes)
+
+	_, err := subscriptionsMigrateCreate(c.Client, &subscriptionMigration{id: "5f01", body: &subscriptionV2{}})
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 2, ngsiErr.ErrNo)
+		assert.Equal(t, "http error", ngsiErr.Message)
+	}
+
+	_, err = subscriptionsMigrateCreate(c.Client, &subscriptionMigration{id: "5f01", body: complex(0, 0)})
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 1, ngsiErr.ErrNo)
+	}
+}
+
+func TestMigrateIsEmpty(t *testing.T) {
+	assert.Equal(t, true, migrateIsEmpty(nil))
+	assert.Equal(t, true, migrateIsEmpty(false))
+	assert.Equal(t, true, migrateIsEmpty(""))
+	assert.Equal(t, true, migrateIsEmpty(float64(0)))
+	assert.Equal(t, true, migrateIsEmpty([]interface{}{}))
+	assert.Equal(t, true, migrateIsEmpty(map[string]interface{}{}))
+	assert.Equal(t, false, migrateIsEmpty(true))
+	assert.Equal(t, false, migrateIsEmpty(int64(0)))
+}
@@ -40,6 +40,9 @@ type JsonBuffer struct {
 	buf             []byte
 	delimiter       string
 	closeingBracket string
+	pretty          bool
+	prefix          string
+	elements        bool
 }
 
 func NewJsonBuffer() *JsonBuffer {
@@ -48,10 +51,13 @@ func NewJsonBuffer() *JsonBuffer {
 
 func (j *JsonBuffer) BufferOpen(w io.Writer, geoJSON, pretty bool) {
 	j.writer = bufio.NewWriter(w)
+	j.pretty = pretty
+	j.prefix = "  "
 
 	if geoJSON && pretty {
 		j.delimiter = "{\n  \"type\": \"FeatureCollection\",\n  \"features\": ["
 		j.closeingBracket = "]\n}"
+		j.prefix = "    "
 	} else if geoJSON {
 		j.delimiter = `{"type":"FeatureCollection","features":[`
 		j.closeingBracket = "]}"
@@ -74,10 +80,30 @@ func (j *JsonBuffer) BufferWrite(b []byte) {
 	}
 }
 
+// BufferWriteElement writes an element of the JSON array. The element should be indented with
+// BufferPrefix when the buffer is opened with pretty.
+func (j *JsonBuffer) BufferWriteElement(b []byte) {
+	fmt.Fprint(j.writer, j.delimiter)
+	if j.pretty {
+		fmt.Fprint(j.writer, "\n"+j.prefix)
+	}
+	_, _ = j.writer.Write(b)
+	j.delimiter = ","
+	j.elements = true
+}
+
+// BufferPrefix returns the prefix to indent an element of the JSON array
+func (j *JsonBuffer) BufferPrefix() string {
+	return j.prefix
+}
+
 func (j *JsonBuffer) BufferClose() {
 	if len(j.buf) > 0 {
 		j.BufferWrite(nil)
 	}
+	if j.elements && j.pretty {
+		fmt.Fprint(j.writer, "\n"+j.prefix[2:])
+	}
 	if j.delimiter == "," {
 		fmt.Fprint(j.writer, j.closeingBracket)
 	}
@@ -192,3 +192,61 @@ func TestBufferClose(t *testing.T) {
 		assert.Equal(t, expected, actual)
 	}
 }
+
+func TestBufferWriteElement(t *testing.T) {
+	buf := &bytes.Buffer{}
+
+	jsonBuf := NewJsonBuffer()
+	jsonBuf.BufferOpen(buf, false, false)
+
+	jsonBuf.BufferWriteElement([]byte(`{"id":"abc"}`))
+	jsonBuf.BufferWriteElement([]byte(`{"id":"xyz"}`))
+	jsonBuf.BufferClose()
+
+	actual := buf.String()
+	expected := `[{"id":"abc"},{"id":"xyz"}]`
+	assert.Equal(t, expected, actual)
+}
+
+func TestBufferWriteElementPretty(t *testing.T) {
+	buf := &bytes.Buffer{}
+
+	jsonBuf := NewJsonBuffer()
+	jsonBuf.BufferOpen(buf, false, true)
+
+	assert.Equal(t, "  ", jsonBuf.BufferPrefix())
+
+	jsonBuf.BufferWriteElement([]byte("{\n    \"id\": \"abc\"\n  }"))
+	jsonBuf.BufferWriteElement([]byte("{\n    \"id\": \"xyz\"\n  }"))
+	jsonBuf.BufferClose()
+
+	actual := buf.String()
+	expected := "[\n  {\n    \"id\": \"abc\"\n  },\n  {\n    \"id\": \"xyz\"\n  }\n]"
+	assert.Equal(t, expected, actual)
+}
+
+func TestBufferWriteElementGeoJSONPretty(t *testing.T) {
+	buf := &bytes.Buffer{}
+
+	jsonBuf := NewJsonBuffer()
+	jsonBuf.BufferOpen(buf, true, true)
+
+	assert.Equal(t, "    ", jsonBuf.BufferPrefix())
+
+	jsonBuf.BufferWriteElement([]byte("{\n      \"id\": \"abc\"\n    }"))
+	jsonBuf.BufferClose()
+
+	actual := buf.String()
+	expected := "{\n  \"type\": \"FeatureCollection\",\n  \"features\": [\n    {\n      \"id\": \"abc\"\n    }\n  ]\n}"
+	assert.Equal(t, expected, actual)
+}
+
+func TestBufferWriteElementEmpty(t *testing.T) {
+	buf := &bytes.Buffer{}
+
+	jsonBuf := NewJsonBuffer()
+	jsonBuf.BufferOpen(buf, false, true)
+	jsonBuf.BufferClose()
+
+	assert.Equal(t, "", buf.String())
+}
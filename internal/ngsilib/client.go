@@ -56,6 +56,12 @@ type Client struct {
 	Link          *string
 	HTTP          HTTPRequest
 	Path          string
+	DryRun        bool
+
+	// tokenRefresh is true when the token was obtained from the token cache and can be renewed
+	tokenRefresh bool
+	// protectedProfile is the name of the profile in use when it is protected
+	protectedProfile string
 }
 
 const (
@@ -68,18 +74,7 @@ func (client *Client) InitHeader() error {
 	const funcName = "InitHeader"
 	client.Headers = make(map[string]string)
 
-	if client.Token != "" {
-		if client.Server.ServerType == "keyrock" {
-			client.Headers["X-Auth-Token"] = client.Token
-			client.Headers["X-Subject-token"] = client.Token
-		} else if client.Server.IdmType == CBasic {
-			client.Headers["Authorization"] = "Basic " + client.Token
-		} else if client.XAuthToken || client.Server.IdmType == CThinkingCities {
-			client.Headers["X-Auth-Token"] = client.Token
-		} else {
-			client.Headers["Authorization"] = "Bearer " + client.Token
-		}
-	}
+	client.setTokenHeader()
 	if client.Server.IdmType == CApikey {
 		key, value := GetApikeyHeader(client)
 		client.Headers[key] = value
@@ -118,6 +113,57 @@ func (client *Client) InitHeader() error {
 	return nil
 }
 
+func (client *Client) setTokenHeader() {
+	if client.Token != "" {
+		if client.Server.ServerType == "keyrock" {
+			client.Headers["X-Auth-Token"] = client.Token
+			client.Headers["X-Subject-token"] = client.Token
+		} else if client.Server.IdmType == CBasic {
+			client.Headers["Authorization"] = "Basic " + client.Token
+		} else if client.XAuthToken || client.Server.IdmType == CThinkingCities {
+			client.Headers["X-Auth-Token"] = client.Token
+		} else {
+			client.Headers["Authorization"] = "Bearer " + client.Token
+		}
+	}
+}
+
+// refreshToken renews the token after the broker has rejected it
+func (client *Client) refreshToken() bool {
+	const funcName = "refreshToken"
+
+	if !client.tokenRefresh || gNGSI == nil {
+		return false
+	}
+
+	token, err := gNGSI.RefreshToken(client)
+	if err != nil {
+		gNGSI.Logging(LogErr, ngsierr.SprintMsg(funcName, 1, err.Error())+"\n")
+		return false
+	}
+	gNGSI.Logging(LogInfo, "Token refreshed after 401 Unauthorized\n")
+
+	client.Token = token
+	client.setTokenHeader()
+
+	return true
+}
+
+// confirmProfile asks for confirmation before a request which changes data in a protected profile
+func (client *Client) confirmProfile() error {
+	const funcName = "confirmProfile"
+
+	if client.protectedProfile == "" || gNGSI == nil {
+		return nil
+	}
+
+	if err := gNGSI.confirmProfile(client.protectedProfile); err != nil {
+		return ngsierr.New(funcName, 1, err.Error(), err)
+	}
+
+	return nil
+}
+
 // SetHeaders is ...
 func (client *Client) SetHeaders(headers map[string]string) {
 	for key, value := range headers {
@@ -138,6 +184,21 @@ func (client *Client) RemoveHeader(key string) {
 	}
 }
 
+// Clone returns a copy of the client which can send requests concurrently with the original one
+func (client *Client) Clone() *Client {
+	clone := *client
+
+	u := *client.URL
+	clone.URL = &u
+
+	clone.Headers = make(map[string]string, len(client.Headers))
+	for key, value := range client.Headers {
+		clone.Headers[key] = value
+	}
+
+	return &clone
+}
+
 // SetContentType is ...
 func (client *Client) SetContentType() {
 	client.Headers["Content-Type"] = "application/json"
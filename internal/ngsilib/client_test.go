@@ -30,6 +30,7 @@ SOFTWARE.
 package ngsilib
 
 import (
+	"bytes"
 	"net/http"
 	"net/url"
 	"testing"
@@ -283,6 +284,24 @@ func TestRemoveHeader(t *testing.T) {
 	assert.Equal(t, expected, actual)
 }
 
+func TestClone(t *testing.T) {
+	client := &Client{URL: &url.URL{Scheme: "http", Host: "orion", Path: "/v2/entities"}, Headers: map[string]string{"Fiware-Service": "iot"}}
+	client.Server = &Server{ServerType: "broker"}
+	client.NgsiType = ngsiV2
+
+	actual := client.Clone()
+
+	assert.Equal(t, client, actual)
+
+	actual.SetPath("/op/update")
+	actual.SetContentJSON()
+
+	assert.Equal(t, "/v2/entities", client.URL.Path)
+	assert.Equal(t, map[string]string{"Fiware-Service": "iot"}, client.Headers)
+	assert.Equal(t, "/v2/op/update", actual.URL.Path)
+	assert.Equal(t, client.Server, actual.Server)
+}
+
 func TestSetContentType(t *testing.T) {
 	client := &Client{URL: &url.URL{}, Headers: map[string]string{}}
 	client.Server = &Server{ServerType: "broker"}
@@ -588,3 +607,87 @@ func TestCheckScopeError(t *testing.T) {
 		assert.Equal(t, "error FIWARE ServicePath: fiware", ngsiErr.Message)
 	}
 }
+
+func TestRefreshTokenClient(t *testing.T) {
+	ngsi := testNgsiLibInit()
+	ngsi.tokenList = tokenInfoList{}
+	filename := ""
+	ngsi.CacheFile = &MockIoLib{filename: &filename}
+	ngsi.LogWriter = &bytes.Buffer{}
+	reqRes := MockHTTPReqRes{}
+	reqRes.Res.StatusCode = http.StatusOK
+	reqRes.ResBody = []byte(`{"access_token": "new", "expires_in": 3599, "refresh_token": "refresh", "token_type": "Bearer" }`)
+	mock := NewMockHTTP()
+	mock.ReqRes = append(mock.ReqRes, reqRes)
+	ngsi.HTTP = mock
+
+	client := &Client{Server: &Server{ServerHost: "http://orion/", IdmType: CTokenproxy, Username: "fiware", Password: "1234"}, Headers: map[string]string{}, Token: "old", tokenRefresh: true}
+
+	actual := client.refreshToken()
+
+	assert.Equal(t, true, actual)
+	assert.Equal(t, "new", client.Token)
+	assert.Equal(t, "Bearer new", client.Headers["Authorization"])
+}
+
+func TestRefreshTokenClientNotRefreshable(t *testing.T) {
+	testNgsiLibInit()
+
+	client := &Client{Server: &Server{ServerHost: "http://orion/", IdmType: CTokenproxy}, Headers: map[string]string{}, Token: "token"}
+
+	actual := client.refreshToken()
+
+	assert.Equal(t, false, actual)
+	assert.Equal(t, "token", client.Token)
+}
+
+func TestRefreshTokenClientError(t *testing.T) {
+	ngsi := testNgsiLibInit()
+	buf := &bytes.Buffer{}
+	ngsi.LogWriter = buf
+	ngsi.tokenList = tokenInfoList{}
+
+	client := &Client{Server: &Server{ServerHost: "http://orion/", IdmType: "unknown"}, Headers: map[string]string{}, Token: "old", tokenRefresh: true}
+
+	actual := client.refreshToken()
+
+	assert.Equal(t, false, actual)
+	assert.Equal(t, "old", client.Token)
+}
+
+func TestConfirmProfileClient(t *testing.T) {
+	ngsi := testNgsiLibInit()
+	ngsi.ConfigFile = &MockIoLib{Env: "prod"}
+
+	client := &Client{protectedProfile: "prod"}
+
+	err := client.confirmProfile()
+
+	assert.NoError(t, err)
+}
+
+func TestConfirmProfileClientNotProtected(t *testing.T) {
+	testNgsiLibInit()
+
+	client := &Client{}
+
+	err := client.confirmProfile()
+
+	assert.NoError(t, err)
+}
+
+func TestConfirmProfileClientError(t *testing.T) {
+	ngsi := testNgsiLibInit()
+	ngsi.ConfigFile = &MockIoLib{}
+	ngsi.TermLib = &MockTermLib{}
+
+	client := &Client{protectedProfile: "prod"}
+
+	err := client.confirmProfile()
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 1, ngsiErr.ErrNo)
+		assert.Equal(t, "profile prod is protected. set NGSI_GO_PROFILE_CONFIRM to the profile name to confirm", ngsiErr.Message)
+	}
+}
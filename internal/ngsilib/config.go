@@ -48,15 +48,21 @@ type Settings struct {
 	Tenant          string `json:"tenant"`
 	Scope           string `json:"scope"`
 	Token           string `json:"token"`
+	RetryMax        string `json:"retryMax,omitempty"`
+	RetryBackoff    string `json:"retryBackoff,omitempty"`
+	RetryOn         string `json:"retryOn,omitempty"`
 }
 
 // NgsiConfig is ...
 type NgsiConfig struct {
-	Version           string       `json:"version"`
-	DefaultValues     Settings     `json:"settings"`
-	DeprecatedBrokers ServerList   `json:"brokers,omitempty"`
-	Servers           ServerList   `json:"servers"`
-	Contexts          ContextsInfo `json:"contexts"`
+	Version           string         `json:"version"`
+	DefaultValues     Settings       `json:"settings"`
+	DeprecatedBrokers ServerList     `json:"brokers,omitempty"`
+	Servers           ServerList     `json:"servers"`
+	Contexts          ContextsInfo   `json:"contexts"`
+	Secrets           *SecretsConfig `json:"secrets,omitempty"`
+	Profile           string         `json:"profile,omitempty"`
+	Profiles          Profiles       `json:"profiles,omitempty"`
 }
 
 // var configFile string
@@ -74,6 +80,9 @@ func initConfig(ngsi *NGSI, io IoLib) error {
 	const funcName = "initConfig"
 
 	saveFlag := false
+	var secrets *SecretsConfig
+	var profiles Profiles
+	var profile string
 
 	if io.FileName() == nil {
 		home, err := getConfigDir(ngsi.ConfigDir, io)
@@ -119,6 +128,9 @@ func initConfig(ngsi *NGSI, io IoLib) error {
 		}
 		ngsi.ServerList = ngsiConfig.Servers
 		ngsi.contextList = ngsiConfig.Contexts
+		secrets = ngsiConfig.Secrets
+		profiles = ngsiConfig.Profiles
+		profile = ngsiConfig.Profile
 	}
 
 	if ngsi.configVresion != "1" {
@@ -136,10 +148,14 @@ func initConfig(ngsi *NGSI, io IoLib) error {
 		ngsi.contextList["ld"] = "https://schema.lab.fiware.org/ld/context"
 	}
 
+	if err := ngsi.initProfiles(profiles, profile); err != nil {
+		return ngsierr.New(funcName, 6, err.Error(), err)
+	}
+
 	errflag := false
 	for k, v := range ngsi.ServerList {
 		if err := gNGSI.checkAllParams(v); err != nil {
-			fmt.Fprintf(gNGSI.LogWriter, "%s in %s\n", err, k)
+			fmt.Fprintf(gNGSI.LogWriter, "%s in %s\n", err, profileHost(ngsi.profile, k))
 			errflag = true
 		}
 	}
@@ -162,12 +178,16 @@ func initConfig(ngsi *NGSI, io IoLib) error {
 		}
 	}
 	if errflag {
-		return ngsierr.New(funcName, 6, "error in config file", nil)
+		return ngsierr.New(funcName, 7, "error in config file", nil)
+	}
+
+	if err := ngsi.initSecrets(secrets); err != nil {
+		return ngsierr.New(funcName, 8, err.Error(), err)
 	}
 
 	if saveFlag {
 		if err := ngsi.saveConfigFile(); err != nil {
-			return ngsierr.New(funcName, 7, err.Error(), err)
+			return ngsierr.New(funcName, 9, err.Error(), err)
 		}
 	}
 	return nil
@@ -183,16 +203,46 @@ func (ngsi *NGSI) saveConfigFile() (err error) {
 		return nil
 	}
 
+	ngsi.activeProfile()
+
+	settings := *ngsi.PreviousArgs
+	profiles := make(Profiles)
+	for name, p := range ngsi.profiles {
+		servers, err := ngsi.sealServerList(name, p.Servers)
+		if err != nil {
+			return ngsierr.New(funcName, 1, err.Error(), err)
+		}
+		profile := *p
+		profile.Servers = servers
+		profiles[name] = &profile
+	}
+	def := profiles[DefaultProfile]
+	delete(profiles, DefaultProfile)
+	if !ngsi.isBatch() {
+		settings.Host = def.Host
+		settings.Tenant = def.Tenant
+		settings.Scope = def.Scope
+	}
+
 	config := make(map[string]interface{})
 
 	config["version"] = ngsi.configVresion
-	config["settings"] = *ngsi.PreviousArgs
-	config["servers"] = ngsi.ServerList
+	config["settings"] = settings
+	config["servers"] = def.Servers
 	config["contexts"] = ngsi.contextList
+	if ngsi.secretsConfig != nil {
+		config["secrets"] = ngsi.secretsConfig
+	}
+	if ngsi.currentProfile != "" {
+		config["profile"] = ngsi.currentProfile
+	}
+	if len(profiles) > 0 {
+		config["profiles"] = profiles
+	}
 
 	err = io.OpenFile(oWRONLY|oCREATE, 0600)
 	if err != nil {
-		return ngsierr.New(funcName, 1, err.Error(), err)
+		return ngsierr.New(funcName, 2, err.Error(), err)
 	}
 	defer func() { _ = io.Close() }()
 
@@ -32,6 +32,7 @@ package ngsilib
 import (
 	"bytes"
 	"errors"
+	"strings"
 	"testing"
 
 	"github.com/lets-fiware/ngsi-go/internal/assert"
@@ -236,6 +237,74 @@ func TestIntiConfigErrorVersion(t *testing.T) {
 	}
 }
 
+var configProfileData = `{
+	"version": "1",
+	"settings": {"usePreviousArgs": true, "host": "orion"},
+	"servers": {"orion": {"serverHost": "http://orion-dev:1026", "serverType": "broker", "ngsiType": "v2"}},
+	"profile": "prod",
+	"profiles": {
+		"prod": {
+			"protected": true,
+			"host": "orion",
+			"tenant": "city",
+			"servers": {"orion": {"serverHost": "http://orion-prod:1026", "serverType": "broker", "ngsiType": "v2"}}
+		}
+	}
+}`
+
+func TestIntiConfigProfile(t *testing.T) {
+	ngsi := testNgsiLibInit()
+	ngsi.ConfigFile = &MockIoLib{}
+	filename := "config"
+	ngsi.ConfigFile.SetFileName(&filename)
+	ngsi.FileReader = &MockFileLib{ReadFileData: []byte(configProfileData)}
+
+	err := initConfig(ngsi, ngsi.ConfigFile)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, "prod", ngsi.CurrentProfile())
+		assert.Equal(t, "http://orion-prod:1026", ngsi.ServerList["orion"].ServerHost)
+		assert.Equal(t, "orion", ngsi.PreviousArgs.Host)
+		assert.Equal(t, "city", ngsi.PreviousArgs.Tenant)
+	}
+}
+
+func TestIntiConfigProfileFlag(t *testing.T) {
+	ngsi := testNgsiLibInit()
+	ngsi.ConfigFile = &MockIoLib{}
+	filename := "config"
+	ngsi.ConfigFile.SetFileName(&filename)
+	ngsi.FileReader = &MockFileLib{ReadFileData: []byte(configProfileData)}
+	profile := "default"
+	ngsi.ProfileFlag = &profile
+
+	err := initConfig(ngsi, ngsi.ConfigFile)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, "default", ngsi.CurrentProfile())
+		assert.Equal(t, "http://orion-dev:1026", ngsi.ServerList["orion"].ServerHost)
+		assert.Equal(t, "", ngsi.PreviousArgs.Tenant)
+	}
+}
+
+func TestIntiConfigErrorProfile(t *testing.T) {
+	ngsi := testNgsiLibInit()
+	ngsi.ConfigFile = &MockIoLib{}
+	filename := "config"
+	ngsi.ConfigFile.SetFileName(&filename)
+	ngsi.FileReader = &MockFileLib{ReadFileData: []byte(configProfileData)}
+	profile := "staging"
+	ngsi.ProfileFlag = &profile
+
+	err := initConfig(ngsi, ngsi.ConfigFile)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 6, ngsiErr.ErrNo)
+		assert.Equal(t, "profile not found: staging", ngsiErr.Message)
+	}
+}
+
 func TestIntiConfigErrorParam(t *testing.T) {
 	ngsi := testNgsiLibInit()
 	ngsi.ConfigFile = &MockIoLib{}
@@ -251,7 +320,7 @@ func TestIntiConfigErrorParam(t *testing.T) {
 
 	if assert.Error(t, err) {
 		ngsiErr := err.(*ngsierr.NgsiError)
-		assert.Equal(t, 6, ngsiErr.ErrNo)
+		assert.Equal(t, 7, ngsiErr.ErrNo)
 		assert.Equal(t, "error in config file", ngsiErr.Message)
 	}
 }
@@ -270,7 +339,7 @@ func TestIntiConfigErrorContext(t *testing.T) {
 
 	if assert.Error(t, err) {
 		ngsiErr := err.(*ngsierr.NgsiError)
-		assert.Equal(t, 6, ngsiErr.ErrNo)
+		assert.Equal(t, 7, ngsiErr.ErrNo)
 		assert.Equal(t, "error in config file", ngsiErr.Message)
 	}
 }
@@ -289,7 +358,7 @@ func TestIntiConfigContext(t *testing.T) {
 
 	if assert.Error(t, err) {
 		ngsiErr := err.(*ngsierr.NgsiError)
-		assert.Equal(t, 6, ngsiErr.ErrNo)
+		assert.Equal(t, 7, ngsiErr.ErrNo)
 		assert.Equal(t, "error in config file", ngsiErr.Message)
 	}
 }
@@ -306,11 +375,44 @@ func TestIntiConfigErrorSaveError(t *testing.T) {
 
 	if assert.Error(t, err) {
 		ngsiErr := err.(*ngsierr.NgsiError)
-		assert.Equal(t, 7, ngsiErr.ErrNo)
+		assert.Equal(t, 9, ngsiErr.ErrNo)
 		assert.Equal(t, "error", ngsiErr.Message)
 	}
 }
 
+func TestIntiConfigSecrets(t *testing.T) {
+	ngsi := testNgsiLibInit()
+	filename := "config.json"
+	ngsi.ConfigFile = &MockIoLib{}
+	ngsi.ConfigFile.SetFileName(&filename)
+	config := `{"version":"1","servers":{"orion":{"serverType":"broker","serverHost":"http://orion:1026","idmType":"basic","username":"fiware","password":"helper:orion/password"}},"secrets":{"backend":"helper","helper":"ngsi-helper"}}`
+	ngsi.FileReader = &MockFileLib{ReadFileData: []byte(config)}
+
+	err := initConfig(ngsi, ngsi.ConfigFile)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, "ngsi-helper", ngsi.SecretsConfig().Helper)
+		assert.Equal(t, "helper:orion/password", ngsi.loadedSecrets["orion/password"])
+	}
+}
+
+func TestIntiConfigErrorSecrets(t *testing.T) {
+	ngsi := testNgsiLibInit()
+	filename := "config.json"
+	ngsi.ConfigFile = &MockIoLib{}
+	ngsi.ConfigFile.SetFileName(&filename)
+	config := `{"version":"1","secrets":{"backend":"vault"}}`
+	ngsi.FileReader = &MockFileLib{ReadFileData: []byte(config)}
+
+	err := initConfig(ngsi, ngsi.ConfigFile)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 8, ngsiErr.ErrNo)
+		assert.Equal(t, "unknown secrets backend: vault", ngsiErr.Message)
+	}
+}
+
 func TestSaveConfigFile(t *testing.T) {
 	ngsi := testNgsiLibInit()
 	ngsi.ConfigFile = &MockIoLib{}
@@ -321,6 +423,90 @@ func TestSaveConfigFile(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestSaveConfigFileSecrets(t *testing.T) {
+	ngsi := testNgsiLibInit()
+	configFile := &MockIoLib{}
+	ngsi.ConfigFile = configFile
+	filename := "config"
+	ngsi.ConfigFile.SetFileName(&filename)
+	ngsi.ExecLib = &MockExecLib{}
+	ngsi.ServerList = ServerList{"orion": &Server{Password: "1234"}}
+	_ = ngsi.initSecrets(&SecretsConfig{Backend: CSecretsHelper, Helper: "ngsi-helper"})
+
+	err := ngsi.saveConfigFile()
+
+	if assert.NoError(t, err) {
+		s := string(configFile.Encoded)
+		assert.Equal(t, true, strings.Contains(s, `"password":"helper:orion/password"`))
+		assert.Equal(t, true, strings.Contains(s, `"secrets":{"backend":"helper","helper":"ngsi-helper"}`))
+		assert.Equal(t, "1234", ngsi.ServerList["orion"].Password)
+	}
+}
+
+func TestSaveConfigFileProfile(t *testing.T) {
+	ngsi := testNgsiLibInit()
+	configFile := &MockIoLib{}
+	ngsi.ConfigFile = configFile
+	filename := "config"
+	ngsi.ConfigFile.SetFileName(&filename)
+	ngsi.FileReader = &MockFileLib{ReadFileData: []byte(configProfileData)}
+	_ = initConfig(ngsi, ngsi.ConfigFile)
+	ngsi.PreviousArgs.Tenant = "town"
+
+	err := ngsi.saveConfigFile()
+
+	if assert.NoError(t, err) {
+		config := NgsiConfig{}
+		_ = JSONUnmarshal(configFile.Encoded, &config)
+		assert.Equal(t, "prod", config.Profile)
+		assert.Equal(t, "orion", config.DefaultValues.Host)
+		assert.Equal(t, "", config.DefaultValues.Tenant)
+		assert.Equal(t, "http://orion-dev:1026", config.Servers["orion"].ServerHost)
+		assert.Equal(t, "town", config.Profiles["prod"].Tenant)
+		assert.Equal(t, true, config.Profiles["prod"].Protected)
+		assert.Equal(t, "http://orion-prod:1026", config.Profiles["prod"].Servers["orion"].ServerHost)
+	}
+}
+
+func TestSaveConfigFileProfileSecrets(t *testing.T) {
+	ngsi := testNgsiLibInit()
+	configFile := &MockIoLib{}
+	ngsi.ConfigFile = configFile
+	filename := "config"
+	ngsi.ConfigFile.SetFileName(&filename)
+	ngsi.ExecLib = &MockExecLib{}
+	ngsi.ServerList = ServerList{"orion": &Server{Password: "1234"}}
+	ngsi.profiles = Profiles{"prod": &Profile{Servers: ServerList{"orion": &Server{Password: "5678"}}}}
+	ngsi.profile = DefaultProfile
+	_ = ngsi.initSecrets(&SecretsConfig{Backend: CSecretsHelper, Helper: "ngsi-helper"})
+
+	err := ngsi.saveConfigFile()
+
+	if assert.NoError(t, err) {
+		s := string(configFile.Encoded)
+		assert.Equal(t, true, strings.Contains(s, `"password":"helper:orion/password"`))
+		assert.Equal(t, true, strings.Contains(s, `"password":"helper:prod/orion/password"`))
+	}
+}
+
+func TestSaveConfigFileErrorSecrets(t *testing.T) {
+	ngsi := testNgsiLibInit()
+	ngsi.ConfigFile = &MockIoLib{}
+	filename := "config"
+	ngsi.ConfigFile.SetFileName(&filename)
+	ngsi.ExecLib = &MockExecLib{Err: errors.New("exec error")}
+	ngsi.ServerList = ServerList{"orion": &Server{Password: "1234"}}
+	_ = ngsi.initSecrets(&SecretsConfig{Backend: CSecretsHelper, Helper: "ngsi-helper"})
+
+	err := ngsi.saveConfigFile()
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 1, ngsiErr.ErrNo)
+		assert.Equal(t, "ngsi-helper store: exec error", ngsiErr.Message)
+	}
+}
+
 func TestSaveConfigFileErrorOpenFile(t *testing.T) {
 	ngsi := testNgsiLibInit()
 	ngsi.ConfigFile = &MockIoLib{OpenErr: errors.New("open error")}
@@ -330,7 +516,7 @@ func TestSaveConfigFileErrorOpenFile(t *testing.T) {
 	err := ngsi.saveConfigFile()
 	if assert.Error(t, err) {
 		ngsiErr := err.(*ngsierr.NgsiError)
-		assert.Equal(t, 1, ngsiErr.ErrNo)
+		assert.Equal(t, 2, ngsiErr.ErrNo)
 		assert.Equal(t, "open error", ngsiErr.Message)
 	}
 }
@@ -0,0 +1,100 @@
+/*
+MIT License
+
+Copyright (c) 2020-2024 Kazuhito Suda
+
+This file is part of NGSI Go
+
+https://github.com/lets-fiware/ngsi-go
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+
+*/
+
+package ngsilib
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/lets-fiware/ngsi-go/internal/ngsierr"
+)
+
+// ErrDryRun is returned instead of the response of a request that changes data when --dryRun is set
+var ErrDryRun = errors.New("dry run")
+
+var dryRunMutex sync.Mutex
+
+// IsDryRun reports whether err is caused by a request not sent with --dryRun
+func IsDryRun(err error) bool {
+	return errors.Is(err, ErrDryRun)
+}
+
+// dryRun prints a request instead of sending it. It is called only by the methods which send requests
+// that change data, so ErrDryRun never hides the response of a query. It is called by workers concurrently.
+func (client *Client) dryRun(method string, body interface{}) (*http.Response, []byte, error) {
+	const funcName = "dryRun"
+
+	var b []byte
+	if body != nil {
+		r, err := newReader(body)
+		if err != nil {
+			return nil, nil, ngsierr.New(funcName, 1, err.Error(), err)
+		}
+		b, _ = io.ReadAll(r)
+	}
+
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "%s %s\n", method, client.URL.String())
+
+	keys := make([]string, 0, len(client.Headers))
+	for k := range client.Headers {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Fprintf(&sb, "%s: %s\n", k, redactHeader(k, client.Headers[k]))
+	}
+
+	if len(b) > 0 {
+		fmt.Fprintf(&sb, "\n%s\n", string(b))
+	}
+	sb.WriteString("\n")
+
+	dryRunMutex.Lock()
+	defer dryRunMutex.Unlock()
+
+	fmt.Fprint(gNGSI.StdWriter, sb.String())
+
+	return nil, nil, ErrDryRun
+}
+
+func redactHeader(key, value string) string {
+	k := strings.ToLower(key)
+	if k == "authorization" || k == "proxy-authorization" || k == "cookie" || strings.Contains(k, "token") || strings.Contains(k, "key") {
+		return "***"
+	}
+	return value
+}
@@ -0,0 +1,148 @@
+/*
+MIT License
+
+Copyright (c) 2020-2024 Kazuhito Suda
+
+This file is part of NGSI Go
+
+https://github.com/lets-fiware/ngsi-go
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+
+*/
+
+package ngsilib
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/lets-fiware/ngsi-go/internal/assert"
+	"github.com/lets-fiware/ngsi-go/internal/ngsierr"
+)
+
+func testDryRunClient() (*Client, *bytes.Buffer) {
+	ngsi := testNgsiLibInit()
+	buf := &bytes.Buffer{}
+	ngsi.StdWriter = buf
+
+	u, _ := url.Parse("http://orion/v2/entities?options=keyValues")
+	client := &Client{URL: u, DryRun: true, Headers: map[string]string{
+		"Content-Type":       "application/json",
+		"Authorization":      "Bearer 1234",
+		"Fiware-Service":     "openiot",
+		"X-Auth-Token":       "5678",
+		"Fiware-ServicePath": "/",
+	}}
+
+	return client, buf
+}
+
+func TestDryRunHTTPPost(t *testing.T) {
+	client, buf := testDryRunClient()
+
+	res, body, err := client.HTTPPost([]byte(`{"id":"device001","type":"Device"}`))
+
+	assert.Equal(t, ErrDryRun, err)
+	assert.Equal(t, (*http.Response)(nil), res)
+	assert.Equal(t, []byte(nil), body)
+	expected := "POST http://orion/v2/entities?options=keyValues\n" +
+		"Authorization: ***\n" +
+		"Content-Type: application/json\n" +
+		"Fiware-Service: openiot\n" +
+		"Fiware-ServicePath: /\n" +
+		"X-Auth-Token: ***\n" +
+		"\n" +
+		"{\"id\":\"device001\",\"type\":\"Device\"}\n" +
+		"\n"
+	assert.Equal(t, expected, buf.String())
+}
+
+func TestDryRunHTTPPut(t *testing.T) {
+	client, buf := testDryRunClient()
+	client.Headers = map[string]string{}
+
+	_, _, err := client.HTTPPut("abc")
+
+	assert.Equal(t, ErrDryRun, err)
+	assert.Equal(t, "PUT http://orion/v2/entities?options=keyValues\n\nabc\n\n", buf.String())
+}
+
+func TestDryRunHTTPPatch(t *testing.T) {
+	client, buf := testDryRunClient()
+	client.Headers = map[string]string{}
+
+	_, _, err := client.HTTPPatch([]byte(`{}`))
+
+	assert.Equal(t, ErrDryRun, err)
+	assert.Equal(t, "PATCH http://orion/v2/entities?options=keyValues\n\n{}\n\n", buf.String())
+}
+
+func TestDryRunHTTPDelete(t *testing.T) {
+	client, buf := testDryRunClient()
+	client.Headers = map[string]string{}
+
+	_, _, err := client.HTTPDelete(nil)
+
+	assert.Equal(t, ErrDryRun, err)
+	assert.Equal(t, "DELETE http://orion/v2/entities?options=keyValues\n\n", buf.String())
+}
+
+func TestDryRunErrorBody(t *testing.T) {
+	client, buf := testDryRunClient()
+
+	_, _, err := client.HTTPPost(1)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 1, ngsiErr.ErrNo)
+		assert.Equal(t, "unsupported type", ngsiErr.Message)
+		assert.Equal(t, "", buf.String())
+	}
+}
+
+func TestIsDryRun(t *testing.T) {
+	err := ngsierr.New("test", 1, ErrDryRun.Error(), ErrDryRun)
+
+	assert.Equal(t, true, IsDryRun(err))
+	assert.Equal(t, false, IsDryRun(errors.New("error")))
+	assert.Equal(t, false, IsDryRun(nil))
+}
+
+func TestRedactHeader(t *testing.T) {
+	cases := []struct {
+		key      string
+		expected string
+	}{
+		{key: "Authorization", expected: "***"},
+		{key: "Proxy-Authorization", expected: "***"},
+		{key: "Cookie", expected: "***"},
+		{key: "X-Auth-Token", expected: "***"},
+		{key: "X-Subject-token", expected: "***"},
+		{key: "Fiware-Apikey", expected: "***"},
+		{key: "Fiware-Service", expected: "value"},
+	}
+
+	for _, c := range cases {
+		assert.Equal(t, c.expected, redactHeader(c.key, "value"))
+	}
+}
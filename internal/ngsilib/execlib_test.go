@@ -0,0 +1,60 @@
+/*
+MIT License
+
+Copyright (c) 2020-2024 Kazuhito Suda
+
+This file is part of NGSI Go
+
+https://github.com/lets-fiware/ngsi-go
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+
+*/
+
+package ngsilib
+
+import (
+	"testing"
+
+	"github.com/lets-fiware/ngsi-go/internal/assert"
+)
+
+func TestNewExecLib(t *testing.T) {
+	actual := NewExecLib()
+
+	assert.NotEqual(t, nil, actual)
+}
+
+func TestExecLibOutput(t *testing.T) {
+	e := &execLib{}
+
+	actual, err := e.Output("sh", []string{"-c", "cat; echo $0", "ngsi"}, []byte("fiware "))
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, "fiware ngsi\n", string(actual))
+	}
+}
+
+func TestExecLibOutputError(t *testing.T) {
+	e := &execLib{}
+
+	_, err := e.Output("sh", []string{"-c", "exit 1"}, nil)
+
+	assert.Error(t, err)
+}
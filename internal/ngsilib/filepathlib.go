@@ -36,6 +36,7 @@ type FilePathLib interface {
 	FilePathAbs(path string) (string, error)
 	FilePathJoin(elem ...string) string
 	FilePathBase(path string) string
+	FilePathGlob(pattern string) ([]string, error)
 }
 
 type filePathLib struct {
@@ -52,3 +53,7 @@ func (i *filePathLib) FilePathJoin(elem ...string) string {
 func (i *filePathLib) FilePathBase(path string) string {
 	return filepath.Base(path)
 }
+
+func (i *filePathLib) FilePathGlob(pattern string) ([]string, error) {
+	return filepath.Glob(pattern)
+}
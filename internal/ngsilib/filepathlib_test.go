@@ -48,3 +48,9 @@ func TestFilePathLibFilePathBase(t *testing.T) {
 
 	_ = f.FilePathBase("")
 }
+
+func TestFilePathLibFilePathGlob(t *testing.T) {
+	f := filePathLib{}
+
+	_, _ = f.FilePathGlob("")
+}
@@ -0,0 +1,149 @@
+/*
+MIT License
+
+Copyright (c) 2020-2024 Kazuhito Suda
+
+This file is part of NGSI Go
+
+https://github.com/lets-fiware/ngsi-go
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+
+*/
+
+package ngsilib
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"text/template"
+
+	"github.com/lets-fiware/ngsi-go/internal/ngsierr"
+)
+
+// FilterWriter prints the results of a filter or a template applied to each JSON value in the output
+// of a command. The values are processed as soon as they are complete, so that the output of commands
+// running until interrupted, e.g. watch and receiver, is printed while they run.
+type FilterWriter struct {
+	Writer   io.Writer
+	Filter   *JSONFilter
+	Template *template.Template
+	Pretty   bool
+	pw       *io.PipeWriter
+	done     chan error
+	once     sync.Once
+	err      error
+}
+
+// NewFilterWriter returns a FilterWriter printing to w. Set Pretty before writing and call Flush when
+// the command ends.
+func NewFilterWriter(w io.Writer, filter *JSONFilter, tmpl *template.Template) *FilterWriter {
+	pr, pw := io.Pipe()
+	f := &FilterWriter{Writer: w, Filter: filter, Template: tmpl, pw: pw, done: make(chan error, 1)}
+
+	go func() {
+		err := f.print(pr)
+		_ = pr.CloseWithError(err)
+		f.done <- err
+	}()
+
+	return f
+}
+
+// Write passes p to the decoder. It returns an error when the output printed so far cannot be processed.
+func (w *FilterWriter) Write(p []byte) (int, error) {
+	return w.pw.Write(p)
+}
+
+// Flush processes the rest of the output and waits until the results are printed.
+func (w *FilterWriter) Flush() error {
+	w.once.Do(func() {
+		_ = w.pw.Close()
+		w.err = <-w.done
+	})
+	return w.err
+}
+
+// print applies the filter to each JSON value read from r and prints the results.
+// Strings are printed without quotes. With a template, each item of the results is rendered instead.
+func (w *FilterWriter) print(r io.Reader) error {
+	const funcName = "print"
+
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+
+	for {
+		var v interface{}
+		err := dec.Decode(&v)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return ngsierr.New(funcName, 1, "output is not JSON: "+err.Error(), err)
+		}
+
+		results := []interface{}{v}
+		if w.Filter != nil {
+			results, err = w.Filter.Apply(v)
+			if err != nil {
+				return ngsierr.New(funcName, 2, err.Error(), err)
+			}
+		}
+
+		if w.Template != nil {
+			for _, r := range results {
+				for _, item := range templateItems(r) {
+					s, err := templateExecute(w.Template, item)
+					if err != nil {
+						return ngsierr.New(funcName, 5, err.Error(), err)
+					}
+					if !strings.HasSuffix(s, "\n") {
+						s += "\n"
+					}
+					fmt.Fprint(w.Writer, s)
+				}
+			}
+			continue
+		}
+
+		for _, r := range results {
+			if s, ok := r.(string); ok {
+				fmt.Fprintln(w.Writer, s)
+				continue
+			}
+			b, err := JSONMarshal(r)
+			if err != nil {
+				return ngsierr.New(funcName, 3, err.Error(), err)
+			}
+			if w.Pretty {
+				newBuf := new(bytes.Buffer)
+				err := gNGSI.JSONConverter.Indent(newBuf, b, "", "  ")
+				if err != nil {
+					return ngsierr.New(funcName, 4, err.Error(), err)
+				}
+				b = newBuf.Bytes()
+			}
+			fmt.Fprintln(w.Writer, string(b))
+		}
+	}
+}
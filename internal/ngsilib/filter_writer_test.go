@@ -0,0 +1,268 @@
+/*
+MIT License
+
+Copyright (c) 2020-2024 Kazuhito Suda
+
+This file is part of NGSI Go
+
+https://github.com/lets-fiware/ngsi-go
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+
+*/
+
+package ngsilib
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/lets-fiware/ngsi-go/internal/assert"
+	"github.com/lets-fiware/ngsi-go/internal/ngsierr"
+)
+
+func TestFilterWriter(t *testing.T) {
+	testNgsiLibInit()
+
+	buf := &bytes.Buffer{}
+	filter, _ := NewJSONFilter(".[] | {id, n: .n}")
+	w := NewFilterWriter(buf, filter, nil)
+
+	fmt.Fprint(w, `[{"id":"a<b","n":12345678901234567890},`)
+	fmt.Fprint(w, `{"id":"c","n":1.50}]`)
+
+	err := w.Flush()
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, "{\"id\":\"a<b\",\"n\":12345678901234567890}\n{\"id\":\"c\",\"n\":1.50}\n", buf.String())
+	}
+}
+
+func TestFilterWriterLines(t *testing.T) {
+	testNgsiLibInit()
+
+	buf := &bytes.Buffer{}
+	filter, _ := NewJSONFilter(".id")
+	w := NewFilterWriter(buf, filter, nil)
+
+	fmt.Fprintln(w, `{"id":"a"}`)
+	fmt.Fprintln(w, `{"id":"b"}`)
+	fmt.Fprintln(w, `{"type":"T"}`)
+
+	err := w.Flush()
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, "a\nb\nnull\n", buf.String())
+	}
+}
+
+func TestFilterWriterPretty(t *testing.T) {
+	testNgsiLibInit()
+
+	buf := &bytes.Buffer{}
+	filter, _ := NewJSONFilter(".a")
+	w := NewFilterWriter(buf, filter, nil)
+	w.Pretty = true
+
+	fmt.Fprintln(w, "{\n  \"a\": {\"b\": [1, 2]}\n}")
+
+	err := w.Flush()
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, "{\n  \"b\": [\n    1,\n    2\n  ]\n}\n", buf.String())
+	}
+}
+
+func TestFilterWriterErrorNotJSON(t *testing.T) {
+	testNgsiLibInit()
+
+	buf := &bytes.Buffer{}
+	filter, _ := NewJSONFilter(".")
+	w := NewFilterWriter(buf, filter, nil)
+
+	fmt.Fprintln(w, "urn:ngsi-ld:Device:001")
+
+	err := w.Flush()
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 1, ngsiErr.ErrNo)
+		assert.Equal(t, "output is not JSON: invalid character 'u' looking for beginning of value", ngsiErr.Message)
+	}
+}
+
+func TestFilterWriterErrorApply(t *testing.T) {
+	testNgsiLibInit()
+
+	buf := &bytes.Buffer{}
+	filter, _ := NewJSONFilter(".[]")
+	w := NewFilterWriter(buf, filter, nil)
+
+	fmt.Fprintln(w, `"abc"`)
+
+	err := w.Flush()
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 2, ngsiErr.ErrNo)
+		assert.Equal(t, "cannot iterate over string", ngsiErr.Message)
+	}
+}
+
+func TestFilterWriterErrorMarshal(t *testing.T) {
+	ngsi := testNgsiLibInit()
+	SetJSONEncodeErr(ngsi, 0)
+
+	buf := &bytes.Buffer{}
+	filter, _ := NewJSONFilter(".")
+	w := NewFilterWriter(buf, filter, nil)
+
+	fmt.Fprintln(w, `{}`)
+
+	err := w.Flush()
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 3, ngsiErr.ErrNo)
+		assert.Equal(t, "json error", ngsiErr.Message)
+	}
+}
+
+func TestFilterWriterErrorIndent(t *testing.T) {
+	ngsi := testNgsiLibInit()
+	SetJSONIndentError(ngsi)
+
+	buf := &bytes.Buffer{}
+	filter, _ := NewJSONFilter(".")
+	w := NewFilterWriter(buf, filter, nil)
+	w.Pretty = true
+
+	fmt.Fprintln(w, `{}`)
+
+	err := w.Flush()
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 4, ngsiErr.ErrNo)
+		assert.Equal(t, "json error", ngsiErr.Message)
+	}
+}
+
+func TestFilterWriterTemplate(t *testing.T) {
+	testNgsiLibInit()
+
+	buf := &bytes.Buffer{}
+	tmpl, _ := NewTemplate("{{.id}} {{.n}}")
+	w := NewFilterWriter(buf, nil, tmpl)
+
+	fmt.Fprint(w, `[{"id":"a","n":1.50},{"id":"b"}]`)
+	fmt.Fprint(w, `{"count":1,"devices":[{"id":"c","n":2}]}`)
+
+	err := w.Flush()
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, "a 1.50\nb \nc 2\n", buf.String())
+	}
+}
+
+func TestFilterWriterFilterTemplate(t *testing.T) {
+	testNgsiLibInit()
+
+	buf := &bytes.Buffer{}
+	filter, _ := NewJSONFilter(".[] | select(.n > 1)")
+	tmpl, _ := NewTemplate("{{.id}}\n")
+	w := NewFilterWriter(buf, filter, tmpl)
+
+	fmt.Fprint(w, `[{"id":"a","n":1},{"id":"b","n":2}]`)
+
+	err := w.Flush()
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, "b\n", buf.String())
+	}
+}
+
+func TestFilterWriterErrorTemplate(t *testing.T) {
+	testNgsiLibInit()
+
+	buf := &bytes.Buffer{}
+	tmpl, _ := NewTemplate("{{.id.x}}")
+	w := NewFilterWriter(buf, nil, tmpl)
+
+	fmt.Fprint(w, `[{"id":"a"}]`)
+
+	err := w.Flush()
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 5, ngsiErr.ErrNo)
+		assert.Equal(t, `template: template:1:5: executing "template" at <.id.x>: can't evaluate field x in type interface {}`, ngsiErr.Message)
+	}
+}
+
+type chanWriter chan string
+
+func (w chanWriter) Write(p []byte) (int, error) {
+	w <- string(p)
+	return len(p), nil
+}
+
+func TestFilterWriterStream(t *testing.T) {
+	testNgsiLibInit()
+
+	out := make(chanWriter, 10)
+	filter, _ := NewJSONFilter(".id")
+	w := NewFilterWriter(out, filter, nil)
+
+	fmt.Fprint(w, `{"id":"a"}`)
+	fmt.Fprint(w, `{"id":`)
+
+	select {
+	case s := <-out:
+		assert.Equal(t, "a\n", s)
+	case <-time.After(5 * time.Second):
+		t.Error("no output before Flush")
+	}
+
+	fmt.Fprint(w, `"b"}`)
+	err := w.Flush()
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, "b\n", <-out)
+	}
+}
+
+func TestFilterWriterErrorWrite(t *testing.T) {
+	testNgsiLibInit()
+
+	buf := &bytes.Buffer{}
+	filter, _ := NewJSONFilter(".")
+	w := NewFilterWriter(buf, filter, nil)
+
+	_, _ = fmt.Fprintln(w, "urn:ngsi-ld:Device:001")
+	_, err := fmt.Fprintln(w, `{}`)
+
+	if assert.Error(t, err) {
+		assert.Equal(t, "output is not JSON: invalid character 'u' looking for beginning of value", err.(*ngsierr.NgsiError).Message)
+		assert.Equal(t, err, w.Flush())
+		assert.Equal(t, err, w.Flush())
+	}
+}
@@ -90,6 +90,10 @@ func (t *MockTimeLib) Format(layout string) string {
 	return t.tTime.Format(layout)
 }
 
+func (t *MockTimeLib) Sleep(d time.Duration) {
+	t.unixTime += int64(d / time.Second)
+}
+
 // MockJSONLib
 type MockJSONLib struct {
 	IndentErr error
@@ -171,6 +175,7 @@ type MockIoLib struct {
 	DecodeErr    error
 	Env          string
 	Data         *string
+	Encoded      []byte
 }
 
 func (io *MockIoLib) Open() (err error) {
@@ -197,6 +202,9 @@ func (io *MockIoLib) Decode(v interface{}) error {
 }
 
 func (io *MockIoLib) Encode(v interface{}) error {
+	if io.EncodeErr == nil {
+		io.Encoded, _ = json.Marshal(v)
+	}
 	return io.EncodeErr
 }
 
@@ -413,3 +421,54 @@ func (i *MockFilePathLib) FilePathJoin(elem ...string) string {
 func (i *MockFilePathLib) FilePathBase(path string) string {
 	return filepath.Base(path)
 }
+
+func (i *MockFilePathLib) FilePathGlob(pattern string) ([]string, error) {
+	return filepath.Glob(pattern)
+}
+
+// MockTermLib
+type MockTermLib struct {
+	Terminal bool
+	RawErr   error
+	Raw      bool
+}
+
+func (t *MockTermLib) IsTerminal(fd uintptr) bool {
+	return t.Terminal
+}
+
+func (t *MockTermLib) MakeRaw(fd uintptr) (func(), error) {
+	if t.RawErr != nil {
+		return nil, t.RawErr
+	}
+	t.Raw = true
+	return func() { t.Raw = false }, nil
+}
+
+// MockExecLib
+type MockExecLib struct {
+	Secrets map[string]string
+	Err     error
+	Args    [][]string
+}
+
+func (e *MockExecLib) Output(name string, args []string, stdin []byte) ([]byte, error) {
+	e.Args = append(e.Args, append([]string{name}, args...))
+	if e.Err != nil {
+		return nil, e.Err
+	}
+	if e.Secrets == nil {
+		e.Secrets = make(map[string]string)
+	}
+	switch args[len(args)-2] {
+	case "store":
+		e.Secrets[args[len(args)-1]] = string(stdin)
+	case "get":
+		v, ok := e.Secrets[args[len(args)-1]]
+		if !ok {
+			return nil, errors.New("not found")
+		}
+		return []byte(v + "\n"), nil
+	}
+	return nil, nil
+}
@@ -31,7 +31,8 @@ package ngsilib
 
 import (
 	"bytes"
-	"crypto/tls"
+	"context"
+	"fmt"
 	"io"
 	"net/http"
 	"net/url"
@@ -43,59 +44,180 @@ import (
 
 // HTTPGet is ...
 func (client *Client) HTTPGet() (*http.Response, []byte, error) {
-	return client.HTTP.Request(http.MethodGet, client.URL, client.Headers, nil)
+	return client.request(http.MethodGet, nil)
+}
+
+// HTTPGetStream is ... The caller must close the body of the response.
+func (client *Client) HTTPGetStream() (*http.Response, error) {
+	if r, ok := client.HTTP.(HTTPStreamRequest); ok {
+		res, err := r.RequestStream(http.MethodGet, client.URL, client.Headers, nil)
+		if err == nil && res.StatusCode == http.StatusUnauthorized && client.refreshToken() {
+			_ = res.Body.Close()
+			return r.RequestStream(http.MethodGet, client.URL, client.Headers, nil)
+		}
+		return res, err
+	}
+	res, body, err := client.request(http.MethodGet, nil)
+	if err != nil {
+		return nil, err
+	}
+	res.Body = io.NopCloser(bytes.NewReader(body))
+	return res, nil
 }
 
 // HTTPPost is ...
 func (client *Client) HTTPPost(body interface{}) (*http.Response, []byte, error) {
-	return client.HTTP.Request(http.MethodPost, client.URL, client.Headers, body)
+	if client.DryRun {
+		return client.dryRun(http.MethodPost, body)
+	}
+	if err := client.confirmProfile(); err != nil {
+		return nil, nil, err
+	}
+	return client.request(http.MethodPost, body)
+}
+
+// HTTPQuery sends a POST request which doesn't change data, such as a query.
+// Unlike HTTPPost, it is sent even when --dryRun is set and a protected profile isn't confirmed.
+func (client *Client) HTTPQuery(body interface{}) (*http.Response, []byte, error) {
+	return client.request(http.MethodPost, body)
 }
 
 // HTTPPut is ...
 func (client *Client) HTTPPut(body interface{}) (*http.Response, []byte, error) {
-	return client.HTTP.Request(http.MethodPut, client.URL, client.Headers, body)
+	if client.DryRun {
+		return client.dryRun(http.MethodPut, body)
+	}
+	if err := client.confirmProfile(); err != nil {
+		return nil, nil, err
+	}
+	return client.request(http.MethodPut, body)
 }
 
 // HTTPPatch is ...
 func (client *Client) HTTPPatch(body interface{}) (*http.Response, []byte, error) {
-	return client.HTTP.Request(http.MethodPatch, client.URL, client.Headers, body)
+	if client.DryRun {
+		return client.dryRun(http.MethodPatch, body)
+	}
+	if err := client.confirmProfile(); err != nil {
+		return nil, nil, err
+	}
+	return client.request(http.MethodPatch, body)
 }
 
 // HTTPDelete is
 func (client *Client) HTTPDelete(body interface{}) (*http.Response, []byte, error) {
-	return client.HTTP.Request(http.MethodDelete, client.URL, client.Headers, body)
+	if client.DryRun {
+		return client.dryRun(http.MethodDelete, body)
+	}
+	if err := client.confirmProfile(); err != nil {
+		return nil, nil, err
+	}
+	return client.request(http.MethodDelete, body)
+}
+
+// request sends a request to the broker. If the broker rejects the token with 401 Unauthorized,
+// the token is renewed and the request is sent once more.
+func (client *Client) request(method string, body interface{}) (*http.Response, []byte, error) {
+	res, resBody, err := client.HTTP.Request(method, client.URL, client.Headers, body)
+	if err == nil && res.StatusCode == http.StatusUnauthorized && client.refreshToken() {
+		return client.HTTP.Request(method, client.URL, client.Headers, body)
+	}
+	return res, resBody, err
 }
 
 // HTTPRequest is ...
 type HTTPRequest interface {
 	Request(method string, url *url.URL, headers map[string]string, body interface{}) (*http.Response, []byte, error)
 }
-type httpRequest struct{}
+
+// HTTPStreamRequest is implemented by an HTTPRequest which can return a response without reading its body
+type HTTPStreamRequest interface {
+	RequestStream(method string, url *url.URL, headers map[string]string, body interface{}) (*http.Response, error)
+}
+
+// HTTPTimeoutRequest is implemented by an HTTPRequest which can send a request once with its own timeout
+type HTTPTimeoutRequest interface {
+	RequestTimeout(method string, url *url.URL, headers map[string]string, body interface{}, timeout time.Duration) (*http.Response, []byte, error)
+}
+
+type httpRequest struct {
+	server *Server
+}
 
 // NewHTTPRequet is ...
 func NewHTTPRequet() HTTPRequest {
 	return &httpRequest{}
 }
 
-func (r *httpRequest) Request(method string, url *url.URL, headers map[string]string, body interface{}) (res *http.Response, b []byte, err error) {
-	const funcName = "Request"
+func (ngsi *NGSI) serverHTTP(server *Server) HTTPRequest {
+	if _, ok := ngsi.HTTP.(*httpRequest); ok {
+		return &httpRequest{server: server}
+	}
+	return ngsi.HTTP
+}
+
+func (ngsi *NGSI) httpClient(server *Server) (*http.Client, error) {
+	const funcName = "httpClient"
+
+	ngsi.transportMutex.Lock()
+	defer ngsi.transportMutex.Unlock()
 
-	tr := &http.Transport{
-		TLSClientConfig: &tls.Config{InsecureSkipVerify: gNGSI.InsecureSkipVerify},
+	if ngsi.transports == nil {
+		ngsi.transports = make(map[string]*http.Transport)
 	}
 
-	client := &http.Client{Timeout: time.Duration(60 * time.Second), Transport: tr}
+	key := transportKey(ngsi, server)
 
-	var reader io.Reader
+	tr, ok := ngsi.transports[key]
+	if !ok {
+		tlsConfig, err := ngsi.tlsConfig(server)
+		if err != nil {
+			return nil, ngsierr.New(funcName, 1, err.Error(), err)
+		}
+		tr = http.DefaultTransport.(*http.Transport).Clone()
+		tr.TLSClientConfig = tlsConfig
+		if server != nil && server.Proxy != "" {
+			proxy, err := proxyFunc(server)
+			if err != nil {
+				return nil, ngsierr.New(funcName, 2, err.Error(), err)
+			}
+			tr.Proxy = proxy
+		}
+		ngsi.transports[key] = tr
+	}
+
+	return &http.Client{Transport: tr}, nil
+}
+
+func transportKey(ngsi *NGSI, server *Server) string {
+	if server == nil {
+		server = &Server{}
+	}
+	return strings.Join([]string{fmt.Sprint(ngsi.InsecureSkipVerify), server.CACert, server.ClientCert, server.ClientKey, server.Proxy, server.NoProxy}, "|")
+}
+
+func (r *httpRequest) Request(method string, url *url.URL, headers map[string]string, body interface{}) (*http.Response, []byte, error) {
+	return r.request(method, url, headers, body, false)
+}
+
+// RequestStream is ...
+func (r *httpRequest) RequestStream(method string, url *url.URL, headers map[string]string, body interface{}) (*http.Response, error) {
+	res, _, err := r.request(method, url, headers, body, true)
+	return res, err
+}
+
+func (r *httpRequest) request(method string, url *url.URL, headers map[string]string, body interface{}, stream bool) (res *http.Response, b []byte, err error) {
+	const funcName = "Request"
 
 	switch method {
 	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
 		if body != nil {
-			reader, err = newReader(body)
-			if err != nil {
+			if _, err = newReader(body); err != nil {
 				return nil, nil, ngsierr.New(funcName, 1, err.Error(), err)
 			}
 		}
+	default:
+		body = nil
 	}
 
 	u := url.String()
@@ -105,9 +227,88 @@ func (r *httpRequest) Request(method string, url *url.URL, headers map[string]st
 		u = u[:p] + url.Path
 	}
 
-	var req *http.Request
-	req, err = http.NewRequest(method, u, reader)
+	policy, err := gNGSI.retryPolicy(r.server)
+	if err != nil {
+		return nil, nil, ngsierr.New(funcName, 4, err.Error(), err)
+	}
+
+	client, err := gNGSI.httpClient(r.server)
+	if err != nil {
+		return nil, nil, ngsierr.New(funcName, 6, err.Error(), err)
+	}
+
+	for attempt := 1; ; attempt++ {
+		res, b, err = r.do(client, method, u, headers, body, stream, gNGSI.Timeout)
+		if err != nil {
+			if _, ok := err.(*ngsierr.NgsiError); ok {
+				return nil, nil, err
+			}
+			if attempt >= policy.MaxAttempts || !idempotentMethod(method) {
+				if e, ok := err.(*readBodyError); ok {
+					return nil, nil, ngsierr.New(funcName, 5, e.Error(), e.err)
+				}
+				return nil, nil, ngsierr.New(funcName, 3, err.Error(), err)
+			}
+			gNGSI.Logging(LogInfo, fmt.Sprintf("%s %s: %s (attempt %d/%d)\n", method, u, err.Error(), attempt, policy.MaxAttempts))
+		} else {
+			if attempt >= policy.MaxAttempts || !policy.IsRetryStatus(res.StatusCode) {
+				return res, b, nil
+			}
+			gNGSI.Logging(LogInfo, fmt.Sprintf("%s %s: %s (attempt %d/%d)\n", method, u, res.Status, attempt, policy.MaxAttempts))
+			if stream {
+				_ = res.Body.Close()
+			}
+		}
+		time.Sleep(policy.Wait(attempt))
+	}
+}
+
+// RequestTimeout sends a request once, without the retry policy, and gives up after timeout
+func (r *httpRequest) RequestTimeout(method string, url *url.URL, headers map[string]string, body interface{}, timeout time.Duration) (*http.Response, []byte, error) {
+	const funcName = "RequestTimeout"
+
+	client, err := gNGSI.httpClient(r.server)
+	if err != nil {
+		return nil, nil, ngsierr.New(funcName, 1, err.Error(), err)
+	}
+
+	res, b, err := r.do(client, method, url.String(), headers, body, false, timeout)
+	if err != nil {
+		if _, ok := err.(*ngsierr.NgsiError); ok {
+			return nil, nil, err
+		}
+		return nil, nil, ngsierr.New(funcName, 2, err.Error(), err)
+	}
+	return res, b, nil
+}
+
+// idempotentMethod reports whether a request can be sent again after a connection error.
+// A POST, PATCH or DELETE may have reached the server, so it is not repeated.
+func idempotentMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPut:
+		return true
+	}
+	return false
+}
+
+func (r *httpRequest) do(client *http.Client, method, u string, headers map[string]string, body interface{}, stream bool, timeout time.Duration) (*http.Response, []byte, error) {
+	const funcName = "Request"
+
+	var reader io.Reader
+	if body != nil {
+		reader, _ = newReader(body)
+	}
+
+	ctx := context.Background()
+	cancel := context.CancelFunc(func() {})
+	if timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u, reader)
 	if err != nil {
+		cancel()
 		return nil, nil, ngsierr.New(funcName, 2, err.Error(), err)
 	}
 
@@ -115,20 +316,44 @@ func (r *httpRequest) Request(method string, url *url.URL, headers map[string]st
 		req.Header.Add(k, v)
 	}
 
-	var resp *http.Response
-	resp, err = client.Do(req)
+	resp, err := client.Do(req)
 	if err != nil {
-		return nil, nil, ngsierr.New(funcName, 3, err.Error(), err)
+		cancel()
+		return nil, nil, err
+	}
+
+	if stream {
+		resp.Body = &cancelBody{ReadCloser: resp.Body, cancel: cancel}
+		return resp, nil, nil
 	}
+	defer cancel()
 	defer func() { _ = resp.Body.Close() }()
 
-	b, err = io.ReadAll(resp.Body)
+	b, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, nil, ngsierr.New(funcName, 5, err.Error(), err)
+		return nil, nil, &readBodyError{err: err}
 	}
 	return resp, b, nil
 }
 
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelBody) Close() error {
+	defer b.cancel()
+	return b.ReadCloser.Close()
+}
+
+type readBodyError struct {
+	err error
+}
+
+func (e *readBodyError) Error() string {
+	return e.err.Error()
+}
+
 func newReader(v interface{}) (io.Reader, error) {
 	const funcName = "newReader"
 
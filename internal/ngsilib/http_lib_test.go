@@ -30,12 +30,16 @@ SOFTWARE.
 package ngsilib
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/lets-fiware/ngsi-go/internal/assert"
 	"github.com/lets-fiware/ngsi-go/internal/ngsierr"
@@ -55,6 +59,92 @@ func TestHTTPGet(t *testing.T) {
 	}
 }
 
+func TestHTTPGetStream(t *testing.T) {
+	testNgsiLibInit()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[{"id":"device001"}]`))
+	}))
+	defer ts.Close()
+
+	u, _ := url.Parse(ts.URL)
+	client := &Client{URL: u, Headers: map[string]string{}}
+	client.HTTP = NewHTTPRequet()
+
+	res, err := client.HTTPGetStream()
+	if assert.NoError(t, err) {
+		b, _ := io.ReadAll(res.Body)
+		_ = res.Body.Close()
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Equal(t, `[{"id":"device001"}]`, string(b))
+	}
+}
+
+func TestHTTPGetStreamMock(t *testing.T) {
+	u, _ := url.Parse("http://orion/v2/entities")
+	client := &Client{URL: u, Headers: map[string]string{}}
+
+	reqRes := MockHTTPReqRes{}
+	reqRes.Res.StatusCode = http.StatusOK
+	reqRes.ResBody = []byte(`[{"id":"device001"}]`)
+	mock := NewMockHTTP()
+	mock.ReqRes = append(mock.ReqRes, reqRes)
+	client.HTTP = mock
+
+	res, err := client.HTTPGetStream()
+	if assert.NoError(t, err) {
+		b, _ := io.ReadAll(res.Body)
+		_ = res.Body.Close()
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Equal(t, `[{"id":"device001"}]`, string(b))
+	}
+}
+
+func TestHTTPGetStreamErrorMock(t *testing.T) {
+	u, _ := url.Parse("http://orion/v2/entities")
+	client := &Client{URL: u, Headers: map[string]string{}}
+
+	reqRes := MockHTTPReqRes{}
+	reqRes.Err = errors.New("http error")
+	mock := NewMockHTTP()
+	mock.ReqRes = append(mock.ReqRes, reqRes)
+	client.HTTP = mock
+
+	_, err := client.HTTPGetStream()
+
+	if assert.Error(t, err) {
+		assert.Equal(t, "http error", err.Error())
+	}
+}
+
+func TestRequestStreamRetry(t *testing.T) {
+	ngsi := testNgsiLibInit()
+	ngsi.Retry.MaxAttempts = 2
+	ngsi.Retry.Backoff = time.Millisecond
+
+	n := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n++
+		if n == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(`[]`))
+	}))
+	defer ts.Close()
+
+	u, _ := url.Parse(ts.URL)
+	res, err := ngsi.serverHTTP(nil).(HTTPStreamRequest).RequestStream(http.MethodGet, u, nil, nil)
+
+	if assert.NoError(t, err) {
+		b, _ := io.ReadAll(res.Body)
+		_ = res.Body.Close()
+		assert.Equal(t, 2, n)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Equal(t, "[]", string(b))
+	}
+}
+
 func TestHTTPPost(t *testing.T) {
 	ts := httptest.NewServer(Route())
 	defer ts.Close()
@@ -69,6 +159,34 @@ func TestHTTPPost(t *testing.T) {
 	}
 }
 
+func TestHTTPQuery(t *testing.T) {
+	ts := httptest.NewServer(Route())
+	defer ts.Close()
+
+	u, _ := url.Parse(ts.URL)
+	client := &Client{URL: u, Headers: map[string]string{}}
+	client.HTTP = NewHTTPRequet()
+
+	res, _, err := client.HTTPQuery("")
+	if assert.NoError(t, err) {
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+	}
+}
+
+func TestHTTPQueryDryRun(t *testing.T) {
+	ts := httptest.NewServer(Route())
+	defer ts.Close()
+
+	u, _ := url.Parse(ts.URL)
+	client := &Client{URL: u, Headers: map[string]string{}, DryRun: true}
+	client.HTTP = NewHTTPRequet()
+
+	res, _, err := client.HTTPQuery("")
+	if assert.NoError(t, err) {
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+	}
+}
+
 func TestHTTPPut(t *testing.T) {
 	ts := httptest.NewServer(Route())
 	defer ts.Close()
@@ -230,6 +348,328 @@ func TestRequestErrorReadAll(t *testing.T) {
 	}
 }
 
+func TestRequestTimeoutNoRetry(t *testing.T) {
+	ngsi := testNgsiLibInit()
+	ngsi.Retry = &RetryPolicy{MaxAttempts: 3, Backoff: time.Millisecond, RetryOn: []int{http.StatusServiceUnavailable}}
+
+	count := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		count++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+
+	r := &httpRequest{}
+	u, _ := url.Parse(ts.URL)
+	res, _, err := r.RequestTimeout(http.MethodHead, u, nil, nil, time.Second)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, http.StatusServiceUnavailable, res.StatusCode)
+		assert.Equal(t, 1, count)
+	}
+}
+
+func TestRequestTimeoutErrorHTTPClient(t *testing.T) {
+	ngsi := testNgsiLibInit()
+	ngsi.FileReader = &MockFileLib{ReadFileError: [5]error{errors.New("read error")}}
+
+	r := &httpRequest{server: &Server{CACert: "ca.pem"}}
+	u, _ := url.Parse("https://orion")
+	_, _, err := r.RequestTimeout(http.MethodHead, u, nil, nil, time.Second)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 1, ngsiErr.ErrNo)
+	}
+}
+
+func TestRequestTimeoutErrorNewRequest(t *testing.T) {
+	testNgsiLibInit()
+
+	r := &httpRequest{}
+	u, _ := url.Parse("http://orion")
+	u.Host = ":\n"
+	_, _, err := r.RequestTimeout(http.MethodHead, u, nil, nil, time.Second)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, "Request", ngsiErr.Function)
+		assert.Equal(t, 2, ngsiErr.ErrNo)
+	}
+}
+
+func TestRequestTimeoutExpired(t *testing.T) {
+	testNgsiLibInit()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+	}))
+	defer ts.Close()
+
+	r := &httpRequest{}
+	u, _ := url.Parse(ts.URL)
+	_, _, err := r.RequestTimeout(http.MethodHead, u, nil, nil, 10*time.Millisecond)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, "RequestTimeout", ngsiErr.Function)
+		assert.Equal(t, 2, ngsiErr.ErrNo)
+	}
+}
+
+func TestRequestRetryStatus(t *testing.T) {
+	ngsi := testNgsiLibInit()
+	ngsi.Retry = &RetryPolicy{MaxAttempts: 3, Backoff: time.Millisecond, RetryOn: []int{http.StatusServiceUnavailable}}
+
+	count := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		count++
+		if count < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprint(w, "ok")
+	}))
+	defer ts.Close()
+
+	r := NewHTTPRequet()
+	u, _ := url.Parse(ts.URL)
+	res, b, err := r.Request("POST", u, nil, "data")
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Equal(t, "ok", string(b))
+		assert.Equal(t, 3, count)
+	}
+}
+
+func TestRequestRetryExhausted(t *testing.T) {
+	ngsi := testNgsiLibInit()
+	ngsi.Retry = &RetryPolicy{MaxAttempts: 2, Backoff: time.Millisecond, RetryOn: []int{http.StatusServiceUnavailable}}
+
+	count := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		count++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+
+	r := NewHTTPRequet()
+	u, _ := url.Parse(ts.URL)
+	res, _, err := r.Request("GET", u, nil, nil)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, http.StatusServiceUnavailable, res.StatusCode)
+		assert.Equal(t, 2, count)
+	}
+}
+
+func TestRequestRetryServer(t *testing.T) {
+	ngsi := testNgsiLibInit()
+
+	count := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		count++
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer ts.Close()
+
+	r := ngsi.serverHTTP(&Server{RetryMax: "4", RetryBackoff: "1ms", RetryOn: "502"})
+	u, _ := url.Parse(ts.URL)
+	res, _, err := r.Request("GET", u, nil, nil)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, http.StatusBadGateway, res.StatusCode)
+		assert.Equal(t, 4, count)
+	}
+}
+
+func TestRequestRetryDo(t *testing.T) {
+	ngsi := testNgsiLibInit()
+	ngsi.Retry = &RetryPolicy{MaxAttempts: 2, Backoff: time.Millisecond}
+
+	r := NewHTTPRequet()
+	u, _ := url.Parse("http://localhost")
+	u.Host = ""
+	_, _, err := r.Request("GET", u, nil, nil)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 3, ngsiErr.ErrNo)
+		assert.Equal(t, "Get \"http:\": http: no Host in request URL", ngsiErr.Message)
+	}
+}
+
+func TestRequestRetryDoNotIdempotent(t *testing.T) {
+	cases := []struct {
+		method string
+		count  int
+	}{
+		{method: http.MethodGet, count: 3},
+		{method: http.MethodPut, count: 3},
+		{method: http.MethodPost, count: 1},
+		{method: http.MethodPatch, count: 1},
+		{method: http.MethodDelete, count: 1},
+	}
+
+	for _, c := range cases {
+		ngsi := testNgsiLibInit()
+		ngsi.Retry = &RetryPolicy{MaxAttempts: 3, Backoff: time.Millisecond}
+
+		count := 0
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			count++
+			conn, _, _ := w.(http.Hijacker).Hijack()
+			_ = conn.Close()
+		}))
+
+		r := NewHTTPRequet()
+		u, _ := url.Parse(ts.URL)
+		var body interface{}
+		if c.method != http.MethodGet {
+			body = "data"
+		}
+		_, _, err := r.Request(c.method, u, nil, body)
+
+		if assert.Error(t, err) {
+			ngsiErr := err.(*ngsierr.NgsiError)
+			assert.Equal(t, 3, ngsiErr.ErrNo)
+		}
+		assert.Equal(t, c.count, count, c.method)
+		ts.Close()
+	}
+}
+
+func TestIdempotentMethod(t *testing.T) {
+	assert.Equal(t, true, idempotentMethod(http.MethodGet))
+	assert.Equal(t, true, idempotentMethod(http.MethodHead))
+	assert.Equal(t, true, idempotentMethod(http.MethodPut))
+	assert.Equal(t, false, idempotentMethod(http.MethodPost))
+	assert.Equal(t, false, idempotentMethod(http.MethodPatch))
+	assert.Equal(t, false, idempotentMethod(http.MethodDelete))
+}
+
+func TestRequestTimeout(t *testing.T) {
+	ngsi := testNgsiLibInit()
+	ngsi.Timeout = 10 * time.Millisecond
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(100 * time.Millisecond)
+	}))
+	defer ts.Close()
+
+	r := NewHTTPRequet()
+	u, _ := url.Parse(ts.URL)
+	_, _, err := r.Request("GET", u, nil, nil)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 3, ngsiErr.ErrNo)
+	}
+}
+
+func TestRequestErrorRetryPolicy(t *testing.T) {
+	ngsi := testNgsiLibInit()
+
+	r := ngsi.serverHTTP(&Server{RetryMax: "x"})
+	u, _ := url.Parse("http://orion")
+	_, _, err := r.Request("GET", u, nil, nil)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 4, ngsiErr.ErrNo)
+		assert.Equal(t, "retryMax error: x (1-10)", ngsiErr.Message)
+	}
+}
+
+func TestServerHTTP(t *testing.T) {
+	ngsi := testNgsiLibInit()
+
+	server := &Server{ServerHost: "http://orion"}
+	actual := ngsi.serverHTTP(server)
+
+	assert.Equal(t, server, actual.(*httpRequest).server)
+}
+
+func TestServerHTTPMock(t *testing.T) {
+	ngsi := testNgsiLibInit()
+	mock := NewMockHTTP()
+	ngsi.HTTP = mock
+
+	actual := ngsi.serverHTTP(&Server{})
+
+	assert.Equal(t, mock, actual)
+}
+
+func TestHTTPClientTransport(t *testing.T) {
+	ngsi := testNgsiLibInit()
+
+	c1, _ := ngsi.httpClient(nil)
+	c2, _ := ngsi.httpClient(&Server{})
+
+	assert.Equal(t, c1.Transport, c2.Transport)
+
+	ngsi.InsecureSkipVerify = true
+	c3, _ := ngsi.httpClient(nil)
+
+	assert.Equal(t, true, c1.Transport != c3.Transport)
+	assert.Equal(t, 2, len(ngsi.transports))
+}
+
+func TestHTTPClientErrorTLS(t *testing.T) {
+	ngsi := testNgsiLibInit()
+	ngsi.FileReader = &MockFileLib{ReadFileError: [5]error{errors.New("read error")}}
+
+	_, err := ngsi.httpClient(&Server{CACert: "ca.pem"})
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 1, ngsiErr.ErrNo)
+		assert.Equal(t, "read error", ngsiErr.Message)
+	}
+}
+
+func TestHTTPClientProxy(t *testing.T) {
+	ngsi := testNgsiLibInit()
+
+	c1, _ := ngsi.httpClient(&Server{})
+	c2, err := ngsi.httpClient(&Server{Proxy: "socks5://localhost:1080"})
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, true, c1.Transport != c2.Transport)
+		req, _ := http.NewRequest(http.MethodGet, "http://orion:1026/version", nil)
+		proxy, _ := c2.Transport.(*http.Transport).Proxy(req)
+		assert.Equal(t, "socks5://localhost:1080", proxy.String())
+	}
+}
+
+func TestHTTPClientErrorProxy(t *testing.T) {
+	ngsi := testNgsiLibInit()
+
+	_, err := ngsi.httpClient(&Server{Proxy: "ftp://proxy"})
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 2, ngsiErr.ErrNo)
+		assert.Equal(t, "proxy error: ftp://proxy (http, https, socks5)", ngsiErr.Message)
+	}
+}
+
+func TestRequestErrorHTTPClient(t *testing.T) {
+	ngsi := testNgsiLibInit()
+	ngsi.FileReader = &MockFileLib{ReadFileError: [5]error{errors.New("read error")}}
+
+	r := ngsi.serverHTTP(&Server{CACert: "ca.pem"})
+	u, _ := url.Parse("https://orion")
+	_, _, err := r.Request("GET", u, nil, nil)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 6, ngsiErr.ErrNo)
+		assert.Equal(t, "read error", ngsiErr.Message)
+	}
+}
+
 func TestNewReaderString(t *testing.T) {
 	s := "tset data"
 
@@ -268,3 +708,162 @@ func Route() *http.ServeMux {
 	})
 	return m
 }
+
+func TestHTTPGetRetryUnauthorized(t *testing.T) {
+	ngsi := testNgsiLibInit()
+	ngsi.tokenList = tokenInfoList{}
+	filename := ""
+	ngsi.CacheFile = &MockIoLib{filename: &filename}
+	ngsi.LogWriter = &bytes.Buffer{}
+
+	reqRes1 := MockHTTPReqRes{}
+	reqRes1.Res.StatusCode = http.StatusUnauthorized
+	reqRes2 := MockHTTPReqRes{}
+	reqRes2.Res.StatusCode = http.StatusOK
+	reqRes2.ResBody = []byte(`{"access_token": "new", "expires_in": 3599, "refresh_token": "refresh", "token_type": "Bearer" }`)
+	reqRes3 := MockHTTPReqRes{}
+	reqRes3.Res.StatusCode = http.StatusOK
+	reqRes3.ResBody = []byte(`{}`)
+	mock := NewMockHTTP()
+	mock.ReqRes = append(mock.ReqRes, reqRes1, reqRes2, reqRes3)
+	ngsi.HTTP = mock
+
+	u, _ := url.Parse("http://orion/v2/entities")
+	client := &Client{URL: u, HTTP: mock, Server: &Server{ServerHost: "http://orion/", IdmType: CTokenproxy, Username: "fiware", Password: "1234"}, Headers: map[string]string{}, Token: "old", tokenRefresh: true}
+
+	res, body, err := client.HTTPGet()
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Equal(t, "{}", string(body))
+		assert.Equal(t, "Bearer new", client.Headers["Authorization"])
+	}
+}
+
+func TestHTTPPostRetryUnauthorizedNotRefreshable(t *testing.T) {
+	testNgsiLibInit()
+
+	reqRes := MockHTTPReqRes{}
+	reqRes.Res.StatusCode = http.StatusUnauthorized
+	mock := NewMockHTTP()
+	mock.ReqRes = append(mock.ReqRes, reqRes)
+
+	u, _ := url.Parse("http://orion/v2/entities")
+	client := &Client{URL: u, HTTP: mock, Server: &Server{ServerHost: "http://orion/"}, Headers: map[string]string{}, Token: "token"}
+
+	res, _, err := client.HTTPPost("{}")
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
+	}
+}
+
+func TestHTTPGetStreamRetryUnauthorized(t *testing.T) {
+	ngsi := testNgsiLibInit()
+	ngsi.tokenList = tokenInfoList{}
+	filename := ""
+	ngsi.CacheFile = &MockIoLib{filename: &filename}
+	ngsi.LogWriter = &bytes.Buffer{}
+
+	reqRes := MockHTTPReqRes{}
+	reqRes.Res.StatusCode = http.StatusOK
+	reqRes.ResBody = []byte(`{"access_token": "new", "expires_in": 3599, "refresh_token": "refresh", "token_type": "Bearer" }`)
+	mock := NewMockHTTP()
+	mock.ReqRes = append(mock.ReqRes, reqRes)
+	ngsi.HTTP = mock
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer new" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte(`[{"id":"device001"}]`))
+	}))
+	defer ts.Close()
+
+	u, _ := url.Parse(ts.URL)
+	client := &Client{URL: u, HTTP: NewHTTPRequet(), Server: &Server{ServerHost: ts.URL, IdmType: CTokenproxy, Username: "fiware", Password: "1234"}, Headers: map[string]string{"Authorization": "Bearer old"}, Token: "old", tokenRefresh: true}
+
+	res, err := client.HTTPGetStream()
+
+	if assert.NoError(t, err) {
+		defer res.Body.Close()
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		b, _ := io.ReadAll(res.Body)
+		assert.Equal(t, `[{"id":"device001"}]`, string(b))
+	}
+}
+
+func TestHTTPProtectedProfile(t *testing.T) {
+	ngsi := testNgsiLibInit()
+	ngsi.ConfigFile = &MockIoLib{}
+	ngsi.TermLib = &MockTermLib{}
+
+	reqRes := MockHTTPReqRes{}
+	reqRes.Res.StatusCode = http.StatusOK
+	mock := NewMockHTTP()
+	mock.ReqRes = append(mock.ReqRes, reqRes)
+
+	u, _ := url.Parse("http://orion/v2/entities")
+	client := &Client{URL: u, HTTP: mock, Server: &Server{ServerHost: "http://orion/"}, Headers: map[string]string{}, protectedProfile: "prod"}
+
+	res, _, err := client.HTTPGet()
+	if assert.NoError(t, err) {
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+	}
+
+	requests := []func() (*http.Response, []byte, error){
+		func() (*http.Response, []byte, error) { return client.HTTPPost("{}") },
+		func() (*http.Response, []byte, error) { return client.HTTPPut("{}") },
+		func() (*http.Response, []byte, error) { return client.HTTPPatch("{}") },
+		func() (*http.Response, []byte, error) { return client.HTTPDelete(nil) },
+	}
+	for _, request := range requests {
+		_, _, err := request()
+		if assert.Error(t, err) {
+			ngsiErr := err.(*ngsierr.NgsiError)
+			assert.Equal(t, "confirmProfile", ngsiErr.Function)
+		}
+	}
+}
+
+func TestHTTPQueryProtectedProfile(t *testing.T) {
+	ngsi := testNgsiLibInit()
+	ngsi.ConfigFile = &MockIoLib{}
+	ngsi.TermLib = &MockTermLib{}
+
+	reqRes := MockHTTPReqRes{}
+	reqRes.Res.StatusCode = http.StatusOK
+	reqRes.ReqData = []byte(`{"entities":[{"idPattern":".*"}]}`)
+	mock := NewMockHTTP()
+	mock.ReqRes = append(mock.ReqRes, reqRes)
+
+	u, _ := url.Parse("http://orion/v2/op/query")
+	client := &Client{URL: u, HTTP: mock, Server: &Server{ServerHost: "http://orion/"}, Headers: map[string]string{}, protectedProfile: "prod"}
+
+	res, _, err := client.HTTPQuery([]byte(`{"entities":[{"idPattern":".*"}]}`))
+	if assert.NoError(t, err) {
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Equal(t, false, ngsi.profileConfirmed)
+	}
+
+	_, _, err = client.HTTPPost("{}")
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, "confirmProfile", ngsiErr.Function)
+	}
+}
+
+func TestHTTPProtectedProfileDryRun(t *testing.T) {
+	ngsi := testNgsiLibInit()
+	ngsi.ConfigFile = &MockIoLib{}
+	ngsi.TermLib = &MockTermLib{}
+	ngsi.StdWriter = &bytes.Buffer{}
+
+	u, _ := url.Parse("http://orion/v2/entities")
+	client := &Client{URL: u, Headers: map[string]string{}, DryRun: true, protectedProfile: "prod"}
+
+	_, _, err := client.HTTPDelete(nil)
+
+	assert.Equal(t, true, IsDryRun(err))
+}
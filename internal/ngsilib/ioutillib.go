@@ -39,7 +39,12 @@ type IoutilLib interface {
 	Copy(dst io.Writer, src io.Reader) (int64, error)
 	ReadFull(r io.Reader, buf []byte) (n int, err error)
 	WriteFile(filename string, data []byte, perm os.FileMode) error
+	AppendFile(filename string, data []byte, perm os.FileMode) error
 	ReadFile(filename string) ([]byte, error)
+	Open(name string) (*os.File, error)
+	CreateTemp(dir, pattern string) (*os.File, error)
+	Rename(oldpath, newpath string) error
+	Remove(name string) error
 }
 
 type ioutilLib struct {
@@ -57,6 +62,34 @@ func (i *ioutilLib) WriteFile(filename string, data []byte, perm os.FileMode) er
 	return os.WriteFile(filename, data, perm)
 }
 
+func (i *ioutilLib) AppendFile(filename string, data []byte, perm os.FileMode) error {
+	f, err := os.OpenFile(filename, os.O_APPEND|os.O_CREATE|os.O_WRONLY, perm)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(data)
+	if err1 := f.Close(); err == nil {
+		err = err1
+	}
+	return err
+}
+
 func (i *ioutilLib) ReadFile(filename string) ([]byte, error) {
 	return os.ReadFile(filename)
 }
+
+func (i *ioutilLib) Open(name string) (*os.File, error) {
+	return os.Open(name)
+}
+
+func (i *ioutilLib) CreateTemp(dir, pattern string) (*os.File, error) {
+	return os.CreateTemp(dir, pattern)
+}
+
+func (i *ioutilLib) Rename(oldpath, newpath string) error {
+	return os.Rename(oldpath, newpath)
+}
+
+func (i *ioutilLib) Remove(name string) error {
+	return os.Remove(name)
+}
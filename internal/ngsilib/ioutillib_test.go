@@ -32,7 +32,11 @@ package ngsilib
 import (
 	"bytes"
 	"io"
+	"os"
+	"path/filepath"
 	"testing"
+
+	"github.com/lets-fiware/ngsi-go/internal/assert"
 )
 
 func TestIoutilLibCopy(t *testing.T) {
@@ -57,8 +61,65 @@ func TestIoutilLibWriteFile(t *testing.T) {
 	_ = iolib.WriteFile("", buf, 0644)
 }
 
+func TestIoutilLibAppendFile(t *testing.T) {
+	iolib := ioutilLib{}
+	filename := filepath.Join(t.TempDir(), "append.jsonl")
+
+	err := iolib.AppendFile(filename, []byte("a\n"), 0600)
+	assert.NoError(t, err)
+	err = iolib.AppendFile(filename, []byte("b\n"), 0600)
+	assert.NoError(t, err)
+
+	b, _ := os.ReadFile(filename)
+	assert.Equal(t, "a\nb\n", string(b))
+}
+
+func TestIoutilLibAppendFileError(t *testing.T) {
+	iolib := ioutilLib{}
+
+	err := iolib.AppendFile("", nil, 0600)
+
+	assert.Error(t, err)
+}
+
 func TestIoutilLibReadFile(t *testing.T) {
 	iolib := ioutilLib{}
 
 	_, _ = iolib.ReadFile("")
 }
+
+func TestIoutilLibOpen(t *testing.T) {
+	iolib := ioutilLib{}
+	filename := filepath.Join(t.TempDir(), "open")
+	_ = os.WriteFile(filename, nil, 0600)
+
+	f, err := iolib.Open(filename)
+
+	if assert.NoError(t, err) {
+		_ = f.Close()
+	}
+}
+
+func TestIoutilLibCreateTempRename(t *testing.T) {
+	iolib := ioutilLib{}
+	dir := t.TempDir()
+
+	f, err := iolib.CreateTemp(dir, "archive.*.tmp")
+	if assert.NoError(t, err) {
+		_ = f.Close()
+		err = iolib.Rename(f.Name(), filepath.Join(dir, "archive.zip"))
+		assert.NoError(t, err)
+		_, err = os.Stat(filepath.Join(dir, "archive.zip"))
+		assert.NoError(t, err)
+	}
+}
+
+func TestIoutilLibRemove(t *testing.T) {
+	iolib := ioutilLib{}
+	filename := filepath.Join(t.TempDir(), "remove")
+	_ = os.WriteFile(filename, nil, 0600)
+
+	err := iolib.Remove(filename)
+
+	assert.NoError(t, err)
+}
@@ -0,0 +1,1109 @@
+/*
+MIT License
+
+Copyright (c) 2020-2024 Kazuhito Suda
+
+This file is part of NGSI Go
+
+https://github.com/lets-fiware/ngsi-go
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+
+*/
+
+package ngsilib
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/lets-fiware/ngsi-go/internal/ngsierr"
+)
+
+// JSONFilter is a compiled filter expression. It supports a subset of jq and of JSONPath:
+// ., .name, .["name"], .[n], .[n:m], .[], .*, [*], [?(cond)], $, @, |, ',', (f), [f], {key: f},
+// ==, !=, <, <=, >, >=, and, or, select(f), map(f), keys, length and not.
+// The recursive descent .. returns a value and all values in it like jq. Followed by a selector,
+// e.g. $..name, $..* or $..[0], it applies the selector to each of them like JSONPath and skips
+// the values the selector doesn't match.
+type JSONFilter struct {
+	f jsonFilterFunc
+}
+
+type jsonFilterFunc func(root, v interface{}) ([]interface{}, error)
+
+const (
+	jsonFilterPunct = iota
+	jsonFilterField
+	jsonFilterIdent
+	jsonFilterNumber
+	jsonFilterString
+)
+
+type jsonFilterToken struct {
+	kind  int
+	s     string
+	value interface{}
+	pos   int
+}
+
+type jsonFilterParser struct {
+	tokens []jsonFilterToken
+	i      int
+}
+
+// NewJSONFilter compiles a jq style filter expression
+func NewJSONFilter(expr string) (*JSONFilter, error) {
+	const funcName = "NewJSONFilter"
+
+	tokens, err := jsonFilterTokenize(expr)
+	if err != nil {
+		return nil, ngsierr.New(funcName, 1, err.Error(), err)
+	}
+	if len(tokens) == 0 {
+		return nil, ngsierr.New(funcName, 2, "filter expression is empty", nil)
+	}
+
+	p := &jsonFilterParser{tokens: tokens}
+	f, err := p.parsePipe()
+	if err != nil {
+		return nil, ngsierr.New(funcName, 3, err.Error(), err)
+	}
+	if t := p.peek(); t != nil {
+		return nil, ngsierr.New(funcName, 4, fmt.Sprintf("unexpected %s at %d", t.s, t.pos), nil)
+	}
+
+	return &JSONFilter{f: f}, nil
+}
+
+// NewJSONPath compiles a JSONPath expression
+func NewJSONPath(expr string) (*JSONFilter, error) {
+	const funcName = "NewJSONPath"
+
+	if !strings.HasPrefix(strings.TrimSpace(expr), "$") {
+		return nil, ngsierr.New(funcName, 1, "JSONPath must start with $: "+expr, nil)
+	}
+
+	f, err := NewJSONFilter(expr)
+	if err != nil {
+		return nil, ngsierr.New(funcName, 2, err.Error(), err)
+	}
+
+	return f, nil
+}
+
+// Apply applies the filter to v and returns the results
+func (f *JSONFilter) Apply(v interface{}) ([]interface{}, error) {
+	const funcName = "Apply"
+
+	results, err := f.f(v, v)
+	if err != nil {
+		return nil, ngsierr.New(funcName, 1, err.Error(), err)
+	}
+
+	return results, nil
+}
+
+// Match applies the filter to v and reports whether it has results and all of them are true
+func (f *JSONFilter) Match(v interface{}) (bool, error) {
+	const funcName = "Match"
+
+	results, err := f.f(v, v)
+	if err != nil {
+		return false, ngsierr.New(funcName, 1, err.Error(), err)
+	}
+
+	for _, r := range results {
+		if !jsonFilterTruthy(r) {
+			return false, nil
+		}
+	}
+
+	return len(results) > 0, nil
+}
+
+func jsonFilterTokenize(expr string) ([]jsonFilterToken, error) {
+	const funcName = "jsonFilterTokenize"
+
+	isIdentStart := func(c byte) bool {
+		return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+	}
+	isIdent := func(c byte) bool {
+		return isIdentStart(c) || (c >= '0' && c <= '9')
+	}
+	isDigit := func(c byte) bool {
+		return c >= '0' && c <= '9'
+	}
+	ident := func(i int) int {
+		for i < len(expr) && isIdent(expr[i]) {
+			i++
+		}
+		return i
+	}
+
+	var tokens []jsonFilterToken
+
+	for i := 0; i < len(expr); {
+		c := expr[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '.' && i+1 < len(expr) && expr[i+1] == '.':
+			tokens = append(tokens, jsonFilterToken{kind: jsonFilterPunct, s: "..", pos: i})
+			i += 2
+		case c == '.' && i+1 < len(expr) && isIdentStart(expr[i+1]):
+			e := ident(i + 1)
+			tokens = append(tokens, jsonFilterToken{kind: jsonFilterField, s: expr[i+1 : e], pos: i})
+			i = e
+		case isIdentStart(c):
+			e := ident(i)
+			tokens = append(tokens, jsonFilterToken{kind: jsonFilterIdent, s: expr[i:e], pos: i})
+			i = e
+		case isDigit(c) || (c == '-' && i+1 < len(expr) && isDigit(expr[i+1])):
+			e := i + 1
+			for e < len(expr) && (isDigit(expr[e]) || expr[e] == '.' || expr[e] == 'e' || expr[e] == 'E' ||
+				((expr[e] == '+' || expr[e] == '-') && (expr[e-1] == 'e' || expr[e-1] == 'E'))) {
+				e++
+			}
+			f, err := strconv.ParseFloat(expr[i:e], 64)
+			if err != nil {
+				return nil, ngsierr.New(funcName, 1, fmt.Sprintf("invalid number %s at %d", expr[i:e], i), err)
+			}
+			tokens = append(tokens, jsonFilterToken{kind: jsonFilterNumber, s: expr[i:e], value: f, pos: i})
+			i = e
+		case c == '"' || c == '\'':
+			e := i + 1
+			for e < len(expr) && expr[e] != c {
+				if expr[e] == '\\' {
+					e++
+				}
+				e++
+			}
+			if e >= len(expr) {
+				return nil, ngsierr.New(funcName, 2, fmt.Sprintf("unterminated string at %d", i), nil)
+			}
+			var s string
+			if c == '"' {
+				if err := json.Unmarshal([]byte(expr[i:e+1]), &s); err != nil {
+					return nil, ngsierr.New(funcName, 3, fmt.Sprintf("invalid string %s at %d", expr[i:e+1], i), err)
+				}
+			} else {
+				s = strings.ReplaceAll(strings.ReplaceAll(expr[i+1:e], `\'`, `'`), `\\`, `\`)
+			}
+			tokens = append(tokens, jsonFilterToken{kind: jsonFilterString, s: expr[i : e+1], value: s, pos: i})
+			i = e + 1
+		case strings.ContainsRune("=!<>", rune(c)):
+			if i+1 < len(expr) && expr[i+1] == '=' {
+				tokens = append(tokens, jsonFilterToken{kind: jsonFilterPunct, s: expr[i : i+2], pos: i})
+				i += 2
+			} else if c == '<' || c == '>' {
+				tokens = append(tokens, jsonFilterToken{kind: jsonFilterPunct, s: expr[i : i+1], pos: i})
+				i++
+			} else {
+				return nil, ngsierr.New(funcName, 4, fmt.Sprintf("unexpected %c at %d", c, i), nil)
+			}
+		case strings.ContainsRune(".[](){}|,:*$@?", rune(c)):
+			tokens = append(tokens, jsonFilterToken{kind: jsonFilterPunct, s: expr[i : i+1], pos: i})
+			i++
+		default:
+			r, _ := utf8.DecodeRuneInString(expr[i:])
+			return nil, ngsierr.New(funcName, 5, fmt.Sprintf("unexpected %c at %d", r, i), nil)
+		}
+	}
+
+	return tokens, nil
+}
+
+func (p *jsonFilterParser) peek() *jsonFilterToken {
+	if p.i < len(p.tokens) {
+		return &p.tokens[p.i]
+	}
+	return nil
+}
+
+func (p *jsonFilterParser) isPunct(s string) bool {
+	t := p.peek()
+	return t != nil && t.kind == jsonFilterPunct && t.s == s
+}
+
+func (p *jsonFilterParser) isIdent(s string) bool {
+	t := p.peek()
+	return t != nil && t.kind == jsonFilterIdent && t.s == s
+}
+
+func (p *jsonFilterParser) expect(s string) error {
+	const funcName = "expect"
+
+	if !p.isPunct(s) {
+		return ngsierr.New(funcName, 1, fmt.Sprintf("%s expected %s", s, p.where()), nil)
+	}
+	p.i++
+	return nil
+}
+
+func (p *jsonFilterParser) where() string {
+	if t := p.peek(); t != nil {
+		return fmt.Sprintf("at %d", t.pos)
+	}
+	return "at end of expression"
+}
+
+func (p *jsonFilterParser) parsePipe() (jsonFilterFunc, error) {
+	f, err := p.parseComma()
+	if err != nil {
+		return nil, err
+	}
+	for p.isPunct("|") {
+		p.i++
+		g, err := p.parseComma()
+		if err != nil {
+			return nil, err
+		}
+		f = jsonFilterCompose(f, g)
+	}
+	return f, nil
+}
+
+func (p *jsonFilterParser) parseComma() (jsonFilterFunc, error) {
+	f, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	fs := []jsonFilterFunc{f}
+	for p.isPunct(",") {
+		p.i++
+		g, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		fs = append(fs, g)
+	}
+	if len(fs) == 1 {
+		return f, nil
+	}
+	return func(root, v interface{}) ([]interface{}, error) {
+		var results []interface{}
+		for _, f := range fs {
+			r, err := f(root, v)
+			if err != nil {
+				return nil, err
+			}
+			results = append(results, r...)
+		}
+		return results, nil
+	}, nil
+}
+
+func (p *jsonFilterParser) parseOr() (jsonFilterFunc, error) {
+	f, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.isIdent("or") {
+		p.i++
+		g, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		f = jsonFilterBinary(f, g, func(a, b interface{}) (interface{}, error) {
+			return jsonFilterTruthy(a) || jsonFilterTruthy(b), nil
+		})
+	}
+	return f, nil
+}
+
+func (p *jsonFilterParser) parseAnd() (jsonFilterFunc, error) {
+	f, err := p.parseCompare()
+	if err != nil {
+		return nil, err
+	}
+	for p.isIdent("and") {
+		p.i++
+		g, err := p.parseCompare()
+		if err != nil {
+			return nil, err
+		}
+		f = jsonFilterBinary(f, g, func(a, b interface{}) (interface{}, error) {
+			return jsonFilterTruthy(a) && jsonFilterTruthy(b), nil
+		})
+	}
+	return f, nil
+}
+
+func (p *jsonFilterParser) parseCompare() (jsonFilterFunc, error) {
+	f, err := p.parsePostfix()
+	if err != nil {
+		return nil, err
+	}
+	for _, op := range []string{"==", "!=", "<", "<=", ">", ">="} {
+		if p.isPunct(op) {
+			p.i++
+			g, err := p.parsePostfix()
+			if err != nil {
+				return nil, err
+			}
+			return jsonFilterBinary(f, g, func(a, b interface{}) (interface{}, error) {
+				c := jsonFilterCompare(a, b)
+				switch op {
+				case "==":
+					return c == 0, nil
+				case "!=":
+					return c != 0, nil
+				case "<":
+					return c < 0, nil
+				case "<=":
+					return c <= 0, nil
+				case ">":
+					return c > 0, nil
+				}
+				return c >= 0, nil
+			}), nil
+		}
+	}
+	return f, nil
+}
+
+func (p *jsonFilterParser) parsePostfix() (jsonFilterFunc, error) {
+	f, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		t := p.peek()
+		switch {
+		case t == nil:
+			return f, nil
+		case t.kind == jsonFilterField:
+			p.i++
+			f = jsonFilterCompose(f, jsonFilterKey(t.s))
+		case p.isPunct(".."):
+			p.i++
+			f, err = p.parseDescendant(f)
+			if err != nil {
+				return nil, err
+			}
+		case p.isPunct(".") && p.i+1 < len(p.tokens) && p.tokens[p.i+1].kind == jsonFilterString:
+			p.i += 2
+			f = jsonFilterCompose(f, jsonFilterKey(p.tokens[p.i-1].value.(string)))
+		case p.isPunct(".") && p.i+1 < len(p.tokens) && p.tokens[p.i+1].s == "*":
+			p.i += 2
+			f = jsonFilterCompose(f, jsonFilterIterate)
+		case p.isPunct(".") && p.i+1 < len(p.tokens) && p.tokens[p.i+1].s == "[":
+			p.i++
+		case p.isPunct("["):
+			p.i++
+			f, err = p.parseBracket(f)
+			if err != nil {
+				return nil, err
+			}
+		default:
+			return f, nil
+		}
+	}
+}
+
+// parseDescendant parses the selector following .. and applies it to f and all values in it
+func (p *jsonFilterParser) parseDescendant(f jsonFilterFunc) (jsonFilterFunc, error) {
+	t := p.peek()
+	switch {
+	case t != nil && t.kind == jsonFilterIdent:
+		p.i++
+		return jsonFilterCompose(f, jsonFilterDescendantKey(t.s)), nil
+	case p.isPunct("[") && p.i+2 < len(p.tokens) && p.tokens[p.i+1].kind == jsonFilterString && p.tokens[p.i+2].s == "]":
+		p.i += 3
+		return jsonFilterCompose(f, jsonFilterDescendantKey(p.tokens[p.i-2].value.(string))), nil
+	case p.isPunct("*"):
+		p.i++
+		return jsonFilterCompose(f, jsonFilterDescendant(jsonFilterIterate, false)), nil
+	case p.isPunct("[") && p.i+2 < len(p.tokens) && p.tokens[p.i+1].s == "*" && p.tokens[p.i+2].s == "]":
+		p.i += 3
+		return jsonFilterCompose(f, jsonFilterDescendant(jsonFilterIterate, false)), nil
+	case p.isPunct("["):
+		p.i++
+		g, err := p.parseBracket(jsonFilterIdentity)
+		if err != nil {
+			return nil, err
+		}
+		return jsonFilterCompose(f, jsonFilterDescendant(g, true)), nil
+	}
+	return jsonFilterCompose(f, jsonFilterRecurse), nil
+}
+
+func (p *jsonFilterParser) parseBracket(f jsonFilterFunc) (jsonFilterFunc, error) {
+	const funcName = "parseBracket"
+
+	switch {
+	case p.isPunct("]"):
+		p.i++
+		return jsonFilterCompose(f, jsonFilterIterate), nil
+	case p.isPunct("*"):
+		p.i++
+		if err := p.expect("]"); err != nil {
+			return nil, err
+		}
+		return jsonFilterCompose(f, jsonFilterIterate), nil
+	case p.isPunct("?"):
+		p.i++
+		if err := p.expect("("); err != nil {
+			return nil, err
+		}
+		cond, err := p.parsePipe()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+		if err := p.expect("]"); err != nil {
+			return nil, err
+		}
+		return jsonFilterCompose(f, jsonFilterCompose(jsonFilterIterate, jsonFilterSelect(cond))), nil
+	}
+
+	t := p.peek()
+	if p.isPunct(":") || (t != nil && t.kind == jsonFilterNumber && p.i+1 < len(p.tokens) && p.tokens[p.i+1].s == ":") {
+		var bounds [2]*int
+		for n := 0; n < 2; n++ {
+			if t := p.peek(); t != nil && t.kind == jsonFilterNumber {
+				i := int(t.value.(float64))
+				bounds[n] = &i
+				p.i++
+			}
+			if n == 0 {
+				if err := p.expect(":"); err != nil {
+					return nil, err
+				}
+			}
+		}
+		if err := p.expect("]"); err != nil {
+			return nil, err
+		}
+		return jsonFilterCompose(f, func(root, v interface{}) ([]interface{}, error) {
+			r, err := jsonFilterSlice(v, bounds[0], bounds[1])
+			if err != nil {
+				return nil, ngsierr.New(funcName, 1, err.Error(), err)
+			}
+			return []interface{}{r}, nil
+		}), nil
+	}
+
+	index, err := p.parsePipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect("]"); err != nil {
+		return nil, err
+	}
+	return func(root, v interface{}) ([]interface{}, error) {
+		bases, err := f(root, v)
+		if err != nil {
+			return nil, err
+		}
+		keys, err := index(root, v)
+		if err != nil {
+			return nil, err
+		}
+		var results []interface{}
+		for _, b := range bases {
+			for _, k := range keys {
+				r, err := jsonFilterIndex(b, k)
+				if err != nil {
+					return nil, err
+				}
+				results = append(results, r)
+			}
+		}
+		return results, nil
+	}, nil
+}
+
+func (p *jsonFilterParser) parsePrimary() (jsonFilterFunc, error) {
+	const funcName = "parsePrimary"
+
+	t := p.peek()
+	if t == nil {
+		return nil, ngsierr.New(funcName, 1, "unexpected end of expression", nil)
+	}
+	p.i++
+
+	switch t.kind {
+	case jsonFilterField:
+		return jsonFilterKey(t.s), nil
+	case jsonFilterNumber, jsonFilterString:
+		return jsonFilterLiteral(t.value), nil
+	case jsonFilterIdent:
+		return p.parseFunction(t)
+	}
+
+	switch t.s {
+	case "..":
+		// .. is parsed as a postfix of the identity
+		p.i--
+		return jsonFilterIdentity, nil
+	case ".":
+		if u := p.peek(); u != nil && u.kind == jsonFilterString {
+			p.i++
+			return jsonFilterKey(u.value.(string)), nil
+		}
+		return jsonFilterIdentity, nil
+	case "@":
+		return jsonFilterIdentity, nil
+	case "$":
+		return func(root, v interface{}) ([]interface{}, error) {
+			return []interface{}{root}, nil
+		}, nil
+	case "(":
+		f, err := p.parsePipe()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+		return f, nil
+	case "[":
+		if p.isPunct("]") {
+			p.i++
+			return jsonFilterLiteral([]interface{}{}), nil
+		}
+		f, err := p.parsePipe()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect("]"); err != nil {
+			return nil, err
+		}
+		return func(root, v interface{}) ([]interface{}, error) {
+			r, err := f(root, v)
+			if err != nil {
+				return nil, err
+			}
+			if r == nil {
+				r = []interface{}{}
+			}
+			return []interface{}{r}, nil
+		}, nil
+	case "{":
+		return p.parseObject()
+	}
+
+	return nil, ngsierr.New(funcName, 2, fmt.Sprintf("unexpected %s at %d", t.s, t.pos), nil)
+}
+
+func (p *jsonFilterParser) parseFunction(t *jsonFilterToken) (jsonFilterFunc, error) {
+	const funcName = "parseFunction"
+
+	switch t.s {
+	case "true":
+		return jsonFilterLiteral(true), nil
+	case "false":
+		return jsonFilterLiteral(false), nil
+	case "null":
+		return jsonFilterLiteral(nil), nil
+	case "keys":
+		return jsonFilterKeys, nil
+	case "length":
+		return jsonFilterLength, nil
+	case "not":
+		return func(root, v interface{}) ([]interface{}, error) {
+			return []interface{}{!jsonFilterTruthy(v)}, nil
+		}, nil
+	case "select", "map":
+		if err := p.expect("("); err != nil {
+			return nil, err
+		}
+		f, err := p.parsePipe()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+		if t.s == "select" {
+			return jsonFilterSelect(f), nil
+		}
+		return jsonFilterMap(f), nil
+	}
+
+	return nil, ngsierr.New(funcName, 1, fmt.Sprintf("unknown function %s at %d", t.s, t.pos), nil)
+}
+
+func (p *jsonFilterParser) parseObject() (jsonFilterFunc, error) {
+	const funcName = "parseObject"
+
+	type entry struct {
+		key   string
+		value jsonFilterFunc
+	}
+	var entries []entry
+
+	for !p.isPunct("}") {
+		if len(entries) > 0 {
+			if err := p.expect(","); err != nil {
+				return nil, err
+			}
+		}
+		t := p.peek()
+		if t == nil || (t.kind != jsonFilterIdent && t.kind != jsonFilterString) {
+			return nil, ngsierr.New(funcName, 1, "object key expected "+p.where(), nil)
+		}
+		p.i++
+		key := t.s
+		if t.kind == jsonFilterString {
+			key = t.value.(string)
+		}
+		value := jsonFilterKey(key)
+		if p.isPunct(":") {
+			p.i++
+			f, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			value = f
+		}
+		entries = append(entries, entry{key: key, value: value})
+	}
+	p.i++
+
+	return func(root, v interface{}) ([]interface{}, error) {
+		objects := []map[string]interface{}{{}}
+		for _, e := range entries {
+			values, err := e.value(root, v)
+			if err != nil {
+				return nil, err
+			}
+			var next []map[string]interface{}
+			for _, o := range objects {
+				for _, value := range values {
+					n := make(map[string]interface{}, len(o)+1)
+					for k, v := range o {
+						n[k] = v
+					}
+					n[e.key] = value
+					next = append(next, n)
+				}
+			}
+			objects = next
+		}
+		results := make([]interface{}, len(objects))
+		for i, o := range objects {
+			results[i] = o
+		}
+		return results, nil
+	}, nil
+}
+
+func jsonFilterIdentity(root, v interface{}) ([]interface{}, error) {
+	return []interface{}{v}, nil
+}
+
+func jsonFilterLiteral(value interface{}) jsonFilterFunc {
+	return func(root, v interface{}) ([]interface{}, error) {
+		return []interface{}{value}, nil
+	}
+}
+
+func jsonFilterCompose(f, g jsonFilterFunc) jsonFilterFunc {
+	return func(root, v interface{}) ([]interface{}, error) {
+		values, err := f(root, v)
+		if err != nil {
+			return nil, err
+		}
+		var results []interface{}
+		for _, value := range values {
+			r, err := g(root, value)
+			if err != nil {
+				return nil, err
+			}
+			results = append(results, r...)
+		}
+		return results, nil
+	}
+}
+
+func jsonFilterBinary(f, g jsonFilterFunc, op func(a, b interface{}) (interface{}, error)) jsonFilterFunc {
+	return func(root, v interface{}) ([]interface{}, error) {
+		as, err := f(root, v)
+		if err != nil {
+			return nil, err
+		}
+		bs, err := g(root, v)
+		if err != nil {
+			return nil, err
+		}
+		var results []interface{}
+		for _, a := range as {
+			for _, b := range bs {
+				r, err := op(a, b)
+				if err != nil {
+					return nil, err
+				}
+				results = append(results, r)
+			}
+		}
+		return results, nil
+	}
+}
+
+func jsonFilterKey(key string) jsonFilterFunc {
+	return func(root, v interface{}) ([]interface{}, error) {
+		r, err := jsonFilterIndex(v, key)
+		if err != nil {
+			return nil, err
+		}
+		return []interface{}{r}, nil
+	}
+}
+
+func jsonFilterSelect(cond jsonFilterFunc) jsonFilterFunc {
+	return func(root, v interface{}) ([]interface{}, error) {
+		values, err := cond(root, v)
+		if err != nil {
+			return nil, err
+		}
+		var results []interface{}
+		for _, value := range values {
+			if jsonFilterTruthy(value) {
+				results = append(results, v)
+			}
+		}
+		return results, nil
+	}
+}
+
+func jsonFilterMap(f jsonFilterFunc) jsonFilterFunc {
+	g := jsonFilterCompose(jsonFilterIterate, f)
+	return func(root, v interface{}) ([]interface{}, error) {
+		r, err := g(root, v)
+		if err != nil {
+			return nil, err
+		}
+		if r == nil {
+			r = []interface{}{}
+		}
+		return []interface{}{r}, nil
+	}
+}
+
+func jsonFilterIndex(v, key interface{}) (interface{}, error) {
+	const funcName = "jsonFilterIndex"
+
+	if v == nil {
+		return nil, nil
+	}
+
+	switch k := key.(type) {
+	case string:
+		if m, ok := v.(map[string]interface{}); ok {
+			return m[k], nil
+		}
+		return nil, ngsierr.New(funcName, 1, fmt.Sprintf("cannot index %s with \"%s\"", jsonFilterTypeName(v), k), nil)
+	case float64, json.Number:
+		if a, ok := v.([]interface{}); ok {
+			i := int(jsonFilterFloat(k))
+			if i < 0 {
+				i += len(a)
+			}
+			if i < 0 || i >= len(a) {
+				return nil, nil
+			}
+			return a[i], nil
+		}
+		return nil, ngsierr.New(funcName, 2, fmt.Sprintf("cannot index %s with number", jsonFilterTypeName(v)), nil)
+	}
+
+	return nil, ngsierr.New(funcName, 3, fmt.Sprintf("cannot index %s with %s", jsonFilterTypeName(v), jsonFilterTypeName(key)), nil)
+}
+
+func jsonFilterIterate(root, v interface{}) ([]interface{}, error) {
+	const funcName = "jsonFilterIterate"
+
+	switch t := v.(type) {
+	case nil:
+		return nil, nil
+	case []interface{}:
+		return t, nil
+	case map[string]interface{}:
+		keys := jsonFilterSortedKeys(t)
+		values := make([]interface{}, len(keys))
+		for i, k := range keys {
+			values[i] = t[k]
+		}
+		return values, nil
+	}
+
+	return nil, ngsierr.New(funcName, 1, fmt.Sprintf("cannot iterate over %s", jsonFilterTypeName(v)), nil)
+}
+
+// jsonFilterRecurse returns v and all values in it in depth-first order
+func jsonFilterRecurse(root, v interface{}) ([]interface{}, error) {
+	results := []interface{}{v}
+
+	switch t := v.(type) {
+	case []interface{}:
+		for _, e := range t {
+			r, _ := jsonFilterRecurse(root, e)
+			results = append(results, r...)
+		}
+	case map[string]interface{}:
+		for _, k := range jsonFilterSortedKeys(t) {
+			r, _ := jsonFilterRecurse(root, t[k])
+			results = append(results, r...)
+		}
+	}
+
+	return results, nil
+}
+
+// jsonFilterDescendant applies f to v and all arrays and objects in it. As a selector of JSONPath,
+// it skips the values f fails on. With skipNull, the null results, i.e. missing members and indexes
+// out of range, are skipped too.
+func jsonFilterDescendant(f jsonFilterFunc, skipNull bool) jsonFilterFunc {
+	return func(root, v interface{}) ([]interface{}, error) {
+		values, _ := jsonFilterRecurse(root, v)
+		var results []interface{}
+		for _, value := range values {
+			switch value.(type) {
+			case []interface{}, map[string]interface{}:
+			default:
+				continue
+			}
+			r, err := f(root, value)
+			if err != nil {
+				continue
+			}
+			for _, e := range r {
+				if e != nil || !skipNull {
+					results = append(results, e)
+				}
+			}
+		}
+		return results, nil
+	}
+}
+
+// jsonFilterDescendantKey returns the members named key of v and of all objects in it
+func jsonFilterDescendantKey(key string) jsonFilterFunc {
+	return func(root, v interface{}) ([]interface{}, error) {
+		values, _ := jsonFilterRecurse(root, v)
+		var results []interface{}
+		for _, value := range values {
+			if m, ok := value.(map[string]interface{}); ok {
+				if r, ok := m[key]; ok {
+					results = append(results, r)
+				}
+			}
+		}
+		return results, nil
+	}
+}
+
+func jsonFilterSlice(v interface{}, start, end *int) (interface{}, error) {
+	const funcName = "jsonFilterSlice"
+
+	var l int
+	switch t := v.(type) {
+	case nil:
+		return nil, nil
+	case []interface{}:
+		l = len(t)
+	case string:
+		l = utf8.RuneCountInString(t)
+	default:
+		return nil, ngsierr.New(funcName, 1, fmt.Sprintf("cannot slice %s", jsonFilterTypeName(v)), nil)
+	}
+
+	bound := func(p *int, def int) int {
+		if p == nil {
+			return def
+		}
+		i := *p
+		if i < 0 {
+			i += l
+		}
+		if i < 0 {
+			return 0
+		}
+		if i > l {
+			return l
+		}
+		return i
+	}
+	s, e := bound(start, 0), bound(end, l)
+	if e < s {
+		e = s
+	}
+
+	if a, ok := v.([]interface{}); ok {
+		return a[s:e], nil
+	}
+	return string([]rune(v.(string))[s:e]), nil
+}
+
+func jsonFilterKeys(root, v interface{}) ([]interface{}, error) {
+	const funcName = "jsonFilterKeys"
+
+	switch t := v.(type) {
+	case map[string]interface{}:
+		keys := []interface{}{}
+		for _, k := range jsonFilterSortedKeys(t) {
+			keys = append(keys, k)
+		}
+		return []interface{}{keys}, nil
+	case []interface{}:
+		keys := []interface{}{}
+		for i := range t {
+			keys = append(keys, float64(i))
+		}
+		return []interface{}{keys}, nil
+	}
+
+	return nil, ngsierr.New(funcName, 1, fmt.Sprintf("%s has no keys", jsonFilterTypeName(v)), nil)
+}
+
+func jsonFilterLength(root, v interface{}) ([]interface{}, error) {
+	const funcName = "jsonFilterLength"
+
+	switch t := v.(type) {
+	case nil:
+		return []interface{}{float64(0)}, nil
+	case string:
+		return []interface{}{float64(utf8.RuneCountInString(t))}, nil
+	case []interface{}:
+		return []interface{}{float64(len(t))}, nil
+	case map[string]interface{}:
+		return []interface{}{float64(len(t))}, nil
+	}
+
+	return nil, ngsierr.New(funcName, 1, fmt.Sprintf("%s has no length", jsonFilterTypeName(v)), nil)
+}
+
+func jsonFilterSortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func jsonFilterTruthy(v interface{}) bool {
+	return v != nil && v != false
+}
+
+func jsonFilterFloat(v interface{}) float64 {
+	switch t := v.(type) {
+	case float64:
+		return t
+	case json.Number:
+		f, _ := t.Float64()
+		return f
+	}
+	return 0
+}
+
+func jsonFilterTypeName(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64, json.Number:
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	}
+	return "object"
+}
+
+// jsonFilterCompare orders values as jq does: null < false < true < numbers < strings < arrays < objects
+func jsonFilterCompare(a, b interface{}) int {
+	rank := func(v interface{}) int {
+		switch v {
+		case nil:
+			return 0
+		case false:
+			return 1
+		case true:
+			return 2
+		}
+		switch v.(type) {
+		case float64, json.Number:
+			return 3
+		case string:
+			return 4
+		case []interface{}:
+			return 5
+		}
+		return 6
+	}
+
+	ra, rb := rank(a), rank(b)
+	if ra != rb {
+		return ra - rb
+	}
+
+	switch ra {
+	case 3:
+		x, y := jsonFilterFloat(a), jsonFilterFloat(b)
+		if x < y {
+			return -1
+		} else if x > y {
+			return 1
+		}
+		return 0
+	case 4:
+		return strings.Compare(a.(string), b.(string))
+	case 5:
+		x, y := a.([]interface{}), b.([]interface{})
+		for i := 0; i < len(x) && i < len(y); i++ {
+			if c := jsonFilterCompare(x[i], y[i]); c != 0 {
+				return c
+			}
+		}
+		return len(x) - len(y)
+	case 6:
+		// Objects are ordered by their sorted keys first and then by the values of the keys
+		x, y := a.(map[string]interface{}), b.(map[string]interface{})
+		kx, ky := jsonFilterSortedKeys(x), jsonFilterSortedKeys(y)
+		if c := jsonFilterCompare(jsonFilterStrings(kx), jsonFilterStrings(ky)); c != 0 {
+			return c
+		}
+		for _, k := range kx {
+			if c := jsonFilterCompare(x[k], y[k]); c != 0 {
+				return c
+			}
+		}
+	}
+	return 0
+}
+
+func jsonFilterStrings(s []string) []interface{} {
+	a := make([]interface{}, len(s))
+	for i, e := range s {
+		a[i] = e
+	}
+	return a
+}
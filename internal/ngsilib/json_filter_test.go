@@ -0,0 +1,491 @@
+/*
+MIT License
+
+Copyright (c) 2020-2024 Kazuhito Suda
+
+This file is part of NGSI Go
+
+https://github.com/lets-fiware/ngsi-go
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+
+*/
+
+package ngsilib
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/lets-fiware/ngsi-go/internal/assert"
+	"github.com/lets-fiware/ngsi-go/internal/ngsierr"
+)
+
+const testJSONFilterData = `[
+ {"id":"urn:ngsi-ld:Device:001","type":"Device","temperature":{"type":"Number","value":21.5},"on":true,"tags":["a","b","c"]},
+ {"id":"urn:ngsi-ld:Device:002","type":"Device","temperature":{"type":"Number","value":30},"on":false,"tags":[]},
+ {"id":"urn:ngsi-ld:Room:001","type":"Room","name":"Kitchen"}
+]`
+
+func testJSONFilterApply(t *testing.T, f *JSONFilter) string {
+	var v interface{}
+	dec := json.NewDecoder(bytesReader(testJSONFilterData))
+	dec.UseNumber()
+	_ = dec.Decode(&v)
+
+	results, err := f.Apply(v)
+	if !assert.NoError(t, err) {
+		return ""
+	}
+	b, _ := json.Marshal(results)
+	return string(b)
+}
+
+func TestJSONFilter(t *testing.T) {
+	cases := []struct {
+		expr     string
+		expected string
+	}{
+		{expr: ".[2] | .", expected: `[{"id":"urn:ngsi-ld:Room:001","name":"Kitchen","type":"Room"}]`},
+		{expr: ".[0].id", expected: `["urn:ngsi-ld:Device:001"]`},
+		{expr: ".[-1].name", expected: `["Kitchen"]`},
+		{expr: ".[5]", expected: `[null]`},
+		{expr: ".[].id", expected: `["urn:ngsi-ld:Device:001","urn:ngsi-ld:Device:002","urn:ngsi-ld:Room:001"]`},
+		{expr: ".[] | .temperature.value", expected: `[21.5,30,null]`},
+		{expr: `.[0]["temperature"].value`, expected: `[21.5]`},
+		{expr: `.[0]."type"`, expected: `["Device"]`},
+		{expr: `.[0].tags[1:]`, expected: `[["b","c"]]`},
+		{expr: `.[0].tags[:-1]`, expected: `[["a","b"]]`},
+		{expr: `.[0].id[0:3]`, expected: `["urn"]`},
+		{expr: `.[0].tags[-5:9]`, expected: `[["a","b","c"]]`},
+		{expr: `.[0].tags[2:1]`, expected: `[[]]`},
+		{expr: `.[0].temperature.*`, expected: `["Number",21.5]`},
+		{expr: `.[0].none[1:2]`, expected: `[null]`},
+		{expr: `.[0].none[]`, expected: `null`},
+		{expr: `.[] | select(.type == "Device") | .id`, expected: `["urn:ngsi-ld:Device:001","urn:ngsi-ld:Device:002"]`},
+		{expr: `.[] | select(.temperature.value >= 30) | .id`, expected: `["urn:ngsi-ld:Device:002"]`},
+		{expr: `.[] | select(.temperature.value < 30 and .on) | .id`, expected: `["urn:ngsi-ld:Device:001"]`},
+		{expr: `.[] | select(.name != null or .on == false) | .id`, expected: `["urn:ngsi-ld:Device:002","urn:ngsi-ld:Room:001"]`},
+		{expr: `.[] | select(.on | not) | .id`, expected: `["urn:ngsi-ld:Device:002","urn:ngsi-ld:Room:001"]`},
+		{expr: `.[] | select(.temperature.value > 21 and .temperature.value <= 30) | .id`, expected: `["urn:ngsi-ld:Device:001","urn:ngsi-ld:Device:002"]`},
+		{expr: `.[0] | {id, t: .temperature.value, "kind": .type}`, expected: `[{"id":"urn:ngsi-ld:Device:001","kind":"Device","t":21.5}]`},
+		{expr: `.[0] | {id, tag: .tags[]}`, expected: `[{"id":"urn:ngsi-ld:Device:001","tag":"a"},{"id":"urn:ngsi-ld:Device:001","tag":"b"},{"id":"urn:ngsi-ld:Device:001","tag":"c"}]`},
+		{expr: `[.[] | .type]`, expected: `[["Device","Device","Room"]]`},
+		{expr: `[.[] | select(.type == "None")]`, expected: `[[]]`},
+		{expr: `[]`, expected: `[[]]`},
+		{expr: `{}`, expected: `[{}]`},
+		{expr: `map(.type)`, expected: `[["Device","Device","Room"]]`},
+		{expr: `map(select(.type == "None"))`, expected: `[[]]`},
+		{expr: `.[0] | keys`, expected: `[["id","on","tags","temperature","type"]]`},
+		{expr: `.[0].tags | keys`, expected: `[[0,1,2]]`},
+		{expr: `length, (.[0] | length), (.[0].tags | length), (.[2].name | length), (.[2].none | length)`, expected: `[3,5,3,7,0]`},
+		{expr: `.[0].id, .[1].id`, expected: `["urn:ngsi-ld:Device:001","urn:ngsi-ld:Device:002"]`},
+		{expr: `true, false, null, 1, -2.5e1, "s", 's\'t'`, expected: `[true,false,null,1,-25,"s","s't"]`},
+		{expr: `.[0].tags == ["a","b","c"], .[0].tags != .[1].tags`, expected: `[true,true]`},
+		{expr: `null < false, false < true, true < 0, 0 < "a", "a" < [], [] < {}, "a" < "b", {} == {}, 1 < 1`, expected: `[true,true,true,true,true,true,true,true,false]`},
+		{expr: `$[0].id`, expected: `["urn:ngsi-ld:Device:001"]`},
+		{expr: `$[*].type`, expected: `["Device","Device","Room"]`},
+		{expr: `$.*.type`, expected: `["Device","Device","Room"]`},
+		{expr: `$[?(@.type == 'Room')].name`, expected: `["Kitchen"]`},
+		{expr: `$[0]['temperature']['value']`, expected: `[21.5]`},
+		{expr: `.[0] | .tags[$[1].tags | length]`, expected: `["a"]`},
+	}
+
+	for _, c := range cases {
+		f, err := NewJSONFilter(c.expr)
+		if assert.NoError(t, err) {
+			assert.Equal(t, c.expected, testJSONFilterApply(t, f), c.expr)
+		}
+	}
+}
+
+func TestJSONFilterApplyObject(t *testing.T) {
+	f, _ := NewJSONFilter(".[]")
+
+	actual, err := f.Apply(map[string]interface{}{"b": 2.0, "a": 1.0})
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, []interface{}{1.0, 2.0}, actual)
+	}
+}
+
+func TestJSONFilterError(t *testing.T) {
+	cases := []struct {
+		expr     string
+		errNo    int
+		expected string
+	}{
+		{expr: "", errNo: 2, expected: "filter expression is empty"},
+		{expr: ".a b", errNo: 4, expected: "unexpected b at 3"},
+		{expr: "1e", errNo: 1, expected: "invalid number 1e at 0"},
+		{expr: `"abc`, errNo: 1, expected: "unterminated string at 0"},
+		{expr: `"\x"`, errNo: 1, expected: `invalid string "\x" at 0`},
+		{expr: `.a = 1`, errNo: 1, expected: "unexpected = at 3"},
+		{expr: `.a # 1`, errNo: 1, expected: "unexpected # at 3"},
+		{expr: `.a | `, errNo: 3, expected: "unexpected end of expression"},
+		{expr: `(.a`, errNo: 3, expected: ") expected at end of expression"},
+		{expr: `]`, errNo: 3, expected: "unexpected ] at 0"},
+		{expr: `foo`, errNo: 3, expected: "unknown function foo at 0"},
+		{expr: `select .a`, errNo: 3, expected: "( expected at 7"},
+		{expr: `select(.a`, errNo: 3, expected: ") expected at end of expression"},
+		{expr: `select(|)`, errNo: 3, expected: "unexpected | at 7"},
+		{expr: `{1}`, errNo: 3, expected: "object key expected at 1"},
+		{expr: `{a b}`, errNo: 3, expected: ", expected at 3"},
+		{expr: `{a: |}`, errNo: 3, expected: "unexpected | at 4"},
+		{expr: `[.a`, errNo: 3, expected: "] expected at end of expression"},
+		{expr: `[|]`, errNo: 3, expected: "unexpected | at 1"},
+		{expr: `.[*`, errNo: 3, expected: "] expected at end of expression"},
+		{expr: `.[?.a]`, errNo: 3, expected: "( expected at 3"},
+		{expr: `.[?(|)]`, errNo: 3, expected: "unexpected | at 4"},
+		{expr: `.[?(.a]`, errNo: 3, expected: ") expected at 6"},
+		{expr: `.[?(.a)`, errNo: 3, expected: "] expected at end of expression"},
+		{expr: `.[1:2`, errNo: 3, expected: "] expected at end of expression"},
+		{expr: `.[|]`, errNo: 3, expected: "unexpected | at 2"},
+		{expr: `.[.a`, errNo: 3, expected: "] expected at end of expression"},
+		{expr: `.a, |`, errNo: 3, expected: "unexpected | at 4"},
+		{expr: `.a or |`, errNo: 3, expected: "unexpected | at 6"},
+		{expr: `.a and |`, errNo: 3, expected: "unexpected | at 7"},
+		{expr: `.a == |`, errNo: 3, expected: "unexpected | at 6"},
+		{expr: `(|)`, errNo: 3, expected: "unexpected | at 1"},
+		{expr: `.a[|]`, errNo: 3, expected: "unexpected | at 3"},
+	}
+
+	for _, c := range cases {
+		_, err := NewJSONFilter(c.expr)
+		if assert.Error(t, err, c.expr) {
+			ngsiErr := err.(*ngsierr.NgsiError)
+			assert.Equal(t, c.errNo, ngsiErr.ErrNo, c.expr)
+			assert.Equal(t, c.expected, ngsiErr.Message, c.expr)
+		}
+	}
+}
+
+func TestJSONFilterApplyError(t *testing.T) {
+	cases := []struct {
+		expr     string
+		expected string
+	}{
+		{expr: ".[0].id.x", expected: `cannot index string with "x"`},
+		{expr: ".[0][0]", expected: "cannot index object with number"},
+		{expr: `.[true]`, expected: "cannot index array with boolean"},
+		{expr: ".[0].id[]", expected: "cannot iterate over string"},
+		{expr: ".[0].on[1:]", expected: "cannot slice boolean"},
+		{expr: ".[0].on | keys", expected: "boolean has no keys"},
+		{expr: ".[0].on | length", expected: "boolean has no length"},
+		{expr: ".[0].id, .[0].id.x", expected: `cannot index string with "x"`},
+		{expr: "(.[0].id.x) or true", expected: `cannot index string with "x"`},
+		{expr: "true or (.[0].id.x)", expected: `cannot index string with "x"`},
+		{expr: "[.[0].id.x]", expected: `cannot index string with "x"`},
+		{expr: "{a: .[0].id.x}", expected: `cannot index string with "x"`},
+		{expr: "select(.[0].id.x)", expected: `cannot index string with "x"`},
+		{expr: ".[0] | map(.x)", expected: `cannot index string with "x"`},
+		{expr: ".[.[0].id.x]", expected: `cannot index string with "x"`},
+		{expr: "(.[0].id.x)[0]", expected: `cannot index string with "x"`},
+		{expr: ".[0] | .tags[.id]", expected: `cannot index array with "urn:ngsi-ld:Device:001"`},
+		{expr: ".[0] | .id | .x", expected: `cannot index string with "x"`},
+	}
+
+	for _, c := range cases {
+		f, err := NewJSONFilter(c.expr)
+		if assert.NoError(t, err, c.expr) {
+			var v interface{}
+			_ = json.Unmarshal([]byte(testJSONFilterData), &v)
+			_, err = f.Apply(v)
+			if assert.Error(t, err, c.expr) {
+				ngsiErr := err.(*ngsierr.NgsiError)
+				assert.Equal(t, 1, ngsiErr.ErrNo, c.expr)
+				assert.Equal(t, c.expected, ngsiErr.Message, c.expr)
+			}
+		}
+	}
+}
+
+func TestJSONFilterMatch(t *testing.T) {
+	cases := []struct {
+		expr     string
+		expected bool
+	}{
+		{expr: ".[0].on", expected: true},
+		{expr: `.[0].type == "Device"`, expected: true},
+		{expr: `.[0].type == "Room"`, expected: false},
+		{expr: ".[].id", expected: true},
+		{expr: ".[0].none", expected: false},
+		{expr: ".[] | select(.id == \"x\")", expected: false},
+	}
+
+	for _, c := range cases {
+		f, err := NewJSONFilter(c.expr)
+		if assert.NoError(t, err, c.expr) {
+			var v interface{}
+			_ = json.Unmarshal([]byte(testJSONFilterData), &v)
+			actual, err := f.Match(v)
+			if assert.NoError(t, err, c.expr) {
+				assert.Equal(t, c.expected, actual, c.expr)
+			}
+		}
+	}
+}
+
+func TestJSONFilterMatchError(t *testing.T) {
+	f, _ := NewJSONFilter(".[0].id.x")
+
+	var v interface{}
+	_ = json.Unmarshal([]byte(testJSONFilterData), &v)
+	_, err := f.Match(v)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 1, ngsiErr.ErrNo)
+		assert.Equal(t, `cannot index string with "x"`, ngsiErr.Message)
+	}
+}
+
+func TestNewJSONPath(t *testing.T) {
+	f, err := NewJSONPath("$[0].type")
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, `["Device"]`, testJSONFilterApply(t, f))
+	}
+}
+
+func TestNewJSONPathErrorRoot(t *testing.T) {
+	_, err := NewJSONPath(".[0]")
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 1, ngsiErr.ErrNo)
+		assert.Equal(t, "JSONPath must start with $: .[0]", ngsiErr.Message)
+	}
+}
+
+func TestNewJSONPathErrorParse(t *testing.T) {
+	_, err := NewJSONPath("$[0")
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 2, ngsiErr.ErrNo)
+		assert.Equal(t, "] expected at end of expression", ngsiErr.Message)
+	}
+}
+
+const testJSONPathStore = `{"store":{
+ "book":[
+  {"category":"reference","author":"Nigel Rees","title":"Sayings of the Century","price":8.95},
+  {"category":"fiction","author":"Evelyn Waugh","title":"Sword of Honour","price":12.99},
+  {"category":"fiction","author":"Herman Melville","title":"Moby Dick","isbn":"0-553-21311-3","price":8.99},
+  {"category":"fiction","author":"J. R. R. Tolkien","title":"The Lord of the Rings","isbn":"0-395-19395-8","price":22.99}
+ ],
+ "bicycle":{"color":"red","price":19.95}
+}}`
+
+func testJSONFilterApplyData(t *testing.T, expr, data string) string {
+	f, err := NewJSONFilter(expr)
+	if !assert.NoError(t, err, expr) {
+		return ""
+	}
+
+	var v interface{}
+	dec := json.NewDecoder(bytesReader(data))
+	dec.UseNumber()
+	_ = dec.Decode(&v)
+
+	results, err := f.Apply(v)
+	if !assert.NoError(t, err, expr) {
+		return ""
+	}
+	b, _ := json.Marshal(results)
+	return string(b)
+}
+
+func TestJSONFilterJSONPath(t *testing.T) {
+	cases := []struct {
+		expr     string
+		expected string
+	}{
+		{expr: `$.store.book[*].author`, expected: `["Nigel Rees","Evelyn Waugh","Herman Melville","J. R. R. Tolkien"]`},
+		{expr: `$..author`, expected: `["Nigel Rees","Evelyn Waugh","Herman Melville","J. R. R. Tolkien"]`},
+		{expr: `[$.store.*] | length`, expected: `[2]`},
+		{expr: `$.store..price`, expected: `[19.95,8.95,12.99,8.99,22.99]`},
+		{expr: `$..book[2].title`, expected: `["Moby Dick"]`},
+		{expr: `$..book[-1:][0].title`, expected: `["The Lord of the Rings"]`},
+		{expr: `$..book[0,1].title`, expected: `["Sayings of the Century","Sword of Honour"]`},
+		{expr: `$..book[:2] | length`, expected: `[2]`},
+		{expr: `$..book[?(@.isbn)].title`, expected: `["Moby Dick","The Lord of the Rings"]`},
+		{expr: `$..book[?(@.price<10)].title`, expected: `["Sayings of the Century","Moby Dick"]`},
+		{expr: `$..book[?(@.author == 'Herman Melville')]['title']`, expected: `["Moby Dick"]`},
+		{expr: `$.store.book[?(@.price >= 8.99 and @.price <= 12.99)].title`, expected: `["Sword of Honour","Moby Dick"]`},
+		{expr: `$..book[?(@.category != 'fiction' or @.price > 20)].title`, expected: `["Sayings of the Century","The Lord of the Rings"]`},
+		{expr: `$..[?(@.price > 20)].title`, expected: `["The Lord of the Rings"]`},
+		{expr: `$..bicycle..color`, expected: `["red"]`},
+		{expr: `$..price | select(. > 10)`, expected: `[19.95,12.99,22.99]`},
+		{expr: `[$..*] | length`, expected: `[27]`},
+		{expr: `[$..book[*] | select(.category == "fiction") | .price] | length`, expected: `[3]`},
+		{expr: `$..nothing`, expected: `null`},
+		{expr: `$..['author','title'] | length`, expected: `[10,22,12,15,15,9,16,21]`},
+		{expr: `$..book[1:2][0].author`, expected: `["Evelyn Waugh"]`},
+		{expr: `$..book[9]`, expected: `[null]`},
+		{expr: `$..[9]`, expected: `null`},
+	}
+
+	for _, c := range cases {
+		assert.Equal(t, c.expected, testJSONFilterApplyData(t, c.expr, testJSONPathStore), c.expr)
+	}
+}
+
+func TestJSONFilterRecursiveDescent(t *testing.T) {
+	data := `{"a":[1,{"id":"x","b":null}],"c":{"id":"y","d":[[2]]},"id":"z"}`
+
+	cases := []struct {
+		expr     string
+		expected string
+	}{
+		{expr: `[..]`, expected: `[[{"a":[1,{"b":null,"id":"x"}],"c":{"d":[[2]],"id":"y"},"id":"z"},[1,{"b":null,"id":"x"}],1,{"b":null,"id":"x"},null,"x",{"d":[[2]],"id":"y"},[[2]],[2],2,"y","z"]]`},
+		{expr: `.a..`, expected: `[[1,{"b":null,"id":"x"}],1,{"b":null,"id":"x"},null,"x"]`},
+		{expr: `.. | select(. == 2)`, expected: `[2]`},
+		{expr: `$..id`, expected: `["z","x","y"]`},
+		{expr: `..id`, expected: `["z","x","y"]`},
+		{expr: `$..["id"]`, expected: `["z","x","y"]`},
+		{expr: `$..b`, expected: `[null]`},
+		{expr: `$..["b"]`, expected: `[null]`},
+		{expr: `$..[0]`, expected: `[1,[2],2]`},
+		{expr: `$..[*] | select(. == 2)`, expected: `[2]`},
+		{expr: `[$..*] | length, ([$..[*]] | length)`, expected: `[11,11]`},
+		{expr: `$.c..id`, expected: `["y"]`},
+		{expr: `$..c..[0]`, expected: `[[2],2]`},
+		{expr: `$..a[1].id`, expected: `["x"]`},
+		{expr: `. ..`, expected: `[{"a":[1,{"b":null,"id":"x"}],"c":{"d":[[2]],"id":"y"},"id":"z"},[1,{"b":null,"id":"x"}],1,{"b":null,"id":"x"},null,"x",{"d":[[2]],"id":"y"},[[2]],[2],2,"y","z"]`},
+	}
+
+	for _, c := range cases {
+		assert.Equal(t, c.expected, testJSONFilterApplyData(t, c.expr, data), c.expr)
+	}
+}
+
+func TestJSONFilterRecursiveDescentError(t *testing.T) {
+	cases := []struct {
+		expr     string
+		expected string
+	}{
+		{expr: `$..[`, expected: "unexpected end of expression"},
+		{expr: `$..[|]`, expected: "unexpected | at 4"},
+		{expr: `$..[?(`, expected: "unexpected end of expression"},
+		{expr: `$..[*`, expected: "] expected at end of expression"},
+		{expr: `$..[1:`, expected: "] expected at end of expression"},
+		{expr: `$..*.`, expected: "unexpected . at 4"},
+		{expr: `$..1`, expected: "unexpected 1 at 3"},
+		{expr: `$..(`, expected: "unexpected ( at 3"},
+	}
+
+	for _, c := range cases {
+		_, err := NewJSONFilter(c.expr)
+		if assert.Error(t, err, c.expr) {
+			ngsiErr := err.(*ngsierr.NgsiError)
+			assert.Equal(t, c.expected, ngsiErr.Message, c.expr)
+		}
+	}
+}
+
+func TestJSONFilterRecursiveDescentApplyError(t *testing.T) {
+	f, _ := NewJSONFilter(`.. | .id`)
+
+	_, err := f.Apply([]interface{}{1.0})
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 1, ngsiErr.ErrNo)
+		assert.Equal(t, `cannot index array with "id"`, ngsiErr.Message)
+	}
+}
+
+func TestJSONFilterOperators(t *testing.T) {
+	data := `{"a":[1,2,3],"b":{"c":[2]},"n":1.5,"m":1,"s":"日本語","big":12345678901234567890}`
+
+	cases := []struct {
+		expr     string
+		expected string
+	}{
+		{expr: `1 == 1.0`, expected: `[true]`},
+		{expr: `.n == 1.5, .n < 2, .n > .m, .m >= 1, .m <= 0`, expected: `[true,true,true,true,false]`},
+		{expr: `.big == 12345678901234567890`, expected: `[true]`},
+		{expr: `1 == 1 and 2 < 1 or true`, expected: `[true]`},
+		{expr: `true or 2 < 1 and false`, expected: `[true]`},
+		{expr: `(true or false) and false`, expected: `[false]`},
+		{expr: `.a, .b | length`, expected: `[3,1]`},
+		{expr: `(.a | length) == 3`, expected: `[true]`},
+		{expr: `.s | length`, expected: `[3]`},
+		{expr: `.s[1:]`, expected: `["本語"]`},
+		{expr: `.a[-1], .a[1:-1]`, expected: `[3,[2]]`},
+		{expr: `[.a[] | select(. >= 2)]`, expected: `[[2,3]]`},
+		{expr: `.a | map(. == 2)`, expected: `[[false,true,false]]`},
+		{expr: `[.a[], .n] | length`, expected: `[4]`},
+		{expr: `not, (.a | not), (null | not), (false | not)`, expected: `[false,false,true,true]`},
+		{expr: `{a: .a[], b: .b.c}`, expected: `[{"a":1,"b":[2]},{"a":2,"b":[2]},{"a":3,"b":[2]}]`},
+		{expr: `{"x y": .n, m}`, expected: `[{"m":1,"x y":1.5}]`},
+		{expr: `{a: (.a | length), s}`, expected: `[{"a":3,"s":"日本語"}]`},
+		{expr: `[.a, .b] == [[1,2,3],{"c":[2]}]`, expected: `[true]`},
+		{expr: `[1,2] < [1,3], [1] < [1,0], [9] < [10], {"a":1} < {"a":2}, {"a":1} < {"b":0}, {"b":0} < {"a":1,"b":0}`, expected: `[true,true,true,true,true,false]`},
+		{expr: `$.b.c[0], @.m`, expected: `[2,1]`},
+		{expr: `.b | .c[0] | . == $.a[1]`, expected: `[true]`},
+	}
+
+	for _, c := range cases {
+		assert.Equal(t, c.expected, testJSONFilterApplyData(t, c.expr, data), c.expr)
+	}
+}
+
+func TestJSONFilterTokenize(t *testing.T) {
+	actual, err := jsonFilterTokenize(`$..a[?(@.b >= -1 and "x" != 'y')]`)
+
+	if assert.NoError(t, err) {
+		expected := []jsonFilterToken{
+			{kind: jsonFilterPunct, s: "$", pos: 0},
+			{kind: jsonFilterPunct, s: "..", pos: 1},
+			{kind: jsonFilterIdent, s: "a", pos: 3},
+			{kind: jsonFilterPunct, s: "[", pos: 4},
+			{kind: jsonFilterPunct, s: "?", pos: 5},
+			{kind: jsonFilterPunct, s: "(", pos: 6},
+			{kind: jsonFilterPunct, s: "@", pos: 7},
+			{kind: jsonFilterField, s: "b", pos: 8},
+			{kind: jsonFilterPunct, s: ">=", pos: 11},
+			{kind: jsonFilterNumber, s: "-1", value: -1.0, pos: 14},
+			{kind: jsonFilterIdent, s: "and", pos: 17},
+			{kind: jsonFilterString, s: `"x"`, value: "x", pos: 21},
+			{kind: jsonFilterPunct, s: "!=", pos: 25},
+			{kind: jsonFilterString, s: `'y'`, value: "y", pos: 28},
+			{kind: jsonFilterPunct, s: ")", pos: 31},
+			{kind: jsonFilterPunct, s: "]", pos: 32},
+		}
+		assert.Equal(t, expected, actual)
+	}
+}
+
+func TestJSONFilterTokenizeErrorRune(t *testing.T) {
+	_, err := jsonFilterTokenize(`.a | é`)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 5, ngsiErr.ErrNo)
+		assert.Equal(t, "unexpected é at 5", ngsiErr.Message)
+	}
+}
+
+func bytesReader(s string) *bytes.Reader {
+	return bytes.NewReader([]byte(s))
+}
@@ -0,0 +1,169 @@
+/*
+MIT License
+
+Copyright (c) 2020-2024 Kazuhito Suda
+
+This file is part of NGSI Go
+
+https://github.com/lets-fiware/ngsi-go
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+
+*/
+
+package ngsilib
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/lets-fiware/ngsi-go/internal/ngsierr"
+)
+
+// jsonStreamWindow is the number of bytes kept to show the context of a syntax error
+const jsonStreamWindow = 4096
+
+// JSONArrayStream decodes a JSON array from r and calls f with each element one by one.
+// When geoJSON is true, the elements are read from the features of a GeoJSON FeatureCollection.
+// It returns the number of elements decoded.
+func JSONArrayStream(r io.Reader, geoJSON bool, f func(json.RawMessage) error) (int, error) {
+	const funcName = "JSONArrayStream"
+
+	s := newJSONStream(r)
+
+	if geoJSON {
+		if err := s.findMember("features"); err != nil {
+			return 0, ngsierr.New(funcName, 1, err.Error(), err)
+		}
+	}
+
+	if err := s.expectDelim('['); err != nil {
+		return 0, ngsierr.New(funcName, 2, err.Error(), err)
+	}
+
+	n := 0
+	for s.dec.More() {
+		var e json.RawMessage
+		if err := s.dec.Decode(&e); err != nil {
+			return n, ngsierr.New(funcName, 3, s.errorMessage(err), err)
+		}
+		if err := f(e); err != nil {
+			return n, err
+		}
+		n++
+	}
+
+	if err := s.expectDelim(']'); err != nil {
+		return n, ngsierr.New(funcName, 4, err.Error(), err)
+	}
+
+	return n, nil
+}
+
+type jsonStream struct {
+	dec *json.Decoder
+	r   *jsonStreamReader
+}
+
+func newJSONStream(r io.Reader) *jsonStream {
+	sr := &jsonStreamReader{r: r}
+	return &jsonStream{dec: json.NewDecoder(sr), r: sr}
+}
+
+// errorMessage adds the offset and the text around it to a syntax error in the same format as jsonUnmarshal.
+func (s *jsonStream) errorMessage(err error) string {
+	var offset int64
+	switch e := err.(type) {
+	case *json.SyntaxError:
+		offset = e.Offset
+	case *json.UnmarshalTypeError:
+		offset = e.Offset
+	default:
+		return err.Error()
+	}
+	return fmt.Sprintf("%s (%d) %s", err.Error(), offset, s.r.around(offset))
+}
+
+func (s *jsonStream) findMember(name string) error {
+	const funcName = "jsonFindMember"
+
+	if err := s.expectDelim('{'); err != nil {
+		return ngsierr.New(funcName, 1, err.Error(), err)
+	}
+	for s.dec.More() {
+		t, err := s.dec.Token()
+		if err != nil {
+			return ngsierr.New(funcName, 2, s.errorMessage(err), err)
+		}
+		if t == name {
+			return nil
+		}
+		var v json.RawMessage
+		if err := s.dec.Decode(&v); err != nil {
+			return ngsierr.New(funcName, 3, s.errorMessage(err), err)
+		}
+	}
+	return ngsierr.New(funcName, 4, name+" not found", nil)
+}
+
+func (s *jsonStream) expectDelim(delim json.Delim) error {
+	const funcName = "jsonExpectDelim"
+
+	t, err := s.dec.Token()
+	if err != nil {
+		return ngsierr.New(funcName, 1, s.errorMessage(err), err)
+	}
+	if d, ok := t.(json.Delim); !ok || d != delim {
+		return ngsierr.New(funcName, 2, fmt.Sprintf("%v found, expected %v", t, delim), nil)
+	}
+	return nil
+}
+
+// jsonStreamReader keeps the last bytes read from r so that a syntax error can show where it occurred.
+type jsonStreamReader struct {
+	r      io.Reader
+	buf    []byte
+	offset int64
+}
+
+func (r *jsonStreamReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.buf = append(r.buf, p[:n]...)
+	if l := len(r.buf); l > jsonStreamWindow {
+		r.offset += int64(l - jsonStreamWindow)
+		r.buf = append(r.buf[:0], r.buf[l-jsonStreamWindow:]...)
+	}
+	return n, err
+}
+
+func (r *jsonStreamReader) around(offset int64) string {
+	s := offset - 15 - r.offset
+	if s < 0 {
+		s = 0
+	}
+	e := offset + 15 - r.offset
+	if e > int64(len(r.buf)) {
+		e = int64(len(r.buf))
+	}
+	if s >= e {
+		return ""
+	}
+	return string(r.buf[s:e])
+}
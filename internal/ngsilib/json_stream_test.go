@@ -0,0 +1,231 @@
+/*
+MIT License
+
+Copyright (c) 2020-2024 Kazuhito Suda
+
+This file is part of NGSI Go
+
+https://github.com/lets-fiware/ngsi-go
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+
+*/
+
+package ngsilib
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/lets-fiware/ngsi-go/internal/assert"
+	"github.com/lets-fiware/ngsi-go/internal/ngsierr"
+)
+
+func TestJSONArrayStream(t *testing.T) {
+	r := strings.NewReader(`[{"id":"device001","type":"Device"}, {"id":"device002","type":"Device"}]`)
+
+	var actual []string
+	n, err := JSONArrayStream(r, false, func(e json.RawMessage) error {
+		actual = append(actual, string(e))
+		return nil
+	})
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, 2, n)
+		assert.Equal(t, []string{`{"id":"device001","type":"Device"}`, `{"id":"device002","type":"Device"}`}, actual)
+	}
+}
+
+func TestJSONArrayStreamEmpty(t *testing.T) {
+	n, err := JSONArrayStream(strings.NewReader(`[]`), false, func(e json.RawMessage) error {
+		return errors.New("should not be called")
+	})
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, 0, n)
+	}
+}
+
+func TestJSONArrayStreamGeoJSON(t *testing.T) {
+	r := strings.NewReader(`{"type":"FeatureCollection","@context":["http://context"],"features":[{"id":"urn:ngsi-ld:Device:001","type":"Feature"}]}`)
+
+	var actual []string
+	n, err := JSONArrayStream(r, true, func(e json.RawMessage) error {
+		actual = append(actual, string(e))
+		return nil
+	})
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, 1, n)
+		assert.Equal(t, []string{`{"id":"urn:ngsi-ld:Device:001","type":"Feature"}`}, actual)
+	}
+}
+
+func TestJSONArrayStreamErrorGeoJSON(t *testing.T) {
+	_, err := JSONArrayStream(strings.NewReader(`{"type":"FeatureCollection"}`), true, nil)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 1, ngsiErr.ErrNo)
+		assert.Equal(t, "features not found", ngsiErr.Message)
+	}
+}
+
+func TestJSONArrayStreamErrorNotArray(t *testing.T) {
+	_, err := JSONArrayStream(strings.NewReader(`{"id":"device001"}`), false, nil)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 2, ngsiErr.ErrNo)
+		assert.Equal(t, "{ found, expected [", ngsiErr.Message)
+	}
+}
+
+func TestJSONArrayStreamErrorDecode(t *testing.T) {
+	n, err := JSONArrayStream(strings.NewReader(`[{"id":"device001"},{"id":}]`), false, func(e json.RawMessage) error {
+		return nil
+	})
+
+	if assert.Error(t, err) {
+		assert.Equal(t, 1, n)
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 3, ngsiErr.ErrNo)
+		assert.Equal(t, "invalid character '}' after array element (27) ce001\"},{\"id\":}]", ngsiErr.Message)
+	}
+}
+
+func TestJSONArrayStreamErrorFunc(t *testing.T) {
+	_, err := JSONArrayStream(strings.NewReader(`[{"id":"device001"}]`), false, func(e json.RawMessage) error {
+		return errors.New("func error")
+	})
+
+	if assert.Error(t, err) {
+		assert.Equal(t, "func error", err.Error())
+	}
+}
+
+func TestJSONArrayStreamErrorEOF(t *testing.T) {
+	_, err := JSONArrayStream(strings.NewReader(`[{"id":"device001"}`), false, func(e json.RawMessage) error {
+		return nil
+	})
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 3, ngsiErr.ErrNo)
+		assert.Equal(t, "unexpected end of JSON input (19) d\":\"device001\"}", ngsiErr.Message)
+	}
+}
+
+func TestJSONArrayStreamErrorClose(t *testing.T) {
+	_, err := JSONArrayStream(strings.NewReader(`[{"id":"device001"}}`), false, func(e json.RawMessage) error {
+		return nil
+	})
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 4, ngsiErr.ErrNo)
+		assert.Equal(t, "invalid character '}' after array element (20) \":\"device001\"}}", ngsiErr.Message)
+	}
+}
+
+func TestJSONArrayStreamErrorDecodeLarge(t *testing.T) {
+	var b strings.Builder
+	b.WriteString("[")
+	for i := 0; i < 1000; i++ {
+		b.WriteString(`{"id":"device001"},`)
+	}
+	b.WriteString(`{"id":}]`)
+
+	_, err := JSONArrayStream(strings.NewReader(b.String()), false, func(e json.RawMessage) error {
+		return nil
+	})
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 3, ngsiErr.ErrNo)
+		assert.Equal(t, "invalid character '}' after array element (19008) ce001\"},{\"id\":}]", ngsiErr.Message)
+	}
+}
+
+func TestJSONStreamErrorMessage(t *testing.T) {
+	s := newJSONStream(strings.NewReader(""))
+
+	assert.Equal(t, "EOF", s.errorMessage(io.EOF))
+	assert.Equal(t, "json: cannot unmarshal string into Go value of type int (3) ", s.errorMessage(&json.UnmarshalTypeError{Value: "string", Type: reflect.TypeOf(0), Offset: 3}))
+}
+
+func TestJSONStreamReaderAround(t *testing.T) {
+	r := &jsonStreamReader{buf: []byte("0123456789"), offset: 100}
+
+	assert.Equal(t, "0123456789", r.around(105))
+	assert.Equal(t, "", r.around(50))
+	assert.Equal(t, "", r.around(200))
+}
+
+func TestJSONFindMemberErrorNotObject(t *testing.T) {
+	s := newJSONStream(strings.NewReader(`[]`))
+
+	err := s.findMember("features")
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 1, ngsiErr.ErrNo)
+		assert.Equal(t, "[ found, expected {", ngsiErr.Message)
+	}
+}
+
+func TestJSONFindMemberErrorToken(t *testing.T) {
+	s := newJSONStream(strings.NewReader(`{`))
+
+	err := s.findMember("features")
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 2, ngsiErr.ErrNo)
+		assert.Equal(t, "unexpected end of JSON input (1) {", ngsiErr.Message)
+	}
+}
+
+func TestJSONFindMemberErrorValue(t *testing.T) {
+	s := newJSONStream(strings.NewReader(`{"type":}`))
+
+	err := s.findMember("features")
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 3, ngsiErr.ErrNo)
+		assert.Equal(t, "invalid character '}' looking for beginning of value (9) {\"type\":}", ngsiErr.Message)
+	}
+}
+
+func TestJSONExpectDelimErrorEOF(t *testing.T) {
+	s := newJSONStream(strings.NewReader(``))
+
+	err := s.expectDelim('[')
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 1, ngsiErr.ErrNo)
+		assert.Equal(t, "EOF", ngsiErr.Message)
+	}
+}
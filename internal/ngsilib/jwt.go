@@ -0,0 +1,135 @@
+/*
+MIT License
+
+Copyright (c) 2020-2024 Kazuhito Suda
+
+This file is part of NGSI Go
+
+https://github.com/lets-fiware/ngsi-go
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+
+*/
+
+package ngsilib
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lets-fiware/ngsi-go/internal/ngsierr"
+)
+
+// JWT is a decoded JSON Web Token. The signature is not verified.
+type JWT struct {
+	Header   json.RawMessage `json:"header"`
+	Claims   json.RawMessage `json:"claims"`
+	Issuer   string          `json:"issuer,omitempty"`
+	Subject  string          `json:"subject,omitempty"`
+	Audience []string        `json:"audience,omitempty"`
+	Roles    []string        `json:"roles,omitempty"`
+	IssuedAt string          `json:"issuedAt,omitempty"`
+	Expires  string          `json:"expires,omitempty"`
+}
+
+type jwtClaims struct {
+	Iss            string              `json:"iss"`
+	Sub            string              `json:"sub"`
+	Aud            interface{}         `json:"aud"`
+	Exp            int64               `json:"exp"`
+	Iat            int64               `json:"iat"`
+	Roles          []string            `json:"roles"`
+	RealmAccess    jwtRoles            `json:"realm_access"`
+	ResourceAccess map[string]jwtRoles `json:"resource_access"`
+}
+
+type jwtRoles struct {
+	Roles []string `json:"roles"`
+}
+
+// DecodeJWT decodes the header and the claims of a JSON Web Token without verifying its signature
+func DecodeJWT(token string) (*JWT, error) {
+	const funcName = "DecodeJWT"
+
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ngsierr.New(funcName, 1, "token is not a JWT", nil)
+	}
+
+	header, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[0], "="))
+	if err != nil {
+		return nil, ngsierr.New(funcName, 2, "header: "+err.Error(), err)
+	}
+	if !json.Valid(header) {
+		return nil, ngsierr.New(funcName, 3, "header is not JSON", nil)
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return nil, ngsierr.New(funcName, 4, "claims: "+err.Error(), err)
+	}
+
+	var claims jwtClaims
+	if err := JSONUnmarshal(payload, &claims); err != nil {
+		return nil, ngsierr.New(funcName, 5, "claims: "+err.Error(), err)
+	}
+
+	jwt := &JWT{
+		Header:  header,
+		Claims:  payload,
+		Issuer:  claims.Iss,
+		Subject: claims.Sub,
+	}
+
+	switch aud := claims.Aud.(type) {
+	case string:
+		jwt.Audience = []string{aud}
+	case []interface{}:
+		for _, v := range aud {
+			if s, ok := v.(string); ok {
+				jwt.Audience = append(jwt.Audience, s)
+			}
+		}
+	}
+
+	jwt.Roles = append(jwt.Roles, claims.Roles...)
+	jwt.Roles = append(jwt.Roles, claims.RealmAccess.Roles...)
+	clients := make([]string, 0, len(claims.ResourceAccess))
+	for k := range claims.ResourceAccess {
+		clients = append(clients, k)
+	}
+	sort.Strings(clients)
+	for _, k := range clients {
+		for _, role := range claims.ResourceAccess[k].Roles {
+			jwt.Roles = append(jwt.Roles, k+":"+role)
+		}
+	}
+
+	if claims.Iat != 0 {
+		jwt.IssuedAt = time.Unix(claims.Iat, 0).UTC().Format(time.RFC3339)
+	}
+	if claims.Exp != 0 {
+		jwt.Expires = time.Unix(claims.Exp, 0).UTC().Format(time.RFC3339)
+	}
+
+	return jwt, nil
+}
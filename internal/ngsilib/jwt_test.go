@@ -0,0 +1,126 @@
+/*
+MIT License
+
+Copyright (c) 2020-2024 Kazuhito Suda
+
+This file is part of NGSI Go
+
+https://github.com/lets-fiware/ngsi-go
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+
+*/
+
+package ngsilib
+
+import (
+	"testing"
+
+	"github.com/lets-fiware/ngsi-go/internal/assert"
+	"github.com/lets-fiware/ngsi-go/internal/ngsierr"
+)
+
+func TestDecodeJWT(t *testing.T) {
+	testNgsiLibInit()
+
+	token := "eyJhbGciOiJSUzI1NiIsInR5cCI6IkpXVCJ9.eyJpc3MiOiJodHRwOi8vaWRtL3JlYWxtcy9maXdhcmUiLCJzdWIiOiJ1MSIsImF1ZCI6WyJuZ3NpIiwiYWNjb3VudCJdLCJleHAiOjE3MDAwMDAwMDAsImlhdCI6MTY5OTk5MDAwMCwicmVhbG1fYWNjZXNzIjp7InJvbGVzIjpbImFkbWluIl19LCJyZXNvdXJjZV9hY2Nlc3MiOnsib3Jpb24iOnsicm9sZXMiOlsicmVhZCJdfX19.c2ln"
+
+	actual, err := DecodeJWT(token)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, `{"alg":"RS256","typ":"JWT"}`, string(actual.Header))
+		assert.Equal(t, "http://idm/realms/fiware", actual.Issuer)
+		assert.Equal(t, "u1", actual.Subject)
+		assert.Equal(t, []string{"ngsi", "account"}, actual.Audience)
+		assert.Equal(t, []string{"admin", "orion:read"}, actual.Roles)
+		assert.Equal(t, "2023-11-14T19:26:40Z", actual.IssuedAt)
+		assert.Equal(t, "2023-11-14T22:13:20Z", actual.Expires)
+	}
+}
+
+func TestDecodeJWTAudienceString(t *testing.T) {
+	testNgsiLibInit()
+
+	token := "eyJhbGciOiJIUzI1NiJ9.eyJhdWQiOiJuZ3NpIiwicm9sZXMiOlsicjEiXX0."
+
+	actual, err := DecodeJWT(token)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, []string{"ngsi"}, actual.Audience)
+		assert.Equal(t, []string{"r1"}, actual.Roles)
+		assert.Equal(t, "", actual.Expires)
+	}
+}
+
+func TestDecodeJWTErrorNotJWT(t *testing.T) {
+	testNgsiLibInit()
+
+	_, err := DecodeJWT("c312d32a36a8a1df219a807a79323bb31941f462")
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 1, ngsiErr.ErrNo)
+		assert.Equal(t, "token is not a JWT", ngsiErr.Message)
+	}
+}
+
+func TestDecodeJWTErrorHeaderBase64(t *testing.T) {
+	testNgsiLibInit()
+
+	_, err := DecodeJWT("%%%.e30.")
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 2, ngsiErr.ErrNo)
+	}
+}
+
+func TestDecodeJWTErrorHeaderJSON(t *testing.T) {
+	testNgsiLibInit()
+
+	_, err := DecodeJWT("YWJj.e30.")
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 3, ngsiErr.ErrNo)
+		assert.Equal(t, "header is not JSON", ngsiErr.Message)
+	}
+}
+
+func TestDecodeJWTErrorClaimsBase64(t *testing.T) {
+	testNgsiLibInit()
+
+	_, err := DecodeJWT("e30.%%%.")
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 4, ngsiErr.ErrNo)
+	}
+}
+
+func TestDecodeJWTErrorClaimsJSON(t *testing.T) {
+	testNgsiLibInit()
+
+	_, err := DecodeJWT("e30.YWJj.")
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 5, ngsiErr.ErrNo)
+	}
+}
@@ -32,6 +32,7 @@ package ngsilib
 import (
 	"net"
 	"net/http"
+	"time"
 )
 
 // NetLib is ...
@@ -39,6 +40,8 @@ type NetLib interface {
 	InterfaceAddrs() ([]net.Addr, error)
 	ListenAndServe(addr string, handler http.Handler) error
 	ListenAndServeTLS(addr, certFile, keyFile string, handler http.Handler) error
+	DialTimeout(network, address string, timeout time.Duration) (net.Conn, error)
+	Listen(network, address string) (net.Listener, error)
 }
 
 func NewNetLib() *netLib {
@@ -58,3 +61,11 @@ func (n *netLib) ListenAndServe(addr string, handler http.Handler) error {
 func (n *netLib) ListenAndServeTLS(addr, certFile, keyFile string, handler http.Handler) error {
 	return http.ListenAndServeTLS(addr, certFile, keyFile, handler)
 }
+
+func (n *netLib) DialTimeout(network, address string, timeout time.Duration) (net.Conn, error) {
+	return net.DialTimeout(network, address, timeout)
+}
+
+func (n *netLib) Listen(network, address string) (net.Listener, error) {
+	return net.Listen(network, address)
+}
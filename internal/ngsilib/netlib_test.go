@@ -30,7 +30,9 @@ SOFTWARE.
 package ngsilib
 
 import (
+	"net"
 	"testing"
+	"time"
 
 	"github.com/lets-fiware/ngsi-go/internal/assert"
 )
@@ -59,3 +61,35 @@ func TestListenAndServeTLS(t *testing.T) {
 	err := n.ListenAndServeTLS("", "", "", nil)
 	assert.Error(t, err)
 }
+
+func TestDialTimeout(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer l.Close()
+
+	n := &netLib{}
+	conn, err := n.DialTimeout("tcp", l.Addr().String(), time.Second)
+
+	if assert.NoError(t, err) {
+		conn.Close()
+	}
+}
+
+func TestDialTimeoutError(t *testing.T) {
+	n := &netLib{}
+	_, err := n.DialTimeout("tcp", "", time.Second)
+
+	assert.Error(t, err)
+}
+
+func TestListen(t *testing.T) {
+	n := &netLib{}
+
+	l, err := n.Listen("tcp", "127.0.0.1:0")
+
+	if assert.NoError(t, err) {
+		l.Close()
+	}
+}
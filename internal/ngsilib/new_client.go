@@ -43,7 +43,6 @@ func (ngsi *NGSI) NewClient(name string, cmdFlags *CmdFlags, isHTTPVerb bool, sk
 
 	client = &Client{}
 	client.Server = &Server{}
-	client.HTTP = ngsi.HTTP
 
 	if IsHTTP(name) {
 		client.URL, err = url.Parse(name)
@@ -113,6 +112,12 @@ func (ngsi *NGSI) NewClient(name string, cmdFlags *CmdFlags, isHTTPVerb bool, sk
 		}
 	}
 
+	if err = ngsi.OpenServerSecrets(client.Server); err != nil {
+		return nil, ngsierr.New(funcName, 8, err.Error(), err)
+	}
+
+	client.HTTP = ngsi.serverHTTP(client.Server)
+
 	flags := []struct {
 		cmd    *string
 		client *string
@@ -133,29 +138,32 @@ func (ngsi *NGSI) NewClient(name string, cmdFlags *CmdFlags, isHTTPVerb bool, sk
 		!skipGetToken {
 		token, err := ngsi.GetToken(client)
 		if err != nil {
-			return nil, ngsierr.New(funcName, 8, err.Error(), err)
+			return nil, ngsierr.New(funcName, 9, err.Error(), err)
 		}
 		client.Token = token
+		client.tokenRefresh = true
 	}
 
 	b, err := client.Server.safeString()
 	if err != nil {
-		return nil, ngsierr.New(funcName, 9, err.Error(), err)
+		return nil, ngsierr.New(funcName, 10, err.Error(), err)
 	}
 	client.SafeString = b
 	if cmdFlags.SafeString != nil {
 		b, err := ngsi.BoolFlag(*cmdFlags.SafeString)
 		if err != nil {
-			return nil, ngsierr.New(funcName, 10, err.Error(), err)
+			return nil, ngsierr.New(funcName, 11, err.Error(), err)
 		}
 		client.SafeString = b
 	}
 
 	client.XAuthToken = cmdFlags.XAuthToken
 	client.Link = cmdFlags.Link
+	client.DryRun = ngsi.DryRun
+	client.protectedProfile = ngsi.protectedProfile()
 
 	if err = client.InitHeader(); err != nil {
-		return nil, ngsierr.New(funcName, 11, err.Error(), err)
+		return nil, ngsierr.New(funcName, 12, err.Error(), err)
 	}
 
 	if ngsi.Updated && ngsi.GetPreviousArgs().UsePreviousArgs {
@@ -165,7 +173,7 @@ func (ngsi *NGSI) NewClient(name string, cmdFlags *CmdFlags, isHTTPVerb bool, sk
 			ngsi.PreviousArgs.Scope = ""
 		}
 		if err = ngsi.saveConfigFile(); err != nil {
-			return nil, ngsierr.New(funcName, 12, err.Error(), err)
+			return nil, ngsierr.New(funcName, 13, err.Error(), err)
 		}
 	}
 	return client, nil
@@ -55,6 +55,42 @@ func TestNewClient(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestNewClientDryRun(t *testing.T) {
+	ngsi := testNgsiLibInit()
+	fileName := ""
+	ngsi.ConfigFile = &MockIoLib{filename: &fileName}
+	ngsi.DryRun = true
+
+	InitServerList()
+
+	broker := &Server{ServerHost: "http://orion/"}
+	ngsi.ServerList["orion"] = broker
+
+	flags := &CmdFlags{}
+
+	client, err := ngsi.NewClient("orion", flags, false, false)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, true, client.DryRun)
+	}
+}
+
+func TestNewClientProtectedProfile(t *testing.T) {
+	ngsi := testNgsiLibInit()
+	fileName := ""
+	ngsi.ConfigFile = &MockIoLib{filename: &fileName}
+	profiles := Profiles{"prod": &Profile{Protected: true, Servers: ServerList{"orion": &Server{ServerHost: "http://orion/"}}}}
+	_ = ngsi.initProfiles(profiles, "prod")
+
+	flags := &CmdFlags{}
+
+	client, err := ngsi.NewClient("orion", flags, false, false)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, "prod", client.protectedProfile)
+	}
+}
+
 func TestNewClientHTTP(t *testing.T) {
 	ngsi := testNgsiLibInit()
 	fileName := ""
@@ -442,11 +478,53 @@ func TestNewClientErrorIdmType(t *testing.T) {
 
 	if assert.Error(t, err) {
 		ngsiErr := err.(*ngsierr.NgsiError)
-		assert.Equal(t, 8, ngsiErr.ErrNo)
+		assert.Equal(t, 9, ngsiErr.ErrNo)
 		assert.Equal(t, "username is required", ngsiErr.Message)
 	}
 }
 
+func TestNewClientSecrets(t *testing.T) {
+	ngsi := testNgsiLibInit()
+	fileName := ""
+	ngsi.ConfigFile = &MockIoLib{filename: &fileName}
+	ngsi.ExecLib = &MockExecLib{Secrets: map[string]string{"orion/token": "abc"}}
+
+	InitServerList()
+
+	ngsi.ServerList["orion"] = &Server{ServerHost: "http://orion/", Token: "helper:orion/token"}
+	_ = ngsi.initSecrets(&SecretsConfig{Backend: CSecretsHelper, Helper: "ngsi-helper"})
+
+	flags := &CmdFlags{}
+
+	client, err := ngsi.NewClient("orion", flags, false, false)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, "abc", client.Server.Token)
+	}
+}
+
+func TestNewClientErrorSecrets(t *testing.T) {
+	ngsi := testNgsiLibInit()
+	fileName := ""
+	ngsi.ConfigFile = &MockIoLib{filename: &fileName}
+	ngsi.ExecLib = &MockExecLib{}
+
+	InitServerList()
+
+	ngsi.ServerList["orion"] = &Server{ServerHost: "http://orion/", Token: "helper:orion/token"}
+	_ = ngsi.initSecrets(&SecretsConfig{Backend: CSecretsHelper, Helper: "ngsi-helper"})
+
+	flags := &CmdFlags{}
+
+	_, err := ngsi.NewClient("orion", flags, false, false)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 8, ngsiErr.ErrNo)
+		assert.Equal(t, "ngsi-helper get: not found", ngsiErr.Message)
+	}
+}
+
 func TestNewClientErrorSafeString(t *testing.T) {
 	ngsi := testNgsiLibInit()
 	fileName := ""
@@ -469,7 +547,7 @@ func TestNewClientErrorSafeString(t *testing.T) {
 
 	if assert.Error(t, err) {
 		ngsiErr := err.(*ngsierr.NgsiError)
-		assert.Equal(t, 9, ngsiErr.ErrNo)
+		assert.Equal(t, 10, ngsiErr.ErrNo)
 		assert.Equal(t, "unknown parameter: enable", ngsiErr.Message)
 	}
 }
@@ -497,7 +575,7 @@ func TestNewClientErrorSafeStringCmdFlag(t *testing.T) {
 
 	if assert.Error(t, err) {
 		ngsiErr := err.(*ngsierr.NgsiError)
-		assert.Equal(t, 10, ngsiErr.ErrNo)
+		assert.Equal(t, 11, ngsiErr.ErrNo)
 		assert.Equal(t, "unknown parameter: enable", ngsiErr.Message)
 	}
 }
@@ -525,7 +603,7 @@ func TestNewClientInitHeader(t *testing.T) {
 
 	if assert.Error(t, err) {
 		ngsiErr := err.(*ngsierr.NgsiError)
-		assert.Equal(t, 11, ngsiErr.ErrNo)
+		assert.Equal(t, 12, ngsiErr.ErrNo)
 		assert.Equal(t, "error FIWARE Service: FIWARE", ngsiErr.Message)
 	}
 }
@@ -549,7 +627,7 @@ func TestNewClientErrorSaveConfig(t *testing.T) {
 
 	if assert.Error(t, err) {
 		ngsiErr := err.(*ngsierr.NgsiError)
-		assert.Equal(t, 12, ngsiErr.ErrNo)
+		assert.Equal(t, 13, ngsiErr.ErrNo)
 		assert.Equal(t, "open error", ngsiErr.Message)
 	}
 }
@@ -0,0 +1,66 @@
+/*
+MIT License
+
+Copyright (c) 2020-2024 Kazuhito Suda
+
+This file is part of NGSI Go
+
+https://github.com/lets-fiware/ngsi-go
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+
+*/
+
+package ngsilib
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var uriScheme = regexp.MustCompile(`(?i)^(?:[^:/?#]+)`)
+
+// NgsiLdURI returns "urn:ngsi-ld:typePart:idPart", or "urn:ngsi-ld:idPart" when idPart starts with typePart.
+func NgsiLdURI(typePart, idPart string) string {
+	entityType := uriScheme.FindAllStringSubmatch(idPart, -1)
+	if len(entityType) > 0 && strings.EqualFold(entityType[0][0], typePart) {
+		return fmt.Sprintf("urn:ngsi-ld:%s", idPart)
+	}
+	return fmt.Sprintf("urn:ngsi-ld:%s:%s", typePart, idPart)
+}
+
+// LdID returns the NGSI-LD id of an NGSIv2 entity. An id which is not a URI is made a URN.
+func LdID(entityID, entityType string) string {
+	scheme := uriScheme.FindAllStringSubmatch(entityID, -1)
+
+	if len(scheme) == 0 || !Contains([]string{"urn", "http", "https"}, scheme[0][0]) {
+		return NgsiLdURI(entityType, entityID)
+	}
+
+	return entityID
+}
+
+// V2ID returns the NGSIv2 id of an NGSI-LD entity. It removes the prefix added by LdID.
+func V2ID(entityID, entityType string) string {
+	if prefix := "urn:ngsi-ld:" + entityType + ":"; entityType != "" && strings.HasPrefix(entityID, prefix) {
+		return strings.TrimPrefix(entityID, prefix)
+	}
+	return strings.TrimPrefix(entityID, "urn:ngsi-ld:")
+}
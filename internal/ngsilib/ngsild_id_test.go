@@ -0,0 +1,89 @@
+/*
+MIT License
+
+Copyright (c) 2020-2024 Kazuhito Suda
+
+This file is part of NGSI Go
+
+https://github.com/lets-fiware/ngsi-go
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+
+*/
+
+package ngsilib
+
+import (
+	"testing"
+
+	"github.com/lets-fiware/ngsi-go/internal/assert"
+)
+
+func TestNgsiLdURI(t *testing.T) {
+	cases := []struct {
+		typePart string
+		idPart   string
+		expected string
+	}{
+		{typePart: "Device", idPart: "device:001", expected: "urn:ngsi-ld:device:001"},
+		{typePart: "Device", idPart: "device001", expected: "urn:ngsi-ld:Device:device001"},
+	}
+	for _, c := range cases {
+		actual := NgsiLdURI(c.typePart, c.idPart)
+		assert.Equal(t, c.expected, actual)
+	}
+}
+
+func TestLdID(t *testing.T) {
+	cases := []struct {
+		entityId   string
+		entityType string
+		expected   string
+	}{
+		{entityId: "http://letsfiware.jp/ns/data-models#fiware", entityType: "", expected: "http://letsfiware.jp/ns/data-models#fiware"},
+		{entityId: "https://letsfiware.jp/ns/data-models#fiware", entityType: "", expected: "https://letsfiware.jp/ns/data-models#fiware"},
+		{entityId: "urn:ngsi-ld:Device:001", entityType: "", expected: "urn:ngsi-ld:Device:001"},
+		{entityId: "Device001", entityType: "Device", expected: "urn:ngsi-ld:Device:Device001"},
+		{entityId: "device001", entityType: "Device", expected: "urn:ngsi-ld:Device:device001"},
+		{entityId: "", entityType: "Device", expected: "urn:ngsi-ld:Device:"},
+	}
+
+	for _, c := range cases {
+		actual := LdID(c.entityId, c.entityType)
+		assert.Equal(t, c.expected, actual)
+	}
+}
+
+func TestV2ID(t *testing.T) {
+	cases := []struct {
+		entityID   string
+		entityType string
+		expected   string
+	}{
+		{entityID: "urn:ngsi-ld:Device:device001", entityType: "Device", expected: "device001"},
+		{entityID: "urn:ngsi-ld:device:001", entityType: "Device", expected: "device:001"},
+		{entityID: "urn:ngsi-ld:Device:001", entityType: "", expected: "Device:001"},
+		{entityID: "http://letsfiware.jp/ns/data-models#fiware", entityType: "Device", expected: "http://letsfiware.jp/ns/data-models#fiware"},
+	}
+
+	for _, c := range cases {
+		actual := V2ID(c.entityID, c.entityType)
+		assert.Equal(t, c.expected, actual)
+	}
+}
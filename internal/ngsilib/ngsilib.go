@@ -33,9 +33,11 @@ import (
 	"bufio"
 	"fmt"
 	"io"
+	"net/http"
 	"os"
 	"runtime"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/lets-fiware/ngsi-go/internal/ngsierr"
@@ -47,8 +49,20 @@ type NGSI struct {
 	ServerList    ServerList
 	tokenList     tokenInfoList
 	contextList   ContextsInfo
+	secretsConfig *SecretsConfig
+	secrets       SecretsBackend
+	openedSecrets map[string]string
+	loadedSecrets map[string]string
+	sealedTokens  string
+
+	profiles         Profiles
+	profile          string
+	currentProfile   string
+	profileConfirmed bool
+	profileMutex     sync.Mutex
 
 	ConfigDir     *string
+	ProfileFlag   *string
 	ConfigFile    IoLib
 	CacheFile     IoLib
 	StdReader     io.Reader
@@ -66,6 +80,9 @@ type NGSI struct {
 	GetReader     GetReaderFunc
 	HTTP          HTTPRequest
 	NetLib        NetLib
+	SignalLib     SignalLib
+	TermLib       TermLib
+	ExecLib       ExecLib
 
 	Host               string
 	Destination        string
@@ -79,6 +96,16 @@ type NGSI struct {
 	OsType             string
 	BatchFlag          *bool
 	InsecureSkipVerify bool
+	Retry              *RetryPolicy
+	DryRun             bool
+
+	transports     map[string]*http.Transport
+	transportMutex sync.Mutex
+
+	oidcConfigs map[string]*oidcConfiguration
+	oidcMutex   sync.Mutex
+
+	tokenMutex sync.Mutex
 }
 
 // CmdFlags is ...
@@ -101,8 +128,11 @@ func NewNGSI() *NGSI {
 		gNGSI.InitLog(os.Stdin, os.Stdout, os.Stderr)
 		gNGSI.HTTP = &httpRequest{}
 		gNGSI.NetLib = NewNetLib()
+		gNGSI.SignalLib = NewSignalLib()
+		gNGSI.TermLib = NewTermLib()
+		gNGSI.ExecLib = NewExecLib()
 		gNGSI.Margin = 180
-		gNGSI.Timeout = 60
+		gNGSI.Timeout = 60 * time.Second
 		gNGSI.Maxsize = 100
 		gNGSI.ConfigFile = &ioLib{}
 		gNGSI.CacheFile = &ioLib{}
@@ -120,7 +150,11 @@ func NewNGSI() *NGSI {
 		gNGSI.PreviousArgs = &Settings{UsePreviousArgs: true}
 		gNGSI.TimeLib = &timeLib{}
 		gNGSI.InsecureSkipVerify = false
+		gNGSI.DryRun = false
+		gNGSI.Retry = NewRetryPolicy()
 		gNGSI.ServerList = make(ServerList)
+		gNGSI.openedSecrets = make(map[string]string)
+		gNGSI.loadedSecrets = make(map[string]string)
 		gNGSI.contextList = make(ContextsInfo)
 		gNGSI.contextList["etsi1.0"] = "https://uri.etsi.org/ngsi-ld/v1/ngsi-ld-core-context.jsonld"
 		gNGSI.contextList["etsi1.3"] = "https://uri.etsi.org/ngsi-ld/v1/ngsi-ld-core-context-v1.3.jsonld"
@@ -0,0 +1,129 @@
+/*
+MIT License
+
+Copyright (c) 2020-2024 Kazuhito Suda
+
+This file is part of NGSI Go
+
+https://github.com/lets-fiware/ngsi-go
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+
+*/
+
+package ngsilib
+
+import "sync"
+
+// PipelineProduceFunc returns the next job. It returns nil when there are no more jobs.
+type PipelineProduceFunc func() (interface{}, error)
+
+// PipelineWorkFunc processes a job. It is called by workers concurrently.
+type PipelineWorkFunc func(job interface{}) (interface{}, error)
+
+// PipelineConsumeFunc receives the result and the error of a job in the order the jobs were produced.
+type PipelineConsumeFunc func(result interface{}, err error) error
+
+// Pipeline runs jobs through a pool of n workers. produce is called sequentially and
+// up to n jobs are in flight at a time. consume is called in the order the jobs were produced,
+// so the output and the error reported do not depend on the timing of the workers.
+// When produce, work or consume returns an error, no more jobs are produced and Pipeline returns
+// the error after the jobs in flight have finished.
+func Pipeline(n int, produce PipelineProduceFunc, work PipelineWorkFunc, consume PipelineConsumeFunc) error {
+	if n < 1 {
+		n = 1
+	}
+
+	type task struct {
+		result interface{}
+		err    error
+		done   chan struct{}
+	}
+
+	tasks := make(chan *task, n)
+	workers := make(chan struct{}, n)
+	quit := make(chan struct{})
+	failed := make(chan struct{})
+	var once sync.Once
+
+	go func() {
+		defer close(tasks)
+
+		for {
+			select {
+			case <-quit:
+				return
+			case workers <- struct{}{}:
+			}
+
+			select {
+			case <-failed:
+				<-workers
+				return
+			default:
+			}
+
+			job, err := produce()
+
+			select {
+			case <-quit:
+				<-workers
+				return
+			default:
+			}
+
+			if err != nil || job == nil {
+				<-workers
+				if err != nil {
+					t := &task{err: err, done: make(chan struct{})}
+					close(t.done)
+					tasks <- t
+				}
+				return
+			}
+
+			t := &task{done: make(chan struct{})}
+			go func() {
+				defer func() {
+					<-workers
+					close(t.done)
+				}()
+				t.result, t.err = work(job)
+				if t.err != nil {
+					once.Do(func() { close(failed) })
+				}
+			}()
+			tasks <- t
+		}
+	}()
+
+	var err error
+
+	for t := range tasks {
+		<-t.done
+		if err != nil {
+			continue
+		}
+		if err = consume(t.result, t.err); err != nil {
+			close(quit)
+		}
+	}
+
+	return err
+}
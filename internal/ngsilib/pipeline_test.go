@@ -0,0 +1,219 @@
+/*
+MIT License
+
+Copyright (c) 2020-2024 Kazuhito Suda
+
+This file is part of NGSI Go
+
+https://github.com/lets-fiware/ngsi-go
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+
+*/
+
+package ngsilib
+
+import (
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/lets-fiware/ngsi-go/internal/assert"
+)
+
+func testPipelineProducer(n int) (PipelineProduceFunc, *int) {
+	i := 0
+	return func() (interface{}, error) {
+		if i >= n {
+			return nil, nil
+		}
+		i++
+		return i, nil
+	}, &i
+}
+
+func TestPipeline(t *testing.T) {
+	produce, _ := testPipelineProducer(10)
+	work := func(job interface{}) (interface{}, error) {
+		return job.(int) * 10, nil
+	}
+	actual := []int{}
+	consume := func(result interface{}, err error) error {
+		actual = append(actual, result.(int))
+		return err
+	}
+
+	err := Pipeline(1, produce, work, consume)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, []int{10, 20, 30, 40, 50, 60, 70, 80, 90, 100}, actual)
+	}
+}
+
+func TestPipelineZero(t *testing.T) {
+	produce, _ := testPipelineProducer(3)
+	var running, max int32
+	work := func(job interface{}) (interface{}, error) {
+		if r := atomic.AddInt32(&running, 1); r > atomic.LoadInt32(&max) {
+			atomic.StoreInt32(&max, r)
+		}
+		time.Sleep(time.Millisecond)
+		atomic.AddInt32(&running, -1)
+		return job, nil
+	}
+	consume := func(result interface{}, err error) error {
+		return err
+	}
+
+	err := Pipeline(0, produce, work, consume)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, int32(1), max)
+	}
+}
+
+func TestPipelineParallel(t *testing.T) {
+	produce, _ := testPipelineProducer(20)
+	var running, max int32
+	work := func(job interface{}) (interface{}, error) {
+		if r := atomic.AddInt32(&running, 1); r > atomic.LoadInt32(&max) {
+			atomic.StoreInt32(&max, r)
+		}
+		time.Sleep(time.Duration(20-job.(int)) * time.Millisecond)
+		atomic.AddInt32(&running, -1)
+		return job, nil
+	}
+	actual := []int{}
+	consume := func(result interface{}, err error) error {
+		actual = append(actual, result.(int))
+		return err
+	}
+
+	err := Pipeline(4, produce, work, consume)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17, 18, 19, 20}, actual)
+		assert.Equal(t, true, max <= 4)
+	}
+}
+
+func TestPipelineErrorWork(t *testing.T) {
+	produce, produced := testPipelineProducer(100)
+	work := func(job interface{}) (interface{}, error) {
+		switch job.(int) {
+		case 3:
+			time.Sleep(20 * time.Millisecond)
+			return nil, errors.New("error 3")
+		case 4:
+			return nil, errors.New("error 4")
+		}
+		return job, nil
+	}
+	actual := []int{}
+	consume := func(result interface{}, err error) error {
+		if err != nil {
+			return err
+		}
+		actual = append(actual, result.(int))
+		return nil
+	}
+
+	err := Pipeline(4, produce, work, consume)
+
+	if assert.Error(t, err) {
+		assert.Equal(t, "error 3", err.Error())
+		assert.Equal(t, []int{1, 2}, actual)
+		assert.Equal(t, true, *produced < 100)
+	}
+}
+
+func TestPipelineErrorProduce(t *testing.T) {
+	i := 0
+	produce := func() (interface{}, error) {
+		i++
+		if i == 3 {
+			return nil, fmt.Errorf("produce error")
+		}
+		return i, nil
+	}
+	work := func(job interface{}) (interface{}, error) {
+		time.Sleep(time.Millisecond)
+		return job, nil
+	}
+	actual := []int{}
+	consume := func(result interface{}, err error) error {
+		if err != nil {
+			return err
+		}
+		actual = append(actual, result.(int))
+		return nil
+	}
+
+	err := Pipeline(4, produce, work, consume)
+
+	if assert.Error(t, err) {
+		assert.Equal(t, "produce error", err.Error())
+		assert.Equal(t, []int{1, 2}, actual)
+	}
+}
+
+func TestPipelineErrorConsume(t *testing.T) {
+	produce, produced := testPipelineProducer(100)
+	work := func(job interface{}) (interface{}, error) {
+		return job, nil
+	}
+	actual := []int{}
+	consume := func(result interface{}, err error) error {
+		if result.(int) == 5 {
+			return errors.New("consume error")
+		}
+		actual = append(actual, result.(int))
+		return nil
+	}
+
+	err := Pipeline(2, produce, work, consume)
+
+	if assert.Error(t, err) {
+		assert.Equal(t, "consume error", err.Error())
+		assert.Equal(t, []int{1, 2, 3, 4}, actual)
+		assert.Equal(t, true, *produced < 100)
+	}
+}
+
+func TestPipelineErrorWorkSequential(t *testing.T) {
+	produce, produced := testPipelineProducer(100)
+	work := func(job interface{}) (interface{}, error) {
+		if job.(int) == 3 {
+			return nil, errors.New("work error")
+		}
+		return job, nil
+	}
+	consume := func(result interface{}, err error) error {
+		return err
+	}
+
+	err := Pipeline(1, produce, work, consume)
+
+	if assert.Error(t, err) {
+		assert.Equal(t, "work error", err.Error())
+		assert.Equal(t, 3, *produced)
+	}
+}
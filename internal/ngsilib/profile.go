@@ -0,0 +1,352 @@
+/*
+MIT License
+
+Copyright (c) 2020-2024 Kazuhito Suda
+
+This file is part of NGSI Go
+
+https://github.com/lets-fiware/ngsi-go
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+
+*/
+
+package ngsilib
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/lets-fiware/ngsi-go/internal/ngsierr"
+)
+
+// DefaultProfile is the name of the profile made of the servers and the settings at the top level of the config file
+const DefaultProfile = "default"
+
+// ProfileConfirmEnv is the name of the environment variable which confirms requests against a protected profile
+// when stdin is not a terminal. Its value must be the name of the profile.
+const ProfileConfirmEnv = "NGSI_GO_PROFILE_CONFIRM"
+
+// Profile is a set of server aliases with its own default host, tenant and scope
+type Profile struct {
+	Protected bool       `json:"protected,omitempty"`
+	Host      string     `json:"host,omitempty"`
+	Tenant    string     `json:"tenant,omitempty"`
+	Scope     string     `json:"scope,omitempty"`
+	Servers   ServerList `json:"servers"`
+}
+
+// Profiles is ...
+type Profiles map[string]*Profile
+
+// initProfiles activates the profile specified by --profile, or the profile selected by "ngsi profile use"
+func (ngsi *NGSI) initProfiles(profiles Profiles, current string) error {
+	const funcName = "initProfiles"
+
+	if _, ok := profiles[DefaultProfile]; ok {
+		return ngsierr.New(funcName, 1, DefaultProfile+" is a reserved profile name", nil)
+	}
+	if profiles == nil {
+		profiles = make(Profiles)
+	}
+	profiles[DefaultProfile] = &Profile{
+		Host:    ngsi.PreviousArgs.Host,
+		Tenant:  ngsi.PreviousArgs.Tenant,
+		Scope:   ngsi.PreviousArgs.Scope,
+		Servers: ngsi.ServerList,
+	}
+	ngsi.profiles = profiles
+	ngsi.profile = DefaultProfile
+	ngsi.currentProfile = current
+
+	name := current
+	if ngsi.ProfileFlag != nil {
+		name = *ngsi.ProfileFlag
+	}
+	if name == "" {
+		name = DefaultProfile
+	}
+
+	if err := ngsi.activateProfile(name); err != nil {
+		return ngsierr.New(funcName, 2, err.Error(), err)
+	}
+
+	return nil
+}
+
+// activeProfile returns the profile in use. The servers and the settings in NGSI are copied to it.
+func (ngsi *NGSI) activeProfile() *Profile {
+	if ngsi.profiles == nil {
+		ngsi.profiles = make(Profiles)
+		ngsi.profile = DefaultProfile
+	}
+	p, ok := ngsi.profiles[ngsi.profile]
+	if !ok {
+		p = &Profile{}
+		ngsi.profiles[ngsi.profile] = p
+	}
+
+	p.Servers = ngsi.ServerList
+	if !ngsi.isBatch() {
+		p.Host = ngsi.PreviousArgs.Host
+		p.Tenant = ngsi.PreviousArgs.Tenant
+		p.Scope = ngsi.PreviousArgs.Scope
+	}
+
+	return p
+}
+
+func (ngsi *NGSI) activateProfile(name string) error {
+	const funcName = "activateProfile"
+
+	p, ok := ngsi.profiles[name]
+	if !ok {
+		return ngsierr.New(funcName, 1, "profile not found: "+name, nil)
+	}
+
+	ngsi.activeProfile()
+
+	if p.Servers == nil {
+		p.Servers = make(ServerList)
+	}
+	ngsi.profile = name
+	ngsi.ServerList = p.Servers
+	if !ngsi.isBatch() {
+		ngsi.PreviousArgs.Host = p.Host
+		ngsi.PreviousArgs.Tenant = p.Tenant
+		ngsi.PreviousArgs.Scope = p.Scope
+	}
+
+	return nil
+}
+
+func (ngsi *NGSI) isBatch() bool {
+	return ngsi.BatchFlag != nil && *ngsi.BatchFlag
+}
+
+// profileHost returns the name of a server qualified with the name of the profile
+func profileHost(profile, host string) string {
+	if profile == DefaultProfile || profile == "" {
+		return host
+	}
+	return profile + "/" + host
+}
+
+// CurrentProfile returns the name of the profile in use
+func (ngsi *NGSI) CurrentProfile() string {
+	if ngsi.profile == "" {
+		return DefaultProfile
+	}
+	return ngsi.profile
+}
+
+// ProfileNames returns the sorted names of the profiles
+func (ngsi *NGSI) ProfileNames() []string {
+	ngsi.activeProfile()
+
+	names := make([]string, 0, len(ngsi.profiles))
+	for name := range ngsi.profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// GetProfile is ...
+func (ngsi *NGSI) GetProfile(name string) (*Profile, error) {
+	const funcName = "GetProfile"
+
+	ngsi.activeProfile()
+
+	p, ok := ngsi.profiles[name]
+	if !ok {
+		return nil, ngsierr.New(funcName, 1, "profile not found: "+name, nil)
+	}
+
+	return p, nil
+}
+
+// AddProfile is ...
+func (ngsi *NGSI) AddProfile(name string, protected bool) error {
+	const funcName = "AddProfile"
+
+	if !IsNameString(name) {
+		return ngsierr.New(funcName, 1, "name error "+name, nil)
+	}
+
+	ngsi.activeProfile()
+
+	if _, ok := ngsi.profiles[name]; ok {
+		return ngsierr.New(funcName, 2, name+" already exists", nil)
+	}
+	ngsi.profiles[name] = &Profile{Protected: protected, Servers: make(ServerList)}
+
+	if err := ngsi.saveConfigFile(); err != nil {
+		return ngsierr.New(funcName, 3, err.Error(), err)
+	}
+
+	return nil
+}
+
+// UpdateProfile is ...
+func (ngsi *NGSI) UpdateProfile(name string, protected bool) error {
+	const funcName = "UpdateProfile"
+
+	if name == DefaultProfile {
+		return ngsierr.New(funcName, 1, DefaultProfile+" profile cannot be protected", nil)
+	}
+
+	p, err := ngsi.GetProfile(name)
+	if err != nil {
+		return ngsierr.New(funcName, 2, err.Error(), err)
+	}
+	p.Protected = protected
+
+	if err := ngsi.saveConfigFile(); err != nil {
+		return ngsierr.New(funcName, 3, err.Error(), err)
+	}
+
+	return nil
+}
+
+// DeleteProfile is ...
+func (ngsi *NGSI) DeleteProfile(name string) error {
+	const funcName = "DeleteProfile"
+
+	if name == DefaultProfile {
+		return ngsierr.New(funcName, 1, DefaultProfile+" profile cannot be deleted", nil)
+	}
+
+	p, err := ngsi.GetProfile(name)
+	if err != nil {
+		return ngsierr.New(funcName, 2, err.Error(), err)
+	}
+	if p.Protected {
+		return ngsierr.New(funcName, 3, name+" is protected", nil)
+	}
+	if name == ngsi.profile || name == ngsi.currentProfile {
+		return ngsierr.New(funcName, 4, name+" is in use", nil)
+	}
+	delete(ngsi.profiles, name)
+
+	if err := ngsi.saveConfigFile(); err != nil {
+		return ngsierr.New(funcName, 5, err.Error(), err)
+	}
+
+	return nil
+}
+
+// UseProfile selects the profile used by the following commands
+func (ngsi *NGSI) UseProfile(name string) error {
+	const funcName = "UseProfile"
+
+	ngsi.activeProfile()
+
+	if err := ngsi.activateProfile(name); err != nil {
+		return ngsierr.New(funcName, 1, err.Error(), err)
+	}
+	ngsi.currentProfile = name
+	if name == DefaultProfile {
+		ngsi.currentProfile = ""
+	}
+
+	if err := ngsi.saveConfigFile(); err != nil {
+		return ngsierr.New(funcName, 2, err.Error(), err)
+	}
+
+	return nil
+}
+
+// protectedProfile returns the name of the profile in use when it is protected
+func (ngsi *NGSI) protectedProfile() string {
+	if p, ok := ngsi.profiles[ngsi.profile]; ok && p.Protected {
+		return ngsi.profile
+	}
+	return ""
+}
+
+// ResetProfileConfirmation makes the next request against a protected profile ask for confirmation again
+func (ngsi *NGSI) ResetProfileConfirmation() {
+	ngsi.profileMutex.Lock()
+	defer ngsi.profileMutex.Unlock()
+
+	ngsi.profileConfirmed = false
+}
+
+// confirmProfile asks for confirmation once before the first request which changes data in a protected profile.
+// It is called by workers concurrently.
+func (ngsi *NGSI) confirmProfile(name string) error {
+	const funcName = "confirmProfile"
+
+	ngsi.profileMutex.Lock()
+	defer ngsi.profileMutex.Unlock()
+
+	if ngsi.profileConfirmed {
+		return nil
+	}
+
+	if ngsi.ConfigFile.Getenv(ProfileConfirmEnv) != name {
+		if !ngsi.TermLib.IsTerminal(os.Stdin.Fd()) {
+			return ngsierr.New(funcName, 1, fmt.Sprintf("profile %s is protected. set %s to the profile name to confirm", name, ProfileConfirmEnv), nil)
+		}
+
+		fmt.Fprintf(ngsi.Stderr, "Profile %s is protected. Type the profile name to continue: ", name)
+
+		answer, err := ngsi.readLine()
+		if err != nil {
+			return ngsierr.New(funcName, 2, err.Error(), err)
+		}
+		if answer != name {
+			return ngsierr.New(funcName, 3, "canceled", nil)
+		}
+	}
+	ngsi.profileConfirmed = true
+
+	return nil
+}
+
+// readLine reads a line from stdin a byte at a time, so that no input after the line is consumed
+func (ngsi *NGSI) readLine() (string, error) {
+	const funcName = "readLine"
+
+	var buf []byte
+	b := make([]byte, 1)
+
+	for {
+		n, err := ngsi.StdReader.Read(b)
+		if n == 1 {
+			if b[0] == '\n' {
+				break
+			}
+			buf = append(buf, b[0])
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", ngsierr.New(funcName, 1, err.Error(), err)
+		}
+	}
+
+	return strings.TrimSpace(string(buf)), nil
+}
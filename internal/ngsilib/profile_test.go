@@ -0,0 +1,505 @@
+/*
+MIT License
+
+Copyright (c) 2020-2024 Kazuhito Suda
+
+This file is part of NGSI Go
+
+https://github.com/lets-fiware/ngsi-go
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+
+*/
+
+package ngsilib
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/lets-fiware/ngsi-go/internal/assert"
+	"github.com/lets-fiware/ngsi-go/internal/ngsierr"
+)
+
+type testProfileErrReader struct{}
+
+func (r *testProfileErrReader) Read(p []byte) (int, error) {
+	return 0, errors.New("read error")
+}
+
+func testProfileInit(t *testing.T) (*NGSI, *MockIoLib) {
+	ngsi := testNgsiLibInit()
+	configFile := &MockIoLib{}
+	ngsi.ConfigFile = configFile
+	filename := "config"
+	ngsi.ConfigFile.SetFileName(&filename)
+	ngsi.ServerList = ServerList{"orion": &Server{ServerHost: "http://orion-dev:1026"}}
+	ngsi.PreviousArgs.Host = "orion"
+
+	profiles := Profiles{
+		"prod":    &Profile{Protected: true, Host: "orion", Tenant: "city", Servers: ServerList{"orion": &Server{ServerHost: "http://orion-prod:1026"}}},
+		"staging": &Profile{},
+	}
+	if err := ngsi.initProfiles(profiles, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	return ngsi, configFile
+}
+
+func TestInitProfiles(t *testing.T) {
+	ngsi, _ := testProfileInit(t)
+
+	assert.Equal(t, DefaultProfile, ngsi.CurrentProfile())
+	assert.Equal(t, "http://orion-dev:1026", ngsi.ServerList["orion"].ServerHost)
+	assert.Equal(t, "", ngsi.protectedProfile())
+}
+
+func TestInitProfilesCurrent(t *testing.T) {
+	ngsi := testNgsiLibInit()
+
+	err := ngsi.initProfiles(Profiles{"prod": &Profile{Protected: true, Host: "orion", Scope: "/a"}}, "prod")
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, "prod", ngsi.CurrentProfile())
+		assert.Equal(t, 0, len(ngsi.ServerList))
+		assert.Equal(t, "orion", ngsi.PreviousArgs.Host)
+		assert.Equal(t, "/a", ngsi.PreviousArgs.Scope)
+		assert.Equal(t, "prod", ngsi.protectedProfile())
+	}
+}
+
+func TestInitProfilesBatch(t *testing.T) {
+	ngsi := testNgsiLibInit()
+	b := true
+	ngsi.BatchFlag = &b
+	ngsi.PreviousArgs = &Settings{}
+
+	err := ngsi.initProfiles(Profiles{"prod": &Profile{Host: "orion"}}, "prod")
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, "prod", ngsi.CurrentProfile())
+		assert.Equal(t, "", ngsi.PreviousArgs.Host)
+	}
+}
+
+func TestInitProfilesErrorReserved(t *testing.T) {
+	ngsi := testNgsiLibInit()
+
+	err := ngsi.initProfiles(Profiles{"default": &Profile{}}, "")
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 1, ngsiErr.ErrNo)
+		assert.Equal(t, "default is a reserved profile name", ngsiErr.Message)
+	}
+}
+
+func TestInitProfilesErrorNotFound(t *testing.T) {
+	ngsi := testNgsiLibInit()
+	profile := "prod"
+	ngsi.ProfileFlag = &profile
+
+	err := ngsi.initProfiles(nil, "")
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 2, ngsiErr.ErrNo)
+		assert.Equal(t, "profile not found: prod", ngsiErr.Message)
+	}
+}
+
+func TestActiveProfile(t *testing.T) {
+	ngsi := testNgsiLibInit()
+	ngsi.ServerList = ServerList{"orion": &Server{}}
+	ngsi.PreviousArgs.Tenant = "city"
+
+	actual := ngsi.activeProfile()
+
+	assert.Equal(t, DefaultProfile, ngsi.CurrentProfile())
+	assert.Equal(t, "city", actual.Tenant)
+	assert.Equal(t, 1, len(actual.Servers))
+}
+
+func TestProfileHost(t *testing.T) {
+	assert.Equal(t, "orion", profileHost("", "orion"))
+	assert.Equal(t, "orion", profileHost(DefaultProfile, "orion"))
+	assert.Equal(t, "prod/orion", profileHost("prod", "orion"))
+}
+
+func TestCurrentProfile(t *testing.T) {
+	ngsi := testNgsiLibInit()
+
+	assert.Equal(t, DefaultProfile, ngsi.CurrentProfile())
+}
+
+func TestProfileNames(t *testing.T) {
+	ngsi, _ := testProfileInit(t)
+
+	assert.Equal(t, []string{"default", "prod", "staging"}, ngsi.ProfileNames())
+}
+
+func TestGetProfile(t *testing.T) {
+	ngsi, _ := testProfileInit(t)
+	ngsi.PreviousArgs.Tenant = "town"
+
+	actual, err := ngsi.GetProfile(DefaultProfile)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, "orion", actual.Host)
+		assert.Equal(t, "town", actual.Tenant)
+	}
+}
+
+func TestGetProfileError(t *testing.T) {
+	ngsi, _ := testProfileInit(t)
+
+	_, err := ngsi.GetProfile("dev")
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 1, ngsiErr.ErrNo)
+		assert.Equal(t, "profile not found: dev", ngsiErr.Message)
+	}
+}
+
+func TestAddProfile(t *testing.T) {
+	ngsi, configFile := testProfileInit(t)
+
+	err := ngsi.AddProfile("dev", true)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, true, ngsi.profiles["dev"].Protected)
+		assert.Equal(t, true, strings.Contains(string(configFile.Encoded), `"dev":{"protected":true,"servers":{}}`))
+	}
+}
+
+func TestAddProfileErrorName(t *testing.T) {
+	ngsi, _ := testProfileInit(t)
+
+	err := ngsi.AddProfile("@dev", false)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 1, ngsiErr.ErrNo)
+		assert.Equal(t, "name error @dev", ngsiErr.Message)
+	}
+}
+
+func TestAddProfileErrorExists(t *testing.T) {
+	ngsi, _ := testProfileInit(t)
+
+	err := ngsi.AddProfile("prod", false)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 2, ngsiErr.ErrNo)
+		assert.Equal(t, "prod already exists", ngsiErr.Message)
+	}
+}
+
+func TestAddProfileErrorSave(t *testing.T) {
+	ngsi, configFile := testProfileInit(t)
+	configFile.OpenErr = errors.New("open error")
+
+	err := ngsi.AddProfile("dev", false)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 3, ngsiErr.ErrNo)
+		assert.Equal(t, "open error", ngsiErr.Message)
+	}
+}
+
+func TestUpdateProfile(t *testing.T) {
+	ngsi, _ := testProfileInit(t)
+
+	err := ngsi.UpdateProfile("prod", false)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, false, ngsi.profiles["prod"].Protected)
+	}
+}
+
+func TestUpdateProfileErrorDefault(t *testing.T) {
+	ngsi, _ := testProfileInit(t)
+
+	err := ngsi.UpdateProfile(DefaultProfile, true)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 1, ngsiErr.ErrNo)
+		assert.Equal(t, "default profile cannot be protected", ngsiErr.Message)
+	}
+}
+
+func TestUpdateProfileErrorNotFound(t *testing.T) {
+	ngsi, _ := testProfileInit(t)
+
+	err := ngsi.UpdateProfile("dev", true)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 2, ngsiErr.ErrNo)
+		assert.Equal(t, "profile not found: dev", ngsiErr.Message)
+	}
+}
+
+func TestUpdateProfileErrorSave(t *testing.T) {
+	ngsi, configFile := testProfileInit(t)
+	configFile.OpenErr = errors.New("open error")
+
+	err := ngsi.UpdateProfile("prod", true)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 3, ngsiErr.ErrNo)
+		assert.Equal(t, "open error", ngsiErr.Message)
+	}
+}
+
+func TestDeleteProfile(t *testing.T) {
+	ngsi, _ := testProfileInit(t)
+
+	err := ngsi.DeleteProfile("staging")
+
+	if assert.NoError(t, err) {
+		_, ok := ngsi.profiles["staging"]
+		assert.Equal(t, false, ok)
+	}
+}
+
+func TestDeleteProfileErrorDefault(t *testing.T) {
+	ngsi, _ := testProfileInit(t)
+
+	err := ngsi.DeleteProfile(DefaultProfile)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 1, ngsiErr.ErrNo)
+		assert.Equal(t, "default profile cannot be deleted", ngsiErr.Message)
+	}
+}
+
+func TestDeleteProfileErrorNotFound(t *testing.T) {
+	ngsi, _ := testProfileInit(t)
+
+	err := ngsi.DeleteProfile("dev")
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 2, ngsiErr.ErrNo)
+		assert.Equal(t, "profile not found: dev", ngsiErr.Message)
+	}
+}
+
+func TestDeleteProfileErrorProtected(t *testing.T) {
+	ngsi, _ := testProfileInit(t)
+
+	err := ngsi.DeleteProfile("prod")
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 3, ngsiErr.ErrNo)
+		assert.Equal(t, "prod is protected", ngsiErr.Message)
+	}
+}
+
+func TestDeleteProfileErrorInUse(t *testing.T) {
+	ngsi, _ := testProfileInit(t)
+	ngsi.currentProfile = "staging"
+
+	err := ngsi.DeleteProfile("staging")
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 4, ngsiErr.ErrNo)
+		assert.Equal(t, "staging is in use", ngsiErr.Message)
+	}
+}
+
+func TestDeleteProfileErrorSave(t *testing.T) {
+	ngsi, configFile := testProfileInit(t)
+	configFile.OpenErr = errors.New("open error")
+
+	err := ngsi.DeleteProfile("staging")
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 5, ngsiErr.ErrNo)
+		assert.Equal(t, "open error", ngsiErr.Message)
+	}
+}
+
+func TestUseProfile(t *testing.T) {
+	ngsi, configFile := testProfileInit(t)
+	ngsi.ServerList["orion2"] = &Server{ServerHost: "http://orion2"}
+
+	err := ngsi.UseProfile("prod")
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, "prod", ngsi.CurrentProfile())
+		assert.Equal(t, "prod", ngsi.currentProfile)
+		assert.Equal(t, "http://orion-prod:1026", ngsi.ServerList["orion"].ServerHost)
+		assert.Equal(t, "city", ngsi.PreviousArgs.Tenant)
+		assert.Equal(t, true, strings.Contains(string(configFile.Encoded), `"profile":"prod"`))
+		assert.Equal(t, true, strings.Contains(string(configFile.Encoded), `"orion2":{"serverHost":"http://orion2"}`))
+	}
+}
+
+func TestUseProfileDefault(t *testing.T) {
+	ngsi, configFile := testProfileInit(t)
+	_ = ngsi.UseProfile("prod")
+
+	err := ngsi.UseProfile(DefaultProfile)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, DefaultProfile, ngsi.CurrentProfile())
+		assert.Equal(t, "", ngsi.currentProfile)
+		assert.Equal(t, "http://orion-dev:1026", ngsi.ServerList["orion"].ServerHost)
+		assert.Equal(t, "", ngsi.PreviousArgs.Tenant)
+		assert.Equal(t, false, strings.Contains(string(configFile.Encoded), `"profile":`))
+	}
+}
+
+func TestUseProfileErrorNotFound(t *testing.T) {
+	ngsi, _ := testProfileInit(t)
+
+	err := ngsi.UseProfile("dev")
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 1, ngsiErr.ErrNo)
+		assert.Equal(t, "profile not found: dev", ngsiErr.Message)
+	}
+}
+
+func TestUseProfileErrorSave(t *testing.T) {
+	ngsi, configFile := testProfileInit(t)
+	configFile.OpenErr = errors.New("open error")
+
+	err := ngsi.UseProfile("prod")
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 2, ngsiErr.ErrNo)
+		assert.Equal(t, "open error", ngsiErr.Message)
+	}
+}
+
+func TestConfirmProfile(t *testing.T) {
+	ngsi := testNgsiLibInit()
+	ngsi.ConfigFile = &MockIoLib{}
+	ngsi.TermLib = &MockTermLib{Terminal: true}
+	ngsi.StdReader = strings.NewReader("prod\nnext")
+	stderr := &strings.Builder{}
+	ngsi.Stderr = stderr
+
+	err := ngsi.confirmProfile("prod")
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, "Profile prod is protected. Type the profile name to continue: ", stderr.String())
+		assert.Equal(t, true, ngsi.profileConfirmed)
+		assert.NoError(t, ngsi.confirmProfile("prod"))
+		ngsi.ResetProfileConfirmation()
+		assert.Equal(t, false, ngsi.profileConfirmed)
+	}
+}
+
+func TestConfirmProfileEnv(t *testing.T) {
+	ngsi := testNgsiLibInit()
+	ngsi.ConfigFile = &MockIoLib{Env: "prod"}
+	ngsi.TermLib = &MockTermLib{}
+
+	err := ngsi.confirmProfile("prod")
+
+	assert.NoError(t, err)
+}
+
+func TestConfirmProfileErrorNotTerminal(t *testing.T) {
+	ngsi := testNgsiLibInit()
+	ngsi.ConfigFile = &MockIoLib{Env: "dev"}
+	ngsi.TermLib = &MockTermLib{}
+
+	err := ngsi.confirmProfile("prod")
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 1, ngsiErr.ErrNo)
+		assert.Equal(t, "profile prod is protected. set NGSI_GO_PROFILE_CONFIRM to the profile name to confirm", ngsiErr.Message)
+	}
+}
+
+func TestConfirmProfileErrorRead(t *testing.T) {
+	ngsi := testNgsiLibInit()
+	ngsi.ConfigFile = &MockIoLib{}
+	ngsi.TermLib = &MockTermLib{Terminal: true}
+	ngsi.StdReader = &testProfileErrReader{}
+	ngsi.Stderr = &strings.Builder{}
+
+	err := ngsi.confirmProfile("prod")
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 2, ngsiErr.ErrNo)
+		assert.Equal(t, "read error", ngsiErr.Message)
+	}
+}
+
+func TestConfirmProfileErrorCanceled(t *testing.T) {
+	ngsi := testNgsiLibInit()
+	ngsi.ConfigFile = &MockIoLib{}
+	ngsi.TermLib = &MockTermLib{Terminal: true}
+	ngsi.StdReader = strings.NewReader("y\n")
+	ngsi.Stderr = &strings.Builder{}
+
+	err := ngsi.confirmProfile("prod")
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 3, ngsiErr.ErrNo)
+		assert.Equal(t, "canceled", ngsiErr.Message)
+		assert.Equal(t, false, ngsi.profileConfirmed)
+	}
+}
+
+func TestReadLine(t *testing.T) {
+	ngsi := testNgsiLibInit()
+	ngsi.StdReader = strings.NewReader(" prod \r")
+
+	actual, err := ngsi.readLine()
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, "prod", actual)
+	}
+}
+
+func TestReadLineError(t *testing.T) {
+	ngsi := testNgsiLibInit()
+	ngsi.StdReader = &testProfileErrReader{}
+
+	_, err := ngsi.readLine()
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 1, ngsiErr.ErrNo)
+		assert.Equal(t, "read error", ngsiErr.Message)
+	}
+}
@@ -0,0 +1,134 @@
+/*
+MIT License
+
+Copyright (c) 2020-2024 Kazuhito Suda
+
+This file is part of NGSI Go
+
+https://github.com/lets-fiware/ngsi-go
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+
+*/
+
+package ngsilib
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/lets-fiware/ngsi-go/internal/ngsierr"
+)
+
+func proxyFunc(server *Server) (func(*http.Request) (*url.URL, error), error) {
+	const funcName = "proxyFunc"
+
+	proxy, err := parseProxy(server.Proxy)
+	if err != nil {
+		return nil, ngsierr.New(funcName, 1, err.Error(), err)
+	}
+	noProxy := server.NoProxy
+
+	return func(req *http.Request) (*url.URL, error) {
+		if isNoProxy(req.URL, noProxy) {
+			return nil, nil
+		}
+		return proxy, nil
+	}, nil
+}
+
+func parseProxy(proxy string) (*url.URL, error) {
+	const funcName = "parseProxy"
+
+	u, err := url.Parse(proxy)
+	if err != nil {
+		return nil, ngsierr.New(funcName, 1, err.Error(), err)
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5", "socks5h":
+	default:
+		return nil, ngsierr.New(funcName, 2, fmt.Sprintf("proxy error: %s (http, https, socks5)", u.Redacted()), nil)
+	}
+	if u.Host == "" {
+		return nil, ngsierr.New(funcName, 3, fmt.Sprintf("proxy error: host not found in %s", u.Redacted()), nil)
+	}
+	return u, nil
+}
+
+func isNoProxy(u *url.URL, noProxy string) bool {
+	host := strings.ToLower(u.Hostname())
+	port := u.Port()
+	if port == "" {
+		port = "80"
+		if u.Scheme == "https" {
+			port = "443"
+		}
+	}
+	ip := net.ParseIP(host)
+
+	for _, e := range strings.Split(noProxy, ",") {
+		e = strings.ToLower(strings.TrimSpace(e))
+		if e == "" {
+			continue
+		}
+		if e == "*" {
+			return true
+		}
+		if _, ipNet, err := net.ParseCIDR(e); err == nil {
+			if ip != nil && ipNet.Contains(ip) {
+				return true
+			}
+			continue
+		}
+		if h, p, err := net.SplitHostPort(e); err == nil {
+			if p != port {
+				continue
+			}
+			e = h
+		}
+		if ip != nil {
+			if eip := net.ParseIP(e); eip != nil && eip.Equal(ip) {
+				return true
+			}
+			continue
+		}
+		e = strings.TrimPrefix(e, "*")
+		e = strings.TrimPrefix(e, ".")
+		if host == e || strings.HasSuffix(host, "."+e) {
+			return true
+		}
+	}
+	return false
+}
+
+func checkProxyParams(server *Server) error {
+	const funcName = "checkProxyParams"
+
+	if server.Proxy != "" {
+		if _, err := parseProxy(server.Proxy); err != nil {
+			return ngsierr.New(funcName, 1, err.Error(), err)
+		}
+	} else if server.NoProxy != "" {
+		return ngsierr.New(funcName, 2, "noProxy requires proxy", nil)
+	}
+	return nil
+}
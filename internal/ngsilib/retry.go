@@ -0,0 +1,170 @@
+/*
+MIT License
+
+Copyright (c) 2020-2024 Kazuhito Suda
+
+This file is part of NGSI Go
+
+https://github.com/lets-fiware/ngsi-go
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+
+*/
+
+package ngsilib
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lets-fiware/ngsi-go/internal/ngsierr"
+)
+
+// RetryPolicy is ...
+type RetryPolicy struct {
+	MaxAttempts int
+	Backoff     time.Duration
+	MaxBackoff  time.Duration
+	RetryOn     []int
+}
+
+const (
+	defaultRetryMaxAttempts = 1
+	defaultRetryBackoff     = time.Second
+	defaultRetryMaxBackoff  = 30 * time.Second
+	retryLimit              = 10
+)
+
+var defaultRetryOn = []int{429, 502, 503, 504}
+
+// NewRetryPolicy is ...
+func NewRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts: defaultRetryMaxAttempts,
+		Backoff:     defaultRetryBackoff,
+		MaxBackoff:  defaultRetryMaxBackoff,
+		RetryOn:     defaultRetryOn,
+	}
+}
+
+// SetParams is ...
+func (p *RetryPolicy) SetParams(maxAttempts, backoff, retryOn string) error {
+	const funcName = "SetParams"
+
+	if maxAttempts != "" {
+		n, err := parseRetryMax(maxAttempts)
+		if err != nil {
+			return ngsierr.New(funcName, 1, err.Error(), err)
+		}
+		p.MaxAttempts = n
+	}
+	if backoff != "" {
+		d, err := parseRetryBackoff(backoff)
+		if err != nil {
+			return ngsierr.New(funcName, 2, err.Error(), err)
+		}
+		p.Backoff = d
+	}
+	if retryOn != "" {
+		codes, err := parseRetryOn(retryOn)
+		if err != nil {
+			return ngsierr.New(funcName, 3, err.Error(), err)
+		}
+		p.RetryOn = codes
+	}
+	return nil
+}
+
+// IsRetryStatus is ...
+func (p *RetryPolicy) IsRetryStatus(code int) bool {
+	for _, c := range p.RetryOn {
+		if c == code {
+			return true
+		}
+	}
+	return false
+}
+
+// Wait returns the backoff duration before the next attempt. It grows exponentially
+// and a full jitter is applied so that concurrent clients do not retry at once.
+func (p *RetryPolicy) Wait(attempt int) time.Duration {
+	d := p.Backoff
+	for i := 1; i < attempt && d < p.MaxBackoff; i++ {
+		d *= 2
+	}
+	if p.MaxBackoff > 0 && d > p.MaxBackoff {
+		d = p.MaxBackoff
+	}
+	if d <= 0 {
+		return 0
+	}
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+}
+
+func (ngsi *NGSI) retryPolicy(server *Server) (*RetryPolicy, error) {
+	const funcName = "retryPolicy"
+
+	p := NewRetryPolicy()
+	if ngsi.Retry != nil {
+		*p = *ngsi.Retry
+	}
+	if server != nil {
+		if err := p.SetParams(server.RetryMax, server.RetryBackoff, server.RetryOn); err != nil {
+			return nil, ngsierr.New(funcName, 1, err.Error(), err)
+		}
+	}
+	return p, nil
+}
+
+func parseRetryMax(s string) (int, error) {
+	const funcName = "parseRetryMax"
+
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 1 || n > retryLimit {
+		return 0, ngsierr.New(funcName, 1, fmt.Sprintf("retryMax error: %s (1-%d)", s, retryLimit), nil)
+	}
+	return n, nil
+}
+
+func parseRetryBackoff(s string) (time.Duration, error) {
+	const funcName = "parseRetryBackoff"
+
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0, ngsierr.New(funcName, 1, fmt.Sprintf("retryBackoff error: %s", s), nil)
+	}
+	return d, nil
+}
+
+func parseRetryOn(s string) ([]int, error) {
+	const funcName = "parseRetryOn"
+
+	codes := []int{}
+	for _, v := range strings.Split(s, ",") {
+		code, err := strconv.Atoi(strings.TrimSpace(v))
+		if err != nil || code < 100 || code > 599 {
+			return nil, ngsierr.New(funcName, 1, fmt.Sprintf("retryOn error: %s", v), nil)
+		}
+		codes = append(codes, code)
+	}
+	return codes, nil
+}
@@ -0,0 +1,174 @@
+/*
+MIT License
+
+Copyright (c) 2020-2024 Kazuhito Suda
+
+This file is part of NGSI Go
+
+https://github.com/lets-fiware/ngsi-go
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+
+*/
+
+package ngsilib
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lets-fiware/ngsi-go/internal/assert"
+	"github.com/lets-fiware/ngsi-go/internal/ngsierr"
+)
+
+func TestNewRetryPolicy(t *testing.T) {
+	actual := NewRetryPolicy()
+
+	assert.Equal(t, 1, actual.MaxAttempts)
+	assert.Equal(t, time.Second, actual.Backoff)
+	assert.Equal(t, 30*time.Second, actual.MaxBackoff)
+	assert.Equal(t, []int{429, 502, 503, 504}, actual.RetryOn)
+}
+
+func TestRetryPolicySetParams(t *testing.T) {
+	p := NewRetryPolicy()
+
+	err := p.SetParams("5", "250ms", "500, 503")
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, 5, p.MaxAttempts)
+		assert.Equal(t, 250*time.Millisecond, p.Backoff)
+		assert.Equal(t, []int{500, 503}, p.RetryOn)
+	}
+}
+
+func TestRetryPolicySetParamsEmpty(t *testing.T) {
+	p := NewRetryPolicy()
+
+	err := p.SetParams("", "", "")
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, NewRetryPolicy(), p)
+	}
+}
+
+func TestRetryPolicySetParamsErrorMax(t *testing.T) {
+	p := NewRetryPolicy()
+
+	err := p.SetParams("11", "", "")
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 1, ngsiErr.ErrNo)
+		assert.Equal(t, "retryMax error: 11 (1-10)", ngsiErr.Message)
+	}
+}
+
+func TestRetryPolicySetParamsErrorBackoff(t *testing.T) {
+	p := NewRetryPolicy()
+
+	err := p.SetParams("", "1x", "")
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 2, ngsiErr.ErrNo)
+		assert.Equal(t, "retryBackoff error: 1x", ngsiErr.Message)
+	}
+}
+
+func TestRetryPolicySetParamsErrorRetryOn(t *testing.T) {
+	p := NewRetryPolicy()
+
+	err := p.SetParams("", "", "503,600")
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 3, ngsiErr.ErrNo)
+		assert.Equal(t, "retryOn error: 600", ngsiErr.Message)
+	}
+}
+
+func TestRetryPolicyIsRetryStatus(t *testing.T) {
+	p := NewRetryPolicy()
+
+	assert.Equal(t, true, p.IsRetryStatus(503))
+	assert.Equal(t, false, p.IsRetryStatus(500))
+}
+
+func TestRetryPolicyWait(t *testing.T) {
+	p := &RetryPolicy{Backoff: 100 * time.Millisecond, MaxBackoff: 300 * time.Millisecond}
+
+	cases := []struct {
+		attempt int
+		max     time.Duration
+	}{
+		{attempt: 1, max: 100 * time.Millisecond},
+		{attempt: 2, max: 200 * time.Millisecond},
+		{attempt: 3, max: 300 * time.Millisecond},
+		{attempt: 8, max: 300 * time.Millisecond},
+	}
+
+	for _, c := range cases {
+		actual := p.Wait(c.attempt)
+		assert.Equal(t, true, actual >= c.max/2 && actual <= c.max)
+	}
+}
+
+func TestRetryPolicyWaitZero(t *testing.T) {
+	p := &RetryPolicy{}
+
+	assert.Equal(t, time.Duration(0), p.Wait(1))
+}
+
+func TestRetryPolicyServer(t *testing.T) {
+	ngsi := testNgsiLibInit()
+	ngsi.Retry = &RetryPolicy{MaxAttempts: 3, Backoff: time.Second, RetryOn: []int{503}}
+
+	actual, err := ngsi.retryPolicy(&Server{RetryMax: "5"})
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, 5, actual.MaxAttempts)
+		assert.Equal(t, time.Second, actual.Backoff)
+		assert.Equal(t, []int{503}, actual.RetryOn)
+		assert.Equal(t, 3, ngsi.Retry.MaxAttempts)
+	}
+}
+
+func TestRetryPolicyServerNil(t *testing.T) {
+	ngsi := testNgsiLibInit()
+	ngsi.Retry = nil
+
+	actual, err := ngsi.retryPolicy(nil)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, NewRetryPolicy(), actual)
+	}
+}
+
+func TestRetryPolicyServerError(t *testing.T) {
+	ngsi := testNgsiLibInit()
+
+	_, err := ngsi.retryPolicy(&Server{RetryMax: "0"})
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 1, ngsiErr.ErrNo)
+		assert.Equal(t, "retryMax error: 0 (1-10)", ngsiErr.Message)
+	}
+}
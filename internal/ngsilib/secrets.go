@@ -0,0 +1,720 @@
+/*
+MIT License
+
+Copyright (c) 2020-2024 Kazuhito Suda
+
+This file is part of NGSI Go
+
+https://github.com/lets-fiware/ngsi-go
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+
+*/
+
+package ngsilib
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/lets-fiware/ngsi-go/internal/ngsierr"
+)
+
+// SecretsBackend seals and opens the secrets stored in the config file and the token cache
+type SecretsBackend interface {
+	Seal(name, value string) (string, error)
+	Open(name, sealed string) (string, error)
+	IsSealed(value string) bool
+}
+
+// SecretsConfig is ...
+type SecretsConfig struct {
+	Backend    string `json:"backend"`
+	Salt       string `json:"salt,omitempty"`
+	Iterations int    `json:"iterations,omitempty"`
+	Check      string `json:"check,omitempty"`
+	Helper     string `json:"helper,omitempty"`
+}
+
+const (
+	CSecretsPassphrase = "passphrase"
+	CSecretsHelper     = "helper"
+)
+
+// PassphraseEnv is the environment variable holding the passphrase of the secrets
+const PassphraseEnv = "NGSI_GO_PASSPHRASE"
+
+type newSecretsBackend func(ngsi *NGSI, config *SecretsConfig) (SecretsBackend, error)
+
+var secretsBackends = map[string]newSecretsBackend{
+	CSecretsPassphrase: newPassphraseBackend,
+	CSecretsHelper:     newHelperBackend,
+}
+
+const (
+	secretsCheck     = "ngsi-go"
+	secretsTokenName = "token-cache"
+	encPrefix        = "enc:"
+	helperPrefix     = "helper:"
+)
+
+var secretsIterations = 600000
+
+var secretsRand io.Reader = rand.Reader
+
+// SecretsConfig returns the secrets section of the config file. It is nil when the secrets are not encrypted.
+func (ngsi *NGSI) SecretsConfig() *SecretsConfig {
+	return ngsi.secretsConfig
+}
+
+func (ngsi *NGSI) initSecrets(config *SecretsConfig) error {
+	const funcName = "initSecrets"
+
+	ngsi.secretsConfig = nil
+	ngsi.secrets = nil
+	ngsi.openedSecrets = make(map[string]string)
+	ngsi.loadedSecrets = make(map[string]string)
+
+	if config == nil {
+		return nil
+	}
+
+	newBackend, ok := secretsBackends[config.Backend]
+	if !ok {
+		return ngsierr.New(funcName, 1, "unknown secrets backend: "+config.Backend, nil)
+	}
+	backend, err := newBackend(ngsi, config)
+	if err != nil {
+		return ngsierr.New(funcName, 2, err.Error(), err)
+	}
+
+	ngsi.secretsConfig = config
+	ngsi.secrets = backend
+
+	ngsi.activeProfile()
+
+	for name, p := range ngsi.profiles {
+		for host, server := range p.Servers {
+			for _, secret := range serverSecrets(server) {
+				if *secret.value != "" {
+					ngsi.loadedSecrets[secretName(profileHost(name, host), secret.name)] = *secret.value
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+type serverSecret struct {
+	name  string
+	value *string
+}
+
+func serverSecrets(server *Server) []serverSecret {
+	return []serverSecret{
+		{cPassword, &server.Password},
+		{cClientSecret, &server.ClientSecret},
+		{cToken, &server.Token},
+		{cHeaderValue, &server.HeaderValue},
+	}
+}
+
+func secretName(host, name string) string {
+	return host + "/" + name
+}
+
+// OpenServerSecrets decrypts the secrets of a server in place
+func (ngsi *NGSI) OpenServerSecrets(server *Server) error {
+	const funcName = "OpenServerSecrets"
+
+	for _, secret := range serverSecrets(server) {
+		if err := ngsi.openSecret(secret.value); err != nil {
+			return ngsierr.New(funcName, 1, err.Error(), err)
+		}
+	}
+
+	return nil
+}
+
+func (ngsi *NGSI) openSecret(value *string) error {
+	const funcName = "openSecret"
+
+	if ngsi.secrets == nil || !ngsi.secrets.IsSealed(*value) {
+		return nil
+	}
+
+	plain, err := ngsi.secrets.Open(ngsi.sealedName(*value), *value)
+	if err != nil {
+		return ngsierr.New(funcName, 1, err.Error(), err)
+	}
+	ngsi.openedSecrets[*value] = plain
+	*value = plain
+
+	return nil
+}
+
+// sealedName returns the name under which a sealed value is stored, so that it is opened with the name
+// it was sealed with. A sealed value stored under more than one name has no name and cannot be opened.
+func (ngsi *NGSI) sealedName(sealed string) string {
+	name := ""
+	for n, v := range ngsi.loadedSecrets {
+		if v == sealed {
+			if name != "" {
+				return ""
+			}
+			name = n
+		}
+	}
+	return name
+}
+
+func (ngsi *NGSI) openAllSecrets() error {
+	const funcName = "openAllSecrets"
+
+	ngsi.activeProfile()
+
+	for name, p := range ngsi.profiles {
+		for host, server := range p.Servers {
+			if err := ngsi.OpenServerSecrets(server); err != nil {
+				return ngsierr.New(funcName, 1, err.Error()+" in "+profileHost(name, host), err)
+			}
+		}
+	}
+	if err := ngsi.openTokenList(); err != nil {
+		return ngsierr.New(funcName, 2, err.Error(), err)
+	}
+
+	return nil
+}
+
+func (ngsi *NGSI) sealServerList(profile string, servers ServerList) (ServerList, error) {
+	const funcName = "sealServerList"
+
+	if ngsi.secrets == nil {
+		return servers, nil
+	}
+
+	list := make(ServerList)
+	for host, server := range servers {
+		s := *server
+		for _, secret := range serverSecrets(&s) {
+			sealed, err := ngsi.sealSecret(secretName(profileHost(profile, host), secret.name), *secret.value)
+			if err != nil {
+				return nil, ngsierr.New(funcName, 1, err.Error(), err)
+			}
+			*secret.value = sealed
+		}
+		list[host] = &s
+	}
+
+	return list, nil
+}
+
+// sealSecret reuses the sealed value read from the config file when the secret has not been changed,
+// so that the passphrase is not asked for and the credential helper is not called needlessly.
+// A sealed value of another name, such as one copied from the server referred to by a host, is opened
+// and sealed again with name.
+func (ngsi *NGSI) sealSecret(name, value string) (string, error) {
+	const funcName = "sealSecret"
+
+	if value == "" {
+		return value, nil
+	}
+	if ngsi.secrets.IsSealed(value) {
+		source := ngsi.sealedName(value)
+		if source == "" || source == name {
+			return value, nil
+		}
+		plain, err := ngsi.secrets.Open(source, value)
+		if err != nil {
+			return "", ngsierr.New(funcName, 1, err.Error(), err)
+		}
+		value = plain
+	}
+	if sealed, ok := ngsi.loadedSecrets[name]; ok {
+		if plain, ok := ngsi.openedSecrets[sealed]; ok && plain == value {
+			return sealed, nil
+		}
+	}
+
+	sealed, err := ngsi.secrets.Seal(name, value)
+	if err != nil {
+		return "", ngsierr.New(funcName, 2, err.Error(), err)
+	}
+	ngsi.loadedSecrets[name] = sealed
+	ngsi.openedSecrets[sealed] = value
+
+	return sealed, nil
+}
+
+func (ngsi *NGSI) openTokenList() error {
+	const funcName = "openTokenList"
+
+	if ngsi.sealedTokens == "" {
+		return nil
+	}
+	if ngsi.secrets == nil || !ngsi.secrets.IsSealed(ngsi.sealedTokens) {
+		return ngsierr.New(funcName, 1, "token cache is encrypted, but secrets backend not found", nil)
+	}
+
+	s, err := ngsi.secrets.Open(secretsTokenName, ngsi.sealedTokens)
+	if err != nil {
+		return ngsierr.New(funcName, 2, err.Error(), err)
+	}
+	list := make(tokenInfoList)
+	if err := JSONUnmarshal([]byte(s), &list); err != nil {
+		return ngsierr.New(funcName, 3, err.Error(), err)
+	}
+	ngsi.tokenList = list
+	ngsi.sealedTokens = ""
+
+	return nil
+}
+
+func (ngsi *NGSI) sealTokenList(tokenList *tokenInfoList) (string, error) {
+	const funcName = "sealTokenList"
+
+	b, err := JSONMarshal(tokenList)
+	if err != nil {
+		return "", ngsierr.New(funcName, 1, err.Error(), err)
+	}
+	sealed, err := ngsi.secrets.Seal(secretsTokenName, string(b))
+	if err != nil {
+		return "", ngsierr.New(funcName, 2, err.Error(), err)
+	}
+
+	return sealed, nil
+}
+
+// EncryptSecrets encrypts the secrets in the config file and the token cache.
+// The secrets are stored by the credential helper when helper is not empty, otherwise they are
+// encrypted with a key derived from a passphrase.
+func (ngsi *NGSI) EncryptSecrets(helper string) error {
+	const funcName = "EncryptSecrets"
+
+	if ngsi.secretsConfig != nil {
+		return ngsierr.New(funcName, 1, "secrets already encrypted", nil)
+	}
+	if err := ngsi.openAllSecrets(); err != nil {
+		return ngsierr.New(funcName, 2, err.Error(), err)
+	}
+
+	var config *SecretsConfig
+	var backend SecretsBackend
+	var err error
+
+	if helper != "" {
+		config = &SecretsConfig{Backend: CSecretsHelper, Helper: helper}
+		backend, err = newHelperBackend(ngsi, config)
+	} else {
+		config, backend, err = newPassphraseSecrets(ngsi)
+	}
+	if err != nil {
+		return ngsierr.New(funcName, 3, err.Error(), err)
+	}
+
+	ngsi.secretsConfig = config
+	ngsi.secrets = backend
+
+	if err := ngsi.saveSecrets(); err != nil {
+		return ngsierr.New(funcName, 4, err.Error(), err)
+	}
+
+	return nil
+}
+
+// DecryptSecrets stores the secrets in the config file and the token cache in plain text.
+func (ngsi *NGSI) DecryptSecrets() error {
+	const funcName = "DecryptSecrets"
+
+	if ngsi.secretsConfig == nil {
+		return ngsierr.New(funcName, 1, "secrets not encrypted", nil)
+	}
+	if err := ngsi.openAllSecrets(); err != nil {
+		return ngsierr.New(funcName, 2, err.Error(), err)
+	}
+
+	ngsi.secretsConfig = nil
+	ngsi.secrets = nil
+
+	if err := ngsi.saveSecrets(); err != nil {
+		return ngsierr.New(funcName, 3, err.Error(), err)
+	}
+
+	return nil
+}
+
+func (ngsi *NGSI) saveSecrets() error {
+	const funcName = "saveSecrets"
+
+	if err := ngsi.saveConfigFile(); err != nil {
+		return ngsierr.New(funcName, 1, err.Error(), err)
+	}
+	if ngsi.tokenList != nil {
+		if err := saveToken(*ngsi.CacheFile.FileName(), &ngsi.tokenList); err != nil {
+			return ngsierr.New(funcName, 2, err.Error(), err)
+		}
+	}
+
+	return nil
+}
+
+//
+// passphrase backend
+//
+
+type passphraseBackend struct {
+	ngsi   *NGSI
+	config *SecretsConfig
+	aead   cipher.AEAD
+}
+
+func newPassphraseBackend(ngsi *NGSI, config *SecretsConfig) (SecretsBackend, error) {
+	const funcName = "newPassphraseBackend"
+
+	if config.Salt == "" || config.Iterations <= 0 || config.Check == "" {
+		return nil, ngsierr.New(funcName, 1, "salt, iterations and check are required", nil)
+	}
+
+	return &passphraseBackend{ngsi: ngsi, config: config}, nil
+}
+
+func newPassphraseSecrets(ngsi *NGSI) (*SecretsConfig, SecretsBackend, error) {
+	const funcName = "newPassphraseSecrets"
+
+	passphrase, err := ngsi.readPassphrase("New passphrase: ", true)
+	if err != nil {
+		return nil, nil, ngsierr.New(funcName, 1, err.Error(), err)
+	}
+
+	salt := make([]byte, 16)
+	if _, err := io.ReadFull(secretsRand, salt); err != nil {
+		return nil, nil, ngsierr.New(funcName, 2, err.Error(), err)
+	}
+
+	aead, err := newSecretsAEAD([]byte(passphrase), salt, secretsIterations)
+	if err != nil {
+		return nil, nil, ngsierr.New(funcName, 3, err.Error(), err)
+	}
+
+	check, err := sealAEAD(aead, "", secretsCheck)
+	if err != nil {
+		return nil, nil, ngsierr.New(funcName, 4, err.Error(), err)
+	}
+
+	config := &SecretsConfig{
+		Backend:    CSecretsPassphrase,
+		Salt:       base64.StdEncoding.EncodeToString(salt),
+		Iterations: secretsIterations,
+		Check:      check,
+	}
+
+	return config, &passphraseBackend{ngsi: ngsi, config: config, aead: aead}, nil
+}
+
+func (b *passphraseBackend) Seal(name, value string) (string, error) {
+	const funcName = "Seal"
+
+	aead, err := b.key()
+	if err != nil {
+		return "", ngsierr.New(funcName, 1, err.Error(), err)
+	}
+	sealed, err := sealAEAD(aead, name, value)
+	if err != nil {
+		return "", ngsierr.New(funcName, 2, err.Error(), err)
+	}
+
+	return sealed, nil
+}
+
+func (b *passphraseBackend) Open(name, sealed string) (string, error) {
+	const funcName = "Open"
+
+	aead, err := b.key()
+	if err != nil {
+		return "", ngsierr.New(funcName, 1, err.Error(), err)
+	}
+	value, err := openAEAD(aead, name, sealed)
+	if err != nil {
+		return "", ngsierr.New(funcName, 2, err.Error(), err)
+	}
+
+	return value, nil
+}
+
+func (b *passphraseBackend) IsSealed(value string) bool {
+	return strings.HasPrefix(value, encPrefix)
+}
+
+func (b *passphraseBackend) key() (cipher.AEAD, error) {
+	const funcName = "key"
+
+	if b.aead != nil {
+		return b.aead, nil
+	}
+
+	salt, err := base64.StdEncoding.DecodeString(b.config.Salt)
+	if err != nil {
+		return nil, ngsierr.New(funcName, 1, err.Error(), err)
+	}
+
+	passphrase, err := b.ngsi.readPassphrase("Passphrase: ", false)
+	if err != nil {
+		return nil, ngsierr.New(funcName, 2, err.Error(), err)
+	}
+
+	aead, err := newSecretsAEAD([]byte(passphrase), salt, b.config.Iterations)
+	if err != nil {
+		return nil, ngsierr.New(funcName, 3, err.Error(), err)
+	}
+
+	if check, err := openAEAD(aead, "", b.config.Check); err != nil || check != secretsCheck {
+		return nil, ngsierr.New(funcName, 4, "wrong passphrase", err)
+	}
+	b.aead = aead
+
+	return aead, nil
+}
+
+func newSecretsAEAD(passphrase, salt []byte, iterations int) (cipher.AEAD, error) {
+	const funcName = "newSecretsAEAD"
+
+	block, err := aes.NewCipher(pbkdf2SHA256(passphrase, salt, iterations, 32))
+	if err != nil {
+		return nil, ngsierr.New(funcName, 1, err.Error(), err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, ngsierr.New(funcName, 2, err.Error(), err)
+	}
+
+	return aead, nil
+}
+
+// sealAEAD encrypts value with name as the additional data, so that the sealed value cannot be opened as
+// the secret of another name.
+func sealAEAD(aead cipher.AEAD, name, value string) (string, error) {
+	const funcName = "sealAEAD"
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(secretsRand, nonce); err != nil {
+		return "", ngsierr.New(funcName, 1, err.Error(), err)
+	}
+	b := aead.Seal(nonce, nonce, []byte(value), []byte(name))
+
+	return encPrefix + base64.StdEncoding.EncodeToString(b), nil
+}
+
+func openAEAD(aead cipher.AEAD, name, sealed string) (string, error) {
+	const funcName = "openAEAD"
+
+	b, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(sealed, encPrefix))
+	if err != nil {
+		return "", ngsierr.New(funcName, 1, err.Error(), err)
+	}
+	size := aead.NonceSize()
+	if len(b) < size {
+		return "", ngsierr.New(funcName, 2, "sealed value too short", nil)
+	}
+	value, err := aead.Open(nil, b[:size], b[size:], []byte(name))
+	if err != nil {
+		return "", ngsierr.New(funcName, 3, err.Error(), err)
+	}
+
+	return string(value), nil
+}
+
+// pbkdf2SHA256 derives a key from a passphrase as described in RFC 8018 with HMAC-SHA256 as PRF.
+func pbkdf2SHA256(passphrase, salt []byte, iterations, keyLen int) []byte {
+	prf := hmac.New(sha256.New, passphrase)
+	hashLen := prf.Size()
+	numBlocks := (keyLen + hashLen - 1) / hashLen
+
+	var counter [4]byte
+	dk := make([]byte, 0, numBlocks*hashLen)
+	u := make([]byte, hashLen)
+
+	for block := 1; block <= numBlocks; block++ {
+		prf.Reset()
+		prf.Write(salt)
+		binary.BigEndian.PutUint32(counter[:], uint32(block))
+		prf.Write(counter[:])
+		dk = prf.Sum(dk)
+		t := dk[len(dk)-hashLen:]
+		copy(u, t)
+
+		for n := 2; n <= iterations; n++ {
+			prf.Reset()
+			prf.Write(u)
+			u = prf.Sum(u[:0])
+			for i := range u {
+				t[i] ^= u[i]
+			}
+		}
+	}
+
+	return dk[:keyLen]
+}
+
+func (ngsi *NGSI) readPassphrase(prompt string, confirm bool) (string, error) {
+	const funcName = "readPassphrase"
+
+	if passphrase := ngsi.ConfigFile.Getenv(PassphraseEnv); passphrase != "" {
+		return passphrase, nil
+	}
+
+	if !ngsi.TermLib.IsTerminal(os.Stdin.Fd()) {
+		return "", ngsierr.New(funcName, 1, "passphrase required. set it to "+PassphraseEnv, nil)
+	}
+
+	passphrase, err := ngsi.promptPassphrase(prompt)
+	if err != nil {
+		return "", ngsierr.New(funcName, 2, err.Error(), err)
+	}
+	if passphrase == "" {
+		return "", ngsierr.New(funcName, 3, "passphrase is empty", nil)
+	}
+
+	if confirm {
+		again, err := ngsi.promptPassphrase("Retype passphrase: ")
+		if err != nil {
+			return "", ngsierr.New(funcName, 4, err.Error(), err)
+		}
+		if again != passphrase {
+			return "", ngsierr.New(funcName, 5, "passphrases do not match", nil)
+		}
+	}
+
+	return passphrase, nil
+}
+
+func (ngsi *NGSI) promptPassphrase(prompt string) (string, error) {
+	const funcName = "promptPassphrase"
+
+	restore, err := ngsi.TermLib.MakeRaw(os.Stdin.Fd())
+	if err != nil {
+		return "", ngsierr.New(funcName, 1, err.Error(), err)
+	}
+	defer restore()
+
+	fmt.Fprint(ngsi.Stderr, prompt)
+	defer fmt.Fprint(ngsi.Stderr, "\n")
+
+	var buf []byte
+	b := make([]byte, 1)
+
+	for {
+		n, err := ngsi.StdReader.Read(b)
+		if n == 0 {
+			if err == io.EOF {
+				return string(buf), nil
+			}
+			if err != nil {
+				return "", ngsierr.New(funcName, 2, err.Error(), err)
+			}
+			continue
+		}
+
+		switch b[0] {
+		case '\r', '\n', 0x04: // Enter, Ctrl-D
+			return string(buf), nil
+		case 0x03: // Ctrl-C
+			return "", ngsierr.New(funcName, 3, "canceled", nil)
+		case 0x08, 0x7f: // Backspace
+			if len(buf) > 0 {
+				_, size := utf8.DecodeLastRune(buf)
+				buf = buf[:len(buf)-size]
+			}
+		case 0x15: // Ctrl-U
+			buf = buf[:0]
+		default:
+			buf = append(buf, b[0])
+		}
+	}
+}
+
+//
+// credential helper backend
+//
+
+type helperBackend struct {
+	ngsi    *NGSI
+	program []string
+}
+
+func newHelperBackend(ngsi *NGSI, config *SecretsConfig) (SecretsBackend, error) {
+	const funcName = "newHelperBackend"
+
+	program := strings.Fields(config.Helper)
+	if len(program) == 0 {
+		return nil, ngsierr.New(funcName, 1, "helper program is required", nil)
+	}
+
+	return &helperBackend{ngsi: ngsi, program: program}, nil
+}
+
+func (b *helperBackend) Seal(name, value string) (string, error) {
+	const funcName = "Seal"
+
+	if _, err := b.run([]byte(value), "store", name); err != nil {
+		return "", ngsierr.New(funcName, 1, err.Error(), err)
+	}
+
+	return helperPrefix + name, nil
+}
+
+// Open gets the secret stored under the name in sealed, which is the name given to Seal.
+func (b *helperBackend) Open(name, sealed string) (string, error) {
+	const funcName = "Open"
+
+	out, err := b.run(nil, "get", strings.TrimPrefix(sealed, helperPrefix))
+	if err != nil {
+		return "", ngsierr.New(funcName, 1, err.Error(), err)
+	}
+
+	return strings.TrimRight(string(out), "\r\n"), nil
+}
+
+func (b *helperBackend) IsSealed(value string) bool {
+	return strings.HasPrefix(value, helperPrefix)
+}
+
+func (b *helperBackend) run(stdin []byte, args ...string) ([]byte, error) {
+	const funcName = "run"
+
+	args = append(append([]string{}, b.program[1:]...), args...)
+
+	out, err := b.ngsi.ExecLib.Output(b.program[0], args, stdin)
+	if err != nil {
+		return nil, ngsierr.New(funcName, 1, b.program[0]+" "+args[len(args)-2]+": "+err.Error(), err)
+	}
+
+	return out, nil
+}
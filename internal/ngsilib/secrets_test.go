@@ -0,0 +1,855 @@
+/*
+MIT License
+
+Copyright (c) 2020-2024 Kazuhito Suda
+
+This file is part of NGSI Go
+
+https://github.com/lets-fiware/ngsi-go
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+
+*/
+
+package ngsilib
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/lets-fiware/ngsi-go/internal/assert"
+	"github.com/lets-fiware/ngsi-go/internal/ngsierr"
+)
+
+type errReader struct{}
+
+func (r *errReader) Read(p []byte) (int, error) {
+	return 0, errors.New("rand error")
+}
+
+func testSecretsInit(passphrase string) *NGSI {
+	ngsi := testNgsiLibInit()
+	secretsIterations = 10
+	filename := ""
+	ngsi.ConfigFile = &MockIoLib{filename: &filename, Env: passphrase}
+	ngsi.CacheFile = &MockIoLib{filename: &filename}
+	ngsi.TermLib = &MockTermLib{}
+	ngsi.ExecLib = &MockExecLib{}
+	return ngsi
+}
+
+func testPassphraseSecrets(t *testing.T, ngsi *NGSI) *SecretsConfig {
+	config, backend, err := newPassphraseSecrets(ngsi)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ngsi.secretsConfig = config
+	ngsi.secrets = backend
+	return config
+}
+
+func TestPbkdf2SHA256(t *testing.T) {
+	cases := []struct {
+		passphrase string
+		salt       string
+		iterations int
+		keyLen     int
+		expected   string
+	}{
+		{passphrase: "passwd", salt: "salt", iterations: 1, keyLen: 64, expected: "55ac046e56e3089fec1691c22544b605f94185216dde0465e68b9d57c20dacbc49ca9cccf179b645991664b39d77ef317c71b845b1e30bd509112041d3a19783"},
+		{passphrase: "Password", salt: "NaCl", iterations: 80000, keyLen: 64, expected: "4ddcd8f60b98be21830cee5ef22701f9641a4418d04c0414aeff08876b34ab56a1d425a1225833549adb841b51c9b3176a272bdebba1d078478f62b397f33c8d"},
+		{passphrase: "ngsi", salt: "go", iterations: 3, keyLen: 32, expected: "87db1fce8f092ce4cc7a1b815bfe9b35568a2698d6446acfa292e45d64092709"},
+	}
+
+	for _, c := range cases {
+		actual := pbkdf2SHA256([]byte(c.passphrase), []byte(c.salt), c.iterations, c.keyLen)
+		assert.Equal(t, c.expected, hex.EncodeToString(actual))
+	}
+}
+
+func TestSecretsConfig(t *testing.T) {
+	ngsi := testSecretsInit("fiware")
+
+	assert.Equal(t, true, ngsi.SecretsConfig() == nil)
+
+	config := testPassphraseSecrets(t, ngsi)
+
+	assert.Equal(t, config, ngsi.SecretsConfig())
+	assert.Equal(t, CSecretsPassphrase, config.Backend)
+	assert.Equal(t, 10, config.Iterations)
+}
+
+func TestInitSecrets(t *testing.T) {
+	ngsi := testSecretsInit("")
+	ngsi.ServerList = ServerList{"orion": &Server{Password: "helper:orion/password"}}
+
+	err := ngsi.initSecrets(&SecretsConfig{Backend: CSecretsHelper, Helper: "ngsi-helper"})
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, CSecretsHelper, ngsi.SecretsConfig().Backend)
+		assert.Equal(t, "helper:orion/password", ngsi.loadedSecrets["orion/password"])
+		assert.Equal(t, 1, len(ngsi.loadedSecrets))
+	}
+}
+
+func TestInitSecretsNil(t *testing.T) {
+	ngsi := testSecretsInit("")
+
+	err := ngsi.initSecrets(nil)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, true, ngsi.secrets == nil)
+	}
+}
+
+func TestInitSecretsErrorBackend(t *testing.T) {
+	ngsi := testSecretsInit("")
+
+	err := ngsi.initSecrets(&SecretsConfig{Backend: "vault"})
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 1, ngsiErr.ErrNo)
+		assert.Equal(t, "unknown secrets backend: vault", ngsiErr.Message)
+	}
+}
+
+func TestInitSecretsErrorNewBackend(t *testing.T) {
+	ngsi := testSecretsInit("")
+
+	err := ngsi.initSecrets(&SecretsConfig{Backend: CSecretsPassphrase})
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 2, ngsiErr.ErrNo)
+		assert.Equal(t, "salt, iterations and check are required", ngsiErr.Message)
+	}
+}
+
+func TestOpenServerSecrets(t *testing.T) {
+	ngsi := testSecretsInit("fiware")
+	testPassphraseSecrets(t, ngsi)
+
+	password, _ := ngsi.sealSecret("orion/password", "1234")
+	secret, _ := ngsi.sealSecret("orion/clientSecret", "secret")
+	ngsi.openedSecrets = map[string]string{}
+	server := &Server{Username: "fiware", Password: password, ClientSecret: secret, HeaderValue: "plain"}
+
+	err := ngsi.OpenServerSecrets(server)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, "1234", server.Password)
+		assert.Equal(t, "secret", server.ClientSecret)
+		assert.Equal(t, "plain", server.HeaderValue)
+		assert.Equal(t, "1234", ngsi.openedSecrets[password])
+	}
+}
+
+func TestOpenServerSecretsErrorCopied(t *testing.T) {
+	ngsi := testSecretsInit("fiware")
+	testPassphraseSecrets(t, ngsi)
+
+	password, _ := ngsi.sealSecret("orion/password", "1234")
+	ngsi.loadedSecrets["orion-ld/password"] = password
+	server := &Server{Password: password}
+
+	err := ngsi.OpenServerSecrets(server)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 1, ngsiErr.ErrNo)
+		assert.Equal(t, password, server.Password)
+	}
+}
+
+func TestSealedName(t *testing.T) {
+	ngsi := testSecretsInit("")
+	ngsi.loadedSecrets = map[string]string{"orion/password": "enc:1", "orion/token": "enc:2", "orion-ld/token": "enc:2"}
+
+	assert.Equal(t, "orion/password", ngsi.sealedName("enc:1"))
+	assert.Equal(t, "", ngsi.sealedName("enc:2"))
+	assert.Equal(t, "", ngsi.sealedName("enc:3"))
+}
+
+func TestOpenServerSecretsNoBackend(t *testing.T) {
+	ngsi := testSecretsInit("")
+	server := &Server{Password: "enc:1234"}
+
+	err := ngsi.OpenServerSecrets(server)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, "enc:1234", server.Password)
+	}
+}
+
+func TestOpenServerSecretsError(t *testing.T) {
+	ngsi := testSecretsInit("")
+	_ = ngsi.initSecrets(&SecretsConfig{Backend: CSecretsHelper, Helper: "ngsi-helper"})
+	server := &Server{Token: "helper:orion/token"}
+
+	err := ngsi.OpenServerSecrets(server)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 1, ngsiErr.ErrNo)
+		assert.Equal(t, "ngsi-helper get: not found", ngsiErr.Message)
+	}
+}
+
+func TestOpenAllSecretsErrorServer(t *testing.T) {
+	ngsi := testSecretsInit("")
+	ngsi.ServerList = ServerList{"orion": &Server{Password: "helper:orion/password"}}
+	_ = ngsi.initSecrets(&SecretsConfig{Backend: CSecretsHelper, Helper: "ngsi-helper"})
+
+	err := ngsi.openAllSecrets()
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 1, ngsiErr.ErrNo)
+		assert.Equal(t, "ngsi-helper get: not found in orion", ngsiErr.Message)
+	}
+}
+
+func TestOpenAllSecretsErrorToken(t *testing.T) {
+	ngsi := testSecretsInit("")
+	ngsi.sealedTokens = "enc:tokens"
+
+	err := ngsi.openAllSecrets()
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 2, ngsiErr.ErrNo)
+		assert.Equal(t, "token cache is encrypted, but secrets backend not found", ngsiErr.Message)
+	}
+}
+
+func TestSealServerList(t *testing.T) {
+	ngsi := testSecretsInit("")
+	exec := &MockExecLib{Secrets: map[string]string{"orion/password": "1234", "orion/token": "token"}}
+	ngsi.ExecLib = exec
+	ngsi.ServerList = ServerList{"orion": &Server{Username: "fiware", Password: "helper:orion/password", Token: "helper:orion/token"}}
+	_ = ngsi.initSecrets(&SecretsConfig{Backend: CSecretsHelper, Helper: "ngsi-helper --profile test"})
+	_ = ngsi.OpenServerSecrets(ngsi.ServerList["orion"])
+	ngsi.ServerList["orion"].Token = "new token"
+	ngsi.ServerList["orion"].HeaderValue = "value"
+
+	list, err := ngsi.sealServerList(DefaultProfile, ngsi.ServerList)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, "helper:orion/password", list["orion"].Password)
+		assert.Equal(t, "helper:orion/token", list["orion"].Token)
+		assert.Equal(t, "helper:orion/headerValue", list["orion"].HeaderValue)
+		assert.Equal(t, "fiware", list["orion"].Username)
+		assert.Equal(t, "1234", ngsi.ServerList["orion"].Password)
+		assert.Equal(t, "new token", exec.Secrets["orion/token"])
+		assert.Equal(t, "value", exec.Secrets["orion/headerValue"])
+		expected := [][]string{
+			{"ngsi-helper", "--profile", "test", "get", "orion/password"},
+			{"ngsi-helper", "--profile", "test", "get", "orion/token"},
+			{"ngsi-helper", "--profile", "test", "store", "orion/token"},
+			{"ngsi-helper", "--profile", "test", "store", "orion/headerValue"},
+		}
+		assert.Equal(t, expected, exec.Args)
+	}
+}
+
+func TestSealServerListCopied(t *testing.T) {
+	ngsi := testSecretsInit("fiware")
+	testPassphraseSecrets(t, ngsi)
+	password, _ := ngsi.sealSecret("orion/password", "1234")
+	ngsi.ServerList = ServerList{"orion": &Server{Password: password}, "alias": &Server{ServerHost: "orion", Password: password}}
+
+	list, err := ngsi.sealServerList(DefaultProfile, ngsi.ServerList)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, password, list["orion"].Password)
+		assert.Equal(t, true, list["alias"].Password != password)
+		assert.Equal(t, "alias/password", ngsi.sealedName(list["alias"].Password))
+		value, err := ngsi.secrets.Open("alias/password", list["alias"].Password)
+		if assert.NoError(t, err) {
+			assert.Equal(t, "1234", value)
+		}
+	}
+}
+
+func TestSealServerListErrorCopied(t *testing.T) {
+	ngsi := testSecretsInit("fiware")
+	testPassphraseSecrets(t, ngsi)
+	ngsi.loadedSecrets["orion/password"] = "enc:MTIz"
+	ngsi.ServerList = ServerList{"alias": &Server{Password: "enc:MTIz"}}
+
+	_, err := ngsi.sealServerList(DefaultProfile, ngsi.ServerList)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 1, ngsiErr.ErrNo)
+		ngsiErr = ngsiErr.Unwrap().(*ngsierr.NgsiError)
+		assert.Equal(t, 1, ngsiErr.ErrNo)
+	}
+}
+
+func TestSealServerListNoBackend(t *testing.T) {
+	ngsi := testSecretsInit("")
+	ngsi.ServerList = ServerList{"orion": &Server{Password: "1234"}}
+
+	list, err := ngsi.sealServerList(DefaultProfile, ngsi.ServerList)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, "1234", list["orion"].Password)
+	}
+}
+
+func TestSealServerListError(t *testing.T) {
+	ngsi := testSecretsInit("")
+	ngsi.ExecLib = &MockExecLib{Err: errors.New("exec error")}
+	ngsi.ServerList = ServerList{"orion": &Server{Password: "1234"}}
+	_ = ngsi.initSecrets(&SecretsConfig{Backend: CSecretsHelper, Helper: "ngsi-helper"})
+
+	_, err := ngsi.sealServerList(DefaultProfile, ngsi.ServerList)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 1, ngsiErr.ErrNo)
+		assert.Equal(t, "ngsi-helper store: exec error", ngsiErr.Message)
+	}
+}
+
+func TestOpenTokenList(t *testing.T) {
+	ngsi := testSecretsInit("fiware")
+	testPassphraseSecrets(t, ngsi)
+	list := tokenInfoList{"token1": TokenInfo{Type: CKeyrock, Token: "abc"}}
+	ngsi.sealedTokens, _ = ngsi.sealTokenList(&list)
+
+	err := ngsi.openTokenList()
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, "abc", ngsi.tokenList["token1"].Token)
+		assert.Equal(t, "", ngsi.sealedTokens)
+	}
+}
+
+func TestOpenTokenListErrorOpen(t *testing.T) {
+	ngsi := testSecretsInit("")
+	_ = ngsi.initSecrets(&SecretsConfig{Backend: CSecretsHelper, Helper: "ngsi-helper"})
+	ngsi.sealedTokens = "helper:token-cache"
+
+	err := ngsi.openTokenList()
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 2, ngsiErr.ErrNo)
+		assert.Equal(t, "ngsi-helper get: not found", ngsiErr.Message)
+	}
+}
+
+func TestOpenTokenListErrorJSON(t *testing.T) {
+	ngsi := testSecretsInit("")
+	ngsi.ExecLib = &MockExecLib{Secrets: map[string]string{"token-cache": "{"}}
+	_ = ngsi.initSecrets(&SecretsConfig{Backend: CSecretsHelper, Helper: "ngsi-helper"})
+	ngsi.sealedTokens = "helper:token-cache"
+
+	err := ngsi.openTokenList()
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 3, ngsiErr.ErrNo)
+	}
+}
+
+func TestSealTokenListErrorJSON(t *testing.T) {
+	ngsi := testSecretsInit("")
+	_ = ngsi.initSecrets(&SecretsConfig{Backend: CSecretsHelper, Helper: "ngsi-helper"})
+	SetJSONEncodeErr(ngsi, 0)
+
+	_, err := ngsi.sealTokenList(&tokenInfoList{})
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 1, ngsiErr.ErrNo)
+	}
+}
+
+func TestSealTokenListErrorSeal(t *testing.T) {
+	ngsi := testSecretsInit("")
+	ngsi.ExecLib = &MockExecLib{Err: errors.New("exec error")}
+	_ = ngsi.initSecrets(&SecretsConfig{Backend: CSecretsHelper, Helper: "ngsi-helper"})
+
+	_, err := ngsi.sealTokenList(&tokenInfoList{})
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 2, ngsiErr.ErrNo)
+		assert.Equal(t, "ngsi-helper store: exec error", ngsiErr.Message)
+	}
+}
+
+func TestEncryptSecretsPassphrase(t *testing.T) {
+	ngsi := testSecretsInit("fiware")
+	filename := "config"
+	configFile := &MockIoLib{filename: &filename, Env: "fiware"}
+	ngsi.ConfigFile = configFile
+	cacheFile := &MockIoLib{filename: &filename}
+	ngsi.CacheFile = cacheFile
+	ngsi.ServerList = ServerList{"orion": &Server{Password: "1234"}}
+	ngsi.tokenList = tokenInfoList{"token1": TokenInfo{Token: "abc"}}
+
+	err := ngsi.EncryptSecrets("")
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, CSecretsPassphrase, ngsi.SecretsConfig().Backend)
+		assert.Equal(t, false, strings.Contains(string(configFile.Encoded), "1234"))
+		assert.Equal(t, true, strings.Contains(string(configFile.Encoded), `"secrets":{"backend":"passphrase"`))
+		assert.Equal(t, false, strings.Contains(string(cacheFile.Encoded), "abc"))
+		assert.Equal(t, true, strings.Contains(string(cacheFile.Encoded), `"sealed":"enc:`))
+	}
+}
+
+func TestEncryptSecretsHelper(t *testing.T) {
+	ngsi := testSecretsInit("")
+	exec := &MockExecLib{}
+	ngsi.ExecLib = exec
+	filename := "config"
+	ngsi.ConfigFile = &MockIoLib{filename: &filename}
+	ngsi.ServerList = ServerList{"orion": &Server{ClientSecret: "secret"}}
+
+	err := ngsi.EncryptSecrets("ngsi-helper")
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, CSecretsHelper, ngsi.SecretsConfig().Backend)
+		assert.Equal(t, "ngsi-helper", ngsi.SecretsConfig().Helper)
+		assert.Equal(t, "secret", exec.Secrets["orion/clientSecret"])
+	}
+}
+
+func TestEncryptSecretsErrorEncrypted(t *testing.T) {
+	ngsi := testSecretsInit("fiware")
+	testPassphraseSecrets(t, ngsi)
+
+	err := ngsi.EncryptSecrets("")
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 1, ngsiErr.ErrNo)
+		assert.Equal(t, "secrets already encrypted", ngsiErr.Message)
+	}
+}
+
+func TestEncryptSecretsErrorOpen(t *testing.T) {
+	ngsi := testSecretsInit("fiware")
+	ngsi.sealedTokens = "enc:tokens"
+
+	err := ngsi.EncryptSecrets("")
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 2, ngsiErr.ErrNo)
+		assert.Equal(t, "token cache is encrypted, but secrets backend not found", ngsiErr.Message)
+	}
+}
+
+func TestEncryptSecretsErrorPassphrase(t *testing.T) {
+	ngsi := testSecretsInit("")
+
+	err := ngsi.EncryptSecrets("")
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 3, ngsiErr.ErrNo)
+		assert.Equal(t, "passphrase required. set it to NGSI_GO_PASSPHRASE", ngsiErr.Message)
+	}
+}
+
+func TestEncryptSecretsErrorHelper(t *testing.T) {
+	ngsi := testSecretsInit("")
+
+	err := ngsi.EncryptSecrets(" ")
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 3, ngsiErr.ErrNo)
+		assert.Equal(t, "helper program is required", ngsiErr.Message)
+	}
+}
+
+func TestEncryptSecretsErrorSave(t *testing.T) {
+	ngsi := testSecretsInit("fiware")
+	filename := "config"
+	ngsi.ConfigFile = &MockIoLib{filename: &filename, Env: "fiware", EncodeErr: errors.New("encode error")}
+
+	err := ngsi.EncryptSecrets("")
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 4, ngsiErr.ErrNo)
+		assert.Equal(t, "encode error", ngsiErr.Message)
+	}
+}
+
+func TestDecryptSecrets(t *testing.T) {
+	ngsi := testSecretsInit("fiware")
+	testPassphraseSecrets(t, ngsi)
+	password, _ := ngsi.sealSecret("orion/password", "1234")
+	list := tokenInfoList{"token1": TokenInfo{Token: "abc"}}
+	sealed, _ := ngsi.sealTokenList(&list)
+	filename := "config"
+	configFile := &MockIoLib{filename: &filename, Env: "fiware"}
+	ngsi.ConfigFile = configFile
+	cacheFile := &MockIoLib{filename: &filename}
+	ngsi.CacheFile = cacheFile
+	ngsi.ServerList = ServerList{"orion": &Server{Password: password}}
+	ngsi.sealedTokens = sealed
+
+	err := ngsi.DecryptSecrets()
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, true, ngsi.SecretsConfig() == nil)
+		assert.Equal(t, true, strings.Contains(string(configFile.Encoded), `"password":"1234"`))
+		assert.Equal(t, false, strings.Contains(string(configFile.Encoded), `"secrets"`))
+		assert.Equal(t, true, strings.Contains(string(cacheFile.Encoded), `"token":"abc"`))
+	}
+}
+
+func TestDecryptSecretsErrorNotEncrypted(t *testing.T) {
+	ngsi := testSecretsInit("fiware")
+
+	err := ngsi.DecryptSecrets()
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 1, ngsiErr.ErrNo)
+		assert.Equal(t, "secrets not encrypted", ngsiErr.Message)
+	}
+}
+
+func TestDecryptSecretsErrorOpen(t *testing.T) {
+	ngsi := testSecretsInit("")
+	ngsi.ServerList = ServerList{"orion": &Server{Password: "helper:orion/password"}}
+	_ = ngsi.initSecrets(&SecretsConfig{Backend: CSecretsHelper, Helper: "ngsi-helper"})
+
+	err := ngsi.DecryptSecrets()
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 2, ngsiErr.ErrNo)
+		assert.Equal(t, "ngsi-helper get: not found in orion", ngsiErr.Message)
+	}
+}
+
+func TestDecryptSecretsErrorSave(t *testing.T) {
+	ngsi := testSecretsInit("fiware")
+	testPassphraseSecrets(t, ngsi)
+	filename := "config"
+	ngsi.ConfigFile = &MockIoLib{filename: &filename, EncodeErr: errors.New("encode error")}
+
+	err := ngsi.DecryptSecrets()
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 3, ngsiErr.ErrNo)
+		assert.Equal(t, "encode error", ngsiErr.Message)
+	}
+}
+
+func TestSaveSecretsErrorToken(t *testing.T) {
+	ngsi := testSecretsInit("fiware")
+	filename := "cache"
+	ngsi.CacheFile = &MockIoLib{filename: &filename, EncodeErr: errors.New("encode error")}
+	ngsi.tokenList = tokenInfoList{}
+
+	err := ngsi.saveSecrets()
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 2, ngsiErr.ErrNo)
+		assert.Equal(t, "encode error", ngsiErr.Message)
+	}
+}
+
+func TestPassphraseBackend(t *testing.T) {
+	ngsi := testSecretsInit("fiware")
+	config := testPassphraseSecrets(t, ngsi)
+
+	backend, err := newPassphraseBackend(ngsi, config)
+
+	if assert.NoError(t, err) {
+		sealed, err := ngsi.secrets.Seal("orion/password", "1234")
+		assert.NoError(t, err)
+		assert.Equal(t, true, backend.IsSealed(sealed))
+		assert.Equal(t, false, backend.IsSealed("1234"))
+
+		value, err := backend.Open("orion/password", sealed)
+		if assert.NoError(t, err) {
+			assert.Equal(t, "1234", value)
+		}
+	}
+}
+
+func TestPassphraseBackendErrorName(t *testing.T) {
+	ngsi := testSecretsInit("fiware")
+	testPassphraseSecrets(t, ngsi)
+	sealed, _ := ngsi.secrets.Seal("orion/password", "1234")
+
+	_, err := ngsi.secrets.Open("orion-ld/password", sealed)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 2, ngsiErr.ErrNo)
+		assert.Equal(t, "cipher: message authentication failed", ngsiErr.Message)
+	}
+}
+
+func TestPassphraseBackendErrorWrongPassphrase(t *testing.T) {
+	ngsi := testSecretsInit("fiware")
+	config := testPassphraseSecrets(t, ngsi)
+	sealed, _ := ngsi.secrets.Seal("orion/password", "1234")
+	ngsi.ConfigFile = &MockIoLib{Env: "FIWARE"}
+	backend, _ := newPassphraseBackend(ngsi, config)
+
+	_, err := backend.Open("orion/password", sealed)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 1, ngsiErr.ErrNo)
+		ngsiErr = ngsiErr.Unwrap().(*ngsierr.NgsiError)
+		assert.Equal(t, 4, ngsiErr.ErrNo)
+		assert.Equal(t, "wrong passphrase", ngsiErr.Message)
+	}
+}
+
+func TestPassphraseBackendErrorSalt(t *testing.T) {
+	ngsi := testSecretsInit("fiware")
+	backend, _ := newPassphraseBackend(ngsi, &SecretsConfig{Salt: "@", Iterations: 10, Check: "enc:"})
+
+	_, err := backend.Seal("orion/password", "1234")
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 1, ngsiErr.ErrNo)
+		ngsiErr = ngsiErr.Unwrap().(*ngsierr.NgsiError)
+		assert.Equal(t, 1, ngsiErr.ErrNo)
+	}
+}
+
+func TestPassphraseBackendErrorPassphrase(t *testing.T) {
+	ngsi := testSecretsInit("")
+	backend, _ := newPassphraseBackend(ngsi, &SecretsConfig{Salt: "c2FsdA==", Iterations: 10, Check: "enc:"})
+
+	_, err := backend.Open("orion/password", "enc:")
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 1, ngsiErr.ErrNo)
+		ngsiErr = ngsiErr.Unwrap().(*ngsierr.NgsiError)
+		assert.Equal(t, 2, ngsiErr.ErrNo)
+		assert.Equal(t, "passphrase required. set it to NGSI_GO_PASSPHRASE", ngsiErr.Message)
+	}
+}
+
+func TestPassphraseBackendErrorSeal(t *testing.T) {
+	ngsi := testSecretsInit("fiware")
+	testPassphraseSecrets(t, ngsi)
+	secretsRand = &errReader{}
+	defer func() { secretsRand = rand.Reader }()
+
+	_, err := ngsi.secrets.Seal("orion/password", "1234")
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 2, ngsiErr.ErrNo)
+		assert.Equal(t, "rand error", ngsiErr.Message)
+	}
+}
+
+func TestPassphraseBackendErrorOpen(t *testing.T) {
+	ngsi := testSecretsInit("fiware")
+	testPassphraseSecrets(t, ngsi)
+
+	_, err := ngsi.secrets.Open("orion/password", "enc:"+base64.StdEncoding.EncodeToString([]byte("0123456789abcdef")))
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 2, ngsiErr.ErrNo)
+		assert.Equal(t, "cipher: message authentication failed", ngsiErr.Message)
+	}
+}
+
+func TestNewPassphraseSecretsErrorRand(t *testing.T) {
+	ngsi := testSecretsInit("fiware")
+	secretsRand = &errReader{}
+	defer func() { secretsRand = rand.Reader }()
+
+	_, _, err := newPassphraseSecrets(ngsi)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 2, ngsiErr.ErrNo)
+		assert.Equal(t, "rand error", ngsiErr.Message)
+	}
+}
+
+func TestOpenAEADError(t *testing.T) {
+	aead, _ := newSecretsAEAD([]byte("fiware"), []byte("salt"), 1)
+
+	cases := []struct {
+		sealed  string
+		errno   int
+		message string
+	}{
+		{sealed: "enc:@", errno: 1, message: "illegal base64 data at input byte 0"},
+		{sealed: "enc:MTIz", errno: 2, message: "sealed value too short"},
+	}
+
+	for _, c := range cases {
+		_, err := openAEAD(aead, "orion/password", c.sealed)
+
+		if assert.Error(t, err) {
+			ngsiErr := err.(*ngsierr.NgsiError)
+			assert.Equal(t, c.errno, ngsiErr.ErrNo)
+			assert.Equal(t, c.message, ngsiErr.Message)
+		}
+	}
+}
+
+func TestReadPassphrasePrompt(t *testing.T) {
+	ngsi := testSecretsInit("")
+	term := &MockTermLib{Terminal: true}
+	ngsi.TermLib = term
+	ngsi.StdReader = strings.NewReader("fiwarX\x7fe\rxyz\x15fiware\n")
+	stderr := &strings.Builder{}
+	ngsi.Stderr = stderr
+
+	passphrase, err := ngsi.readPassphrase("New passphrase: ", true)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, "fiware", passphrase)
+		assert.Equal(t, "New passphrase: \nRetype passphrase: \n", stderr.String())
+		assert.Equal(t, false, term.Raw)
+	}
+}
+
+func TestReadPassphraseEOF(t *testing.T) {
+	ngsi := testSecretsInit("")
+	ngsi.TermLib = &MockTermLib{Terminal: true}
+	ngsi.StdReader = strings.NewReader("fiware")
+	ngsi.Stderr = &strings.Builder{}
+
+	passphrase, err := ngsi.readPassphrase("Passphrase: ", false)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, "fiware", passphrase)
+	}
+}
+
+func TestReadPassphraseError(t *testing.T) {
+	cases := []struct {
+		input   string
+		rawErr  error
+		errno   int
+		message string
+	}{
+		{input: "fiware\r", rawErr: errors.New("raw error"), errno: 2, message: "raw error"},
+		{input: "\r", errno: 3, message: "passphrase is empty"},
+		{input: "fiware\r\x03", errno: 4, message: "canceled"},
+		{input: "fiware\rFIWARE\r", errno: 5, message: "passphrases do not match"},
+	}
+
+	for _, c := range cases {
+		ngsi := testSecretsInit("")
+		ngsi.TermLib = &MockTermLib{Terminal: true, RawErr: c.rawErr}
+		ngsi.StdReader = strings.NewReader(c.input)
+		ngsi.Stderr = &strings.Builder{}
+
+		_, err := ngsi.readPassphrase("New passphrase: ", true)
+
+		if assert.Error(t, err) {
+			ngsiErr := err.(*ngsierr.NgsiError)
+			assert.Equal(t, c.errno, ngsiErr.ErrNo)
+			assert.Equal(t, c.message, ngsiErr.Message)
+		}
+	}
+}
+
+func TestPromptPassphraseErrorRead(t *testing.T) {
+	ngsi := testSecretsInit("")
+	ngsi.TermLib = &MockTermLib{Terminal: true}
+	ngsi.StdReader = &errReader{}
+	ngsi.Stderr = &strings.Builder{}
+
+	_, err := ngsi.promptPassphrase("Passphrase: ")
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 2, ngsiErr.ErrNo)
+		assert.Equal(t, "rand error", ngsiErr.Message)
+	}
+}
+
+func TestHelperBackend(t *testing.T) {
+	ngsi := testSecretsInit("")
+	exec := &MockExecLib{}
+	ngsi.ExecLib = exec
+
+	backend, err := newHelperBackend(ngsi, &SecretsConfig{Helper: "ngsi-helper"})
+
+	if assert.NoError(t, err) {
+		sealed, err := backend.Seal("orion/password", "1234")
+		if assert.NoError(t, err) {
+			assert.Equal(t, "helper:orion/password", sealed)
+			assert.Equal(t, true, backend.IsSealed(sealed))
+			assert.Equal(t, false, backend.IsSealed("enc:1234"))
+		}
+
+		value, err := backend.Open("orion/password", sealed)
+		if assert.NoError(t, err) {
+			assert.Equal(t, "1234", value)
+		}
+		assert.Equal(t, [][]string{{"ngsi-helper", "store", "orion/password"}, {"ngsi-helper", "get", "orion/password"}}, exec.Args)
+	}
+}
+
+func TestHelperBackendErrorSeal(t *testing.T) {
+	ngsi := testSecretsInit("")
+	ngsi.ExecLib = &MockExecLib{Err: errors.New("exec error")}
+	backend, _ := newHelperBackend(ngsi, &SecretsConfig{Helper: "ngsi-helper"})
+
+	_, err := backend.Seal("orion/password", "1234")
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 1, ngsiErr.ErrNo)
+		assert.Equal(t, "ngsi-helper store: exec error", ngsiErr.Message)
+	}
+}
+
+func TestHelperBackendErrorOpen(t *testing.T) {
+	ngsi := testSecretsInit("")
+	backend, _ := newHelperBackend(ngsi, &SecretsConfig{Helper: "ngsi-helper"})
+
+	_, err := backend.Open("orion/password", "helper:orion/password")
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 1, ngsiErr.ErrNo)
+		assert.Equal(t, "ngsi-helper get: not found", ngsiErr.Message)
+	}
+}
@@ -60,6 +60,14 @@ type Server struct {
 	Scope                string `json:"scope,omitempty"`
 	SafeString           string `json:"safeString,omitempty"`
 	XAuthToken           string `json:"xAuthToken,omitempty"`
+	RetryMax             string `json:"retryMax,omitempty"`
+	RetryBackoff         string `json:"retryBackoff,omitempty"`
+	RetryOn              string `json:"retryOn,omitempty"`
+	CACert               string `json:"caCert,omitempty"`
+	ClientCert           string `json:"clientCert,omitempty"`
+	ClientKey            string `json:"clientKey,omitempty"`
+	Proxy                string `json:"proxy,omitempty"`
+	NoProxy              string `json:"noProxy,omitempty"`
 }
 
 const (
@@ -85,6 +93,14 @@ const (
 	cFiwareServicePath = "path"
 	cSafeString        = "safeString"
 	cXAuthToken        = "xAuthToken"
+	cRetryMax          = "retryMax"
+	cRetryBackoff      = "retryBackoff"
+	cRetryOn           = "retryOn"
+	cCACert            = "caCert"
+	cClientCert        = "clientCert"
+	cClientKey         = "clientKey"
+	cProxy             = "proxy"
+	cNoProxy           = "noProxy"
 )
 
 const (
@@ -118,7 +134,8 @@ const (
 var (
 	brokerArgs = []string{cServerType, cServerHost, cBrokerHost, cBrokerType, cNgsiType, cAPIPath,
 		cIdmType, cIdmHost, cToken, cUsername, cPassword, cClientID, cClientSecret, cHeaderName, cHeaderValue, cHeaderEnvValue, cTokenScope,
-		cContext, cFiwareService, cFiwareServicePath, cSafeString, cXAuthToken}
+		cContext, cFiwareService, cFiwareServicePath, cSafeString, cXAuthToken, cRetryMax, cRetryBackoff, cRetryOn,
+		cCACert, cClientCert, cClientKey, cProxy, cNoProxy}
 	brokerTypeArgs = []string{cOrionLD, cScorpio, cStellio}
 	serverTypeArgs = []string{cComet, cCygnus, cQuantumLeap, cIota, cfiwareKeyrock, cPerseo, cPerseoCore, cWireCloud, cRegProxy, cTokenProxy, cQueryProxy}
 
@@ -210,6 +227,18 @@ func (ngsi *NGSI) checkAllParams(host *Server) error {
 		return ngsierr.New(funcName, 10, err.Error(), err)
 	}
 
+	if err := NewRetryPolicy().SetParams(host.RetryMax, host.RetryBackoff, host.RetryOn); err != nil {
+		return ngsierr.New(funcName, 11, err.Error(), err)
+	}
+
+	if err := checkTLSParams(host); err != nil {
+		return ngsierr.New(funcName, 12, err.Error(), err)
+	}
+
+	if err := checkProxyParams(host); err != nil {
+		return ngsierr.New(funcName, 13, err.Error(), err)
+	}
+
 	return nil
 }
 
@@ -319,6 +348,30 @@ func copyServerInfo(from *Server, to *Server) {
 	if from.XAuthToken != "" && to.XAuthToken == "" {
 		to.XAuthToken = from.XAuthToken
 	}
+	if from.RetryMax != "" && to.RetryMax == "" {
+		to.RetryMax = from.RetryMax
+	}
+	if from.RetryBackoff != "" && to.RetryBackoff == "" {
+		to.RetryBackoff = from.RetryBackoff
+	}
+	if from.RetryOn != "" && to.RetryOn == "" {
+		to.RetryOn = from.RetryOn
+	}
+	if from.CACert != "" && to.CACert == "" {
+		to.CACert = from.CACert
+	}
+	if from.ClientCert != "" && to.ClientCert == "" {
+		to.ClientCert = from.ClientCert
+	}
+	if from.ClientKey != "" && to.ClientKey == "" {
+		to.ClientKey = from.ClientKey
+	}
+	if from.Proxy != "" && to.Proxy == "" {
+		to.Proxy = from.Proxy
+	}
+	if from.NoProxy != "" && to.NoProxy == "" {
+		to.NoProxy = from.NoProxy
+	}
 }
 func setServerParam(broker *Server, param map[string]string) error {
 	const funcName = "setServerParam"
@@ -371,6 +424,22 @@ func setServerParam(broker *Server, param map[string]string) error {
 			broker.SafeString = value
 		case cXAuthToken:
 			broker.XAuthToken = value
+		case cRetryMax:
+			broker.RetryMax = value
+		case cRetryBackoff:
+			broker.RetryBackoff = value
+		case cRetryOn:
+			broker.RetryOn = value
+		case cCACert:
+			broker.CACert = value
+		case cClientCert:
+			broker.ClientCert = value
+		case cClientKey:
+			broker.ClientKey = value
+		case cProxy:
+			broker.Proxy = value
+		case cNoProxy:
+			broker.NoProxy = value
 		}
 	}
 	return nil
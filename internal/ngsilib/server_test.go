@@ -308,6 +308,75 @@ func TestCheckAllParamsErrorSafeString(t *testing.T) {
 	}
 }
 
+func TestCheckAllParamsErrorRetry(t *testing.T) {
+	ngsi := testNgsiLibInit()
+	fileName := ""
+	ngsi.ConfigFile = &MockIoLib{filename: &fileName}
+
+	InitServerList()
+
+	param := make(map[string]string)
+	param["brokerHost"] = "http://orion"
+	err := ngsi.CreateServer("orion", param)
+	assert.NoError(t, err)
+
+	host := ngsi.ServerList["orion"]
+	host.RetryOn = "503,abc"
+	err = ngsi.checkAllParams(host)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 11, ngsiErr.ErrNo)
+		assert.Equal(t, "retryOn error: abc", ngsiErr.Message)
+	}
+}
+
+func TestCheckAllParamsErrorTLS(t *testing.T) {
+	ngsi := testNgsiLibInit()
+	fileName := ""
+	ngsi.ConfigFile = &MockIoLib{filename: &fileName}
+
+	InitServerList()
+
+	param := make(map[string]string)
+	param["brokerHost"] = "http://orion"
+	err := ngsi.CreateServer("orion", param)
+	assert.NoError(t, err)
+
+	host := ngsi.ServerList["orion"]
+	host.ClientCert = "/tmp/cert.pem"
+	err = ngsi.checkAllParams(host)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 12, ngsiErr.ErrNo)
+		assert.Equal(t, "clientCert and clientKey must be specified together", ngsiErr.Message)
+	}
+}
+
+func TestCheckAllParamsErrorProxy(t *testing.T) {
+	ngsi := testNgsiLibInit()
+	fileName := ""
+	ngsi.ConfigFile = &MockIoLib{filename: &fileName}
+
+	InitServerList()
+
+	param := make(map[string]string)
+	param["brokerHost"] = "http://orion"
+	err := ngsi.CreateServer("orion", param)
+	assert.NoError(t, err)
+
+	host := ngsi.ServerList["orion"]
+	host.Proxy = "ftp://proxy"
+	err = ngsi.checkAllParams(host)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 13, ngsiErr.ErrNo)
+		assert.Equal(t, "proxy error: ftp://proxy (http, https, socks5)", ngsiErr.Message)
+	}
+}
+
 func TestGetAPIPath(t *testing.T) {
 	b, a, err := getAPIPath("/,/api")
 
@@ -435,6 +504,14 @@ func TestCopyBrokerInfo(t *testing.T) {
 	param[cHeaderEnvValue] = "TOKEN"
 	param[cSafeString] = "off"
 	param[cXAuthToken] = "on"
+	param[cRetryMax] = "3"
+	param[cRetryBackoff] = "500ms"
+	param[cRetryOn] = "502,503"
+	param[cCACert] = "/tmp/ca.pem"
+	param[cClientCert] = "/tmp/cert.pem"
+	param[cClientKey] = "/tmp/key.pem"
+	param[cProxy] = "http://proxy:8080"
+	param[cNoProxy] = "localhost"
 	_ = setServerParam(&broker, param)
 
 	broker2 := Server{}
@@ -493,6 +570,14 @@ func TestSetBrokerParam(t *testing.T) {
 	param[cFiwareServicePath] = "/iot"
 	param[cSafeString] = "off"
 	param[cXAuthToken] = "on"
+	param[cRetryMax] = "3"
+	param[cRetryBackoff] = "500ms"
+	param[cRetryOn] = "502,503"
+	param[cCACert] = "/tmp/ca.pem"
+	param[cClientCert] = "/tmp/cert.pem"
+	param[cClientKey] = "/tmp/key.pem"
+	param[cProxy] = "http://proxy:8080"
+	param[cNoProxy] = "localhost"
 	err := setServerParam(&broker, param)
 
 	assert.NoError(t, err)
@@ -0,0 +1,271 @@
+/*
+MIT License
+
+Copyright (c) 2020-2024 Kazuhito Suda
+
+This file is part of NGSI Go
+
+https://github.com/lets-fiware/ngsi-go
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+
+*/
+
+package ngsilib
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/lets-fiware/ngsi-go/internal/ngsierr"
+)
+
+// TableFormats is a list of formats supported by TableWriter
+var TableFormats = []string{"csv", "tsv", "table"}
+
+// TableWriter writes rows as CSV, TSV or columns aligned for terminals
+type TableWriter struct {
+	format string
+	csv    *csv.Writer
+	tab    *tabwriter.Writer
+	w      io.Writer
+}
+
+// NewTableWriter returns a TableWriter writing rows to w in format
+func NewTableWriter(w io.Writer, format string) (*TableWriter, error) {
+	const funcName = "NewTableWriter"
+
+	format = strings.ToLower(format)
+	t := &TableWriter{format: format, w: w}
+
+	switch format {
+	case "csv":
+		t.csv = csv.NewWriter(w)
+	case "tsv":
+	case "table":
+		t.tab = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	default:
+		return nil, ngsierr.New(funcName, 1, fmt.Sprintf("unknown output format: %s (%s)", format, strings.Join(TableFormats, ", ")), nil)
+	}
+	return t, nil
+}
+
+// Write writes a row
+func (t *TableWriter) Write(row []string) error {
+	const funcName = "Write"
+
+	var err error
+
+	switch t.format {
+	case "csv":
+		err = t.csv.Write(row)
+	case "tsv":
+		_, err = fmt.Fprintln(t.w, tableJoin(row))
+	case "table":
+		_, err = fmt.Fprintln(t.tab, tableJoin(row))
+	}
+	if err != nil {
+		return ngsierr.New(funcName, 1, err.Error(), err)
+	}
+	return nil
+}
+
+// Flush writes any buffered rows. The columns of the table format are aligned when flushed.
+func (t *TableWriter) Flush() error {
+	const funcName = "Flush"
+
+	var err error
+
+	switch t.format {
+	case "csv":
+		t.csv.Flush()
+		err = t.csv.Error()
+	case "table":
+		err = t.tab.Flush()
+	}
+	if err != nil {
+		return ngsierr.New(funcName, 1, err.Error(), err)
+	}
+	return nil
+}
+
+func tableJoin(row []string) string {
+	r := strings.NewReplacer("\t", " ", "\r", " ", "\n", " ")
+	s := make([]string, len(row))
+	for i, v := range row {
+		s[i] = r.Replace(v)
+	}
+	return strings.Join(s, "\t")
+}
+
+// PrintTable writes a header row and a row for each of items. The columns default to the keys of items.
+func PrintTable(w io.Writer, format string, items []interface{}, columns []string) error {
+	const funcName = "PrintTable"
+
+	t, err := NewTableWriter(w, format)
+	if err != nil {
+		return ngsierr.New(funcName, 1, err.Error(), err)
+	}
+
+	if len(columns) == 0 {
+		columns = TableColumns(items, "id")
+	}
+	if err := t.Write(columns); err != nil {
+		return ngsierr.New(funcName, 2, err.Error(), err)
+	}
+	for _, item := range items {
+		row := make([]string, len(columns))
+		for i, column := range columns {
+			row[i] = TableCell(item, column)
+		}
+		if err := t.Write(row); err != nil {
+			return ngsierr.New(funcName, 3, err.Error(), err)
+		}
+	}
+	if err := t.Flush(); err != nil {
+		return ngsierr.New(funcName, 4, err.Error(), err)
+	}
+	return nil
+}
+
+// PrintJSONTable writes items of an array found at key of a JSON object in body as a table.
+// An object found at key is written as a row, and an item that is not an object is used as
+// the value of the first column.
+func PrintJSONTable(w io.Writer, format string, body []byte, key string, columns []string) error {
+	const funcName = "PrintJSONTable"
+
+	var res map[string]interface{}
+	if err := JSONUnmarshal(body, &res); err != nil {
+		return ngsierr.New(funcName, 1, err.Error(), err)
+	}
+
+	var items []interface{}
+	switch v := res[key].(type) {
+	case []interface{}:
+		items = v
+	case map[string]interface{}:
+		items = []interface{}{v}
+	}
+
+	for i, item := range items {
+		if _, ok := item.(map[string]interface{}); !ok && len(columns) > 0 {
+			items[i] = map[string]interface{}{columns[0]: item}
+		}
+	}
+
+	if err := PrintTable(w, format, items, columns); err != nil {
+		return ngsierr.New(funcName, 2, err.Error(), err)
+	}
+	return nil
+}
+
+// TableColumns returns sorted keys found in items. The keys in first come before the others.
+func TableColumns(items []interface{}, first ...string) []string {
+	keys := map[string]bool{}
+	for _, item := range items {
+		if m, ok := item.(map[string]interface{}); ok {
+			for k := range m {
+				keys[k] = true
+			}
+		}
+	}
+
+	columns := []string{}
+	for _, k := range first {
+		if keys[k] {
+			columns = append(columns, k)
+			delete(keys, k)
+		}
+	}
+	others := []string{}
+	for k := range keys {
+		others = append(others, k)
+	}
+	sort.Strings(others)
+
+	return append(columns, others...)
+}
+
+// TableCell returns a value of v specified by a dotted path as a string.
+// Each element of the path is looked up as a member, a metadata or a member of the value
+// of an attribute. The value of an attribute is used when the path ends at the attribute.
+func TableCell(v interface{}, path string) string {
+	if path != "" {
+		for _, key := range strings.Split(path, ".") {
+			m, ok := v.(map[string]interface{})
+			if !ok {
+				return ""
+			}
+			if e, ok := m[key]; ok {
+				v = e
+			} else if e, ok := tableMember(m["metadata"], key); ok {
+				v = e
+			} else if e, ok := tableMember(m["value"], key); ok {
+				v = e
+			} else {
+				return ""
+			}
+		}
+		v = tableAttrValue(v)
+	}
+
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
+
+func tableMember(v interface{}, key string) (interface{}, bool) {
+	if m, ok := v.(map[string]interface{}); ok {
+		e, ok := m[key]
+		return e, ok
+	}
+	return nil, false
+}
+
+func tableAttrValue(v interface{}) interface{} {
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return v
+	}
+	if _, ok := m["type"]; !ok {
+		return v
+	}
+	for _, key := range []string{"value", "object", "languageMap"} {
+		if e, ok := m[key]; ok {
+			return e
+		}
+	}
+	return v
+}
@@ -0,0 +1,331 @@
+/*
+MIT License
+
+Copyright (c) 2020-2024 Kazuhito Suda
+
+This file is part of NGSI Go
+
+https://github.com/lets-fiware/ngsi-go
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+
+*/
+
+package ngsilib
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/lets-fiware/ngsi-go/internal/assert"
+	"github.com/lets-fiware/ngsi-go/internal/ngsierr"
+)
+
+type errTableWriter struct{}
+
+func (w *errTableWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("write error")
+}
+
+func TestNewTableWriter(t *testing.T) {
+	for _, format := range []string{"csv", "tsv", "table"} {
+		actual, err := NewTableWriter(new(bytes.Buffer), format)
+
+		if assert.NoError(t, err) {
+			assert.Equal(t, format, actual.format)
+		}
+	}
+}
+
+func TestNewTableWriterUpperCase(t *testing.T) {
+	actual, err := NewTableWriter(new(bytes.Buffer), "CSV")
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, "csv", actual.format)
+	}
+}
+
+func TestNewTableWriterError(t *testing.T) {
+	_, err := NewTableWriter(new(bytes.Buffer), "xml")
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 1, ngsiErr.ErrNo)
+		assert.Equal(t, "unknown output format: xml (csv, tsv, table)", ngsiErr.Message)
+	}
+}
+
+func TestTableWriterCSV(t *testing.T) {
+	buf := new(bytes.Buffer)
+	w, _ := NewTableWriter(buf, "csv")
+
+	_ = w.Write([]string{"id", "name"})
+	_ = w.Write([]string{"urn:ngsi-ld:Room:001", "Room 1, \"east\""})
+	err := w.Flush()
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, "id,name\nurn:ngsi-ld:Room:001,\"Room 1, \"\"east\"\"\"\n", buf.String())
+	}
+}
+
+func TestTableWriterTSV(t *testing.T) {
+	buf := new(bytes.Buffer)
+	w, _ := NewTableWriter(buf, "tsv")
+
+	_ = w.Write([]string{"id", "name"})
+	_ = w.Write([]string{"Room1", "a\tb\nc"})
+	err := w.Flush()
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, "id\tname\nRoom1\ta b c\n", buf.String())
+	}
+}
+
+func TestTableWriterTable(t *testing.T) {
+	buf := new(bytes.Buffer)
+	w, _ := NewTableWriter(buf, "table")
+
+	_ = w.Write([]string{"id", "temperature"})
+	_ = w.Write([]string{"urn:ngsi-ld:Room:001", "21.5"})
+	err := w.Flush()
+
+	if assert.NoError(t, err) {
+		expected := "id                    temperature\n" +
+			"urn:ngsi-ld:Room:001  21.5\n"
+		assert.Equal(t, expected, buf.String())
+	}
+}
+
+func TestTableWriterErrorWrite(t *testing.T) {
+	w, _ := NewTableWriter(&errTableWriter{}, "tsv")
+
+	err := w.Write([]string{"id"})
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 1, ngsiErr.ErrNo)
+		assert.Equal(t, "write error", ngsiErr.Message)
+	}
+}
+
+func TestTableWriterErrorFlush(t *testing.T) {
+	w, _ := NewTableWriter(&errTableWriter{}, "csv")
+
+	_ = w.Write([]string{"id"})
+	err := w.Flush()
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 1, ngsiErr.ErrNo)
+		assert.Equal(t, "write error", ngsiErr.Message)
+	}
+}
+
+func TestPrintTable(t *testing.T) {
+	buf := new(bytes.Buffer)
+	items := []interface{}{
+		map[string]interface{}{"name": "Alice", "id": "1", "admin": true},
+		map[string]interface{}{"id": "2", "email": "bob@example.com"},
+	}
+
+	err := PrintTable(buf, "csv", items, nil)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, "id,admin,email,name\n1,true,,Alice\n2,,bob@example.com,\n", buf.String())
+	}
+}
+
+func TestPrintTableColumns(t *testing.T) {
+	buf := new(bytes.Buffer)
+	items := []interface{}{
+		map[string]interface{}{"name": "Alice", "id": "1"},
+	}
+
+	err := PrintTable(buf, "tsv", items, []string{"name"})
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, "name\nAlice\n", buf.String())
+	}
+}
+
+func TestPrintTableErrorFormat(t *testing.T) {
+	err := PrintTable(new(bytes.Buffer), "xml", nil, nil)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 1, ngsiErr.ErrNo)
+		assert.Equal(t, "unknown output format: xml (csv, tsv, table)", ngsiErr.Message)
+	}
+}
+
+func TestPrintTableErrorHeader(t *testing.T) {
+	err := PrintTable(&errTableWriter{}, "tsv", nil, []string{"id"})
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 2, ngsiErr.ErrNo)
+		assert.Equal(t, "write error", ngsiErr.Message)
+	}
+}
+
+func TestPrintTableErrorFlush(t *testing.T) {
+	items := []interface{}{map[string]interface{}{"id": "1"}}
+
+	err := PrintTable(&errTableWriter{}, "csv", items, nil)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 4, ngsiErr.ErrNo)
+		assert.Equal(t, "write error", ngsiErr.Message)
+	}
+}
+
+func TestPrintJSONTable(t *testing.T) {
+	_ = testNgsiLibInit()
+
+	buf := new(bytes.Buffer)
+	body := []byte(`{"users":[{"id":"1","username":"alice"},{"id":"2","username":"bob"}]}`)
+
+	err := PrintJSONTable(buf, "csv", body, "users", []string{"id", "username"})
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, "id,username\n1,alice\n2,bob\n", buf.String())
+	}
+}
+
+func TestPrintJSONTableObject(t *testing.T) {
+	_ = testNgsiLibInit()
+
+	buf := new(bytes.Buffer)
+	body := []byte(`{"pep_proxy":{"id":"pep1","oauth_client_id":"app1"}}`)
+
+	err := PrintJSONTable(buf, "csv", body, "pep_proxy", nil)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, "id,oauth_client_id\npep1,app1\n", buf.String())
+	}
+}
+
+func TestPrintJSONTableString(t *testing.T) {
+	_ = testNgsiLibInit()
+
+	buf := new(bytes.Buffer)
+	body := []byte(`{"trusted_applications":["app1","app2"]}`)
+
+	err := PrintJSONTable(buf, "csv", body, "trusted_applications", []string{"id"})
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, "id\napp1\napp2\n", buf.String())
+	}
+}
+
+func TestPrintJSONTableErrorJSON(t *testing.T) {
+	_ = testNgsiLibInit()
+
+	err := PrintJSONTable(new(bytes.Buffer), "csv", []byte(`{`), "users", nil)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 1, ngsiErr.ErrNo)
+	}
+}
+
+func TestPrintJSONTableErrorFormat(t *testing.T) {
+	_ = testNgsiLibInit()
+
+	err := PrintJSONTable(new(bytes.Buffer), "xml", []byte(`{}`), "users", nil)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 2, ngsiErr.ErrNo)
+		assert.Equal(t, "unknown output format: xml (csv, tsv, table)", ngsiErr.Message)
+	}
+}
+
+func TestTableColumns(t *testing.T) {
+	items := []interface{}{
+		map[string]interface{}{"type": "Room", "temperature": 1, "id": "r1"},
+		map[string]interface{}{"humidity": 2},
+		"string",
+	}
+
+	actual := TableColumns(items, "id", "type", "location")
+
+	assert.Equal(t, []string{"id", "type", "humidity", "temperature"}, actual)
+}
+
+func TestTableCell(t *testing.T) {
+	entity := map[string]interface{}{
+		"id":   "urn:ngsi-ld:Room:001",
+		"type": "Room",
+		"temperature": map[string]interface{}{
+			"type":     "Number",
+			"value":    21.5,
+			"metadata": map[string]interface{}{"unitCode": map[string]interface{}{"type": "Text", "value": "CEL"}},
+		},
+		"humidity": map[string]interface{}{"type": "Property", "value": 1000000.0, "observedAt": "2021-01-01T00:00:00Z"},
+		"owner":    map[string]interface{}{"type": "Relationship", "object": "urn:ngsi-ld:Person:001"},
+		"address":  map[string]interface{}{"type": "StructuredValue", "value": map[string]interface{}{"city": "Tokyo"}},
+		"location": map[string]interface{}{"type": "Point", "coordinates": []interface{}{139.7, 35.6}},
+		"name":     "Room 1",
+		"open":     true,
+		"note":     nil,
+	}
+
+	cases := []struct {
+		path     string
+		expected string
+	}{
+		{path: "id", expected: "urn:ngsi-ld:Room:001"},
+		{path: "type", expected: "Room"},
+		{path: "temperature", expected: "21.5"},
+		{path: "temperature.type", expected: "Number"},
+		{path: "temperature.unitCode", expected: "CEL"},
+		{path: "humidity", expected: "1000000"},
+		{path: "humidity.observedAt", expected: "2021-01-01T00:00:00Z"},
+		{path: "owner", expected: "urn:ngsi-ld:Person:001"},
+		{path: "address", expected: `{"city":"Tokyo"}`},
+		{path: "address.city", expected: "Tokyo"},
+		{path: "location", expected: `{"coordinates":[139.7,35.6],"type":"Point"}`},
+		{path: "name", expected: "Room 1"},
+		{path: "name.first", expected: ""},
+		{path: "open", expected: "true"},
+		{path: "note", expected: ""},
+		{path: "pressure", expected: ""},
+	}
+
+	for _, c := range cases {
+		assert.Equal(t, c.expected, TableCell(entity, c.path), c.path)
+	}
+}
+
+func TestTableCellValue(t *testing.T) {
+	assert.Equal(t, "abc", TableCell("abc", ""))
+	assert.Equal(t, "[1,2]", TableCell([]interface{}{1.0, 2.0}, ""))
+}
+
+func TestTableCellErrorMarshal(t *testing.T) {
+	ch := make(chan int)
+
+	actual := TableCell(ch, "")
+
+	assert.Equal(t, fmt.Sprint(ch), actual)
+}
@@ -0,0 +1,232 @@
+/*
+MIT License
+
+Copyright (c) 2020-2024 Kazuhito Suda
+
+This file is part of NGSI Go
+
+https://github.com/lets-fiware/ngsi-go
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+
+*/
+
+package ngsilib
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/template"
+	"text/template/parse"
+	"time"
+
+	"github.com/lets-fiware/ngsi-go/internal/ngsierr"
+)
+
+// NewTemplate parses a text/template used by --template
+func NewTemplate(text string) (*template.Template, error) {
+	const funcName = "NewTemplate"
+
+	t, err := template.New("template").Funcs(templateFuncs()).Parse(text)
+	if err != nil {
+		return nil, ngsierr.New(funcName, 1, err.Error(), err)
+	}
+
+	for _, tmpl := range t.Templates() {
+		if tmpl.Tree != nil {
+			templateNoValue(tmpl.Tree.Root)
+		}
+	}
+
+	return t, nil
+}
+
+// templateNoValue appends "| noValue" to the actions printing a value, so that null and missing
+// members are rendered as an empty string instead of <no value>.
+func templateNoValue(node parse.Node) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, e := range n.Nodes {
+			templateNoValue(e)
+		}
+	case *parse.ActionNode:
+		if len(n.Pipe.Decl) == 0 {
+			n.Pipe.Cmds = append(n.Pipe.Cmds, &parse.CommandNode{
+				NodeType: parse.NodeCommand,
+				Pos:      n.Pos,
+				Args:     []parse.Node{parse.NewIdentifier("noValue").SetPos(n.Pos)},
+			})
+		}
+	case *parse.IfNode:
+		templateNoValue(n.List)
+		templateNoValue(n.ElseList)
+	case *parse.RangeNode:
+		templateNoValue(n.List)
+		templateNoValue(n.ElseList)
+	case *parse.WithNode:
+		templateNoValue(n.List)
+		templateNoValue(n.ElseList)
+	}
+}
+
+func templateNoValueFunc(v interface{}) interface{} {
+	if v == nil {
+		return ""
+	}
+	return v
+}
+
+func templateFuncs() template.FuncMap {
+	return template.FuncMap{
+		"attr":       TableCell,
+		"dateTime":   GetDateTime,
+		"default":    templateDefault,
+		"formatTime": templateFormatTime,
+		"join":       templateJoin,
+		"json":       templateJSON,
+		"lower":      strings.ToLower,
+		"noValue":    templateNoValueFunc,
+		"unixTime":   templateUnixTime,
+		"upper":      strings.ToUpper,
+	}
+}
+
+// templateItems returns the items rendered by a template. The elements of an array are rendered
+// one by one, as are those of a list object such as {"count": 2, "devices": [...]}.
+func templateItems(v interface{}) []interface{} {
+	switch t := v.(type) {
+	case []interface{}:
+		return t
+	case map[string]interface{}:
+		var items []interface{}
+		for k, e := range t {
+			if k == "count" {
+				continue
+			}
+			a, ok := e.([]interface{})
+			if !ok || items != nil {
+				return []interface{}{v}
+			}
+			items = a
+		}
+		if items != nil {
+			return items
+		}
+	}
+	return []interface{}{v}
+}
+
+// templateExecute renders v with t
+func templateExecute(t *template.Template, v interface{}) (string, error) {
+	const funcName = "templateExecute"
+
+	var sb strings.Builder
+	err := t.Execute(&sb, v)
+	if err != nil {
+		return "", ngsierr.New(funcName, 1, err.Error(), err)
+	}
+
+	return sb.String(), nil
+}
+
+func templateString(v interface{}) string {
+	switch t := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return t
+	case json.Number:
+		return t.String()
+	case float64:
+		return fmt.Sprint(t)
+	case bool:
+		return fmt.Sprint(t)
+	}
+	b, _ := json.Marshal(v)
+	return string(b)
+}
+
+func templateDefault(def, v interface{}) interface{} {
+	if v == nil || v == "" {
+		return def
+	}
+	return v
+}
+
+func templateJoin(sep string, v interface{}) string {
+	switch t := v.(type) {
+	case []interface{}:
+		s := make([]string, len(t))
+		for i, e := range t {
+			s[i] = templateString(e)
+		}
+		return strings.Join(s, sep)
+	case []string:
+		return strings.Join(t, sep)
+	}
+	return templateString(v)
+}
+
+func templateJSON(v interface{}) (string, error) {
+	const funcName = "templateJSON"
+
+	b, err := JSONMarshal(v)
+	if err != nil {
+		return "", ngsierr.New(funcName, 1, err.Error(), err)
+	}
+	return string(b), nil
+}
+
+func templateFormatTime(layout string, v interface{}) (string, error) {
+	const funcName = "templateFormatTime"
+
+	s := templateString(v)
+	t, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		return "", ngsierr.New(funcName, 1, "not a date time: "+s, err)
+	}
+	return t.Format(layout), nil
+}
+
+func templateUnixTime(v interface{}) (string, error) {
+	const funcName = "templateUnixTime"
+
+	var ms int64
+	switch t := v.(type) {
+	case json.Number:
+		f, err := t.Float64()
+		if err != nil {
+			return "", ngsierr.New(funcName, 1, "not a number: "+t.String(), err)
+		}
+		ms = int64(f)
+	case float64:
+		ms = int64(t)
+	case int:
+		ms = int64(t)
+	case int64:
+		ms = t
+	default:
+		return "", ngsierr.New(funcName, 2, "not a number: "+templateString(v), nil)
+	}
+	return GetTime(gNGSI, ms), nil
+}
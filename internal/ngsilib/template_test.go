@@ -0,0 +1,231 @@
+/*
+MIT License
+
+Copyright (c) 2020-2024 Kazuhito Suda
+
+This file is part of NGSI Go
+
+https://github.com/lets-fiware/ngsi-go
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+
+*/
+
+package ngsilib
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/lets-fiware/ngsi-go/internal/assert"
+	"github.com/lets-fiware/ngsi-go/internal/ngsierr"
+)
+
+func TestNewTemplate(t *testing.T) {
+	testNgsiLibInit()
+
+	tmpl, err := NewTemplate(`{{.id}} {{attr . "temperature"}} {{.tags | join ","}} {{.none}}`)
+
+	if assert.NoError(t, err) {
+		v := map[string]interface{}{
+			"id":          "urn:ngsi-ld:Device:001",
+			"temperature": map[string]interface{}{"type": "Number", "value": json.Number("21.5")},
+			"tags":        []interface{}{"a", json.Number("1"), true},
+		}
+		actual, err := templateExecute(tmpl, v)
+		if assert.NoError(t, err) {
+			assert.Equal(t, "urn:ngsi-ld:Device:001 21.5 a,1,true ", actual)
+		}
+	}
+}
+
+func TestNewTemplateError(t *testing.T) {
+	testNgsiLibInit()
+
+	_, err := NewTemplate("{{.id")
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 1, ngsiErr.ErrNo)
+		assert.Equal(t, "template: template:1: unclosed action", ngsiErr.Message)
+	}
+}
+
+func TestTemplateItems(t *testing.T) {
+	cases := []struct {
+		v        interface{}
+		expected []interface{}
+	}{
+		{v: []interface{}{"a", "b"}, expected: []interface{}{"a", "b"}},
+		{v: map[string]interface{}{"count": 2.0, "devices": []interface{}{"a", "b"}}, expected: []interface{}{"a", "b"}},
+		{v: map[string]interface{}{"users": []interface{}{"a"}}, expected: []interface{}{"a"}},
+		{v: map[string]interface{}{"a": []interface{}{"a"}, "b": []interface{}{"b"}}, expected: []interface{}{map[string]interface{}{"a": []interface{}{"a"}, "b": []interface{}{"b"}}}},
+		{v: map[string]interface{}{"id": "a", "tags": []interface{}{"a"}}, expected: []interface{}{map[string]interface{}{"id": "a", "tags": []interface{}{"a"}}}},
+		{v: map[string]interface{}{"count": 0.0}, expected: []interface{}{map[string]interface{}{"count": 0.0}}},
+		{v: "a", expected: []interface{}{"a"}},
+	}
+
+	for _, c := range cases {
+		assert.Equal(t, c.expected, templateItems(c.v))
+	}
+}
+
+func TestTemplateExecuteNoValue(t *testing.T) {
+	testNgsiLibInit()
+
+	cases := []struct {
+		text     string
+		expected string
+	}{
+		{text: `[{{.id}}] [{{.none}}] [{{.null}}] [{{.none.value}}]`, expected: `[a] [] [] []`},
+		{text: `{{.text}}`, expected: `<no value>`},
+		{text: `{{default "n/a" .none}} {{.none | default "-"}}`, expected: `n/a -`},
+		{text: `{{if .id}}{{.none}}x{{else}}{{.null}}y{{end}}`, expected: `x`},
+		{text: `{{range .list}}[{{.}}]{{else}}{{.none}}{{end}}`, expected: `[1][][<no value>]`},
+		{text: `{{with .none}}{{.}}{{else}}[{{.null}}]{{end}}`, expected: `[]`},
+		{text: `{{$v := .none}}[{{$v}}]`, expected: `[]`},
+		{text: `{{define "t"}}[{{.none}}]{{end}}{{template "t" .}}`, expected: `[]`},
+		{text: `{{printf "%v" .none}}`, expected: `<nil>`},
+	}
+
+	for _, c := range cases {
+		tmpl, err := NewTemplate(c.text)
+		if assert.NoError(t, err, c.text) {
+			v := map[string]interface{}{"id": "a", "null": nil, "text": "<no value>", "list": []interface{}{1, nil, "<no value>"}}
+			actual, err := templateExecute(tmpl, v)
+			if assert.NoError(t, err, c.text) {
+				assert.Equal(t, c.expected, actual, c.text)
+			}
+		}
+	}
+}
+
+func TestTemplateExecuteError(t *testing.T) {
+	testNgsiLibInit()
+
+	tmpl, _ := NewTemplate(`{{formatTime "2006" .}}`)
+
+	_, err := templateExecute(tmpl, "abc")
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 1, ngsiErr.ErrNo)
+		assert.Equal(t, `template: template:1:2: executing "template" at <formatTime "2006" .>: error calling formatTime: not a date time: abc`, ngsiErr.Message)
+	}
+}
+
+func TestTemplateString(t *testing.T) {
+	assert.Equal(t, "", templateString(nil))
+	assert.Equal(t, "s", templateString("s"))
+	assert.Equal(t, "1.50", templateString(json.Number("1.50")))
+	assert.Equal(t, "1.5", templateString(1.5))
+	assert.Equal(t, "false", templateString(false))
+	assert.Equal(t, `{"a":1}`, templateString(map[string]interface{}{"a": 1}))
+}
+
+func TestTemplateDefault(t *testing.T) {
+	assert.Equal(t, "n/a", templateDefault("n/a", nil))
+	assert.Equal(t, "n/a", templateDefault("n/a", ""))
+	assert.Equal(t, "a", templateDefault("n/a", "a"))
+}
+
+func TestTemplateJoin(t *testing.T) {
+	assert.Equal(t, "a,b", templateJoin(",", []interface{}{"a", "b"}))
+	assert.Equal(t, "a b", templateJoin(" ", []string{"a", "b"}))
+	assert.Equal(t, "a", templateJoin(",", "a"))
+}
+
+func TestTemplateJSON(t *testing.T) {
+	testNgsiLibInit()
+
+	actual, err := templateJSON(map[string]interface{}{"a": "<b>"})
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, `{"a":"<b>"}`, actual)
+	}
+}
+
+func TestTemplateJSONError(t *testing.T) {
+	ngsi := testNgsiLibInit()
+	ngsi.JSONConverter = &MockJSONLib{EncodeErr: [5]error{errors.New("json error")}}
+
+	_, err := templateJSON("a")
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 1, ngsiErr.ErrNo)
+		assert.Equal(t, "json error", ngsiErr.Message)
+	}
+}
+
+func TestTemplateFormatTime(t *testing.T) {
+	actual, err := templateFormatTime("2006/01/02 15:04", "2026-01-02T03:04:05.000Z")
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, "2026/01/02 03:04", actual)
+	}
+}
+
+func TestTemplateFormatTimeError(t *testing.T) {
+	_, err := templateFormatTime("2006", json.Number("1"))
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 1, ngsiErr.ErrNo)
+		assert.Equal(t, "not a date time: 1", ngsiErr.Message)
+	}
+}
+
+func TestTemplateUnixTime(t *testing.T) {
+	ngsi := testNgsiLibInit()
+	format := "2023/11/14 22:13:20"
+	ngsi.TimeLib = &MockTimeLib{format: &format}
+
+	for _, v := range []interface{}{json.Number("1700000000000"), 1700000000000.0, 1700000000000, int64(1700000000000)} {
+		actual, err := templateUnixTime(v)
+		if assert.NoError(t, err) {
+			assert.Equal(t, "2023/11/14 22:13:20", actual)
+		}
+	}
+}
+
+func TestTemplateUnixTimeErrorNumber(t *testing.T) {
+	testNgsiLibInit()
+
+	_, err := templateUnixTime(json.Number("x"))
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 1, ngsiErr.ErrNo)
+		assert.Equal(t, "not a number: x", ngsiErr.Message)
+	}
+}
+
+func TestTemplateUnixTimeErrorType(t *testing.T) {
+	testNgsiLibInit()
+
+	_, err := templateUnixTime("x")
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 2, ngsiErr.ErrNo)
+		assert.Equal(t, "not a number: x", ngsiErr.Message)
+	}
+}
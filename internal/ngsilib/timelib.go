@@ -42,6 +42,7 @@ type TimeLib interface {
 	NowUnix() int64
 	Unix(sec int64, nsec int64) time.Time
 	Format(layout string) string
+	Sleep(d time.Duration)
 }
 
 type timeLib struct {
@@ -65,6 +66,10 @@ func (t *timeLib) Format(layout string) string {
 	return t.TTime.Format(layout)
 }
 
+func (t *timeLib) Sleep(d time.Duration) {
+	time.Sleep(d)
+}
+
 // GetDateTime
 func GetDateTime(dateTime string) (string, error) {
 	const funcName = "getDateTime"
@@ -31,6 +31,7 @@ package ngsilib
 
 import (
 	"testing"
+	"time"
 
 	"github.com/lets-fiware/ngsi-go/internal/assert"
 	"github.com/lets-fiware/ngsi-go/internal/ngsierr"
@@ -61,6 +62,15 @@ func TestTimeLibFormat(t *testing.T) {
 	_ = time.Format("2021/06/27 06:47:39")
 }
 
+func TestTimeLibSleep(t *testing.T) {
+	tl := &timeLib{}
+	start := time.Now()
+
+	tl.Sleep(10 * time.Millisecond)
+
+	assert.Equal(t, true, time.Since(start) >= 10*time.Millisecond)
+}
+
 func TestGetDateTimeISO8601(t *testing.T) {
 
 	actual, err := GetDateTime("2022-09-24T12:07:54.035Z")
@@ -0,0 +1,90 @@
+/*
+MIT License
+
+Copyright (c) 2020-2024 Kazuhito Suda
+
+This file is part of NGSI Go
+
+https://github.com/lets-fiware/ngsi-go
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+
+*/
+
+package ngsilib
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+
+	"github.com/lets-fiware/ngsi-go/internal/ngsierr"
+)
+
+func (ngsi *NGSI) tlsConfig(server *Server) (*tls.Config, error) {
+	const funcName = "tlsConfig"
+
+	config := &tls.Config{InsecureSkipVerify: ngsi.InsecureSkipVerify}
+
+	if server == nil {
+		return config, nil
+	}
+
+	if server.CACert != "" {
+		b, err := ngsi.FileReader.ReadFile(server.CACert)
+		if err != nil {
+			return nil, ngsierr.New(funcName, 1, err.Error(), err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(b) {
+			return nil, ngsierr.New(funcName, 2, fmt.Sprintf("no certificate found in %s", server.CACert), nil)
+		}
+		config.RootCAs = pool
+	}
+
+	if server.ClientCert != "" || server.ClientKey != "" {
+		cert, err := ngsi.FileReader.ReadFile(server.ClientCert)
+		if err != nil {
+			return nil, ngsierr.New(funcName, 3, err.Error(), err)
+		}
+		key, err := ngsi.FileReader.ReadFile(server.ClientKey)
+		if err != nil {
+			return nil, ngsierr.New(funcName, 4, err.Error(), err)
+		}
+		pair, err := tls.X509KeyPair(cert, key)
+		if err != nil {
+			return nil, ngsierr.New(funcName, 5, err.Error(), err)
+		}
+		config.Certificates = []tls.Certificate{pair}
+	}
+
+	return config, nil
+}
+
+func checkTLSParams(server *Server) error {
+	const funcName = "checkTLSParams"
+
+	if (server.ClientCert == "") != (server.ClientKey == "") {
+		return ngsierr.New(funcName, 1, "clientCert and clientKey must be specified together", nil)
+	}
+	return nil
+}
@@ -0,0 +1,217 @@
+/*
+MIT License
+
+Copyright (c) 2020-2024 Kazuhito Suda
+
+This file is part of NGSI Go
+
+https://github.com/lets-fiware/ngsi-go
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+
+*/
+
+package ngsilib
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/lets-fiware/ngsi-go/internal/assert"
+	"github.com/lets-fiware/ngsi-go/internal/ngsierr"
+)
+
+func TestTLSConfig(t *testing.T) {
+	ngsi := testNgsiLibInit()
+	ngsi.InsecureSkipVerify = true
+
+	actual, err := ngsi.tlsConfig(nil)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, true, actual.InsecureSkipVerify)
+		assert.Equal(t, (*x509.CertPool)(nil), actual.RootCAs)
+	}
+}
+
+func TestTLSConfigCertificates(t *testing.T) {
+	ngsi := testNgsiLibInit()
+	ngsi.FileReader = &fileLib{}
+	server := testTLSFiles(t)
+
+	actual, err := ngsi.tlsConfig(server)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, false, actual.RootCAs == nil)
+		assert.Equal(t, 1, len(actual.Certificates))
+	}
+}
+
+func TestTLSConfigMutualTLS(t *testing.T) {
+	ngsi := testNgsiLibInit()
+	ngsi.FileReader = &fileLib{}
+	server := testTLSFiles(t)
+
+	caPEM, _ := os.ReadFile(server.CACert)
+	pool := x509.NewCertPool()
+	pool.AppendCertsFromPEM(caPEM)
+	serverCert, _ := tls.LoadX509KeyPair(server.ClientCert, server.ClientKey)
+
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	ts.TLS = &tls.Config{
+		Certificates: []tls.Certificate{serverCert},
+		ClientCAs:    pool,
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+	}
+	ts.StartTLS()
+	defer ts.Close()
+
+	u, _ := url.Parse(ts.URL)
+
+	res, _, err := ngsi.serverHTTP(server).Request("GET", u, nil, nil)
+	if assert.NoError(t, err) {
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+	}
+
+	_, _, err = ngsi.serverHTTP(&Server{CACert: server.CACert}).Request("GET", u, nil, nil)
+	assert.Error(t, err)
+}
+
+func TestTLSConfigErrorCACert(t *testing.T) {
+	ngsi := testNgsiLibInit()
+	ngsi.FileReader = &MockFileLib{ReadFileError: [5]error{errors.New("read error")}}
+
+	_, err := ngsi.tlsConfig(&Server{CACert: "ca.pem"})
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 1, ngsiErr.ErrNo)
+		assert.Equal(t, "read error", ngsiErr.Message)
+	}
+}
+
+func TestTLSConfigErrorNoCertificate(t *testing.T) {
+	ngsi := testNgsiLibInit()
+	ngsi.FileReader = &MockFileLib{ReadFileData: []byte("no pem")}
+
+	_, err := ngsi.tlsConfig(&Server{CACert: "ca.pem"})
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 2, ngsiErr.ErrNo)
+		assert.Equal(t, "no certificate found in ca.pem", ngsiErr.Message)
+	}
+}
+
+func TestTLSConfigErrorClientCert(t *testing.T) {
+	ngsi := testNgsiLibInit()
+	ngsi.FileReader = &MockFileLib{ReadFileError: [5]error{errors.New("read error")}}
+
+	_, err := ngsi.tlsConfig(&Server{ClientCert: "cert.pem", ClientKey: "key.pem"})
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 3, ngsiErr.ErrNo)
+		assert.Equal(t, "read error", ngsiErr.Message)
+	}
+}
+
+func TestTLSConfigErrorClientKey(t *testing.T) {
+	ngsi := testNgsiLibInit()
+	ngsi.FileReader = &MockFileLib{ReadFileError: [5]error{nil, errors.New("read error")}}
+
+	_, err := ngsi.tlsConfig(&Server{ClientCert: "cert.pem", ClientKey: "key.pem"})
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 4, ngsiErr.ErrNo)
+		assert.Equal(t, "read error", ngsiErr.Message)
+	}
+}
+
+func TestTLSConfigErrorKeyPair(t *testing.T) {
+	ngsi := testNgsiLibInit()
+	ngsi.FileReader = &MockFileLib{ReadFileData: []byte("no pem")}
+
+	_, err := ngsi.tlsConfig(&Server{ClientCert: "cert.pem", ClientKey: "key.pem"})
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 5, ngsiErr.ErrNo)
+		assert.Equal(t, "tls: failed to find any PEM data in certificate input", ngsiErr.Message)
+	}
+}
+
+func TestCheckTLSParams(t *testing.T) {
+	assert.NoError(t, checkTLSParams(&Server{}))
+	assert.NoError(t, checkTLSParams(&Server{ClientCert: "cert.pem", ClientKey: "key.pem"}))
+}
+
+func TestCheckTLSParamsError(t *testing.T) {
+	err := checkTLSParams(&Server{ClientCert: "cert.pem"})
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 1, ngsiErr.ErrNo)
+		assert.Equal(t, "clientCert and clientKey must be specified together", ngsiErr.Message)
+	}
+}
+
+// testTLSFiles creates a self-signed certificate which is used as CA, server and client certificate.
+func testTLSFiles(t *testing.T) *Server {
+	dir := t.TempDir()
+
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "ngsi-go test"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	der, _ := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	keyDer, _ := x509.MarshalECPrivateKey(key)
+
+	certFile := filepath.Join(dir, "cert.pem")
+	keyFile := filepath.Join(dir, "key.pem")
+	_ = os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600)
+	_ = os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0600)
+
+	return &Server{CACert: certFile, ClientCert: certFile, ClientKey: keyFile}
+}
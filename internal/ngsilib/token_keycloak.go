@@ -86,7 +86,7 @@ func (i *idmKeycloak) requestToken(ngsi *NGSI, client *Client, tokenInfo *TokenI
 		headers := make(map[string]string)
 		u, _ := url.Parse(client.idmURL())
 		u.Path = path.Join(u.Path, keyCloakToken)
-		idm := Client{URL: u, Headers: headers, HTTP: ngsi.HTTP}
+		idm := Client{URL: u, Headers: headers, HTTP: ngsi.serverHTTP(client.Server)}
 
 		idm.SetHeader(cContentType, cAppXWwwFormUrlencoded)
 
@@ -132,7 +132,7 @@ func (i *idmKeycloak) revokeToken(ngsi *NGSI, client *Client, tokenInfo *TokenIn
 
 	u, _ := url.Parse(client.idmURL())
 	u.Path = path.Join(u.Path, keyCLoakRevoke)
-	idm := Client{URL: u, Headers: headers, HTTP: ngsi.HTTP}
+	idm := Client{URL: u, Headers: headers, HTTP: ngsi.serverHTTP(client.Server)}
 
 	broker := client.Server
 	idm.SetHeader(cContentType, cAppXWwwFormUrlencoded)
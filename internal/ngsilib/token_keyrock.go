@@ -73,7 +73,7 @@ func (i *idmKeyrock) requestToken(ngsi *NGSI, client *Client, tokenInfo *TokenIn
 
 		headers := make(map[string]string)
 		u, _ := url.Parse(client.idmURL())
-		idm := Client{URL: u, Headers: headers, HTTP: ngsi.HTTP}
+		idm := Client{URL: u, Headers: headers, HTTP: ngsi.serverHTTP(client.Server)}
 		broker := client.Server
 
 		idm.SetHeader(cContentType, cAppXWwwFormUrlencoded)
@@ -121,7 +121,7 @@ func (i *idmKeyrock) revokeToken(ngsi *NGSI, client *Client, tokenInfo *TokenInf
 	headers := make(map[string]string)
 
 	u, _ := url.Parse(strings.Replace(client.idmURL(), "/token", "/revoke", 1))
-	idm := Client{URL: u, Headers: headers, HTTP: ngsi.HTTP}
+	idm := Client{URL: u, Headers: headers, HTTP: ngsi.serverHTTP(client.Server)}
 	broker := client.Server
 
 	idm.SetHeader(cContentType, cAppXWwwFormUrlencoded)
@@ -58,7 +58,7 @@ func (i *idmKeyrockIDM) requestToken(ngsi *NGSI, client *Client, tokenInfo *Toke
 
 	headers := make(map[string]string)
 	u, _ := url.Parse(client.idmURL())
-	idm := Client{URL: u, Headers: headers, HTTP: ngsi.HTTP}
+	idm := Client{URL: u, Headers: headers, HTTP: ngsi.serverHTTP(client.Server)}
 
 	username, password, err := getUserNamePassword(client)
 	if err != nil {
@@ -99,7 +99,7 @@ func (i *idmKeyrockIDM) revokeToken(ngsi *NGSI, client *Client, tokenInfo *Token
 	headers := make(map[string]string)
 
 	u, _ := url.Parse(client.idmURL())
-	idm := Client{URL: u, Headers: headers, HTTP: ngsi.HTTP}
+	idm := Client{URL: u, Headers: headers, HTTP: ngsi.serverHTTP(client.Server)}
 
 	idm.SetHeader("X-Auth-token", tokenInfo.Token)
 	idm.SetHeader("X-Subject-token", tokenInfo.Token)
@@ -53,7 +53,7 @@ func (i *idmKeyrockTokenProvider) requestToken(ngsi *NGSI, client *Client, token
 
 	headers := make(map[string]string)
 	u, _ := url.Parse(client.idmURL())
-	idm := Client{URL: u, Headers: headers, HTTP: ngsi.HTTP}
+	idm := Client{URL: u, Headers: headers, HTTP: ngsi.serverHTTP(client.Server)}
 
 	username, password, err := getUserNamePassword(client)
 	if err != nil {
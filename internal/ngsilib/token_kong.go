@@ -70,7 +70,7 @@ func (i *idmKong) requestToken(ngsi *NGSI, client *Client, tokenInfo *TokenInfo)
 
 		headers := make(map[string]string)
 		u, _ := url.Parse(getKongHost(client.idmURL(), cKongService))
-		idm := Client{URL: u, Headers: headers, HTTP: ngsi.HTTP}
+		idm := Client{URL: u, Headers: headers, HTTP: ngsi.serverHTTP(client.Server)}
 
 		idm.SetHeader(cContentType, cAppXWwwFormUrlencoded)
 
@@ -115,7 +115,7 @@ func (i *idmKong) revokeToken(ngsi *NGSI, client *Client, tokenInfo *TokenInfo)
 
 	u, _ := url.Parse(getKongHost(client.idmURL(), cKongIdm))
 	u.Path = path.Join(u.Path, "/oauth2_tokens/", tokenInfo.Token)
-	idm := Client{URL: u, Headers: headers, HTTP: ngsi.HTTP}
+	idm := Client{URL: u, Headers: headers, HTTP: ngsi.serverHTTP(client.Server)}
 
 	res, body, err := idm.HTTPDelete(nil)
 	if err != nil {
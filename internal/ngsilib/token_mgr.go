@@ -55,6 +55,7 @@ type TokenInfo struct {
 	Keycloak      *KeycloakToken   `json:"keycloak,omitempty"`
 	WSO2          *WSO2Token       `json:"wso2,omitempty"`
 	Kong          *KongToken       `json:"kong,omitempty"`
+	OAuth2        *OAuth2Token     `json:"oauth2,omitempty"`
 }
 
 type tokenInfoList map[string]TokenInfo
@@ -62,6 +63,7 @@ type tokenInfoList map[string]TokenInfo
 type tokens struct {
 	Version string        `json:"version"`
 	Tokens  tokenInfoList `json:"tokens"`
+	Sealed  string        `json:"sealed,omitempty"`
 }
 
 // IdmParam is ...
@@ -96,36 +98,43 @@ const (
 )
 
 const (
-	CPasswordCredentials  = "password"
-	CKeyrock              = "keyrock"
-	CKeyrocktokenprovider = "keyrocktokenprovider"
-	CTokenproxy           = "tokenproxy"
-	CKeyrockIDM           = "idm"
-	CThinkingCities       = "thinkingcities"
-	CBasic                = "basic"
-	CKeycloak             = "keycloak"
-	CWSO2                 = "wso2"
-	CKong                 = "kong"
-	CApikey               = "apikey"
+	CPasswordCredentials     = "password"
+	CKeyrock                 = "keyrock"
+	CKeyrocktokenprovider    = "keyrocktokenprovider"
+	CTokenproxy              = "tokenproxy"
+	CKeyrockIDM              = "idm"
+	CThinkingCities          = "thinkingcities"
+	CBasic                   = "basic"
+	CKeycloak                = "keycloak"
+	CWSO2                    = "wso2"
+	CKong                    = "kong"
+	CApikey                  = "apikey"
+	COAuth2ClientCredentials = "oauth2-client-credentials"
+	COAuth2DeviceCode        = "oauth2-device-code"
+	COIDC                    = "oidc"
 )
 
 var idmTypes = []string{
 	CPasswordCredentials, CKeyrock, CKeyrocktokenprovider, CTokenproxy, CKeyrockIDM,
 	CThinkingCities, CBasic, CKeycloak, CWSO2, CKong, CApikey,
+	COAuth2ClientCredentials, COAuth2DeviceCode, COIDC,
 }
 
 var tokenPlugins = map[string]TokenPlugin{
-	CKeyrock:              &idmKeyrock{},
-	CPasswordCredentials:  &idmPasswordCredentials{},
-	CKeyrocktokenprovider: &idmKeyrockTokenProvider{},
-	CTokenproxy:           &idmTokenProxy{},
-	CKeyrockIDM:           &idmKeyrockIDM{},
-	CThinkingCities:       &idmThinkingCities{},
-	CBasic:                &idmBasic{},
-	CKeycloak:             &idmKeycloak{},
-	CWSO2:                 &idmWSO2{},
-	CKong:                 &idmKong{},
-	CApikey:               &idmApikey{},
+	CKeyrock:                 &idmKeyrock{},
+	CPasswordCredentials:     &idmPasswordCredentials{},
+	CKeyrocktokenprovider:    &idmKeyrockTokenProvider{},
+	CTokenproxy:              &idmTokenProxy{},
+	CKeyrockIDM:              &idmKeyrockIDM{},
+	CThinkingCities:          &idmThinkingCities{},
+	CBasic:                   &idmBasic{},
+	CKeycloak:                &idmKeycloak{},
+	CWSO2:                    &idmWSO2{},
+	CKong:                    &idmKong{},
+	CApikey:                  &idmApikey{},
+	COAuth2ClientCredentials: &idmOAuth2ClientCredentials{},
+	COAuth2DeviceCode:        &idmOAuth2DeviceCode{},
+	COIDC:                    &idmOIDC{},
 }
 
 const cacheFileName = "ngsi-go-token-cache.json"
@@ -173,6 +182,7 @@ func initTokenList(io IoLib) (err error) {
 	}
 
 	gNGSI.tokenList = make(tokenInfoList)
+	gNGSI.sealedTokens = ""
 
 	if existsFile(io, *io.FileName()) {
 		err = io.Open()
@@ -185,7 +195,11 @@ func initTokenList(io IoLib) (err error) {
 		err = io.Decode(&tokens)
 		if err == nil {
 			if tokens.Version == "1" {
-				gNGSI.tokenList = tokens.Tokens
+				if tokens.Sealed != "" {
+					gNGSI.sealedTokens = tokens.Sealed
+				} else {
+					gNGSI.tokenList = tokens.Tokens
+				}
 			}
 		}
 	}
@@ -210,17 +224,28 @@ func (ngsi *NGSI) TokenList() string {
 func (ngsi *NGSI) TokenInfo(client *Client) (*TokenInfo, error) {
 	const funcName = "TokenInfo"
 
+	if err := ngsi.openTokenList(); err != nil {
+		return nil, ngsierr.New(funcName, 1, err.Error(), err)
+	}
+
 	hash := getHash(client)
 	if v, ok := ngsi.tokenList[hash]; ok {
 		return &v, nil
 	}
-	return nil, ngsierr.New(funcName, 1, "not found", nil)
+	return nil, ngsierr.New(funcName, 2, "not found", nil)
 }
 
 // GetToken is ...
 func (ngsi *NGSI) GetToken(client *Client) (string, error) {
 	const funcName = "GetToken"
 
+	ngsi.tokenMutex.Lock()
+	defer ngsi.tokenMutex.Unlock()
+
+	if err := ngsi.openTokenList(); err != nil {
+		return "", ngsierr.New(funcName, 1, err.Error(), err)
+	}
+
 	hash := getHash(client)
 	info, ok := ngsi.tokenList[hash]
 	if ok {
@@ -236,11 +261,46 @@ func (ngsi *NGSI) GetToken(client *Client) (string, error) {
 	}
 	token, err := requestToken(ngsi, client, &info)
 	if err != nil {
+		return "", ngsierr.New(funcName, 2, err.Error(), err)
+	}
+	return token, nil
+}
+
+// RefreshToken requests a new token even if the cached token has not expired yet. The token of
+// the client is the rejected one. If another goroutine has already replaced it, the new token is
+// returned without requesting another one.
+func (ngsi *NGSI) RefreshToken(client *Client) (string, error) {
+	const funcName = "RefreshToken"
+
+	ngsi.tokenMutex.Lock()
+	defer ngsi.tokenMutex.Unlock()
+
+	if err := ngsi.openTokenList(); err != nil {
 		return "", ngsierr.New(funcName, 1, err.Error(), err)
 	}
+
+	info := ngsi.tokenList[getHash(client)]
+
+	if info.Token != "" && info.Token != client.Token && info.Expires.Unix() > ngsi.TimeLib.NowUnix()+gNGSI.Margin {
+		gNGSI.Logging(LogInfo, "Token already refreshed\n")
+		return info.Token, nil
+	}
+
+	token, err := requestToken(ngsi, client, &info)
+	if err != nil {
+		return "", ngsierr.New(funcName, 2, err.Error(), err)
+	}
 	return token, nil
 }
 
+// tokenExpires returns the expiry of the cached token of the client
+func (ngsi *NGSI) tokenExpires(client *Client) time.Time {
+	ngsi.tokenMutex.Lock()
+	defer ngsi.tokenMutex.Unlock()
+
+	return ngsi.tokenList[getHash(client)].Expires
+}
+
 // GetAuthHeader is ...
 func (ngsi *NGSI) GetAuthHeader(client *Client) (string, string, error) {
 	const funcName = "GetAuthHeader"
@@ -266,25 +326,29 @@ func (ngsi *NGSI) GetAuthHeader(client *Client) (string, string, error) {
 func (ngsi *NGSI) RevokeToken(client *Client) error {
 	const funcName = "RevokeToken"
 
+	if err := ngsi.openTokenList(); err != nil {
+		return ngsierr.New(funcName, 1, err.Error(), err)
+	}
+
 	hash := getHash(client)
 
 	if tokenInfo, ok := ngsi.tokenList[hash]; ok {
 		idmType := strings.ToLower(client.Server.IdmType)
 		idm, ok := tokenPlugins[idmType]
 		if !ok {
-			return ngsierr.New(funcName, 1, "unknown idm type: "+idmType, nil)
+			return ngsierr.New(funcName, 2, "unknown idm type: "+idmType, nil)
 		}
 
 		err := idm.revokeToken(ngsi, client, &tokenInfo)
 		if err != nil {
-			fmt.Fprint(ngsi.Stderr, ngsierr.SprintMsg(funcName, 2, err.Error()))
+			fmt.Fprint(ngsi.Stderr, ngsierr.SprintMsg(funcName, 3, err.Error()))
 		}
 
 		delete(ngsi.tokenList, hash)
 
 		err = saveToken(*ngsi.CacheFile.FileName(), &ngsi.tokenList)
 		if err != nil {
-			return ngsierr.New(funcName, 3, err.Error(), err)
+			return ngsierr.New(funcName, 4, err.Error(), err)
 		}
 	}
 
@@ -346,7 +410,7 @@ func appendToken(ngsi *NGSI, hash string, tokenInfo *TokenInfo) *tokenInfoList {
 	utime := ngsi.TimeLib.NowUnix()
 
 	for k, v := range ngsi.tokenList {
-		if v.Expires.Unix() > utime+gNGSI.Margin {
+		if k != hash && v.Expires.Unix() > utime+gNGSI.Margin {
 			newTokenList[k] = v
 		}
 	}
@@ -368,21 +432,30 @@ func saveToken(file string, tokenList *tokenInfoList) error {
 		Tokens:  *tokenList,
 	}
 
+	if gNGSI.secrets != nil {
+		sealed, err := gNGSI.sealTokenList(tokenList)
+		if err != nil {
+			return ngsierr.New(funcName, 1, err.Error(), err)
+		}
+		tokens.Tokens = tokenInfoList{}
+		tokens.Sealed = sealed
+	}
+
 	cacheFile := gNGSI.CacheFile
 
 	err := cacheFile.OpenFile(oWRONLY|oCREATE, 0600)
 	if err != nil {
-		return ngsierr.New(funcName, 1, err.Error()+" "+file, err)
+		return ngsierr.New(funcName, 2, err.Error()+" "+file, err)
 	}
 	defer func() { _ = cacheFile.Close() }()
 
 	if err := cacheFile.Truncate(0); err != nil {
-		return ngsierr.New(funcName, 2, err.Error(), err)
+		return ngsierr.New(funcName, 3, err.Error(), err)
 	}
 
 	err = cacheFile.Encode(tokens)
 	if err != nil {
-		return ngsierr.New(funcName, 3, err.Error(), err)
+		return ngsierr.New(funcName, 4, err.Error(), err)
 	}
 
 	return nil
@@ -393,7 +466,8 @@ func getHash(client *Client) string {
 	if client.Server.IdmType == CThinkingCities {
 		s = s + client.Server.Tenant + client.Server.Scope
 	}
-	if client.Server.IdmType == CKeycloak {
+	switch client.Server.IdmType {
+	case CKeycloak, COAuth2ClientCredentials, COAuth2DeviceCode, COIDC:
 		s = s + client.Server.ClientID + client.Server.ClientSecret
 	}
 	r := sha1.Sum([]byte(s))
@@ -33,6 +33,7 @@ import (
 	"bytes"
 	"errors"
 	"net/http"
+	"strings"
 	"testing"
 	"time"
 
@@ -136,6 +137,21 @@ func TestInitTokenListVersion1(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestInitTokenListSealed(t *testing.T) {
+	ngsi := testNgsiLibInit()
+	tokens := `{"version":"1","tokens":{},"sealed":"helper:token-cache"}`
+	io := &MockIoLib{Data: &tokens}
+	filename := "cache-file"
+	io.SetFileName(&filename)
+
+	err := initTokenList(io)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, "helper:token-cache", ngsi.sealedTokens)
+		assert.Equal(t, 0, len(ngsi.tokenList))
+	}
+}
+
 func TestInitTokenListNoExistsFile(t *testing.T) {
 	testNgsiLibInit()
 	io := &MockIoLib{StatErr: errors.New("stat error")}
@@ -209,11 +225,24 @@ func TestTokenInfoNotFound(t *testing.T) {
 
 	if assert.Error(t, err) {
 		ngsiErr := err.(*ngsierr.NgsiError)
-		assert.Equal(t, 1, ngsiErr.ErrNo)
+		assert.Equal(t, 2, ngsiErr.ErrNo)
 		assert.Equal(t, "not found", ngsiErr.Message)
 	}
 }
 
+func TestTokenInfoErrorOpen(t *testing.T) {
+	ngsi := testNgsiLibInit()
+	ngsi.sealedTokens = "enc:tokens"
+
+	_, err := ngsi.TokenInfo(&Client{Server: &Server{}})
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 1, ngsiErr.ErrNo)
+		assert.Equal(t, "token cache is encrypted, but secrets backend not found", ngsiErr.Message)
+	}
+}
+
 func TestGetToken(t *testing.T) {
 	ngsi := testNgsiLibInit()
 	ngsi.tokenList = tokenInfoList{}
@@ -332,11 +361,24 @@ func TestGetTokenErrorRequestToken(t *testing.T) {
 
 	if assert.Error(t, err) {
 		ngsiErr := err.(*ngsierr.NgsiError)
-		assert.Equal(t, 1, ngsiErr.ErrNo)
+		assert.Equal(t, 2, ngsiErr.ErrNo)
 		assert.Equal(t, "unknown idm type: unknown", ngsiErr.Message)
 	}
 }
 
+func TestGetTokenErrorOpen(t *testing.T) {
+	ngsi := testNgsiLibInit()
+	ngsi.sealedTokens = "enc:tokens"
+
+	_, err := ngsi.GetToken(&Client{Server: &Server{}})
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 1, ngsiErr.ErrNo)
+		assert.Equal(t, "token cache is encrypted, but secrets backend not found", ngsiErr.Message)
+	}
+}
+
 func TestGetAuthHeader(t *testing.T) {
 	ngsi := testNgsiLibInit()
 
@@ -639,6 +681,40 @@ func TestSaveTokenNoFileName(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestSaveTokenSealed(t *testing.T) {
+	ngsi := testNgsiLibInit()
+	cacheFile := &MockIoLib{}
+	ngsi.CacheFile = cacheFile
+	ngsi.LogWriter = &bytes.Buffer{}
+	exec := &MockExecLib{}
+	ngsi.ExecLib = exec
+	_ = ngsi.initSecrets(&SecretsConfig{Backend: CSecretsHelper, Helper: "ngsi-helper"})
+	tokenInfo := &tokenInfoList{"token1": TokenInfo{Token: "abc"}}
+
+	err := saveToken("cache-file", tokenInfo)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, `{"version":"1","tokens":{},"sealed":"helper:token-cache"}`, string(cacheFile.Encoded))
+		assert.Equal(t, true, strings.Contains(exec.Secrets["token-cache"], `"token":"abc"`))
+	}
+}
+
+func TestSaveTokenErrorSeal(t *testing.T) {
+	ngsi := testNgsiLibInit()
+	ngsi.CacheFile = &MockIoLib{}
+	ngsi.LogWriter = &bytes.Buffer{}
+	ngsi.ExecLib = &MockExecLib{Err: errors.New("exec error")}
+	_ = ngsi.initSecrets(&SecretsConfig{Backend: CSecretsHelper, Helper: "ngsi-helper"})
+
+	err := saveToken("cache-file", &tokenInfoList{})
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 1, ngsiErr.ErrNo)
+		assert.Equal(t, "ngsi-helper store: exec error", ngsiErr.Message)
+	}
+}
+
 func TestSaveTokenErrorOpenFile(t *testing.T) {
 	ngsi := testNgsiLibInit()
 	ngsi.CacheFile = &MockIoLib{OpenErr: errors.New("open error")}
@@ -648,7 +724,7 @@ func TestSaveTokenErrorOpenFile(t *testing.T) {
 	err := saveToken("cache-file", tokenInfo)
 	if assert.Error(t, err) {
 		ngsiErr := err.(*ngsierr.NgsiError)
-		assert.Equal(t, 1, ngsiErr.ErrNo)
+		assert.Equal(t, 2, ngsiErr.ErrNo)
 		assert.Equal(t, "open error cache-file", ngsiErr.Message)
 	}
 }
@@ -662,7 +738,7 @@ func TestSaveTokenErrorTruncate(t *testing.T) {
 	err := saveToken("cache-file", tokenInfo)
 	if assert.Error(t, err) {
 		ngsiErr := err.(*ngsierr.NgsiError)
-		assert.Equal(t, 2, ngsiErr.ErrNo)
+		assert.Equal(t, 3, ngsiErr.ErrNo)
 		assert.Equal(t, "truncate error", ngsiErr.Message)
 	}
 }
@@ -676,7 +752,7 @@ func TestSaveTokenErrorEncode(t *testing.T) {
 	err := saveToken("cache-file", tokenInfo)
 	if assert.Error(t, err) {
 		ngsiErr := err.(*ngsierr.NgsiError)
-		assert.Equal(t, 3, ngsiErr.ErrNo)
+		assert.Equal(t, 4, ngsiErr.ErrNo)
 		assert.Equal(t, "encode error", ngsiErr.Message)
 	}
 }
@@ -829,11 +905,24 @@ func TestRevodeTokenErrorIdm(t *testing.T) {
 
 	if assert.Error(t, err) {
 		ngsiErr := err.(*ngsierr.NgsiError)
-		assert.Equal(t, 1, ngsiErr.ErrNo)
+		assert.Equal(t, 2, ngsiErr.ErrNo)
 		assert.Equal(t, "unknown idm type: unknown", ngsiErr.Message)
 	}
 }
 
+func TestRevodeTokenErrorOpen(t *testing.T) {
+	ngsi := testNgsiLibInit()
+	ngsi.sealedTokens = "enc:tokens"
+
+	err := ngsi.RevokeToken(&Client{Server: &Server{}})
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 1, ngsiErr.ErrNo)
+		assert.Equal(t, "token cache is encrypted, but secrets backend not found", ngsiErr.Message)
+	}
+}
+
 func TestRevodeTokenErrorRevokeToken(t *testing.T) {
 	ngsi := testNgsiLibInit()
 	ngsi.tokenList = tokenInfoList{}
@@ -873,7 +962,7 @@ func TestRevodeTokenErrorSaveToken(t *testing.T) {
 
 	if assert.Error(t, err) {
 		ngsiErr := err.(*ngsierr.NgsiError)
-		assert.Equal(t, 3, ngsiErr.ErrNo)
+		assert.Equal(t, 4, ngsiErr.ErrNo)
 		assert.Equal(t, "open error file", ngsiErr.Message)
 	}
 }
@@ -1009,3 +1098,89 @@ func TestCheckIdmParamsErrorParam(t *testing.T) {
 		assert.Equal(t, "idmHost, username, password, clientID and clientSecret are needed", ngsiErr.Message)
 	}
 }
+
+func TestRefreshToken(t *testing.T) {
+	ngsi := testNgsiLibInit()
+	ngsi.tokenList = tokenInfoList{}
+	filename := ""
+	ngsi.CacheFile = &MockIoLib{filename: &filename}
+	ngsi.LogWriter = &bytes.Buffer{}
+	reqRes := MockHTTPReqRes{}
+	reqRes.Res.StatusCode = http.StatusOK
+	reqRes.ResBody = []byte(`{"access_token": "ad5252cd520cnaddacdc5d2e63899f0cdcf946f3", "expires_in": 3599, "refresh_token": "03e33a311e03317b390956729bcac2794b695670", "scope": [ "bearer" ], "token_type": "Bearer" }`)
+	mock := NewMockHTTP()
+	mock.ReqRes = append(mock.ReqRes, reqRes)
+	ngsi.HTTP = mock
+
+	client := &Client{Server: &Server{ServerHost: "http://orion/", IdmType: CTokenproxy, Username: "fiware", Password: "1234"}, Token: "old"}
+	ngsi.tokenList[getHash(client)] = TokenInfo{Token: "old", Expires: time.Unix(9613169598, 0)}
+
+	actual, err := ngsi.RefreshToken(client)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, "ad5252cd520cnaddacdc5d2e63899f0cdcf946f3", actual)
+		assert.Equal(t, "ad5252cd520cnaddacdc5d2e63899f0cdcf946f3", ngsi.tokenList[getHash(client)].Token)
+	}
+}
+
+func TestTokenExpires(t *testing.T) {
+	ngsi := testNgsiLibInit()
+	ngsi.tokenList = tokenInfoList{}
+
+	client := &Client{Server: &Server{ServerHost: "http://orion/", IdmType: CTokenproxy, Username: "fiware", Password: "1234"}}
+	ngsi.tokenList[getHash(client)] = TokenInfo{Token: "token", Expires: time.Unix(9613169598, 0)}
+
+	actual := ngsi.tokenExpires(client)
+
+	assert.Equal(t, int64(9613169598), actual.Unix())
+}
+
+func TestRefreshTokenAlreadyRefreshed(t *testing.T) {
+	ngsi := testNgsiLibInit()
+	ngsi.tokenList = tokenInfoList{}
+	filename := ""
+	ngsi.CacheFile = &MockIoLib{filename: &filename}
+	buf := &bytes.Buffer{}
+	ngsi.LogWriter = buf
+	ngsi.LogLevel = LogInfo
+	ngsi.HTTP = NewMockHTTP()
+
+	client := &Client{Server: &Server{ServerHost: "http://orion/", IdmType: CTokenproxy, Username: "fiware", Password: "1234"}, Token: "old"}
+	ngsi.tokenList[getHash(client)] = TokenInfo{Token: "new", Expires: time.Unix(9613169598, 0)}
+
+	actual, err := ngsi.RefreshToken(client)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, "new", actual)
+		assert.Equal(t, "Token already refreshed\n", buf.String())
+	}
+}
+
+func TestRefreshTokenErrorOpen(t *testing.T) {
+	ngsi := testNgsiLibInit()
+	ngsi.sealedTokens = "enc:tokens"
+
+	_, err := ngsi.RefreshToken(&Client{Server: &Server{}})
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 1, ngsiErr.ErrNo)
+		assert.Equal(t, "token cache is encrypted, but secrets backend not found", ngsiErr.Message)
+	}
+}
+
+func TestRefreshTokenErrorRequestToken(t *testing.T) {
+	ngsi := testNgsiLibInit()
+	ngsi.tokenList = tokenInfoList{}
+	ngsi.LogWriter = &bytes.Buffer{}
+
+	client := &Client{Server: &Server{ServerHost: "http://orion/", IdmType: "unknown"}}
+
+	_, err := ngsi.RefreshToken(client)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 2, ngsiErr.ErrNo)
+		assert.Equal(t, "unknown idm type: unknown", ngsiErr.Message)
+	}
+}
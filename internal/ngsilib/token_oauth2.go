@@ -0,0 +1,208 @@
+/*
+MIT License
+
+Copyright (c) 2020-2024 Kazuhito Suda
+
+This file is part of NGSI Go
+
+https://github.com/lets-fiware/ngsi-go
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+
+*/
+
+package ngsilib
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/lets-fiware/ngsi-go/internal/ngsierr"
+)
+
+// OAuth2Token is ...
+type OAuth2Token struct {
+	AccessToken      string `json:"access_token"`
+	ExpiresIn        int64  `json:"expires_in"`
+	RefreshExpiresIn int64  `json:"refresh_expires_in,omitempty"`
+	RefreshToken     string `json:"refresh_token,omitempty"`
+	TokenType        string `json:"token_type"`
+	IDToken          string `json:"id_token,omitempty"`
+	Scope            string `json:"scope,omitempty"`
+}
+
+type oauth2Error struct {
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+type oidcConfiguration struct {
+	Issuer                      string `json:"issuer"`
+	TokenEndpoint               string `json:"token_endpoint"`
+	AuthorizationEndpoint       string `json:"authorization_endpoint"`
+	RevocationEndpoint          string `json:"revocation_endpoint"`
+	DeviceAuthorizationEndpoint string `json:"device_authorization_endpoint"`
+}
+
+const oidcDiscoveryPath = "/.well-known/openid-configuration"
+
+// oidcDiscovery gets the endpoints of the authorization server. The idmHost is the issuer
+// or the URL of the OpenID Provider Configuration Document itself. The document is cached
+// per issuer for the lifetime of the process.
+func oidcDiscovery(ngsi *NGSI, client *Client) (*oidcConfiguration, error) {
+	const funcName = "oidcDiscovery"
+
+	discoveryURL := client.idmURL()
+	if !strings.HasSuffix(discoveryURL, oidcDiscoveryPath) {
+		discoveryURL = strings.TrimSuffix(discoveryURL, "/") + oidcDiscoveryPath
+	}
+
+	ngsi.oidcMutex.Lock()
+	defer ngsi.oidcMutex.Unlock()
+
+	if config, ok := ngsi.oidcConfigs[discoveryURL]; ok {
+		return config, nil
+	}
+
+	u, err := url.Parse(discoveryURL)
+	if err != nil {
+		return nil, ngsierr.New(funcName, 1, err.Error(), err)
+	}
+	idm := Client{URL: u, Headers: map[string]string{}, HTTP: ngsi.serverHTTP(client.Server)}
+
+	res, body, err := idm.HTTPGet()
+	if err != nil {
+		return nil, ngsierr.New(funcName, 2, err.Error(), err)
+	}
+	if res.StatusCode != http.StatusOK {
+		return nil, ngsierr.New(funcName, 3, fmt.Sprintf("error %s %s", res.Status, string(body)), nil)
+	}
+
+	var config oidcConfiguration
+	if err := JSONUnmarshal(body, &config); err != nil {
+		return nil, ngsierr.New(funcName, 4, err.Error(), err)
+	}
+	if config.TokenEndpoint == "" {
+		return nil, ngsierr.New(funcName, 5, "token_endpoint not found in "+discoveryURL, nil)
+	}
+
+	if ngsi.oidcConfigs == nil {
+		ngsi.oidcConfigs = make(map[string]*oidcConfiguration)
+	}
+	ngsi.oidcConfigs[discoveryURL] = &config
+
+	return &config, nil
+}
+
+func oauth2Post(ngsi *NGSI, client *Client, endpoint string, values url.Values) (*http.Response, []byte, error) {
+	const funcName = "oauth2Post"
+
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, nil, ngsierr.New(funcName, 1, err.Error(), err)
+	}
+	idm := Client{URL: u, Headers: map[string]string{}, HTTP: ngsi.serverHTTP(client.Server)}
+	idm.SetHeader(cContentType, cAppXWwwFormUrlencoded)
+
+	res, body, err := idm.HTTPPost(values.Encode())
+	if err != nil {
+		return nil, nil, ngsierr.New(funcName, 2, err.Error(), err)
+	}
+
+	return res, body, nil
+}
+
+func oauth2ClientValues(server *Server, values url.Values) url.Values {
+	values.Set("client_id", server.ClientID)
+	if server.ClientSecret != "" {
+		values.Set("client_secret", server.ClientSecret)
+	}
+	return values
+}
+
+func oauth2RefreshToken(ngsi *NGSI, client *Client, endpoint, refreshToken string) (*http.Response, []byte, error) {
+	values := url.Values{"grant_type": {"refresh_token"}, "refresh_token": {refreshToken}}
+	return oauth2Post(ngsi, client, endpoint, oauth2ClientValues(client.Server, values))
+}
+
+// newOAuth2TokenInfo makes a token info from a token response. The refreshToken is kept
+// when the response of a refresh request has no refresh_token.
+func newOAuth2TokenInfo(ngsi *NGSI, idmType string, body []byte, refreshToken string) (*TokenInfo, error) {
+	const funcName = "newOAuth2TokenInfo"
+
+	utime := ngsi.TimeLib.NowUnix()
+
+	var token OAuth2Token
+	if err := JSONUnmarshal(body, &token); err != nil {
+		return nil, ngsierr.New(funcName, 1, err.Error(), err)
+	}
+	if token.RefreshToken == "" {
+		token.RefreshToken = refreshToken
+	}
+
+	tokenInfo := &TokenInfo{
+		Type:         idmType,
+		Token:        token.AccessToken,
+		RefreshToken: token.RefreshToken,
+		Expires:      time.Unix(utime+token.ExpiresIn, 0),
+		OAuth2:       &token,
+	}
+
+	return tokenInfo, nil
+}
+
+func oauth2RevokeToken(ngsi *NGSI, client *Client, tokenInfo *TokenInfo) error {
+	const funcName = "oauth2RevokeToken"
+
+	oidc, err := oidcDiscovery(ngsi, client)
+	if err != nil {
+		return ngsierr.New(funcName, 1, err.Error(), err)
+	}
+	if oidc.RevocationEndpoint == "" {
+		return ngsierr.New(funcName, 2, "revocation_endpoint not found", nil)
+	}
+
+	values := url.Values{"token": {tokenInfo.Token}, "token_type_hint": {"access_token"}}
+	if tokenInfo.RefreshToken != "" {
+		values = url.Values{"token": {tokenInfo.RefreshToken}, "token_type_hint": {"refresh_token"}}
+	}
+
+	res, body, err := oauth2Post(ngsi, client, oidc.RevocationEndpoint, oauth2ClientValues(client.Server, values))
+	if err != nil {
+		return ngsierr.New(funcName, 3, err.Error(), err)
+	}
+	if res.StatusCode != http.StatusOK {
+		return ngsierr.New(funcName, 4, fmt.Sprintf("error %s %s", res.Status, string(body)), nil)
+	}
+
+	return nil
+}
+
+func oauth2TokenInfo(tokenInfo *TokenInfo) ([]byte, error) {
+	const funcName = "oauth2TokenInfo"
+
+	b, err := JSONMarshal(tokenInfo.OAuth2)
+	if err != nil {
+		return nil, ngsierr.New(funcName, 1, err.Error(), err)
+	}
+	return b, nil
+}
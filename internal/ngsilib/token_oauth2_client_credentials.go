@@ -0,0 +1,127 @@
+/*
+MIT License
+
+Copyright (c) 2020-2024 Kazuhito Suda
+
+This file is part of NGSI Go
+
+https://github.com/lets-fiware/ngsi-go
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+
+*/
+
+package ngsilib
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/lets-fiware/ngsi-go/internal/ngsierr"
+)
+
+type idmOAuth2ClientCredentials struct {
+}
+
+func (i *idmOAuth2ClientCredentials) requestToken(ngsi *NGSI, client *Client, tokenInfo *TokenInfo) (*TokenInfo, error) {
+	const funcName = "requestTokenOAuth2ClientCredentials"
+
+	oidc, err := oidcDiscovery(ngsi, client)
+	if err != nil {
+		return nil, ngsierr.New(funcName, 1, err.Error(), err)
+	}
+
+	broker := client.Server
+
+	if tokenInfo.RefreshToken != "" {
+		res, body, err := oauth2RefreshToken(ngsi, client, oidc.TokenEndpoint, tokenInfo.RefreshToken)
+		if err != nil {
+			return nil, ngsierr.New(funcName, 2, err.Error(), err)
+		}
+		if res.StatusCode == http.StatusOK {
+			tokenInfo, err := newOAuth2TokenInfo(ngsi, COAuth2ClientCredentials, body, tokenInfo.RefreshToken)
+			if err != nil {
+				return nil, ngsierr.New(funcName, 3, err.Error(), err)
+			}
+			return tokenInfo, nil
+		}
+		gNGSI.Logging(LogInfo, fmt.Sprintf("%s %d\n", funcName, res.StatusCode))
+	}
+
+	values := url.Values{"grant_type": {"client_credentials"}}
+	if broker.TokenScope != "" {
+		values.Set("scope", broker.TokenScope)
+	}
+
+	res, body, err := oauth2Post(ngsi, client, oidc.TokenEndpoint, oauth2ClientValues(broker, values))
+	if err != nil {
+		return nil, ngsierr.New(funcName, 4, err.Error(), err)
+	}
+	if res.StatusCode != http.StatusOK {
+		return nil, ngsierr.New(funcName, 5, fmt.Sprintf("error %s %s", res.Status, string(body)), nil)
+	}
+
+	tokenInfo, err = newOAuth2TokenInfo(ngsi, COAuth2ClientCredentials, body, "")
+	if err != nil {
+		return nil, ngsierr.New(funcName, 6, err.Error(), err)
+	}
+
+	return tokenInfo, nil
+}
+
+func (i *idmOAuth2ClientCredentials) revokeToken(ngsi *NGSI, client *Client, tokenInfo *TokenInfo) error {
+	const funcName = "revokeTokenOAuth2ClientCredentials"
+
+	if err := oauth2RevokeToken(ngsi, client, tokenInfo); err != nil {
+		return ngsierr.New(funcName, 1, err.Error(), err)
+	}
+
+	return nil
+}
+
+func (i *idmOAuth2ClientCredentials) getAuthHeader(token string) (string, string) {
+	return "Authorization", "Bearer " + token
+}
+
+func (i *idmOAuth2ClientCredentials) getTokenInfo(tokenInfo *TokenInfo) ([]byte, error) {
+	const funcName = "getTokenInfoOAuth2ClientCredentials"
+
+	b, err := oauth2TokenInfo(tokenInfo)
+	if err != nil {
+		return nil, ngsierr.New(funcName, 1, err.Error(), err)
+	}
+	return b, nil
+}
+
+func (i *idmOAuth2ClientCredentials) checkIdmParams(idmParams *IdmParams) error {
+	const funcName = "checkIdmParamsOAuth2ClientCredentials"
+
+	if idmParams.IdmHost != "" &&
+		idmParams.Username == "" &&
+		idmParams.Password == "" &&
+		idmParams.ClientID != "" &&
+		idmParams.ClientSecret != "" &&
+		idmParams.HeaderName == "" &&
+		idmParams.HeaderValue == "" &&
+		idmParams.HeaderEnvValue == "" {
+		return nil
+	}
+	return ngsierr.New(funcName, 1, "idmHost, clientID and clientSecret are needed", nil)
+}
@@ -0,0 +1,351 @@
+/*
+MIT License
+
+Copyright (c) 2020-2024 Kazuhito Suda
+
+This file is part of NGSI Go
+
+https://github.com/lets-fiware/ngsi-go
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+
+*/
+
+package ngsilib
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/lets-fiware/ngsi-go/internal/assert"
+	"github.com/lets-fiware/ngsi-go/internal/ngsierr"
+)
+
+func testOAuth2Discovery() MockHTTPReqRes {
+	reqRes := MockHTTPReqRes{}
+	reqRes.Res.StatusCode = http.StatusOK
+	reqRes.ResBody = []byte(testOIDCConfiguration)
+	return reqRes
+}
+
+func TestRequestTokenOAuth2ClientCredentials(t *testing.T) {
+	ngsi := testNgsiLibInit()
+
+	reqRes := MockHTTPReqRes{}
+	reqRes.Res.StatusCode = http.StatusOK
+	reqRes.Path = "/realms/fiware/token"
+	reqRes.ReqData = []byte("client_id=0000&client_secret=1111&grant_type=client_credentials&scope=openid")
+	reqRes.ResBody = []byte(testOAuth2Token)
+	mock := NewMockHTTP()
+	mock.ReqRes = append(mock.ReqRes, testOAuth2Discovery(), reqRes)
+	ngsi.HTTP = mock
+
+	client := &Client{Server: &Server{ServerHost: "http://orion/", IdmType: COAuth2ClientCredentials, IdmHost: "http://idm/realms/fiware", ClientID: "0000", ClientSecret: "1111", TokenScope: "openid"}}
+	idm := &idmOAuth2ClientCredentials{}
+
+	actual, err := idm.requestToken(ngsi, client, &TokenInfo{})
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, COAuth2ClientCredentials, actual.Type)
+		assert.Equal(t, "access", actual.Token)
+		assert.Equal(t, "access", actual.OAuth2.AccessToken)
+	}
+}
+
+func TestRequestTokenOAuth2ClientCredentialsRefresh(t *testing.T) {
+	ngsi := testNgsiLibInit()
+
+	reqRes := MockHTTPReqRes{}
+	reqRes.Res.StatusCode = http.StatusOK
+	reqRes.ReqData = []byte("client_id=0000&client_secret=1111&grant_type=refresh_token&refresh_token=refresh")
+	reqRes.ResBody = []byte(testOAuth2Token)
+	mock := NewMockHTTP()
+	mock.ReqRes = append(mock.ReqRes, testOAuth2Discovery(), reqRes)
+	ngsi.HTTP = mock
+
+	client := &Client{Server: &Server{ServerHost: "http://orion/", IdmType: COAuth2ClientCredentials, IdmHost: "http://idm/realms/fiware", ClientID: "0000", ClientSecret: "1111"}}
+	idm := &idmOAuth2ClientCredentials{}
+
+	actual, err := idm.requestToken(ngsi, client, &TokenInfo{RefreshToken: "refresh"})
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, "access", actual.Token)
+	}
+}
+
+func TestRequestTokenOAuth2ClientCredentialsRefreshFallback(t *testing.T) {
+	ngsi := testNgsiLibInit()
+	buf := &bytes.Buffer{}
+	ngsi.LogWriter = buf
+
+	reqRes1 := MockHTTPReqRes{}
+	reqRes1.Res.StatusCode = http.StatusBadRequest
+	reqRes1.ResBody = []byte(`{"error":"invalid_grant"}`)
+	reqRes2 := MockHTTPReqRes{}
+	reqRes2.Res.StatusCode = http.StatusOK
+	reqRes2.ReqData = []byte("client_id=0000&client_secret=1111&grant_type=client_credentials")
+	reqRes2.ResBody = []byte(testOAuth2Token)
+	mock := NewMockHTTP()
+	mock.ReqRes = append(mock.ReqRes, testOAuth2Discovery(), reqRes1, reqRes2)
+	ngsi.HTTP = mock
+
+	client := &Client{Server: &Server{ServerHost: "http://orion/", IdmType: COAuth2ClientCredentials, IdmHost: "http://idm/realms/fiware", ClientID: "0000", ClientSecret: "1111"}}
+	idm := &idmOAuth2ClientCredentials{}
+
+	actual, err := idm.requestToken(ngsi, client, &TokenInfo{RefreshToken: "refresh"})
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, "access", actual.Token)
+	}
+}
+
+func TestRequestTokenOAuth2ClientCredentialsErrorDiscovery(t *testing.T) {
+	ngsi := testNgsiLibInit()
+
+	reqRes := MockHTTPReqRes{}
+	reqRes.Err = errors.New("http error")
+	mock := NewMockHTTP()
+	mock.ReqRes = append(mock.ReqRes, reqRes)
+	ngsi.HTTP = mock
+
+	client := &Client{Server: &Server{ServerHost: "http://orion/", IdmType: COAuth2ClientCredentials, IdmHost: "http://idm/realms/fiware", ClientID: "0000", ClientSecret: "1111"}}
+	idm := &idmOAuth2ClientCredentials{}
+
+	_, err := idm.requestToken(ngsi, client, &TokenInfo{})
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 1, ngsiErr.ErrNo)
+		assert.Equal(t, "http error", ngsiErr.Message)
+	}
+}
+
+func TestRequestTokenOAuth2ClientCredentialsErrorRefreshHTTP(t *testing.T) {
+	ngsi := testNgsiLibInit()
+
+	reqRes := MockHTTPReqRes{}
+	reqRes.Err = errors.New("http error")
+	mock := NewMockHTTP()
+	mock.ReqRes = append(mock.ReqRes, testOAuth2Discovery(), reqRes)
+	ngsi.HTTP = mock
+
+	client := &Client{Server: &Server{ServerHost: "http://orion/", IdmType: COAuth2ClientCredentials, IdmHost: "http://idm/realms/fiware", ClientID: "0000", ClientSecret: "1111"}}
+	idm := &idmOAuth2ClientCredentials{}
+
+	_, err := idm.requestToken(ngsi, client, &TokenInfo{RefreshToken: "refresh"})
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 2, ngsiErr.ErrNo)
+		assert.Equal(t, "http error", ngsiErr.Message)
+	}
+}
+
+func TestRequestTokenOAuth2ClientCredentialsErrorRefreshJSON(t *testing.T) {
+	ngsi := testNgsiLibInit()
+
+	reqRes := MockHTTPReqRes{}
+	reqRes.Res.StatusCode = http.StatusOK
+	reqRes.ResBody = []byte(`{`)
+	mock := NewMockHTTP()
+	mock.ReqRes = append(mock.ReqRes, testOAuth2Discovery(), reqRes)
+	ngsi.HTTP = mock
+
+	client := &Client{Server: &Server{ServerHost: "http://orion/", IdmType: COAuth2ClientCredentials, IdmHost: "http://idm/realms/fiware", ClientID: "0000", ClientSecret: "1111"}}
+	idm := &idmOAuth2ClientCredentials{}
+
+	_, err := idm.requestToken(ngsi, client, &TokenInfo{RefreshToken: "refresh"})
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 3, ngsiErr.ErrNo)
+	}
+}
+
+func TestRequestTokenOAuth2ClientCredentialsErrorHTTP(t *testing.T) {
+	ngsi := testNgsiLibInit()
+
+	reqRes := MockHTTPReqRes{}
+	reqRes.Err = errors.New("http error")
+	mock := NewMockHTTP()
+	mock.ReqRes = append(mock.ReqRes, testOAuth2Discovery(), reqRes)
+	ngsi.HTTP = mock
+
+	client := &Client{Server: &Server{ServerHost: "http://orion/", IdmType: COAuth2ClientCredentials, IdmHost: "http://idm/realms/fiware", ClientID: "0000", ClientSecret: "1111"}}
+	idm := &idmOAuth2ClientCredentials{}
+
+	_, err := idm.requestToken(ngsi, client, &TokenInfo{})
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 4, ngsiErr.ErrNo)
+		assert.Equal(t, "http error", ngsiErr.Message)
+	}
+}
+
+func TestRequestTokenOAuth2ClientCredentialsErrorStatus(t *testing.T) {
+	ngsi := testNgsiLibInit()
+
+	reqRes := MockHTTPReqRes{}
+	reqRes.Res.StatusCode = http.StatusUnauthorized
+	reqRes.Res.Status = "401 Unauthorized"
+	reqRes.ResBody = []byte(`{"error":"invalid_client"}`)
+	mock := NewMockHTTP()
+	mock.ReqRes = append(mock.ReqRes, testOAuth2Discovery(), reqRes)
+	ngsi.HTTP = mock
+
+	client := &Client{Server: &Server{ServerHost: "http://orion/", IdmType: COAuth2ClientCredentials, IdmHost: "http://idm/realms/fiware", ClientID: "0000", ClientSecret: "1111"}}
+	idm := &idmOAuth2ClientCredentials{}
+
+	_, err := idm.requestToken(ngsi, client, &TokenInfo{})
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 5, ngsiErr.ErrNo)
+		assert.Equal(t, `error 401 Unauthorized {"error":"invalid_client"}`, ngsiErr.Message)
+	}
+}
+
+func TestRequestTokenOAuth2ClientCredentialsErrorJSON(t *testing.T) {
+	ngsi := testNgsiLibInit()
+
+	reqRes := MockHTTPReqRes{}
+	reqRes.Res.StatusCode = http.StatusOK
+	reqRes.ResBody = []byte(`{`)
+	mock := NewMockHTTP()
+	mock.ReqRes = append(mock.ReqRes, testOAuth2Discovery(), reqRes)
+	ngsi.HTTP = mock
+
+	client := &Client{Server: &Server{ServerHost: "http://orion/", IdmType: COAuth2ClientCredentials, IdmHost: "http://idm/realms/fiware", ClientID: "0000", ClientSecret: "1111"}}
+	idm := &idmOAuth2ClientCredentials{}
+
+	_, err := idm.requestToken(ngsi, client, &TokenInfo{})
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 6, ngsiErr.ErrNo)
+	}
+}
+
+func TestRevokeTokenOAuth2ClientCredentials(t *testing.T) {
+	ngsi := testNgsiLibInit()
+
+	reqRes := MockHTTPReqRes{}
+	reqRes.Res.StatusCode = http.StatusOK
+	mock := NewMockHTTP()
+	mock.ReqRes = append(mock.ReqRes, testOAuth2Discovery(), reqRes)
+	ngsi.HTTP = mock
+
+	client := &Client{Server: &Server{ServerHost: "http://orion/", IdmType: COAuth2ClientCredentials, IdmHost: "http://idm/realms/fiware", ClientID: "0000", ClientSecret: "1111"}}
+	idm := &idmOAuth2ClientCredentials{}
+
+	err := idm.revokeToken(ngsi, client, &TokenInfo{Token: "access"})
+
+	assert.NoError(t, err)
+}
+
+func TestRevokeTokenOAuth2ClientCredentialsError(t *testing.T) {
+	ngsi := testNgsiLibInit()
+
+	reqRes := MockHTTPReqRes{}
+	reqRes.Err = errors.New("http error")
+	mock := NewMockHTTP()
+	mock.ReqRes = append(mock.ReqRes, reqRes)
+	ngsi.HTTP = mock
+
+	client := &Client{Server: &Server{ServerHost: "http://orion/", IdmType: COAuth2ClientCredentials, IdmHost: "http://idm/realms/fiware", ClientID: "0000", ClientSecret: "1111"}}
+	idm := &idmOAuth2ClientCredentials{}
+
+	err := idm.revokeToken(ngsi, client, &TokenInfo{Token: "access"})
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 1, ngsiErr.ErrNo)
+		assert.Equal(t, "http error", ngsiErr.Message)
+	}
+}
+
+func TestGetAuthHeaderOAuth2ClientCredentials(t *testing.T) {
+	idm := &idmOAuth2ClientCredentials{}
+
+	key, value := idm.getAuthHeader("access")
+
+	assert.Equal(t, "Authorization", key)
+	assert.Equal(t, "Bearer access", value)
+}
+
+func TestGetTokenInfoOAuth2ClientCredentials(t *testing.T) {
+	testNgsiLibInit()
+
+	idm := &idmOAuth2ClientCredentials{}
+	tokenInfo := &TokenInfo{OAuth2: &OAuth2Token{AccessToken: "access", ExpiresIn: 300, TokenType: "Bearer"}}
+
+	actual, err := idm.getTokenInfo(tokenInfo)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, `{"access_token":"access","expires_in":300,"token_type":"Bearer"}`, string(actual))
+	}
+}
+
+func TestGetTokenInfoOAuth2ClientCredentialsError(t *testing.T) {
+	ngsi := testNgsiLibInit()
+	ngsi.JSONConverter = &MockJSONLib{EncodeErr: [5]error{errors.New("json error")}, DecodeErr: [5]error{errors.New("json error")}}
+
+	idm := &idmOAuth2ClientCredentials{}
+	tokenInfo := &TokenInfo{OAuth2: &OAuth2Token{AccessToken: "access"}}
+
+	_, err := idm.getTokenInfo(tokenInfo)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 1, ngsiErr.ErrNo)
+		assert.Equal(t, "json error", ngsiErr.Message)
+	}
+}
+
+func TestCheckIdmParamsOAuth2ClientCredentials(t *testing.T) {
+	idm := &idmOAuth2ClientCredentials{}
+	idmParams := &IdmParams{
+		IdmHost:      "http://keycloak:8080/realms/fiware",
+		ClientID:     "00000000-1111-2222-3333-444444444444",
+		ClientSecret: "55555555-6666-7777-8888-999999999999",
+	}
+
+	err := idm.checkIdmParams(idmParams)
+
+	assert.NoError(t, err)
+}
+
+func TestCheckIdmParamsOAuth2ClientCredentialsError(t *testing.T) {
+	idm := &idmOAuth2ClientCredentials{}
+	idmParams := &IdmParams{
+		IdmHost:  "http://keycloak:8080/realms/fiware",
+		ClientID: "00000000-1111-2222-3333-444444444444",
+	}
+
+	err := idm.checkIdmParams(idmParams)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 1, ngsiErr.ErrNo)
+		assert.Equal(t, "idmHost, clientID and clientSecret are needed", ngsiErr.Message)
+	}
+}
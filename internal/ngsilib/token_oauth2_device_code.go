@@ -0,0 +1,194 @@
+/*
+MIT License
+
+Copyright (c) 2020-2024 Kazuhito Suda
+
+This file is part of NGSI Go
+
+https://github.com/lets-fiware/ngsi-go
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+
+*/
+
+package ngsilib
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/lets-fiware/ngsi-go/internal/ngsierr"
+)
+
+type oauth2DeviceAuthorization struct {
+	DeviceCode              string `json:"device_code"`
+	UserCode                string `json:"user_code"`
+	VerificationURI         string `json:"verification_uri"`
+	VerificationURIComplete string `json:"verification_uri_complete"`
+	ExpiresIn               int64  `json:"expires_in"`
+	Interval                int64  `json:"interval"`
+}
+
+const (
+	oauth2DeviceCodeGrantType = "urn:ietf:params:oauth:grant-type:device_code"
+	oauth2DefaultInterval     = 5
+	oauth2DefaultDeviceExpiry = 600
+)
+
+type idmOAuth2DeviceCode struct {
+}
+
+func (i *idmOAuth2DeviceCode) requestToken(ngsi *NGSI, client *Client, tokenInfo *TokenInfo) (*TokenInfo, error) {
+	const funcName = "requestTokenOAuth2DeviceCode"
+
+	oidc, err := oidcDiscovery(ngsi, client)
+	if err != nil {
+		return nil, ngsierr.New(funcName, 1, err.Error(), err)
+	}
+
+	broker := client.Server
+
+	if tokenInfo.RefreshToken != "" {
+		res, body, err := oauth2RefreshToken(ngsi, client, oidc.TokenEndpoint, tokenInfo.RefreshToken)
+		if err != nil {
+			return nil, ngsierr.New(funcName, 2, err.Error(), err)
+		}
+		if res.StatusCode == http.StatusOK {
+			tokenInfo, err := newOAuth2TokenInfo(ngsi, COAuth2DeviceCode, body, tokenInfo.RefreshToken)
+			if err != nil {
+				return nil, ngsierr.New(funcName, 3, err.Error(), err)
+			}
+			return tokenInfo, nil
+		}
+		gNGSI.Logging(LogInfo, fmt.Sprintf("%s %d\n", funcName, res.StatusCode))
+	}
+
+	if oidc.DeviceAuthorizationEndpoint == "" {
+		return nil, ngsierr.New(funcName, 4, "device_authorization_endpoint not found", nil)
+	}
+
+	values := url.Values{}
+	if broker.TokenScope != "" {
+		values.Set("scope", broker.TokenScope)
+	}
+
+	res, body, err := oauth2Post(ngsi, client, oidc.DeviceAuthorizationEndpoint, oauth2ClientValues(broker, values))
+	if err != nil {
+		return nil, ngsierr.New(funcName, 5, err.Error(), err)
+	}
+	if res.StatusCode != http.StatusOK {
+		return nil, ngsierr.New(funcName, 6, fmt.Sprintf("error %s %s", res.Status, string(body)), nil)
+	}
+
+	var auth oauth2DeviceAuthorization
+	if err := JSONUnmarshal(body, &auth); err != nil {
+		return nil, ngsierr.New(funcName, 7, err.Error(), err)
+	}
+
+	if auth.VerificationURIComplete != "" {
+		fmt.Fprintf(ngsi.Stderr, "Open %s in a browser to sign in.\n", auth.VerificationURIComplete)
+	} else {
+		fmt.Fprintf(ngsi.Stderr, "Open %s in a browser and enter the code %s to sign in.\n", auth.VerificationURI, auth.UserCode)
+	}
+
+	interval := auth.Interval
+	if interval <= 0 {
+		interval = oauth2DefaultInterval
+	}
+	expiresIn := auth.ExpiresIn
+	if expiresIn <= 0 {
+		expiresIn = oauth2DefaultDeviceExpiry
+	}
+	deadline := ngsi.TimeLib.NowUnix() + expiresIn
+
+	values = url.Values{"grant_type": {oauth2DeviceCodeGrantType}, "device_code": {auth.DeviceCode}}
+	values = oauth2ClientValues(broker, values)
+
+	for {
+		ngsi.TimeLib.Sleep(time.Duration(interval) * time.Second)
+
+		res, body, err := oauth2Post(ngsi, client, oidc.TokenEndpoint, values)
+		if err != nil {
+			return nil, ngsierr.New(funcName, 8, err.Error(), err)
+		}
+		if res.StatusCode == http.StatusOK {
+			tokenInfo, err := newOAuth2TokenInfo(ngsi, COAuth2DeviceCode, body, "")
+			if err != nil {
+				return nil, ngsierr.New(funcName, 9, err.Error(), err)
+			}
+			return tokenInfo, nil
+		}
+
+		var e oauth2Error
+		_ = JSONUnmarshal(body, &e)
+
+		switch e.Error {
+		default:
+			return nil, ngsierr.New(funcName, 10, fmt.Sprintf("error %s %s", res.Status, string(body)), nil)
+		case "authorization_pending":
+		case "slow_down":
+			interval += oauth2DefaultInterval
+		}
+
+		if ngsi.TimeLib.NowUnix() >= deadline {
+			return nil, ngsierr.New(funcName, 11, "device code expired", nil)
+		}
+	}
+}
+
+func (i *idmOAuth2DeviceCode) revokeToken(ngsi *NGSI, client *Client, tokenInfo *TokenInfo) error {
+	const funcName = "revokeTokenOAuth2DeviceCode"
+
+	if err := oauth2RevokeToken(ngsi, client, tokenInfo); err != nil {
+		return ngsierr.New(funcName, 1, err.Error(), err)
+	}
+
+	return nil
+}
+
+func (i *idmOAuth2DeviceCode) getAuthHeader(token string) (string, string) {
+	return "Authorization", "Bearer " + token
+}
+
+func (i *idmOAuth2DeviceCode) getTokenInfo(tokenInfo *TokenInfo) ([]byte, error) {
+	const funcName = "getTokenInfoOAuth2DeviceCode"
+
+	b, err := oauth2TokenInfo(tokenInfo)
+	if err != nil {
+		return nil, ngsierr.New(funcName, 1, err.Error(), err)
+	}
+	return b, nil
+}
+
+func (i *idmOAuth2DeviceCode) checkIdmParams(idmParams *IdmParams) error {
+	const funcName = "checkIdmParamsOAuth2DeviceCode"
+
+	if idmParams.IdmHost != "" &&
+		idmParams.Username == "" &&
+		idmParams.Password == "" &&
+		idmParams.ClientID != "" &&
+		idmParams.HeaderName == "" &&
+		idmParams.HeaderValue == "" &&
+		idmParams.HeaderEnvValue == "" {
+		return nil
+	}
+	return ngsierr.New(funcName, 1, "idmHost and clientID are needed", nil)
+}
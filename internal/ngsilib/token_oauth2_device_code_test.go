@@ -0,0 +1,502 @@
+/*
+MIT License
+
+Copyright (c) 2020-2024 Kazuhito Suda
+
+This file is part of NGSI Go
+
+https://github.com/lets-fiware/ngsi-go
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+
+*/
+
+package ngsilib
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/lets-fiware/ngsi-go/internal/assert"
+	"github.com/lets-fiware/ngsi-go/internal/ngsierr"
+)
+
+const testDeviceAuthorization = `{"device_code":"device","user_code":"ABCD-EFGH","verification_uri":"http://idm/device","expires_in":600,"interval":5}`
+
+func testOAuth2DeviceAuthorization(body string) MockHTTPReqRes {
+	reqRes := MockHTTPReqRes{}
+	reqRes.Res.StatusCode = http.StatusOK
+	reqRes.Path = "/realms/fiware/device"
+	reqRes.ResBody = []byte(body)
+	return reqRes
+}
+
+func testOAuth2DevicePending(e string) MockHTTPReqRes {
+	reqRes := MockHTTPReqRes{}
+	reqRes.Res.StatusCode = http.StatusBadRequest
+	reqRes.Res.Status = "400 Bad Request"
+	reqRes.ResBody = []byte(`{"error":"` + e + `"}`)
+	return reqRes
+}
+
+func TestRequestTokenOAuth2DeviceCode(t *testing.T) {
+	ngsi := testNgsiLibInit()
+	ngsi.TimeLib = &MockTimeLib{unixTime: 1000}
+	buf := &bytes.Buffer{}
+	ngsi.Stderr = buf
+
+	authReq := testOAuth2DeviceAuthorization(testDeviceAuthorization)
+	authReq.ReqData = []byte("client_id=0000&scope=openid")
+	reqRes := MockHTTPReqRes{}
+	reqRes.Res.StatusCode = http.StatusOK
+	reqRes.Path = "/realms/fiware/token"
+	reqRes.ReqData = []byte("client_id=0000&device_code=device&grant_type=urn%3Aietf%3Aparams%3Aoauth%3Agrant-type%3Adevice_code")
+	reqRes.ResBody = []byte(testOAuth2Token)
+	mock := NewMockHTTP()
+	mock.ReqRes = append(mock.ReqRes, testOAuth2Discovery(), authReq, testOAuth2DevicePending("authorization_pending"), testOAuth2DevicePending("slow_down"), reqRes)
+	ngsi.HTTP = mock
+
+	client := &Client{Server: &Server{ServerHost: "http://orion/", IdmType: COAuth2DeviceCode, IdmHost: "http://idm/realms/fiware", ClientID: "0000", TokenScope: "openid"}}
+	idm := &idmOAuth2DeviceCode{}
+
+	actual, err := idm.requestToken(ngsi, client, &TokenInfo{})
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, COAuth2DeviceCode, actual.Type)
+		assert.Equal(t, "access", actual.Token)
+		assert.Equal(t, "Open http://idm/device in a browser and enter the code ABCD-EFGH to sign in.\n", buf.String())
+	}
+}
+
+func TestRequestTokenOAuth2DeviceCodeComplete(t *testing.T) {
+	ngsi := testNgsiLibInit()
+	ngsi.TimeLib = &MockTimeLib{unixTime: 1000}
+	buf := &bytes.Buffer{}
+	ngsi.Stderr = buf
+
+	reqRes := MockHTTPReqRes{}
+	reqRes.Res.StatusCode = http.StatusOK
+	reqRes.ResBody = []byte(testOAuth2Token)
+	mock := NewMockHTTP()
+	mock.ReqRes = append(mock.ReqRes, testOAuth2Discovery(), testOAuth2DeviceAuthorization(`{"device_code":"device","user_code":"ABCD-EFGH","verification_uri":"http://idm/device","verification_uri_complete":"http://idm/device?user_code=ABCD-EFGH"}`), reqRes)
+	ngsi.HTTP = mock
+
+	client := &Client{Server: &Server{ServerHost: "http://orion/", IdmType: COAuth2DeviceCode, IdmHost: "http://idm/realms/fiware", ClientID: "0000", ClientSecret: "1111"}}
+	idm := &idmOAuth2DeviceCode{}
+
+	actual, err := idm.requestToken(ngsi, client, &TokenInfo{})
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, "access", actual.Token)
+		assert.Equal(t, "Open http://idm/device?user_code=ABCD-EFGH in a browser to sign in.\n", buf.String())
+	}
+}
+
+func TestRequestTokenOAuth2DeviceCodeRefresh(t *testing.T) {
+	ngsi := testNgsiLibInit()
+	ngsi.TimeLib = &MockTimeLib{unixTime: 1000}
+
+	reqRes := MockHTTPReqRes{}
+	reqRes.Res.StatusCode = http.StatusOK
+	reqRes.ReqData = []byte("client_id=0000&grant_type=refresh_token&refresh_token=refresh")
+	reqRes.ResBody = []byte(testOAuth2Token)
+	mock := NewMockHTTP()
+	mock.ReqRes = append(mock.ReqRes, testOAuth2Discovery(), reqRes)
+	ngsi.HTTP = mock
+
+	client := &Client{Server: &Server{ServerHost: "http://orion/", IdmType: COAuth2DeviceCode, IdmHost: "http://idm/realms/fiware", ClientID: "0000"}}
+	idm := &idmOAuth2DeviceCode{}
+
+	actual, err := idm.requestToken(ngsi, client, &TokenInfo{RefreshToken: "refresh"})
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, "access", actual.Token)
+	}
+}
+
+func TestRequestTokenOAuth2DeviceCodeRefreshFallback(t *testing.T) {
+	ngsi := testNgsiLibInit()
+	ngsi.TimeLib = &MockTimeLib{unixTime: 1000}
+	ngsi.Stderr = &bytes.Buffer{}
+	ngsi.LogWriter = &bytes.Buffer{}
+
+	reqRes := MockHTTPReqRes{}
+	reqRes.Res.StatusCode = http.StatusOK
+	reqRes.ResBody = []byte(testOAuth2Token)
+	mock := NewMockHTTP()
+	mock.ReqRes = append(mock.ReqRes, testOAuth2Discovery(), testOAuth2DevicePending("invalid_grant"), testOAuth2DeviceAuthorization(testDeviceAuthorization), reqRes)
+	ngsi.HTTP = mock
+
+	client := &Client{Server: &Server{ServerHost: "http://orion/", IdmType: COAuth2DeviceCode, IdmHost: "http://idm/realms/fiware", ClientID: "0000"}}
+	idm := &idmOAuth2DeviceCode{}
+
+	actual, err := idm.requestToken(ngsi, client, &TokenInfo{RefreshToken: "refresh"})
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, "access", actual.Token)
+	}
+}
+
+func TestRequestTokenOAuth2DeviceCodeErrorDiscovery(t *testing.T) {
+	ngsi := testNgsiLibInit()
+	ngsi.TimeLib = &MockTimeLib{unixTime: 1000}
+
+	reqRes := MockHTTPReqRes{}
+	reqRes.Err = errors.New("http error")
+	mock := NewMockHTTP()
+	mock.ReqRes = append(mock.ReqRes, reqRes)
+	ngsi.HTTP = mock
+
+	client := &Client{Server: &Server{ServerHost: "http://orion/", IdmType: COAuth2DeviceCode, IdmHost: "http://idm/realms/fiware", ClientID: "0000"}}
+	idm := &idmOAuth2DeviceCode{}
+
+	_, err := idm.requestToken(ngsi, client, &TokenInfo{})
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 1, ngsiErr.ErrNo)
+		assert.Equal(t, "http error", ngsiErr.Message)
+	}
+}
+
+func TestRequestTokenOAuth2DeviceCodeErrorRefreshHTTP(t *testing.T) {
+	ngsi := testNgsiLibInit()
+	ngsi.TimeLib = &MockTimeLib{unixTime: 1000}
+
+	reqRes := MockHTTPReqRes{}
+	reqRes.Err = errors.New("http error")
+	mock := NewMockHTTP()
+	mock.ReqRes = append(mock.ReqRes, testOAuth2Discovery(), reqRes)
+	ngsi.HTTP = mock
+
+	client := &Client{Server: &Server{ServerHost: "http://orion/", IdmType: COAuth2DeviceCode, IdmHost: "http://idm/realms/fiware", ClientID: "0000"}}
+	idm := &idmOAuth2DeviceCode{}
+
+	_, err := idm.requestToken(ngsi, client, &TokenInfo{RefreshToken: "refresh"})
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 2, ngsiErr.ErrNo)
+		assert.Equal(t, "http error", ngsiErr.Message)
+	}
+}
+
+func TestRequestTokenOAuth2DeviceCodeErrorRefreshJSON(t *testing.T) {
+	ngsi := testNgsiLibInit()
+	ngsi.TimeLib = &MockTimeLib{unixTime: 1000}
+
+	reqRes := MockHTTPReqRes{}
+	reqRes.Res.StatusCode = http.StatusOK
+	reqRes.ResBody = []byte(`{`)
+	mock := NewMockHTTP()
+	mock.ReqRes = append(mock.ReqRes, testOAuth2Discovery(), reqRes)
+	ngsi.HTTP = mock
+
+	client := &Client{Server: &Server{ServerHost: "http://orion/", IdmType: COAuth2DeviceCode, IdmHost: "http://idm/realms/fiware", ClientID: "0000"}}
+	idm := &idmOAuth2DeviceCode{}
+
+	_, err := idm.requestToken(ngsi, client, &TokenInfo{RefreshToken: "refresh"})
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 3, ngsiErr.ErrNo)
+	}
+}
+
+func TestRequestTokenOAuth2DeviceCodeErrorNoEndpoint(t *testing.T) {
+	ngsi := testNgsiLibInit()
+	ngsi.TimeLib = &MockTimeLib{unixTime: 1000}
+
+	reqRes := MockHTTPReqRes{}
+	reqRes.Res.StatusCode = http.StatusOK
+	reqRes.ResBody = []byte(`{"token_endpoint":"http://idm/realms/fiware/token"}`)
+	mock := NewMockHTTP()
+	mock.ReqRes = append(mock.ReqRes, reqRes)
+	ngsi.HTTP = mock
+
+	client := &Client{Server: &Server{ServerHost: "http://orion/", IdmType: COAuth2DeviceCode, IdmHost: "http://idm/realms/fiware", ClientID: "0000"}}
+	idm := &idmOAuth2DeviceCode{}
+
+	_, err := idm.requestToken(ngsi, client, &TokenInfo{})
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 4, ngsiErr.ErrNo)
+		assert.Equal(t, "device_authorization_endpoint not found", ngsiErr.Message)
+	}
+}
+
+func TestRequestTokenOAuth2DeviceCodeErrorAuthorizationHTTP(t *testing.T) {
+	ngsi := testNgsiLibInit()
+	ngsi.TimeLib = &MockTimeLib{unixTime: 1000}
+
+	reqRes := MockHTTPReqRes{}
+	reqRes.Err = errors.New("http error")
+	mock := NewMockHTTP()
+	mock.ReqRes = append(mock.ReqRes, testOAuth2Discovery(), reqRes)
+	ngsi.HTTP = mock
+
+	client := &Client{Server: &Server{ServerHost: "http://orion/", IdmType: COAuth2DeviceCode, IdmHost: "http://idm/realms/fiware", ClientID: "0000"}}
+	idm := &idmOAuth2DeviceCode{}
+
+	_, err := idm.requestToken(ngsi, client, &TokenInfo{})
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 5, ngsiErr.ErrNo)
+		assert.Equal(t, "http error", ngsiErr.Message)
+	}
+}
+
+func TestRequestTokenOAuth2DeviceCodeErrorAuthorizationStatus(t *testing.T) {
+	ngsi := testNgsiLibInit()
+	ngsi.TimeLib = &MockTimeLib{unixTime: 1000}
+
+	mock := NewMockHTTP()
+	mock.ReqRes = append(mock.ReqRes, testOAuth2Discovery(), testOAuth2DevicePending("unauthorized_client"))
+	ngsi.HTTP = mock
+
+	client := &Client{Server: &Server{ServerHost: "http://orion/", IdmType: COAuth2DeviceCode, IdmHost: "http://idm/realms/fiware", ClientID: "0000"}}
+	idm := &idmOAuth2DeviceCode{}
+
+	_, err := idm.requestToken(ngsi, client, &TokenInfo{})
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 6, ngsiErr.ErrNo)
+		assert.Equal(t, `error 400 Bad Request {"error":"unauthorized_client"}`, ngsiErr.Message)
+	}
+}
+
+func TestRequestTokenOAuth2DeviceCodeErrorAuthorizationJSON(t *testing.T) {
+	ngsi := testNgsiLibInit()
+	ngsi.TimeLib = &MockTimeLib{unixTime: 1000}
+
+	mock := NewMockHTTP()
+	mock.ReqRes = append(mock.ReqRes, testOAuth2Discovery(), testOAuth2DeviceAuthorization(`{`))
+	ngsi.HTTP = mock
+
+	client := &Client{Server: &Server{ServerHost: "http://orion/", IdmType: COAuth2DeviceCode, IdmHost: "http://idm/realms/fiware", ClientID: "0000"}}
+	idm := &idmOAuth2DeviceCode{}
+
+	_, err := idm.requestToken(ngsi, client, &TokenInfo{})
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 7, ngsiErr.ErrNo)
+	}
+}
+
+func TestRequestTokenOAuth2DeviceCodeErrorTokenHTTP(t *testing.T) {
+	ngsi := testNgsiLibInit()
+	ngsi.TimeLib = &MockTimeLib{unixTime: 1000}
+	ngsi.Stderr = &bytes.Buffer{}
+
+	reqRes := MockHTTPReqRes{}
+	reqRes.Err = errors.New("http error")
+	mock := NewMockHTTP()
+	mock.ReqRes = append(mock.ReqRes, testOAuth2Discovery(), testOAuth2DeviceAuthorization(testDeviceAuthorization), reqRes)
+	ngsi.HTTP = mock
+
+	client := &Client{Server: &Server{ServerHost: "http://orion/", IdmType: COAuth2DeviceCode, IdmHost: "http://idm/realms/fiware", ClientID: "0000"}}
+	idm := &idmOAuth2DeviceCode{}
+
+	_, err := idm.requestToken(ngsi, client, &TokenInfo{})
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 8, ngsiErr.ErrNo)
+		assert.Equal(t, "http error", ngsiErr.Message)
+	}
+}
+
+func TestRequestTokenOAuth2DeviceCodeErrorTokenJSON(t *testing.T) {
+	ngsi := testNgsiLibInit()
+	ngsi.TimeLib = &MockTimeLib{unixTime: 1000}
+	ngsi.Stderr = &bytes.Buffer{}
+
+	reqRes := MockHTTPReqRes{}
+	reqRes.Res.StatusCode = http.StatusOK
+	reqRes.ResBody = []byte(`{`)
+	mock := NewMockHTTP()
+	mock.ReqRes = append(mock.ReqRes, testOAuth2Discovery(), testOAuth2DeviceAuthorization(testDeviceAuthorization), reqRes)
+	ngsi.HTTP = mock
+
+	client := &Client{Server: &Server{ServerHost: "http://orion/", IdmType: COAuth2DeviceCode, IdmHost: "http://idm/realms/fiware", ClientID: "0000"}}
+	idm := &idmOAuth2DeviceCode{}
+
+	_, err := idm.requestToken(ngsi, client, &TokenInfo{})
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 9, ngsiErr.ErrNo)
+	}
+}
+
+func TestRequestTokenOAuth2DeviceCodeErrorDenied(t *testing.T) {
+	ngsi := testNgsiLibInit()
+	ngsi.TimeLib = &MockTimeLib{unixTime: 1000}
+	ngsi.Stderr = &bytes.Buffer{}
+
+	mock := NewMockHTTP()
+	mock.ReqRes = append(mock.ReqRes, testOAuth2Discovery(), testOAuth2DeviceAuthorization(testDeviceAuthorization), testOAuth2DevicePending("access_denied"))
+	ngsi.HTTP = mock
+
+	client := &Client{Server: &Server{ServerHost: "http://orion/", IdmType: COAuth2DeviceCode, IdmHost: "http://idm/realms/fiware", ClientID: "0000"}}
+	idm := &idmOAuth2DeviceCode{}
+
+	_, err := idm.requestToken(ngsi, client, &TokenInfo{})
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 10, ngsiErr.ErrNo)
+		assert.Equal(t, `error 400 Bad Request {"error":"access_denied"}`, ngsiErr.Message)
+	}
+}
+
+func TestRequestTokenOAuth2DeviceCodeErrorExpired(t *testing.T) {
+	ngsi := testNgsiLibInit()
+	ngsi.TimeLib = &MockTimeLib{unixTime: 1000}
+	ngsi.Stderr = &bytes.Buffer{}
+
+	mock := NewMockHTTP()
+	mock.ReqRes = append(mock.ReqRes, testOAuth2Discovery(), testOAuth2DeviceAuthorization(`{"device_code":"device","user_code":"ABCD-EFGH","verification_uri":"http://idm/device","expires_in":10,"interval":5}`), testOAuth2DevicePending("authorization_pending"), testOAuth2DevicePending("authorization_pending"))
+	ngsi.HTTP = mock
+
+	client := &Client{Server: &Server{ServerHost: "http://orion/", IdmType: COAuth2DeviceCode, IdmHost: "http://idm/realms/fiware", ClientID: "0000"}}
+	idm := &idmOAuth2DeviceCode{}
+
+	_, err := idm.requestToken(ngsi, client, &TokenInfo{})
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 11, ngsiErr.ErrNo)
+		assert.Equal(t, "device code expired", ngsiErr.Message)
+	}
+}
+
+func TestRevokeTokenOAuth2DeviceCode(t *testing.T) {
+	ngsi := testNgsiLibInit()
+	ngsi.TimeLib = &MockTimeLib{unixTime: 1000}
+
+	reqRes := MockHTTPReqRes{}
+	reqRes.Res.StatusCode = http.StatusOK
+	mock := NewMockHTTP()
+	mock.ReqRes = append(mock.ReqRes, testOAuth2Discovery(), reqRes)
+	ngsi.HTTP = mock
+
+	client := &Client{Server: &Server{ServerHost: "http://orion/", IdmType: COAuth2DeviceCode, IdmHost: "http://idm/realms/fiware", ClientID: "0000"}}
+	idm := &idmOAuth2DeviceCode{}
+
+	err := idm.revokeToken(ngsi, client, &TokenInfo{Token: "access", RefreshToken: "refresh"})
+
+	assert.NoError(t, err)
+}
+
+func TestRevokeTokenOAuth2DeviceCodeError(t *testing.T) {
+	ngsi := testNgsiLibInit()
+	ngsi.TimeLib = &MockTimeLib{unixTime: 1000}
+
+	reqRes := MockHTTPReqRes{}
+	reqRes.Err = errors.New("http error")
+	mock := NewMockHTTP()
+	mock.ReqRes = append(mock.ReqRes, reqRes)
+	ngsi.HTTP = mock
+
+	client := &Client{Server: &Server{ServerHost: "http://orion/", IdmType: COAuth2DeviceCode, IdmHost: "http://idm/realms/fiware", ClientID: "0000"}}
+	idm := &idmOAuth2DeviceCode{}
+
+	err := idm.revokeToken(ngsi, client, &TokenInfo{Token: "access"})
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 1, ngsiErr.ErrNo)
+		assert.Equal(t, "http error", ngsiErr.Message)
+	}
+}
+
+func TestGetAuthHeaderOAuth2DeviceCode(t *testing.T) {
+	idm := &idmOAuth2DeviceCode{}
+
+	key, value := idm.getAuthHeader("access")
+
+	assert.Equal(t, "Authorization", key)
+	assert.Equal(t, "Bearer access", value)
+}
+
+func TestGetTokenInfoOAuth2DeviceCode(t *testing.T) {
+	testNgsiLibInit()
+
+	idm := &idmOAuth2DeviceCode{}
+	tokenInfo := &TokenInfo{OAuth2: &OAuth2Token{AccessToken: "access", ExpiresIn: 300, TokenType: "Bearer"}}
+
+	actual, err := idm.getTokenInfo(tokenInfo)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, `{"access_token":"access","expires_in":300,"token_type":"Bearer"}`, string(actual))
+	}
+}
+
+func TestGetTokenInfoOAuth2DeviceCodeError(t *testing.T) {
+	ngsi := testNgsiLibInit()
+	ngsi.TimeLib = &MockTimeLib{unixTime: 1000}
+	ngsi.JSONConverter = &MockJSONLib{EncodeErr: [5]error{errors.New("json error")}, DecodeErr: [5]error{errors.New("json error")}}
+
+	idm := &idmOAuth2DeviceCode{}
+	tokenInfo := &TokenInfo{OAuth2: &OAuth2Token{AccessToken: "access"}}
+
+	_, err := idm.getTokenInfo(tokenInfo)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 1, ngsiErr.ErrNo)
+		assert.Equal(t, "json error", ngsiErr.Message)
+	}
+}
+
+func TestCheckIdmParamsOAuth2DeviceCode(t *testing.T) {
+	idm := &idmOAuth2DeviceCode{}
+	idmParams := &IdmParams{
+		IdmHost:  "http://keycloak:8080/realms/fiware",
+		ClientID: "00000000-1111-2222-3333-444444444444",
+	}
+
+	err := idm.checkIdmParams(idmParams)
+
+	assert.NoError(t, err)
+}
+
+func TestCheckIdmParamsOAuth2DeviceCodeError(t *testing.T) {
+	idm := &idmOAuth2DeviceCode{}
+	idmParams := &IdmParams{
+		IdmHost:  "http://keycloak:8080/realms/fiware",
+		ClientID: "00000000-1111-2222-3333-444444444444",
+		Username: "fiware",
+	}
+
+	err := idm.checkIdmParams(idmParams)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 1, ngsiErr.ErrNo)
+		assert.Equal(t, "idmHost and clientID are needed", ngsiErr.Message)
+	}
+}
@@ -0,0 +1,467 @@
+/*
+MIT License
+
+Copyright (c) 2020-2024 Kazuhito Suda
+
+This file is part of NGSI Go
+
+https://github.com/lets-fiware/ngsi-go
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+
+*/
+
+package ngsilib
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/lets-fiware/ngsi-go/internal/assert"
+	"github.com/lets-fiware/ngsi-go/internal/ngsierr"
+)
+
+const testOIDCConfiguration = `{"issuer":"http://idm/realms/fiware","token_endpoint":"http://idm/realms/fiware/token","revocation_endpoint":"http://idm/realms/fiware/revoke","device_authorization_endpoint":"http://idm/realms/fiware/device"}`
+
+const testOAuth2Token = `{"access_token":"access","expires_in":300,"refresh_expires_in":1800,"refresh_token":"refresh","token_type":"Bearer","scope":"openid"}`
+
+func TestOIDCDiscovery(t *testing.T) {
+	ngsi := testNgsiLibInit()
+
+	reqRes := MockHTTPReqRes{}
+	reqRes.Res.StatusCode = http.StatusOK
+	reqRes.Path = "/realms/fiware/.well-known/openid-configuration"
+	reqRes.ResBody = []byte(testOIDCConfiguration)
+	mock := NewMockHTTP()
+	mock.ReqRes = append(mock.ReqRes, reqRes)
+	ngsi.HTTP = mock
+
+	client := &Client{Server: &Server{ServerHost: "http://orion/", IdmHost: "http://idm/realms/fiware/"}}
+
+	actual, err := oidcDiscovery(ngsi, client)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, "http://idm/realms/fiware/token", actual.TokenEndpoint)
+		assert.Equal(t, "http://idm/realms/fiware/revoke", actual.RevocationEndpoint)
+		assert.Equal(t, "http://idm/realms/fiware/device", actual.DeviceAuthorizationEndpoint)
+	}
+}
+
+func TestOIDCDiscoveryWellKnown(t *testing.T) {
+	ngsi := testNgsiLibInit()
+
+	reqRes := MockHTTPReqRes{}
+	reqRes.Res.StatusCode = http.StatusOK
+	reqRes.Path = "/realms/fiware/.well-known/openid-configuration"
+	reqRes.ResBody = []byte(testOIDCConfiguration)
+	mock := NewMockHTTP()
+	mock.ReqRes = append(mock.ReqRes, reqRes)
+	ngsi.HTTP = mock
+
+	client := &Client{Server: &Server{ServerHost: "http://orion/", IdmHost: "http://idm/realms/fiware/.well-known/openid-configuration"}}
+
+	actual, err := oidcDiscovery(ngsi, client)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, "http://idm/realms/fiware/token", actual.TokenEndpoint)
+	}
+}
+
+func TestOIDCDiscoveryCache(t *testing.T) {
+	ngsi := testNgsiLibInit()
+
+	reqRes := MockHTTPReqRes{}
+	reqRes.Res.StatusCode = http.StatusOK
+	reqRes.Path = "/realms/fiware/.well-known/openid-configuration"
+	reqRes.ResBody = []byte(testOIDCConfiguration)
+	mock := NewMockHTTP()
+	mock.ReqRes = append(mock.ReqRes, reqRes)
+	ngsi.HTTP = mock
+
+	client := &Client{Server: &Server{ServerHost: "http://orion/", IdmHost: "http://idm/realms/fiware"}}
+
+	_, err := oidcDiscovery(ngsi, client)
+	assert.NoError(t, err)
+
+	client = &Client{Server: &Server{ServerHost: "http://orion2/", IdmHost: "http://idm/realms/fiware/.well-known/openid-configuration"}}
+
+	actual, err := oidcDiscovery(ngsi, client)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, "http://idm/realms/fiware/token", actual.TokenEndpoint)
+		assert.Equal(t, 1, mock.index)
+	}
+}
+
+func TestOIDCDiscoveryErrorURL(t *testing.T) {
+	ngsi := testNgsiLibInit()
+
+	client := &Client{Server: &Server{ServerHost: "http://orion/", IdmHost: "http://idm/\x7f"}}
+
+	_, err := oidcDiscovery(ngsi, client)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 1, ngsiErr.ErrNo)
+	}
+}
+
+func TestOIDCDiscoveryErrorHTTP(t *testing.T) {
+	ngsi := testNgsiLibInit()
+
+	reqRes := MockHTTPReqRes{}
+	reqRes.Err = errors.New("http error")
+	mock := NewMockHTTP()
+	mock.ReqRes = append(mock.ReqRes, reqRes)
+	ngsi.HTTP = mock
+
+	client := &Client{Server: &Server{ServerHost: "http://orion/", IdmHost: "http://idm/realms/fiware"}}
+
+	_, err := oidcDiscovery(ngsi, client)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 2, ngsiErr.ErrNo)
+		assert.Equal(t, "http error", ngsiErr.Message)
+	}
+}
+
+func TestOIDCDiscoveryErrorStatus(t *testing.T) {
+	ngsi := testNgsiLibInit()
+
+	reqRes := MockHTTPReqRes{}
+	reqRes.Res.StatusCode = http.StatusNotFound
+	reqRes.Res.Status = "404 Not Found"
+	reqRes.ResBody = []byte("not found")
+	mock := NewMockHTTP()
+	mock.ReqRes = append(mock.ReqRes, reqRes)
+	ngsi.HTTP = mock
+
+	client := &Client{Server: &Server{ServerHost: "http://orion/", IdmHost: "http://idm/realms/fiware"}}
+
+	_, err := oidcDiscovery(ngsi, client)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 3, ngsiErr.ErrNo)
+		assert.Equal(t, "error 404 Not Found not found", ngsiErr.Message)
+	}
+}
+
+func TestOIDCDiscoveryErrorJSON(t *testing.T) {
+	ngsi := testNgsiLibInit()
+
+	reqRes := MockHTTPReqRes{}
+	reqRes.Res.StatusCode = http.StatusOK
+	reqRes.ResBody = []byte(`{`)
+	mock := NewMockHTTP()
+	mock.ReqRes = append(mock.ReqRes, reqRes)
+	ngsi.HTTP = mock
+
+	client := &Client{Server: &Server{ServerHost: "http://orion/", IdmHost: "http://idm/realms/fiware"}}
+
+	_, err := oidcDiscovery(ngsi, client)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 4, ngsiErr.ErrNo)
+	}
+}
+
+func TestOIDCDiscoveryErrorTokenEndpoint(t *testing.T) {
+	ngsi := testNgsiLibInit()
+
+	reqRes := MockHTTPReqRes{}
+	reqRes.Res.StatusCode = http.StatusOK
+	reqRes.ResBody = []byte(`{"issuer":"http://idm/realms/fiware"}`)
+	mock := NewMockHTTP()
+	mock.ReqRes = append(mock.ReqRes, reqRes)
+	ngsi.HTTP = mock
+
+	client := &Client{Server: &Server{ServerHost: "http://orion/", IdmHost: "http://idm/realms/fiware"}}
+
+	_, err := oidcDiscovery(ngsi, client)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 5, ngsiErr.ErrNo)
+		assert.Equal(t, "token_endpoint not found in http://idm/realms/fiware/.well-known/openid-configuration", ngsiErr.Message)
+	}
+}
+
+func TestOAuth2Post(t *testing.T) {
+	ngsi := testNgsiLibInit()
+
+	reqRes := MockHTTPReqRes{}
+	reqRes.Res.StatusCode = http.StatusOK
+	reqRes.ReqData = []byte("client_id=0000&grant_type=client_credentials")
+	reqRes.ResBody = []byte(testOAuth2Token)
+	mock := NewMockHTTP()
+	mock.ReqRes = append(mock.ReqRes, reqRes)
+	ngsi.HTTP = mock
+
+	client := &Client{Server: &Server{ServerHost: "http://orion/"}}
+	values := url.Values{"grant_type": {"client_credentials"}, "client_id": {"0000"}}
+
+	res, body, err := oauth2Post(ngsi, client, "http://idm/token", values)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Equal(t, testOAuth2Token, string(body))
+	}
+}
+
+func TestOAuth2PostErrorURL(t *testing.T) {
+	ngsi := testNgsiLibInit()
+
+	client := &Client{Server: &Server{ServerHost: "http://orion/"}}
+
+	_, _, err := oauth2Post(ngsi, client, "http://idm/\x7f", url.Values{})
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 1, ngsiErr.ErrNo)
+	}
+}
+
+func TestOAuth2PostErrorHTTP(t *testing.T) {
+	ngsi := testNgsiLibInit()
+
+	reqRes := MockHTTPReqRes{}
+	reqRes.Err = errors.New("http error")
+	mock := NewMockHTTP()
+	mock.ReqRes = append(mock.ReqRes, reqRes)
+	ngsi.HTTP = mock
+
+	client := &Client{Server: &Server{ServerHost: "http://orion/"}}
+
+	_, _, err := oauth2Post(ngsi, client, "http://idm/token", url.Values{})
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 2, ngsiErr.ErrNo)
+		assert.Equal(t, "http error", ngsiErr.Message)
+	}
+}
+
+func TestOAuth2ClientValues(t *testing.T) {
+	actual := oauth2ClientValues(&Server{ClientID: "0000", ClientSecret: "1111"}, url.Values{})
+	assert.Equal(t, "client_id=0000&client_secret=1111", actual.Encode())
+
+	actual = oauth2ClientValues(&Server{ClientID: "0000"}, url.Values{})
+	assert.Equal(t, "client_id=0000", actual.Encode())
+}
+
+func TestNewOAuth2TokenInfo(t *testing.T) {
+	ngsi := testNgsiLibInit()
+	ngsi.TimeLib = &MockTimeLib{unixTime: 1000}
+
+	actual, err := newOAuth2TokenInfo(ngsi, COAuth2ClientCredentials, []byte(testOAuth2Token), "previous")
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, COAuth2ClientCredentials, actual.Type)
+		assert.Equal(t, "access", actual.Token)
+		assert.Equal(t, "refresh", actual.RefreshToken)
+		assert.Equal(t, int64(1300), actual.Expires.Unix())
+		assert.Equal(t, "openid", actual.OAuth2.Scope)
+	}
+}
+
+func TestNewOAuth2TokenInfoKeepRefreshToken(t *testing.T) {
+	ngsi := testNgsiLibInit()
+	ngsi.TimeLib = &MockTimeLib{unixTime: 1000}
+
+	actual, err := newOAuth2TokenInfo(ngsi, COIDC, []byte(`{"access_token":"access","expires_in":300,"token_type":"Bearer"}`), "previous")
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, "access", actual.Token)
+		assert.Equal(t, "previous", actual.RefreshToken)
+		assert.Equal(t, "previous", actual.OAuth2.RefreshToken)
+	}
+}
+
+func TestNewOAuth2TokenInfoError(t *testing.T) {
+	ngsi := testNgsiLibInit()
+
+	_, err := newOAuth2TokenInfo(ngsi, COAuth2ClientCredentials, []byte(`{`), "")
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 1, ngsiErr.ErrNo)
+	}
+}
+
+func TestOAuth2RevokeToken(t *testing.T) {
+	ngsi := testNgsiLibInit()
+
+	reqRes1 := MockHTTPReqRes{}
+	reqRes1.Res.StatusCode = http.StatusOK
+	reqRes1.ResBody = []byte(testOIDCConfiguration)
+	reqRes2 := MockHTTPReqRes{}
+	reqRes2.Res.StatusCode = http.StatusOK
+	reqRes2.Path = "/realms/fiware/revoke"
+	reqRes2.ReqData = []byte("client_id=0000&client_secret=1111&token=refresh&token_type_hint=refresh_token")
+	mock := NewMockHTTP()
+	mock.ReqRes = append(mock.ReqRes, reqRes1, reqRes2)
+	ngsi.HTTP = mock
+
+	client := &Client{Server: &Server{ServerHost: "http://orion/", IdmHost: "http://idm/realms/fiware", ClientID: "0000", ClientSecret: "1111"}}
+	tokenInfo := &TokenInfo{Token: "access", RefreshToken: "refresh"}
+
+	err := oauth2RevokeToken(ngsi, client, tokenInfo)
+
+	assert.NoError(t, err)
+}
+
+func TestOAuth2RevokeTokenAccessToken(t *testing.T) {
+	ngsi := testNgsiLibInit()
+
+	reqRes1 := MockHTTPReqRes{}
+	reqRes1.Res.StatusCode = http.StatusOK
+	reqRes1.ResBody = []byte(testOIDCConfiguration)
+	reqRes2 := MockHTTPReqRes{}
+	reqRes2.Res.StatusCode = http.StatusOK
+	reqRes2.ReqData = []byte("client_id=0000&token=access&token_type_hint=access_token")
+	mock := NewMockHTTP()
+	mock.ReqRes = append(mock.ReqRes, reqRes1, reqRes2)
+	ngsi.HTTP = mock
+
+	client := &Client{Server: &Server{ServerHost: "http://orion/", IdmHost: "http://idm/realms/fiware", ClientID: "0000"}}
+	tokenInfo := &TokenInfo{Token: "access"}
+
+	err := oauth2RevokeToken(ngsi, client, tokenInfo)
+
+	assert.NoError(t, err)
+}
+
+func TestOAuth2RevokeTokenErrorDiscovery(t *testing.T) {
+	ngsi := testNgsiLibInit()
+
+	reqRes := MockHTTPReqRes{}
+	reqRes.Err = errors.New("http error")
+	mock := NewMockHTTP()
+	mock.ReqRes = append(mock.ReqRes, reqRes)
+	ngsi.HTTP = mock
+
+	client := &Client{Server: &Server{ServerHost: "http://orion/", IdmHost: "http://idm/realms/fiware", ClientID: "0000"}}
+
+	err := oauth2RevokeToken(ngsi, client, &TokenInfo{})
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 1, ngsiErr.ErrNo)
+		assert.Equal(t, "http error", ngsiErr.Message)
+	}
+}
+
+func TestOAuth2RevokeTokenErrorNoEndpoint(t *testing.T) {
+	ngsi := testNgsiLibInit()
+
+	reqRes := MockHTTPReqRes{}
+	reqRes.Res.StatusCode = http.StatusOK
+	reqRes.ResBody = []byte(`{"token_endpoint":"http://idm/token"}`)
+	mock := NewMockHTTP()
+	mock.ReqRes = append(mock.ReqRes, reqRes)
+	ngsi.HTTP = mock
+
+	client := &Client{Server: &Server{ServerHost: "http://orion/", IdmHost: "http://idm/realms/fiware", ClientID: "0000"}}
+
+	err := oauth2RevokeToken(ngsi, client, &TokenInfo{})
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 2, ngsiErr.ErrNo)
+		assert.Equal(t, "revocation_endpoint not found", ngsiErr.Message)
+	}
+}
+
+func TestOAuth2RevokeTokenErrorHTTP(t *testing.T) {
+	ngsi := testNgsiLibInit()
+
+	reqRes1 := MockHTTPReqRes{}
+	reqRes1.Res.StatusCode = http.StatusOK
+	reqRes1.ResBody = []byte(testOIDCConfiguration)
+	reqRes2 := MockHTTPReqRes{}
+	reqRes2.Err = errors.New("http error")
+	mock := NewMockHTTP()
+	mock.ReqRes = append(mock.ReqRes, reqRes1, reqRes2)
+	ngsi.HTTP = mock
+
+	client := &Client{Server: &Server{ServerHost: "http://orion/", IdmHost: "http://idm/realms/fiware", ClientID: "0000"}}
+
+	err := oauth2RevokeToken(ngsi, client, &TokenInfo{})
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 3, ngsiErr.ErrNo)
+		assert.Equal(t, "http error", ngsiErr.Message)
+	}
+}
+
+func TestOAuth2RevokeTokenErrorStatus(t *testing.T) {
+	ngsi := testNgsiLibInit()
+
+	reqRes1 := MockHTTPReqRes{}
+	reqRes1.Res.StatusCode = http.StatusOK
+	reqRes1.ResBody = []byte(testOIDCConfiguration)
+	reqRes2 := MockHTTPReqRes{}
+	reqRes2.Res.StatusCode = http.StatusBadRequest
+	reqRes2.Res.Status = "400 Bad Request"
+	reqRes2.ResBody = []byte(`{"error":"invalid_client"}`)
+	mock := NewMockHTTP()
+	mock.ReqRes = append(mock.ReqRes, reqRes1, reqRes2)
+	ngsi.HTTP = mock
+
+	client := &Client{Server: &Server{ServerHost: "http://orion/", IdmHost: "http://idm/realms/fiware", ClientID: "0000"}}
+
+	err := oauth2RevokeToken(ngsi, client, &TokenInfo{})
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 4, ngsiErr.ErrNo)
+		assert.Equal(t, `error 400 Bad Request {"error":"invalid_client"}`, ngsiErr.Message)
+	}
+}
+
+func TestOAuth2TokenInfo(t *testing.T) {
+	tokenInfo := &TokenInfo{OAuth2: &OAuth2Token{AccessToken: "access", ExpiresIn: 300, TokenType: "Bearer"}}
+
+	actual, err := oauth2TokenInfo(tokenInfo)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, `{"access_token":"access","expires_in":300,"token_type":"Bearer"}`, string(actual))
+	}
+}
+
+func TestOAuth2TokenInfoError(t *testing.T) {
+	ngsi := testNgsiLibInit()
+	ngsi.JSONConverter = &MockJSONLib{EncodeErr: [5]error{errors.New("json error")}, DecodeErr: [5]error{errors.New("json error")}}
+
+	tokenInfo := &TokenInfo{OAuth2: &OAuth2Token{AccessToken: "access"}}
+
+	_, err := oauth2TokenInfo(tokenInfo)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 1, ngsiErr.ErrNo)
+		assert.Equal(t, "json error", ngsiErr.Message)
+	}
+}
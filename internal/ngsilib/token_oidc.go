@@ -0,0 +1,265 @@
+/*
+MIT License
+
+Copyright (c) 2020-2024 Kazuhito Suda
+
+This file is part of NGSI Go
+
+https://github.com/lets-fiware/ngsi-go
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+
+*/
+
+package ngsilib
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/lets-fiware/ngsi-go/internal/ngsierr"
+)
+
+// idmOIDC gets a token from an OpenID Connect provider with the authorization code flow and PKCE.
+// The authorization response is received by a listener on the loopback interface.
+type idmOIDC struct {
+}
+
+const (
+	oidcCallbackPath = "/callback"
+	oidcDefaultScope = "openid"
+)
+
+var (
+	oidcRand    io.Reader = rand.Reader
+	oidcTimeout           = 5 * time.Minute
+)
+
+type oidcAuthorizationResponse struct {
+	code string
+	err  error
+}
+
+func (i *idmOIDC) requestToken(ngsi *NGSI, client *Client, tokenInfo *TokenInfo) (*TokenInfo, error) {
+	const funcName = "requestTokenOIDC"
+
+	oidc, err := oidcDiscovery(ngsi, client)
+	if err != nil {
+		return nil, ngsierr.New(funcName, 1, err.Error(), err)
+	}
+
+	if tokenInfo.RefreshToken != "" {
+		res, body, err := oauth2RefreshToken(ngsi, client, oidc.TokenEndpoint, tokenInfo.RefreshToken)
+		if err != nil {
+			return nil, ngsierr.New(funcName, 2, err.Error(), err)
+		}
+		if res.StatusCode == http.StatusOK {
+			tokenInfo, err := newOAuth2TokenInfo(ngsi, COIDC, body, tokenInfo.RefreshToken)
+			if err != nil {
+				return nil, ngsierr.New(funcName, 3, err.Error(), err)
+			}
+			return tokenInfo, nil
+		}
+		gNGSI.Logging(LogInfo, fmt.Sprintf("%s %d\n", funcName, res.StatusCode))
+	}
+
+	if oidc.AuthorizationEndpoint == "" {
+		return nil, ngsierr.New(funcName, 4, "authorization_endpoint not found", nil)
+	}
+
+	code, redirectURI, verifier, err := oidcAuthorize(ngsi, client.Server, oidc.AuthorizationEndpoint)
+	if err != nil {
+		return nil, ngsierr.New(funcName, 5, err.Error(), err)
+	}
+
+	values := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {redirectURI},
+		"code_verifier": {verifier},
+	}
+
+	res, body, err := oauth2Post(ngsi, client, oidc.TokenEndpoint, oauth2ClientValues(client.Server, values))
+	if err != nil {
+		return nil, ngsierr.New(funcName, 6, err.Error(), err)
+	}
+	if res.StatusCode != http.StatusOK {
+		return nil, ngsierr.New(funcName, 7, fmt.Sprintf("error %s %s", res.Status, string(body)), nil)
+	}
+
+	tokenInfo, err = newOAuth2TokenInfo(ngsi, COIDC, body, "")
+	if err != nil {
+		return nil, ngsierr.New(funcName, 8, err.Error(), err)
+	}
+
+	return tokenInfo, nil
+}
+
+// oidcAuthorize asks the user to sign in with a browser and waits for the authorization code
+func oidcAuthorize(ngsi *NGSI, server *Server, endpoint string) (string, string, string, error) {
+	const funcName = "oidcAuthorize"
+
+	verifier, err := oidcRandomString(32)
+	if err != nil {
+		return "", "", "", ngsierr.New(funcName, 1, err.Error(), err)
+	}
+	state, err := oidcRandomString(16)
+	if err != nil {
+		return "", "", "", ngsierr.New(funcName, 2, err.Error(), err)
+	}
+
+	authURL, err := url.Parse(endpoint)
+	if err != nil {
+		return "", "", "", ngsierr.New(funcName, 3, err.Error(), err)
+	}
+
+	listener, err := ngsi.NetLib.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return "", "", "", ngsierr.New(funcName, 4, err.Error(), err)
+	}
+	redirectURI := "http://" + listener.Addr().String() + oidcCallbackPath
+
+	scope := server.TokenScope
+	if scope == "" {
+		scope = oidcDefaultScope
+	}
+	challenge := sha256.Sum256([]byte(verifier))
+
+	q := authURL.Query()
+	q.Set("response_type", "code")
+	q.Set("client_id", server.ClientID)
+	q.Set("redirect_uri", redirectURI)
+	q.Set("scope", scope)
+	q.Set("state", state)
+	q.Set("code_challenge", base64.RawURLEncoding.EncodeToString(challenge[:]))
+	q.Set("code_challenge_method", "S256")
+	authURL.RawQuery = q.Encode()
+
+	ch := make(chan oidcAuthorizationResponse, 1)
+	srv := &http.Server{Handler: &oidcCallbackHandler{state: state, ch: ch}}
+	go func() { _ = srv.Serve(listener) }()
+	defer func() { _ = srv.Close() }()
+
+	fmt.Fprintf(ngsi.Stderr, "Open the following URL in a browser to sign in:\n%s\n", authURL.String())
+
+	select {
+	case r := <-ch:
+		if r.err != nil {
+			return "", "", "", ngsierr.New(funcName, 5, r.err.Error(), r.err)
+		}
+		return r.code, redirectURI, verifier, nil
+	case <-time.After(oidcTimeout):
+		return "", "", "", ngsierr.New(funcName, 6, "timed out waiting for the authorization response", nil)
+	}
+}
+
+func oidcRandomString(n int) (string, error) {
+	const funcName = "oidcRandomString"
+
+	b := make([]byte, n)
+	if _, err := io.ReadFull(oidcRand, b); err != nil {
+		return "", ngsierr.New(funcName, 1, err.Error(), err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+type oidcCallbackHandler struct {
+	state string
+	ch    chan oidcAuthorizationResponse
+	once  sync.Once
+}
+
+func (h *oidcCallbackHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	const funcName = "oidcCallback"
+
+	if r.URL.Path != oidcCallbackPath {
+		http.NotFound(w, r)
+		return
+	}
+
+	q := r.URL.Query()
+	var res oidcAuthorizationResponse
+
+	switch {
+	case q.Get("error") != "":
+		res.err = ngsierr.New(funcName, 1, fmt.Sprintf("%s %s", q.Get("error"), q.Get("error_description")), nil)
+	case q.Get("state") != h.state:
+		res.err = ngsierr.New(funcName, 2, "state mismatch", nil)
+	case q.Get("code") == "":
+		res.err = ngsierr.New(funcName, 3, "authorization code not found", nil)
+	default:
+		res.code = q.Get("code")
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if res.err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte("<html><body>Sign-in failed. You can close this window.</body></html>"))
+	} else {
+		_, _ = w.Write([]byte("<html><body>Signed in. You can close this window.</body></html>"))
+	}
+
+	h.once.Do(func() { h.ch <- res })
+}
+
+func (i *idmOIDC) revokeToken(ngsi *NGSI, client *Client, tokenInfo *TokenInfo) error {
+	const funcName = "revokeTokenOIDC"
+
+	if err := oauth2RevokeToken(ngsi, client, tokenInfo); err != nil {
+		return ngsierr.New(funcName, 1, err.Error(), err)
+	}
+
+	return nil
+}
+
+func (i *idmOIDC) getAuthHeader(token string) (string, string) {
+	return "Authorization", "Bearer " + token
+}
+
+func (i *idmOIDC) getTokenInfo(tokenInfo *TokenInfo) ([]byte, error) {
+	const funcName = "getTokenInfoOIDC"
+
+	b, err := oauth2TokenInfo(tokenInfo)
+	if err != nil {
+		return nil, ngsierr.New(funcName, 1, err.Error(), err)
+	}
+	return b, nil
+}
+
+func (i *idmOIDC) checkIdmParams(idmParams *IdmParams) error {
+	const funcName = "checkIdmParamsOIDC"
+
+	if idmParams.IdmHost != "" &&
+		idmParams.Username == "" &&
+		idmParams.Password == "" &&
+		idmParams.ClientID != "" &&
+		idmParams.HeaderName == "" &&
+		idmParams.HeaderValue == "" &&
+		idmParams.HeaderEnvValue == "" {
+		return nil
+	}
+	return ngsierr.New(funcName, 1, "idmHost and clientID are needed", nil)
+}
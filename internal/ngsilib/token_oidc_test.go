@@ -0,0 +1,654 @@
+/*
+MIT License
+
+Copyright (c) 2020-2024 Kazuhito Suda
+
+This file is part of NGSI Go
+
+https://github.com/lets-fiware/ngsi-go
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+
+*/
+
+package ngsilib
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lets-fiware/ngsi-go/internal/assert"
+	"github.com/lets-fiware/ngsi-go/internal/ngsierr"
+)
+
+const testOIDCDiscovery = `{"issuer":"http://idm/realms/fiware","authorization_endpoint":"http://idm/realms/fiware/auth","token_endpoint":"http://idm/realms/fiware/token","revocation_endpoint":"http://idm/realms/fiware/revoke"}`
+
+// testOIDCBrowser plays the role of a user signing in with a browser.
+// It reads the authorization URL printed by oidcAuthorize and calls the redirect URI.
+type testOIDCBrowser struct {
+	buf    bytes.Buffer
+	params func(q url.Values) url.Values
+	query  url.Values
+	done   chan struct{}
+}
+
+func newTestOIDCBrowser(params func(q url.Values) url.Values) *testOIDCBrowser {
+	return &testOIDCBrowser{params: params, done: make(chan struct{})}
+}
+
+func (b *testOIDCBrowser) Write(p []byte) (int, error) {
+	b.buf.Write(p)
+	lines := strings.Split(strings.TrimSpace(b.buf.String()), "\n")
+	u, err := url.Parse(lines[len(lines)-1])
+	if err != nil || u.Scheme == "" {
+		return len(p), nil
+	}
+	b.query = u.Query()
+	if b.params != nil {
+		go func() {
+			defer close(b.done)
+			res, err := http.Get(b.query.Get("redirect_uri") + "?" + b.params(b.query).Encode())
+			if err == nil {
+				_ = res.Body.Close()
+			}
+		}()
+	}
+	return len(p), nil
+}
+
+func testOIDCCode(q url.Values) url.Values {
+	return url.Values{"code": {"code1"}, "state": {q.Get("state")}}
+}
+
+func testOIDCDiscoveryReqRes() MockHTTPReqRes {
+	reqRes := MockHTTPReqRes{}
+	reqRes.Res.StatusCode = http.StatusOK
+	reqRes.ResBody = []byte(testOIDCDiscovery)
+	return reqRes
+}
+
+type testOIDCNetLib struct {
+	NetLib
+	err error
+}
+
+func (n *testOIDCNetLib) Listen(network, address string) (net.Listener, error) {
+	return nil, n.err
+}
+
+type testOIDCErrReader struct{}
+
+func (r *testOIDCErrReader) Read(p []byte) (int, error) {
+	return 0, errors.New("rand error")
+}
+
+func TestRequestTokenOIDC(t *testing.T) {
+	ngsi := testNgsiLibInit()
+	browser := newTestOIDCBrowser(testOIDCCode)
+	ngsi.Stderr = browser
+
+	reqRes := MockHTTPReqRes{}
+	reqRes.Res.StatusCode = http.StatusOK
+	reqRes.Path = "/realms/fiware/token"
+	reqRes.ResBody = []byte(testOAuth2Token)
+	mock := NewMockHTTP()
+	mock.ReqRes = append(mock.ReqRes, testOIDCDiscoveryReqRes(), reqRes)
+	ngsi.HTTP = mock
+
+	client := &Client{Server: &Server{ServerHost: "http://orion/", IdmType: COIDC, IdmHost: "http://idm/realms/fiware", ClientID: "0000"}}
+	idm := &idmOIDC{}
+
+	actual, err := idm.requestToken(ngsi, client, &TokenInfo{})
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, COIDC, actual.Type)
+		assert.Equal(t, "access", actual.Token)
+		assert.Equal(t, "refresh", actual.RefreshToken)
+		<-browser.done
+		assert.Equal(t, "code", browser.query.Get("response_type"))
+		assert.Equal(t, "0000", browser.query.Get("client_id"))
+		assert.Equal(t, "openid", browser.query.Get("scope"))
+		assert.Equal(t, "S256", browser.query.Get("code_challenge_method"))
+		assert.Equal(t, true, strings.HasPrefix(browser.query.Get("redirect_uri"), "http://127.0.0.1:"))
+		assert.Equal(t, true, strings.HasSuffix(browser.query.Get("redirect_uri"), "/callback"))
+	}
+}
+
+func TestRequestTokenOIDCRefresh(t *testing.T) {
+	ngsi := testNgsiLibInit()
+
+	reqRes := MockHTTPReqRes{}
+	reqRes.Res.StatusCode = http.StatusOK
+	reqRes.ReqData = []byte("client_id=0000&grant_type=refresh_token&refresh_token=refresh")
+	reqRes.ResBody = []byte(testOAuth2Token)
+	mock := NewMockHTTP()
+	mock.ReqRes = append(mock.ReqRes, testOIDCDiscoveryReqRes(), reqRes)
+	ngsi.HTTP = mock
+
+	client := &Client{Server: &Server{ServerHost: "http://orion/", IdmType: COIDC, IdmHost: "http://idm/realms/fiware", ClientID: "0000"}}
+	idm := &idmOIDC{}
+
+	actual, err := idm.requestToken(ngsi, client, &TokenInfo{RefreshToken: "refresh"})
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, "access", actual.Token)
+	}
+}
+
+func TestRequestTokenOIDCRefreshKeepRefreshToken(t *testing.T) {
+	ngsi := testNgsiLibInit()
+
+	reqRes := MockHTTPReqRes{}
+	reqRes.Res.StatusCode = http.StatusOK
+	reqRes.ReqData = []byte("client_id=0000&grant_type=refresh_token&refresh_token=refresh")
+	reqRes.ResBody = []byte(`{"access_token":"access2","expires_in":300,"token_type":"Bearer"}`)
+	mock := NewMockHTTP()
+	mock.ReqRes = append(mock.ReqRes, testOIDCDiscoveryReqRes(), reqRes)
+	ngsi.HTTP = mock
+
+	client := &Client{Server: &Server{ServerHost: "http://orion/", IdmType: COIDC, IdmHost: "http://idm/realms/fiware", ClientID: "0000"}}
+	idm := &idmOIDC{}
+
+	actual, err := idm.requestToken(ngsi, client, &TokenInfo{RefreshToken: "refresh"})
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, "access2", actual.Token)
+		assert.Equal(t, "refresh", actual.RefreshToken)
+	}
+}
+
+func TestRequestTokenOIDCRefreshFallback(t *testing.T) {
+	ngsi := testNgsiLibInit()
+	buf := &bytes.Buffer{}
+	ngsi.LogWriter = buf
+	ngsi.Stderr = newTestOIDCBrowser(testOIDCCode)
+
+	reqRes1 := MockHTTPReqRes{}
+	reqRes1.Res.StatusCode = http.StatusBadRequest
+	reqRes1.ResBody = []byte(`{"error":"invalid_grant"}`)
+	reqRes2 := MockHTTPReqRes{}
+	reqRes2.Res.StatusCode = http.StatusOK
+	reqRes2.ResBody = []byte(testOAuth2Token)
+	mock := NewMockHTTP()
+	mock.ReqRes = append(mock.ReqRes, testOIDCDiscoveryReqRes(), reqRes1, reqRes2)
+	ngsi.HTTP = mock
+
+	client := &Client{Server: &Server{ServerHost: "http://orion/", IdmType: COIDC, IdmHost: "http://idm/realms/fiware", ClientID: "0000"}}
+	idm := &idmOIDC{}
+
+	actual, err := idm.requestToken(ngsi, client, &TokenInfo{RefreshToken: "refresh"})
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, "access", actual.Token)
+	}
+}
+
+func TestRequestTokenOIDCErrorDiscovery(t *testing.T) {
+	ngsi := testNgsiLibInit()
+
+	reqRes := MockHTTPReqRes{}
+	reqRes.Err = errors.New("http error")
+	mock := NewMockHTTP()
+	mock.ReqRes = append(mock.ReqRes, reqRes)
+	ngsi.HTTP = mock
+
+	client := &Client{Server: &Server{ServerHost: "http://orion/", IdmType: COIDC, IdmHost: "http://idm/realms/fiware", ClientID: "0000"}}
+	idm := &idmOIDC{}
+
+	_, err := idm.requestToken(ngsi, client, &TokenInfo{})
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 1, ngsiErr.ErrNo)
+		assert.Equal(t, "http error", ngsiErr.Message)
+	}
+}
+
+func TestRequestTokenOIDCErrorRefreshHTTP(t *testing.T) {
+	ngsi := testNgsiLibInit()
+
+	reqRes := MockHTTPReqRes{}
+	reqRes.Err = errors.New("http error")
+	mock := NewMockHTTP()
+	mock.ReqRes = append(mock.ReqRes, testOIDCDiscoveryReqRes(), reqRes)
+	ngsi.HTTP = mock
+
+	client := &Client{Server: &Server{ServerHost: "http://orion/", IdmType: COIDC, IdmHost: "http://idm/realms/fiware", ClientID: "0000"}}
+	idm := &idmOIDC{}
+
+	_, err := idm.requestToken(ngsi, client, &TokenInfo{RefreshToken: "refresh"})
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 2, ngsiErr.ErrNo)
+		assert.Equal(t, "http error", ngsiErr.Message)
+	}
+}
+
+func TestRequestTokenOIDCErrorRefreshJSON(t *testing.T) {
+	ngsi := testNgsiLibInit()
+
+	reqRes := MockHTTPReqRes{}
+	reqRes.Res.StatusCode = http.StatusOK
+	reqRes.ResBody = []byte(`{`)
+	mock := NewMockHTTP()
+	mock.ReqRes = append(mock.ReqRes, testOIDCDiscoveryReqRes(), reqRes)
+	ngsi.HTTP = mock
+
+	client := &Client{Server: &Server{ServerHost: "http://orion/", IdmType: COIDC, IdmHost: "http://idm/realms/fiware", ClientID: "0000"}}
+	idm := &idmOIDC{}
+
+	_, err := idm.requestToken(ngsi, client, &TokenInfo{RefreshToken: "refresh"})
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 3, ngsiErr.ErrNo)
+	}
+}
+
+func TestRequestTokenOIDCErrorAuthorizationEndpoint(t *testing.T) {
+	ngsi := testNgsiLibInit()
+
+	mock := NewMockHTTP()
+	mock.ReqRes = append(mock.ReqRes, testOAuth2Discovery())
+	ngsi.HTTP = mock
+
+	client := &Client{Server: &Server{ServerHost: "http://orion/", IdmType: COIDC, IdmHost: "http://idm/realms/fiware", ClientID: "0000"}}
+	idm := &idmOIDC{}
+
+	_, err := idm.requestToken(ngsi, client, &TokenInfo{})
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 4, ngsiErr.ErrNo)
+		assert.Equal(t, "authorization_endpoint not found", ngsiErr.Message)
+	}
+}
+
+func TestRequestTokenOIDCErrorAuthorize(t *testing.T) {
+	ngsi := testNgsiLibInit()
+	ngsi.NetLib = &testOIDCNetLib{err: errors.New("listen error")}
+
+	mock := NewMockHTTP()
+	mock.ReqRes = append(mock.ReqRes, testOIDCDiscoveryReqRes())
+	ngsi.HTTP = mock
+
+	client := &Client{Server: &Server{ServerHost: "http://orion/", IdmType: COIDC, IdmHost: "http://idm/realms/fiware", ClientID: "0000"}}
+	idm := &idmOIDC{}
+
+	_, err := idm.requestToken(ngsi, client, &TokenInfo{})
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 5, ngsiErr.ErrNo)
+		assert.Equal(t, "listen error", ngsiErr.Message)
+	}
+}
+
+func TestRequestTokenOIDCErrorHTTP(t *testing.T) {
+	ngsi := testNgsiLibInit()
+	ngsi.Stderr = newTestOIDCBrowser(testOIDCCode)
+
+	reqRes := MockHTTPReqRes{}
+	reqRes.Err = errors.New("http error")
+	mock := NewMockHTTP()
+	mock.ReqRes = append(mock.ReqRes, testOIDCDiscoveryReqRes(), reqRes)
+	ngsi.HTTP = mock
+
+	client := &Client{Server: &Server{ServerHost: "http://orion/", IdmType: COIDC, IdmHost: "http://idm/realms/fiware", ClientID: "0000"}}
+	idm := &idmOIDC{}
+
+	_, err := idm.requestToken(ngsi, client, &TokenInfo{})
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 6, ngsiErr.ErrNo)
+		assert.Equal(t, "http error", ngsiErr.Message)
+	}
+}
+
+func TestRequestTokenOIDCErrorStatus(t *testing.T) {
+	ngsi := testNgsiLibInit()
+	ngsi.Stderr = newTestOIDCBrowser(testOIDCCode)
+
+	reqRes := MockHTTPReqRes{}
+	reqRes.Res.StatusCode = http.StatusBadRequest
+	reqRes.Res.Status = "400 Bad Request"
+	reqRes.ResBody = []byte(`{"error":"invalid_grant"}`)
+	mock := NewMockHTTP()
+	mock.ReqRes = append(mock.ReqRes, testOIDCDiscoveryReqRes(), reqRes)
+	ngsi.HTTP = mock
+
+	client := &Client{Server: &Server{ServerHost: "http://orion/", IdmType: COIDC, IdmHost: "http://idm/realms/fiware", ClientID: "0000"}}
+	idm := &idmOIDC{}
+
+	_, err := idm.requestToken(ngsi, client, &TokenInfo{})
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 7, ngsiErr.ErrNo)
+		assert.Equal(t, `error 400 Bad Request {"error":"invalid_grant"}`, ngsiErr.Message)
+	}
+}
+
+func TestRequestTokenOIDCErrorJSON(t *testing.T) {
+	ngsi := testNgsiLibInit()
+	ngsi.Stderr = newTestOIDCBrowser(testOIDCCode)
+
+	reqRes := MockHTTPReqRes{}
+	reqRes.Res.StatusCode = http.StatusOK
+	reqRes.ResBody = []byte(`{`)
+	mock := NewMockHTTP()
+	mock.ReqRes = append(mock.ReqRes, testOIDCDiscoveryReqRes(), reqRes)
+	ngsi.HTTP = mock
+
+	client := &Client{Server: &Server{ServerHost: "http://orion/", IdmType: COIDC, IdmHost: "http://idm/realms/fiware", ClientID: "0000"}}
+	idm := &idmOIDC{}
+
+	_, err := idm.requestToken(ngsi, client, &TokenInfo{})
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 8, ngsiErr.ErrNo)
+	}
+}
+
+func TestOIDCAuthorize(t *testing.T) {
+	ngsi := testNgsiLibInit()
+	browser := newTestOIDCBrowser(testOIDCCode)
+	ngsi.Stderr = browser
+	oidcRand = bytes.NewReader(make([]byte, 48))
+	defer func() { oidcRand = testOIDCRand }()
+
+	server := &Server{ClientID: "0000", TokenScope: "openid profile"}
+
+	code, redirectURI, verifier, err := oidcAuthorize(ngsi, server, "http://idm/auth?prompt=login")
+
+	if assert.NoError(t, err) {
+		<-browser.done
+		assert.Equal(t, "code1", code)
+		assert.Equal(t, browser.query.Get("redirect_uri"), redirectURI)
+		assert.Equal(t, "AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA", verifier)
+		assert.Equal(t, "DwBzhbb51LfusnSGBa_hqYSgo7-j8BTQnip4TOnlzRo", browser.query.Get("code_challenge"))
+		assert.Equal(t, "openid profile", browser.query.Get("scope"))
+		assert.Equal(t, "login", browser.query.Get("prompt"))
+	}
+}
+
+var testOIDCRand = oidcRand
+
+func TestOIDCAuthorizeErrorVerifier(t *testing.T) {
+	ngsi := testNgsiLibInit()
+	oidcRand = &testOIDCErrReader{}
+	defer func() { oidcRand = testOIDCRand }()
+
+	_, _, _, err := oidcAuthorize(ngsi, &Server{ClientID: "0000"}, "http://idm/auth")
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 1, ngsiErr.ErrNo)
+		assert.Equal(t, "rand error", ngsiErr.Message)
+	}
+}
+
+func TestOIDCAuthorizeErrorState(t *testing.T) {
+	ngsi := testNgsiLibInit()
+	oidcRand = io.MultiReader(bytes.NewReader(make([]byte, 32)), &testOIDCErrReader{})
+	defer func() { oidcRand = testOIDCRand }()
+
+	_, _, _, err := oidcAuthorize(ngsi, &Server{ClientID: "0000"}, "http://idm/auth")
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 2, ngsiErr.ErrNo)
+		assert.Equal(t, "rand error", ngsiErr.Message)
+	}
+}
+
+func TestOIDCAuthorizeErrorURL(t *testing.T) {
+	ngsi := testNgsiLibInit()
+
+	_, _, _, err := oidcAuthorize(ngsi, &Server{ClientID: "0000"}, ":")
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 3, ngsiErr.ErrNo)
+		assert.Equal(t, "parse \":\": missing protocol scheme", ngsiErr.Message)
+	}
+}
+
+func TestOIDCAuthorizeErrorListen(t *testing.T) {
+	ngsi := testNgsiLibInit()
+	ngsi.NetLib = &testOIDCNetLib{err: errors.New("listen error")}
+
+	_, _, _, err := oidcAuthorize(ngsi, &Server{ClientID: "0000"}, "http://idm/auth")
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 4, ngsiErr.ErrNo)
+		assert.Equal(t, "listen error", ngsiErr.Message)
+	}
+}
+
+func TestOIDCAuthorizeErrorCallback(t *testing.T) {
+	ngsi := testNgsiLibInit()
+	ngsi.Stderr = newTestOIDCBrowser(func(q url.Values) url.Values {
+		return url.Values{"error": {"access_denied"}, "error_description": {"denied"}, "state": {q.Get("state")}}
+	})
+
+	_, _, _, err := oidcAuthorize(ngsi, &Server{ClientID: "0000"}, "http://idm/auth")
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 5, ngsiErr.ErrNo)
+		assert.Equal(t, "access_denied denied", ngsiErr.Message)
+	}
+}
+
+func TestOIDCAuthorizeErrorTimeout(t *testing.T) {
+	ngsi := testNgsiLibInit()
+	ngsi.Stderr = newTestOIDCBrowser(nil)
+	oidcTimeout = 10 * time.Millisecond
+	defer func() { oidcTimeout = 5 * time.Minute }()
+
+	_, _, _, err := oidcAuthorize(ngsi, &Server{ClientID: "0000"}, "http://idm/auth")
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 6, ngsiErr.ErrNo)
+		assert.Equal(t, "timed out waiting for the authorization response", ngsiErr.Message)
+	}
+}
+
+func TestOIDCRandomString(t *testing.T) {
+	oidcRand = bytes.NewReader([]byte{0xff, 0xff, 0xff})
+	defer func() { oidcRand = testOIDCRand }()
+
+	actual, err := oidcRandomString(3)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, "____", actual)
+	}
+}
+
+func TestOIDCCallbackHandler(t *testing.T) {
+	cases := []struct {
+		path     string
+		status   int
+		code     string
+		errMsg   string
+		received bool
+	}{
+		{path: "/callback?code=code1&state=s1", status: http.StatusOK, code: "code1", received: true},
+		{path: "/callback?error=access_denied&state=s1", status: http.StatusBadRequest, errMsg: "access_denied ", received: true},
+		{path: "/callback?code=code1&state=s2", status: http.StatusBadRequest, errMsg: "state mismatch", received: true},
+		{path: "/callback?state=s1", status: http.StatusBadRequest, errMsg: "authorization code not found", received: true},
+		{path: "/favicon.ico", status: http.StatusNotFound},
+	}
+
+	for _, c := range cases {
+		ch := make(chan oidcAuthorizationResponse, 1)
+		h := &oidcCallbackHandler{state: "s1", ch: ch}
+		w := &testOIDCResponseWriter{header: http.Header{}}
+		r, _ := http.NewRequest(http.MethodGet, "http://127.0.0.1"+c.path, nil)
+
+		h.ServeHTTP(w, r)
+
+		assert.Equal(t, c.status, w.status)
+		if c.received {
+			res := <-ch
+			assert.Equal(t, c.code, res.code)
+			if c.errMsg != "" {
+				assert.Equal(t, c.errMsg, res.err.(*ngsierr.NgsiError).Message)
+			}
+		} else {
+			assert.Equal(t, 0, len(ch))
+		}
+	}
+}
+
+type testOIDCResponseWriter struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (w *testOIDCResponseWriter) Header() http.Header { return w.header }
+
+func (w *testOIDCResponseWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return w.body.Write(b)
+}
+
+func (w *testOIDCResponseWriter) WriteHeader(status int) { w.status = status }
+
+func TestRevokeTokenOIDC(t *testing.T) {
+	ngsi := testNgsiLibInit()
+
+	reqRes := MockHTTPReqRes{}
+	reqRes.Res.StatusCode = http.StatusOK
+	reqRes.Path = "/realms/fiware/revoke"
+	reqRes.ReqData = []byte("client_id=0000&token=refresh&token_type_hint=refresh_token")
+	mock := NewMockHTTP()
+	mock.ReqRes = append(mock.ReqRes, testOIDCDiscoveryReqRes(), reqRes)
+	ngsi.HTTP = mock
+
+	client := &Client{Server: &Server{ServerHost: "http://orion/", IdmType: COIDC, IdmHost: "http://idm/realms/fiware", ClientID: "0000"}}
+	idm := &idmOIDC{}
+
+	err := idm.revokeToken(ngsi, client, &TokenInfo{Token: "access", RefreshToken: "refresh"})
+
+	assert.NoError(t, err)
+}
+
+func TestRevokeTokenOIDCError(t *testing.T) {
+	ngsi := testNgsiLibInit()
+
+	reqRes := MockHTTPReqRes{}
+	reqRes.Err = errors.New("http error")
+	mock := NewMockHTTP()
+	mock.ReqRes = append(mock.ReqRes, reqRes)
+	ngsi.HTTP = mock
+
+	client := &Client{Server: &Server{ServerHost: "http://orion/", IdmType: COIDC, IdmHost: "http://idm/realms/fiware", ClientID: "0000"}}
+	idm := &idmOIDC{}
+
+	err := idm.revokeToken(ngsi, client, &TokenInfo{Token: "access"})
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 1, ngsiErr.ErrNo)
+		assert.Equal(t, "http error", ngsiErr.Message)
+	}
+}
+
+func TestGetAuthHeaderOIDC(t *testing.T) {
+	idm := &idmOIDC{}
+
+	key, value := idm.getAuthHeader("9e7067026d0aac494e8fedf66b1f585e79f52935")
+
+	assert.Equal(t, "Authorization", key)
+	assert.Equal(t, "Bearer 9e7067026d0aac494e8fedf66b1f585e79f52935", value)
+}
+
+func TestGetTokenInfoOIDC(t *testing.T) {
+	testNgsiLibInit()
+
+	tokenInfo := &TokenInfo{OAuth2: &OAuth2Token{AccessToken: "access"}}
+	idm := &idmOIDC{}
+
+	actual, err := idm.getTokenInfo(tokenInfo)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, `{"access_token":"access","expires_in":0,"token_type":""}`, string(actual))
+	}
+}
+
+func TestGetTokenInfoOIDCError(t *testing.T) {
+	ngsi := testNgsiLibInit()
+	ngsi.JSONConverter = &MockJSONLib{EncodeErr: [5]error{errors.New("json error")}}
+
+	tokenInfo := &TokenInfo{OAuth2: &OAuth2Token{AccessToken: "access"}}
+	idm := &idmOIDC{}
+
+	_, err := idm.getTokenInfo(tokenInfo)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 1, ngsiErr.ErrNo)
+		assert.Equal(t, "json error", ngsiErr.Message)
+	}
+}
+
+func TestCheckIdmParamsOIDC(t *testing.T) {
+	idm := &idmOIDC{}
+
+	cases := []struct {
+		idmParams *IdmParams
+		ok        bool
+	}{
+		{idmParams: &IdmParams{IdmHost: "http://idm", ClientID: "0000"}, ok: true},
+		{idmParams: &IdmParams{IdmHost: "http://idm", ClientID: "0000", ClientSecret: "1111"}, ok: true},
+		{idmParams: &IdmParams{ClientID: "0000"}},
+		{idmParams: &IdmParams{IdmHost: "http://idm"}},
+		{idmParams: &IdmParams{IdmHost: "http://idm", ClientID: "0000", Username: "fiware"}},
+		{idmParams: &IdmParams{IdmHost: "http://idm", ClientID: "0000", Password: "1234"}},
+		{idmParams: &IdmParams{IdmHost: "http://idm", ClientID: "0000", HeaderName: "Authorization"}},
+	}
+
+	for _, c := range cases {
+		err := idm.checkIdmParams(c.idmParams)
+		if c.ok {
+			assert.NoError(t, err)
+		} else if assert.Error(t, err) {
+			ngsiErr := err.(*ngsierr.NgsiError)
+			assert.Equal(t, 1, ngsiErr.ErrNo)
+			assert.Equal(t, "idmHost and clientID are needed", ngsiErr.Message)
+		}
+	}
+}
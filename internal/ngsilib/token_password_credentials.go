@@ -59,7 +59,7 @@ func (i *idmPasswordCredentials) requestToken(ngsi *NGSI, client *Client, tokenI
 
 	headers := make(map[string]string)
 	u, _ := url.Parse(client.idmURL())
-	idm := Client{URL: u, Headers: headers, HTTP: ngsi.HTTP}
+	idm := Client{URL: u, Headers: headers, HTTP: ngsi.serverHTTP(client.Server)}
 
 	username, password, err := getUserNamePassword(client)
 	if err != nil {
@@ -105,7 +105,7 @@ func (i *idmPasswordCredentials) revokeToken(ngsi *NGSI, client *Client, tokenIn
 		s += "/revoke"
 	}
 	u, _ := url.Parse(s)
-	idm := Client{URL: u, Headers: headers, HTTP: ngsi.HTTP}
+	idm := Client{URL: u, Headers: headers, HTTP: ngsi.serverHTTP(client.Server)}
 	broker := client.Server
 
 	idm.SetHeader(cContentType, cAppXWwwFormUrlencoded)
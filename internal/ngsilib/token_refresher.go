@@ -0,0 +1,108 @@
+/*
+MIT License
+
+Copyright (c) 2020-2024 Kazuhito Suda
+
+This file is part of NGSI Go
+
+https://github.com/lets-fiware/ngsi-go
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+
+*/
+
+package ngsilib
+
+import (
+	"sync"
+	"time"
+
+	"github.com/lets-fiware/ngsi-go/internal/ngsierr"
+)
+
+// TokenRefresher renews the token of a long-running server in the background before it expires
+type TokenRefresher struct {
+	ngsi   *NGSI
+	client *Client
+	mutex  *sync.Mutex
+	done   chan struct{}
+	once   sync.Once
+}
+
+const (
+	tokenRefresherRetry    = 30 * time.Second
+	tokenRefresherInterval = 10 * time.Second
+)
+
+var tokenRefresherAfter = time.After
+
+// StartTokenRefresher starts renewing the token of the client. The mutex must be the one which
+// guards the calls of GetToken and GetAuthHeader for the client.
+func (ngsi *NGSI) StartTokenRefresher(client *Client, mutex *sync.Mutex) *TokenRefresher {
+	r := &TokenRefresher{ngsi: ngsi, client: client, mutex: mutex, done: make(chan struct{})}
+
+	if !client.tokenRefresh {
+		return r
+	}
+
+	ngsi.Logging(LogInfo, "Start token refresher\n")
+
+	go r.run()
+
+	return r
+}
+
+// Stop stops the token refresher
+func (r *TokenRefresher) Stop() {
+	r.once.Do(func() { close(r.done) })
+}
+
+func (r *TokenRefresher) run() {
+	for {
+		select {
+		case <-r.done:
+			return
+		case <-tokenRefresherAfter(r.refresh()):
+		}
+	}
+}
+
+// refresh renews the token if it expires within the margin and returns the time until the next check
+func (r *TokenRefresher) refresh() time.Duration {
+	const funcName = "tokenRefresher"
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	token, err := r.ngsi.GetToken(r.client)
+	if err != nil {
+		r.ngsi.Logging(LogErr, ngsierr.SprintMsg(funcName, 1, err.Error())+"\n")
+		return tokenRefresherRetry
+	}
+	r.client.Token = token
+
+	expires := r.ngsi.tokenExpires(r.client)
+
+	wait := time.Duration(expires.Unix()-r.ngsi.TimeLib.NowUnix()-r.ngsi.Margin) * time.Second
+	if wait < tokenRefresherInterval {
+		wait = tokenRefresherInterval
+	}
+
+	return wait
+}
@@ -0,0 +1,140 @@
+/*
+MIT License
+
+Copyright (c) 2020-2024 Kazuhito Suda
+
+This file is part of NGSI Go
+
+https://github.com/lets-fiware/ngsi-go
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+
+*/
+
+package ngsilib
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/lets-fiware/ngsi-go/internal/assert"
+)
+
+func TestStartTokenRefresher(t *testing.T) {
+	ngsi := testNgsiLibInit()
+	ngsi.LogWriter = &bytes.Buffer{}
+	ngsi.TimeLib = &MockTimeLib{unixTime: 1000}
+	ngsi.tokenList = tokenInfoList{}
+
+	client := &Client{Server: &Server{ServerHost: "http://orion/", IdmType: CTokenproxy, Username: "fiware", Password: "1234"}, tokenRefresh: true}
+	ngsi.tokenList[getHash(client)] = TokenInfo{Token: "token", Expires: time.Unix(5000, 0)}
+
+	waits := make(chan time.Duration)
+	tokenRefresherAfter = func(d time.Duration) <-chan time.Time {
+		waits <- d
+		return make(chan time.Time)
+	}
+	defer func() { tokenRefresherAfter = time.After }()
+
+	r := ngsi.StartTokenRefresher(client, &sync.Mutex{})
+
+	assert.Equal(t, 3820*time.Second, <-waits)
+
+	r.Stop()
+	r.Stop()
+}
+
+func TestStartTokenRefresherNotRefreshable(t *testing.T) {
+	ngsi := testNgsiLibInit()
+
+	client := &Client{Server: &Server{ServerHost: "http://orion/", IdmType: CTokenproxy}, Token: "token"}
+
+	r := ngsi.StartTokenRefresher(client, &sync.Mutex{})
+
+	r.Stop()
+}
+
+func TestTokenRefresherRefresh(t *testing.T) {
+	ngsi := testNgsiLibInit()
+	ngsi.LogWriter = &bytes.Buffer{}
+	ngsi.TimeLib = &MockTimeLib{unixTime: 1000}
+	ngsi.tokenList = tokenInfoList{}
+	filename := ""
+	ngsi.CacheFile = &MockIoLib{filename: &filename}
+	reqRes := MockHTTPReqRes{}
+	reqRes.Res.StatusCode = http.StatusOK
+	reqRes.ResBody = []byte(`{"access_token": "new", "expires_in": 3600, "refresh_token": "refresh", "token_type": "Bearer" }`)
+	mock := NewMockHTTP()
+	mock.ReqRes = append(mock.ReqRes, reqRes)
+	ngsi.HTTP = mock
+
+	client := &Client{Server: &Server{ServerHost: "http://orion/", IdmType: CTokenproxy, Username: "fiware", Password: "1234"}, Token: "old", tokenRefresh: true}
+	ngsi.tokenList[getHash(client)] = TokenInfo{Token: "old", Expires: time.Unix(1100, 0)}
+
+	r := &TokenRefresher{ngsi: ngsi, client: client, mutex: &sync.Mutex{}}
+
+	actual := r.refresh()
+
+	assert.Equal(t, 3420*time.Second, actual)
+	assert.Equal(t, "new", client.Token)
+}
+
+func TestTokenRefresherRefreshInterval(t *testing.T) {
+	ngsi := testNgsiLibInit()
+	ngsi.LogWriter = &bytes.Buffer{}
+	ngsi.TimeLib = &MockTimeLib{unixTime: 1000}
+	ngsi.tokenList = tokenInfoList{}
+	filename := ""
+	ngsi.CacheFile = &MockIoLib{filename: &filename}
+	reqRes := MockHTTPReqRes{}
+	reqRes.Res.StatusCode = http.StatusOK
+	reqRes.ResBody = []byte(`{"access_token": "new", "expires_in": 60, "token_type": "Bearer" }`)
+	mock := NewMockHTTP()
+	mock.ReqRes = append(mock.ReqRes, reqRes)
+	ngsi.HTTP = mock
+
+	client := &Client{Server: &Server{ServerHost: "http://orion/", IdmType: CTokenproxy, Username: "fiware", Password: "1234"}, tokenRefresh: true}
+
+	r := &TokenRefresher{ngsi: ngsi, client: client, mutex: &sync.Mutex{}}
+
+	actual := r.refresh()
+
+	assert.Equal(t, tokenRefresherInterval, actual)
+}
+
+func TestTokenRefresherRefreshError(t *testing.T) {
+	ngsi := testNgsiLibInit()
+	buf := &bytes.Buffer{}
+	ngsi.LogWriter = buf
+	ngsi.tokenList = tokenInfoList{}
+
+	client := &Client{Server: &Server{ServerHost: "http://orion/", IdmType: "unknown"}, Token: "old", tokenRefresh: true}
+
+	r := &TokenRefresher{ngsi: ngsi, client: client, mutex: &sync.Mutex{}}
+
+	actual := r.refresh()
+
+	assert.Equal(t, tokenRefresherRetry, actual)
+	assert.Equal(t, "old", client.Token)
+	assert.Equal(t, true, strings.Contains(buf.String(), "tokenRefresher001"))
+}
@@ -46,7 +46,7 @@ func (i *idmThinkingCities) requestToken(ngsi *NGSI, client *Client, tokenInfo *
 
 	headers := make(map[string]string)
 	u, _ := url.Parse(client.idmURL())
-	idm := Client{URL: u, Headers: headers, HTTP: ngsi.HTTP}
+	idm := Client{URL: u, Headers: headers, HTTP: ngsi.serverHTTP(client.Server)}
 
 	username, password, err := getUserNamePassword(client)
 	if err != nil {
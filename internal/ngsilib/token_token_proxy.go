@@ -75,7 +75,7 @@ func (i *idmTokenProxy) requestToken(ngsi *NGSI, client *Client, tokenInfo *Toke
 		if !strings.HasSuffix(u.Path, "/token") {
 			u.Path = path.Join(u.Path, "/token")
 		}
-		idm := Client{URL: u, Headers: headers, HTTP: ngsi.HTTP}
+		idm := Client{URL: u, Headers: headers, HTTP: ngsi.serverHTTP(client.Server)}
 
 		idm.SetHeader(cContentType, cAppJSON)
 
@@ -124,7 +124,7 @@ func (i *idmTokenProxy) revokeToken(ngsi *NGSI, client *Client, tokenInfo *Token
 	} else if !strings.HasSuffix(u.Path, "/revoke") {
 		u.Path = path.Join(u.Path, "/revoke")
 	}
-	idm := Client{URL: u, Headers: headers, HTTP: ngsi.HTTP}
+	idm := Client{URL: u, Headers: headers, HTTP: ngsi.serverHTTP(client.Server)}
 
 	idm.SetHeader(cContentType, cAppJSON)
 	payload := fmt.Sprintf(`{"token":"%s","token_type_hint":"refresh_token"}`, tokenInfo.RefreshToken)
@@ -76,7 +76,7 @@ func (i *idmWSO2) requestToken(ngsi *NGSI, client *Client, tokenInfo *TokenInfo)
 
 		headers := make(map[string]string)
 		u, _ := url.Parse(client.idmURL())
-		idm := Client{URL: u, Headers: headers, HTTP: ngsi.HTTP}
+		idm := Client{URL: u, Headers: headers, HTTP: ngsi.serverHTTP(client.Server)}
 		broker := client.Server
 
 		idm.SetHeader(cContentType, cAppXWwwFormUrlencoded)
@@ -127,7 +127,7 @@ func (i *idmWSO2) revokeToken(ngsi *NGSI, client *Client, tokenInfo *TokenInfo)
 		s += "/revoke"
 	}
 	u, _ := url.Parse(s)
-	idm := Client{URL: u, Headers: headers, HTTP: ngsi.HTTP}
+	idm := Client{URL: u, Headers: headers, HTTP: ngsi.serverHTTP(client.Server)}
 	broker := client.Server
 
 	idm.SetHeader(cContentType, cAppXWwwFormUrlencoded)
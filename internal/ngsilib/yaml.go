@@ -0,0 +1,523 @@
+/*
+MIT License
+
+Copyright (c) 2020-2024 Kazuhito Suda
+
+This file is part of NGSI Go
+
+https://github.com/lets-fiware/ngsi-go
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+
+*/
+
+package ngsilib
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/lets-fiware/ngsi-go/internal/ngsierr"
+)
+
+// YAMLDecode decodes the documents of a YAML stream into values of the same types as JSONUnmarshal uses.
+// It supports a subset of YAML: block mappings and sequences, flow collections, plain and quoted scalars,
+// literal (|) and folded (>) block scalars, comments and documents separated by ---.
+// Anchors, aliases, tags and multi-line plain scalars are not supported.
+func YAMLDecode(b []byte) ([]interface{}, error) {
+	const funcName = "YAMLDecode"
+
+	var docs []interface{}
+	p := &yamlParser{}
+
+	flush := func() error {
+		if l := p.skip(); l == nil {
+			p.lines, p.pos = nil, 0
+			return nil
+		}
+		v, err := p.node(0)
+		if err != nil {
+			return err
+		}
+		if l := p.skip(); l != nil {
+			return ngsierr.New(funcName, 1, fmt.Sprintf("line %d: unexpected indentation", l.no), nil)
+		}
+		docs = append(docs, v)
+		p.lines, p.pos = nil, 0
+		return nil
+	}
+
+	for i, raw := range strings.Split(strings.ReplaceAll(string(b), "\r\n", "\n"), "\n") {
+		if raw == "---" || strings.HasPrefix(raw, "--- ") || raw == "..." {
+			if err := flush(); err != nil {
+				return nil, ngsierr.New(funcName, 2, err.Error(), err)
+			}
+			if text := strings.TrimSpace(yamlStripComment(strings.TrimPrefix(raw, "---"))); text != "" && raw != "..." {
+				p.lines = append(p.lines, &yamlLine{no: i + 1, raw: text, text: text})
+			}
+			continue
+		}
+		l := &yamlLine{no: i + 1, raw: raw}
+		body := strings.TrimLeft(raw, " ")
+		l.indent = len(raw) - len(body)
+		l.text = yamlStripComment(body)
+		l.tab = strings.HasPrefix(body, "\t") && l.text != ""
+		p.lines = append(p.lines, l)
+	}
+	if err := flush(); err != nil {
+		return nil, ngsierr.New(funcName, 3, err.Error(), err)
+	}
+
+	return docs, nil
+}
+
+type yamlLine struct {
+	no     int
+	indent int
+	raw    string
+	text   string
+	tab    bool
+}
+
+type yamlParser struct {
+	lines []*yamlLine
+	pos   int
+}
+
+var yamlNumberRegexp = regexp.MustCompile(`^[-+]?(\d+(\.\d*)?|\.\d+)([eE][-+]?\d+)?$`)
+
+// skip moves to the next line which is neither blank nor a comment
+func (p *yamlParser) skip() *yamlLine {
+	for p.pos < len(p.lines) {
+		if l := p.lines[p.pos]; l.text != "" {
+			return l
+		}
+		p.pos++
+	}
+	return nil
+}
+
+func (p *yamlParser) node(indent int) (interface{}, error) {
+	const funcName = "yamlNode"
+
+	l := p.skip()
+	if l == nil || l.indent < indent {
+		return nil, nil
+	}
+	if l.tab {
+		return nil, ngsierr.New(funcName, 1, fmt.Sprintf("line %d: tabs are not allowed for indentation", l.no), nil)
+	}
+	if yamlIsItem(l.text) {
+		return p.sequence(l.indent)
+	}
+	if _, _, ok := yamlSplitKey(l.text); ok {
+		return p.mapping(l.indent)
+	}
+	p.pos++
+	return p.value(l.text, l.indent, l.no)
+}
+
+func (p *yamlParser) sequence(indent int) (interface{}, error) {
+	const funcName = "yamlSequence"
+
+	seq := []interface{}{}
+
+	for {
+		l := p.skip()
+		if l != nil && l.tab {
+			return nil, ngsierr.New(funcName, 1, fmt.Sprintf("line %d: tabs are not allowed for indentation", l.no), nil)
+		}
+		if l == nil || l.indent != indent || !yamlIsItem(l.text) {
+			break
+		}
+		rest := strings.TrimLeft(l.text[1:], " ")
+		var v interface{}
+		var err error
+		if rest == "" {
+			p.pos++
+			v, err = p.node(indent + 1)
+		} else if rest[0] == '|' || rest[0] == '>' {
+			p.pos++
+			v, err = p.block(rest, indent, l.no)
+		} else {
+			// The rest of the line is a node indented by the width of "- "
+			l.indent += len(l.text) - len(rest)
+			l.text = rest
+			v, err = p.node(l.indent)
+		}
+		if err != nil {
+			return nil, ngsierr.New(funcName, 2, err.Error(), err)
+		}
+		seq = append(seq, v)
+	}
+
+	return seq, nil
+}
+
+func (p *yamlParser) mapping(indent int) (interface{}, error) {
+	const funcName = "yamlMapping"
+
+	m := map[string]interface{}{}
+
+	for {
+		l := p.skip()
+		if l != nil && l.tab {
+			return nil, ngsierr.New(funcName, 1, fmt.Sprintf("line %d: tabs are not allowed for indentation", l.no), nil)
+		}
+		if l == nil || l.indent < indent {
+			break
+		}
+		if l.indent > indent || yamlIsItem(l.text) {
+			return nil, ngsierr.New(funcName, 2, fmt.Sprintf("line %d: unexpected indentation", l.no), nil)
+		}
+		key, rest, ok := yamlSplitKey(l.text)
+		if !ok || key == "" {
+			return nil, ngsierr.New(funcName, 3, fmt.Sprintf("line %d: mapping key expected", l.no), nil)
+		}
+		k, err := yamlScalar(key, l.no)
+		if err != nil {
+			return nil, ngsierr.New(funcName, 4, err.Error(), err)
+		}
+		name := fmt.Sprint(k)
+		if _, ok := m[name]; ok {
+			return nil, ngsierr.New(funcName, 5, fmt.Sprintf("line %d: duplicate key %s", l.no, name), nil)
+		}
+		p.pos++
+		v, err := p.value(rest, indent, l.no)
+		if err != nil {
+			return nil, ngsierr.New(funcName, 6, err.Error(), err)
+		}
+		m[name] = v
+	}
+
+	return m, nil
+}
+
+// value parses the value of a mapping entry or a sequence item whose parent is at indent
+func (p *yamlParser) value(s string, indent, no int) (interface{}, error) {
+	if s == "" {
+		l := p.skip()
+		if l != nil && l.indent > indent {
+			return p.node(l.indent)
+		}
+		if l != nil && l.indent == indent && yamlIsItem(l.text) {
+			return p.sequence(indent)
+		}
+		return nil, nil
+	}
+	if s[0] == '|' || s[0] == '>' {
+		return p.block(s, indent, no)
+	}
+	return yamlScalar(s, no)
+}
+
+// block parses a literal or folded block scalar
+func (p *yamlParser) block(indicator string, indent, no int) (interface{}, error) {
+	const funcName = "yamlBlock"
+
+	switch indicator {
+	case "|", "|-", "|+", ">", ">-", ">+":
+	default:
+		return nil, ngsierr.New(funcName, 1, fmt.Sprintf("line %d: unsupported block scalar %s", no, indicator), nil)
+	}
+
+	var lines []string
+	width := -1
+	for ; p.pos < len(p.lines); p.pos++ {
+		l := p.lines[p.pos]
+		if strings.TrimSpace(l.raw) == "" {
+			lines = append(lines, "")
+			continue
+		}
+		if l.indent <= indent {
+			break
+		}
+		if width < 0 {
+			width = l.indent
+		}
+		if l.indent < width {
+			return nil, ngsierr.New(funcName, 2, fmt.Sprintf("line %d: unexpected indentation", l.no), nil)
+		}
+		lines = append(lines, l.raw[width:])
+	}
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	var s string
+	if indicator[0] == '|' {
+		s = strings.Join(lines, "\n")
+	} else {
+		for i, line := range lines {
+			switch {
+			case line == "":
+				s += "\n"
+			case i > 0 && lines[i-1] != "":
+				s += " " + line
+			default:
+				s += line
+			}
+		}
+	}
+	if s != "" && !strings.HasSuffix(indicator, "-") {
+		s += "\n"
+	}
+
+	return s, nil
+}
+
+func yamlIsItem(s string) bool {
+	return s == "-" || strings.HasPrefix(s, "- ")
+}
+
+// yamlSplitKey splits "key: value" into the key and the value
+func yamlSplitKey(s string) (string, string, bool) {
+	if s == "" || s[0] == '[' || s[0] == '{' {
+		return "", "", false
+	}
+	i := 0
+	if s[0] == '"' || s[0] == '\'' {
+		i = yamlQuoteEnd(s)
+		if i < 0 {
+			return "", "", false
+		}
+	}
+	for ; i < len(s); i++ {
+		if s[i] == ':' && (i+1 == len(s) || s[i+1] == ' ') {
+			return strings.TrimSpace(s[:i]), strings.TrimSpace(s[i+1:]), true
+		}
+	}
+	return "", "", false
+}
+
+// yamlQuoteEnd returns the index after the closing quote of the quoted string at the start of s
+func yamlQuoteEnd(s string) int {
+	q := s[0]
+	for i := 1; i < len(s); i++ {
+		switch {
+		case q == '"' && s[i] == '\\':
+			i++
+		case q == '\'' && s[i] == '\'' && i+1 < len(s) && s[i+1] == '\'':
+			i++
+		case s[i] == q:
+			return i + 1
+		}
+	}
+	return -1
+}
+
+// yamlStripComment removes a comment and trailing spaces
+func yamlStripComment(s string) string {
+	var q byte
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case q != 0:
+			if c == '\\' && q == '"' {
+				i++
+			} else if c == q {
+				q = 0
+			}
+		case c == '"' || c == '\'':
+			if i == 0 || strings.ContainsRune(" \t:[{,-", rune(s[i-1])) {
+				q = c
+			}
+		case c == '#' && (i == 0 || s[i-1] == ' ' || s[i-1] == '\t'):
+			return strings.TrimRight(s[:i], " \t")
+		}
+	}
+	return strings.TrimRight(s, " \t")
+}
+
+func yamlScalar(s string, no int) (interface{}, error) {
+	const funcName = "yamlScalar"
+
+	switch s[0] {
+	case '"', '\'':
+		if yamlQuoteEnd(s) != len(s) {
+			return nil, ngsierr.New(funcName, 1, fmt.Sprintf("line %d: unterminated string %s", no, s), nil)
+		}
+		return yamlQuoted(s, no)
+	case '[', '{':
+		f := &yamlFlow{s: s, no: no}
+		v, err := f.value()
+		if err != nil {
+			return nil, ngsierr.New(funcName, 2, err.Error(), err)
+		}
+		if f.skipSpaces(); f.i != len(s) {
+			return nil, ngsierr.New(funcName, 3, fmt.Sprintf("line %d: unexpected %s", no, s[f.i:]), nil)
+		}
+		return v, nil
+	}
+	return yamlPlain(s), nil
+}
+
+func yamlQuoted(s string, no int) (interface{}, error) {
+	const funcName = "yamlQuoted"
+
+	if s[0] == '\'' {
+		return strings.ReplaceAll(s[1:len(s)-1], "''", "'"), nil
+	}
+	var v string
+	if err := json.Unmarshal([]byte(s), &v); err != nil {
+		return nil, ngsierr.New(funcName, 1, fmt.Sprintf("line %d: invalid string %s", no, s), err)
+	}
+	return v, nil
+}
+
+func yamlPlain(s string) interface{} {
+	switch s {
+	case "~", "null", "Null", "NULL":
+		return nil
+	case "true", "True", "TRUE":
+		return true
+	case "false", "False", "FALSE":
+		return false
+	}
+	if yamlNumberRegexp.MatchString(s) {
+		if f, err := strconv.ParseFloat(s, 64); err == nil {
+			return f
+		}
+	}
+	return s
+}
+
+type yamlFlow struct {
+	s  string
+	i  int
+	no int
+}
+
+func (f *yamlFlow) skipSpaces() {
+	for f.i < len(f.s) && f.s[f.i] == ' ' {
+		f.i++
+	}
+}
+
+func (f *yamlFlow) value() (interface{}, error) {
+	const funcName = "yamlFlowValue"
+
+	f.skipSpaces()
+	if f.i == len(f.s) {
+		return nil, ngsierr.New(funcName, 1, fmt.Sprintf("line %d: unexpected end of flow collection", f.no), nil)
+	}
+	switch f.s[f.i] {
+	case '[':
+		return f.collection(']')
+	case '{':
+		return f.collection('}')
+	case '"', '\'':
+		end := yamlQuoteEnd(f.s[f.i:])
+		if end < 0 {
+			return nil, ngsierr.New(funcName, 2, fmt.Sprintf("line %d: unterminated string %s", f.no, f.s[f.i:]), nil)
+		}
+		s := f.s[f.i : f.i+end]
+		f.i += end
+		return yamlQuoted(s, f.no)
+	}
+	start := f.i
+	for f.i < len(f.s) && !strings.ContainsRune(",]}", rune(f.s[f.i])) {
+		f.i++
+	}
+	if f.i == start {
+		return nil, ngsierr.New(funcName, 3, fmt.Sprintf("line %d: unexpected %c", f.no, f.s[f.i]), nil)
+	}
+	return yamlPlain(strings.TrimSpace(f.s[start:f.i])), nil
+}
+
+func (f *yamlFlow) collection(end byte) (interface{}, error) {
+	const funcName = "yamlFlowCollection"
+
+	f.i++
+	seq := []interface{}{}
+	m := map[string]interface{}{}
+
+	for {
+		f.skipSpaces()
+		if f.i < len(f.s) && f.s[f.i] == end {
+			f.i++
+			break
+		}
+		if end == ']' {
+			v, err := f.value()
+			if err != nil {
+				return nil, ngsierr.New(funcName, 1, err.Error(), err)
+			}
+			seq = append(seq, v)
+		} else {
+			key, err := f.key()
+			if err != nil {
+				return nil, ngsierr.New(funcName, 2, err.Error(), err)
+			}
+			v, err := f.value()
+			if err != nil {
+				return nil, ngsierr.New(funcName, 3, err.Error(), err)
+			}
+			m[key] = v
+		}
+		f.skipSpaces()
+		if f.i < len(f.s) && f.s[f.i] == ',' {
+			f.i++
+			continue
+		}
+		if f.i < len(f.s) && f.s[f.i] == end {
+			f.i++
+			break
+		}
+		return nil, ngsierr.New(funcName, 4, fmt.Sprintf("line %d: expected , or %c", f.no, end), nil)
+	}
+
+	if end == ']' {
+		return seq, nil
+	}
+	return m, nil
+}
+
+func (f *yamlFlow) key() (string, error) {
+	const funcName = "yamlFlowKey"
+
+	f.skipSpaces()
+	var key string
+	if f.i < len(f.s) && (f.s[f.i] == '"' || f.s[f.i] == '\'') {
+		end := yamlQuoteEnd(f.s[f.i:])
+		if end < 0 {
+			return "", ngsierr.New(funcName, 1, fmt.Sprintf("line %d: unterminated string %s", f.no, f.s[f.i:]), nil)
+		}
+		v, err := yamlQuoted(f.s[f.i:f.i+end], f.no)
+		if err != nil {
+			return "", ngsierr.New(funcName, 2, err.Error(), err)
+		}
+		key = v.(string)
+		f.i += end
+	} else {
+		start := f.i
+		for f.i < len(f.s) && f.s[f.i] != ':' && f.s[f.i] != ',' && f.s[f.i] != '}' {
+			f.i++
+		}
+		key = strings.TrimSpace(f.s[start:f.i])
+	}
+	f.skipSpaces()
+	if f.i == len(f.s) || f.s[f.i] != ':' {
+		return "", ngsierr.New(funcName, 3, fmt.Sprintf("line %d: expected : after %s", f.no, key), nil)
+	}
+	f.i++
+	return key, nil
+}
@@ -0,0 +1,204 @@
+/*
+MIT License
+
+Copyright (c) 2020-2024 Kazuhito Suda
+
+This file is part of NGSI Go
+
+https://github.com/lets-fiware/ngsi-go
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+
+*/
+
+package ngsilib
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/lets-fiware/ngsi-go/internal/assert"
+)
+
+func yamlTestJSON(t *testing.T, s string) string {
+	docs, err := YAMLDecode([]byte(s))
+	if !assert.NoError(t, err) {
+		return ""
+	}
+	b, _ := json.Marshal(docs)
+	return string(b)
+}
+
+func TestYAMLDecodeSubscription(t *testing.T) {
+	s := `# subscription
+description: Notify me of all product price changes
+subject:
+  entities:
+    - idPattern: .*
+      type: Product
+  condition:
+    attrs: [ price ]
+notification:
+  http:
+    url: "http://tutorial:3000/subscription/price-change"   # comment
+  attrs:
+  - price
+throttling: 5
+`
+	expected := `[{"description":"Notify me of all product price changes","notification":{"attrs":["price"],"http":{"url":"http://tutorial:3000/subscription/price-change"}},"subject":{"condition":{"attrs":["price"]},"entities":[{"idPattern":".*","type":"Product"}]},"throttling":5}]`
+
+	assert.Equal(t, expected, yamlTestJSON(t, s))
+}
+
+func TestYAMLDecodeScalars(t *testing.T) {
+	s := `a: 1
+b: -2.5e3
+c: true
+d: False
+e: ~
+f: null
+g:
+h: 'it''s'
+i: "a\tb # not comment"
+j: it's # comment
+k: 2026-10-01T10:00:00.000Z
+"l m": x: y
+`
+	expected := `[{"a":1,"b":-2500,"c":true,"d":false,"e":null,"f":null,"g":null,"h":"it's","i":"a\tb # not comment","j":"it's","k":"2026-10-01T10:00:00.000Z","l m":"x: y"}]`
+
+	assert.Equal(t, expected, yamlTestJSON(t, s))
+}
+
+func TestYAMLDecodeFlow(t *testing.T) {
+	s := `a: {x: 1, "y": [a, 'b', "c"], z: {}}
+b: [ [1, 2], [], {k: v} ]
+`
+	expected := `[{"a":{"x":1,"y":["a","b","c"],"z":{}},"b":[[1,2],[],{"k":"v"}]}]`
+
+	assert.Equal(t, expected, yamlTestJSON(t, s))
+}
+
+func TestYAMLDecodeSequence(t *testing.T) {
+	s := `- a
+- - b
+  - c
+-
+  d: 1
+- e: 2
+  f:
+  - 3
+`
+	expected := `[["a",["b","c"],{"d":1},{"e":2,"f":[3]}]]`
+
+	assert.Equal(t, expected, yamlTestJSON(t, s))
+}
+
+func TestYAMLDecodeBlock(t *testing.T) {
+	s := `a: |
+  line1
+    line2
+
+  line3 # not comment
+
+b: >-
+  folded
+  text
+
+  next
+c: |-
+  strip
+- |
+  item
+`
+	_, err := YAMLDecode([]byte(s))
+	assert.Error(t, err)
+
+	s = `a: |
+  line1
+    line2
+
+  line3 # not comment
+
+b: >-
+  folded
+  text
+
+  next
+c: |-
+  strip
+d:
+  - |
+    item
+e: |
+f: x
+`
+	expected := `[{"a":"line1\n  line2\n\nline3 # not comment\n","b":"folded text\nnext","c":"strip","d":["item\n"],"e":"","f":"x"}]`
+
+	assert.Equal(t, expected, yamlTestJSON(t, s))
+}
+
+func TestYAMLDecodeDocuments(t *testing.T) {
+	s := "---\na: 1\n---\n# empty\n--- [b]\n...\n- c\r\n"
+	expected := `[{"a":1},["b"],["c"]]`
+
+	assert.Equal(t, expected, yamlTestJSON(t, s))
+}
+
+func TestYAMLDecodeScalarDocument(t *testing.T) {
+	assert.Equal(t, `["text"]`, yamlTestJSON(t, "text\n"))
+	assert.Equal(t, `null`, yamlTestJSON(t, "# only comment\n"))
+}
+
+func TestYAMLDecodeError(t *testing.T) {
+	cases := []struct {
+		s        string
+		expected string
+	}{
+		{s: "a: 1\n  b: 2\n", expected: "line 2: unexpected indentation"},
+		{s: "a:\n\t- 1\n", expected: "line 2: tabs are not allowed for indentation"},
+		{s: "a: 1\nb\n", expected: "line 2: mapping key expected"},
+		{s: "a: 1\na: 2\n", expected: "line 2: duplicate key a"},
+		{s: "a: 1\n- b\n", expected: "line 2: unexpected indentation"},
+		{s: "a: \"b\n", expected: "line 1: unterminated string \"b"},
+		{s: "a: \"\\q\"\n", expected: "line 1: invalid string \"\\q\""},
+		{s: "a: |2\n  b\n", expected: "line 1: unsupported block scalar |2"},
+		{s: "a:\n    - |\n      b\n     c\n", expected: "line 4: unexpected indentation"},
+		{s: "a: [1, 2\n", expected: "line 1: expected , or ]"},
+		{s: "a: [1,\n", expected: "line 1: unexpected end of flow collection"},
+		{s: "a: {b: 1 c: 2 ]\n", expected: "line 1: expected , or }"},
+		{s: "a: [1] x\n", expected: "line 1: unexpected x"},
+		{s: "a: {b}\n", expected: "line 1: expected : after b"},
+		{s: "a: {\"b: 1}\n", expected: "line 1: unterminated string \"b: 1}"},
+		{s: "a: {\"\\q\": 1}\n", expected: "line 1: invalid string \"\\q\""},
+		{s: "a: {b: \"c}\n", expected: "line 1: unterminated string \"c}"},
+		{s: "a: {b: [}\n", expected: "line 1: unexpected }"},
+		{s: "- a\n\t- b\n", expected: "line 2: tabs are not allowed for indentation"},
+		{s: "\ta: 1\n", expected: "line 1: tabs are not allowed for indentation"},
+		{s: "---\na: 1\n b: 2\n---\n", expected: "line 3: unexpected indentation"},
+		{s: ": 1\n", expected: "line 1: mapping key expected"},
+		{s: "- a: 1\n   b: 2\n", expected: "line 2: unexpected indentation"},
+	}
+
+	for _, c := range cases {
+		_, err := YAMLDecode([]byte(c.s))
+		if assert.Error(t, err) {
+			assert.Equal(t, c.expected, err.Error())
+		}
+	}
+}
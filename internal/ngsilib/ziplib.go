@@ -37,6 +37,7 @@ import (
 // ZipLib is ...
 type ZipLib interface {
 	NewReader(r io.ReaderAt, size int64) (*zip.Reader, error)
+	NewWriter(w io.Writer) *zip.Writer
 }
 
 type zipLib struct {
@@ -45,3 +46,7 @@ type zipLib struct {
 func (z *zipLib) NewReader(r io.ReaderAt, size int64) (*zip.Reader, error) {
 	return zip.NewReader(r, size)
 }
+
+func (z *zipLib) NewWriter(w io.Writer) *zip.Writer {
+	return zip.NewWriter(w)
+}
@@ -40,3 +40,12 @@ func TestZipNewReader(t *testing.T) {
 
 	_, _ = z.NewReader(src, -1)
 }
+
+func TestZipNewWriter(t *testing.T) {
+	z := zipLib{}
+	buf := &bytes.Buffer{}
+
+	w := z.NewWriter(buf)
+
+	_ = w.Close()
+}
@@ -109,6 +109,7 @@ func NewNgsiApp() *ngsicli.App {
 		Commands: []*ngsicli.Command{
 			&convenience.AdminCmd,
 			&convenience.ApisCmd,
+			&convenience.ApplyCmd,
 			&ngsicmd.AppendCmd,
 			&management.BrokersCmd,
 			&management.ContextCmd,
@@ -119,12 +120,15 @@ func NewNgsiApp() *ngsicli.App {
 			&ngsicmd.DeleteCmd,
 			&iotagent.DevicesCmd,
 			&convenience.DocumentsCmd,
+			&convenience.ExportCmd,
 			&ngsicmd.GetCmd,
 			&timeseries.HDeleteCmd,
 			&timeseries.HGetCmd,
 			&convenience.HealthCmd,
+			&convenience.ImportCmd,
 			&ngsicmd.ListCmd,
 			&ngsicmd.LsCmd,
+			&management.ProfileCmd,
 			&convenience.QueryProxyCmd,
 			&convenience.RemoveCmd,
 			&convenience.ReceiverCmd,
@@ -133,7 +137,9 @@ func NewNgsiApp() *ngsicli.App {
 			&perseo.RulesCmd,
 			&management.SettingsCmd,
 			&management.ServerCmd,
+			&convenience.ShellCmd,
 			&iotagent.ServicesCmd,
+			&ngsicmd.SubscriptionsCmd,
 			&ngsicmd.TemplateCmd,
 			&management.TokenCmd,
 			&management.LicenseCmd,
@@ -141,6 +147,7 @@ func NewNgsiApp() *ngsicli.App {
 			&ngsicmd.UpdateCmd,
 			&ngsicmd.UpsertCmd,
 			&convenience.VersionCmd,
+			&convenience.WatchCmd,
 			&keyrock.ApplicationsCmd,
 			&keyrock.UsersCmd,
 			&keyrock.OrganizationsCmd,
@@ -71,6 +71,7 @@ var RulesCmd = ngsicli.Command{
 				perseoRulesRaw,
 				ngsicli.VerboseFlag,
 				ngsicli.PrettyFlag,
+				ngsicli.OutputFlag,
 			},
 			Action: func(c *ngsicli.Context, ngsi *ngsilib.NGSI, client *ngsilib.Client) error {
 				return perseoRulesList(c, ngsi, client)
@@ -40,6 +40,7 @@ import (
 	"github.com/lets-fiware/ngsi-go/internal/helper"
 	"github.com/lets-fiware/ngsi-go/internal/ngsicli"
 	"github.com/lets-fiware/ngsi-go/internal/ngsierr"
+	"github.com/lets-fiware/ngsi-go/internal/ngsilib"
 )
 
 func TestNewNgsiApp(t *testing.T) {
@@ -84,3 +85,46 @@ func TestNGSICommand(t *testing.T) {
 		}
 	}
 }
+
+func TestListOutput(t *testing.T) {
+	cases := []struct {
+		args     []string
+		path     string
+		resBody  string
+		list     func(*ngsicli.Context, *ngsilib.NGSI, *ngsilib.Client) error
+		expected map[string]string
+	}{
+		{
+			args:    []string{"rules", "list", "--host", "perseo"},
+			path:    "/rules",
+			resBody: `{"error":null,"data":[{"_id":"6024cb208e2bfc0012c77488","name":"blood_rule_update","text":"select \"blood_rule_update\" as ruleName, *, *, ev.BloodPressure? as Pressure, ev.id? as Meter from pattern [every ev=iotEvent(cast(cast(BloodPressure?,String),float)>1.5 and type=\"BloodMeter\")]","action":{"type":"update","parameters":{"attributes":[{"name":"abnormal","value":"true","type":"boolean"}]}},"subservice":"/","service":"unknownt"}],"count":1}`,
+			list:    perseoRulesList,
+			expected: map[string]string{
+				"csv": "name,service,subservice,text\nblood_rule_update,unknownt,/,\"select \"\"blood_rule_update\"\" as ruleName, *, *, ev.BloodPressure? as Pressure, ev.id? as Meter from pattern [every ev=iotEvent(cast(cast(BloodPressure?,String),float)>1.5 and type=\"\"BloodMeter\"\")]\"\n",
+				"tsv": "name\tservice\tsubservice\ttext\nblood_rule_update\tunknownt\t/\tselect \"blood_rule_update\" as ruleName, *, *, ev.BloodPressure? as Pressure, ev.id? as Meter from pattern [every ev=iotEvent(cast(cast(BloodPressure?,String),float)>1.5 and type=\"BloodMeter\")]\n",
+				"table": "" +
+					"name               service   subservice  text\n" +
+					"blood_rule_update  unknownt  /           select \"blood_rule_update\" as ruleName, *, *, ev.BloodPressure? as Pressure, ev.id? as Meter from pattern [every ev=iotEvent(cast(cast(BloodPressure?,String),float)>1.5 and type=\"BloodMeter\")]\n",
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		for _, format := range ngsilib.TableFormats {
+			c := setupTest(append(tc.args, "--output", format))
+
+			reqRes := helper.MockHTTPReqRes{}
+			reqRes.Res.StatusCode = http.StatusOK
+			reqRes.Path = tc.path
+			reqRes.ResBody = []byte(tc.resBody)
+
+			helper.SetClientHTTP(c, reqRes)
+
+			err := tc.list(c, c.Ngsi, c.Client)
+
+			if assert.NoError(t, err) {
+				assert.Equal(t, tc.expected[format], helper.GetStdoutString(c))
+			}
+		}
+	}
+}
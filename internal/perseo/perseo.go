@@ -70,6 +70,13 @@ func perseoRulesList(c *ngsicli.Context, ngsi *ngsilib.NGSI, client *ngsilib.Cli
 		return ngsierr.New(funcName, 2, fmt.Sprintf("%s %s", res.Status, string(body)), nil)
 	}
 
+	if c.IsSet("output") {
+		if err := ngsilib.PrintJSONTable(ngsi.StdWriter, c.String("output"), body, "data", []string{"name", "service", "subservice", "text"}); err != nil {
+			return ngsierr.New(funcName, 4, err.Error(), err)
+		}
+		return nil
+	}
+
 	if err = perseoPrintRespose(c, ngsi, body); err != nil {
 		return ngsierr.New(funcName, 3, err.Error(), err)
 	}
@@ -155,6 +155,26 @@ func TestPerseoRulesListErrorPretty(t *testing.T) {
 	}
 }
 
+func TestPerseoRulesListErrorOutput(t *testing.T) {
+	c := setupTest([]string{"rules", "list", "--host", "perseo", "--output", "csv"})
+	c.GetStringFlag("output").Value = "xml"
+
+	reqRes := helper.MockHTTPReqRes{}
+	reqRes.Res.StatusCode = http.StatusOK
+	reqRes.Path = "/rules"
+	reqRes.ResBody = []byte(`{"error":null,"data":[{"_id":"6024cb208e2bfc0012c77488","name":"blood_rule_update","text":"select \"blood_rule_update\" as ruleName, *, *, ev.BloodPressure? as Pressure, ev.id? as Meter from pattern [every ev=iotEvent(cast(cast(BloodPressure?,String),float)>1.5 and type=\"BloodMeter\")]","action":{"type":"update","parameters":{"attributes":[{"name":"abnormal","value":"true","type":"boolean"}]}},"subservice":"/","service":"unknownt"}],"count":1}`)
+
+	helper.SetClientHTTP(c, reqRes)
+
+	err := perseoRulesList(c, c.Ngsi, c.Client)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 4, ngsiErr.ErrNo)
+		assert.Equal(t, "unknown output format: xml (csv, tsv, table)", ngsiErr.Message)
+	}
+}
+
 func TestPerseoRulesGet(t *testing.T) {
 	c := setupTest([]string{"rules", "get", "--host", "perseo", "--name", "blood_rule_update"})
 
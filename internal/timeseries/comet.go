@@ -99,6 +99,12 @@ func cometAttrReadMain(c *ngsicli.Context, ngsi *ngsilib.NGSI, client *ngsilib.C
 			return ngsierr.New(funcName, 6, err.Error(), err)
 		}
 	}
+	if c.IsSet("output") {
+		if err := cometPrintTable(c, ngsi, body); err != nil {
+			return ngsierr.New(funcName, 8, err.Error(), err)
+		}
+		return nil
+	}
 	if c.Bool("pretty") {
 		newBuf := new(bytes.Buffer)
 		err := ngsi.JSONConverter.Indent(newBuf, body, "", "  ")
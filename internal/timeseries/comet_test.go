@@ -115,6 +115,25 @@ func TestCometAttrReadMainPretty(t *testing.T) {
 	}
 }
 
+func TestCometAttrReadMainOutput(t *testing.T) {
+	c := setupTest([]string{"hget", "attr", "--host", "comet", "--id", "device001", "--type", "device", "--attr", "A1", "--hLimit", "3", "--output", "csv"})
+
+	reqRes := helper.MockHTTPReqRes{}
+	reqRes.Res.StatusCode = http.StatusOK
+	reqRes.Path = "/STH/v2/entities/device001/attrs/A1"
+	reqRes.ResBody = []byte(`{"type":"StructuredValue","value":[{"recvTime":"2016-09-13T00:00:00.000Z","attrType":"Number","attrValue":1}]}`)
+
+	helper.SetClientHTTP(c, reqRes)
+
+	err := cometAttrReadMain(c, c.Ngsi, c.Client)
+
+	if assert.NoError(t, err) {
+		actual := helper.GetStdoutString(c)
+		expected := "entityType,entityId,attrName,index,value\ndevice,device001,A1,2016-09-13T00:00:00.000Z,1\n"
+		assert.Equal(t, expected, actual)
+	}
+}
+
 func TestCometAttrReadMainErrorNoType(t *testing.T) {
 	c := setupTest([]string{"hget", "attr", "--host", "comet"})
 
@@ -248,6 +267,26 @@ func TestCometAttrReadMainErrorPretty(t *testing.T) {
 	}
 }
 
+func TestCometAttrReadMainErrorOutput(t *testing.T) {
+	c := setupTest([]string{"hget", "attr", "--host", "comet", "--id", "device001", "--type", "device", "--attr", "A1", "--hLimit", "3", "--output", "csv"})
+	c.GetStringFlag("output").Value = "xml"
+
+	reqRes := helper.MockHTTPReqRes{}
+	reqRes.Res.StatusCode = http.StatusOK
+	reqRes.Path = "/STH/v2/entities/device001/attrs/A1"
+	reqRes.ResBody = []byte(`{"type":"StructuredValue","value":[{"recvTime":"2016-09-13T00:00:00.000Z","attrType":"Number","attrValue":1}]}`)
+
+	helper.SetClientHTTP(c, reqRes)
+
+	err := cometAttrReadMain(c, c.Ngsi, c.Client)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 8, ngsiErr.ErrNo)
+		assert.Equal(t, "unknown output format: xml (csv, tsv, table)", ngsiErr.Message)
+	}
+}
+
 func TestCometEntitiesDeleteMain(t *testing.T) {
 	c := setupTest([]string{"hdelete", "entities", "--host", "comet"})
 
@@ -83,6 +83,7 @@ var HGetCmd = ngsicli.Command{
 				valueFlag,
 				ngsicli.PrettyFlag,
 				ngsicli.SafeStringFlag,
+				ngsicli.OutputFlag,
 			},
 			Action: func(c *ngsicli.Context, ngsi *ngsilib.NGSI, client *ngsilib.Client) error {
 				return tsAttrRead(c, ngsi, client)
@@ -111,6 +112,7 @@ var HGetCmd = ngsicli.Command{
 				valueFlag,
 				ngsicli.PrettyFlag,
 				ngsicli.SafeStringFlag,
+				ngsicli.OutputFlag,
 			},
 			Action: func(c *ngsicli.Context, ngsi *ngsilib.NGSI, client *ngsilib.Client) error {
 				return qlAttrsRead(c, ngsi, client)
@@ -128,6 +130,7 @@ var HGetCmd = ngsicli.Command{
 				hOffsetFlag,
 				ngsicli.PrettyFlag,
 				ngsicli.SafeStringFlag,
+				ngsicli.OutputFlag,
 			},
 			Action: func(c *ngsicli.Context, ngsi *ngsilib.NGSI, client *ngsilib.Client) error {
 				return qlEntitiesRead(c, ngsi, client)
@@ -74,6 +74,12 @@ func qlEntitiesRead(c *ngsicli.Context, ngsi *ngsilib.NGSI, client *ngsilib.Clie
 			return ngsierr.New(funcName, 4, err.Error(), err)
 		}
 	}
+	if c.IsSet("output") {
+		if err := qlEntitiesPrintTable(c, ngsi, body); err != nil {
+			return ngsierr.New(funcName, 6, err.Error(), err)
+		}
+		return nil
+	}
 	if c.Bool("pretty") {
 		newBuf := new(bytes.Buffer)
 		err := ngsi.JSONConverter.Indent(newBuf, body, "", "  ")
@@ -165,6 +171,12 @@ func qlAttrReadMain(c *ngsicli.Context, ngsi *ngsilib.NGSI, client *ngsilib.Clie
 			return ngsierr.New(funcName, 9, err.Error(), err)
 		}
 	}
+	if c.IsSet("output") {
+		if err := qlPrintTable(c, ngsi, body); err != nil {
+			return ngsierr.New(funcName, 11, err.Error(), err)
+		}
+		return nil
+	}
 	if c.Bool("pretty") {
 		newBuf := new(bytes.Buffer)
 		err := ngsi.JSONConverter.Indent(newBuf, body, "", "  ")
@@ -253,6 +265,12 @@ func qlAttrsRead(c *ngsicli.Context, ngsi *ngsilib.NGSI, client *ngsilib.Client)
 			return ngsierr.New(funcName, 7, err.Error(), err)
 		}
 	}
+	if c.IsSet("output") {
+		if err := qlPrintTable(c, ngsi, body); err != nil {
+			return ngsierr.New(funcName, 9, err.Error(), err)
+		}
+		return nil
+	}
 	if c.Bool("pretty") {
 		newBuf := new(bytes.Buffer)
 		err := ngsi.JSONConverter.Indent(newBuf, body, "", "  ")
@@ -96,6 +96,25 @@ func TestQlEntitiesReadMainPretty(t *testing.T) {
 	}
 }
 
+func TestQlEntitiesReadMainOutput(t *testing.T) {
+	c := setupTest([]string{"hget", "entities", "--host", "ql", "--output", "csv"})
+
+	reqRes := helper.MockHTTPReqRes{}
+	reqRes.Res.StatusCode = http.StatusOK
+	reqRes.Path = "/v2/entities"
+	reqRes.ResBody = []byte(`[{"id":"Event001","index":["2016-11-13T00:11:22"],"type":"Event"}]`)
+
+	helper.SetClientHTTP(c, reqRes)
+
+	err := qlEntitiesRead(c, c.Ngsi, c.Client)
+
+	if assert.NoError(t, err) {
+		actual := helper.GetStdoutString(c)
+		expected := "id,type,index\nEvent001,Event,2016-11-13T00:11:22\n"
+		assert.Equal(t, expected, actual)
+	}
+}
+
 func TestQlEntitiesReadMainErrorDate(t *testing.T) {
 	c := setupTest([]string{"hget", "entities", "--host", "ql", "--hLimit", "3", "--fromDate", "123"})
 
@@ -185,6 +204,26 @@ func TestQlEntitiesReadMainErrorPretty(t *testing.T) {
 	}
 }
 
+func TestQlEntitiesReadMainErrorOutput(t *testing.T) {
+	c := setupTest([]string{"hget", "entities", "--host", "ql", "--output", "csv"})
+	c.GetStringFlag("output").Value = "xml"
+
+	reqRes := helper.MockHTTPReqRes{}
+	reqRes.Res.StatusCode = http.StatusOK
+	reqRes.Path = "/v2/entities"
+	reqRes.ResBody = []byte(`[{"id":"Event001","index":["2016-11-13T00:11:22"],"type":"Event"}]`)
+
+	helper.SetClientHTTP(c, reqRes)
+
+	err := qlEntitiesRead(c, c.Ngsi, c.Client)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 6, ngsiErr.ErrNo)
+		assert.Equal(t, "unknown output format: xml (csv, tsv, table)", ngsiErr.Message)
+	}
+}
+
 func TestQlAttrReadMainLastN(t *testing.T) {
 	c := setupTest([]string{"hget", "attr", "--host", "ql", "--id", "device001", "--attr", "A1", "--lastN", "3"})
 
@@ -318,6 +357,25 @@ func TestQlAttrReadMainPretty(t *testing.T) {
 	}
 }
 
+func TestQlAttrReadMainOutput(t *testing.T) {
+	c := setupTest([]string{"hget", "attr", "--host", "ql", "--id", "device001", "--attr", "A1", "--output", "csv"})
+
+	reqRes := helper.MockHTTPReqRes{}
+	reqRes.Res.StatusCode = http.StatusOK
+	reqRes.Path = "/v2/entities/device001/attrs/A1"
+	reqRes.ResBody = []byte(`{"attrName":"A1","entityId":"device001","index":["2016-09-13T03:01:00.000+00:00"],"values":[91.0]}`)
+
+	helper.SetClientHTTP(c, reqRes)
+
+	err := qlAttrReadMain(c, c.Ngsi, c.Client)
+
+	if assert.NoError(t, err) {
+		actual := helper.GetStdoutString(c)
+		expected := "entityType,entityId,attrName,index,value\n,device001,A1,2016-09-13T03:01:00.000+00:00,91\n"
+		assert.Equal(t, expected, actual)
+	}
+}
+
 func TestQlAttrReadMainErrorAttrName(t *testing.T) {
 	c := setupTest([]string{"hget", "attr", "--host", "ql"})
 
@@ -469,6 +527,26 @@ func TestQlAttrReadMainErrorPretty(t *testing.T) {
 	}
 }
 
+func TestQlAttrReadMainErrorOutput(t *testing.T) {
+	c := setupTest([]string{"hget", "attr", "--host", "ql", "--id", "device001", "--attr", "A1", "--output", "csv"})
+	c.GetStringFlag("output").Value = "xml"
+
+	reqRes := helper.MockHTTPReqRes{}
+	reqRes.Res.StatusCode = http.StatusOK
+	reqRes.Path = "/v2/entities/device001/attrs/A1"
+	reqRes.ResBody = []byte(`{"attrName":"A1","entityId":"device001","index":["2016-09-13T03:01:00.000+00:00"],"values":[91.0]}`)
+
+	helper.SetClientHTTP(c, reqRes)
+
+	err := qlAttrReadMain(c, c.Ngsi, c.Client)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 11, ngsiErr.ErrNo)
+		assert.Equal(t, "unknown output format: xml (csv, tsv, table)", ngsiErr.Message)
+	}
+}
+
 func TestQlAttrsReadMain(t *testing.T) {
 	c := setupTest([]string{"hget", "attrs", "--host", "ql", "--id", "device001", "--attrs", "A1,A2", "--lastN", "3"})
 
@@ -621,6 +699,25 @@ func TestQlAttrsReadMainPretty(t *testing.T) {
 	}
 }
 
+func TestQlAttrsReadMainOutput(t *testing.T) {
+	c := setupTest([]string{"hget", "attrs", "--host", "ql", "--id", "device001", "--attrs", "A1", "--output", "csv"})
+
+	reqRes := helper.MockHTTPReqRes{}
+	reqRes.Res.StatusCode = http.StatusOK
+	reqRes.Path = "/v2/entities/device001"
+	reqRes.ResBody = []byte(`{"entityId":"device001","index":["2016-09-13T03:01:00.000+00:00"],"attributes":[{"attrName":"A1","values":[91.0]}]}`)
+
+	helper.SetClientHTTP(c, reqRes)
+
+	err := qlAttrsRead(c, c.Ngsi, c.Client)
+
+	if assert.NoError(t, err) {
+		actual := helper.GetStdoutString(c)
+		expected := "entityType,entityId,attrName,index,value\n,device001,A1,2016-09-13T03:01:00.000+00:00,91\n"
+		assert.Equal(t, expected, actual)
+	}
+}
+
 func TestQlAttrsReadMainErrorGeo(t *testing.T) {
 	c := setupTest([]string{"hget", "attrs", "--host", "ql", "--id", "device001", "--attrs", "A1,A2", "--lastN", "3", "--georel", "line"})
 
@@ -775,6 +872,26 @@ func TestQlAttrsReadMainErrorPretty(t *testing.T) {
 	}
 }
 
+func TestQlAttrsReadMainErrorOutput(t *testing.T) {
+	c := setupTest([]string{"hget", "attrs", "--host", "ql", "--id", "device001", "--attrs", "A1", "--output", "csv"})
+	c.GetStringFlag("output").Value = "xml"
+
+	reqRes := helper.MockHTTPReqRes{}
+	reqRes.Res.StatusCode = http.StatusOK
+	reqRes.Path = "/v2/entities/device001"
+	reqRes.ResBody = []byte(`{"entityId":"device001","index":["2016-09-13T03:01:00.000+00:00"],"attributes":[{"attrName":"A1","values":[91.0]}]}`)
+
+	helper.SetClientHTTP(c, reqRes)
+
+	err := qlAttrsRead(c, c.Ngsi, c.Client)
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 9, ngsiErr.ErrNo)
+		assert.Equal(t, "unknown output format: xml (csv, tsv, table)", ngsiErr.Message)
+	}
+}
+
 func TestQlEntityDeleteMain(t *testing.T) {
 	c := setupTest([]string{"hdelete", "entity", "--host", "ql", "--id", "device001"})
 
@@ -0,0 +1,188 @@
+/*
+MIT License
+
+Copyright (c) 2020-2024 Kazuhito Suda
+
+This file is part of NGSI Go
+
+https://github.com/lets-fiware/ngsi-go
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+
+*/
+
+package timeseries
+
+import (
+	"github.com/lets-fiware/ngsi-go/internal/ngsicli"
+	"github.com/lets-fiware/ngsi-go/internal/ngsierr"
+	"github.com/lets-fiware/ngsi-go/internal/ngsilib"
+)
+
+// tsTableColumns is the columns of history printed as a table. A row is made for each value.
+var tsTableColumns = []string{"entityType", "entityId", "attrName", "index", "value"}
+
+func tsTableContext(c *ngsicli.Context) map[string]interface{} {
+	ctx := map[string]interface{}{}
+	for _, p := range [][2]string{{"type", "entityType"}, {"id", "entityId"}, {"attr", "attrName"}} {
+		if c.IsSet(p[0]) {
+			ctx[p[1]] = c.String(p[0])
+		}
+	}
+	return ctx
+}
+
+func qlPrintTable(c *ngsicli.Context, ngsi *ngsilib.NGSI, body []byte) error {
+	const funcName = "qlPrintTable"
+
+	var v interface{}
+	if err := ngsilib.JSONUnmarshal(body, &v); err != nil {
+		return ngsierr.New(funcName, 1, err.Error(), err)
+	}
+
+	items := qlTableItems(v, tsTableContext(c), []interface{}{})
+
+	if err := ngsilib.PrintTable(ngsi.StdWriter, c.String("output"), items, tsTableColumns); err != nil {
+		return ngsierr.New(funcName, 2, err.Error(), err)
+	}
+	return nil
+}
+
+// qlTableItems flattens a response of QuantumLeap, which nests entities by types, attributes
+// or entities, into items of a value of an attribute.
+func qlTableItems(v interface{}, ctx map[string]interface{}, items []interface{}) []interface{} {
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return items
+	}
+
+	newCtx := map[string]interface{}{}
+	for k, e := range ctx {
+		newCtx[k] = e
+	}
+	for _, k := range []string{"entityType", "entityId", "attrName", "index"} {
+		if e, ok := m[k]; ok {
+			newCtx[k] = e
+		}
+	}
+
+	for _, k := range []string{"attrs", "types", "entities", "attributes"} {
+		if list, ok := m[k].([]interface{}); ok {
+			for _, e := range list {
+				items = qlTableItems(e, newCtx, items)
+			}
+			return items
+		}
+	}
+
+	values, _ := m["values"].([]interface{})
+	index, _ := newCtx["index"].([]interface{})
+	for i, value := range values {
+		if _, ok := value.(map[string]interface{}); ok {
+			items = qlTableItems(value, newCtx, items)
+			continue
+		}
+		item := map[string]interface{}{"value": value}
+		for _, k := range []string{"entityType", "entityId", "attrName"} {
+			item[k] = newCtx[k]
+		}
+		if i < len(index) {
+			item["index"] = index[i]
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+func qlEntitiesPrintTable(c *ngsicli.Context, ngsi *ngsilib.NGSI, body []byte) error {
+	const funcName = "qlEntitiesPrintTable"
+
+	var items []interface{}
+	if err := ngsilib.JSONUnmarshal(body, &items); err != nil {
+		return ngsierr.New(funcName, 1, err.Error(), err)
+	}
+
+	for _, item := range items {
+		if m, ok := item.(map[string]interface{}); ok {
+			if index, ok := m["index"].([]interface{}); ok && len(index) == 1 {
+				m["index"] = index[0]
+			}
+		}
+	}
+
+	if err := ngsilib.PrintTable(ngsi.StdWriter, c.String("output"), items, []string{"id", "type", "index"}); err != nil {
+		return ngsierr.New(funcName, 2, err.Error(), err)
+	}
+	return nil
+}
+
+func cometPrintTable(c *ngsicli.Context, ngsi *ngsilib.NGSI, body []byte) error {
+	const funcName = "cometPrintTable"
+
+	var res struct {
+		Value []map[string]interface{} `json:"value"`
+	}
+	if err := ngsilib.JSONUnmarshal(body, &res); err != nil {
+		return ngsierr.New(funcName, 1, err.Error(), err)
+	}
+
+	ctx := tsTableContext(c)
+	columns := tsTableColumns
+	items := []interface{}{}
+
+	if c.IsSet("aggrMethod") {
+		columns = []string{"entityType", "entityId", "attrName", "origin", "resolution", "offset", "samples", "value"}
+		for _, v := range res.Value {
+			points, _ := v["points"].([]interface{})
+			for _, p := range points {
+				point, ok := p.(map[string]interface{})
+				if !ok {
+					continue
+				}
+				item := map[string]interface{}{
+					"origin":     ngsilib.TableCell(v, "_id.origin"),
+					"resolution": ngsilib.TableCell(v, "_id.resolution"),
+					"offset":     point["offset"],
+					"samples":    point["samples"],
+					"value":      point[c.String("aggrMethod")],
+				}
+				for k, e := range ctx {
+					item[k] = e
+				}
+				items = append(items, item)
+			}
+		}
+	} else {
+		for _, v := range res.Value {
+			item := map[string]interface{}{
+				"index": v["recvTime"],
+				"value": v["attrValue"],
+			}
+			for k, e := range ctx {
+				item[k] = e
+			}
+			items = append(items, item)
+		}
+	}
+
+	if err := ngsilib.PrintTable(ngsi.StdWriter, c.String("output"), items, columns); err != nil {
+		return ngsierr.New(funcName, 2, err.Error(), err)
+	}
+	return nil
+}
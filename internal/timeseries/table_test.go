@@ -0,0 +1,238 @@
+/*
+MIT License
+
+Copyright (c) 2020-2024 Kazuhito Suda
+
+This file is part of NGSI Go
+
+https://github.com/lets-fiware/ngsi-go
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+
+*/
+
+package timeseries
+
+import (
+	"testing"
+
+	"github.com/lets-fiware/ngsi-go/internal/assert"
+	"github.com/lets-fiware/ngsi-go/internal/helper"
+	"github.com/lets-fiware/ngsi-go/internal/ngsierr"
+)
+
+func TestTsTableContext(t *testing.T) {
+	c := setupTest([]string{"hget", "attr", "--host", "ql", "--id", "device001", "--attr", "A1"})
+
+	actual := tsTableContext(c)
+
+	assert.Equal(t, map[string]interface{}{"entityId": "device001", "attrName": "A1"}, actual)
+}
+
+func TestQlPrintTableAttr(t *testing.T) {
+	c := setupTest([]string{"hget", "attr", "--host", "ql", "--id", "device001", "--attr", "A1", "--output", "csv"})
+	body := []byte(`{"attrName":"A1","entityId":"device001","index":["2016-09-13T03:01:00.000+00:00","2016-09-13T03:03:00.000+00:00"],"values":[91.0,92.5]}`)
+
+	err := qlPrintTable(c, c.Ngsi, body)
+
+	if assert.NoError(t, err) {
+		actual := helper.GetStdoutString(c)
+		expected := "entityType,entityId,attrName,index,value\n" +
+			",device001,A1,2016-09-13T03:01:00.000+00:00,91\n" +
+			",device001,A1,2016-09-13T03:03:00.000+00:00,92.5\n"
+		assert.Equal(t, expected, actual)
+	}
+}
+
+func TestQlPrintTableAttrValue(t *testing.T) {
+	c := setupTest([]string{"hget", "attr", "--host", "ql", "--type", "Device", "--id", "device001", "--attr", "A1", "--value", "--output", "tsv"})
+	body := []byte(`{"index":["2016-09-13T03:01:00.000+00:00"],"values":[91.0]}`)
+
+	err := qlPrintTable(c, c.Ngsi, body)
+
+	if assert.NoError(t, err) {
+		actual := helper.GetStdoutString(c)
+		expected := "entityType\tentityId\tattrName\tindex\tvalue\n" +
+			"Device\tdevice001\tA1\t2016-09-13T03:01:00.000+00:00\t91\n"
+		assert.Equal(t, expected, actual)
+	}
+}
+
+func TestQlPrintTableNTypes(t *testing.T) {
+	c := setupTest([]string{"hget", "attr", "--host", "ql", "--attr", "A1", "--nTypes", "--output", "csv"})
+	body := []byte(`{"attrName":"A1","types":[{"entityType":"Device","entities":[{"entityId":"device001","index":["2016-09-13T03:01:00.000+00:00"],"values":[1]},{"entityId":"device002","index":["2016-09-13T03:02:00.000+00:00"],"values":[2]}]},{"entityType":"Sensor","entities":[{"entityId":"sensor001","index":["2016-09-13T03:03:00.000+00:00"],"values":[3]}]}]}`)
+
+	err := qlPrintTable(c, c.Ngsi, body)
+
+	if assert.NoError(t, err) {
+		actual := helper.GetStdoutString(c)
+		expected := "entityType,entityId,attrName,index,value\n" +
+			"Device,device001,A1,2016-09-13T03:01:00.000+00:00,1\n" +
+			"Device,device002,A1,2016-09-13T03:02:00.000+00:00,2\n" +
+			"Sensor,sensor001,A1,2016-09-13T03:03:00.000+00:00,3\n"
+		assert.Equal(t, expected, actual)
+	}
+}
+
+func TestQlPrintTableAttrsValues(t *testing.T) {
+	c := setupTest([]string{"hget", "attrs", "--host", "ql", "--type", "Device", "--sameType", "--value", "--output", "csv"})
+	body := []byte(`{"values":[{"entityId":"device001","index":["2016-09-13T03:01:00.000+00:00"],"attributes":[{"attrName":"A1","values":[1]},{"attrName":"A2","values":["on"]}]}]}`)
+
+	err := qlPrintTable(c, c.Ngsi, body)
+
+	if assert.NoError(t, err) {
+		actual := helper.GetStdoutString(c)
+		expected := "entityType,entityId,attrName,index,value\n" +
+			"Device,device001,A1,2016-09-13T03:01:00.000+00:00,1\n" +
+			"Device,device001,A2,2016-09-13T03:01:00.000+00:00,on\n"
+		assert.Equal(t, expected, actual)
+	}
+}
+
+func TestQlPrintTableErrorJSON(t *testing.T) {
+	c := setupTest([]string{"hget", "attr", "--host", "ql", "--id", "device001", "--attr", "A1", "--output", "csv"})
+
+	helper.SetJSONDecodeErr(c.Ngsi, 0)
+
+	err := qlPrintTable(c, c.Ngsi, []byte(`{}`))
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 1, ngsiErr.ErrNo)
+		assert.Equal(t, "json error", ngsiErr.Message)
+	}
+}
+
+func TestQlPrintTableErrorFormat(t *testing.T) {
+	c := setupTest([]string{"hget", "attr", "--host", "ql", "--id", "device001", "--attr", "A1", "--output", "csv"})
+	c.GetStringFlag("output").Value = "xml"
+
+	err := qlPrintTable(c, c.Ngsi, []byte(`{}`))
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 2, ngsiErr.ErrNo)
+		assert.Equal(t, "unknown output format: xml (csv, tsv, table)", ngsiErr.Message)
+	}
+}
+
+func TestQlTableItemsNotObject(t *testing.T) {
+	actual := qlTableItems("string", nil, []interface{}{})
+
+	assert.Equal(t, []interface{}{}, actual)
+}
+
+func TestQlEntitiesPrintTable(t *testing.T) {
+	c := setupTest([]string{"hget", "entities", "--host", "ql", "--output", "table"})
+	body := []byte(`[{"id":"Event001","index":["2016-11-13T00:11:22"],"type":"Event"},{"id":"Event002","index":["2016-11-13T00:11:22"],"type":"Event"}]`)
+
+	err := qlEntitiesPrintTable(c, c.Ngsi, body)
+
+	if assert.NoError(t, err) {
+		actual := helper.GetStdoutString(c)
+		expected := "id        type   index\n" +
+			"Event001  Event  2016-11-13T00:11:22\n" +
+			"Event002  Event  2016-11-13T00:11:22\n"
+		assert.Equal(t, expected, actual)
+	}
+}
+
+func TestQlEntitiesPrintTableErrorJSON(t *testing.T) {
+	c := setupTest([]string{"hget", "entities", "--host", "ql", "--output", "csv"})
+
+	helper.SetJSONDecodeErr(c.Ngsi, 0)
+
+	err := qlEntitiesPrintTable(c, c.Ngsi, []byte(`[]`))
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 1, ngsiErr.ErrNo)
+		assert.Equal(t, "json error", ngsiErr.Message)
+	}
+}
+
+func TestQlEntitiesPrintTableErrorFormat(t *testing.T) {
+	c := setupTest([]string{"hget", "entities", "--host", "ql", "--output", "csv"})
+	c.GetStringFlag("output").Value = "xml"
+
+	err := qlEntitiesPrintTable(c, c.Ngsi, []byte(`[]`))
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 2, ngsiErr.ErrNo)
+		assert.Equal(t, "unknown output format: xml (csv, tsv, table)", ngsiErr.Message)
+	}
+}
+
+func TestCometPrintTable(t *testing.T) {
+	c := setupTest([]string{"hget", "attr", "--host", "comet", "--id", "device001", "--type", "device", "--attr", "A1", "--hLimit", "3", "--output", "csv"})
+	body := []byte(`{"type":"StructuredValue","value":[{"recvTime":"2016-09-13T00:00:00.000Z","attrType":"Number","attrValue":1},{"recvTime":"2016-09-13T00:01:00.000Z","attrType":"Number","attrValue":2}]}`)
+
+	err := cometPrintTable(c, c.Ngsi, body)
+
+	if assert.NoError(t, err) {
+		actual := helper.GetStdoutString(c)
+		expected := "entityType,entityId,attrName,index,value\n" +
+			"device,device001,A1,2016-09-13T00:00:00.000Z,1\n" +
+			"device,device001,A1,2016-09-13T00:01:00.000Z,2\n"
+		assert.Equal(t, expected, actual)
+	}
+}
+
+func TestCometPrintTableAggr(t *testing.T) {
+	c := setupTest([]string{"hget", "attr", "--host", "comet", "--id", "device001", "--type", "device", "--attr", "A1", "--aggrMethod", "sum", "--aggrPeriod", "minute", "--output", "csv"})
+	body := []byte(`{"type":"StructuredValue","value":[{"_id":{"origin":"2016-09-13T00:00:00.000Z","attrName":"A1","resolution":"minute"},"points":[{"offset":0,"samples":2,"sum":3},{"offset":1,"samples":1,"sum":5},"point"]}]}`)
+
+	err := cometPrintTable(c, c.Ngsi, body)
+
+	if assert.NoError(t, err) {
+		actual := helper.GetStdoutString(c)
+		expected := "entityType,entityId,attrName,origin,resolution,offset,samples,value\n" +
+			"device,device001,A1,2016-09-13T00:00:00.000Z,minute,0,2,3\n" +
+			"device,device001,A1,2016-09-13T00:00:00.000Z,minute,1,1,5\n"
+		assert.Equal(t, expected, actual)
+	}
+}
+
+func TestCometPrintTableErrorJSON(t *testing.T) {
+	c := setupTest([]string{"hget", "attr", "--host", "comet", "--id", "device001", "--type", "device", "--attr", "A1", "--hLimit", "3", "--output", "csv"})
+
+	helper.SetJSONDecodeErr(c.Ngsi, 0)
+
+	err := cometPrintTable(c, c.Ngsi, []byte(`{}`))
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 1, ngsiErr.ErrNo)
+		assert.Equal(t, "json error", ngsiErr.Message)
+	}
+}
+
+func TestCometPrintTableErrorFormat(t *testing.T) {
+	c := setupTest([]string{"hget", "attr", "--host", "comet", "--id", "device001", "--type", "device", "--attr", "A1", "--hLimit", "3", "--output", "csv"})
+	c.GetStringFlag("output").Value = "xml"
+
+	err := cometPrintTable(c, c.Ngsi, []byte(`{}`))
+
+	if assert.Error(t, err) {
+		ngsiErr := err.(*ngsierr.NgsiError)
+		assert.Equal(t, 2, ngsiErr.ErrNo)
+		assert.Equal(t, "unknown output format: xml (csv, tsv, table)", ngsiErr.Message)
+	}
+}